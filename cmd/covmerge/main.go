@@ -0,0 +1,117 @@
+// Command covmerge combines unit-test profiles and instrumented E2E service
+// coverage into one profile, prints per-package coverage, and fails when a
+// package falls below its threshold.
+//
+// Usage:
+//
+//	go run ./cmd/covmerge \
+//	    -profile coverage/unit.out \
+//	    -coverdir 'coverage/e2e-service*' \
+//	    -thresholds coverage-thresholds.txt \
+//	    -o coverage/combined.out -html coverage/coverage.html
+//
+// -profile and -coverdir may be repeated and accept glob patterns, so data
+// from several E2E runs can be merged in one invocation.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gti/heatmap-internal/e2e/coverage"
+)
+
+// globList is a repeatable flag whose values are expanded as glob patterns.
+type globList struct {
+	paths []string
+	// dirs keeps only matches that are directories, so a pattern like
+	// coverage/e2e-service* skips the converted e2e-service.out file.
+	dirs bool
+}
+
+func (g *globList) String() string { return strings.Join(g.paths, ",") }
+
+func (g *globList) Set(value string) error {
+	matches, err := filepath.Glob(value)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", value, err)
+	}
+	for _, match := range matches {
+		if g.dirs {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		g.paths = append(g.paths, match)
+	}
+	return nil
+}
+
+func main() {
+	profiles := globList{}
+	coverDirs := globList{dirs: true}
+	var out, html, thresholdsFile string
+	flag.Var(&profiles, "profile", "text coverage profile (repeatable, glob)")
+	flag.Var(&coverDirs, "coverdir", "GOCOVERDIR directory from an instrumented run (repeatable, glob)")
+	flag.StringVar(&out, "o", "coverage/combined.out", "merged profile output path")
+	flag.StringVar(&html, "html", "", "also write an HTML report to this path")
+	flag.StringVar(&thresholdsFile, "thresholds", "", "per-package thresholds file")
+	flag.Parse()
+
+	if len(profiles.paths) == 0 && len(coverDirs.paths) == 0 {
+		log.Fatal("No coverage inputs found; pass -profile and/or -coverdir")
+	}
+
+	ctx := context.Background()
+	merged, err := coverage.Collect(ctx, coverage.Sources{Profiles: profiles.paths, CoverDirs: coverDirs.paths})
+	if err != nil {
+		log.Fatalf("Failed to merge coverage: %v", err)
+	}
+
+	if err := merged.Write(out); err != nil {
+		log.Fatalf("Failed to write merged profile: %v", err)
+	}
+
+	if html != "" {
+		cmd := exec.CommandContext(ctx, "go", "tool", "cover", "-html="+out, "-o="+html)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Fatalf("Failed to write HTML report: %v\n%s", err, output)
+		}
+	}
+
+	var thresholds coverage.Thresholds
+	if thresholdsFile != "" {
+		thresholds, err = coverage.ParseThresholds(thresholdsFile)
+		if err != nil {
+			log.Fatalf("Failed to load thresholds: %v", err)
+		}
+	}
+
+	packages := merged.Packages()
+	fmt.Printf("%-60s %8s %8s\n", "PACKAGE", "COVERAGE", "MINIMUM")
+	for _, stats := range packages {
+		minimum := "-"
+		if min, ok := thresholds.For(stats.Package); ok {
+			minimum = fmt.Sprintf("%.1f%%", min)
+		}
+		fmt.Printf("%-60s %7.1f%% %8s\n", stats.Package, stats.Percent(), minimum)
+	}
+	total := merged.Total()
+	fmt.Printf("%-60s %7.1f%%\n", "total", total.Percent())
+	fmt.Printf("\nMerged %d profile(s) and %d coverage dir(s) into %s\n", len(profiles.paths), len(coverDirs.paths), out)
+
+	violations := thresholds.Check(packages)
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d package(s) below threshold:\n", len(violations))
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "  %s\n", v)
+		}
+		os.Exit(1)
+	}
+}
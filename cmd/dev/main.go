@@ -0,0 +1,160 @@
+// Command dev bootstraps a complete local environment in one step: it starts
+// PostgreSQL, runs migrations, seeds demo data, and runs the server,
+// restarting it when templates or static files change and rebuilding it when
+// Go sources change.
+//
+// Usage (from the repository root):
+//
+//	go run ./cmd/dev                      # throwaway Postgres via testcontainers
+//	go run ./cmd/dev -db compose          # persistent Postgres from docker-compose.yml
+//	go run ./cmd/dev -db env -demo=false  # existing DATABASE_URL, no demo data
+//
+// Press Ctrl+C to stop the server (and the testcontainers database).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/database"
+	"github.com/gti/heatmap-internal/internal/demo"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// options holds the command-line options.
+type options struct {
+	db       string
+	port     int
+	apiKey   string
+	demo     bool
+	demoOpts demo.Options
+	watch    bool
+	interval time.Duration
+}
+
+func main() {
+	opts := parseFlags()
+
+	if _, err := os.Stat("go.mod"); err != nil {
+		log.Fatal("Run cmd/dev from the repository root")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	databaseURL, stopDatabase, err := startDatabase(ctx, opts.db)
+	if err != nil {
+		log.Fatalf("Failed to start database: %v", err)
+	}
+	defer stopDatabase()
+
+	if err := prepareDatabase(ctx, databaseURL, opts); err != nil {
+		stopDatabase()
+		//nolint:gocritic // We stop the database before Fatalf, so this is safe
+		log.Fatalf("Failed to prepare database: %v", err)
+	}
+
+	srv := newServer(opts.port, databaseURL, opts.apiKey)
+	log.Printf("Building server...")
+	if err := srv.build(ctx); err != nil {
+		stopDatabase()
+		log.Fatalf("%v", err)
+	}
+	if err := srv.start(ctx); err != nil {
+		stopDatabase()
+		log.Fatalf("%v", err)
+	}
+	defer srv.stop()
+
+	log.Printf("Server ready at %s (API key %q)", srv.url, opts.apiKey)
+	log.Printf("Database: %s", databaseURL)
+
+	if !opts.watch {
+		<-ctx.Done()
+		log.Printf("Shutting down...")
+		return
+	}
+
+	log.Printf("Watching templates, static files, and Go sources for changes")
+	newWatcher(opts.interval).run(ctx, func(c change) {
+		if c.code {
+			log.Printf("Go sources changed")
+		} else {
+			log.Printf("Templates or static files changed")
+		}
+		srv.restart(ctx, c.code)
+	})
+	log.Printf("Shutting down...")
+}
+
+// parseFlags reads and validates command-line options.
+func parseFlags() options {
+	defaults := demo.DefaultOptions()
+
+	var opts options
+	flag.StringVar(&opts.db, "db", dbTestcontainers, "database to use: testcontainers, compose, or env (DATABASE_URL)")
+	flag.IntVar(&opts.port, "port", 8080, "port for the server")
+	flag.StringVar(&opts.apiKey, "api-key", envOr("API_KEY", "dev-api-key"), "API key for protected endpoints")
+	flag.BoolVar(&opts.demo, "demo", true, "seed generated demo teams and workloads")
+	flag.IntVar(&opts.demoOpts.Teams, "teams", defaults.Teams, "number of demo teams")
+	flag.IntVar(&opts.demoOpts.TeamSize, "team-size", defaults.TeamSize, "people per demo team")
+	flag.IntVar(&opts.demoOpts.Days, "days", defaults.Days, "days of demo work")
+	flag.Int64Var(&opts.demoOpts.Seed, "seed", defaults.Seed, "demo data random seed")
+	flag.BoolVar(&opts.watch, "watch", true, "restart on template changes and rebuild on Go changes")
+	flag.DurationVar(&opts.interval, "interval", 500*time.Millisecond, "how often to check for changes")
+	flag.Parse()
+
+	opts.demoOpts.Start = defaults.Start
+	return opts
+}
+
+// prepareDatabase runs migrations and, if enabled, seeds demo data.
+func prepareDatabase(ctx context.Context, databaseURL string, opts options) error {
+	db, err := database.New(databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	log.Printf("Running migrations...")
+	if err := db.RunMigrations(ctx); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	if !opts.demo {
+		return nil
+	}
+
+	ds, err := demo.Generate(opts.demoOpts)
+	if err != nil {
+		return fmt.Errorf("failed to generate demo data: %w", err)
+	}
+
+	seeder := demo.NewSeeder(
+		repository.NewEntityRepository(db.Pool),
+		repository.NewGroupRepository(db.Pool),
+		repository.NewCapacityRepository(db.Pool),
+		repository.NewLoadRepository(db.Pool),
+	)
+	if err := seeder.Seed(ctx, ds); err != nil {
+		return fmt.Errorf("failed to seed demo data: %w", err)
+	}
+
+	log.Printf("Seeded %d demo persons in %d teams with %d loads (try %q)",
+		len(ds.Persons), len(ds.Groups), len(ds.Loads), ds.Groups[0].ID)
+	return nil
+}
+
+// envOr returns the environment variable or a fallback when it is unset.
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// server builds and runs cmd/server as a child process so it can be
+// restarted when sources or templates change.
+type server struct {
+	binary string
+	env    []string
+	url    string
+
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// newServer prepares a server that listens on port and uses databaseURL.
+func newServer(port int, databaseURL, apiKey string) *server {
+	env := append(os.Environ(),
+		"DATABASE_URL="+databaseURL,
+		fmt.Sprintf("PORT=%d", port),
+		"DEV_MODE=true",
+	)
+	if apiKey != "" {
+		env = append(env, "API_KEY="+apiKey)
+	}
+
+	return &server{
+		binary: filepath.Join("tmp", "dev-server"),
+		env:    env,
+		url:    fmt.Sprintf("http://localhost:%d", port),
+	}
+}
+
+// build compiles cmd/server into the dev binary.
+func (s *server) build(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.binary), 0o755); err != nil {
+		return fmt.Errorf("failed to create tmp dir: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", s.binary, "./cmd/server")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to build server: %w", err)
+	}
+	return nil
+}
+
+// start launches the binary and waits until it answers HTTP requests.
+func (s *server) start(ctx context.Context) error {
+	cmd := exec.Command(s.binary) //nolint:gosec // Binary path is built by this command
+	cmd.Env = s.env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	s.cmd = cmd
+	s.done = done
+
+	return s.waitReady(ctx, 30*time.Second)
+}
+
+// waitReady polls /health until the server is up or exits.
+func (s *server) waitReady(ctx context.Context, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return fmt.Errorf("server exited during startup")
+		default:
+		}
+
+		resp, err := client.Get(s.url + "/health")
+		if err == nil {
+			_ = resp.Body.Close()
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return fmt.Errorf("server not ready after %s", timeout)
+}
+
+// stop terminates the running process group, escalating to SIGKILL if it
+// does not exit promptly.
+func (s *server) stop() {
+	if s.cmd == nil || s.cmd.Process == nil {
+		return
+	}
+
+	pgid := s.cmd.Process.Pid
+	_ = syscall.Kill(-pgid, syscall.SIGTERM)
+
+	select {
+	case <-s.done:
+	case <-time.After(10 * time.Second):
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		<-s.done
+	}
+	s.cmd = nil
+}
+
+// restart stops the server, optionally rebuilds it, and starts it again.
+// A failed build leaves the server stopped until the next change.
+func (s *server) restart(ctx context.Context, rebuild bool) {
+	s.stop()
+
+	if rebuild {
+		log.Printf("Rebuilding server...")
+		if err := s.build(ctx); err != nil {
+			log.Printf("Build failed, waiting for the next change: %v", err)
+			return
+		}
+	}
+
+	if err := s.start(ctx); err != nil {
+		log.Printf("Server failed to start, waiting for the next change: %v", err)
+		return
+	}
+	log.Printf("Server ready at %s", s.url)
+}
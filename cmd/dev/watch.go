@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// change describes what kind of files changed between two scans.
+type change struct {
+	// code is true when Go sources changed and the server must be rebuilt.
+	code bool
+
+	// assets is true when templates or static files changed and a restart
+	// is enough.
+	assets bool
+}
+
+// watcher polls the source tree for changes. Polling keeps the command free
+// of platform-specific file notification dependencies and is fast enough
+// for a tree this size.
+type watcher struct {
+	codeDirs  []string
+	assetDirs []string
+	interval  time.Duration
+
+	code   map[string]time.Time
+	assets map[string]time.Time
+}
+
+// newWatcher creates a watcher over the server's Go packages and its
+// templates and static files, taking an initial snapshot.
+func newWatcher(interval time.Duration) *watcher {
+	w := &watcher{
+		codeDirs:  []string{"cmd/server", "internal", "docs"},
+		assetDirs: []string{"templates", "static"},
+		interval:  interval,
+	}
+	w.code = scan(w.codeDirs, isGoSource)
+	w.assets = scan(w.assetDirs, func(string) bool { return true })
+	return w
+}
+
+// run calls onChange after each scan that finds modified, added, or removed
+// files, until ctx is cancelled.
+func (w *watcher) run(ctx context.Context, onChange func(change)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		code := scan(w.codeDirs, isGoSource)
+		assets := scan(w.assetDirs, func(string) bool { return true })
+
+		c := change{
+			code:   !sameSnapshot(w.code, code),
+			assets: !sameSnapshot(w.assets, assets),
+		}
+		w.code, w.assets = code, assets
+
+		if c.code || c.assets {
+			onChange(c)
+		}
+	}
+}
+
+// isGoSource reports whether path is a non-test Go file.
+func isGoSource(path string) bool {
+	return strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go")
+}
+
+// scan records the modification time of every matching file under dirs.
+// Missing directories are ignored.
+func scan(dirs []string, match func(string) bool) map[string]time.Time {
+	files := make(map[string]time.Time)
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !match(path) {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// sameSnapshot reports whether two scans saw the same files and times.
+func sameSnapshot(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, modTime := range a {
+		if other, ok := b[path]; !ok || !other.Equal(modTime) {
+			return false
+		}
+	}
+	return true
+}
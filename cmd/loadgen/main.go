@@ -0,0 +1,314 @@
+// Command loadgen seeds synthetic entities and loads, then fires concurrent
+// heatmap and upsert requests at a running server and reports latency
+// percentiles per endpoint.
+//
+// Usage:
+//
+//	go run ./cmd/loadgen -url http://localhost:8080 -api-key $API_KEY \
+//	    -entities 50 -loads 2000 -concurrency 20 -duration 30s
+//
+// Seeded data is prefixed with "loadgen-" so it is easy to spot and is
+// removed on exit unless -keep is set.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// config holds the command-line options.
+type config struct {
+	baseURL     string
+	apiKey      string
+	entities    int
+	groups      int
+	loads       int
+	concurrency int
+	duration    time.Duration
+	upsertRatio float64
+	seed        int64
+	keep        bool
+}
+
+// generator fires requests against the target server.
+type generator struct {
+	cfg     config
+	client  *http.Client
+	persons []string
+	groups  []string
+	stats   *stats
+}
+
+func main() {
+	cfg := parseFlags()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	g := &generator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		stats:  newStats(),
+	}
+
+	log.Printf("Seeding %d persons, %d groups, and %d loads...", cfg.entities, cfg.groups, cfg.loads)
+	if err := g.seed(ctx); err != nil {
+		g.cleanup()
+		log.Fatalf("Failed to seed data: %v", err)
+	}
+
+	log.Printf("Running %d workers for %s...", cfg.concurrency, cfg.duration)
+	elapsed := g.run(ctx)
+
+	g.stats.report(os.Stdout, elapsed)
+
+	if !cfg.keep {
+		g.cleanup()
+	}
+}
+
+// parseFlags reads and validates command-line options.
+func parseFlags() config {
+	var cfg config
+	flag.StringVar(&cfg.baseURL, "url", "http://localhost:8080", "base URL of the server under test")
+	flag.StringVar(&cfg.apiKey, "api-key", os.Getenv("API_KEY"), "API key for protected endpoints (defaults to $API_KEY)")
+	flag.IntVar(&cfg.entities, "entities", 50, "number of person entities to seed")
+	flag.IntVar(&cfg.groups, "groups", 5, "number of groups to seed (persons are spread across them)")
+	flag.IntVar(&cfg.loads, "loads", 1000, "number of loads to seed")
+	flag.IntVar(&cfg.concurrency, "concurrency", 10, "number of concurrent workers")
+	flag.DurationVar(&cfg.duration, "duration", 30*time.Second, "how long to generate traffic")
+	flag.Float64Var(&cfg.upsertRatio, "upsert-ratio", 0.2, "fraction of requests that are load upserts")
+	flag.Int64Var(&cfg.seed, "seed", time.Now().UnixNano(), "random seed for reproducible runs")
+	flag.BoolVar(&cfg.keep, "keep", false, "keep seeded data after the run")
+	flag.Parse()
+
+	if cfg.entities < 1 || cfg.concurrency < 1 {
+		log.Fatal("-entities and -concurrency must be at least 1")
+	}
+	if cfg.upsertRatio < 0 || cfg.upsertRatio > 1 {
+		log.Fatal("-upsert-ratio must be between 0 and 1")
+	}
+
+	return cfg
+}
+
+// seed creates persons, groups, and loads through the public API.
+func (g *generator) seed(ctx context.Context) error {
+	rng := rand.New(rand.NewSource(g.cfg.seed))
+
+	for i := 0; i < g.cfg.entities; i++ {
+		email := fmt.Sprintf("loadgen-%04d@example.com", i)
+		body := map[string]interface{}{
+			"id":               email,
+			"title":            fmt.Sprintf("Loadgen Person %d", i),
+			"type":             "person",
+			"default_capacity": float64(3 + rng.Intn(5)),
+		}
+		if err := g.expect(ctx, http.MethodPost, "/api/entities", body, http.StatusCreated); err != nil {
+			return fmt.Errorf("failed to create person %s: %w", email, err)
+		}
+		g.persons = append(g.persons, email)
+	}
+
+	for i := 0; i < g.cfg.groups; i++ {
+		groupID := fmt.Sprintf("loadgen-group-%02d", i)
+		body := map[string]interface{}{
+			"id":    groupID,
+			"title": fmt.Sprintf("Loadgen Group %d", i),
+			"type":  "group",
+		}
+		if err := g.expect(ctx, http.MethodPost, "/api/entities", body, http.StatusCreated); err != nil {
+			return fmt.Errorf("failed to create group %s: %w", groupID, err)
+		}
+		g.groups = append(g.groups, groupID)
+	}
+
+	for i, email := range g.persons {
+		if len(g.groups) == 0 {
+			break
+		}
+		groupID := g.groups[i%len(g.groups)]
+		body := map[string]interface{}{"person_email": email}
+		if err := g.expect(ctx, http.MethodPost, "/api/groups/"+groupID+"/members", body, http.StatusOK); err != nil {
+			return fmt.Errorf("failed to add %s to %s: %w", email, groupID, err)
+		}
+	}
+
+	for i := 0; i < g.cfg.loads; i++ {
+		if err := g.expect(ctx, http.MethodPost, "/api/loads/upsert", g.randomLoad(rng, i), http.StatusOK); err != nil {
+			return fmt.Errorf("failed to create load %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// run fires requests until the duration elapses or ctx is cancelled.
+func (g *generator) run(ctx context.Context) time.Duration {
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.duration)
+	defer cancel()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < g.cfg.concurrency; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(g.cfg.seed + int64(worker) + 1))
+			for n := 0; ctx.Err() == nil; n++ {
+				g.fire(ctx, rng, worker, n)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return time.Since(start)
+}
+
+// fire sends one randomly chosen request and records its latency.
+func (g *generator) fire(ctx context.Context, rng *rand.Rand, worker, n int) {
+	var (
+		endpoint string
+		method   = http.MethodGet
+		path     string
+		body     interface{}
+	)
+
+	entity := g.randomEntity(rng)
+	switch r := rng.Float64(); {
+	case r < g.cfg.upsertRatio:
+		endpoint = "POST /api/loads/upsert"
+		method = http.MethodPost
+		path = "/api/loads/upsert"
+		body = g.randomLoad(rng, g.cfg.loads+worker*1_000_000+n)
+	case r < g.cfg.upsertRatio+(1-g.cfg.upsertRatio)/2:
+		endpoint = "GET /api/heatmap/:entity"
+		path = "/api/heatmap/" + entity
+	default:
+		endpoint = "GET /api/heatmap/:entity/day/:date"
+		path = "/api/heatmap/" + entity + "/day/" + randomDate(rng).Format("2006-01-02")
+	}
+
+	start := time.Now()
+	status, err := g.do(ctx, method, path, body)
+	latency := time.Since(start)
+
+	// Requests cut off by the end of the run are not failures
+	if ctx.Err() != nil {
+		return
+	}
+	g.stats.record(endpoint, latency, err == nil && status < 400)
+}
+
+// randomEntity picks a seeded person or group.
+func (g *generator) randomEntity(rng *rand.Rand) string {
+	if len(g.groups) > 0 && rng.Intn(4) == 0 {
+		return g.groups[rng.Intn(len(g.groups))]
+	}
+	return g.persons[rng.Intn(len(g.persons))]
+}
+
+// randomLoad builds an upsert body assigned to one to three persons.
+func (g *generator) randomLoad(rng *rand.Rand, n int) map[string]interface{} {
+	count := 1 + rng.Intn(3)
+	assignees := make([]map[string]interface{}, 0, count)
+	seen := make(map[string]bool)
+	for len(assignees) < count && len(seen) < len(g.persons) {
+		email := g.persons[rng.Intn(len(g.persons))]
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		assignees = append(assignees, map[string]interface{}{
+			"email":  email,
+			"weight": float64(1+rng.Intn(6)) / 2,
+		})
+	}
+
+	return map[string]interface{}{
+		"external_id": fmt.Sprintf("loadgen-%d", n),
+		"title":       fmt.Sprintf("Loadgen Task %d", n),
+		"source":      "loadgen",
+		"date":        randomDate(rng).Format("2006-01-02"),
+		"assignees":   assignees,
+	}
+}
+
+// randomDate picks a day inside the heatmap window (1 month back, 6 ahead).
+func randomDate(rng *rand.Rand) time.Time {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, rng.Intn(210)-30)
+}
+
+// cleanup deletes seeded entities; loads lose their assignments via cascade.
+func (g *generator) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ids := append(append([]string{}, g.groups...), g.persons...)
+	log.Printf("Removing %d seeded entities...", len(ids))
+	for _, id := range ids {
+		if _, err := g.do(ctx, http.MethodDelete, "/api/entities/"+id, nil); err != nil {
+			log.Printf("Failed to delete %s: %v", id, err)
+		}
+	}
+}
+
+// expect sends a request and fails unless the status matches.
+func (g *generator) expect(ctx context.Context, method, path string, body interface{}, want int) error {
+	status, err := g.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if status != want {
+		return fmt.Errorf("%s %s returned %d, want %d", method, path, status, want)
+	}
+	return nil
+}
+
+// do sends a request and returns the response status.
+func (g *generator) do(ctx context.Context, method, path string, body interface{}) (int, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.cfg.baseURL+path, reqBody)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.cfg.apiKey != "" {
+		req.Header.Set("x-api-key", g.cfg.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
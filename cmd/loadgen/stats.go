@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// stats collects latencies per endpoint.
+type stats struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+	}
+}
+
+// record adds one request outcome.
+func (s *stats) record(endpoint string, latency time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies[endpoint] = append(s.latencies[endpoint], latency)
+	if !ok {
+		s.errors[endpoint]++
+	}
+}
+
+// report writes a latency table with throughput and error counts.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoints := make([]string, 0, len(s.latencies))
+	for endpoint := range s.latencies {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "endpoint\trequests\terrors\treq/s\tp50\tp90\tp95\tp99\tmax\t")
+
+	for _, endpoint := range endpoints {
+		l := append([]time.Duration(nil), s.latencies[endpoint]...)
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t\n",
+			endpoint,
+			len(l),
+			s.errors[endpoint],
+			float64(len(l))/elapsed.Seconds(),
+			percentile(l, 50),
+			percentile(l, 90),
+			percentile(l, 95),
+			percentile(l, 99),
+			l[len(l)-1].Round(time.Microsecond),
+		)
+	}
+
+	_ = tw.Flush()
+}
+
+// percentile returns the p-th percentile of sorted latencies (nearest rank).
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank].Round(time.Microsecond)
+}
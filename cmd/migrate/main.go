@@ -0,0 +1,89 @@
+// Command migrate applies, reverts, or lists the versioned schema migrations
+// in internal/database/migrations. The server applies pending migrations on
+// start; use this to check what a release will apply or to step back after a
+// bad one.
+//
+// Usage:
+//
+//	go run ./cmd/migrate status        # every migration and when it was applied
+//	go run ./cmd/migrate up            # apply pending migrations
+//	go run ./cmd/migrate down -steps 1 # revert the latest migration
+//
+// The database is taken from DATABASE_URL (or .env).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gti/heatmap-internal/internal/config"
+	"github.com/gti/heatmap-internal/internal/database"
+)
+
+func main() {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := flags.Int("steps", 1, "migrations to revert with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: migrate status|up|down [-steps N]")
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	_ = flags.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = db.RunMigrations(ctx)
+	case "down":
+		if *steps < 1 {
+			db.Close()
+			//nolint:gocritic // We close DB before Fatalf, so this is safe
+			log.Fatalf("-steps must be at least 1")
+		}
+		err = db.MigrateDown(ctx, *steps)
+	case "status":
+		err = printStatus(ctx, db)
+	default:
+		db.Close()
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		db.Close()
+		log.Fatalf("Failed to %s: %v", command, err)
+	}
+}
+
+func printStatus(ctx context.Context, db *database.DB) error {
+	status, err := db.GetMigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range status {
+		applied := "pending"
+		if m.AppliedAt != nil {
+			applied = "applied " + m.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Printf("%04d_%-30s %s\n", m.Version, m.Name, applied)
+	}
+	return nil
+}
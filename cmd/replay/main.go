@@ -0,0 +1,98 @@
+// Command replay rebuilds the derived tables from the domain event log after
+// they went wrong, for instance after a bad migration. It finds the persons
+// and groups the events changed, with the groups of those persons, discards
+// their heatmap snapshots and recomputes them from the source tables, then
+// re-sweeps the overload days from today on.
+//
+// Usage:
+//
+//	go run ./cmd/replay              # every event
+//	go run ./cmd/replay -after 1200  # events after ID 1200
+//	go run ./cmd/replay -dry-run     # list what would be rebuilt
+//
+// The database is taken from DATABASE_URL (or .env). It prints the last
+// event replayed, to pass as -after next time.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/gti/heatmap-internal/internal/config"
+	"github.com/gti/heatmap-internal/internal/database"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+)
+
+func main() {
+	var after int64
+	var dryRun bool
+	flag.Int64Var(&after, "after", 0, "replay only events after this ID")
+	flag.BoolVar(&dryRun, "dry-run", false, "list the entities that would be rebuilt without writing")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.RunMigrations(ctx); err != nil {
+		db.Close()
+		//nolint:gocritic // We close DB before Fatalf, so this is safe
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	events := service.NewEventLog(repository.NewEventRepository(db.Pool))
+	entityIDs, last, err := events.ChangedEntities(ctx, after)
+	if err != nil {
+		db.Close()
+		log.Fatalf("Failed to read domain events: %v", err)
+	}
+	fmt.Printf("Events %d to %d changed %d entities\n", after+1, last, len(entityIDs))
+
+	if dryRun {
+		for _, id := range entityIDs {
+			fmt.Println(id)
+		}
+		return
+	}
+
+	entityRepo := repository.NewEntityRepository(db.Pool)
+	heatmapService := service.NewHeatmapService(
+		entityRepo,
+		repository.NewCapacityRepository(db.Pool),
+		repository.NewLoadRepository(db.Pool),
+		repository.NewGroupRepository(db.Pool),
+		repository.NewSnapshotRepository(db.Pool),
+		repository.NewScenarioRepository(db.Pool),
+		repository.NewHolidayRepository(db.Pool),
+	)
+
+	// Sweep overload days even when some snapshots failed, and fail after
+	// both so the next run repeats the same events
+	rebuilt, snapshotErr := heatmapService.RebuildSnapshots(ctx, entityIDs)
+	fmt.Printf("Rebuilt %d heatmap snapshots\n", rebuilt)
+
+	overloadService := service.NewOverloadService(repository.NewOverloadRepository(db.Pool), entityRepo)
+	opened, resolved, err := overloadService.Sweep(ctx)
+	if err != nil {
+		db.Close()
+		log.Fatalf("Failed to sweep overload days: %v", err)
+	}
+	fmt.Printf("Overload sweep: %d person-days overloaded, %d resolved\n", opened, resolved)
+
+	if snapshotErr != nil {
+		db.Close()
+		log.Fatalf("Failed to rebuild heatmap snapshots: %v", snapshotErr)
+	}
+	fmt.Printf("Replayed through event %d; next time run with -after %d\n", last, last)
+}
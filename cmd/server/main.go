@@ -1,6 +1,7 @@
 // @title Heatmap Internal API
 // @version 1.0
-// @description API for managing heatmap loads, entities, groups, and capacity tracking
+// @description API for managing heatmap loads, entities, groups, and capacity tracking.
+// @description API-key routes are also served under /api/v1, which keeps this behavior, and /api/v2, which returns structured errors; the unversioned paths are deprecated.
 // @termsOfService http://swagger.io/terms/
 
 // @contact.name API Support
@@ -25,21 +26,24 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	_ "github.com/gti/heatmap-internal/docs"
+	"github.com/gti/heatmap-internal/docs"
+	"github.com/gti/heatmap-internal/internal/cache"
 	"github.com/gti/heatmap-internal/internal/config"
 	"github.com/gti/heatmap-internal/internal/database"
 	"github.com/gti/heatmap-internal/internal/handler"
 	"github.com/gti/heatmap-internal/internal/middleware"
 	"github.com/gti/heatmap-internal/internal/repository"
 	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/gti/heatmap-internal/internal/tracing"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 func main() {
@@ -56,18 +60,22 @@ func main() {
 	}
 	defer db.Close()
 
-	// Run migrations
+	// Run migrations and seed data, unless on a read-only replica whose
+	// primary already has
 	ctx := context.Background()
-	if err := db.RunMigrations(ctx); err != nil {
-		db.Close()
-		//nolint:gocritic // We close DB before Fatalf, so this is safe
-		log.Fatalf("Failed to run migrations: %v", err)
-	}
+	if cfg.ReadOnly {
+		log.Println("Read-only mode: serving reads only, without migrations or background jobs")
+	} else {
+		if err := db.RunMigrations(ctx); err != nil {
+			db.Close()
+			//nolint:gocritic // We close DB before Fatalf, so this is safe
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
 
-	// Seed data
-	if err := db.SeedData(ctx); err != nil {
-		db.Close()
-		log.Fatalf("Failed to seed data: %v", err)
+		if err := db.SeedData(ctx); err != nil {
+			db.Close()
+			log.Fatalf("Failed to seed data: %v", err)
+		}
 	}
 
 	// Initialize repositories
@@ -75,92 +83,324 @@ func main() {
 	groupRepo := repository.NewGroupRepository(db.Pool)
 	capacityRepo := repository.NewCapacityRepository(db.Pool)
 	loadRepo := repository.NewLoadRepository(db.Pool)
+	snapshotRepo := repository.NewSnapshotRepository(db.Pool)
+	scenarioRepo := repository.NewScenarioRepository(db.Pool)
+	overloadRepo := repository.NewOverloadRepository(db.Pool)
+	reportRepo := repository.NewReportRepository(db.Pool)
+	delegationRepo := repository.NewDelegationRepository(db.Pool)
+	blackoutRepo := repository.NewBlackoutRepository(db.Pool)
+	holidayRepo := repository.NewHolidayRepository(db.Pool)
+	noteRepo := repository.NewNoteRepository(db.Pool)
+	pinRepo := repository.NewPinRepository(db.Pool)
+	integrationRepo := repository.NewIntegrationRepository(db.Pool)
+	eventRepo := repository.NewEventRepository(db.Pool)
+	jobRepo := repository.NewJobRepository(db.Pool)
+
+	// Rendered heatmap partials, invalidated by load and capacity writes
+	renderCache := cache.NewRenderCache(cfg.RenderCacheSize, cfg.RenderCacheTTL, groupRepo.GetGroupsForPerson)
 
 	// Initialize services
+	events := service.NewEventLog(eventRepo)
+	// Settings admins tune at runtime; the defaults apply until they are read
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db.Pool))
+	settingsService.SetAdmins(cfg.AdminEmails)
+	settingsService.RecordEvents(events)
+	if err := settingsService.Load(ctx); err != nil {
+		log.Printf("Failed to load settings, using the defaults: %v", err)
+	}
 	webhookService := service.NewWebhookService(cfg.WebhookDestinationURL, loadRepo, capacityRepo)
-	heatmapService := service.NewHeatmapService(entityRepo, capacityRepo, loadRepo, groupRepo)
-	loadService := service.NewLoadService(loadRepo, entityRepo, webhookService)
-	authService := service.NewAuthService(db.Pool, cfg.LarkAppID, cfg.LarkAppSecret)
-	capacityService := service.NewCapacityService(entityRepo, capacityRepo)
-
-	// Load templates
-	templates, err := loadTemplates()
+	webhookService.UseSettings(settingsService)
+	webhookService.RecordDeliveries(integrationRepo)
+	webhookService.SetEndpoints(repository.NewWebhookEndpointRepository(db.Pool))
+	webhookService.AlertGroups(groupRepo)
+	if cfg.WebhookDestinationURL != "" {
+		log.Println("WEBHOOK_DESTINATION_URL is deprecated; it receives every event besides the endpoints added through /api/webhooks")
+	}
+	var linkSigner *service.LinkSigner
+	if cfg.NotificationLinkTTL > 0 {
+		linkSigner = service.NewLinkSigner(cfg.SessionSecret, cfg.PublicURL+cfg.BasePath, cfg.NotificationLinkTTL)
+		webhookService.SetLinkSigner(linkSigner)
+	}
+	heatmapService := service.NewHeatmapService(entityRepo, capacityRepo, loadRepo, groupRepo, snapshotRepo, scenarioRepo, holidayRepo)
+	heatmapService.SetWeekStart(cfg.WeekStart)
+	heatmapService.SetAdmins(cfg.AdminEmails)
+	heatmapService.RecordEvents(events)
+	if cfg.ReadOnly {
+		heatmapService.DisableSnapshotSaves()
+	}
+	loadService := service.NewLoadService(loadRepo, entityRepo, groupRepo, blackoutRepo, capacityRepo, webhookService, renderCache)
+	loadService.RecordEvents(events)
+	loadService.UseSettings(settingsService)
+	loadService.UseShadowSources(scenarioRepo)
+	if cfg.WeightRulesFile != "" {
+		rules, err := service.LoadWeightRules(cfg.WeightRulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load weight rules: %v", err)
+		}
+		loadService.SetWeightRules(rules)
+		log.Printf("Loaded %d weight rules from %s", len(rules), cfg.WeightRulesFile)
+	}
+	loadService.MapIssues(service.IssueMapping{
+		StoryPointsField: cfg.IssueStoryPointsField,
+		PointWeight:      cfg.IssuePointWeight,
+	})
+	if cfg.RejectBlackouts {
+		loadService.RejectBlackouts()
+	}
+	if !cfg.AutoCreatePersons {
+		loadService.DisableAutoCreation()
+	}
+	loadService.LimitAutoCreation(cfg.AutoCreateDailyLimit)
+	customFieldService := service.NewCustomFieldService(repository.NewCustomFieldRepository(db.Pool))
+	loadService.CheckCustomFields(customFieldService)
+	larkClient := service.NewLarkClient(cfg.LarkAppID, cfg.LarkAppSecret)
+	rateLimitRepo := repository.NewRateLimitRepository(db.Pool)
+	authService := service.NewAuthService(db.Pool, otpDeliverer(cfg, larkClient))
+	authService.LimitOTPRequests(rateLimitRepo, cfg.OTPRequestLimit)
+	if cfg.OIDCIssuerURL != "" {
+		oidc := service.NewOIDCProvider(cfg.OIDCProviderName, cfg.OIDCIssuerURL, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL)
+		oidc.UseEmailClaim(cfg.OIDCEmailClaim, cfg.OIDCTenantID)
+		authService.UseOIDC(oidc)
+		log.Printf("Single sign-on with %s (%s)", cfg.OIDCProviderName, cfg.OIDCIssuerURL)
+	}
+	capacityService := service.NewCapacityService(entityRepo, capacityRepo, groupRepo, delegationRepo, renderCache)
+	capacityService.RecordEvents(events)
+	capacityService.NotifyWebhooks(webhookService)
+	capacityService.ReportLoads(loadRepo)
+	capacityService.SetAdmins(cfg.AdminEmails)
+	if cfg.CapacityApprovalDays > 0 {
+		capacityService.RequireApproval(cfg.CapacityApprovalDays)
+	}
+	peopleService := service.NewPeopleService(entityRepo, webhookService, renderCache)
+	peopleService.RecordEvents(events)
+	peopleService.UseSettings(settingsService)
+	overloadService := service.NewOverloadService(overloadRepo, entityRepo)
+	reportService := service.NewReportService(reportRepo)
+	noteService := service.NewNoteService(noteRepo)
+	noteService.SetAdmins(cfg.AdminEmails)
+	pinService := service.NewPinService(pinRepo)
+	integrationService := service.NewIntegrationService(integrationRepo)
+	statusService := service.NewStatusService(db.Health, integrationRepo, repository.NewIncidentRepository(db.Pool), time.Now())
+	statusService.SetAdmins(cfg.AdminEmails)
+	presenceService := service.NewPresenceService(repository.NewPresenceRepository(db.Pool), entityRepo, loadRepo)
+	digestService := service.NewDigestService(repository.NewDigestRepository(db.Pool), entityRepo, groupRepo, heatmapService,
+		larkClient, cfg.PublicURL+cfg.BasePath)
+	jobRunner := service.NewJobRunner(ctx, jobRepo)
+	apiMetricsService := service.NewAPIMetricsService(repository.NewAPIMetricsRepository(db.Pool))
+	apiMetricsService.SetAdmins(cfg.AdminEmails)
+
+	// Load templates, reloading them on every use in dev mode
+	load := func() (*template.Template, error) {
+		return loadTemplates(cfg.BasePath)
+	}
+	var templates handler.Templates
+	templates, err = load()
 	if err != nil {
 		log.Fatalf("Failed to load templates: %v", err)
 	}
+	if cfg.DevMode {
+		log.Println("Dev mode: reloading templates on every request")
+		templates = handler.NewDevTemplates(load)
+	}
 
 	// Initialize handlers
-	heatmapHandler := handler.NewHeatmapHandler(heatmapService, entityRepo, templates)
-	apiHandler := handler.NewAPIHandler(loadService, entityRepo, groupRepo)
+	heatmapHandler := handler.NewHeatmapHandler(heatmapService, noteService, pinService, customFieldService, entityRepo, scenarioRepo, templates, renderCache)
+	apiHandler := handler.NewAPIHandler(loadService, heatmapService, integrationService, settingsService, events, entityRepo, groupRepo, renderCache)
 	authHandler := handler.NewAuthHandler(authService, entityRepo, templates)
 	capacityHandler := handler.NewCapacityHandler(capacityService, templates)
+	healthHandler := handler.NewHealthHandler(db)
+	peopleHandler := handler.NewPeopleHandler(peopleService)
+	scenarioHandler := handler.NewScenarioHandler(scenarioRepo, heatmapService, templates)
+	overloadHandler := handler.NewOverloadHandler(overloadService)
+	reportHandler := handler.NewReportHandler(reportService, heatmapService)
+	noteHandler := handler.NewNoteHandler(noteService)
+	integrationHandler := handler.NewIntegrationHandler(integrationService, templates)
+	statusHandler := handler.NewStatusHandler(statusService, templates)
+	presenceHandler := handler.NewPresenceHandler(presenceService, templates)
+	eventHandler := handler.NewEventHandler(events)
+	jobHandler := handler.NewJobHandler(jobRunner, loadService, heatmapService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	customFieldHandler := handler.NewCustomFieldHandler(customFieldService)
+	digestHandler := handler.NewDigestHandler(digestService)
+	holidayHandler := handler.NewHolidayHandler(service.NewHolidayService(holidayRepo, cfg.HolidaysAPIURL), renderCache)
+	settingsHandler := handler.NewSettingsHandler(settingsService, templates, renderCache)
+	apiMetricsHandler := handler.NewAPIMetricsHandler(apiMetricsService)
+	linkHandler := handler.NewLinkHandler(linkSigner, heatmapService, noteService, capacityService, templates)
 
 	// Create Echo instance
 	e := echo.New()
 	e.HideBanner = true
+	e.IPExtractor = middleware.ClientIP(cfg.TrustedProxies)
+	if cfg.DevMode {
+		e.HTTPErrorHandler = handler.TemplateErrorHandler(e.DefaultHTTPErrorHandler)
+	}
 
 	// Middleware
+	// Trace first, so request log lines carry the IDs that webhooks the
+	// request causes are sent with
+	e.Use(middleware.Trace())
 	e.Use(echoMiddleware.RequestLoggerWithConfig(echoMiddleware.RequestLoggerConfig{
 		LogStatus:   true,
 		LogURI:      true,
 		LogError:    true,
 		HandleError: true,
 		LogValuesFunc: func(c echo.Context, v echoMiddleware.RequestLoggerValues) error {
+			traceID, spanID := tracing.IDs(c.Request().Context())
 			if v.Error == nil {
-				log.Printf("[%s] %s %d\n", v.Method, v.URI, v.Status)
+				log.Printf("[%s] %s %d trace_id=%s span_id=%s\n", v.Method, v.URI, v.Status, traceID, spanID)
 			} else {
-				log.Printf("[%s] %s %d - %v\n", v.Method, v.URI, v.Status, v.Error)
+				log.Printf("[%s] %s %d trace_id=%s span_id=%s - %v\n", v.Method, v.URI, v.Status, traceID, spanID, v.Error)
 			}
 			return nil
 		},
 	}))
 	e.Use(echoMiddleware.Recover())
-	e.Use(echoMiddleware.CORS())
+	e.Use(middleware.BasePath(cfg.BasePath))
+
+	// Refuse every change on a read-only replica
+	if cfg.ReadOnly {
+		e.Use(middleware.ReadOnly())
+	}
+	e.Use(middleware.RequestDeadline(cfg.RequestTimeout))
+	e.Use(middleware.CORS(middleware.CORSPolicy{
+		AllowOrigins:     cfg.CORSAllowOrigins,
+		AllowMethods:     cfg.CORSAllowMethods,
+		AllowCredentials: cfg.CORSAllowCredentials,
+	}))
+	e.Use(echoMiddleware.GzipWithConfig(echoMiddleware.GzipConfig{
+		// Small JSON responses are not worth the CPU
+		MinLength: 1024,
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Path(), cfg.BasePath+"/api/doc")
+		},
+	}))
 
 	// Optional session auth for all routes (sets user context if logged in)
 	e.Use(middleware.SessionAuthOptional(authService))
 
-	// Public routes
-	e.GET("/", heatmapHandler.Index)
-	e.GET("/login", authHandler.LoginPage)
-
-	// Auth routes (public)
-	e.POST("/auth/request-otp", authHandler.RequestOTP)
-	e.POST("/auth/verify-otp", authHandler.VerifyOTP)
-	e.POST("/auth/logout", authHandler.Logout)
-
-	// Protected routes (require session)
-	protected := e.Group("")
-	protected.Use(middleware.SessionAuth(authService))
-	protected.GET("/my-capacity", capacityHandler.MyCapacityPage)
-	protected.POST("/api/my-capacity", capacityHandler.UpdateMyCapacity)
-	protected.DELETE("/api/my-capacity/override/:date", capacityHandler.DeleteMyCapacityOverride)
-
-	// Public API routes
-	e.GET("/api/entities", apiHandler.ListEntities)
-	e.GET("/api/entities/:id", apiHandler.GetEntity)
-	e.GET("/api/heatmap/:entity", heatmapHandler.GetHeatmapPartial)
-	e.GET("/api/heatmap/:entity/day/:date", heatmapHandler.GetDayDetails)
-
-	// Protected API routes (require x-api-key)
-	apiProtected := e.Group("/api")
-	apiProtected.Use(middleware.APIKeyAuth(cfg.APIKey))
-	apiProtected.POST("/loads/upsert", apiHandler.UpsertLoad)
-	apiProtected.POST("/loads/upsert-by-employee-id", apiHandler.UpsertLoadByEmployeeID)
-	apiProtected.POST("/loads/:id/assignees", apiHandler.AddAssigneesToLoad)
-	apiProtected.DELETE("/loads/:id/assignees/:email", apiHandler.RemoveAssigneeFromLoad)
-	apiProtected.POST("/entities", apiHandler.CreateEntity)
-	apiProtected.PUT("/entities/:id", apiHandler.UpdateEntity)
-	apiProtected.DELETE("/entities/:id", apiHandler.DeleteEntity)
-	apiProtected.GET("/groups/:id/members", apiHandler.GetGroupMembers)
-	apiProtected.POST("/groups/:id/members", apiHandler.AddGroupMember)
-	apiProtected.DELETE("/groups/:id/members/:member", apiHandler.RemoveGroupMember)
-
-	// Static files (if needed)
-	e.Static("/static", "static")
-
-	// Swagger API documentation
-	e.GET("/api/doc/*", echoSwagger.WrapHandler)
+	// Shed bulk API traffic while requests queue for database connections
+	shedder := middleware.NewLoadShedder(func() middleware.PoolStats {
+		return db.Pool.Stat()
+	}, cfg.LoadShedWait, cfg.LoadShedRetryAfter)
+	go shedder.Run(ctx, time.Second)
+
+	// Limit OTP requests and verifications per client IP
+	otpLimiter := middleware.NewRateLimiter(rateLimitRepo, cfg.OTPIPLimit, time.Hour)
+
+	// Count API requests per key, writing them every minute; read-only
+	// replicas cannot write them
+	var apiMetricsRecorder middleware.APIKeyRecorder
+	if !cfg.ReadOnly {
+		apiMetricsRecorder = apiMetricsService
+		go apiMetricsService.RunFlush(ctx, service.APIMetricsFlushInterval)
+	}
+
+	// Drop expired sessions, OTPs, SSO logins and rate limit windows; a
+	// read-only replica cannot
+	if !cfg.ReadOnly {
+		go authService.RunCleanup(ctx, service.AuthCleanupInterval)
+	}
+
+	// Pick up settings changed through other instances
+	go settingsService.RunRefresh(ctx, service.SettingsRefreshInterval)
+
+	// Precompute heatmaps after the window moves at midnight
+	if cfg.SnapshotRefresh {
+		go heatmapService.RunSnapshotRefresh(ctx, cfg.SnapshotRefreshAt)
+	}
+
+	// Post each group's upcoming two weeks to its Lark chat on Mondays
+	if cfg.LarkDigest {
+		if cfg.PublicURL == "" {
+			log.Println("PUBLIC_URL is not set; links in Lark digests will be relative")
+		}
+		go digestService.RunWeeklyDigests(ctx, cfg.LarkDigestAt)
+	}
+
+	// Track when person-days go over capacity and come back under
+	if cfg.OverloadSweepInterval > 0 {
+		go overloadService.RunOverloadSweep(ctx, cfg.OverloadSweepInterval)
+	}
+
+	// Store each quarter's utilization report soon after it ends
+	if cfg.QuarterlyReportCheck > 0 {
+		go reportService.RunQuarterlyReports(ctx, cfg.QuarterlyReportCheck)
+	}
+
+	// Keep a year of occurrences ahead for loads that repeat without end
+	if cfg.RecurrenceExpansion > 0 {
+		go loadService.RunRecurrenceExpansion(ctx, cfg.RecurrenceExpansion)
+	}
+
+	// Remind assignees of heavy loads they have not acknowledged, through the
+	// webhook endpoints subscribed to load_unacknowledged. Reminders no
+	// endpoint receives are retried once one does.
+	if cfg.AckReminderDays > 0 {
+		after := time.Duration(cfg.AckReminderDays) * 24 * time.Hour
+		go loadService.RunAcknowledgmentReminders(ctx, cfg.AckReminderInterval, after, cfg.AckReminderMinWeight)
+	}
+	if cfg.AnomalyCheckInterval > 0 {
+		go loadService.RunAnomalyCheck(ctx, cfg.AnomalyCheckInterval, cfg.AnomalyFactor)
+	}
+
+	// Flag imported loads their source stopped sending, for review
+	if cfg.StaleLoadWindow > 0 || len(cfg.StaleLoadSourceWindows) > 0 {
+		go loadService.RunStaleLoadCheck(ctx, cfg.StaleLoadCheckInterval, service.FreshnessWindows{
+			Default: cfg.StaleLoadWindow,
+			Sources: cfg.StaleLoadSourceWindows,
+		})
+	}
+
+	// Fail bulk jobs left unfinished by a server that stopped
+	if !cfg.ReadOnly {
+		go jobRunner.RunAbandonedJobCheck(ctx, time.Minute)
+	}
+
+	// Hand analytics each finished day's person load and capacity
+	if cfg.ExportDestination != "" {
+		var destination service.ExportDestination
+		if cfg.ExportDestination == "table" {
+			destination = service.NewTableDestination(repository.NewExportRepository(db.Pool), cfg.ExportTarget)
+		} else {
+			store := service.NewObjectStore(cfg.ExportEndpoint, cfg.ExportTarget, cfg.ExportRegion, cfg.ExportAccessKeyID, cfg.ExportSecretAccessKey)
+			destination = service.NewBucketDestination(store, cfg.ExportPrefix)
+		}
+		go service.NewExportService(reportRepo, destination).RunNightlyExport(ctx, cfg.ExportAt)
+	}
+
+	// The spec's server, so "Try it out" calls this deployment
+	if cfg.BasePath != "" {
+		log.Printf("Serving under %s", cfg.BasePath)
+		docs.SwaggerInfo.BasePath = cfg.BasePath
+	}
+	if u, err := url.Parse(cfg.PublicURL); err == nil && u.Host != "" {
+		docs.SwaggerInfo.Host = u.Host
+	}
+
+	registerRoutes(e, cfg.BasePath, cfg.APIKey, cfg.GroupAPIKeys, cfg.LegacyAPISunset, authService, shedder, otpLimiter, apiMetricsRecorder, routeHandlers{
+		heatmap:     heatmapHandler,
+		api:         apiHandler,
+		auth:        authHandler,
+		capacity:    capacityHandler,
+		health:      healthHandler,
+		people:      peopleHandler,
+		scenario:    scenarioHandler,
+		overload:    overloadHandler,
+		report:      reportHandler,
+		note:        noteHandler,
+		links:       linkHandler,
+		integration: integrationHandler,
+		events:      eventHandler,
+		jobs:        jobHandler,
+		webhooks:    webhookHandler,
+		status:      statusHandler,
+		presence:    presenceHandler,
+		customField: customFieldHandler,
+		digest:      digestHandler,
+		holiday:     holidayHandler,
+		settings:    settingsHandler,
+		apiMetrics:  apiMetricsHandler,
+	})
 
 	// Start server in goroutine
 	go func() {
@@ -185,19 +425,48 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+	if !cfg.ReadOnly {
+		if err := apiMetricsService.Flush(ctx); err != nil {
+			log.Printf("API metrics flush: %v", err)
+		}
+	}
 
 	log.Println("Server stopped")
 }
 
-func loadTemplates() (*template.Template, error) {
+// otpDeliverer returns what sends login codes, as configured
+func otpDeliverer(cfg *config.Config, lark *service.LarkClient) service.OTPDeliverer {
+	log.Printf("Sending login codes by %s", cfg.OTPDelivery)
+	switch cfg.OTPDelivery {
+	case "lark":
+		return service.NewLarkOTPDeliverer(lark)
+	case "mailgun":
+		return service.NewMailgunOTPDeliverer(cfg.MailgunAPIURL, cfg.MailgunDomain, cfg.MailgunAPIKey, cfg.OTPEmailFrom)
+	case "smtp":
+		return service.NewSMTPOTPDeliverer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.OTPEmailFrom)
+	default:
+		if cfg.Production {
+			log.Println("OTP_DELIVERY is console: login codes are only logged")
+		}
+		return service.ConsoleOTPDeliverer{}
+	}
+}
+
+// loadTemplates parses the templates, whose links go through the url
+// function so they stay under basePath
+func loadTemplates(basePath string) (*template.Template, error) {
 	// Custom template functions
 	funcMap := template.FuncMap{
+		"url": func(path string) string {
+			return basePath + path
+		},
 		"formatDate": func(t time.Time) string {
 			return t.Format("2006-01-02")
 		},
 		"formatDateTime": func(t time.Time) string {
 			return t.Format("Jan 2, 2006 3:04 PM")
 		},
+		"tagColor": service.TagColor,
 	}
 
 	templates, err := template.New("").Funcs(funcMap).ParseGlob("templates/*.html")
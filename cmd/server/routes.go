@@ -0,0 +1,255 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/handler"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+	echoSwagger "github.com/swaggo/echo-swagger"
+)
+
+// routeHandlers groups the handlers mounted by registerRoutes.
+type routeHandlers struct {
+	heatmap     *handler.HeatmapHandler
+	api         *handler.APIHandler
+	auth        *handler.AuthHandler
+	capacity    *handler.CapacityHandler
+	health      *handler.HealthHandler
+	people      *handler.PeopleHandler
+	scenario    *handler.ScenarioHandler
+	overload    *handler.OverloadHandler
+	report      *handler.ReportHandler
+	note        *handler.NoteHandler
+	links       *handler.LinkHandler
+	integration *handler.IntegrationHandler
+	events      *handler.EventHandler
+	jobs        *handler.JobHandler
+	webhooks    *handler.WebhookHandler
+	status      *handler.StatusHandler
+	presence    *handler.PresenceHandler
+	customField *handler.CustomFieldHandler
+	digest      *handler.DigestHandler
+	holiday     *handler.HolidayHandler
+	settings    *handler.SettingsHandler
+	apiMetrics  *handler.APIMetricsHandler
+}
+
+// dryRunRoutes are the writes that honor ?dry_run=true, checking the write
+// and reporting what it would do without making it; other writes refuse it.
+var dryRunRoutes = []string{
+	"POST /loads/upsert",
+	"POST /loads/upsert-by-employee-id",
+	"DELETE /loads/by-external-id/:external_id",
+	"POST /loads/:id/assignees",
+	"DELETE /loads/:id/assignees/:email",
+	"POST /entities",
+	"PUT /entities/:id",
+	"DELETE /entities/:id",
+	"POST /api/my-capacity",
+	"DELETE /api/my-capacity/override/:date",
+}
+
+// registerRoutes mounts every application route on e.
+//
+// It is kept separate from main so tests can compare the route table against
+// the published OpenAPI spec without a database.
+func registerRoutes(e *echo.Echo, basePath string, apiKey string, groupKeys map[string][]string, legacySunset time.Time, authService *service.AuthService, shedder *middleware.LoadShedder, otpLimiter *middleware.RateLimiter, apiMetrics middleware.APIKeyRecorder, h routeHandlers) {
+	// Every route is served under basePath, e.g. /heatmap behind a reverse
+	// proxy; the prefix alone redirects to the index page
+	root := e.Group(basePath)
+	if basePath != "" {
+		e.GET(basePath, func(c echo.Context) error {
+			return c.Redirect(http.StatusMovedPermanently, basePath+"/")
+		})
+	}
+
+	// Public routes
+	root.GET("/health", h.health.Health)
+	root.GET("/metrics", h.overload.Metrics)
+	root.GET("/", h.heatmap.Index)
+	root.GET("/login", h.auth.LoginPage)
+	root.GET("/dashboard/:group", h.heatmap.Dashboard)
+	root.GET("/integrations", h.integration.IntegrationsPage)
+	root.GET("/status", h.status.StatusPage)
+
+	// Signed links from notifications (public, read-only)
+	root.GET("/links/day/:email/:date", h.links.LinkedDay)
+	root.GET("/links/settings/:email", h.links.LinkedSettings)
+
+	// Auth routes (public), rate limited per client IP against brute forcing
+	root.POST("/auth/request-otp", h.auth.RequestOTP, middleware.RateLimit(otpLimiter, "request-otp"))
+	root.POST("/auth/verify-otp", h.auth.VerifyOTP, middleware.RateLimit(otpLimiter, "verify-otp"))
+	root.POST("/auth/logout", h.auth.Logout)
+	// Single sign-on (public), answering 404 unless OIDC_ISSUER_URL is set
+	root.GET("/auth/oidc/login", h.auth.OIDCLogin)
+	root.GET("/auth/oidc/callback", h.auth.OIDCCallback)
+
+	// Protected routes (require session)
+	protected := root.Group("")
+	protected.Use(middleware.SessionAuth(authService), middleware.DryRun(dryRunRoutes...))
+	protected.GET("/my-capacity", h.capacity.MyCapacityPage)
+	protected.POST("/api/my-capacity", h.capacity.UpdateMyCapacity)
+	protected.DELETE("/api/my-capacity/override/:date", h.capacity.DeleteMyCapacityOverride)
+	protected.DELETE("/api/my-capacity/overrides", h.capacity.DeleteMyCapacityOverrides)
+	protected.GET("/api/my-capacity/audit", h.capacity.GetMyCapacityAudit)
+	protected.GET("/api/my-delegations", h.capacity.ListMyDelegations)
+	protected.POST("/api/my-delegations", h.capacity.AddMyDelegation)
+	protected.DELETE("/api/my-delegations/:assistant", h.capacity.RemoveMyDelegation)
+	protected.POST("/api/loads/:id/acknowledge", h.api.AcknowledgeLoad)
+	protected.GET("/api/my-loads/unacknowledged", h.api.ListMyUnacknowledgedLoads)
+	protected.POST("/api/loads/:id/actual", h.api.RecordLoadActual)
+	protected.POST("/api/loads/:id/notes", h.note.AddLoadNote)
+	protected.POST("/api/my-notes", h.note.AddMyDayNote)
+	protected.DELETE("/api/my-notes/:id", h.note.DeleteMyNote)
+	protected.PUT("/api/entities/:id/info", h.note.UpdateEntityInfo)
+	protected.POST("/api/loads/:id/pin", h.heatmap.PinLoad)
+	protected.DELETE("/api/loads/:id/pin", h.heatmap.UnpinLoad)
+	protected.GET("/api/my-pins", h.heatmap.ListMyPins)
+	protected.GET("/api/my-preferences", h.heatmap.GetMyPreferences)
+	protected.PUT("/api/my-preferences", h.heatmap.UpdateMyPreferences)
+	protected.GET("/api/capacity-approvals", h.capacity.ListCapacityApprovals)
+	protected.POST("/api/capacity-approvals/:id/approve", h.capacity.ApproveCapacityChange)
+	protected.POST("/api/capacity-approvals/:id/reject", h.capacity.RejectCapacityChange)
+	protected.POST("/api/status/incidents", h.status.AddIncident)
+	protected.PUT("/api/status/incidents/:id", h.status.UpdateIncident)
+	protected.DELETE("/api/status/incidents/:id", h.status.DeleteIncident)
+	protected.GET("/settings", h.settings.SettingsPage)
+	protected.PUT("/api/settings", h.settings.UpdateSettings)
+	protected.GET("/api/admin/api-metrics", h.apiMetrics.GetAPIMetrics)
+	protected.DELETE("/api/admin/capacity/:id/overrides", h.capacity.DeleteCapacityOverrides)
+	protected.POST("/api/presence/:kind/:id", h.presence.Heartbeat)
+
+	// Public API routes
+	// Pollers revalidate the entity list by ETag, or fetch only its changes
+	root.GET("/api/entities", h.api.ListEntities, middleware.CacheControl(middleware.CachePartial), middleware.ETag())
+	root.GET("/api/entities/changes", h.api.ListEntityChanges)
+	root.GET("/api/entities/:id", h.api.GetEntity)
+	root.GET("/api/entities/:id/calendar.ics", h.api.GetEntityCalendar)
+	root.GET("/api/scenarios", h.scenario.ListScenarios)
+	root.GET("/api/scenarios/:id", h.scenario.GetScenario)
+	root.GET("/api/scenarios/:id/heatmap/:entity", h.scenario.GetScenarioHeatmap)
+	root.GET("/api/integrations/health", h.integration.GetIntegrationHealth)
+	// The heatmap handler sets its own ETag from row timestamps; day details
+	// are tagged by hashing the rendered body
+	root.GET("/api/heatmap/:entity", h.heatmap.GetHeatmapPartial, middleware.CacheControl(middleware.CachePartial))
+	root.GET("/api/heatmap/:entity/json", h.heatmap.GetHeatmapJSON, middleware.CacheControl(middleware.CachePartial))
+	root.GET("/api/heatmap/:entity/day/:date", h.heatmap.GetDayDetails,
+		middleware.CacheControl(middleware.CachePartial), middleware.ETag())
+	root.GET("/api/heatmap/:entity/day/:date/members", h.heatmap.GetGroupDayBreakdown,
+		middleware.CacheControl(middleware.CachePartial), middleware.ETag())
+	root.GET("/api/dashboard/:group", h.heatmap.GetDashboardPartial, middleware.CacheControl(middleware.CachePartial))
+	root.GET("/api/heatmap/:entity/reports", h.heatmap.GetRollupHeatmap, middleware.CacheControl(middleware.CachePartial))
+
+	// Protected API routes (require x-api-key). They carry bulk imports, so
+	// they are shed while the database pool is saturated, leaving connections
+	// for interactive users.
+	//
+	// Integrations such as n8n should call a versioned prefix: /api/v1 keeps
+	// the original behavior and /api/v2 carries breaking changes, starting
+	// with structured error bodies. The unversioned paths answer as v1 but
+	// announce their deprecation and, once set, their sunset date.
+	//
+	// Group-scoped API keys may only write for their groups' members; the
+	// load service and the entity and group handlers enforce that, and routes
+	// that do not check are closed to them.
+	//
+	// Every request, refused keys included, is counted per API key
+	auth := []echo.MiddlewareFunc{middleware.APIMetrics(apiMetrics), middleware.APIKeyAuth(apiKey, groupKeys), middleware.LoadShed(shedder), middleware.DryRun(dryRunRoutes...)}
+	legacy := append([]echo.MiddlewareFunc{middleware.Deprecated(basePath+"/api", basePath+"/api/v1", legacySunset)}, auth...)
+	v2 := append([]echo.MiddlewareFunc{middleware.ErrorEnvelope()}, auth...)
+	registerIntegrationRoutes(root.Group("/api", legacy...), h)
+	registerIntegrationRoutes(root.Group("/api/v1", auth...), h)
+	registerIntegrationRoutes(root.Group("/api/v2", v2...), h)
+
+	// Static files, revalidated by ETag after the max-age expires
+	static := root.Group("/static", middleware.CacheControl(middleware.CacheStatic), middleware.ETag())
+	static.Static("/", "static")
+
+	// Swagger API documentation
+	root.GET("/api/doc/*", echoSwagger.WrapHandler)
+}
+
+// registerIntegrationRoutes mounts the API-key routes used by integrations on
+// g, once per API version.
+func registerIntegrationRoutes(g *echo.Group, h routeHandlers) {
+	g.POST("/loads/upsert", h.api.UpsertLoad)
+	g.POST("/loads/upsert-by-employee-id", h.api.UpsertLoadByEmployeeID)
+	g.POST("/loads/from-issue", h.api.UpsertLoadFromIssue)
+	g.POST("/loads/:id/assignees", h.api.AddAssigneesToLoad)
+	g.DELETE("/loads/:id/assignees/:email", h.api.RemoveAssigneeFromLoad)
+	g.DELETE("/loads/by-external-id/:external_id", h.api.DeleteLoadByExternalID)
+	g.GET("/loads", h.api.ListLoads)
+	g.GET("/loads/stale", h.api.GetStaleLoads)
+	g.POST("/loads/stale/delete", h.api.DeleteStaleLoads)
+	g.GET("/utilization", h.api.ListUtilization)
+	g.POST("/entities", h.api.CreateEntity)
+	g.PUT("/entities/:id", h.api.UpdateEntity)
+	g.GET("/entities/:id/delete-preview", h.api.GetDeletePreview)
+	g.DELETE("/entities/:id", h.api.DeleteEntity)
+	g.POST("/entities/:id/archive", h.api.ArchiveEntity)
+	g.POST("/entities/:id/restore", h.api.RestoreEntity)
+	g.GET("/entities/:id/blackouts", h.api.ListBlackouts)
+	g.POST("/entities/:id/blackouts", h.api.AddBlackout)
+	g.DELETE("/entities/:id/blackouts/:blackout", h.api.DeleteBlackout)
+	g.GET("/groups/:id/members", h.api.GetGroupMembers)
+	g.POST("/groups/:id/members", h.api.AddGroupMember)
+	g.DELETE("/groups/:id/members/:member", h.api.RemoveGroupMember)
+	g.GET("/groups/:id/heatmap-exclusions", h.api.GetGroupHeatmapExclusions)
+	g.PUT("/groups/:id/heatmap-exclusions/:member", h.api.ExcludeGroupMember)
+	g.DELETE("/groups/:id/heatmap-exclusions/:member", h.api.IncludeGroupMember)
+	g.GET("/groups/:id/least-loaded", h.api.GetLeastLoaded)
+	g.GET("/rebalance/:group", h.api.RebalanceGroup)
+	g.GET("/groups/:id/owners", h.api.GetGroupOwners)
+	g.POST("/groups/:id/owners", h.api.AddGroupOwner)
+	g.DELETE("/groups/:id/owners/:owner", h.api.RemoveGroupOwner)
+	g.PUT("/groups/:id/dashboard", h.api.EnableGroupDashboard)
+	g.DELETE("/groups/:id/dashboard", h.api.DisableGroupDashboard)
+	g.GET("/groups/:id/lark-digest", h.digest.GetLarkDigest)
+	g.PUT("/groups/:id/lark-digest", h.digest.PutLarkDigest)
+	g.DELETE("/groups/:id/lark-digest", h.digest.DeleteLarkDigest)
+	g.POST("/suggest-assignee", h.api.SuggestAssignee)
+
+	// People, group import and scenario writes do not check a key's groups,
+	// and the event log, bulk jobs, webhook endpoints, custom fields and
+	// holidays and reports span every group
+	unscoped := middleware.UnscopedAPIKey()
+	g.GET("/events", h.events.ListEvents, unscoped)
+	g.GET("/reports/overload-resolution", h.overload.GetResolutionReport, unscoped)
+	g.GET("/reports/utilization", h.report.GetUtilizationReport, unscoped)
+	g.GET("/reports/calibration", h.report.GetCalibrationReport, unscoped)
+	g.POST("/loads/bulk-upsert", h.jobs.BulkUpsertLoads, unscoped)
+	g.POST("/loads/reassign", h.jobs.ReassignLoads, unscoped)
+	g.POST("/snapshots/backfill", h.jobs.BackfillSnapshots, unscoped)
+	g.GET("/jobs/:id", h.jobs.GetJob, unscoped)
+	g.GET("/webhooks", h.webhooks.ListWebhooks, unscoped)
+	g.POST("/webhooks", h.webhooks.CreateWebhook, unscoped)
+	g.GET("/webhooks/:id", h.webhooks.GetWebhook, unscoped)
+	g.PUT("/webhooks/:id", h.webhooks.UpdateWebhook, unscoped)
+	g.DELETE("/webhooks/:id", h.webhooks.DeleteWebhook, unscoped)
+	g.GET("/custom-fields", h.customField.ListCustomFields, unscoped)
+	g.PUT("/custom-fields/:source/:key", h.customField.PutCustomField, unscoped)
+	g.DELETE("/custom-fields/:source/:key", h.customField.DeleteCustomField, unscoped)
+	g.GET("/holidays/:region", h.holiday.ListHolidays, unscoped)
+	g.POST("/holidays/:region/import", h.holiday.ImportHolidays, unscoped)
+	g.POST("/holidays/:region/fetch", h.holiday.FetchHolidays, unscoped)
+	g.DELETE("/holidays/:region/:date", h.holiday.DeleteHoliday, unscoped)
+	g.POST("/groups/import", h.people.ImportGroups, unscoped)
+	g.POST("/people/onboard", h.people.OnboardPerson, unscoped)
+	g.GET("/people/auto-created", h.people.ListAutoCreatedPersons, unscoped)
+	g.POST("/people/auto-created/confirm", h.people.ConfirmAutoCreatedPersons, unscoped)
+	g.POST("/people/auto-created/reject", h.people.RejectAutoCreatedPersons, unscoped)
+	g.POST("/people/:email/offboard", h.people.OffboardPerson, unscoped)
+	g.POST("/entities/:id/change-id", h.people.ChangeEntityID, unscoped)
+	g.POST("/scenarios", h.scenario.CreateScenario, unscoped)
+	g.DELETE("/scenarios/:id", h.scenario.DeleteScenario, unscoped)
+	g.POST("/scenarios/:id/loads", h.scenario.AddScenarioLoad, unscoped)
+	g.DELETE("/scenarios/:id/loads/:load", h.scenario.DeleteScenarioLoad, unscoped)
+	g.PUT("/scenarios/:id/capacity", h.scenario.SetScenarioCapacity, unscoped)
+	g.DELETE("/scenarios/:id/capacity/:entity/:date", h.scenario.DeleteScenarioCapacity, unscoped)
+	g.GET("/shadow-sources", h.scenario.ListShadowSources)
+	g.POST("/shadow-sources", h.scenario.CreateShadowSource, unscoped)
+	g.DELETE("/shadow-sources/:source", h.scenario.DeleteShadowSource, unscoped)
+}
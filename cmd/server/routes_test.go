@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/contract"
+	"github.com/gti/heatmap-internal/internal/handler"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumentedRoutes are served but intentionally left out of the API spec:
+// HTML pages, static assets, and the spec itself.
+var undocumentedRoutes = map[string]bool{
+	"GET /":                         true,
+	"GET /login":                    true,
+	"GET /dashboard/{group}":        true,
+	"GET /integrations":             true,
+	"GET /status":                   true,
+	"GET /links/day/{email}/{date}": true,
+	"GET /links/settings/{email}":   true,
+	"GET /static/*":                 true,
+	"GET /api/doc/*":                true,
+}
+
+// TestRoutesMatchSpec fails when a route is added or removed without
+// regenerating docs/swagger.json (make docs).
+func TestRoutesMatchSpec(t *testing.T) {
+	spec, err := contract.LoadDefault()
+	require.NoError(t, err)
+
+	e := echo.New()
+	registerRoutes(e, "", "test-api-key", nil, time.Time{}, nil, nil, nil, nil, stubRouteHandlers())
+
+	served := make(map[string]bool)
+	for _, r := range e.Routes() {
+		if !isHTTPMethod(r.Method) {
+			continue
+		}
+		route := contract.Route{Method: r.Method, Path: contract.SpecPath(unversioned(r.Path))}.String()
+		if !undocumentedRoutes[route] {
+			served[route] = true
+		}
+	}
+
+	documented := make(map[string]bool)
+	for _, r := range spec.Routes() {
+		documented[r.String()] = true
+	}
+
+	var missingFromSpec, missingFromRouter []string
+	for route := range served {
+		if !documented[route] {
+			missingFromSpec = append(missingFromSpec, route)
+		}
+	}
+	for route := range documented {
+		if !served[route] {
+			missingFromRouter = append(missingFromRouter, route)
+		}
+	}
+	sort.Strings(missingFromSpec)
+	sort.Strings(missingFromRouter)
+
+	assert.Empty(t, missingFromSpec, "routes served but not documented; add swag annotations and run make docs")
+	assert.Empty(t, missingFromRouter, "routes documented but not served; remove stale annotations and run make docs")
+}
+
+// TestRoutesUnderBasePath checks that BASE_PATH prefixes every route, and
+// that the bare prefix redirects to the index page.
+func TestRoutesUnderBasePath(t *testing.T) {
+	e := echo.New()
+	registerRoutes(e, "/heatmap", "test-api-key", nil, time.Time{}, nil, nil, nil, nil, stubRouteHandlers())
+
+	for _, r := range e.Routes() {
+		assert.True(t, strings.HasPrefix(r.Path, "/heatmap"), "%s %s is outside the base path", r.Method, r.Path)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/heatmap", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/heatmap/", rec.Header().Get(echo.HeaderLocation))
+}
+
+// stubRouteHandlers returns handlers that are never called, for tests that
+// only inspect the route table.
+func stubRouteHandlers() routeHandlers {
+	return routeHandlers{
+		heatmap:     &handler.HeatmapHandler{},
+		api:         &handler.APIHandler{},
+		auth:        &handler.AuthHandler{},
+		capacity:    &handler.CapacityHandler{},
+		health:      &handler.HealthHandler{},
+		people:      &handler.PeopleHandler{},
+		scenario:    &handler.ScenarioHandler{},
+		overload:    &handler.OverloadHandler{},
+		report:      &handler.ReportHandler{},
+		note:        &handler.NoteHandler{},
+		links:       &handler.LinkHandler{},
+		integration: &handler.IntegrationHandler{},
+		events:      &handler.EventHandler{},
+		jobs:        &handler.JobHandler{},
+		webhooks:    &handler.WebhookHandler{},
+		status:      &handler.StatusHandler{},
+		presence:    &handler.PresenceHandler{},
+		customField: &handler.CustomFieldHandler{},
+		digest:      &handler.DigestHandler{},
+		holiday:     &handler.HolidayHandler{},
+		settings:    &handler.SettingsHandler{},
+		apiMetrics:  &handler.APIMetricsHandler{},
+	}
+}
+
+// isHTTPMethod filters out Echo's internal route-not-found entries.
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// unversioned maps a versioned API path to the unversioned one documented in
+// the spec; every version serves the same operations.
+func unversioned(path string) string {
+	for _, prefix := range []string{"/api/v1/", "/api/v2/"} {
+		if strings.HasPrefix(path, prefix) {
+			return "/api/" + strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
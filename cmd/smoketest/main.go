@@ -0,0 +1,234 @@
+// Command smoketest runs a short, read-only sequence of requests against a
+// deployed server and exits non-zero if any check fails. It is meant to run
+// right after a deploy.
+//
+// Usage:
+//
+//	go run ./cmd/smoketest -url https://heatmap.example.com -api-key $API_KEY
+//
+// Checks, in order: /health, entity listing, one entity lookup, one rendered
+// heatmap, and (with an API key) that protected reads accept the key and
+// reject requests without it. Nothing is written.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// config holds the command-line options.
+type config struct {
+	baseURL string
+	apiKey  string
+	entity  string
+	timeout time.Duration
+}
+
+// entity is the subset of the entity JSON the smoke test needs.
+type entity struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// runner executes checks and records their outcome.
+type runner struct {
+	cfg    config
+	client *http.Client
+	out    io.Writer
+	failed int
+}
+
+func main() {
+	cfg := parseFlags()
+
+	r := &runner{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.timeout},
+		out:    os.Stdout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*cfg.timeout+10*time.Second)
+	defer cancel()
+
+	r.run(ctx)
+
+	if r.failed > 0 {
+		fmt.Fprintf(r.out, "\n%d check(s) failed against %s\n", r.failed, cfg.baseURL)
+		os.Exit(1)
+	}
+	fmt.Fprintf(r.out, "\nAll checks passed against %s\n", cfg.baseURL)
+}
+
+// parseFlags reads and validates command-line options.
+func parseFlags() config {
+	var cfg config
+	flag.StringVar(&cfg.baseURL, "url", "http://localhost:8080", "base URL of the deployed server")
+	flag.StringVar(&cfg.apiKey, "api-key", os.Getenv("API_KEY"), "API key for protected reads (defaults to $API_KEY; protected checks are skipped if empty)")
+	flag.StringVar(&cfg.entity, "entity", "", "entity to render a heatmap for (defaults to the first listed entity)")
+	flag.DurationVar(&cfg.timeout, "timeout", 10*time.Second, "timeout per request")
+	flag.Parse()
+
+	cfg.baseURL = strings.TrimRight(cfg.baseURL, "/")
+	if _, err := url.ParseRequestURI(cfg.baseURL); err != nil {
+		log.Fatalf("Invalid -url: %v", err)
+	}
+
+	return cfg
+}
+
+// run executes every check in order. Later checks that depend on data from
+// an earlier failed check are skipped rather than failed again.
+func (r *runner) run(ctx context.Context) {
+	r.check("health", func() error {
+		var body map[string]string
+		if err := r.getJSON(ctx, "/health", "", &body); err != nil {
+			return err
+		}
+		if body["status"] != "ok" {
+			return fmt.Errorf("status is %q", body["status"])
+		}
+		return nil
+	})
+
+	var entities []entity
+	listed := r.check("list entities", func() error {
+		return r.getJSON(ctx, "/api/entities", "", &entities)
+	})
+
+	target := r.cfg.entity
+	if target == "" && len(entities) > 0 {
+		target = entities[0].ID
+	}
+	if target == "" {
+		if listed {
+			r.skip("entity lookup", "no entities exist; pass -entity")
+			r.skip("render heatmap", "no entities exist; pass -entity")
+		}
+	} else {
+		r.check("entity lookup", func() error {
+			var e entity
+			if err := r.getJSON(ctx, "/api/entities/"+url.PathEscape(target), "", &e); err != nil {
+				return err
+			}
+			if e.ID != target {
+				return fmt.Errorf("expected entity %q, got %q", target, e.ID)
+			}
+			return nil
+		})
+
+		r.check("render heatmap", func() error {
+			body, err := r.get(ctx, "/api/heatmap/"+url.PathEscape(target), "", http.StatusOK)
+			if err != nil {
+				return err
+			}
+			if len(strings.TrimSpace(string(body))) == 0 {
+				return fmt.Errorf("empty heatmap response")
+			}
+			return nil
+		})
+	}
+
+	if r.cfg.apiKey == "" {
+		r.skip("protected read", "no API key")
+		return
+	}
+
+	var group string
+	for _, e := range entities {
+		if e.Type == "group" {
+			group = e.ID
+			break
+		}
+	}
+	if group == "" {
+		r.skip("protected read", "no group entities exist")
+		return
+	}
+
+	membersPath := "/api/groups/" + url.PathEscape(group) + "/members"
+	r.check("protected read", func() error {
+		var members []json.RawMessage
+		return r.getJSON(ctx, membersPath, r.cfg.apiKey, &members)
+	})
+	r.check("protected read without key", func() error {
+		_, err := r.get(ctx, membersPath, "", http.StatusUnauthorized)
+		return err
+	})
+}
+
+// check runs fn, prints its outcome, and reports whether it passed.
+func (r *runner) check(name string, fn func() error) bool {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		r.failed++
+		fmt.Fprintf(r.out, "FAIL  %-28s %8s  %v\n", name, elapsed, err)
+		return false
+	}
+	fmt.Fprintf(r.out, "ok    %-28s %8s\n", name, elapsed)
+	return true
+}
+
+// skip prints a check that could not run.
+func (r *runner) skip(name, reason string) {
+	fmt.Fprintf(r.out, "skip  %-28s %8s  %s\n", name, "-", reason)
+}
+
+// get sends a GET request and returns the body if the status matches want.
+func (r *runner) get(ctx context.Context, path, apiKey string, want int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("x-api-key", apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != want {
+		return nil, fmt.Errorf("GET %s: expected status %d, got %d: %s", path, want, resp.StatusCode, truncate(body, 200))
+	}
+	return body, nil
+}
+
+// getJSON sends a GET request expecting 200 and decodes the JSON body into v.
+func (r *runner) getJSON(ctx context.Context, path, apiKey string, v interface{}) error {
+	body, err := r.get(ctx, path, apiKey, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("GET %s: invalid JSON: %w", path, err)
+	}
+	return nil
+}
+
+// truncate shortens a response body for error messages.
+func truncate(body []byte, n int) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
@@ -23,31 +23,67 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/entities": {
+        "/api/admin/api-metrics": {
             "get": {
-                "description": "Returns all entities or filters by type (person/group)",
+                "description": "Per API key and UTC day between from and to: requests, 4xx and 5xx responses, the share answered with an error, and request and response bytes. Keys are named, never shown: api_key for API_KEY, groups:\u003cids\u003e for a group-scoped key, and unauthenticated for requests whose key was missing or wrong. Sorted by date, then most requests first. Counts reach the database within a minute. Only admins (ADMIN_EMAILS) may.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Entities"
+                    "Reports"
                 ],
-                "summary": "List all entities",
+                "summary": "API key metrics",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by entity type (person or group)",
-                        "name": "type",
+                        "description": "First date (YYYY-MM-DD), default 7 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), default today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this key's metrics, by name",
+                        "name": "key",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of entities",
+                        "description": "API key metrics",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.APIKeyMetricsReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -61,44 +97,50 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Create a new person or group entity",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/admin/capacity/{id}/overrides": {
+            "delete": {
+                "description": "Remove every date override of a person or group from from to to, both included, such as vacation entered on the wrong dates. Answers with how many were removed; the removal is audited as one entry. Only admins (ADMIN_EMAILS) may.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Entities"
+                    "Capacity"
                 ],
-                "summary": "Create a new entity",
+                "summary": "Delete an entity's capacity overrides in a range",
                 "parameters": [
                     {
-                        "description": "Entity to create",
-                        "name": "entity",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateEntityRequest"
-                        }
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created entity",
+                    "200": {
+                        "description": "Overrides removed",
                         "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeleteOverridesResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid date range",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -107,7 +149,25 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -127,34 +187,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/entities/{id}": {
+        "/api/capacity-approvals": {
             "get": {
-                "description": "Returns a single entity by its ID",
+                "description": "List pending capacity changes from members of groups the currently logged-in user owns",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Entities"
-                ],
-                "summary": "Get entity by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Entity ID (email for persons, string ID for groups)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Capacity"
                 ],
+                "summary": "List capacity approvals",
                 "responses": {
                     "200": {
-                        "description": "Entity details",
+                        "description": "Pending capacity changes",
                         "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Entity not found",
+                    "401": {
+                        "description": "Not authenticated",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -172,25 +226,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Delete an entity by its ID",
+            }
+        },
+        "/api/capacity-approvals/{id}/approve": {
+            "post": {
+                "description": "Approve a pending capacity change from a member of a group the currently logged-in user owns, applying it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Entities"
+                    "Capacity"
                 ],
-                "summary": "Delete an entity",
+                "summary": "Approve capacity change",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Entity ID",
+                        "type": "integer",
+                        "description": "Change request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -198,7 +249,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Approved change",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -207,7 +264,16 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an approver",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -216,7 +282,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Entity not found",
+                        "description": "Change request not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Already decided",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -236,25 +311,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/groups/{id}/members": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Returns all members of a group",
+        "/api/capacity-approvals/{id}/reject": {
+            "post": {
+                "description": "Reject a pending capacity change from a member of a group the currently logged-in user owns, discarding it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Groups"
+                    "Capacity"
                 ],
-                "summary": "Get group members",
+                "summary": "Reject capacity change",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group ID",
+                        "type": "integer",
+                        "description": "Change request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -262,14 +332,49 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Group members",
+                        "description": "Rejected change",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an approver",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Change request not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Already decided",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -287,54 +392,43 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/custom-fields": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add a person to a group",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the custom fields defined for a source's loads, or for every source, ordered by source, then position.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Groups"
+                    "Custom Fields"
                 ],
-                "summary": "Add member to group",
+                "summary": "List custom fields",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Member to add",
-                        "name": "member",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddGroupMemberRequest"
-                        }
+                        "description": "Only this source's fields",
+                        "name": "source",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Custom fields",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CustomField"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -342,8 +436,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -351,8 +445,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Group or person not found",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -360,8 +454,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "503": {
+                        "description": "Server busy, retry later",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -372,40 +466,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/groups/{id}/members/{member}": {
-            "delete": {
+        "/api/custom-fields/{source}/{key}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Remove a person from a group",
-                "produces": [
+                "description": "Define, or redefine, a custom field a source sends under custom_fields in its upserts: its label, its type (string, number, boolean or url), whether day details display it, and in what position. Upserts from the source must then send the field with that type; fields that are not defined are kept but neither checked nor displayed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Groups"
+                    "Custom Fields"
                 ],
-                "summary": "Remove member from group",
+                "summary": "Define a custom field",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
+                        "description": "Source, as sent in upserts",
+                        "name": "source",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Member email to remove",
-                        "name": "member",
+                        "description": "Key under custom_fields",
+                        "name": "key",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Field definition",
+                        "name": "field",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.PutCustomFieldRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Custom field",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CustomField"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -422,6 +534,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -430,86 +551,165 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            }
-        },
-        "/api/heatmap/{entity}": {
-            "get": {
-                "description": "Returns the heatmap grid partial for an entity",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a custom field's definition. Loads keep the values they were sent, but they are no longer checked or displayed.",
                 "produces": [
-                    "text/html"
+                    "application/json"
                 ],
                 "tags": [
-                    "Heatmap"
+                    "Custom Fields"
                 ],
-                "summary": "Get heatmap partial for entity",
+                "summary": "Delete a custom field",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Entity ID",
-                        "name": "entity",
+                        "description": "Source",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under custom_fields",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "HTML partial for heatmap grid",
+                        "description": "Success message",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Custom field not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Failed to load heatmap",
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/heatmap/{entity}/day/{date}": {
+        "/api/dashboard/{group}": {
             "get": {
-                "description": "Returns the tasks/loads for a specific day",
+                "description": "Returns a group's heatmap grid with only aggregate utilization per day, for groups shown on public dashboards. Members and loads are not included.",
                 "produces": [
                     "text/html"
                 ],
                 "tags": [
                     "Heatmap"
                 ],
-                "summary": "Get day details for entity",
+                "summary": "Get anonymized group heatmap partial",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Entity ID",
-                        "name": "entity",
+                        "description": "Group ID",
+                        "name": "group",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Date in YYYY-MM-DD format",
-                        "name": "date",
-                        "in": "path",
-                        "required": true
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "HTML partial for day details",
+                        "description": "HTML partial for the anonymized heatmap grid",
                         "schema": {
                             "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Heatmap version"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last change to the heatmap"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid date format",
+                    "304": {
+                        "description": "Heatmap unchanged since the given ETag or date"
+                    },
+                    "404": {
+                        "description": "Group not shown on dashboards",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to load day details",
+                        "description": "Failed to load dashboard",
                         "schema": {
                             "type": "string"
                         }
@@ -517,61 +717,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/loads/upsert": {
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Create or update a load item with assignments (for n8n integration)",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/entities": {
+            "get": {
+                "description": "Returns all entities or filters by type (person/group). Private persons are listed only for viewers who may see their heatmap: themselves, owners of their groups, and admins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Loads"
+                    "Entities"
                 ],
-                "summary": "Upsert a load",
+                "summary": "List all entities",
                 "parameters": [
                     {
-                        "description": "Load data to upsert",
-                        "name": "load",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadRequest"
-                        }
+                        "type": "string",
+                        "description": "Filter by entity type (person or group)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success with load ID",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request body",
+                        "description": "List of entities",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
                             }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "List version"
                             }
                         }
                     },
+                    "304": {
+                        "description": "List unchanged since the given ETag"
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -582,16 +770,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/loads/{id}/assignees": {
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add one or more assignees to an existing load with optional weight",
+                "description": "Create a new person or group entity. When the ID is taken the existing entity is returned with 409, unless upsert is set: then an active entity of the same type is updated with the fields given, and fields left out keep their values, as with PUT. Archived persons are not restored; onboard them instead. Without default_capacity the entity gets the default capacity from the settings, 5.0 unless an admin changed it. A group with capacity_mode members has the summed effective capacities of its active members each day, in place of its own.",
                 "consumes": [
                     "application/json"
                 ],
@@ -599,39 +785,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Loads"
+                    "Entities"
                 ],
-                "summary": "Add assignees to a load",
+                "summary": "Create a new entity",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Load ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Assignees to add",
-                        "name": "assignees",
+                        "description": "Entity to create",
+                        "name": "entity",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddAssigneeRequest"
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateEntityRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Update the entity if it exists, for idempotent directory syncs",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Existing entity, updated by an upsert",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                        }
+                    },
+                    "201": {
+                        "description": "Created entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request body, a private group, or a person with member capacities",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -648,8 +842,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Load not found",
+                    "403": {
+                        "description": "Outside the API key's groups",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -657,6 +851,12 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "ID taken, with the existing entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityConflictResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -665,44 +865,47 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/api/loads/{id}/assignees/{email}": {
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Remove a specific assignee from a load",
+        "/api/entities/changes": {
+            "get": {
+                "description": "Returns the entities created or updated after since, and the IDs of those archived or deleted after it, for clients keeping a copy of the entity list in sync without fetching all of it. Pass until from the response as the next since; an entity may be listed again when it changed while the list was read. Private persons the viewer may not see are left out, so a person who turns private is not reported as removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Loads"
+                    "Entities"
                 ],
-                "summary": "Remove assignee from load",
+                "summary": "List entity changes",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Load ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "Assignee email to remove",
-                        "name": "email",
-                        "in": "path",
+                        "description": "RFC 3339 time, usually until from the previous response",
+                        "name": "since",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Changes since the given time",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityChanges"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid since",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -710,26 +913,46 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}": {
+            "get": {
+                "description": "Returns a single entity by its ID. Private persons are not found for viewers who may not see their heatmap.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Get entity by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID (email for persons, string ID for groups)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entity details",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
                         }
                     },
                     "404": {
-                        "description": "Load or assignee not found",
+                        "description": "Entity not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -747,11 +970,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/my-capacity": {
-            "post": {
-                "description": "Update capacity settings for the currently logged-in user",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update an entity's title, employee_id, default_capacity, skills, manager_email, private, region, and/or capacity_mode. A group with capacity_mode members has the summed effective capacities of its active members each day, in place of its own.",
                 "consumes": [
                     "application/json"
                 ],
@@ -759,23 +985,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Capacity"
+                    "Entities"
                 ],
-                "summary": "Update user capacity",
+                "summary": "Update an entity",
                 "parameters": [
                     {
-                        "description": "Capacity update request",
-                        "name": "capacity",
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entity fields to update",
+                        "name": "entity",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateCapacityRequest"
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateEntityRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "Updated entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, a private group, or a person with member capacities",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -783,8 +1028,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -792,8 +1037,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Not authenticated",
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -809,30 +1063,45 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            }
-        },
-        "/api/my-capacity/override/{date}": {
+            },
             "delete": {
-                "description": "Delete a specific date override for the currently logged-in user",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Delete an entity by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Capacity"
+                    "Entities"
                 ],
-                "summary": "Delete capacity override",
+                "summary": "Delete an entity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Date in YYYY-MM-DD format",
-                        "name": "date",
+                        "description": "Entity ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -845,8 +1114,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid date format",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -854,8 +1123,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Not authenticated",
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -871,54 +1149,52 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/auth/logout": {
+        "/api/entities/{id}/archive": {
             "post": {
-                "description": "End the current session",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Authentication"
-                ],
-                "summary": "Logout",
-                "responses": {
-                    "302": {
-                        "description": "Redirect to home page"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     }
-                }
-            }
-        },
-        "/auth/request-otp": {
-            "post": {
-                "description": "Send an OTP code to the user's email for authentication",
-                "consumes": [
-                    "application/json"
                 ],
+                "description": "Hide a person or group from entity lists and heatmap selectors without deleting anything: its loads, memberships, capacity and history are kept, and an archived person cannot log in. Restore it with POST /api/entities/{id}/restore.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Entities"
                 ],
-                "summary": "Request OTP",
+                "summary": "Archive an entity",
                 "parameters": [
                     {
-                        "description": "Email address",
-                        "name": "email",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OTPRequest"
-                        }
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OTP sent successfully",
+                        "description": "Archived entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -926,8 +1202,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid email",
+                    "403": {
+                        "description": "Outside the API key's groups",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -936,7 +1212,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Email not found",
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Entity is already archived",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -945,7 +1230,16 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Failed to send OTP",
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -956,33 +1250,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/verify-otp": {
-            "post": {
-                "description": "Verify the OTP and create a session",
-                "consumes": [
-                    "application/json"
+        "/api/entities/{id}/blackouts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "List the blackouts of a person or group that have not yet ended, in date order",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Entities"
                 ],
-                "summary": "Verify OTP",
+                "summary": "List blackout dates",
                 "parameters": [
                     {
-                        "description": "Email and OTP",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.VerifyOTPRequest"
-                        }
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OTP verified, session created",
+                        "description": "Blackouts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.BlackoutDate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -990,8 +1293,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid OTP",
+                    "404": {
+                        "description": "Entity not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1000,7 +1303,16 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Failed to create session",
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1009,149 +1321,11275 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "github_com_gti_heatmap-internal_internal_models.AddAssigneeRequest": {
-            "type": "object",
-            "required": [
-                "assignees"
-            ],
-            "properties": {
-                "assignees": {
-                    "type": "array",
-                    "minItems": 1,
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Declare a date range, such as a release freeze or exam week, in which a person or group takes no new loads. Upserts newly assigning someone in their own or a group's blackout are warned about or rejected, per BLACKOUT_MODE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Add blackout dates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Blackout to add",
+                        "name": "blackout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateBlackoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created blackout",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.BlackoutDate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/blackouts/{blackout}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a blackout from a person or group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Delete blackout dates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Blackout ID",
+                        "name": "blackout",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid blackout ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Blackout not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/calendar.ics": {
+            "get": {
+                "description": "The loads of a person, or of any member of a group, from 90 days ago to a year ahead as an iCalendar (.ics) feed, to subscribe to from Google Calendar or Outlook. Each load is one all-day event over its days, with its assignees and weights in the description; recurring loads repeat through an RRULE. Private persons are not found for viewers who may not see their heatmap, and are left out of other feeds.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Entity calendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID (email for persons, string ID for groups)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/change-id": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move an entity to a new ID, such as a person whose email was renamed. Their loads, capacity, group memberships and ownerships, notes, history and logins move with them in one transaction; the old ID is then unknown, and shows up as deleted in GET /api/entities/changes. A person's new ID must be an email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Change an entity's ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Current entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New ID",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ChangeEntityIDRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entity moved",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ChangeEntityIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or a new ID that is the current one or, for a person, not an email",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "New ID already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/delete-preview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Counts what DELETE /api/entities/{id} would remove along with the entity: the person's load assignments, the loads not yet over that would lose them and how many of those would be left with no assignee, group memberships, capacity overrides, and weekly capacity. Nothing is changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Preview an entity deletion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "What the deletion would remove",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityDeletePreview"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/info": {
+            "put": {
+                "description": "Replace the freeform notes and important links, such as a team charter or on-call schedule, shown in a panel beside an entity's heatmap. Links are shown in the order given and must be http or https. Only admins (ADMIN_EMAILS) may.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notes"
+                ],
+                "summary": "Update entity notes and links",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notes, at most 5000 characters, and at most 20 links",
+                        "name": "info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateEntityInfoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notes and links after the change",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/entities/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Bring an archived person or group back into entity lists and heatmap selectors, with the loads, memberships and capacity it had. Assignments an offboarding removed stay removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entities"
+                ],
+                "summary": "Restore an archived entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Entity is not archived",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Every change to loads, capacity, entities and groups, oldest first, from the append-only domain event log. Pass the last event's id as after to read the next page. Each event lists the persons and groups whose data it changed under entity_ids; filter on one with entity.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List domain events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only events after this ID",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events that changed this person or group",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum events to return (default 100, at most 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Events",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DomainEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid after or limit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Load an org structure in one call, such as an HR export. Send JSON rows, or a CSV with Content-Type text/csv whose header names a \"group\" and a \"member\" column (other columns are ignored). Missing groups are created with their ID as title, and unknown members as persons with the default capacity from the settings (5.0 unless an admin changed it). Existing groups, persons and memberships are left as they are, so the same import can be repeated safely. Nothing is written if any row fails.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Import groups",
+                "parameters": [
+                    {
+                        "description": "Rows to import",
+                        "name": "import",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.GroupImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.GroupImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rows, or a name that is both a group and a member or has the other type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Group or person is archived",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/dashboard": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Allow a group's heatmap to be shown at /dashboard/{id} with member identities hidden and only aggregate utilization displayed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Enable group dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dashboard URL",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Entity is not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop showing a group's anonymized heatmap on public dashboards",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Disable group dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/heatmap-exclusions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the members whose loads the group's heatmap, utilization, digests and overload alerts leave out, such as a lead whose calendar is always full. Their own heatmaps are unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Get members excluded from a group heatmap",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Excluded members",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/heatmap-exclusions/{member}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Leave a member's loads out of the group's heatmap, utilization, digests and overload alerts, so a figurehead whose calendar is always full does not skew the team's. The member stays in the group and keeps their own heatmap. To leave members out of a single request instead, pass exclude to GET /api/heatmap/{entity}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Exclude member from group heatmap",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email to exclude",
+                        "name": "member",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not a member of the group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Count a member's loads in the group's heatmap, utilization, digests and overload alerts again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Include member in group heatmap again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email to include",
+                        "name": "member",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not a member of the group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/lark-digest": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the Lark chat a group's upcoming two weeks are posted to every Monday as a heatmap image, whether that is enabled, and the Monday of the latest post",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Get group Lark digest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lark digest",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LarkDigest"
+                        }
+                    },
+                    "400": {
+                        "description": "Entity is not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found, or it has no Lark digest",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Post a group's upcoming two weeks every Monday (LARK_DIGEST_AT, UTC) to a Lark chat as a heatmap image: a row for the group, then one for each member whose heatmap is not private, captioned with who is over capacity and linked back to the heatmaps. The Lark app must be in the chat. Set enabled to false to pause it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Set group Lark digest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lark chat ID, and whether the digest is enabled",
+                        "name": "digest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.PutLarkDigestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lark digest",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LarkDigest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, or entity is not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop posting a group's upcoming two weeks to its Lark chat",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Delete group Lark digest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Entity is not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found, or it has no Lark digest",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/least-loaded": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rank a group's active members by the capacity they would have left on a date after taking a load of the given weight, most first, for \"assign to whoever is free\" automations. Members the weight would overload are listed last with overloaded set. Nothing is written.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Find a group's least-loaded members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD), default today",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Weight of the new load, default 1.0",
+                        "name": "weight",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Members, most remaining capacity first",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LeastLoadedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date or weight, or not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns all members of a group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Get group members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group members",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a person to a group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Add member to group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member to add",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddGroupMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group or person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/members/{member}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a person from a group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Remove member from group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email to remove",
+                        "name": "member",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/owners": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the owners of a group, who approve its members' capacity changes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Get group owners",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group owners",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Make a person an owner of a group, able to approve its members' capacity changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Add owner to group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Owner to add",
+                        "name": "owner",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddGroupOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group or person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/owners/{owner}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a person from a group's owners",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Remove owner from group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner email to remove",
+                        "name": "owner",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/heatmap/{entity}": {
+            "get": {
+                "description": "Returns the heatmap grid partial for an entity, or, when the Accept header asks for application/json, its days as JSON. Cell tooltips name the loads a logged-in viewer pinned, and loads from the sources they hide are left out. Given a tag, only loads tagged with it count. For groups, loads of the members listed in exclude do not count, on top of the members the group always excludes. The heatmap covers 1 month back to 6 months ahead, or from from to to, either defaulting to that window's end, or days days from from or today; a range spans at most 366 days.",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get heatmap partial for entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only count loads with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated emails of group members whose loads do not count",
+                        "name": "exclude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days from from, or from today; not with to",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML partial for heatmap grid, or the heatmap days as JSON",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HeatmapData"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Heatmap version"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last change to the heatmap"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Heatmap unchanged since the given ETag or date"
+                    },
+                    "400": {
+                        "description": "Invalid or too long date range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found, or private and not visible to the viewer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load heatmap",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/heatmap/{entity}/day/{date}": {
+            "get": {
+                "description": "Returns the tasks/loads, with the logged-in viewer's pinned loads first, and notes for a specific day, as an HTML partial or, when the Accept header asks for application/json, as JSON. For a group, the loads are also grouped per member under members, each with the member's subtotal against their own capacity, fullest first. Assignments of private persons the viewer may not see are left out, though the day's total load still counts them. Each load lists, under fields, its custom fields that are defined for its source to be displayed. A load whose time window, from its start_time and end_time custom fields, overlaps another's for an assignee they share lists the other loads' IDs under conflicts and is badged; in a group's member sections only the member's own loads count. When any load is tagged, tags breaks the loads down per tag, each with its color for the legend. Given a tag, only loads tagged with it are listed and totaled. For groups, as on their heatmap, assignments and member sections of the members listed in exclude and of those the group always excludes are left out and not totaled.",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get day details for entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date in YYYY-MM-DD format",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only list loads with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated emails of group members whose loads do not count",
+                        "name": "exclude",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML partial for day details, or its JSON form",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DayDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found, or private and not visible to the viewer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load day details",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/heatmap/{entity}/day/{date}/members": {
+            "get": {
+                "description": "Returns each active member of a group with their own total load and capacity on a day, the color their day has on their heatmap, and whether they are overloaded, fullest first, as an HTML partial expanded from the group's day details or, when the Accept header asks for application/json, as JSON. Members whose heatmap is private to the viewer are left out.",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get a group's per-member day breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date in YYYY-MM-DD format",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML partial of the members, or its JSON form",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.GroupDayBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date format, or not a group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found, or private and not visible to the viewer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load the breakdown",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/heatmap/{entity}/json": {
+            "get": {
+                "description": "Returns an entity's heatmap as JSON, for dashboards and single-page apps: the entity and, for each day of the window, its total load, capacity and color, leaving out loads from the sources a logged-in viewer hides, given a tag, loads without it, and for groups, loads of the members listed in exclude and of those the group always excludes. from, to and days choose the days as for GET /api/heatmap/{entity}. The same as GET /api/heatmap/{entity} with Accept: application/json, without having to set the header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get heatmap data for entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only count loads with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated emails of group members whose loads do not count",
+                        "name": "exclude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days from from, or from today; not with to",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Heatmap data",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HeatmapData"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Heatmap version"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last change to the heatmap"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Heatmap unchanged since the given ETag or date"
+                    },
+                    "400": {
+                        "description": "Invalid or too long date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found, or private and not visible to the viewer",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/heatmap/{entity}/reports": {
+            "get": {
+                "description": "Returns one heatmap grid for everyone reporting to a person, directly or through their own managers, following manager_email from directory sync. Each day shows the reports' summed load as a share of their summed capacity; no explicit group is needed.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get roll-up heatmap partial for a manager",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manager's email",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML partial for the roll-up heatmap grid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Manager not found or has no reports",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load heatmap",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holidays/{region}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List a region's public holidays in a year, in date order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holidays"
+                ],
+                "summary": "List holidays",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Region, such as a country code",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year, default this year",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Holidays",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Holiday"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid region or year",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holidays/{region}/fetch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import a region's public holidays of a year from the holidays API (HOLIDAYS_API_URL, Nager.Date by default, where regions are ISO 3166-1 alpha-2 country codes such as ID). Holidays of only some of the region's provinces are left out. Holidays already stored for a day are renamed; others are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holidays"
+                ],
+                "summary": "Import holidays from the holidays API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Region, such as a country code",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Year to import",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.FetchHolidaysRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Imported holidays",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HolidayImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid region or year, or no holidays found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, or the holidays API failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holidays/{region}/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Store the events of an iCalendar (.ics) file, such as a public holidays calendar exported from Google Calendar, as a region's holidays. Each day of a multi-day event is a holiday named by its SUMMARY. Holidays already stored for a day are renamed; others are kept. Persons and groups whose region is set have no capacity on their region's holidays, and heatmaps mark them.",
+                "consumes": [
+                    "text/calendar"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holidays"
+                ],
+                "summary": "Import holidays from a calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Region, such as a country code",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "iCalendar file, at most 1 MiB",
+                        "name": "calendar",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Imported holidays",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HolidayImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid region or calendar",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holidays/{region}/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove one of a region's holidays, such as one a calendar listed by mistake, giving persons and groups of the region their capacity back that day",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holidays"
+                ],
+                "summary": "Delete a holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Region, such as a country code",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Holiday date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Holiday deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid region or date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Holiday not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/integrations/health": {
+            "get": {
+                "description": "Per source system: the last successful load sync, loads synced in the past 24 hours, failed upserts in the past 24 hours and the latest few of them. Per webhook event: deliveries and failures in the past 24 hours, the success rate, and the latest failure. Sources with errors are listed first. Failures are kept for a week.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Integration health",
+                "responses": {
+                    "200": {
+                        "description": "Integration health",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IntegrationHealthReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "A bulk operation's status (queued, running, succeeded or failed), how many of its items it has processed and how many failed, and the first 1000 failed items with their errors. A job fails as a whole only when it cannot go on, such as when the server stops; the reason is under error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get a background job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid job ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List loads on any day of a date range with their assignees, ordered by first day and id, optionally only those from one source, assigned to one person, or assigned to any member of one group. When more loads follow, next_cursor is set; pass it as cursor, with the same filters, for the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "List loads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD, default today)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD, default 30 days after from)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only loads from this source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only loads assigned to this email",
+                        "name": "assignee",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only loads assigned to a member of this group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum loads to return (default 100, at most 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Loads",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date, cursor or limit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/bulk-upsert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upsert up to 10000 loads, each as POST /api/loads/upsert would, tombstones included, in a background job. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. A load that fails does not stop the others; it is counted under failed and listed under errors by its index and external_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Upsert loads in bulk",
+                "parameters": [
+                    {
+                        "description": "Loads to upsert",
+                        "name": "loads",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.BulkUpsertLoadsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued job",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/by-external-id/{external_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete the load a source system knows by this external ID, e.g. a deleted calendar event (for n8n integration). The same can be sent to the upsert endpoints as a tombstone, {\"external_id\": ..., \"deleted\": true}. For an upcoming load, each assignee's load and capacity on its date are re-checked and sent as a load_deleted webhook.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Delete a load by external ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "External ID of the load, URL-encoded",
+                        "name": "external_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted load and its former assignees",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeletedLoad"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid external ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/from-issue": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Point a Jira webhook for issue created, updated and deleted events here, or send the same shape from another tracker, to keep sprint work on the heatmap. The issue key is the load's external_id and its source is jira; the load falls on the issue's due date (fields.duedate), assigned to fields.assignee.emailAddress and titled with the key and fields.summary. Its weight is the issue's story points, from ISSUE_STORY_POINTS_FIELD (default customfield_10016), times ISSUE_POINT_WEIGHT (default 1); without points, fields.timeoriginalestimate is the duration the weight rules go by. An issue that is deleted, done (status category done), unassigned or without a due date has its load deleted, or is ignored when it has none. Blackout dates, auto-created assignees and the assignees' resulting loads are handled as for /api/loads/upsert.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Upsert a load from an issue webhook",
+                "parameters": [
+                    {
+                        "description": "Jira-style issue webhook",
+                        "name": "issue",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IssueWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Whether the issue's load was upserted, deleted or ignored, and the upsert's response",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IssueLoadResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, issue key or due date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown assignee while auto-creation is disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Assignee on a blackout date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Source reached its daily quota of auto-created persons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/reassign": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move every load assigned to from that lasts through from_date (default today), optionally only those of one source, to to, in a background job. to must be an active person; where they already share a load, the weights are added together. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. Loads that fail are listed under errors by their ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Reassign a person's loads",
+                "parameters": [
+                    {
+                        "description": "Persons to reassign between",
+                        "name": "reassign",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ReassignLoadsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued job",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or from_date, or to is not a person",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "to is offboarded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/stale": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List upcoming loads the stale load check flagged because their source has not upserted them within its freshness window (STALE_LOAD_WINDOW, STALE_LOAD_SOURCE_WINDOWS), oldest first, with a count per source. Upserting a load again clears its flag. Review the list, then remove dead loads with POST /api/loads/stale/delete.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "List stale loads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only loads from this source",
+                        "name": "source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stale loads",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.StaleLoadReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/stale/delete": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete the listed loads that are still flagged stale, after reviewing GET /api/loads/stale. Loads upserted again since the review, already deleted, or now in the past are kept and returned as skipped, as are loads outside a group-scoped API key's groups. Each deleted upcoming load sends load_deleted webhooks like a deletion from its source.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Delete stale loads",
+                "parameters": [
+                    {
+                        "description": "Reviewed load IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeleteStaleLoadsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted and skipped loads",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeleteStaleLoadsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/upsert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create or update a load item with assignments (for n8n integration). New assignments on an assignee's or their group's blackout dates are listed under blackouts, or rejected with 409 when BLACKOUT_MODE is reject. assignees lists each assignee's resulting total load and capacity on each day of the load, the first occurrence of a recurring one, and whether they are overloaded, so workflows can branch on overloads without waiting for the overload_alert webhook. A tombstone, {\"external_id\": ..., \"deleted\": true}, deletes the load instead, as DELETE /api/loads/by-external-id/{external_id} does, answering with the deleted load or 404. A load spanning several days sets end_date; its weights are split evenly across the days, or with spread per_day counted in full on each. A recurrence repeats the load daily, weekly or monthly, every interval days, weeks or months from date, until a day or for count occurrences, or without end; the server expands its occurrences, so the source only sends the load once. Unknown assignees are created as persons and queued for review at GET /api/people/auto-created, up to AUTO_CREATE_DAILY_LIMIT a day per source; with AUTO_CREATE_PERSONS=false they are rejected instead. custom_fields are kept with the load as sent; those defined for the source at /api/custom-fields must have the defined type. Loads from a source in shadow mode, see /api/shadow-sources, are kept in its scenario instead and answer with shadow true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Upsert a load",
+                "parameters": [
+                    {
+                        "description": "Load data to upsert",
+                        "name": "load",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Load ID, blackouts the new assignments fall on, and each assignee's resulting load and capacity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, dates, recurrence, custom fields or confidential group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tombstoned load not found, or an unknown assignee while auto-creation is disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Assignee on a blackout date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Source reached its daily quota of auto-created persons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/upsert-by-employee-id": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create or update a load item with assignments using employee_id instead of email. Blackout dates, multi-day and recurring loads, custom fields, tombstones and the assignees' resulting loads are handled as for /api/loads/upsert.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Upsert a load by employee ID",
+                "parameters": [
+                    {
+                        "description": "Load data to upsert",
+                        "name": "load",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadByEmployeeIDRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Load ID, blackouts the new assignments fall on, and each assignee's resulting load and capacity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, dates, recurrence, custom fields or confidential group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Assignee, or tombstoned load, not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Assignee on a blackout date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/acknowledge": {
+            "post": {
+                "description": "Confirm that the currently logged-in user has seen a load assigned to them. Acknowledging again keeps the original time. Unacknowledged loads may trigger a reminder webhook.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Acknowledge load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acknowledgment",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AcknowledgeLoadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid load ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not assigned to this load",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/actual": {
+            "post": {
+                "description": "Record the effort the currently logged-in user actually spent on a load assigned to them, in the same units as weights, once the load's date has come. The current weight is kept next to it as the planned effort; recording again replaces both. Feeds the calibration report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Record actual effort",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Actual effort",
+                        "name": "actual",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RecordActualRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recorded effort",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadActual"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or load not yet happened",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not assigned to this load",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/assignees": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add one or more assignees to an existing load with optional weight. Unknown assignees are created and queued for review as for /api/loads/upsert, counting against the quota of the load's source.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Add assignees to a load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Assignees to add",
+                        "name": "assignees",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddAssigneeRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or an unknown assignee while auto-creation is disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Source reached its daily quota of auto-created persons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/assignees/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a specific assignee from a load",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Remove assignee from load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Assignee email to remove",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Load or assignee not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/notes": {
+            "post": {
+                "description": "Attach a short comment to a load as the currently logged-in user. Notes are shown in the day details of the load's assignees and groups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notes"
+                ],
+                "summary": "Add load note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note text, at most 280 characters",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateLoadNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created note",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loads/{id}/pin": {
+            "post": {
+                "description": "Pin a load for the currently logged-in user, so it is listed first in day details and named in the heatmap cell tooltip when they view it. Pinning again keeps the original time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pins"
+                ],
+                "summary": "Pin load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pin",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Pin"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid load ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the currently logged-in user's pin on a load",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pins"
+                ],
+                "summary": "Unpin load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid load ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Pin not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-capacity": {
+            "post": {
+                "description": "Update capacity settings for the currently logged-in user, or for a person who delegated their capacity to them. A weekly pattern sets the capacity of a weekday (monday .. sunday) in every week, used unless a date override applies; a null capacity clears the weekday. When capacity approval is on, lowering the default capacity, setting a weekday below it, or setting a long run of zero-capacity days is held for an owner of one of the user's groups to approve.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Update user capacity",
+                "parameters": [
+                    {
+                        "description": "Capacity update request",
+                        "name": "capacity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateCapacityRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of a person who delegated their capacity to the user; default the user",
+                        "name": "person",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Change held for approval",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the person's assistant",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-capacity/audit": {
+            "get": {
+                "description": "List the most recent changes to the capacity of the currently logged-in user, or of a person who delegated their capacity to them, newest first. Each entry names both the person and who made the change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Capacity audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email of a person who delegated their capacity to the user; default the user",
+                        "name": "person",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit entries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityAuditEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the person's assistant",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-capacity/override/{date}": {
+            "delete": {
+                "description": "Delete a specific date override for the currently logged-in user, or for a person who delegated their capacity to them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Delete capacity override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date in YYYY-MM-DD format",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of a person who delegated their capacity to the user; default the user",
+                        "name": "person",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the write and answer with what it would do, as a DryRunResult, without making it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the person's assistant",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-capacity/overrides": {
+            "delete": {
+                "description": "Remove every date override from from to to, both included, for the currently logged-in user, or for a person who delegated their capacity to them, to undo mistaken vacation entries in one call. Answers with how many were removed; the removal is audited as one entry.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Delete capacity overrides in a range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of a person who delegated their capacity to the user; default the user",
+                        "name": "person",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Overrides removed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeleteOverridesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the person's assistant",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-delegations": {
+            "get": {
+                "description": "List who manages the currently logged-in user's capacity and whose capacity they manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "List delegations",
+                "responses": {
+                    "200": {
+                        "description": "Delegations",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DelegationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Let another person manage the currently logged-in user's capacity. Their changes are audited under both identities.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Add delegation",
+                "parameters": [
+                    {
+                        "description": "Assistant to add",
+                        "name": "delegation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddDelegationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delegation",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Delegation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Assistant not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-delegations/{assistant}": {
+            "delete": {
+                "description": "Stop another person managing the currently logged-in user's capacity",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Remove delegation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistant email",
+                        "name": "assistant",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Delegation not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-loads/unacknowledged": {
+            "get": {
+                "description": "List upcoming loads assigned to the currently logged-in user that they have not acknowledged yet, soonest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Unacknowledged loads",
+                "responses": {
+                    "200": {
+                        "description": "Unacknowledged loads",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.PendingAcknowledgment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-notes": {
+            "post": {
+                "description": "Attach a short note, such as \"travel day\", to a day of the currently logged-in user. It is shown in their day details and those of their groups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notes"
+                ],
+                "summary": "Add day note",
+                "parameters": [
+                    {
+                        "description": "Day and note text, at most 280 characters",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateDayNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created note",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-notes/{id}": {
+            "delete": {
+                "description": "Remove a load or day note written by the currently logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notes"
+                ],
+                "summary": "Delete note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid note ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Note not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-pins": {
+            "get": {
+                "description": "List the loads the currently logged-in user pinned, from today on, in date order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pins"
+                ],
+                "summary": "List pins",
+                "responses": {
+                    "200": {
+                        "description": "Pins",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Pin"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/my-preferences": {
+            "get": {
+                "description": "Get how heatmap grids are laid out for the currently logged-in user, the weekday each week starts on, their own choice or the server's WEEK_START, whether their own heatmap is private, and the load sources left out of the heatmaps they view",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Get heatmap preferences",
+                "responses": {
+                    "200": {
+                        "description": "Preferences",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HeatmapPreferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not a person",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set the weekday (monday .. sunday) the currently logged-in user's heatmap weeks start on, for every heatmap they view. Grid columns follow it and rows are labelled with ISO week numbers. A null or missing week_start follows the server's WEEK_START again. private: true hides the user's heatmap from everyone but themselves, owners of their groups, and admins; omitted, it is left unchanged. hidden_sources replaces the load sources (e.g. gcal) left out of every heatmap the user views, an empty list showing them all again; omitted, it is left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Heatmap"
+                ],
+                "summary": "Update heatmap preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateHeatmapPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated preferences",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HeatmapPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid weekday",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not a person",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/people/auto-created": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Persons created because an upsert named an unknown assignee, oldest first, with the source of the upsert and how many loads they are assigned. Confirm the real ones with POST /api/people/auto-created/confirm and delete mistyped emails with POST /api/people/auto-created/reject.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "List auto-created persons",
+                "responses": {
+                    "200": {
+                        "description": "Persons waiting for review",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AutoCreatedPerson"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/people/auto-created/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Keep the listed auto-created persons and take them off the review queue. They still count against their source's quota for the day they were created. Persons not waiting for review are listed as skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Confirm auto-created persons",
+                "parameters": [
+                    {
+                        "description": "Reviewed emails",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmed and skipped persons",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/people/auto-created/reject": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete the listed auto-created persons, such as mistyped emails, with their load assignments. Persons already confirmed, or no longer there, are kept and listed as skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Reject auto-created persons",
+                "parameters": [
+                    {
+                        "description": "Reviewed emails",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted and skipped persons",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/people/onboard": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a person, add them to groups, and set their capacity with an optional ramp-up",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Onboard a person",
+                "parameters": [
+                    {
+                        "description": "Person to onboard",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OnboardPersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Onboarded person",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OnboardPersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Entity already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/people/{email}/offboard": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Archive a person, remove them from loads after their last day, and notify the webhook destination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Offboard a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Last working day",
+                        "name": "offboarding",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OffboardPersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Offboarding result",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OffboardPersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Person already offboarded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/presence/{kind}/{id}": {
+            "post": {
+                "description": "Record that the currently logged-in user is viewing or editing an entity (a person or group) or a load, and list who else has been on it in the last 30 seconds, editors first. Pages poll this every 10 seconds; HTMX requests get the \"X is also editing\" indicator as HTML.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "Presence"
+                ],
+                "summary": "Presence heartbeat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "What is viewed or edited: entity or load",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID or load ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "viewing (default) or editing",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Who else is on it, or the HTML indicator",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.PresenceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid kind or mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity or load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rebalance/{group}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Dry-run plan of load moves between members that resolves overloads on a date without overloading anyone else",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Propose a group rebalance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD), default today",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Proposed moves",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RebalancePlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date or not a group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/calibration": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per source, the weights planned for assignments whose assignees recorded the effort they actually spent, next to those actuals: totals, their ratio (above 1 means the source's loads are underestimated), and the mean absolute error. Use it to tune weight rules.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Effort calibration report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First load date, YYYY-MM-DD, default 90 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last load date, YYYY-MM-DD, default today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calibration report",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CalibrationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/overload-resolution": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per group, how many member person-days between from and to went over capacity, how many were brought back under, stayed open, or passed unresolved, and the mean time to resolution",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Overload resolution report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), default 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), default today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resolution report",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OverloadResolutionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/utilization": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per person and per group over a quarter: average load and utilization, p95 daily load, overloaded-day count, and top sources by load. Finished quarters are stored when first generated and served unchanged afterwards; the current quarter is reported up to today. Private persons are left out of the people; group summaries still count them.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Quarterly utilization report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quarter as YYYY-QN, default the last finished quarter",
+                        "name": "quarter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Utilization report",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UtilizationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid quarter or format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios": {
+            "get": {
+                "description": "List all what-if scenarios, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "List scenarios",
+                "responses": {
+                    "200": {
+                        "description": "List of scenarios",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Scenario"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a named what-if scenario",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Create a scenario",
+                "parameters": [
+                    {
+                        "description": "Scenario to create",
+                        "name": "scenario",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateScenarioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created scenario",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Scenario"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Scenario name already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}": {
+            "get": {
+                "description": "Get a what-if scenario with its hypothetical loads and capacities",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Get a scenario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scenario details",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ScenarioDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scenario ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a what-if scenario with its hypothetical loads and capacities",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Delete a scenario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scenario ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}/capacity": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set an entity's hypothetical capacity on a date, replacing the real one in the scenario",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Set a scenario capacity",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Hypothetical capacity",
+                        "name": "capacity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SetScenarioCapacityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scenario capacity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ScenarioCapacity"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario or entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}/capacity/{entity}/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove an entity's hypothetical capacity on a date, so the real one applies in the scenario",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Delete a scenario capacity",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date in YYYY-MM-DD format",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scenario ID or date",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}/heatmap/{entity}": {
+            "get": {
+                "description": "Returns the heatmap grid partial for an entity with the scenario applied",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Get scenario heatmap partial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML partial for the scenario heatmap grid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scenario ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario or entity not found, or the entity is private and not visible to the viewer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load heatmap",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}/loads": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a hypothetical load with its assignees to a scenario",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Add a scenario load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Hypothetical load",
+                        "name": "load",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AddScenarioLoadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Updated scenario",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ScenarioDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario or person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scenarios/{id}/loads/{load}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove one hypothetical load assignment from a scenario",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Delete a scenario load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scenario ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Scenario load ID",
+                        "name": "load",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Scenario load not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/settings": {
+            "put": {
+                "description": "Change settings, taking effect at once on this server and within 30 seconds on others. Omitted fields are kept. color_thresholds are the five ascending load-to-capacity ratios above which a heatmap day turns lime, amber, orange, red and blood red (default 0.2, 0.4, 0.6, 0.8, 1.0). default_capacity is the daily capacity of persons and groups created without one (default 5.0). alert_cooldown_minutes is how long overload alerts about the same person or group and day are not sent again, up to a week (default 0, no cooldown). webhook_secret, when set, signs every webhook delivery in the X-Heatmap-Signature header as \"sha256=\" and the hex HMAC-SHA256 of the body; an empty string removes it. Every change is recorded in the event log as settings.updated, without the secret. Only admins (ADMIN_EMAILS) may.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update settings",
+                "parameters": [
+                    {
+                        "description": "Settings to change",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settings after the change",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid settings",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shadow-sources": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the sources in shadow mode, with the scenario their upserted loads are kept in and how many scenario loads, one per assignee and day, it holds",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "List shadow sources",
+                "responses": {
+                    "200": {
+                        "description": "Sources in shadow mode",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ShadowSource"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Register a new connector's source in shadow mode: the loads it upserts, and its tombstones, go to a scenario named \"Shadow: \u003csource\u003e\" instead of to loads, so they show only on that scenario's heatmaps and never in real totals, reports or webhooks. Weight rules and custom field checks apply as for live upserts; unknown assignees are rejected rather than created. Upserts answer with shadow true and no load ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Put a source in shadow mode",
+                "parameters": [
+                    {
+                        "description": "Source to put in shadow mode",
+                        "name": "source",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateShadowSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Source in shadow mode",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ShadowSource"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Source already in shadow mode, or its scenario name is taken",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shadow-sources/{source}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Take a source out of shadow mode, so its upserts are saved as loads again. Its scenario is kept to compare with; have the connector send its loads again to bring them live. Deleting the scenario also takes the source live.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scenarios"
+                ],
+                "summary": "Take a source live",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Source not in shadow mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/snapshots/backfill": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Discard and recompute the heatmap snapshots of the given entities, or of every entity when entity_ids is empty, one entity at a time in a background job. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. Entities that fail, or do not exist, are listed under errors by their ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Backfill heatmap snapshots",
+                "parameters": [
+                    {
+                        "description": "Entities to backfill",
+                        "name": "backfill",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.BackfillSnapshotsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued job",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/status/incidents": {
+            "post": {
+                "description": "Post a note about an incident, open until resolved, to the public /status page. Only admins (ADMIN_EMAILS) may.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Status"
+                ],
+                "summary": "Add incident note",
+                "parameters": [
+                    {
+                        "description": "Note text, at most 1000 characters",
+                        "name": "incident",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateIncidentNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created incident note",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IncidentNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/status/incidents/{id}": {
+            "put": {
+                "description": "Change an incident note's text, or resolve or reopen it. Omitted fields are kept. Only admins (ADMIN_EMAILS) may.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Status"
+                ],
+                "summary": "Update incident note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "incident",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateIncidentNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated incident note",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IncidentNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Incident note not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove an incident note from the status page. Only admins (ADMIN_EMAILS) may.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Status"
+                ],
+                "summary": "Delete incident note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid incident note ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Incident note not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/suggest-assignee": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rank active persons with a skill by remaining capacity on a date, leaving out anyone the weight would overload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loads"
+                ],
+                "summary": "Suggest assignees",
+                "parameters": [
+                    {
+                        "description": "Date, weight, and required skill",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SuggestAssigneeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ranked suggestions",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SuggestAssigneeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/utilization": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "One flat row per entity and day of a date range, with the day's load, capacity, and their ratio (null on days without capacity), for ingestion by BI tools such as Metabase or Power BI. Rows are ordered by entity, as listed, then by date. When more rows follow, next_cursor is set; pass it as cursor, with the same entities and range, for the next page. A group-scoped API key may only list its groups and their members.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List daily utilization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated person emails and group IDs",
+                        "name": "entities",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD, default today)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD, default 30 days after from)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum rows to return (default 1000, at most 10000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Utilization rows",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UtilizationList"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing entities, or invalid date, cursor or limit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Outside the API key's groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Entity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every webhook endpoint with the events it subscribes to and whether it is enabled. WEBHOOK_DESTINATION_URL, if set, is not listed; it receives every event.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook endpoints",
+                "responses": {
+                    "200": {
+                        "description": "Webhook endpoints",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WebhookEndpoint"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a URL to POST the subscribed events to: overload_alert, group_overload_alert, load_created, load_deleted, capacity_changed, person_offboarded, load_unacknowledged, schedule_conflict or anomaly. The endpoint is enabled unless enabled is false.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Add a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Webhook endpoint to add",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CreateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created webhook endpoint",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, URL or event",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a webhook endpoint with the events it subscribes to and whether it is enabled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook endpoint",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook endpoint ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change a webhook endpoint's URL, description or events, or enable or disable it. Omitted fields are kept; events, when given, replaces the subscribed events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated webhook endpoint",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook endpoint ID, request body, URL or event",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop delivering events to a webhook endpoint and remove it. Its past deliveries stay in the integration health report.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook endpoint ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for group-scoped API keys",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Server busy, retry later",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "End the current session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to home page"
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Where the identity provider sends the user back. The ID token's signature, issuer, audience, expiry and nonce are checked, and its email must be verified and belong to a registered, active person, as for OTP. Redirects home with a session cookie; failures render the login page.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Finish single sign-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of the login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Why the identity provider did not sign the user in",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to home page with a session"
+                    },
+                    "400": {
+                        "description": "Login expired or not started from this browser",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Sign-in refused or not confirmed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Email not found in system",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Single sign-on is not configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider unreachable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirect to the OpenID Connect identity provider (OIDC_ISSUER_URL), such as Google Workspace or Azure AD, to sign in by the authorization code flow with PKCE. The login must be finished at /auth/oidc/callback within 10 minutes from the same browser.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Start single sign-on",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    },
+                    "404": {
+                        "description": "Single sign-on is not configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider unreachable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/request-otp": {
+            "post": {
+                "description": "Send an OTP code to the user's email for authentication. Each email may be sent OTP_REQUEST_LIMIT codes an hour, and each client IP may call this OTP_IP_LIMIT times an hour.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Request OTP",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid email",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Email not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many OTP requests for the email or from the client IP",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to send OTP",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-otp": {
+            "post": {
+                "description": "Verify the OTP and create a session. An OTP is dropped after 5 wrong codes, and each client IP may call this OTP_IP_LIMIT times an hour.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify OTP",
+                "parameters": [
+                    {
+                        "description": "Email and OTP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.VerifyOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP verified, session created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes for the OTP, or calls from the client IP",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create session",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns 200 when the service and its database are reachable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "status: ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Database unreachable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Overload tracking counters in the Prometheus text format: open, resolved, and unresolved person-days and total time to resolution",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Overload metrics",
+                "responses": {
+                    "200": {
+                        "description": "Metrics in the Prometheus text format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/my-capacity": {
+            "get": {
+                "description": "Renders the capacity management page for the currently logged-in user, or for a person who delegated their capacity to them, or, when the Accept header asks for application/json, returns the same settings as JSON: default capacity, date overrides, weekly pattern, and capacity changes awaiting approval",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Get capacity settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email of a person who delegated their capacity to the user; default the user",
+                        "name": "person",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Capacity page, or its settings as JSON",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacitySettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the person's assistant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load capacity data",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings": {
+            "get": {
+                "description": "Renders the settings page, or, when the Accept header asks for application/json, returns the settings as JSON: heatmap color thresholds, the default capacity of new persons and groups, the overload alert cooldown, and whether a webhook secret is set. The secret itself is never returned. Only admins (ADMIN_EMAILS) may.",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get settings",
+                "responses": {
+                    "200": {
+                        "description": "Settings page, or the settings as JSON",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Settings"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_gti_heatmap-internal_internal_models.APIKeyMetric": {
+            "type": "object",
+            "properties": {
+                "client_errors": {
+                    "description": "4xx responses",
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "error_rate": {
+                    "description": "Share of requests answered with an error",
+                    "type": "number"
+                },
+                "key": {
+                    "description": "api_key, groups:\u003cids\u003e for group-scoped keys, or unauthenticated",
+                    "type": "string"
+                },
+                "request_bytes": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "response_bytes": {
+                    "type": "integer"
+                },
+                "server_errors": {
+                    "description": "5xx responses",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.APIKeyMetricsReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.APIKeyMetric"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AcknowledgeLoadResponse": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AddAssigneeRequest": {
+            "type": "object",
+            "required": [
+                "assignees"
+            ],
+            "properties": {
+                "assignees": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "object",
+                        "required": [
+                            "email"
+                        ],
+                        "properties": {
+                            "email": {
+                                "type": "string"
+                            },
+                            "weight": {
+                                "description": "Default 1.0",
+                                "type": "number"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AddDelegationRequest": {
+            "type": "object",
+            "required": [
+                "assistant_email"
+            ],
+            "properties": {
+                "assistant_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AddGroupMemberRequest": {
+            "type": "object",
+            "required": [
+                "person_email"
+            ],
+            "properties": {
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AddGroupOwnerRequest": {
+            "type": "object",
+            "required": [
+                "person_email"
+            ],
+            "properties": {
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AddScenarioLoadRequest": {
+            "type": "object",
+            "required": [
+                "assignees",
+                "date",
+                "title"
+            ],
+            "properties": {
+                "assignees": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "object",
+                        "required": [
+                            "email"
+                        ],
+                        "properties": {
+                            "email": {
+                                "type": "string"
+                            },
+                            "weight": {
+                                "description": "Default 1.0",
+                                "type": "number"
+                            }
+                        }
+                    }
+                },
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AssigneeDayLoad": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "overloaded": {
+                    "type": "boolean"
+                },
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AssigneeSuggestion": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "remaining": {
+                    "description": "Capacity minus load, before the new load",
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.AutoCreatedPerson": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "loads": {
+                    "description": "loads assigned to them now",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "source of the upsert, empty when it did not name one",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.BackfillSnapshotsRequest": {
+            "type": "object",
+            "properties": {
+                "entity_ids": {
+                    "description": "Default every entity",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.BlackoutConflict": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "description": "Entity that declared the blackout",
+                    "type": "string"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.BlackoutDate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Format: YYYY-MM-DD, inclusive",
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.BulkUpsertLoadsRequest": {
+            "type": "object",
+            "required": [
+                "loads"
+            ],
+            "properties": {
+                "loads": {
+                    "type": "array",
+                    "maxItems": 10000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadRequest"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CalibrationReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SourceCalibration"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacityAuditAction": {
+            "type": "string",
+            "enum": [
+                "update",
+                "request",
+                "delete_override",
+                "approve",
+                "reject"
+            ],
+            "x-enum-varnames": [
+                "CapacityAuditUpdate",
+                "CapacityAuditRequest",
+                "CapacityAuditDeleteOverride",
+                "CapacityAuditApprove",
+                "CapacityAuditReject"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacityAuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityAuditAction"
+                },
+                "actor_email": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpdateCapacityRequest"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why the change needs approval",
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeStatus"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacityChangeStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "CapacityChangePending",
+                "CapacityChangeApproved",
+                "CapacityChangeRejected"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacityOverride": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CapacitySettings": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "description": "changes the user may approve, empty when acting for someone else",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                    }
+                },
+                "entity": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityOverride"
+                    }
+                },
+                "pending_changes": {
+                    "description": "the person's own changes awaiting approval",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CapacityChangeRequest"
+                    }
+                },
+                "weekly_pattern": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WeekdayCapacity"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ChangeEntityIDRequest": {
+            "type": "object",
+            "required": [
+                "new_id"
+            ],
+            "properties": {
+                "new_id": {
+                    "description": "A person's new email",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ChangeEntityIDResponse": {
+            "type": "object",
+            "properties": {
+                "new_id": {
+                    "type": "string"
+                },
+                "old_id": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "description": "Logins kept under the new ID",
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityType"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateBlackoutRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "description": "Format: YYYY-MM-DD, default start_date",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateDayNoteRequest": {
+            "type": "object",
+            "required": [
+                "body",
+                "date"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 280
+                },
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateEntityRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "skills",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "capacity_mode": {
+                    "description": "Default static; members only for groups",
+                    "type": "string",
+                    "enum": [
+                        "static",
+                        "members"
+                    ]
+                },
+                "default_capacity": {
+                    "type": "number"
+                },
+                "employee_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manager_email": {
+                    "type": "string"
+                },
+                "private": {
+                    "type": "boolean"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "person",
+                        "group"
+                    ]
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateIncidentNoteRequest": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateLoadNoteRequest": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 280
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateScenarioRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateShadowSourceRequest": {
+            "type": "object",
+            "required": [
+                "source"
+            ],
+            "properties": {
+                "source": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CreateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Default true",
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CustomField": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "display": {
+                    "description": "Shown in day details",
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Order in day details, lowest first",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CustomFieldType"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.CustomFieldType": {
+            "type": "string",
+            "enum": [
+                "string",
+                "number",
+                "boolean",
+                "url"
+            ],
+            "x-enum-comments": {
+                "CustomFieldURL": "An http(s) link, shown as one"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "An http(s) link, shown as one"
+            ],
+            "x-enum-varnames": [
+                "CustomFieldString",
+                "CustomFieldNumber",
+                "CustomFieldBoolean",
+                "CustomFieldURL"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.CustomFieldValue": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CustomFieldType"
+                },
+                "value": {}
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DayDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "loads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadWithAssignments"
+                    }
+                },
+                "members": {
+                    "description": "Groups only: the loads per member",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.MemberDayLoad"
+                    }
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Note"
+                    }
+                },
+                "tags": {
+                    "description": "Each tag's share of the loads, when any is tagged",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.TagLoad"
+                    }
+                },
+                "total_load": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Delegation": {
+            "type": "object",
+            "properties": {
+                "assistant_email": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DelegationsResponse": {
+            "type": "object",
+            "properties": {
+                "assistants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Delegation"
+                    }
+                },
+                "delegators": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Delegation"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DeleteOverridesResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DeleteStaleLoadsRequest": {
+            "type": "object",
+            "required": [
+                "load_ids"
+            ],
+            "properties": {
+                "load_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DeleteStaleLoadsResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DeletedLoad"
+                    }
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DeletedLoad": {
+            "type": "object",
+            "properties": {
+                "assignees": {
+                    "description": "People whose capacity is re-checked",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "shadow": {
+                    "description": "Removed from the source's shadow scenario; load_id is 0",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DomainEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "who made the change, when known",
+                    "type": "string"
+                },
+                "entity_ids": {
+                    "description": "persons and groups whose data changed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.DomainEventType"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.DomainEventType": {
+            "type": "string",
+            "enum": [
+                "load.upserted",
+                "load.deleted",
+                "load.assignees_added",
+                "load.assignee_removed",
+                "load.acknowledged",
+                "load.actual_recorded",
+                "load.reassigned",
+                "capacity.changed",
+                "capacity.requested",
+                "capacity.decided",
+                "delegation.added",
+                "delegation.removed",
+                "blackout.added",
+                "blackout.deleted",
+                "entity.created",
+                "entity.updated",
+                "entity.deleted",
+                "entity.archived",
+                "entity.restored",
+                "entity.id_changed",
+                "entity.preferences_updated",
+                "person.onboarded",
+                "person.offboarded",
+                "persons.confirmed",
+                "persons.rejected",
+                "groups.imported",
+                "group.member_added",
+                "group.member_removed",
+                "group.member_excluded",
+                "group.member_included",
+                "group.owner_added",
+                "group.owner_removed",
+                "group.dashboard_enabled",
+                "group.dashboard_disabled",
+                "settings.updated"
+            ],
+            "x-enum-varnames": [
+                "EventLoadUpserted",
+                "EventLoadDeleted",
+                "EventLoadAssigneesAdded",
+                "EventLoadAssigneeRemoved",
+                "EventLoadAcknowledged",
+                "EventLoadActualRecorded",
+                "EventLoadReassigned",
+                "EventCapacityChanged",
+                "EventCapacityRequested",
+                "EventCapacityDecided",
+                "EventDelegationAdded",
+                "EventDelegationRemoved",
+                "EventBlackoutAdded",
+                "EventBlackoutDeleted",
+                "EventEntityCreated",
+                "EventEntityUpdated",
+                "EventEntityDeleted",
+                "EventEntityArchived",
+                "EventEntityRestored",
+                "EventEntityIDChanged",
+                "EventPreferencesUpdated",
+                "EventPersonOnboarded",
+                "EventPersonOffboarded",
+                "EventPersonsConfirmed",
+                "EventPersonsRejected",
+                "EventGroupsImported",
+                "EventGroupMemberAdded",
+                "EventGroupMemberRemoved",
+                "EventGroupMemberExcluded",
+                "EventGroupMemberIncluded",
+                "EventGroupOwnerAdded",
+                "EventGroupOwnerRemoved",
+                "EventDashboardEnabled",
+                "EventDashboardDisabled",
+                "EventSettingsUpdated"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.Entity": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "description": "Set when a person is offboarded",
+                    "type": "string"
+                },
+                "capacity_mode": {
+                    "description": "\"static\", or for groups \"members\" to sum member capacities",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_capacity": {
+                    "description": "Default daily capacity",
+                    "type": "number"
+                },
+                "employee_id": {
+                    "description": "Optional employee identifier",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "email for persons, string-id for groups",
+                    "type": "string"
+                },
+                "manager_email": {
+                    "description": "Who a person reports to, from directory sync",
+                    "type": "string"
+                },
+                "private": {
+                    "description": "Heatmap hidden from the public selector and endpoints",
+                    "type": "boolean"
+                },
+                "region": {
+                    "description": "Region whose public holidays are days off, such as a country code",
+                    "type": "string"
+                },
+                "skills": {
+                    "description": "Lowercase skill tags (persons)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "description": "Display name",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "\"person\" or \"group\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityType"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityChanges": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "entities created or updated since, not archived",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                    }
+                },
+                "removed": {
+                    "description": "IDs of the entities archived or deleted since",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "until": {
+                    "description": "pass as since to fetch the next changes",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityConflictResponse": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "description": "Left out when outside the API key's groups",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                        }
+                    ]
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityDeletePreview": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "description": "load assignments of the person",
+                    "type": "integer"
+                },
+                "capacity_overrides": {
+                    "description": "per-date capacity overrides",
+                    "type": "integer"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "future_loads": {
+                    "description": "loads not yet over that lose the person",
+                    "type": "integer"
+                },
+                "memberships": {
+                    "description": "group memberships, of the person or in the group",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unassigned_loads": {
+                    "description": "future loads left with no assignee",
+                    "type": "integer"
+                },
+                "weekly_capacity": {
+                    "description": "weekdays with their own capacity",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityInfo": {
+            "type": "object",
+            "properties": {
+                "entity_id": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityLink"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "description": "unset until first edited",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityLink": {
+            "type": "object",
+            "required": [
+                "title",
+                "url"
+            ],
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "url": {
+                    "description": "http or https",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.EntityType": {
+            "type": "string",
+            "enum": [
+                "person",
+                "group"
+            ],
+            "x-enum-varnames": [
+                "EntityTypePerson",
+                "EntityTypeGroup"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.FetchHolidaysRequest": {
+            "type": "object",
+            "required": [
+                "year"
+            ],
+            "properties": {
+                "year": {
+                    "type": "integer",
+                    "maximum": 2999,
+                    "minimum": 1900
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.GroupDayBreakdownResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Fullest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.MemberDayBreakdown"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.GroupImportRequest": {
+            "type": "object",
+            "required": [
+                "rows"
+            ],
+            "properties": {
+                "rows": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.GroupImportRow"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.GroupImportResponse": {
+            "type": "object",
+            "properties": {
+                "groups_created": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "memberships_added": {
+                    "type": "integer"
+                },
+                "memberships_existing": {
+                    "type": "integer"
+                },
+                "people_created": {
+                    "description": "Members not yet known, created with the default capacity",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.GroupImportRow": {
+            "type": "object",
+            "required": [
+                "group",
+                "member"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "member": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.HeatmapData": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.HeatmapDay"
+                    }
+                },
+                "entity": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.HeatmapDay": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "holiday": {
+                    "description": "Public holiday in the entity's region, a day without capacity",
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.HeatmapPreferences": {
+            "type": "object",
+            "properties": {
+                "custom": {
+                    "description": "false when following the server's WEEK_START",
+                    "type": "boolean"
+                },
+                "hidden_sources": {
+                    "description": "HiddenSources are load sources left out of the heatmaps you view",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "private": {
+                    "description": "heatmap visible only to you, your group owners and admins",
+                    "type": "boolean"
+                },
+                "week_start": {
+                    "description": "monday .. sunday, the first column of each week",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Holiday": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.HolidayImport": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.IncidentNote": {
+            "type": "object",
+            "properties": {
+                "author_email": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "description": "unset while the incident is open",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.IntegrationError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "the route called, e.g. /api/v1/loads/upsert",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "empty when the request did not name one",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.IntegrationHealthReport": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SourceHealth"
+                    }
+                },
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WebhookHealth"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Issue": {
+            "type": "object",
+            "required": [
+                "key"
+            ],
+            "properties": {
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "key": {
+                    "description": "e.g. PROJ-123, the load's external ID",
+                    "type": "string"
+                },
+                "self": {
+                    "description": "REST URL of the issue, for the link back to it",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.IssueLoadResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "\"upserted\", \"deleted\", or \"ignored\" when there was no load to delete",
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "load": {
+                    "description": "the upsert's response, when upserted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UpsertLoadResponse"
+                        }
+                    ]
+                },
+                "reason": {
+                    "description": "why the issue has no load, unless upserted",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.IssueWebhook": {
+            "type": "object",
+            "properties": {
+                "issue": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Issue"
+                },
+                "webhookEvent": {
+                    "description": "jira:issue_deleted removes the issue's load",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "The first failed items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.JobItemError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "Items done so far, failed ones included",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.JobStatus"
+                },
+                "total": {
+                    "description": "Items to process",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.JobItemError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "External ID, load ID or entity ID of the item",
+                    "type": "string"
+                },
+                "item": {
+                    "description": "Index in the request, or in the job's own item list",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.JobStatus": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "JobFailed": "Stopped early; see Error",
+                "JobSucceeded": "Finished, though items may have failed"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "Finished, though items may have failed",
+                "Stopped early; see Error"
+            ],
+            "x-enum-varnames": [
+                "JobQueued",
+                "JobRunning",
+                "JobSucceeded",
+                "JobFailed"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.LarkDigest": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "last_sent_on": {
+                    "description": "Monday of the latest post",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LeastLoadedMember": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "load": {
+                    "description": "Before the new load",
+                    "type": "number"
+                },
+                "overloaded": {
+                    "type": "boolean"
+                },
+                "remaining": {
+                    "description": "Capacity minus load and the new load's weight",
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LeastLoadedResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LeastLoadedMember"
+                    }
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Load": {
+            "type": "object",
+            "properties": {
+                "confidential_group": {
+                    "description": "ConfidentialGroup is the group whose members alone see the load, nil\nfor a load anyone may see",
+                    "type": "string"
+                },
+                "custom_fields": {
+                    "description": "CustomFields is extra data from the source, such as a meeting link,\nas it was sent; see CustomField",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "date": {
+                    "description": "First (or only) day",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Last day of a load spanning several days",
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "For n8n/external system deduplication",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "Recurrence repeats the load from Date, nil for a one-off",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Recurrence"
+                        }
+                    ]
+                },
+                "source": {
+                    "description": "Origin system (gcal, crm, etc.)",
+                    "type": "string"
+                },
+                "spread": {
+                    "description": "How assignment weights fall on the days",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadSpread"
+                        }
+                    ]
+                },
+                "tags": {
+                    "description": "Tags categorize the load, such as meeting or project work; lowercase",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Link back to original platform (gcal, lark, etc.)",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LoadActual": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "number"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "planned": {
+                    "type": "number"
+                },
+                "recorded_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LoadAssignment": {
+            "type": "object",
+            "properties": {
+                "load_id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Default 1.0",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LoadList": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "loads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadWithAssignments"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; empty on the last page",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.LoadSpread": {
+            "type": "string",
+            "enum": [
+                "even",
+                "per_day"
+            ],
+            "x-enum-varnames": [
+                "LoadSpreadEven",
+                "LoadSpreadPerDay"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.LoadWithAssignments": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadAssignment"
+                    }
+                },
+                "conflicts": {
+                    "description": "Conflicts are the IDs of the other loads in day details whose time\nwindows overlap this one's for an assignee they share",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "fields": {
+                    "description": "Fields are the custom fields day details show, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.CustomFieldValue"
+                    }
+                },
+                "load": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Load"
+                },
+                "pinned": {
+                    "description": "pinned by the viewing user",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.MemberDayBreakdown": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "color": {
+                    "description": "Heatmap color of the member's day",
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "overloaded": {
+                    "type": "boolean"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.MemberDayLoad": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "loads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadWithAssignments"
+                    }
+                },
+                "person_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Note": {
+            "type": "object",
+            "properties": {
+                "author_email": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "the load's date or the noted day, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "load_id": {
+                    "description": "unset for day notes",
+                    "type": "integer"
+                },
+                "load_title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OTPRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OffboardPersonRequest": {
+            "type": "object",
+            "properties": {
+                "last_day": {
+                    "description": "Format: YYYY-MM-DD, default today",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OffboardPersonResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_day": {
+                    "type": "string"
+                },
+                "removed_assignments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OffboardedAssignment"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OffboardedAssignment": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unassigned": {
+                    "description": "No assignees left",
+                    "type": "boolean"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OnboardPersonRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "groups",
+                "skills",
+                "title"
+            ],
+            "properties": {
+                "default_capacity": {
+                    "description": "Default from the settings, 5.0 unless changed",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "manager_email": {
+                    "type": "string"
+                },
+                "ramp_up": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RampUpRequest"
+                },
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "start_date": {
+                    "description": "Format: YYYY-MM-DD, default today",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OnboardPersonResponse": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Entity"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ramp_up_until": {
+                    "description": "Last reduced-capacity day",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OverloadResolution": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "string"
+                },
+                "mean_hours_to_resolve": {
+                    "description": "Nil until a day is resolved",
+                    "type": "number"
+                },
+                "open": {
+                    "description": "Still overloaded, date not yet passed",
+                    "type": "integer"
+                },
+                "overloaded": {
+                    "description": "Person-days that went over capacity",
+                    "type": "integer"
+                },
+                "resolved": {
+                    "description": "Brought back under capacity",
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unresolved": {
+                    "description": "Date passed while still overloaded",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.OverloadResolutionReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.OverloadResolution"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.PendingAcknowledgment": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Pin": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "pinned_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Presence": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "\"viewing\" or \"editing\"",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Their entity title, or their email",
+                    "type": "string"
+                },
+                "seen_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.PresenceResponse": {
+            "type": "object",
+            "properties": {
+                "others": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Presence"
+                    }
+                },
+                "subject": {
+                    "description": "e.g. \"entity:team-a\" or \"load:42\"",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.PutCustomFieldRequest": {
+            "type": "object",
+            "required": [
+                "label",
+                "type"
+            ],
+            "properties": {
+                "display": {
+                    "description": "Show in day details; default false",
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "position": {
+                    "description": "Order in day details, lowest first",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "string",
+                        "number",
+                        "boolean",
+                        "url"
+                    ]
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.PutLarkDigestRequest": {
+            "type": "object",
+            "required": [
+                "chat_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "enabled": {
+                    "description": "default true",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RampUpRequest": {
+            "type": "object",
+            "required": [
+                "days"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ReassignLoadsRequest": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "from_date": {
+                    "description": "Format: YYYY-MM-DD; loads ending before it stay, default today",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Only loads from this source",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RebalanceMember": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "load_after": {
+                    "description": "Load once every move is applied",
+                    "type": "number"
+                },
+                "load_before": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RebalanceMove": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RebalancePlan": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RebalanceMember"
+                    }
+                },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RebalanceMove"
+                    }
+                },
+                "resolved": {
+                    "description": "No member is overloaded after the moves",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RecordActualRequest": {
+            "type": "object",
+            "required": [
+                "actual"
+            ],
+            "properties": {
+                "actual": {
+                    "description": "In the same units as weights",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Recurrence": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of occurrences, the first included",
+                    "type": "integer"
+                },
+                "frequency": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RecurrenceFrequency"
+                },
+                "interval": {
+                    "description": "Every Nth day, week or month",
+                    "type": "integer"
+                },
+                "until": {
+                    "description": "Last day an occurrence may start on",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.RecurrenceFrequency": {
+            "type": "string",
+            "enum": [
+                "daily",
+                "weekly",
+                "monthly"
+            ],
+            "x-enum-varnames": [
+                "RecurrenceDaily",
+                "RecurrenceWeekly",
+                "RecurrenceMonthly"
+            ]
+        },
+        "github_com_gti_heatmap-internal_internal_models.RecurrenceRequest": {
+            "type": "object",
+            "required": [
+                "frequency"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ]
+                },
+                "interval": {
+                    "description": "Default 1",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "until": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedRequest": {
+            "type": "object",
+            "required": [
+                "emails"
+            ],
+            "properties": {
+                "emails": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ReviewAutoCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "reviewed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Scenario": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ScenarioCapacity": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "scenario_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ScenarioDetail": {
+            "type": "object",
+            "properties": {
+                "capacities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ScenarioCapacity"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "loads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.ScenarioLoad"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ScenarioLoad": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "person_email": {
+                    "type": "string"
+                },
+                "scenario_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SetScenarioCapacityRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "entity_id"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.Settings": {
+            "type": "object",
+            "properties": {
+                "alert_cooldown_minutes": {
+                    "description": "AlertCooldownMinutes is how long overload alerts about the same person\nor group and day are not sent again, 0 for no cooldown",
+                    "type": "integer"
+                },
+                "color_thresholds": {
+                    "description": "ColorThresholds are the load-to-capacity ratios above which a heatmap\nday turns lime, amber, orange, red and blood red, in ascending order",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "default_capacity": {
+                    "description": "DefaultCapacity is the daily capacity of persons and groups created\nwithout one",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "description": "unset while every key has its default",
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "webhook_secret_set": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.ShadowSource": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "loads": {
+                    "description": "Scenario loads, one per assignee and day",
+                    "type": "integer"
+                },
+                "scenario_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SourceCalibration": {
+            "type": "object",
+            "properties": {
+                "actual_total": {
+                    "type": "number"
+                },
+                "mean_absolute_error": {
+                    "type": "number"
+                },
+                "planned_total": {
+                    "type": "number"
+                },
+                "ratio": {
+                    "description": "Actual over planned; above 1 means underestimated",
+                    "type": "number"
+                },
+                "samples": {
+                    "description": "Assignments with a recorded actual",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SourceHealth": {
+            "type": "object",
+            "properties": {
+                "errors_24h": {
+                    "description": "failed upserts in the past 24 hours",
+                    "type": "integer"
+                },
+                "last_sync_at": {
+                    "description": "last successful upsert of any of its loads",
+                    "type": "string"
+                },
+                "loads_synced_24h": {
+                    "description": "loads upserted in the past 24 hours",
+                    "type": "integer"
+                },
+                "recent_errors": {
+                    "description": "latest first, at most a few",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.IntegrationError"
+                    }
+                },
+                "source": {
+                    "description": "empty for loads without a source",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SourceLoad": {
+            "type": "object",
+            "properties": {
+                "load": {
+                    "type": "number"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.StaleLoad": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.LoadAssignment"
+                    }
+                },
+                "last_seen_at": {
+                    "description": "last upsert by the source",
+                    "type": "string"
+                },
+                "load": {
+                    "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.Load"
+                },
+                "stale_since": {
+                    "description": "when the stale load check flagged it",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.StaleLoadReport": {
+            "type": "object",
+            "properties": {
+                "loads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.StaleLoad"
+                    }
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.StaleSourceSummary"
+                    }
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.StaleSourceSummary": {
+            "type": "object",
+            "properties": {
+                "last_seen_at": {
+                    "description": "oldest last upsert among them",
+                    "type": "string"
+                },
+                "loads": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "empty for loads without a source",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SuggestAssigneeRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "skill"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "Default 5",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "skill": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Default 1.0",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.SuggestAssigneeResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "skill": {
+                    "type": "string"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AssigneeSuggestion"
+                    }
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.TagLoad": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UpdateCapacityRequest": {
+            "type": "object",
+            "properties": {
+                "date_overrides": {
+                    "type": "array",
                     "items": {
                         "type": "object",
                         "required": [
-                            "email"
+                            "capacity",
+                            "date"
                         ],
                         "properties": {
-                            "email": {
-                                "type": "string"
+                            "capacity": {
+                                "type": "number",
+                                "minimum": 0
                             },
-                            "weight": {
-                                "description": "Default 1.0",
-                                "type": "number"
+                            "date": {
+                                "description": "Format: YYYY-MM-DD",
+                                "type": "string"
                             }
                         }
                     }
+                },
+                "default_capacity": {
+                    "type": "number"
+                },
+                "weekly_pattern": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.WeekdayCapacity"
+                    }
                 }
             }
         },
-        "github_com_gti_heatmap-internal_internal_models.AddGroupMemberRequest": {
+        "github_com_gti_heatmap-internal_internal_models.UpdateEntityInfoRequest": {
             "type": "object",
-            "required": [
-                "person_email"
-            ],
             "properties": {
-                "person_email": {
-                    "type": "string"
+                "links": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityLink"
+                    }
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 5000
                 }
             }
         },
-        "github_com_gti_heatmap-internal_internal_models.CreateEntityRequest": {
+        "github_com_gti_heatmap-internal_internal_models.UpdateEntityRequest": {
             "type": "object",
-            "required": [
-                "id",
-                "title",
-                "type"
-            ],
             "properties": {
+                "capacity_mode": {
+                    "type": "string"
+                },
                 "default_capacity": {
                     "type": "number"
                 },
-                "id": {
+                "employee_id": {
+                    "type": "string"
+                },
+                "manager_email": {
+                    "description": "\"\" clears the manager",
+                    "type": "string"
+                },
+                "private": {
+                    "type": "boolean"
+                },
+                "region": {
+                    "description": "\"\" clears the region",
                     "type": "string"
                 },
+                "skills": {
+                    "description": "Replaces the tags; [] clears them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UpdateHeatmapPreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "hidden_sources": {
+                    "description": "HiddenSources replaces the load sources left out of the heatmaps you\nview; an empty list shows every source again",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "type": {
+                "private": {
+                    "description": "hide your heatmap from everyone but group owners and admins",
+                    "type": "boolean"
+                },
+                "week_start": {
+                    "description": "monday .. sunday",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UpdateIncidentNoteRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
                     "type": "string",
-                    "enum": [
-                        "person",
-                        "group"
-                    ]
+                    "maxLength": 1000,
+                    "minLength": 1
+                },
+                "resolved": {
+                    "description": "true resolves, false reopens",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UpdateSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "alert_cooldown_minutes": {
+                    "type": "integer"
+                },
+                "color_thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "default_capacity": {
+                    "type": "number"
+                },
+                "webhook_secret": {
+                    "description": "\"\" removes the secret",
+                    "type": "string"
                 }
             }
         },
-        "github_com_gti_heatmap-internal_internal_models.Entity": {
+        "github_com_gti_heatmap-internal_internal_models.UpdateWebhookEndpointRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "description": {
                     "type": "string"
                 },
-                "default_capacity": {
-                    "description": "Default daily capacity",
-                    "type": "number"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "description": "email for persons, string-id for groups",
-                    "type": "string"
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "title": {
-                    "description": "Display name",
+                "url": {
                     "type": "string"
-                },
-                "type": {
-                    "description": "\"person\" or \"group\"",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.EntityType"
-                        }
-                    ]
                 }
             }
         },
-        "github_com_gti_heatmap-internal_internal_models.EntityType": {
-            "type": "string",
-            "enum": [
-                "person",
-                "group"
-            ],
-            "x-enum-varnames": [
-                "EntityTypePerson",
-                "EntityTypeGroup"
-            ]
-        },
-        "github_com_gti_heatmap-internal_internal_models.OTPRequest": {
+        "github_com_gti_heatmap-internal_internal_models.UpsertLoadByEmployeeIDRequest": {
             "type": "object",
             "required": [
-                "email"
+                "assignees",
+                "date",
+                "external_id",
+                "title"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_gti_heatmap-internal_internal_models.UpdateCapacityRequest": {
-            "type": "object",
-            "properties": {
-                "date_overrides": {
+                "all_day": {
+                    "description": "Used by weight rules",
+                    "type": "boolean"
+                },
+                "assignees": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "type": "object",
                         "required": [
-                            "capacity",
-                            "date"
+                            "employee_id"
                         ],
                         "properties": {
-                            "capacity": {
-                                "type": "number",
-                                "minimum": 0
-                            },
-                            "date": {
-                                "description": "Format: YYYY-MM-DD",
+                            "employee_id": {
                                 "type": "string"
+                            },
+                            "weight": {
+                                "description": "Default from weight rules, else 1.0",
+                                "type": "number"
                             }
                         }
                     }
                 },
-                "default_capacity": {
-                    "type": "number"
+                "confidential_group": {
+                    "description": "ConfidentialGroup is a group ID for a sensitive project's load; see\nUpsertLoadRequest",
+                    "type": "string"
+                },
+                "custom_fields": {
+                    "description": "CustomFields is extra data kept with the load, replacing what was sent\nbefore. Fields defined for the source must have the defined type.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "date": {
+                    "description": "Format: YYYY-MM-DD; the first day of a multi-day load",
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Tombstone: delete the load with this external_id; other fields are ignored",
+                    "type": "boolean"
+                },
+                "duration_minutes": {
+                    "description": "Used by weight rules",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "end_date": {
+                    "description": "Format: YYYY-MM-DD; the last day, for loads spanning several days",
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "recurrence": {
+                    "description": "Repeats the load from date; omitted for a one-off",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RecurrenceRequest"
+                        }
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                },
+                "spread": {
+                    "description": "\"even\" (default) splits weights across the days, \"per_day\" counts them on each",
+                    "type": "string",
+                    "enum": [
+                        "even",
+                        "per_day"
+                    ]
+                },
+                "tags": {
+                    "description": "Tags categorize the load; see UpsertLoadRequest",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Link back to original platform",
+                    "type": "string"
                 }
             }
         },
@@ -1164,6 +12602,10 @@ const docTemplate = `{
                 "title"
             ],
             "properties": {
+                "all_day": {
+                    "description": "Used by weight rules",
+                    "type": "boolean"
+                },
                 "assignees": {
                     "type": "array",
                     "minItems": 1,
@@ -1177,22 +12619,68 @@ const docTemplate = `{
                                 "type": "string"
                             },
                             "weight": {
-                                "description": "Default 1.0",
+                                "description": "Default from weight rules, else 1.0",
                                 "type": "number"
                             }
                         }
                     }
                 },
+                "confidential_group": {
+                    "description": "ConfidentialGroup is a group ID for a sensitive project's load: only the\ngroup's members, and admins, see it. Every assignee must be a member.",
+                    "type": "string"
+                },
+                "custom_fields": {
+                    "description": "CustomFields is extra data kept with the load, replacing what was sent\nbefore. Fields defined for the source must have the defined type.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "date": {
-                    "description": "Format: YYYY-MM-DD",
+                    "description": "Format: YYYY-MM-DD; the first day of a multi-day load",
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Tombstone: delete the load with this external_id; other fields are ignored",
+                    "type": "boolean"
+                },
+                "duration_minutes": {
+                    "description": "Used by weight rules",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "end_date": {
+                    "description": "Format: YYYY-MM-DD; the last day, for loads spanning several days",
                     "type": "string"
                 },
                 "external_id": {
                     "type": "string"
                 },
+                "recurrence": {
+                    "description": "Repeats the load from date; omitted for a one-off",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.RecurrenceRequest"
+                        }
+                    ]
+                },
                 "source": {
                     "type": "string"
                 },
+                "spread": {
+                    "description": "\"even\" (default) splits weights across the days, \"per_day\" counts them on each",
+                    "type": "string",
+                    "enum": [
+                        "even",
+                        "per_day"
+                    ]
+                },
+                "tags": {
+                    "description": "Tags categorize the load, such as \"meeting\" or \"project\", replacing\nthose sent before. They are lowercased.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -1202,6 +12690,143 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_gti_heatmap-internal_internal_models.UpsertLoadResponse": {
+            "type": "object",
+            "properties": {
+                "assignees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.AssigneeDayLoad"
+                    }
+                },
+                "blackouts": {
+                    "description": "New assignments on blackout dates",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.BlackoutConflict"
+                    }
+                },
+                "load_id": {
+                    "type": "integer"
+                },
+                "shadow": {
+                    "description": "Kept in the source's shadow scenario, not in loads; load_id is 0",
+                    "type": "boolean"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UtilizationList": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; empty on the last page",
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UtilizationRow"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UtilizationReport": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UtilizationSummary"
+                    }
+                },
+                "people": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.UtilizationSummary"
+                    }
+                },
+                "quarter": {
+                    "description": "e.g. 2025-Q1",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Today while the quarter is in progress",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UtilizationRow": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "load": {
+                    "type": "number"
+                },
+                "ratio": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.UtilizationSummary": {
+            "type": "object",
+            "properties": {
+                "average_load": {
+                    "type": "number"
+                },
+                "average_utilization": {
+                    "description": "Total load as a percentage of total capacity",
+                    "type": "number"
+                },
+                "days": {
+                    "description": "Days active in the quarter",
+                    "type": "integer"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "overloaded_days": {
+                    "type": "integer"
+                },
+                "p95_load": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "top_sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_gti_heatmap-internal_internal_models.SourceLoad"
+                    }
+                }
+            }
+        },
         "github_com_gti_heatmap-internal_internal_models.VerifyOTPRequest": {
             "type": "object",
             "required": [
@@ -1216,6 +12841,75 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "github_com_gti_heatmap-internal_internal_models.WebhookEndpoint": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.WebhookHealth": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "type": "integer"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_failure_at": {
+                    "type": "string"
+                },
+                "last_failure_trace_id": {
+                    "description": "LastFailureTraceID is the trace ID the latest failure was sent with",
+                    "type": "string"
+                },
+                "success_rate": {
+                    "description": "delivered / attempts, 0..1",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_gti_heatmap-internal_internal_models.WeekdayCapacity": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "weekday": {
+                    "description": "monday .. sunday",
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,245 @@
+//go:build e2e
+
+// Package bench benchmarks the heatmap service and load repository against a
+// real PostgreSQL in a container, at fixed data sizes, so performance changes
+// can be compared before and after:
+//
+//	go test -tags=e2e -run='^$' -bench=. -benchmem ./e2e/bench/ | tee before.txt
+//	# apply change
+//	go test -tags=e2e -run='^$' -bench=. -benchmem ./e2e/bench/ | tee after.txt
+//	benchstat before.txt after.txt
+//
+// Set TEST_DATABASE_URL to benchmark against an existing database instead
+// of starting a container. Its load_calendar_data schema is truncated.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/testenv"
+	"github.com/gti/heatmap-internal/internal/database"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+)
+
+// Dataset shape. Loads are spread over a year around today, so the heatmap
+// window (1 month back, 6 months ahead) covers about 60% of them.
+const (
+	persons   = 500
+	teamSize  = 25
+	spanDays  = 365
+	daysAgo   = 180
+	benchTeam = "bench-team-1"
+)
+
+// loadCounts are the dataset sizes every benchmark runs at.
+var loadCounts = []int{10_000, 100_000}
+
+var (
+	dbOnce    sync.Once
+	benchDB   *database.DB
+	dbErr     error
+	stopDB    = func() {}
+	benchUser = personID(1)
+
+	// upsertSeq keeps inserted external IDs unique across b.N ramp-ups.
+	upsertSeq int
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchDB != nil {
+		benchDB.Close()
+	}
+	stopDB()
+	os.Exit(code)
+}
+
+// db starts the database on first use, so running the package without
+// -bench does not need Docker.
+func db(b *testing.B) *database.DB {
+	b.Helper()
+
+	dbOnce.Do(func() {
+		ctx := context.Background()
+
+		url := os.Getenv("TEST_DATABASE_URL")
+		if url == "" {
+			pg, cleanup, err := testenv.StartPostgres(ctx, testenv.DefaultPostgresConfig())
+			if err != nil {
+				dbErr = fmt.Errorf("failed to start postgres: %w", err)
+				return
+			}
+			url, stopDB = pg.ConnectionString, cleanup
+		}
+
+		benchDB, dbErr = database.New(url)
+		if dbErr == nil {
+			dbErr = benchDB.RunMigrations(ctx)
+		}
+	})
+
+	if dbErr != nil {
+		b.Fatalf("benchmark database unavailable: %v", dbErr)
+	}
+	return benchDB
+}
+
+func personID(n int) string {
+	return fmt.Sprintf("bench-%d@example.com", n)
+}
+
+// seed replaces all data with persons in teams and the given number of loads.
+// Every load has one assignee and every third load a second one.
+func seed(b *testing.B, d *database.DB, loads int) {
+	b.Helper()
+	ctx := context.Background()
+
+	statements := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`TRUNCATE load_assignments, loads, group_members, capacity_overrides, entities, heatmap_tombstones, heatmap_snapshots CASCADE`, nil},
+		{`INSERT INTO entities (id, title, type, default_capacity)
+		  SELECT 'bench-' || g || '@example.com', 'Bench ' || g, 'person', 5
+		  FROM generate_series(1, $1) g`, []interface{}{persons}},
+		{`INSERT INTO entities (id, title, type, default_capacity)
+		  SELECT 'bench-team-' || t, 'Bench Team ' || t, 'group', $2 * 5
+		  FROM generate_series(1, $1 / $2) t`, []interface{}{persons, teamSize}},
+		{`INSERT INTO group_members (group_id, person_email)
+		  SELECT 'bench-team-' || ((g - 1) / $2 + 1), 'bench-' || g || '@example.com'
+		  FROM generate_series(1, $1) g`, []interface{}{persons, teamSize}},
+		{`INSERT INTO loads (external_id, title, source, url, date)
+		  SELECT 'bench-' || i, 'Bench load ' || i, 'bench', '', CURRENT_DATE - $2::int + (i % $3)
+		  FROM generate_series(1, $1) i`, []interface{}{loads, daysAgo, spanDays}},
+		{`INSERT INTO load_assignments (load_id, person_email, weight)
+		  SELECT id, 'bench-' || (1 + id % $1) || '@example.com', 1
+		  FROM loads`, []interface{}{persons}},
+		{`INSERT INTO load_assignments (load_id, person_email, weight)
+		  SELECT id, 'bench-' || (1 + (id * 7 + 3) % $1) || '@example.com', 0.5
+		  FROM loads WHERE id % 3 = 0
+		  ON CONFLICT DO NOTHING`, []interface{}{persons}},
+		{`ANALYZE`, nil},
+	}
+
+	for _, st := range statements {
+		if _, err := d.Pool.Exec(ctx, st.sql, st.args...); err != nil {
+			b.Fatalf("failed to seed %d loads: %v", loads, err)
+		}
+	}
+}
+
+// BenchmarkLoadCalendar runs each benchmark at every dataset size, e.g.
+// BenchmarkLoadCalendar/loads=100000/GetGroupLoadForDateRange. Filter with
+// -bench, such as -bench='/loads=10000/'.
+func BenchmarkLoadCalendar(b *testing.B) {
+	for _, n := range loadCounts {
+		b.Run(fmt.Sprintf("loads=%d", n), func(b *testing.B) {
+			d := db(b)
+			seed(b, d, n)
+
+			entityRepo := repository.NewEntityRepository(d.Pool)
+			groupRepo := repository.NewGroupRepository(d.Pool)
+			capacityRepo := repository.NewCapacityRepository(d.Pool)
+			loadRepo := repository.NewLoadRepository(d.Pool)
+			snapshotRepo := repository.NewSnapshotRepository(d.Pool)
+			heatmapService := service.NewHeatmapService(entityRepo, capacityRepo, loadRepo, groupRepo, snapshotRepo, repository.NewScenarioRepository(d.Pool), repository.NewHolidayRepository(d.Pool))
+
+			// Unchanged heatmaps are served from their snapshot; "recompute"
+			// drops snapshots first to measure building them
+			for _, cold := range []bool{false, true} {
+				suffix := ""
+				if cold {
+					suffix = "/recompute"
+				}
+				b.Run("GetHeatmapData/person"+suffix, func(b *testing.B) {
+					benchmarkHeatmap(b, d, heatmapService, benchUser, cold)
+				})
+				b.Run("GetHeatmapData/group"+suffix, func(b *testing.B) {
+					benchmarkHeatmap(b, d, heatmapService, benchTeam, cold)
+				})
+			}
+			b.Run("GetGroupLoadForDateRange", func(b *testing.B) {
+				benchmarkGroupLoad(b, loadRepo)
+			})
+			b.Run("UpsertByExternalID/insert", func(b *testing.B) {
+				benchmarkUpsert(b, loadRepo, "insert")
+			})
+			b.Run("UpsertByExternalID/update", func(b *testing.B) {
+				benchmarkUpsert(b, loadRepo, "update")
+			})
+		})
+	}
+}
+
+func benchmarkHeatmap(b *testing.B, d *database.DB, s *service.HeatmapService, entityID string, cold bool) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if cold {
+			b.StopTimer()
+			if _, err := d.Pool.Exec(ctx, `DELETE FROM heatmap_snapshots`); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+		}
+		start, end := service.HeatmapWindow(time.Now())
+		if _, err := s.GetHeatmapData(ctx, "", entityID, "", nil, start, end); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkGroupLoad(b *testing.B, r *repository.LoadRepository) {
+	ctx := context.Background()
+	start, end := service.HeatmapWindow(time.Now())
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := r.GetGroupLoadForDateRange(ctx, benchTeam, start, end, nil, "", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkUpsert upserts loads with two assignees. "insert" uses a new
+// external ID each time; "update" rewrites seeded loads, replacing their
+// assignments.
+func benchmarkUpsert(b *testing.B, r *repository.LoadRepository, mode string) {
+	ctx := context.Background()
+	date := time.Now().AddDate(0, 0, 7)
+	source, url := "bench", ""
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		upsertSeq++
+		externalID := fmt.Sprintf("bench-insert-%d", upsertSeq)
+		if mode == "update" {
+			externalID = fmt.Sprintf("bench-%d", i%loadCounts[0]+1)
+		}
+		load := &models.Load{
+			ExternalID: &externalID,
+			Title:      "Bench upsert",
+			Source:     &source,
+			URL:        &url,
+			Date:       date,
+		}
+		assignments := []models.LoadAssignment{
+			{PersonEmail: personID(i%persons + 1), Weight: 1},
+			{PersonEmail: personID((i+1)%persons + 1), Weight: 0.5},
+		}
+		if _, _, _, err := r.UpsertByExternalID(ctx, load, assignments, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
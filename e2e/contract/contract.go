@@ -0,0 +1,451 @@
+// Package contract validates HTTP traffic against the published OpenAPI
+// (Swagger 2.0) spec in docs/swagger.json.
+//
+// The spec is generated from handler annotations, so it drifts whenever a
+// route or response shape changes without a matching annotation update.
+// Tests use this package to replay representative requests and fail when
+// the code and the documentation disagree.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Spec is the subset of a Swagger 2.0 document needed for validation.
+type Spec struct {
+	Paths       map[string]map[string]*Operation `json:"paths"`
+	Definitions map[string]*Schema               `json:"definitions"`
+}
+
+// Operation describes a single method on a documented path.
+type Operation struct {
+	Summary    string                `json:"summary"`
+	Consumes   []string              `json:"consumes"`
+	Produces   []string              `json:"produces"`
+	Parameters []Parameter           `json:"parameters"`
+	Responses  map[string]Response   `json:"responses"`
+	Security   []map[string][]string `json:"security"`
+}
+
+// Parameter describes an operation parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Type     string  `json:"type"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Response describes a documented response for one status code.
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema emitted by swag.
+type Schema struct {
+	Ref                  string                `json:"$ref"`
+	Type                 string                `json:"type"`
+	Enum                 []interface{}         `json:"enum"`
+	Required             []string              `json:"required"`
+	Properties           map[string]*Schema    `json:"properties"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties"`
+	Items                *Schema               `json:"items"`
+	AllOf                []*Schema             `json:"allOf"`
+	MinItems             *int                  `json:"minItems"`
+	Minimum              *float64              `json:"minimum"`
+}
+
+// AdditionalProperties is either a boolean or a schema for map values.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalJSON accepts both forms of additionalProperties.
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("true")) || bytes.Equal(data, []byte("false")) {
+		a.Allowed = bytes.Equal(data, []byte("true"))
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Route identifies an operation by method and spec path (e.g. /api/entities/{id}).
+type Route struct {
+	Method string
+	Path   string
+}
+
+// String formats the route as "METHOD /path".
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Load reads a spec from path.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	return &spec, nil
+}
+
+// LoadDefault reads docs/swagger.json from the project root.
+func LoadDefault() (*Spec, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	return Load(filepath.Join(root, "docs", "swagger.json"))
+}
+
+// Routes returns every documented operation, sorted by path then method.
+func (s *Spec) Routes() []Route {
+	var routes []Route
+	for path, ops := range s.Paths {
+		for method := range ops {
+			routes = append(routes, Route{Method: strings.ToUpper(method), Path: path})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Operation looks up an operation by method and spec path.
+func (s *Spec) Operation(method, specPath string) (*Operation, bool) {
+	op, ok := s.Paths[specPath][strings.ToLower(method)]
+	return op, ok
+}
+
+// Match finds the documented path template for a concrete request path.
+//
+// Literal segments win over parameters, so /api/loads/upsert matches its own
+// entry rather than a hypothetical /api/loads/{id}.
+func (s *Spec) Match(method, requestPath string) (string, *Operation, bool) {
+	if i := strings.IndexByte(requestPath, '?'); i >= 0 {
+		requestPath = requestPath[:i]
+	}
+	segments := strings.Split(strings.Trim(requestPath, "/"), "/")
+
+	best, bestParams := "", -1
+	for template, ops := range s.Paths {
+		if _, ok := ops[strings.ToLower(method)]; !ok {
+			continue
+		}
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		params, ok := 0, true
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+				params++
+				continue
+			}
+			if part != segments[i] {
+				ok = false
+				break
+			}
+		}
+		if ok && (bestParams < 0 || params < bestParams) {
+			best, bestParams = template, params
+		}
+	}
+
+	if bestParams < 0 {
+		return "", nil, false
+	}
+	op, _ := s.Operation(method, best)
+	return best, op, true
+}
+
+// RequiresAPIKey reports whether the operation declares ApiKeyAuth security.
+func (op *Operation) RequiresAPIKey() bool {
+	for _, requirement := range op.Security {
+		if _, ok := requirement["ApiKeyAuth"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRequest checks a JSON request body against the operation's body parameter.
+func (s *Spec) ValidateRequest(method, requestPath string, body []byte) error {
+	template, op, ok := s.Match(method, requestPath)
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, requestPath)
+	}
+
+	for _, param := range op.Parameters {
+		if param.In != "body" || param.Schema == nil {
+			continue
+		}
+		if len(body) == 0 {
+			if param.Required {
+				return fmt.Errorf("%s %s: missing required body", method, template)
+			}
+			return nil
+		}
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return fmt.Errorf("%s %s: request body is not JSON: %w", method, template, err)
+		}
+		return joinErrors(fmt.Sprintf("%s %s request", method, template), s.Validate(param.Schema, value, "body"))
+	}
+
+	return nil
+}
+
+// ValidateResponse checks that status is documented for the operation and
+// that a JSON body matches its schema.
+func (s *Spec) ValidateResponse(method, requestPath string, status int, contentType string, body []byte) error {
+	template, op, ok := s.Match(method, requestPath)
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, requestPath)
+	}
+
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented (documented: %s)",
+			method, template, status, strings.Join(sortedKeys(op.Responses), ", "))
+	}
+
+	// Redirects and empty responses have nothing further to check
+	if (status >= 300 && status < 400) || len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	// Produces describes the success payload; errors may be plain text or HTML
+	// fragments depending on whether the caller is HTMX.
+	if status < 400 && len(op.Produces) > 0 && !contains(op.Produces, mediaType) {
+		return fmt.Errorf("%s %s: content type %q is not one of %v", method, template, mediaType, op.Produces)
+	}
+
+	if response.Schema == nil || mediaType != "application/json" {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %s: response body is not JSON: %w", method, template, err)
+	}
+
+	return joinErrors(fmt.Sprintf("%s %s %d response", method, template, status), s.Validate(response.Schema, value, "body"))
+}
+
+// Validate checks a decoded JSON value against schema and returns one error
+// per violation, each prefixed with the JSON path of the offending value.
+func (s *Spec) Validate(schema *Schema, value interface{}, path string) []error {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		resolved, err := s.resolve(schema.Ref)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", path, err)}
+		}
+		return s.Validate(resolved, value, path)
+	}
+
+	var errs []error
+	for _, sub := range schema.AllOf {
+		errs = append(errs, s.Validate(sub, value, path)...)
+	}
+
+	if value == nil {
+		// Optional pointer fields are omitted rather than null, but tolerate
+		// explicit nulls since encoding/json emits them for nil slices.
+		return errs
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		return append(errs, fmt.Errorf("%s: expected %s, got %s", path, schema.Type, jsonType(value)))
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		errs = append(errs, fmt.Errorf("%s: %v is not one of %v", path, value, schema.Enum))
+	}
+
+	if schema.Minimum != nil {
+		if n, ok := value.(float64); ok && n < *schema.Minimum {
+			errs = append(errs, fmt.Errorf("%s: %v is below minimum %v", path, n, *schema.Minimum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: missing required property %q", path, name))
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			child := path + "." + name
+			if prop, ok := schema.Properties[name]; ok {
+				errs = append(errs, s.Validate(prop, v[name], child)...)
+				continue
+			}
+			switch {
+			case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+				errs = append(errs, s.Validate(schema.AdditionalProperties.Schema, v[name], child)...)
+			case schema.AdditionalProperties != nil && schema.AdditionalProperties.Allowed:
+			case schema.Properties != nil:
+				errs = append(errs, fmt.Errorf("%s: undocumented property", child))
+			}
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			errs = append(errs, fmt.Errorf("%s: expected at least %d items, got %d", path, *schema.MinItems, len(v)))
+		}
+		for i, item := range v {
+			errs = append(errs, s.Validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return errs
+}
+
+// resolve looks up a local #/definitions reference.
+func (s *Spec) resolve(ref string) (*Schema, error) {
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	schema, ok := s.Definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", ref)
+	}
+	return schema, nil
+}
+
+// SpecPath converts an Echo route path (/api/entities/:id) to spec form
+// (/api/entities/{id}).
+func SpecPath(echoPath string) string {
+	segments := strings.Split(echoPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinErrors folds validation errors into one error with a shared prefix.
+func joinErrors(prefix string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Errorf("%s does not match spec:\n%s", prefix, strings.Join(lines, "\n"))
+}
+
+// findProjectRoot walks up from the working directory to the go.mod.
+func findProjectRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("could not find project root (go.mod)")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,143 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponse(t *testing.T) {
+	spec, err := LoadDefault()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{
+			name:        "valid entity",
+			method:      "GET",
+			path:        "/api/entities/alice@example.com",
+			status:      200,
+			contentType: "application/json; charset=UTF-8",
+			body:        `{"id":"alice@example.com","title":"Alice","type":"person","default_capacity":5,"created_at":"2025-01-01T00:00:00Z"}`,
+		},
+		{
+			name:        "wrong property type",
+			method:      "GET",
+			path:        "/api/entities/alice@example.com",
+			status:      200,
+			contentType: "application/json",
+			body:        `{"id":"alice@example.com","title":"Alice","type":"person","default_capacity":"5"}`,
+			wantErr:     "body.default_capacity: expected number, got string",
+		},
+		{
+			name:        "invalid enum",
+			method:      "GET",
+			path:        "/api/entities/alice@example.com",
+			status:      200,
+			contentType: "application/json",
+			body:        `{"id":"alice@example.com","title":"Alice","type":"team"}`,
+			wantErr:     "body.type: team is not one of",
+		},
+		{
+			name:        "undocumented property",
+			method:      "GET",
+			path:        "/api/entities/alice@example.com",
+			status:      200,
+			contentType: "application/json",
+			body:        `{"id":"alice@example.com","title":"Alice","type":"person","nickname":"Al"}`,
+			wantErr:     "body.nickname: undocumented property",
+		},
+		{
+			name:        "undocumented status",
+			method:      "GET",
+			path:        "/api/entities",
+			status:      404,
+			contentType: "application/json",
+			body:        `{"error":"not found"}`,
+			wantErr:     "status 404 is not documented",
+		},
+		{
+			name:        "html endpoint",
+			method:      "GET",
+			path:        "/api/heatmap/alice@example.com",
+			status:      200,
+			contentType: "text/html; charset=UTF-8",
+			body:        `<div class="heatmap"></div>`,
+		},
+		{
+			name:        "wrong content type",
+			method:      "GET",
+			path:        "/api/dashboard/engineering",
+			status:      200,
+			contentType: "application/json",
+			body:        `{}`,
+			wantErr:     `content type "application/json" is not one of`,
+		},
+		{
+			name:    "undocumented route",
+			method:  "GET",
+			path:    "/api/unknown",
+			status:  200,
+			wantErr: "is not documented",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.ValidateResponse(tt.method, tt.path, tt.status, tt.contentType, []byte(tt.body))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.wantErr), "error %q should contain %q", err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec, err := LoadDefault()
+	require.NoError(t, err)
+
+	err = spec.ValidateRequest("POST", "/api/loads/upsert",
+		[]byte(`{"external_id":"x","title":"T","date":"2025-01-01","assignees":[{"email":"a@example.com","weight":1}]}`))
+	assert.NoError(t, err)
+
+	err = spec.ValidateRequest("POST", "/api/loads/upsert", []byte(`{"title":"T","assignees":[]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing required property "external_id"`)
+	assert.Contains(t, err.Error(), "body.assignees: expected at least 1 items")
+}
+
+func TestMatchPrefersLiteralSegments(t *testing.T) {
+	spec := &Spec{Paths: map[string]map[string]*Operation{
+		"/api/loads/{id}":    {"post": {}},
+		"/api/loads/upsert":  {"post": {}},
+		"/api/entities/{id}": {"get": {}},
+	}}
+
+	template, _, ok := spec.Match("POST", "/api/loads/upsert")
+	require.True(t, ok)
+	assert.Equal(t, "/api/loads/upsert", template)
+
+	template, _, ok = spec.Match("GET", "/api/entities/bob@example.com?x=1")
+	require.True(t, ok)
+	assert.Equal(t, "/api/entities/{id}", template)
+
+	_, _, ok = spec.Match("DELETE", "/api/entities/bob@example.com")
+	assert.False(t, ok)
+}
+
+func TestSpecPath(t *testing.T) {
+	assert.Equal(t, "/api/loads/{id}/assignees/{email}", SpecPath("/api/loads/:id/assignees/:email"))
+	assert.Equal(t, "/api/entities", SpecPath("/api/entities"))
+}
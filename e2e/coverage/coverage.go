@@ -0,0 +1,367 @@
+// Package coverage merges Go coverage data from unit tests and instrumented
+// E2E service runs into a single profile and enforces per-package thresholds.
+//
+// Sources are either text profiles written by `go test -coverprofile` or
+// binary GOCOVERDIR directories written by a binary built with `go build
+// -cover` (see testenv.StartInstrumentedService). Blocks covered by any
+// source count as covered in the merged profile, so one report shows what
+// the whole test suite exercises.
+package coverage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is one coverage block of a profile line.
+type Block struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// key identifies a block independently of its count.
+func (b Block) key() string {
+	return fmt.Sprintf("%s:%d.%d,%d.%d", b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol)
+}
+
+// Profile is a parsed text coverage profile.
+type Profile struct {
+	Mode   string
+	Blocks []Block
+}
+
+// ParseProfile reads a text coverage profile.
+func ParseProfile(path string) (*Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	profile := &Profile{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "mode:") {
+			profile.Mode = strings.TrimSpace(strings.TrimPrefix(text, "mode:"))
+			continue
+		}
+		block, err := parseBlock(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		profile.Blocks = append(profile.Blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if profile.Mode == "" {
+		return nil, fmt.Errorf("%s: missing mode line", path)
+	}
+
+	return profile, nil
+}
+
+// parseBlock parses "file.go:1.2,3.4 5 6".
+func parseBlock(text string) (Block, error) {
+	colon := strings.LastIndex(text, ":")
+	if colon < 0 {
+		return Block{}, fmt.Errorf("malformed block %q", text)
+	}
+
+	var b Block
+	b.File = text[:colon]
+	_, err := fmt.Sscanf(text[colon+1:], "%d.%d,%d.%d %d %d",
+		&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count)
+	if err != nil {
+		return Block{}, fmt.Errorf("malformed block %q: %w", text, err)
+	}
+	return b, nil
+}
+
+// ConvertCoverDirs converts one or more GOCOVERDIR directories into a single
+// text profile at out using `go tool covdata textfmt`. Data from every
+// directory is merged, so several E2E runs can be combined in one call.
+func ConvertCoverDirs(ctx context.Context, dirs []string, out string) error {
+	if len(dirs) == 0 {
+		return fmt.Errorf("no coverage directories given")
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "tool", "covdata", "textfmt",
+		"-i="+strings.Join(dirs, ","), "-o="+out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to convert coverage data: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// Sources lists the coverage inputs to combine.
+type Sources struct {
+	// Profiles are text profiles such as coverage/unit.out.
+	Profiles []string
+	// CoverDirs are GOCOVERDIR directories from instrumented service runs.
+	CoverDirs []string
+}
+
+// Collect parses every profile, converts every coverage directory, and merges
+// the result into one profile. Directories without data are skipped so a
+// missing E2E run does not hide unit coverage.
+func Collect(ctx context.Context, src Sources) (*Profile, error) {
+	var profiles []*Profile
+	for _, path := range src.Profiles {
+		p, err := ParseProfile(path)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	var dirs []string
+	for _, dir := range src.CoverDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read coverage directory: %w", err)
+		}
+		if len(entries) > 0 {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if len(dirs) > 0 {
+		tmp, err := os.CreateTemp("", "covdata-*.out")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp profile: %w", err)
+		}
+		_ = tmp.Close()
+		defer func() { _ = os.Remove(tmp.Name()) }()
+
+		if err := ConvertCoverDirs(ctx, dirs, tmp.Name()); err != nil {
+			return nil, err
+		}
+		p, err := ParseProfile(tmp.Name())
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	return Merge(profiles...)
+}
+
+// Merge combines profiles block by block.
+//
+// In "set" mode a block is covered if any profile covered it; in "count" and
+// "atomic" modes counts are summed. Mixing "set" with a counting mode yields
+// a "set" profile, since counts cannot be recovered from set data.
+func Merge(profiles ...*Profile) (*Profile, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles to merge")
+	}
+
+	mode := profiles[0].Mode
+	for _, p := range profiles[1:] {
+		if p.Mode == "set" {
+			mode = "set"
+		}
+	}
+
+	merged := make(map[string]*Block)
+	for _, p := range profiles {
+		for _, b := range p.Blocks {
+			existing, ok := merged[b.key()]
+			if !ok {
+				copied := b
+				merged[b.key()] = &copied
+				continue
+			}
+			existing.Count += b.Count
+		}
+	}
+
+	result := &Profile{Mode: mode}
+	for _, b := range merged {
+		if mode == "set" && b.Count > 0 {
+			b.Count = 1
+		}
+		result.Blocks = append(result.Blocks, *b)
+	}
+	sort.Slice(result.Blocks, func(i, j int) bool {
+		a, b := result.Blocks[i], result.Blocks[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartCol < b.StartCol
+	})
+
+	return result, nil
+}
+
+// Write saves the profile in text format so `go tool cover` can read it.
+func (p *Profile) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("mode: " + p.Mode + "\n")
+	for _, b := range p.Blocks {
+		fmt.Fprintf(&sb, "%s %d %d\n", b.key(), b.NumStmt, b.Count)
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// PackageStats summarizes statement coverage for one package.
+type PackageStats struct {
+	Package    string
+	Statements int
+	Covered    int
+}
+
+// Percent returns covered statements as a percentage (100 for empty packages).
+func (s PackageStats) Percent() float64 {
+	if s.Statements == 0 {
+		return 100
+	}
+	return float64(s.Covered) * 100 / float64(s.Statements)
+}
+
+// Packages returns per-package statement coverage, sorted by package path.
+func (p *Profile) Packages() []PackageStats {
+	byPackage := make(map[string]*PackageStats)
+	for _, b := range p.Blocks {
+		pkg := path.Dir(b.File)
+		stats, ok := byPackage[pkg]
+		if !ok {
+			stats = &PackageStats{Package: pkg}
+			byPackage[pkg] = stats
+		}
+		stats.Statements += b.NumStmt
+		if b.Count > 0 {
+			stats.Covered += b.NumStmt
+		}
+	}
+
+	result := make([]PackageStats, 0, len(byPackage))
+	for _, stats := range byPackage {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Package < result[j].Package })
+	return result
+}
+
+// Total returns statement coverage across every package.
+func (p *Profile) Total() PackageStats {
+	total := PackageStats{Package: "total"}
+	for _, stats := range p.Packages() {
+		total.Statements += stats.Statements
+		total.Covered += stats.Covered
+	}
+	return total
+}
+
+// Thresholds maps package paths to minimum coverage percentages.
+//
+// A package matches the longest entry that equals it or is a parent of it,
+// so "github.com/gti/heatmap-internal/internal" covers every internal
+// package unless a more specific entry exists. The "*" entry, if present,
+// applies to packages with no other match.
+type Thresholds map[string]float64
+
+// ParseThresholds reads a thresholds file with one "package percent" pair
+// per line. Blank lines and lines starting with # are ignored.
+//
+//	# Minimum combined coverage
+//	*                                               40
+//	github.com/gti/heatmap-internal/internal/service 60
+func ParseThresholds(path string) (Thresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read thresholds: %w", err)
+	}
+
+	thresholds := make(Thresholds)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"package percent\"", path, i+1)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid percent %q", path, i+1, fields[1])
+		}
+		thresholds[fields[0]] = percent
+	}
+
+	return thresholds, nil
+}
+
+// For returns the threshold that applies to pkg and whether one exists.
+func (t Thresholds) For(pkg string) (float64, bool) {
+	best, found := "", false
+	for prefix := range t {
+		if prefix == "*" {
+			continue
+		}
+		if (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) && len(prefix) > len(best) {
+			best, found = prefix, true
+		}
+	}
+	if found {
+		return t[best], true
+	}
+	min, ok := t["*"]
+	return min, ok
+}
+
+// Violation is a package whose coverage is below its threshold.
+type Violation struct {
+	PackageStats
+	Threshold float64
+}
+
+// String formats the violation for reports.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %.1f%% < %.1f%%", v.Package, v.Percent(), v.Threshold)
+}
+
+// Check returns every package whose coverage is below its threshold.
+func (t Thresholds) Check(packages []PackageStats) []Violation {
+	var violations []Violation
+	for _, stats := range packages {
+		min, ok := t.For(stats.Package)
+		if ok && stats.Percent() < min {
+			violations = append(violations, Violation{PackageStats: stats, Threshold: min})
+		}
+	}
+	return violations
+}
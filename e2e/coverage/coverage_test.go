@@ -0,0 +1,170 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modulePath = "github.com/gti/heatmap-internal"
+
+func writeProfile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cover.out")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParseProfile(t *testing.T) {
+	path := writeProfile(t, "mode: atomic\n"+
+		modulePath+"/internal/service/load.go:10.2,12.16 2 3\n"+
+		modulePath+"/internal/service/load.go:14.2,14.12 1 0\n")
+
+	p, err := ParseProfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "atomic", p.Mode)
+	require.Len(t, p.Blocks, 2)
+	assert.Equal(t, Block{
+		File: modulePath + "/internal/service/load.go", StartLine: 10, StartCol: 2,
+		EndLine: 12, EndCol: 16, NumStmt: 2, Count: 3,
+	}, p.Blocks[0])
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	_, err := ParseProfile(writeProfile(t, "a.go:1.1,2.2 1 1\n"))
+	assert.ErrorContains(t, err, "missing mode line")
+
+	_, err = ParseProfile(writeProfile(t, "mode: set\nnot a block\n"))
+	assert.ErrorContains(t, err, "malformed block")
+}
+
+func TestMerge(t *testing.T) {
+	unit := &Profile{Mode: "atomic", Blocks: []Block{
+		{File: "pkg/a.go", StartLine: 1, EndLine: 2, NumStmt: 2, Count: 4},
+		{File: "pkg/a.go", StartLine: 3, EndLine: 4, NumStmt: 1, Count: 0},
+	}}
+	e2e := &Profile{Mode: "atomic", Blocks: []Block{
+		{File: "pkg/a.go", StartLine: 3, EndLine: 4, NumStmt: 1, Count: 2},
+		{File: "pkg/b.go", StartLine: 1, EndLine: 1, NumStmt: 3, Count: 0},
+	}}
+
+	merged, err := Merge(unit, e2e)
+	require.NoError(t, err)
+	assert.Equal(t, "atomic", merged.Mode)
+	require.Len(t, merged.Blocks, 3)
+	assert.Equal(t, 4, merged.Blocks[0].Count)
+	assert.Equal(t, 2, merged.Blocks[1].Count, "counts are summed across profiles")
+	assert.Equal(t, "pkg/b.go", merged.Blocks[2].File)
+
+	// Inputs are not modified
+	assert.Equal(t, 0, unit.Blocks[1].Count)
+}
+
+func TestMergeSetMode(t *testing.T) {
+	unit := &Profile{Mode: "atomic", Blocks: []Block{{File: "pkg/a.go", StartLine: 1, NumStmt: 1, Count: 7}}}
+	service := &Profile{Mode: "set", Blocks: []Block{{File: "pkg/a.go", StartLine: 1, NumStmt: 1, Count: 1}}}
+
+	merged, err := Merge(unit, service)
+	require.NoError(t, err)
+	assert.Equal(t, "set", merged.Mode)
+	assert.Equal(t, 1, merged.Blocks[0].Count)
+
+	_, err = Merge()
+	assert.Error(t, err)
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	original := &Profile{Mode: "count", Blocks: []Block{
+		{File: modulePath + "/internal/handler/api.go", StartLine: 5, StartCol: 1, EndLine: 9, EndCol: 3, NumStmt: 4, Count: 2},
+	}}
+	path := filepath.Join(t.TempDir(), "nested", "combined.out")
+	require.NoError(t, original.Write(path))
+
+	parsed, err := ParseProfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, parsed)
+}
+
+func TestPackages(t *testing.T) {
+	p := &Profile{Mode: "set", Blocks: []Block{
+		{File: "mod/internal/service/a.go", StartLine: 1, NumStmt: 3, Count: 1},
+		{File: "mod/internal/service/b.go", StartLine: 1, NumStmt: 1, Count: 0},
+		{File: "mod/internal/handler/h.go", StartLine: 1, NumStmt: 2, Count: 0},
+	}}
+
+	packages := p.Packages()
+	require.Len(t, packages, 2)
+	assert.Equal(t, PackageStats{Package: "mod/internal/handler", Statements: 2, Covered: 0}, packages[0])
+	assert.Equal(t, PackageStats{Package: "mod/internal/service", Statements: 4, Covered: 3}, packages[1])
+	assert.InDelta(t, 75.0, packages[1].Percent(), 0.001)
+
+	total := p.Total()
+	assert.Equal(t, 6, total.Statements)
+	assert.Equal(t, 3, total.Covered)
+}
+
+func TestThresholds(t *testing.T) {
+	path := writeProfile(t, `# Combined coverage minimums
+*                     40
+mod/internal          50%
+mod/internal/service  80
+`)
+	thresholds, err := ParseThresholds(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pkg  string
+		want float64
+	}{
+		{"mod/internal/service", 80},
+		{"mod/internal/handler", 50},
+		{"mod/internal", 50},
+		{"mod/internalx", 40},
+		{"mod/cmd/server", 40},
+	}
+	for _, tt := range tests {
+		got, ok := thresholds.For(tt.pkg)
+		assert.True(t, ok, tt.pkg)
+		assert.Equal(t, tt.want, got, tt.pkg)
+	}
+
+	violations := thresholds.Check([]PackageStats{
+		{Package: "mod/internal/service", Statements: 10, Covered: 7},
+		{Package: "mod/internal/handler", Statements: 10, Covered: 5},
+		{Package: "mod/cmd/server", Statements: 10, Covered: 1},
+	})
+	require.Len(t, violations, 2)
+	assert.Equal(t, "mod/internal/service: 70.0% < 80.0%", violations[0].String())
+	assert.Equal(t, "mod/cmd/server", violations[1].Package)
+}
+
+func TestThresholdsWithoutDefault(t *testing.T) {
+	thresholds := Thresholds{"mod/internal/service": 60}
+
+	_, ok := thresholds.For("mod/cmd/server")
+	assert.False(t, ok)
+	assert.Empty(t, thresholds.Check([]PackageStats{{Package: "mod/cmd/server", Statements: 1}}))
+}
+
+func TestParseThresholdsErrors(t *testing.T) {
+	_, err := ParseThresholds(writeProfile(t, "mod/internal\n"))
+	assert.ErrorContains(t, err, "expected")
+
+	_, err = ParseThresholds(writeProfile(t, "mod/internal high\n"))
+	assert.ErrorContains(t, err, "invalid percent")
+}
+
+func TestCollectSkipsEmptyCoverDirs(t *testing.T) {
+	path := writeProfile(t, "mode: atomic\npkg/a.go:1.1,2.2 1 1\n")
+	empty := t.TempDir()
+
+	merged, err := Collect(t.Context(), Sources{
+		Profiles:  []string{path},
+		CoverDirs: []string{empty, filepath.Join(empty, "missing")},
+	})
+	require.NoError(t, err)
+	assert.Len(t, merged.Blocks, 1)
+}
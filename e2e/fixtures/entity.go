@@ -0,0 +1,178 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// PersonBuilder builds a person entity with optional capacity overrides.
+type PersonBuilder struct {
+	id         string
+	title      string
+	employeeID *string
+	capacity   float64
+	overrides  map[time.Time]float64
+}
+
+// NewPerson starts a person fixture identified by email.
+//
+// The title defaults to the email and the default capacity to 5.0,
+// matching what the upsert API uses for auto-created assignees.
+func NewPerson(email string) *PersonBuilder {
+	return &PersonBuilder{
+		id:        email,
+		title:     email,
+		capacity:  5.0,
+		overrides: make(map[time.Time]float64),
+	}
+}
+
+// ID returns the person's email.
+func (b *PersonBuilder) ID() string {
+	return b.id
+}
+
+// WithTitle sets the display name.
+func (b *PersonBuilder) WithTitle(title string) *PersonBuilder {
+	b.title = title
+	return b
+}
+
+// WithEmployeeID sets the employee identifier.
+func (b *PersonBuilder) WithEmployeeID(employeeID string) *PersonBuilder {
+	b.employeeID = &employeeID
+	return b
+}
+
+// WithCapacity sets the default daily capacity.
+func (b *PersonBuilder) WithCapacity(capacity float64) *PersonBuilder {
+	b.capacity = capacity
+	return b
+}
+
+// WithCapacityOn overrides the capacity for a single date.
+func (b *PersonBuilder) WithCapacityOn(date time.Time, capacity float64) *PersonBuilder {
+	b.overrides[normalizeDate(date)] = capacity
+	return b
+}
+
+// OnLeave sets zero capacity for every day from start to end inclusive.
+func (b *PersonBuilder) OnLeave(start, end time.Time) *PersonBuilder {
+	for d := normalizeDate(start); !d.After(normalizeDate(end)); d = d.AddDate(0, 0, 1) {
+		b.overrides[d] = 0
+	}
+	return b
+}
+
+// Insert writes the person and its capacity overrides.
+func (b *PersonBuilder) Insert(ctx context.Context, db *helpers.DBHelper) error {
+	if err := insertEntity(ctx, db, b.id, b.title, "person", b.employeeID, b.capacity); err != nil {
+		return err
+	}
+	return insertOverrides(ctx, db, b.id, b.overrides)
+}
+
+// GroupBuilder builds a group entity and its memberships.
+type GroupBuilder struct {
+	id        string
+	title     string
+	capacity  float64
+	members   []string
+	overrides map[time.Time]float64
+}
+
+// NewGroup starts a group fixture.
+//
+// The title defaults to the ID and the default capacity to 10.0.
+func NewGroup(id string) *GroupBuilder {
+	return &GroupBuilder{
+		id:        id,
+		title:     id,
+		capacity:  10.0,
+		overrides: make(map[time.Time]float64),
+	}
+}
+
+// ID returns the group ID.
+func (b *GroupBuilder) ID() string {
+	return b.id
+}
+
+// WithTitle sets the display name.
+func (b *GroupBuilder) WithTitle(title string) *GroupBuilder {
+	b.title = title
+	return b
+}
+
+// WithCapacity sets the default daily capacity.
+func (b *GroupBuilder) WithCapacity(capacity float64) *GroupBuilder {
+	b.capacity = capacity
+	return b
+}
+
+// WithCapacityOn overrides the capacity for a single date.
+func (b *GroupBuilder) WithCapacityOn(date time.Time, capacity float64) *GroupBuilder {
+	b.overrides[normalizeDate(date)] = capacity
+	return b
+}
+
+// WithMembers adds persons to the group.
+//
+// The persons must be inserted before the group.
+func (b *GroupBuilder) WithMembers(members ...*PersonBuilder) *GroupBuilder {
+	for _, m := range members {
+		b.members = append(b.members, m.ID())
+	}
+	return b
+}
+
+// WithMemberEmails adds members by email, for persons created elsewhere.
+func (b *GroupBuilder) WithMemberEmails(emails ...string) *GroupBuilder {
+	b.members = append(b.members, emails...)
+	return b
+}
+
+// Insert writes the group, its memberships, and its capacity overrides.
+func (b *GroupBuilder) Insert(ctx context.Context, db *helpers.DBHelper) error {
+	if err := insertEntity(ctx, db, b.id, b.title, "group", nil, b.capacity); err != nil {
+		return err
+	}
+
+	for _, member := range b.members {
+		_, err := db.Exec(ctx,
+			"INSERT INTO load_calendar_data.group_members (group_id, person_email) VALUES ($1, $2)",
+			b.id, member)
+		if err != nil {
+			return fmt.Errorf("failed to add member %s to group %s: %w", member, b.id, err)
+		}
+	}
+
+	return insertOverrides(ctx, db, b.id, b.overrides)
+}
+
+// insertEntity inserts a single row into the entities table.
+func insertEntity(ctx context.Context, db *helpers.DBHelper, id, title, entityType string, employeeID *string, capacity float64) error {
+	_, err := db.Exec(ctx,
+		"INSERT INTO load_calendar_data.entities (id, title, type, employee_id, default_capacity) VALUES ($1, $2, $3, $4, $5)",
+		id, title, entityType, employeeID, capacity)
+	if err != nil {
+		return fmt.Errorf("failed to create %s %s: %w", entityType, id, err)
+	}
+	return nil
+}
+
+// insertOverrides inserts capacity overrides for an entity.
+func insertOverrides(ctx context.Context, db *helpers.DBHelper, entityID string, overrides map[time.Time]float64) error {
+	for date, capacity := range overrides {
+		_, err := db.Exec(ctx,
+			"INSERT INTO load_calendar_data.capacity_overrides (entity_id, date, capacity) VALUES ($1, $2::date, $3)",
+			entityID, date.Format(dateFormat), capacity)
+		if err != nil {
+			return fmt.Errorf("failed to set capacity override for %s on %s: %w", entityID, date.Format(dateFormat), err)
+		}
+	}
+	return nil
+}
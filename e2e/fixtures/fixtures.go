@@ -0,0 +1,62 @@
+// Package fixtures provides fluent builders for E2E test data.
+//
+// Builders describe a consistent graph of entities, group memberships,
+// capacity overrides, and loads, then insert it through helpers.DBHelper.
+// They replace hand-written INSERT statements in individual tests:
+//
+//	alice := fixtures.NewPerson("alice@example.com").WithCapacity(3).OnLeave(monday, friday)
+//	team := fixtures.NewGroup("team").WithMembers(alice)
+//	load := fixtures.NewLoad("ext-1").OnDate(tuesday).AssignedTo(alice, 2.0)
+//
+//	err := fixtures.NewScenario(alice, team, load).Insert(ctx, env.DB)
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// dateFormat is the layout used when passing dates to PostgreSQL.
+const dateFormat = "2006-01-02"
+
+// Fixture is anything that can insert itself into the test database.
+type Fixture interface {
+	Insert(ctx context.Context, db *helpers.DBHelper) error
+}
+
+// Scenario is an ordered collection of fixtures inserted together.
+//
+// Fixtures are inserted in the order they were added, so entities should be
+// added before the groups and loads that reference them.
+type Scenario struct {
+	fixtures []Fixture
+}
+
+// NewScenario creates a scenario from the given fixtures.
+func NewScenario(fixtures ...Fixture) *Scenario {
+	return &Scenario{fixtures: fixtures}
+}
+
+// Add appends fixtures to the scenario.
+func (s *Scenario) Add(fixtures ...Fixture) *Scenario {
+	s.fixtures = append(s.fixtures, fixtures...)
+	return s
+}
+
+// Insert inserts every fixture in order, stopping at the first error.
+func (s *Scenario) Insert(ctx context.Context, db *helpers.DBHelper) error {
+	for i, f := range s.fixtures {
+		if err := f.Insert(ctx, db); err != nil {
+			return fmt.Errorf("fixture %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// normalizeDate strips the time component so dates compare by day.
+func normalizeDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
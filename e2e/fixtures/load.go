@@ -0,0 +1,112 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// assignment is a pending load assignment.
+type assignment struct {
+	email  string
+	weight float64
+}
+
+// LoadBuilder builds a load and its assignments.
+type LoadBuilder struct {
+	id          int
+	externalID  string
+	title       string
+	source      string
+	url         *string
+	date        time.Time
+	assignments []assignment
+}
+
+// NewLoad starts a load fixture identified by external ID.
+//
+// The title defaults to the external ID, the source to "test",
+// and the date to today.
+func NewLoad(externalID string) *LoadBuilder {
+	return &LoadBuilder{
+		externalID: externalID,
+		title:      externalID,
+		source:     "test",
+		date:       normalizeDate(time.Now()),
+	}
+}
+
+// ID returns the database ID assigned on Insert (0 before insertion).
+func (b *LoadBuilder) ID() int {
+	return b.id
+}
+
+// WithTitle sets the load title.
+func (b *LoadBuilder) WithTitle(title string) *LoadBuilder {
+	b.title = title
+	return b
+}
+
+// WithSource sets the origin system.
+func (b *LoadBuilder) WithSource(source string) *LoadBuilder {
+	b.source = source
+	return b
+}
+
+// WithURL sets the link back to the origin system.
+func (b *LoadBuilder) WithURL(url string) *LoadBuilder {
+	b.url = &url
+	return b
+}
+
+// OnDate sets the load date.
+func (b *LoadBuilder) OnDate(date time.Time) *LoadBuilder {
+	b.date = normalizeDate(date)
+	return b
+}
+
+// AssignedTo assigns the load to a person with the given weight.
+//
+// The person must be inserted before the load.
+func (b *LoadBuilder) AssignedTo(person *PersonBuilder, weight float64) *LoadBuilder {
+	return b.AssignedToEmail(person.ID(), weight)
+}
+
+// AssignedToEmail assigns the load by email, for persons created elsewhere.
+func (b *LoadBuilder) AssignedToEmail(email string, weight float64) *LoadBuilder {
+	b.assignments = append(b.assignments, assignment{email: email, weight: weight})
+	return b
+}
+
+// Insert writes the load and its assignments, recording the new load ID.
+func (b *LoadBuilder) Insert(ctx context.Context, db *helpers.DBHelper) error {
+	rows, err := db.Query(ctx,
+		"INSERT INTO load_calendar_data.loads (external_id, title, source, url, date) VALUES ($1, $2, $3, $4, $5::date) RETURNING id",
+		b.externalID, b.title, b.source, b.url, b.date.Format(dateFormat))
+	if err != nil {
+		return fmt.Errorf("failed to create load %s: %w", b.externalID, err)
+	}
+	if rows.Next() {
+		err = rows.Scan(&b.id)
+	}
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read load ID for %s: %w", b.externalID, err)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to create load %s: %w", b.externalID, err)
+	}
+
+	for _, a := range b.assignments {
+		_, err := db.Exec(ctx,
+			"INSERT INTO load_calendar_data.load_assignments (load_id, person_email, weight) VALUES ($1, $2, $3)",
+			b.id, a.email, a.weight)
+		if err != nil {
+			return fmt.Errorf("failed to assign load %s to %s: %w", b.externalID, a.email, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package golden provides snapshot testing for HTML templates.
+//
+// Templates are rendered with fixed data and compared byte-for-byte against
+// files in testdata/. This catches template refactors that silently change
+// the structure HTMX selectors and swap targets rely on.
+//
+// Regenerate golden files after an intentional template change with:
+//
+//	go test ./e2e/golden/... -update
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/service"
+)
+
+// update rewrites golden files instead of comparing against them.
+var update = flag.Bool("update", false, "update golden files")
+
+// LoadTemplates parses the application templates from the project root.
+//
+// The function map mirrors the one registered by cmd/server so rendered
+// output matches production.
+func LoadTemplates() (*template.Template, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	funcMap := template.FuncMap{
+		// Served at the root, without a BASE_PATH
+		"url": func(path string) string {
+			return path
+		},
+		"formatDate": func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+		"formatDateTime": func(t time.Time) string {
+			return t.Format("Jan 2, 2006 3:04 PM")
+		},
+		"tagColor": service.TagColor,
+	}
+
+	templates, err := template.New("").Funcs(funcMap).ParseGlob(filepath.Join(root, "templates", "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err = templates.ParseGlob(filepath.Join(root, "templates", "partials", "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// Render executes the named template with data and returns the output.
+func Render(templates *template.Template, name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Assert compares got against testdata/<name>.golden.
+//
+// With -update, the golden file is (re)written and the comparison is skipped.
+func Assert(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create testdata dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to write golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s (run with -update to create it): %v", path, err)
+	}
+
+	if !bytes.Equal(want, got) {
+		t.Errorf("%s does not match golden file %s\n%s\nrun with -update if the change is intentional",
+			name, path, firstDifference(want, got))
+	}
+}
+
+// firstDifference describes the first line where want and got diverge.
+func firstDifference(want, got []byte) string {
+	wantLines := bytes.Split(want, []byte("\n"))
+	gotLines := bytes.Split(got, []byte("\n"))
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g []byte
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if !bytes.Equal(w, g) {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, w, g)
+		}
+	}
+	return "outputs differ"
+}
+
+// findProjectRoot finds the project root by looking for go.mod.
+func findProjectRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("could not find project root (go.mod)")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,379 @@
+package golden
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/handler"
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// fixedDate is the reference date used by all snapshot data.
+var fixedDate = time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestHeatmapGridGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"EntityID": "alice@example.com",
+		"Months": []handler.MonthData{
+			{
+				Year:      2025,
+				Month:     time.March,
+				MonthName: "March",
+				WeekStart: time.Monday,
+				Days: []handler.DayData{
+					{Date: fixedDate, DateStr: "2025-03-10", Day: 10, Load: 0, Capacity: 5, Color: "#e5e7eb"},
+					{Date: fixedDate.AddDate(0, 0, 1), DateStr: "2025-03-11", Day: 11, Load: 2.5, Capacity: 5, Color: "#fbbf24", IsToday: true},
+					{Date: fixedDate.AddDate(0, 0, 2), DateStr: "2025-03-12", Day: 12, Load: 6, Capacity: 5, Color: "#8B0000"},
+					{Date: fixedDate.AddDate(0, 0, 3), DateStr: "2025-03-13", Day: 13, Load: 0, Capacity: 0, Color: "#e5e7eb", Holiday: "Nyepi"},
+				},
+			},
+		},
+	}
+
+	got, err := Render(templates, "heatmap_grid", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "heatmap_grid", got)
+}
+
+func TestHeatmapGridSundayWeeksGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	// Saturday to Sunday: the Sunday starts a new row, labelled with the
+	// ISO week of the Monday after it
+	saturday := fixedDate.AddDate(0, 0, 5)
+	data := map[string]interface{}{
+		"EntityID": "alice@example.com",
+		"Months": []handler.MonthData{
+			{
+				Year:      2025,
+				Month:     time.March,
+				MonthName: "March",
+				WeekStart: time.Sunday,
+				Days: []handler.DayData{
+					{Date: saturday, DateStr: "2025-03-15", Day: 15, Load: 1, Capacity: 5, Color: "#86efac"},
+					{Date: saturday.AddDate(0, 0, 1), DateStr: "2025-03-16", Day: 16, Load: 0, Capacity: 5, Color: "#e5e7eb"},
+				},
+			},
+		},
+	}
+
+	got, err := Render(templates, "heatmap_grid", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "heatmap_grid_sunday", got)
+}
+
+func TestDashboardGridGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Months": []handler.MonthData{
+			{
+				Year:      2025,
+				Month:     time.March,
+				MonthName: "March",
+				WeekStart: time.Monday,
+				Days: []handler.DayData{
+					{Date: fixedDate, DateStr: "2025-03-10", Day: 10, Load: 0, Capacity: 0, Color: "#e5e7eb"},
+					{Date: fixedDate.AddDate(0, 0, 1), DateStr: "2025-03-11", Day: 11, Load: 2.5, Capacity: 5, Color: "#fbbf24", IsToday: true, Utilization: 50},
+					{Date: fixedDate.AddDate(0, 0, 2), DateStr: "2025-03-12", Day: 12, Load: 6, Capacity: 5, Color: "#8B0000", Utilization: 120},
+				},
+			},
+		},
+	}
+
+	got, err := Render(templates, "dashboard_grid", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "dashboard_grid", got)
+}
+
+func TestDayTasksGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Date":    fixedDate,
+		"DateStr": "2025-03-10",
+		"Loads": []models.LoadWithAssignments{
+			{
+				Load: models.Load{
+					ID:     1,
+					Title:  "Release Prep",
+					Source: strPtr("gcal"),
+					URL:    strPtr("https://example.com/event/1"),
+					Date:   fixedDate,
+					Tags:   []string{"meeting"},
+				},
+				Assignments: []models.LoadAssignment{
+					{LoadID: 1, PersonEmail: "alice@example.com", Weight: 4},
+				},
+				Fields: []models.CustomFieldValue{
+					{Key: "meeting_link", Label: "Meeting", Type: models.CustomFieldURL, Value: "https://meet.example.com/abc"},
+					{Key: "attendees", Label: "Attendees", Type: models.CustomFieldNumber, Value: 12.0},
+				},
+			},
+			{
+				Load: models.Load{
+					ID:    2,
+					Title: "Code Review",
+					Date:  fixedDate,
+				},
+				Assignments: []models.LoadAssignment{
+					{LoadID: 2, PersonEmail: "alice@example.com", Weight: 2},
+				},
+			},
+		},
+		"Tags": []models.TagLoad{
+			{Tag: "meeting", Load: 4, Color: "#db2777"},
+			{Load: 2, Color: "#9ca3af"},
+		},
+		"TotalLoad": 6.0,
+		"Capacity":  5.0,
+		"EntityID":  "alice@example.com",
+	}
+
+	got, err := Render(templates, "day_tasks", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "day_tasks", got)
+}
+
+func TestGroupDayTasksGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	release := models.Load{ID: 1, Title: "Release Prep", Date: fixedDate}
+	review := models.Load{ID: 2, Title: "Code Review", Date: fixedDate}
+	data := map[string]interface{}{
+		"Date":    fixedDate,
+		"DateStr": "2025-03-10",
+		"Loads": []models.LoadWithAssignments{
+			{Load: release, Assignments: []models.LoadAssignment{
+				{LoadID: 1, PersonEmail: "alice@example.com", Weight: 4},
+				{LoadID: 1, PersonEmail: "bob@example.com", Weight: 1},
+			}},
+			{Load: review, Assignments: []models.LoadAssignment{
+				{LoadID: 2, PersonEmail: "alice@example.com", Weight: 2},
+			}},
+		},
+		"Members": []models.MemberDayLoad{
+			{PersonEmail: "alice@example.com", Load: 6, Capacity: 5, Loads: []models.LoadWithAssignments{
+				{Load: release, Assignments: []models.LoadAssignment{{LoadID: 1, PersonEmail: "alice@example.com", Weight: 4}}},
+				{Load: review, Assignments: []models.LoadAssignment{{LoadID: 2, PersonEmail: "alice@example.com", Weight: 2}}},
+			}},
+			{PersonEmail: "bob@example.com", Load: 1, Capacity: 5, Loads: []models.LoadWithAssignments{
+				{Load: release, Assignments: []models.LoadAssignment{{LoadID: 1, PersonEmail: "bob@example.com", Weight: 1}}},
+			}},
+			{PersonEmail: "carol@example.com", Capacity: 4, Loads: []models.LoadWithAssignments{}},
+		},
+		"TotalLoad": 7.0,
+		"Capacity":  14.0,
+		"EntityID":  "backend",
+	}
+
+	got, err := Render(templates, "day_tasks", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "day_tasks_group", got)
+}
+
+func TestGroupDayMembersGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"EntityID": "backend",
+		"DateStr":  "2025-03-10",
+		"Members": []models.MemberDayBreakdown{
+			{PersonEmail: "alice@example.com", Title: "Alice", Load: 6, Capacity: 5, Color: "#8B0000", Overloaded: true},
+			{PersonEmail: "bob@example.com", Title: "Bob", Load: 1, Capacity: 5, Color: "#a3e635"},
+			{PersonEmail: "carol@example.com", Title: "Carol", Capacity: 4, Color: "#e5e7eb"},
+		},
+	}
+
+	got, err := Render(templates, "day_members", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "day_members", got)
+}
+
+func TestCapacityFormGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Entity": &models.Entity{
+			ID:              "alice@example.com",
+			Title:           "Alice Johnson",
+			Type:            models.EntityTypePerson,
+			DefaultCapacity: 5,
+		},
+		"Overrides": []models.CapacityOverride{
+			{EntityID: "alice@example.com", Date: fixedDate, Capacity: 0},
+			{EntityID: "alice@example.com", Date: fixedDate.AddDate(0, 0, 3), Capacity: 2.5},
+		},
+		"IsAuthenticated": true,
+		"UserEmail":       "alice@example.com",
+	}
+
+	got, err := Render(templates, "capacity_form", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "capacity_form", got)
+}
+
+func TestStatusPageGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	lastSync := fixedDate.Add(9 * time.Hour)
+	resolvedAt := fixedDate.Add(-20 * time.Hour)
+	report := &models.StatusReport{
+		GeneratedAt: fixedDate.Add(10 * time.Hour),
+		StartedAt:   fixedDate.Add(-50 * time.Hour),
+		Uptime:      60 * time.Hour,
+		DatabaseOK:  true,
+		Sources: []models.SourceHealth{
+			{Source: "calendar", LastSyncAt: &lastSync},
+			{Source: "jira", LastSyncAt: &lastSync},
+		},
+		Incidents: []models.IncidentNote{
+			{ID: 2, Body: "Jira sync is delayed; loads may be up to an hour old", AuthorEmail: "admin@example.com", CreatedAt: fixedDate.Add(8 * time.Hour)},
+			{ID: 1, Body: "Login emails were not sent", AuthorEmail: "admin@example.com", CreatedAt: fixedDate.Add(-22 * time.Hour), ResolvedAt: &resolvedAt},
+		},
+	}
+	data := map[string]interface{}{
+		"Report":  report,
+		"Uptime":  "2d 12h 0m",
+		"IsAdmin": true,
+	}
+
+	got, err := Render(templates, "status", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "status", got)
+}
+
+func TestPresenceGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	data := &models.PresenceResponse{
+		Subject: "entity:team-a",
+		Others: []models.Presence{
+			{Email: "bob@example.com", Name: "Bob", Mode: models.PresenceEditing, SeenAt: fixedDate},
+			{Email: "carol@example.com", Name: "carol@example.com", Mode: models.PresenceViewing, SeenAt: fixedDate},
+		},
+	}
+
+	got, err := Render(templates, "presence", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "presence", got)
+}
+
+func TestSettingsPageGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	updatedAt := fixedDate.Add(9 * time.Hour)
+	settings := models.Settings{
+		ColorThresholds:      []float64{0.25, 0.5, 0.7, 0.9, 1.1},
+		DefaultCapacity:      6,
+		AlertCooldownMinutes: 30,
+		WebhookSecretSet:     true,
+		UpdatedAt:            &updatedAt,
+		UpdatedBy:            "admin@example.com",
+	}
+	type threshold struct {
+		Name  string
+		Color string
+		Ratio float64
+	}
+	data := map[string]interface{}{
+		"Settings": settings,
+		"Thresholds": []threshold{
+			{"Lime", "#a3e635", 0.25},
+			{"Amber", "#fbbf24", 0.5},
+			{"Orange", "#f97316", 0.7},
+			{"Red", "#dc2626", 0.9},
+			{"Blood red", "#8B0000", 1.1},
+		},
+		"UserEmail": "admin@example.com",
+	}
+
+	got, err := Render(templates, "settings", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "settings", got)
+}
+
+func TestEntityInfoGolden(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	updatedAt := fixedDate.Add(9 * time.Hour)
+	data := map[string]interface{}{
+		"SelectedEntity": "platform",
+		"CanEditInfo":    true,
+		"EntityInfo": &models.EntityInfo{
+			EntityID: "platform",
+			Notes:    "Standup at 09:30 in the big room.\nAsk in #platform before paging.",
+			Links: []models.EntityLink{
+				{Title: "Team charter", URL: "https://wiki.example.com/platform/charter"},
+				{Title: "On-call schedule", URL: "https://oncall.example.com/platform"},
+			},
+			UpdatedBy: "admin@example.com",
+			UpdatedAt: &updatedAt,
+		},
+	}
+
+	got, err := Render(templates, "entity_info", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Assert(t, "entity_info", got)
+}
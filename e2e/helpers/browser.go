@@ -3,6 +3,10 @@ package helpers
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -17,8 +21,9 @@ import (
 //   - Verify page content and element states
 //   - Test HTMX-powered dynamic updates
 //
-// Browser intentionally exposes only five methods (Navigate, Click, Fill, Text, Wait)
-// to keep the E2E testing interface minimal and focused.
+// Browser intentionally exposes a small set of methods (Navigate, Click, Fill,
+// Text, Wait, Attr, Screenshot) to keep the E2E testing interface minimal and
+// focused.
 type Browser struct {
 	browser *rod.Browser
 	page    *rod.Page
@@ -145,6 +150,78 @@ func (b *Browser) Wait(selector string) error {
 	return nil
 }
 
+// Attr returns the value of an attribute on the element matching the CSS selector.
+//
+// Use this to assert on HTMX wiring such as hx-get URLs and swap targets.
+// Returns an error if the element or the attribute does not exist.
+//
+//	target, err := browser.Attr("form", "hx-target")
+//	assert.Equal(t, "#login-form-container", target)
+func (b *Browser) Attr(selector, name string) (string, error) {
+	el, err := b.page.Timeout(b.timeout).Element(selector)
+	if err != nil {
+		return "", fmt.Errorf("failed to find element %s: %w", selector, err)
+	}
+	value, err := el.Attribute(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attribute %s from %s: %w", name, selector, err)
+	}
+	if value == nil {
+		return "", fmt.Errorf("attribute %s not found on %s", name, selector)
+	}
+	return *value, nil
+}
+
+// Screenshot saves a full-page PNG of the current page to path.
+//
+// Parent directories are created as needed.
+//
+//	err := browser.Screenshot("screenshots/login.png")
+func (b *Browser) Screenshot(path string) error {
+	data, err := b.page.Timeout(b.timeout).Screenshot(true, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write screenshot %s: %w", path, err)
+	}
+	return nil
+}
+
+// ScreenshotOnFailure registers a cleanup that captures the current page if
+// the test fails, so broken HTMX flows leave evidence behind.
+//
+// Screenshots are written to $E2E_SCREENSHOT_DIR (default "screenshots")
+// as <TestName>.png.
+//
+//	browser, err := env.Browser()
+//	a.NoError(err, "should start browser")
+//	browser.ScreenshotOnFailure(t)
+func (b *Browser) ScreenshotOnFailure(t testing.TB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		dir := os.Getenv("E2E_SCREENSHOT_DIR")
+		if dir == "" {
+			dir = "screenshots"
+		}
+		name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()) + ".png"
+		path := filepath.Join(dir, name)
+		if err := b.Screenshot(path); err != nil {
+			t.Logf("failed to capture failure screenshot: %v", err)
+			return
+		}
+		t.Logf("saved failure screenshot to %s", path)
+	})
+}
+
 // Close releases browser resources.
 //
 // Always call Close() when done with the browser, typically using defer:
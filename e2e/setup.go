@@ -14,6 +14,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/gti/heatmap-internal/e2e/fixtures"
 	"github.com/gti/heatmap-internal/e2e/helpers"
 	"github.com/gti/heatmap-internal/internal/database"
 	"github.com/gti/heatmap-internal/internal/handler"
@@ -218,10 +219,32 @@ func (env *TestEnv) CleanupTestData(ctx context.Context) error {
 		"load_calendar_data.sessions",
 		"load_calendar_data.otp_records",
 		"load_calendar_data.load_assignments",
+		"load_calendar_data.load_tags",
 		"load_calendar_data.loads",
 		"load_calendar_data.capacity_overrides",
 		"load_calendar_data.group_members",
 		"load_calendar_data.entities",
+		"load_calendar_data.heatmap_tombstones",
+		"load_calendar_data.scenarios",
+		"load_calendar_data.integration_errors",
+		"load_calendar_data.webhook_deliveries",
+		"load_calendar_data.webhook_endpoints",
+		"load_calendar_data.incident_notes",
+		"load_calendar_data.presence",
+		"load_calendar_data.custom_field_definitions",
+		"load_calendar_data.lark_digests",
+		"load_calendar_data.entity_tombstones",
+		"load_calendar_data.holidays",
+		"load_calendar_data.settings",
+		"load_calendar_data.rate_limits",
+		"load_calendar_data.api_key_metrics",
+		"load_calendar_data.entity_info",
+		"load_calendar_data.load_anomalies",
+		"load_calendar_data.shadow_sources",
+		"load_calendar_data.oidc_logins",
+		"load_calendar_data.domain_events",
+		"load_calendar_data.jobs",
+		"load_calendar_data.utilization_reports",
 	}
 
 	for _, table := range tables {
@@ -240,10 +263,10 @@ func (env *TestEnv) CleanupTestData(ctx context.Context) error {
 //
 //	err := env.SeedTestEntity(ctx, "test@example.com", "Test User", "person", 5.0)
 func (env *TestEnv) SeedTestEntity(ctx context.Context, id, title, entityType string, capacity float64) error {
-	_, err := env.DB.Exec(ctx,
-		"INSERT INTO load_calendar_data.entities (id, title, type, default_capacity) VALUES ($1, $2, $3, $4)",
-		id, title, entityType, capacity)
-	return err
+	if entityType == "group" {
+		return fixtures.NewGroup(id).WithTitle(title).WithCapacity(capacity).Insert(ctx, env.DB)
+	}
+	return fixtures.NewPerson(id).WithTitle(title).WithCapacity(capacity).Insert(ctx, env.DB)
 }
 
 // SeedTestLoad creates a test load with assignment in the database.
@@ -252,18 +275,25 @@ func (env *TestEnv) SeedTestEntity(ctx context.Context, id, title, entityType st
 //
 //	err := env.SeedTestLoad(ctx, "ext-123", "Test Load", "test@example.com", time.Now(), 2.5)
 func (env *TestEnv) SeedTestLoad(ctx context.Context, externalID, title, assignee string, date time.Time, weight float64) error {
-	var loadID int
-	err := env.Pool.QueryRow(ctx,
-		"INSERT INTO load_calendar_data.loads (external_id, title, source, date) VALUES ($1, $2, 'test', $3) RETURNING id",
-		externalID, title, date).Scan(&loadID)
-	if err != nil {
-		return fmt.Errorf("failed to create load: %w", err)
-	}
+	return fixtures.NewLoad(externalID).
+		WithTitle(title).
+		OnDate(date).
+		AssignedToEmail(assignee, weight).
+		Insert(ctx, env.DB)
+}
 
-	_, err = env.DB.Exec(ctx,
-		"INSERT INTO load_calendar_data.load_assignments (load_id, person_email, weight) VALUES ($1, $2, $3)",
-		loadID, assignee, weight)
-	return err
+// dryRunRoutes are the writes that honor ?dry_run=true, as in cmd/server
+var dryRunRoutes = []string{
+	"POST /loads/upsert",
+	"POST /loads/upsert-by-employee-id",
+	"DELETE /loads/by-external-id/:external_id",
+	"POST /loads/:id/assignees",
+	"DELETE /loads/:id/assignees/:email",
+	"POST /entities",
+	"PUT /entities/:id",
+	"DELETE /entities/:id",
+	"POST /api/my-capacity",
+	"DELETE /api/my-capacity/override/:date",
 }
 
 // startServer initializes and starts the Echo server.
@@ -273,13 +303,56 @@ func (env *TestEnv) startServer(db *database.DB, apiKey string, port int) error
 	groupRepo := repository.NewGroupRepository(db.Pool)
 	capacityRepo := repository.NewCapacityRepository(db.Pool)
 	loadRepo := repository.NewLoadRepository(db.Pool)
-
-	// Initialize services
+	snapshotRepo := repository.NewSnapshotRepository(db.Pool)
+	scenarioRepo := repository.NewScenarioRepository(db.Pool)
+	overloadRepo := repository.NewOverloadRepository(db.Pool)
+	reportRepo := repository.NewReportRepository(db.Pool)
+	delegationRepo := repository.NewDelegationRepository(db.Pool)
+	blackoutRepo := repository.NewBlackoutRepository(db.Pool)
+	holidayRepo := repository.NewHolidayRepository(db.Pool)
+	noteRepo := repository.NewNoteRepository(db.Pool)
+	pinRepo := repository.NewPinRepository(db.Pool)
+	integrationRepo := repository.NewIntegrationRepository(db.Pool)
+	eventRepo := repository.NewEventRepository(db.Pool)
+	jobRepo := repository.NewJobRepository(db.Pool)
+
+	// Initialize services. The render cache stays disabled (nil) because
+	// fixtures write straight to the database.
+	events := service.NewEventLog(eventRepo)
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db.Pool))
+	settingsService.RecordEvents(events)
 	webhookService := service.NewWebhookService("", loadRepo, capacityRepo) // No webhook in tests
-	heatmapService := service.NewHeatmapService(entityRepo, capacityRepo, loadRepo, groupRepo)
-	loadService := service.NewLoadService(loadRepo, entityRepo, webhookService)
-	authService := service.NewAuthService(db.Pool, "", "") // No Lark in tests
-	capacityService := service.NewCapacityService(entityRepo, capacityRepo)
+	webhookService.UseSettings(settingsService)
+	webhookService.SetEndpoints(repository.NewWebhookEndpointRepository(db.Pool))
+	webhookService.AlertGroups(groupRepo)
+	heatmapService := service.NewHeatmapService(entityRepo, capacityRepo, loadRepo, groupRepo, snapshotRepo, scenarioRepo, holidayRepo)
+	heatmapService.RecordEvents(events)
+	loadService := service.NewLoadService(loadRepo, entityRepo, groupRepo, blackoutRepo, capacityRepo, webhookService, nil)
+	loadService.RecordEvents(events)
+	loadService.UseSettings(settingsService)
+	loadService.UseShadowSources(scenarioRepo)
+	customFieldService := service.NewCustomFieldService(repository.NewCustomFieldRepository(db.Pool))
+	loadService.CheckCustomFields(customFieldService)
+	rateLimitRepo := repository.NewRateLimitRepository(db.Pool)
+	authService := service.NewAuthService(db.Pool, service.ConsoleOTPDeliverer{}) // No Lark in tests
+	authService.LimitOTPRequests(rateLimitRepo, 5)
+	capacityService := service.NewCapacityService(entityRepo, capacityRepo, groupRepo, delegationRepo, nil)
+	capacityService.RecordEvents(events)
+	capacityService.NotifyWebhooks(webhookService)
+	capacityService.ReportLoads(loadRepo)
+	peopleService := service.NewPeopleService(entityRepo, webhookService, nil)
+	peopleService.RecordEvents(events)
+	peopleService.UseSettings(settingsService)
+	overloadService := service.NewOverloadService(overloadRepo, entityRepo)
+	reportService := service.NewReportService(reportRepo)
+	noteService := service.NewNoteService(noteRepo)
+	pinService := service.NewPinService(pinRepo)
+	integrationService := service.NewIntegrationService(integrationRepo)
+	statusService := service.NewStatusService(db.Health, integrationRepo, repository.NewIncidentRepository(db.Pool), time.Now())
+	presenceService := service.NewPresenceService(repository.NewPresenceRepository(db.Pool), entityRepo, loadRepo)
+	digestService := service.NewDigestService(repository.NewDigestRepository(db.Pool), entityRepo, groupRepo, heatmapService,
+		service.NewLarkClient("", ""), "") // No Lark in tests
+	jobRunner := service.NewJobRunner(context.Background(), jobRepo)
 
 	// Load templates
 	templates, err := loadTestTemplates()
@@ -288,52 +361,192 @@ func (env *TestEnv) startServer(db *database.DB, apiKey string, port int) error
 	}
 
 	// Initialize handlers
-	heatmapHandler := handler.NewHeatmapHandler(heatmapService, entityRepo, templates)
-	apiHandler := handler.NewAPIHandler(loadService, entityRepo, groupRepo)
+	heatmapHandler := handler.NewHeatmapHandler(heatmapService, noteService, pinService, customFieldService, entityRepo, scenarioRepo, templates, nil)
+	apiHandler := handler.NewAPIHandler(loadService, heatmapService, integrationService, settingsService, events, entityRepo, groupRepo, nil)
 	authHandler := handler.NewAuthHandler(authService, entityRepo, templates)
 	capacityHandler := handler.NewCapacityHandler(capacityService, templates)
+	peopleHandler := handler.NewPeopleHandler(peopleService)
+	scenarioHandler := handler.NewScenarioHandler(scenarioRepo, heatmapService, templates)
+	overloadHandler := handler.NewOverloadHandler(overloadService)
+	reportHandler := handler.NewReportHandler(reportService, heatmapService)
+	noteHandler := handler.NewNoteHandler(noteService)
+	integrationHandler := handler.NewIntegrationHandler(integrationService, templates)
+	statusHandler := handler.NewStatusHandler(statusService, templates)
+	presenceHandler := handler.NewPresenceHandler(presenceService, templates)
+	eventHandler := handler.NewEventHandler(events)
+	jobHandler := handler.NewJobHandler(jobRunner, loadService, heatmapService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	customFieldHandler := handler.NewCustomFieldHandler(customFieldService)
+	digestHandler := handler.NewDigestHandler(digestService)
+	holidayHandler := handler.NewHolidayHandler(service.NewHolidayService(holidayRepo, ""), nil)
+	settingsHandler := handler.NewSettingsHandler(settingsService, templates, nil)
+	apiMetricsService := service.NewAPIMetricsService(repository.NewAPIMetricsRepository(db.Pool))
+	apiMetricsHandler := handler.NewAPIMetricsHandler(apiMetricsService)
+	linkHandler := handler.NewLinkHandler(nil, heatmapService, noteService, capacityService, templates) // No notification links in tests
 
 	// Create Echo instance
 	e := echo.New()
 	e.HideBanner = true
+	e.IPExtractor = middleware.ClientIP(nil)
 	e.HidePort = true
 
+	e.Use(middleware.Trace())
+
 	// Optional session auth
 	e.Use(middleware.SessionAuthOptional(authService))
 
 	// Public routes
 	e.GET("/", heatmapHandler.Index)
 	e.GET("/login", authHandler.LoginPage)
-
-	// Auth routes
-	e.POST("/auth/request-otp", authHandler.RequestOTP)
-	e.POST("/auth/verify-otp", authHandler.VerifyOTP)
+	e.GET("/dashboard/:group", heatmapHandler.Dashboard)
+	e.GET("/integrations", integrationHandler.IntegrationsPage)
+	e.GET("/status", statusHandler.StatusPage)
+	e.GET("/links/day/:email/:date", linkHandler.LinkedDay)
+	e.GET("/links/settings/:email", linkHandler.LinkedSettings)
+
+	// Auth routes, with the default limits
+	otpLimiter := middleware.NewRateLimiter(rateLimitRepo, 20, time.Hour)
+	e.POST("/auth/request-otp", authHandler.RequestOTP, middleware.RateLimit(otpLimiter, "request-otp"))
+	e.POST("/auth/verify-otp", authHandler.VerifyOTP, middleware.RateLimit(otpLimiter, "verify-otp"))
 	e.POST("/auth/logout", authHandler.Logout)
+	e.GET("/auth/oidc/login", authHandler.OIDCLogin)
+	e.GET("/auth/oidc/callback", authHandler.OIDCCallback)
 
 	// Protected routes (require session)
 	protected := e.Group("")
-	protected.Use(middleware.SessionAuth(authService))
+	protected.Use(middleware.SessionAuth(authService), middleware.DryRun(dryRunRoutes...))
 	protected.GET("/my-capacity", capacityHandler.MyCapacityPage)
 	protected.POST("/api/my-capacity", capacityHandler.UpdateMyCapacity)
 	protected.DELETE("/api/my-capacity/override/:date", capacityHandler.DeleteMyCapacityOverride)
+	protected.DELETE("/api/my-capacity/overrides", capacityHandler.DeleteMyCapacityOverrides)
+	protected.GET("/api/my-capacity/audit", capacityHandler.GetMyCapacityAudit)
+	protected.GET("/api/my-delegations", capacityHandler.ListMyDelegations)
+	protected.POST("/api/my-delegations", capacityHandler.AddMyDelegation)
+	protected.DELETE("/api/my-delegations/:assistant", capacityHandler.RemoveMyDelegation)
+	protected.POST("/api/loads/:id/acknowledge", apiHandler.AcknowledgeLoad)
+	protected.GET("/api/my-loads/unacknowledged", apiHandler.ListMyUnacknowledgedLoads)
+	protected.POST("/api/loads/:id/actual", apiHandler.RecordLoadActual)
+	protected.POST("/api/loads/:id/notes", noteHandler.AddLoadNote)
+	protected.POST("/api/my-notes", noteHandler.AddMyDayNote)
+	protected.DELETE("/api/my-notes/:id", noteHandler.DeleteMyNote)
+	protected.PUT("/api/entities/:id/info", noteHandler.UpdateEntityInfo)
+	protected.POST("/api/status/incidents", statusHandler.AddIncident)
+	protected.PUT("/api/status/incidents/:id", statusHandler.UpdateIncident)
+	protected.DELETE("/api/status/incidents/:id", statusHandler.DeleteIncident)
+	protected.GET("/settings", settingsHandler.SettingsPage)
+	protected.PUT("/api/settings", settingsHandler.UpdateSettings)
+	protected.GET("/api/admin/api-metrics", apiMetricsHandler.GetAPIMetrics)
+	protected.DELETE("/api/admin/capacity/:id/overrides", capacityHandler.DeleteCapacityOverrides)
+	protected.POST("/api/presence/:kind/:id", presenceHandler.Heartbeat)
+	protected.POST("/api/loads/:id/pin", heatmapHandler.PinLoad)
+	protected.DELETE("/api/loads/:id/pin", heatmapHandler.UnpinLoad)
+	protected.GET("/api/my-pins", heatmapHandler.ListMyPins)
+	protected.GET("/api/my-preferences", heatmapHandler.GetMyPreferences)
+	protected.PUT("/api/my-preferences", heatmapHandler.UpdateMyPreferences)
+	protected.GET("/api/capacity-approvals", capacityHandler.ListCapacityApprovals)
+	protected.POST("/api/capacity-approvals/:id/approve", capacityHandler.ApproveCapacityChange)
+	protected.POST("/api/capacity-approvals/:id/reject", capacityHandler.RejectCapacityChange)
 
 	// Public API routes
-	e.GET("/api/entities", apiHandler.ListEntities)
+	e.GET("/api/entities", apiHandler.ListEntities, middleware.CacheControl(middleware.CachePartial), middleware.ETag())
+	e.GET("/api/entities/changes", apiHandler.ListEntityChanges)
 	e.GET("/api/entities/:id", apiHandler.GetEntity)
+	e.GET("/api/entities/:id/calendar.ics", apiHandler.GetEntityCalendar)
+	e.GET("/api/scenarios", scenarioHandler.ListScenarios)
+	e.GET("/api/scenarios/:id", scenarioHandler.GetScenario)
+	e.GET("/api/scenarios/:id/heatmap/:entity", scenarioHandler.GetScenarioHeatmap)
+	e.GET("/api/integrations/health", integrationHandler.GetIntegrationHealth)
+	e.GET("/metrics", overloadHandler.Metrics)
 	e.GET("/api/heatmap/:entity", heatmapHandler.GetHeatmapPartial)
+	e.GET("/api/heatmap/:entity/json", heatmapHandler.GetHeatmapJSON)
 	e.GET("/api/heatmap/:entity/day/:date", heatmapHandler.GetDayDetails)
-
-	// Protected API routes
-	apiProtected := e.Group("/api")
-	apiProtected.Use(middleware.APIKeyAuth(apiKey))
-	apiProtected.POST("/loads/upsert", apiHandler.UpsertLoad)
-	apiProtected.POST("/loads/:id/assignees", apiHandler.AddAssigneesToLoad)
-	apiProtected.DELETE("/loads/:id/assignees/:email", apiHandler.RemoveAssigneeFromLoad)
-	apiProtected.POST("/entities", apiHandler.CreateEntity)
-	apiProtected.DELETE("/entities/:id", apiHandler.DeleteEntity)
-	apiProtected.GET("/groups/:id/members", apiHandler.GetGroupMembers)
-	apiProtected.POST("/groups/:id/members", apiHandler.AddGroupMember)
-	apiProtected.DELETE("/groups/:id/members/:member", apiHandler.RemoveGroupMember)
+	e.GET("/api/heatmap/:entity/day/:date/members", heatmapHandler.GetGroupDayBreakdown)
+	e.GET("/api/dashboard/:group", heatmapHandler.GetDashboardPartial)
+	e.GET("/api/heatmap/:entity/reports", heatmapHandler.GetRollupHeatmap)
+
+	// Protected API routes, served unversioned (deprecated), as v1, and as v2
+	// with structured errors, as in cmd/server
+	mount := func(g *echo.Group) {
+		g.POST("/loads/upsert", apiHandler.UpsertLoad)
+		g.POST("/loads/upsert-by-employee-id", apiHandler.UpsertLoadByEmployeeID)
+		g.POST("/loads/from-issue", apiHandler.UpsertLoadFromIssue)
+		g.POST("/loads/:id/assignees", apiHandler.AddAssigneesToLoad)
+		g.DELETE("/loads/:id/assignees/:email", apiHandler.RemoveAssigneeFromLoad)
+		g.DELETE("/loads/by-external-id/:external_id", apiHandler.DeleteLoadByExternalID)
+		g.GET("/loads", apiHandler.ListLoads)
+		g.GET("/loads/stale", apiHandler.GetStaleLoads)
+		g.POST("/loads/stale/delete", apiHandler.DeleteStaleLoads)
+		g.GET("/utilization", apiHandler.ListUtilization)
+		g.POST("/entities", apiHandler.CreateEntity)
+		g.PUT("/entities/:id", apiHandler.UpdateEntity)
+		g.GET("/entities/:id/delete-preview", apiHandler.GetDeletePreview)
+		g.DELETE("/entities/:id", apiHandler.DeleteEntity)
+		g.POST("/entities/:id/archive", apiHandler.ArchiveEntity)
+		g.POST("/entities/:id/restore", apiHandler.RestoreEntity)
+		g.GET("/entities/:id/blackouts", apiHandler.ListBlackouts)
+		g.POST("/entities/:id/blackouts", apiHandler.AddBlackout)
+		g.DELETE("/entities/:id/blackouts/:blackout", apiHandler.DeleteBlackout)
+		g.GET("/groups/:id/members", apiHandler.GetGroupMembers)
+		g.POST("/groups/:id/members", apiHandler.AddGroupMember)
+		g.DELETE("/groups/:id/members/:member", apiHandler.RemoveGroupMember)
+		g.GET("/groups/:id/heatmap-exclusions", apiHandler.GetGroupHeatmapExclusions)
+		g.PUT("/groups/:id/heatmap-exclusions/:member", apiHandler.ExcludeGroupMember)
+		g.DELETE("/groups/:id/heatmap-exclusions/:member", apiHandler.IncludeGroupMember)
+		g.GET("/groups/:id/least-loaded", apiHandler.GetLeastLoaded)
+		g.GET("/rebalance/:group", apiHandler.RebalanceGroup)
+		g.GET("/groups/:id/owners", apiHandler.GetGroupOwners)
+		g.POST("/groups/:id/owners", apiHandler.AddGroupOwner)
+		g.DELETE("/groups/:id/owners/:owner", apiHandler.RemoveGroupOwner)
+		g.PUT("/groups/:id/dashboard", apiHandler.EnableGroupDashboard)
+		g.DELETE("/groups/:id/dashboard", apiHandler.DisableGroupDashboard)
+		g.GET("/groups/:id/lark-digest", digestHandler.GetLarkDigest)
+		g.PUT("/groups/:id/lark-digest", digestHandler.PutLarkDigest)
+		g.DELETE("/groups/:id/lark-digest", digestHandler.DeleteLarkDigest)
+		g.POST("/suggest-assignee", apiHandler.SuggestAssignee)
+
+		unscoped := middleware.UnscopedAPIKey()
+		g.GET("/events", eventHandler.ListEvents, unscoped)
+		g.GET("/reports/overload-resolution", overloadHandler.GetResolutionReport, unscoped)
+		g.GET("/reports/utilization", reportHandler.GetUtilizationReport, unscoped)
+		g.GET("/reports/calibration", reportHandler.GetCalibrationReport, unscoped)
+		g.POST("/loads/bulk-upsert", jobHandler.BulkUpsertLoads, unscoped)
+		g.POST("/loads/reassign", jobHandler.ReassignLoads, unscoped)
+		g.POST("/snapshots/backfill", jobHandler.BackfillSnapshots, unscoped)
+		g.GET("/jobs/:id", jobHandler.GetJob, unscoped)
+		g.GET("/webhooks", webhookHandler.ListWebhooks, unscoped)
+		g.POST("/webhooks", webhookHandler.CreateWebhook, unscoped)
+		g.GET("/webhooks/:id", webhookHandler.GetWebhook, unscoped)
+		g.PUT("/webhooks/:id", webhookHandler.UpdateWebhook, unscoped)
+		g.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook, unscoped)
+		g.GET("/custom-fields", customFieldHandler.ListCustomFields, unscoped)
+		g.PUT("/custom-fields/:source/:key", customFieldHandler.PutCustomField, unscoped)
+		g.DELETE("/custom-fields/:source/:key", customFieldHandler.DeleteCustomField, unscoped)
+		g.GET("/holidays/:region", holidayHandler.ListHolidays, unscoped)
+		g.POST("/holidays/:region/import", holidayHandler.ImportHolidays, unscoped)
+		g.POST("/holidays/:region/fetch", holidayHandler.FetchHolidays, unscoped)
+		g.DELETE("/holidays/:region/:date", holidayHandler.DeleteHoliday, unscoped)
+		g.POST("/groups/import", peopleHandler.ImportGroups, unscoped)
+		g.POST("/people/onboard", peopleHandler.OnboardPerson, unscoped)
+		g.GET("/people/auto-created", peopleHandler.ListAutoCreatedPersons, unscoped)
+		g.POST("/people/auto-created/confirm", peopleHandler.ConfirmAutoCreatedPersons, unscoped)
+		g.POST("/people/auto-created/reject", peopleHandler.RejectAutoCreatedPersons, unscoped)
+		g.POST("/people/:email/offboard", peopleHandler.OffboardPerson, unscoped)
+		g.POST("/entities/:id/change-id", peopleHandler.ChangeEntityID, unscoped)
+		g.POST("/scenarios", scenarioHandler.CreateScenario, unscoped)
+		g.DELETE("/scenarios/:id", scenarioHandler.DeleteScenario, unscoped)
+		g.POST("/scenarios/:id/loads", scenarioHandler.AddScenarioLoad, unscoped)
+		g.DELETE("/scenarios/:id/loads/:load", scenarioHandler.DeleteScenarioLoad, unscoped)
+		g.PUT("/scenarios/:id/capacity", scenarioHandler.SetScenarioCapacity, unscoped)
+		g.DELETE("/scenarios/:id/capacity/:entity/:date", scenarioHandler.DeleteScenarioCapacity, unscoped)
+		g.GET("/shadow-sources", scenarioHandler.ListShadowSources)
+		g.POST("/shadow-sources", scenarioHandler.CreateShadowSource, unscoped)
+		g.DELETE("/shadow-sources/:source", scenarioHandler.DeleteShadowSource, unscoped)
+	}
+	dryRun := middleware.DryRun(dryRunRoutes...)
+	metrics := middleware.APIMetrics(apiMetricsService)
+	mount(e.Group("/api", middleware.Deprecated("/api", "/api/v1", time.Time{}), metrics, middleware.APIKeyAuth(apiKey, nil), dryRun))
+	mount(e.Group("/api/v1", metrics, middleware.APIKeyAuth(apiKey, nil), dryRun))
+	mount(e.Group("/api/v2", middleware.ErrorEnvelope(), metrics, middleware.APIKeyAuth(apiKey, nil), dryRun))
 
 	// Static files
 	e.Static("/static", "static")
@@ -371,12 +584,17 @@ func (env *TestEnv) waitForServer(timeout time.Duration) error {
 // loadTestTemplates loads HTML templates for the test server.
 func loadTestTemplates() (*template.Template, error) {
 	funcMap := template.FuncMap{
+		// Served at the root, without a BASE_PATH
+		"url": func(path string) string {
+			return path
+		},
 		"formatDate": func(t time.Time) string {
 			return t.Format("2006-01-02")
 		},
 		"formatDateTime": func(t time.Time) string {
 			return t.Format("Jan 2, 2006 3:04 PM")
 		},
+		"tagColor": service.TagColor,
 	}
 
 	templates, err := template.New("").Funcs(funcMap).ParseGlob("templates/*.html")
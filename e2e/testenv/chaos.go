@@ -0,0 +1,321 @@
+package testenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ChaosConfig describes the faults a ChaosProxy injects between the service
+// and PostgreSQL. The zero value injects nothing.
+type ChaosConfig struct {
+	// Latency delays every packet the service sends to PostgreSQL, so each
+	// query round trip is slowed by at least this much.
+	Latency time.Duration
+
+	// Jitter adds a random extra delay in [0, Jitter) on top of Latency.
+	Jitter time.Duration
+
+	// FailureRate is the probability (0..1) that a packet from the service
+	// resets its connection instead of being forwarded. The query fails
+	// and the handler typically answers with a 500.
+	FailureRate float64
+
+	// Seed makes the failure sequence reproducible (0 uses a fixed seed).
+	Seed int64
+}
+
+// ChaosStats counts what a ChaosProxy has done so far.
+type ChaosStats struct {
+	// Connections is the number of connections accepted.
+	Connections int
+
+	// Delayed is the number of packets held back by Latency or Jitter.
+	Delayed int
+
+	// Failures is the number of connections reset by FailureRate.
+	Failures int
+}
+
+// ChaosProxy is a TCP proxy that sits between the service and PostgreSQL
+// and injects latency and connection failures on demand.
+//
+// Faults can be changed at any time with Configure and switched off with
+// Disable; the test's own DB helpers connect directly and are unaffected,
+// so fixtures can be seeded while the service is being starved:
+//
+//	ded, err := env.NewDedicatedEnv(ctx, t.Name(), testenv.WithChaos())
+//	...
+//	ded.Chaos.Configure(testenv.ChaosConfig{FailureRate: 1})
+//	resp, _ := ded.API.Get("/api/entities") // 500
+//	ded.Chaos.Disable()
+type ChaosProxy struct {
+	// Addr is the host:port the service should connect to.
+	Addr string
+
+	target   string
+	listener net.Listener
+
+	mu     sync.Mutex
+	cfg    ChaosConfig
+	rng    *rand.Rand
+	stats  ChaosStats
+	conns  map[net.Conn]struct{}
+	closed bool
+
+	wg sync.WaitGroup
+}
+
+// StartChaosProxy starts a proxy on a random local port forwarding to
+// target (host:port). It starts with no faults configured.
+//
+// Always call Close when done.
+func StartChaosProxy(target string) (*ChaosProxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start chaos proxy: %w", err)
+	}
+
+	p := &ChaosProxy{
+		Addr:     listener.Addr().String(),
+		target:   target,
+		listener: listener,
+		rng:      rand.New(rand.NewSource(1)), //nolint:gosec // Reproducible fault injection, not security
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.accept()
+
+	return p, nil
+}
+
+// Configure replaces the active faults. It applies to packets sent after
+// the call, including on connections that are already open.
+func (p *ChaosProxy) Configure(cfg ChaosConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	p.cfg = cfg
+	p.rng = rand.New(rand.NewSource(seed)) //nolint:gosec // Reproducible fault injection, not security
+}
+
+// SetLatency changes only the injected latency.
+func (p *ChaosProxy) SetLatency(latency, jitter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg.Latency = latency
+	p.cfg.Jitter = jitter
+}
+
+// SetFailureRate changes only the injected failure probability.
+func (p *ChaosProxy) SetFailureRate(rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg.FailureRate = rate
+}
+
+// Disable stops injecting faults. Connections reset earlier stay closed;
+// the service's pool reconnects on its next query.
+func (p *ChaosProxy) Disable() {
+	p.Configure(ChaosConfig{})
+}
+
+// Stats returns a snapshot of the proxy's counters.
+func (p *ChaosProxy) Stats() ChaosStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// Close stops the proxy and drops every open connection.
+func (p *ChaosProxy) Close() {
+	_ = p.listener.Close()
+
+	p.mu.Lock()
+	p.closed = true
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// ProxyURL rewrites a PostgreSQL connection URL to go through the proxy.
+func (p *ChaosProxy) ProxyURL(databaseURL string) (string, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	u.Host = p.Addr
+	return u.String(), nil
+}
+
+// accept forwards each incoming connection until the listener closes.
+func (p *ChaosProxy) accept() {
+	defer p.wg.Done()
+
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		p.mu.Lock()
+		p.stats.Connections++
+		p.mu.Unlock()
+
+		p.wg.Add(1)
+		go p.handle(client)
+	}
+}
+
+// handle pipes one client connection to the target, injecting faults on
+// the client-to-server direction.
+func (p *ChaosProxy) handle(client net.Conn) {
+	defer p.wg.Done()
+
+	server, err := net.DialTimeout("tcp", p.target, 5*time.Second)
+	if err != nil {
+		_ = client.Close()
+		return
+	}
+
+	if !p.track(client, server) {
+		return
+	}
+	defer p.untrack(client, server)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(client, server)
+		done <- struct{}{}
+	}()
+	go func() {
+		p.forward(server, client)
+		done <- struct{}{}
+	}()
+
+	// When either side finishes, close both so the other copy returns
+	<-done
+	_ = client.Close()
+	_ = server.Close()
+	<-done
+}
+
+// forward copies client packets to the server, delaying or failing each one
+// according to the current configuration.
+func (p *ChaosProxy) forward(server, client net.Conn) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := client.Read(buf)
+		if n > 0 {
+			delay, fail := p.decide()
+			if fail {
+				resetConn(client)
+				resetConn(server)
+				return
+			}
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+			if _, werr := server.Write(buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				_ = server.Close()
+			}
+			return
+		}
+	}
+}
+
+// decide picks the delay and failure outcome for one packet.
+func (p *ChaosProxy) decide() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.FailureRate > 0 && p.rng.Float64() < p.cfg.FailureRate {
+		p.stats.Failures++
+		return 0, true
+	}
+
+	delay := p.cfg.Latency
+	if p.cfg.Jitter > 0 {
+		delay += time.Duration(p.rng.Int63n(int64(p.cfg.Jitter)))
+	}
+	if delay > 0 {
+		p.stats.Delayed++
+	}
+	return delay, false
+}
+
+// track registers a connection pair so Close can drop it. It returns false
+// if the proxy is already closing.
+func (p *ChaosProxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Close may have run between Accept and here
+	if p.closed {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		return false
+	}
+	for _, conn := range conns {
+		p.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+// untrack forgets a connection pair.
+func (p *ChaosProxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range conns {
+		delete(p.conns, conn)
+	}
+}
+
+// resetConn closes a TCP connection with RST instead of FIN so the peer
+// sees an abrupt failure rather than a clean shutdown.
+func resetConn(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+// startChaosProxyFor starts a proxy in front of the database in databaseURL
+// and returns it with the URL the service should use instead.
+func startChaosProxyFor(databaseURL string) (*ChaosProxy, string, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	proxy, err := StartChaosProxy(u.Host)
+	if err != nil {
+		return nil, "", err
+	}
+
+	proxied, err := proxy.ProxyURL(databaseURL)
+	if err != nil {
+		proxy.Close()
+		return nil, "", err
+	}
+	return proxy, proxied, nil
+}
@@ -0,0 +1,324 @@
+// Package testenv provides ephemeral test infrastructure using testcontainers.
+package testenv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unsafeDBNameChars matches characters not allowed in generated database names.
+var unsafeDBNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// DedicatedEnv is a test environment backed by its own database and service.
+//
+// Unlike IsolatedEnv, which shares tables with every other test, a DedicatedEnv
+// creates a fresh PostgreSQL database on the shared server, runs migrations
+// into it, and starts a service instance pointed at it. Tests using it can
+// call t.Parallel() and truncate freely without affecting each other.
+type DedicatedEnv struct {
+	// DatabaseName is the name of the dedicated database.
+	DatabaseName string
+
+	// DatabaseURL is the connection string for the dedicated database.
+	DatabaseURL string
+
+	// Service is the service instance connected to the dedicated database
+	// (nil when the parent environment skips the service).
+	Service *Service
+
+	// DB provides database helper for the dedicated database.
+	DB *helpers.DBHelper
+
+	// API provides HTTP client for the dedicated service (pre-configured with API key).
+	API *helpers.APIClient
+
+	// Pool provides direct access to the dedicated database.
+	Pool *pgxpool.Pool
+
+	// Webhooks captures overload alerts sent by the dedicated service.
+	Webhooks *WebhookReceiver
+
+	// Chaos injects latency and failures between the dedicated service and
+	// its database (nil unless created with WithChaos).
+	Chaos *ChaosProxy
+
+	// parent is the environment whose server hosts the database.
+	parent *TestEnv
+
+	// cleanupFuncs holds cleanup functions in reverse order.
+	cleanupFuncs []func()
+}
+
+// DedicatedOption customizes a DedicatedEnv.
+type DedicatedOption func(*dedicatedOptions)
+
+// dedicatedOptions collects the settings applied by DedicatedOption values.
+type dedicatedOptions struct {
+	chaos bool
+}
+
+// WithChaos routes the dedicated service's database traffic through a
+// ChaosProxy, exposed as ded.Chaos. Since the service is not shared,
+// faults injected here cannot leak into other tests.
+func WithChaos() DedicatedOption {
+	return func(o *dedicatedOptions) {
+		o.chaos = true
+	}
+}
+
+// NewDedicatedEnv creates a database and service reserved for a single test.
+//
+// The database name is derived from name (typically t.Name()) plus a random
+// suffix. Always call Teardown when done:
+//
+//	func TestParallel(t *testing.T) {
+//	    t.Parallel()
+//	    ded, err := env.NewDedicatedEnv(ctx, t.Name())
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    defer ded.Teardown()
+//	    // ded.API and ded.DB only see this test's data
+//	}
+func (env *TestEnv) NewDedicatedEnv(ctx context.Context, name string, opts ...DedicatedOption) (*DedicatedEnv, error) {
+	var options dedicatedOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if env.databaseURL == "" {
+		return nil, fmt.Errorf("parent environment has no database URL")
+	}
+
+	dbName, err := dedicatedDatabaseName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dbURL, err := replaceDatabaseName(env.databaseURL, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ded := &DedicatedEnv{
+		DatabaseName: dbName,
+		DatabaseURL:  dbURL,
+		parent:       env,
+		cleanupFuncs: make([]func(), 0),
+	}
+
+	// Create the database through the parent's connection
+	dropDatabase, err := env.createDatabase(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	ded.addCleanup(dropDatabase)
+
+	// Connect and run migrations
+	db, err := database.New(dbURL)
+	if err != nil {
+		ded.Teardown()
+		return nil, fmt.Errorf("failed to connect to dedicated database: %w", err)
+	}
+	ded.addCleanup(db.Close)
+
+	if err := db.RunMigrations(ctx); err != nil {
+		ded.Teardown()
+		return nil, fmt.Errorf("failed to run migrations on dedicated database: %w", err)
+	}
+
+	ded.Pool = db.Pool
+	ded.DB = helpers.NewDBHelper(db.Pool)
+
+	// Start a service instance unless the parent skips it
+	if !env.Config.SkipService {
+		svcCfg := env.Config.Service
+		svcCfg.DatabaseURL = dbURL
+		svcCfg.Port = 0
+		if options.chaos {
+			proxy, proxiedURL, err := startChaosProxyFor(dbURL)
+			if err != nil {
+				ded.Teardown()
+				return nil, err
+			}
+			ded.addCleanup(proxy.Close)
+			ded.Chaos = proxy
+			svcCfg.DatabaseURL = proxiedURL
+		}
+		if env.Webhooks != nil {
+			// The parent's receiver would mix alerts from every test
+			ded.Webhooks = StartWebhookReceiver()
+			ded.addCleanup(ded.Webhooks.Close)
+			svcCfg.WebhookURL = ded.Webhooks.URL
+		}
+		if env.Service != nil && svcCfg.BinaryPath == "" {
+			// Reuse the parent's binary instead of rebuilding per test
+			svcCfg.BinaryPath = env.Service.BinaryPath
+		}
+
+		svc, svcCleanup, err := StartService(ctx, svcCfg)
+		if err != nil {
+			ded.Teardown()
+			return nil, fmt.Errorf("failed to start dedicated service: %w", err)
+		}
+		ded.addCleanup(svcCleanup)
+		ded.Service = svc
+
+		ded.API = helpers.NewAPIClient(svc.URL)
+		ded.API.SetHeader("x-api-key", svcCfg.APIKey)
+	}
+
+	return ded, nil
+}
+
+// ServiceURL returns the base URL of the dedicated service.
+func (ded *DedicatedEnv) ServiceURL() string {
+	if ded.Service == nil {
+		return ""
+	}
+	return ded.Service.URL
+}
+
+// CleanupTestData removes all data from the dedicated database.
+//
+// This only affects this test's database, so it is safe under t.Parallel().
+func (ded *DedicatedEnv) CleanupTestData(ctx context.Context) error {
+	if ded.Webhooks != nil {
+		ded.Webhooks.Reset()
+	}
+	if ded.Chaos != nil {
+		ded.Chaos.Disable()
+	}
+	return truncateTables(ctx, ded.Pool)
+}
+
+// Teardown stops the service, closes connections, and drops the database.
+func (ded *DedicatedEnv) Teardown() {
+	for i := len(ded.cleanupFuncs) - 1; i >= 0; i-- {
+		ded.cleanupFuncs[i]()
+	}
+	ded.cleanupFuncs = nil
+}
+
+// addCleanup adds a cleanup function to be called during Teardown.
+func (ded *DedicatedEnv) addCleanup(fn func()) {
+	ded.cleanupFuncs = append(ded.cleanupFuncs, fn)
+}
+
+// ScratchDatabase is an empty database with no migrations applied.
+//
+// Use it for tests that exercise migrations themselves; everything else
+// should use NewDedicatedEnv or the shared environment.
+type ScratchDatabase struct {
+	// DatabaseName is the name of the scratch database.
+	DatabaseName string
+
+	// DB is the connection to the scratch database.
+	DB *database.DB
+
+	// drop removes the database from the parent server.
+	drop func()
+}
+
+// NewScratchDatabase creates an empty database on the parent's server.
+//
+// Always call Teardown when done:
+//
+//	scratch, err := env.NewScratchDatabase(ctx, t.Name())
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer scratch.Teardown()
+//	err = scratch.DB.RunMigrations(ctx)
+func (env *TestEnv) NewScratchDatabase(ctx context.Context, name string) (*ScratchDatabase, error) {
+	if env.databaseURL == "" {
+		return nil, fmt.Errorf("parent environment has no database URL")
+	}
+
+	dbName, err := dedicatedDatabaseName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dbURL, err := replaceDatabaseName(env.databaseURL, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	drop, err := env.createDatabase(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		drop()
+		return nil, fmt.Errorf("failed to connect to scratch database: %w", err)
+	}
+
+	return &ScratchDatabase{
+		DatabaseName: dbName,
+		DB:           db,
+		drop:         drop,
+	}, nil
+}
+
+// Teardown closes the connection and drops the scratch database.
+func (s *ScratchDatabase) Teardown() {
+	s.DB.Close()
+	s.drop()
+}
+
+// createDatabase creates dbName on the parent's server and returns a function
+// that drops it again.
+func (env *TestEnv) createDatabase(ctx context.Context, dbName string) (func(), error) {
+	if _, err := env.Pool.Exec(ctx, "CREATE DATABASE "+pgx.Identifier{dbName}.Sanitize()); err != nil {
+		return nil, fmt.Errorf("failed to create database %s: %w", dbName, err)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, _ = env.Pool.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{dbName}.Sanitize()+" WITH (FORCE)")
+	}, nil
+}
+
+// dedicatedDatabaseName builds a valid, unique database name from a test name.
+func dedicatedDatabaseName(name string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate database suffix: %w", err)
+	}
+
+	base := unsafeDBNameChars.ReplaceAllString(strings.ToLower(name), "_")
+	base = strings.Trim(base, "_")
+
+	// PostgreSQL identifiers are limited to 63 bytes
+	const maxBase = 63 - len("e2e__") - 8
+	if len(base) > maxBase {
+		base = base[:maxBase]
+	}
+
+	return fmt.Sprintf("e2e_%s_%s", base, hex.EncodeToString(suffix)), nil
+}
+
+// replaceDatabaseName returns connStr pointing at a different database.
+func replaceDatabaseName(connStr, dbName string) (string, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	u.Path = "/" + dbName
+	return u.String(), nil
+}
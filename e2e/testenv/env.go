@@ -23,7 +23,9 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/gti/heatmap-internal/e2e/fixtures"
 	"github.com/gti/heatmap-internal/e2e/helpers"
 	"github.com/gti/heatmap-internal/internal/database"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -49,6 +51,14 @@ type TestEnv struct {
 	// Pool provides direct database access.
 	Pool *pgxpool.Pool
 
+	// Webhooks captures overload alerts sent by the service (nil when the
+	// service is skipped or Service.WebhookURL points elsewhere).
+	Webhooks *WebhookReceiver
+
+	// Chaos injects latency and failures between the service and PostgreSQL
+	// (nil unless EnvConfig.Chaos is set).
+	Chaos *ChaosProxy
+
 	// Config holds the environment configuration.
 	Config EnvConfig
 
@@ -58,6 +68,9 @@ type TestEnv struct {
 	// browser is lazily initialized.
 	browser *helpers.Browser
 
+	// databaseURL is the connection string used by the service.
+	databaseURL string
+
 	// cleanupFuncs holds cleanup functions in reverse order.
 	cleanupFuncs []func()
 }
@@ -76,6 +89,10 @@ type EnvConfig struct {
 	// ExternalDatabaseURL is an optional external database URL to use instead of testcontainers.
 	// If set, testcontainers will be skipped. Useful for CI environments without Docker.
 	ExternalDatabaseURL string
+
+	// Chaos routes the service's database traffic through a ChaosProxy,
+	// exposed as env.Chaos. No faults are injected until it is configured.
+	Chaos bool
 }
 
 // DefaultConfig returns the default test environment configuration.
@@ -146,6 +163,7 @@ func Setup(ctx context.Context, cfg EnvConfig) (*TestEnv, error) {
 	}
 
 	env.Pool = pool
+	env.databaseURL = dbURL
 
 	// Initialize DB helper
 	env.DB = helpers.NewDBHelper(pool)
@@ -156,6 +174,25 @@ func Setup(ctx context.Context, cfg EnvConfig) (*TestEnv, error) {
 		svcCfg := cfg.Service
 		svcCfg.DatabaseURL = dbURL
 
+		// Route database traffic through the chaos proxy if requested
+		if cfg.Chaos {
+			proxy, proxiedURL, err := startChaosProxyFor(dbURL)
+			if err != nil {
+				env.Teardown()
+				return nil, err
+			}
+			env.addCleanup(proxy.Close)
+			env.Chaos = proxy
+			svcCfg.DatabaseURL = proxiedURL
+		}
+
+		// Capture overload alerts unless a destination was configured
+		if svcCfg.WebhookURL == "" {
+			env.Webhooks = StartWebhookReceiver()
+			env.addCleanup(env.Webhooks.Close)
+			svcCfg.WebhookURL = env.Webhooks.URL
+		}
+
 		svc, svcCleanup, err := StartService(ctx, svcCfg)
 		if err != nil {
 			env.Teardown()
@@ -202,29 +239,19 @@ func (env *TestEnv) Teardown() {
 //	    // ... test with clean state ...
 //	}
 func (env *TestEnv) CleanupTestData(ctx context.Context) error {
-	if env.Postgres != nil {
-		return env.Postgres.TruncateAllTables(ctx)
+	if env.Webhooks != nil {
+		env.Webhooks.Reset()
 	}
-
-	// Fallback for external database: manually truncate tables
-	tables := []string{
-		"load_calendar_data.sessions",
-		"load_calendar_data.otp_records",
-		"load_calendar_data.load_assignments",
-		"load_calendar_data.loads",
-		"load_calendar_data.capacity_overrides",
-		"load_calendar_data.group_members",
-		"load_calendar_data.entities",
+	if env.Chaos != nil {
+		env.Chaos.Disable()
 	}
 
-	for _, table := range tables {
-		_, err := env.Pool.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
-		if err != nil {
-			return fmt.Errorf("failed to truncate %s: %w", table, err)
-		}
+	if env.Postgres != nil {
+		return env.Postgres.TruncateAllTables(ctx)
 	}
 
-	return nil
+	// Fallback for external database: manually truncate tables
+	return truncateTables(ctx, env.Pool)
 }
 
 // Browser returns the browser helper, initializing it lazily.
@@ -257,33 +284,34 @@ func (env *TestEnv) ServiceURL() string {
 }
 
 // SeedTestEntity creates a test entity in the database.
+//
+// For richer setups (overrides, memberships), use the fixtures package.
 func (env *TestEnv) SeedTestEntity(ctx context.Context, id, title, entityType string, capacity float64) error {
-	_, err := env.DB.Exec(ctx,
-		"INSERT INTO load_calendar_data.entities (id, title, type, default_capacity) VALUES ($1, $2, $3, $4)",
-		id, title, entityType, capacity)
-	return err
+	if entityType == "group" {
+		return fixtures.NewGroup(id).WithTitle(title).WithCapacity(capacity).Insert(ctx, env.DB)
+	}
+	return fixtures.NewPerson(id).WithTitle(title).WithCapacity(capacity).Insert(ctx, env.DB)
 }
 
 // SeedTestLoad creates a test load with an assignment.
 func (env *TestEnv) SeedTestLoad(ctx context.Context, externalID, title, assignee string, date string, weight float64) error {
-	var loadID int
-	err := env.Pool.QueryRow(ctx,
-		"INSERT INTO load_calendar_data.loads (external_id, title, source, date) VALUES ($1, $2, 'test', $3::date) RETURNING id",
-		externalID, title, date).Scan(&loadID)
+	loadDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return fmt.Errorf("failed to create load: %w", err)
+		return fmt.Errorf("invalid load date %q: %w", date, err)
 	}
 
-	_, err = env.DB.Exec(ctx,
-		"INSERT INTO load_calendar_data.load_assignments (load_id, person_email, weight) VALUES ($1, $2, $3)",
-		loadID, assignee, weight)
-	return err
+	return fixtures.NewLoad(externalID).
+		WithTitle(title).
+		OnDate(loadDate).
+		AssignedToEmail(assignee, weight).
+		Insert(ctx, env.DB)
 }
 
 // NewIsolatedEnv creates a new test environment for parallel test isolation.
 //
 // Each isolated environment shares the same PostgreSQL container but gets
 // its own API client. Tests should use unique identifiers for their data.
+// For full isolation, including safe truncation, use NewDedicatedEnv.
 //
 // Example:
 //
@@ -341,6 +369,45 @@ func (iso *IsolatedEnv) Cleanup(ctx context.Context) error {
 	return err
 }
 
+// truncateTables removes all data from the application tables.
+func truncateTables(ctx context.Context, pool *pgxpool.Pool) error {
+	tables := []string{
+		"load_calendar_data.sessions",
+		"load_calendar_data.otp_records",
+		"load_calendar_data.load_assignments",
+		"load_calendar_data.load_tags",
+		"load_calendar_data.loads",
+		"load_calendar_data.capacity_overrides",
+		"load_calendar_data.group_members",
+		"load_calendar_data.entities",
+		"load_calendar_data.heatmap_tombstones",
+		"load_calendar_data.scenarios",
+		"load_calendar_data.integration_errors",
+		"load_calendar_data.webhook_deliveries",
+		"load_calendar_data.webhook_endpoints",
+		"load_calendar_data.incident_notes",
+		"load_calendar_data.presence",
+		"load_calendar_data.custom_field_definitions",
+		"load_calendar_data.lark_digests",
+		"load_calendar_data.entity_tombstones",
+		"load_calendar_data.holidays",
+		"load_calendar_data.settings",
+		"load_calendar_data.rate_limits",
+		"load_calendar_data.domain_events",
+		"load_calendar_data.jobs",
+		"load_calendar_data.utilization_reports",
+	}
+
+	for _, table := range tables {
+		_, err := pool.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
+		if err != nil {
+			return fmt.Errorf("failed to truncate %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
 // addCleanup adds a cleanup function to be called during Teardown.
 func (env *TestEnv) addCleanup(fn func()) {
 	env.cleanupFuncs = append(env.cleanupFuncs, fn)
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"syscall"
 	"time"
+
+	"github.com/gti/heatmap-internal/e2e/coverage"
 )
 
 // InstrumentedService represents a running instance of the coverage-instrumented server.
@@ -119,6 +121,20 @@ func StartInstrumentedService(ctx context.Context, cfg InstrumentedConfig) (*Ins
 		fmt.Sprintf("API_KEY=%s", cfg.APIKey),
 		fmt.Sprintf("SESSION_SECRET=%s", cfg.SessionSecret),
 		fmt.Sprintf("PORT=%d", port),
+		fmt.Sprintf("WEBHOOK_DESTINATION_URL=%s", cfg.WebhookURL),
+		// Fixtures write straight to the database, bypassing cache invalidation
+		"RENDER_CACHE_SIZE=0",
+		// Track overloads often enough for tests to wait on
+		"OVERLOAD_SWEEP_INTERVAL=200ms",
+		"WEIGHT_RULES_FILE=weight_rules.example.json",
+		"CAPACITY_APPROVAL_ZERO_DAYS=3",
+		// Reports are requested directly, so nothing is stored in the background
+		"QUARTERLY_REPORT_INTERVAL=off",
+		// Tests backdate assignments rather than wait a day
+		"ACK_REMINDER_DAYS=1",
+		"ACK_REMINDER_INTERVAL=200ms",
+		"ADMIN_EMAILS="+AdminEmail,
+		"GROUP_API_KEYS="+GroupAPIKeyGroup+"="+GroupAPIKey,
 		"LARK_APP_ID=",
 		"LARK_APP_SECRET=",
 	)
@@ -141,10 +157,11 @@ func StartInstrumentedService(ctx context.Context, cfg InstrumentedConfig) (*Ins
 
 	svc := &InstrumentedService{
 		Service: &Service{
-			URL:     url,
-			Port:    port,
-			Process: cmd.Process,
-			cmd:     cmd,
+			URL:        url,
+			Port:       port,
+			BinaryPath: binaryPath,
+			Process:    cmd.Process,
+			cmd:        cmd,
 		},
 		CoverageDir: coverageDir,
 		BinaryPath:  binaryPath,
@@ -266,3 +283,23 @@ func (s *InstrumentedService) ConvertCoverageData(outputFile string) error {
 
 	return nil
 }
+
+// MergeCoverage merges this service's coverage data with text profiles (for
+// example coverage/unit.out) and extra GOCOVERDIR directories from earlier
+// runs, writes the result to outputFile, and returns it for threshold checks:
+//
+//	merged, err := svc.MergeCoverage(ctx, "coverage/combined.out", []string{"coverage/unit.out"})
+//	violations := thresholds.Check(merged.Packages())
+func (s *InstrumentedService) MergeCoverage(ctx context.Context, outputFile string, profiles []string, extraDirs ...string) (*coverage.Profile, error) {
+	merged, err := coverage.Collect(ctx, coverage.Sources{
+		Profiles:  profiles,
+		CoverDirs: append([]string{s.CoverageDir}, extraDirs...),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := merged.Write(outputFile); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
@@ -159,10 +159,26 @@ func (pg *PostgresContainer) TruncateAllTables(ctx context.Context) error {
 		"load_calendar_data.sessions",
 		"load_calendar_data.otp_records",
 		"load_calendar_data.load_assignments",
+		"load_calendar_data.load_tags",
 		"load_calendar_data.loads",
 		"load_calendar_data.capacity_overrides",
 		"load_calendar_data.group_members",
 		"load_calendar_data.entities",
+		"load_calendar_data.heatmap_tombstones",
+		"load_calendar_data.scenarios",
+		"load_calendar_data.integration_errors",
+		"load_calendar_data.webhook_deliveries",
+		"load_calendar_data.webhook_endpoints",
+		"load_calendar_data.incident_notes",
+		"load_calendar_data.presence",
+		"load_calendar_data.custom_field_definitions",
+		"load_calendar_data.lark_digests",
+		"load_calendar_data.entity_tombstones",
+		"load_calendar_data.holidays",
+		"load_calendar_data.settings",
+		"load_calendar_data.rate_limits",
+		"load_calendar_data.domain_events",
+		"load_calendar_data.jobs",
 	}
 
 	for _, table := range tables {
@@ -0,0 +1,72 @@
+package testenv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AppSchema is the PostgreSQL schema that holds all application tables.
+const AppSchema = "load_calendar_data"
+
+// schemaCatalogQuery lists every column, constraint, and index in a schema as
+// one normalized line each, in a stable order.
+const schemaCatalogQuery = `
+	SELECT line FROM (
+		SELECT format('column %s.%s %s null=%s default=%s',
+			table_name, column_name, data_type, is_nullable, COALESCE(column_default, '')) AS line
+		FROM information_schema.columns
+		WHERE table_schema = $1
+		UNION ALL
+		SELECT format('constraint %s.%s %s', rel.relname, con.conname, pg_get_constraintdef(con.oid))
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = rel.relnamespace
+		WHERE ns.nspname = $1
+		UNION ALL
+		SELECT format('index %s.%s %s', tablename, indexname, indexdef)
+		FROM pg_indexes
+		WHERE schemaname = $1
+	) catalog
+	ORDER BY line
+`
+
+// SchemaDescription returns a normalized, line-per-object description of the
+// columns, constraints, and indexes in schema. It is empty when the schema
+// does not exist.
+func SchemaDescription(ctx context.Context, pool *pgxpool.Pool, schema string) (string, error) {
+	rows, err := pool.Query(ctx, schemaCatalogQuery, schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe schema %s: %w", schema, err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", fmt.Errorf("failed to scan schema description: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to describe schema %s: %w", schema, err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// SchemaChecksum returns a SHA-256 of SchemaDescription, for comparing schema
+// shape across migration runs.
+func SchemaChecksum(ctx context.Context, pool *pgxpool.Pool, schema string) (string, error) {
+	description, err := SchemaDescription(ctx, pool, schema)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(description))
+	return hex.EncodeToString(sum[:]), nil
+}
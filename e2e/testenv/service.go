@@ -13,6 +13,17 @@ import (
 	"time"
 )
 
+// AdminEmail is the admin the service is started with, who may view every
+// private heatmap.
+const AdminEmail = "admin@example.com"
+
+// GroupAPIKey is an API key the service is started with that may only write
+// for members of GroupAPIKeyGroup.
+const (
+	GroupAPIKey      = "test-group-api-key"
+	GroupAPIKeyGroup = "scoped-team"
+)
+
 // Service represents a running instance of the application server.
 type Service struct {
 	// URL is the base URL of the running service.
@@ -21,6 +32,9 @@ type Service struct {
 	// Port is the port the service is listening on.
 	Port int
 
+	// BinaryPath is the server binary the process was started from.
+	BinaryPath string
+
 	// Process is the underlying OS process.
 	Process *os.Process
 
@@ -49,6 +63,10 @@ type ServiceConfig struct {
 	// WorkingDir is the working directory for the service.
 	// Defaults to project root (for template access).
 	WorkingDir string
+
+	// WebhookURL is the overload alert destination (WEBHOOK_DESTINATION_URL).
+	// Setup fills it with a WebhookReceiver when left empty.
+	WebhookURL string
 }
 
 // DefaultServiceConfig returns default service configuration.
@@ -112,6 +130,20 @@ func StartService(ctx context.Context, cfg ServiceConfig) (*Service, func(), err
 		fmt.Sprintf("API_KEY=%s", cfg.APIKey),
 		fmt.Sprintf("SESSION_SECRET=%s", cfg.SessionSecret),
 		fmt.Sprintf("PORT=%d", port),
+		fmt.Sprintf("WEBHOOK_DESTINATION_URL=%s", cfg.WebhookURL),
+		// Fixtures write straight to the database, bypassing cache invalidation
+		"RENDER_CACHE_SIZE=0",
+		// Track overloads often enough for tests to wait on
+		"OVERLOAD_SWEEP_INTERVAL=200ms",
+		"WEIGHT_RULES_FILE=weight_rules.example.json",
+		"CAPACITY_APPROVAL_ZERO_DAYS=3",
+		// Reports are requested directly, so nothing is stored in the background
+		"QUARTERLY_REPORT_INTERVAL=off",
+		// Tests backdate assignments rather than wait a day
+		"ACK_REMINDER_DAYS=1",
+		"ACK_REMINDER_INTERVAL=200ms",
+		"ADMIN_EMAILS="+AdminEmail,
+		"GROUP_API_KEYS="+GroupAPIKeyGroup+"="+GroupAPIKey,
 		"LARK_APP_ID=",     // Disable Lark in tests
 		"LARK_APP_SECRET=", // Disable Lark in tests
 	)
@@ -135,10 +167,11 @@ func StartService(ctx context.Context, cfg ServiceConfig) (*Service, func(), err
 	url := fmt.Sprintf("http://localhost:%d", port)
 
 	svc := &Service{
-		URL:     url,
-		Port:    port,
-		Process: cmd.Process,
-		cmd:     cmd,
+		URL:        url,
+		Port:       port,
+		BinaryPath: binaryPath,
+		Process:    cmd.Process,
+		cmd:        cmd,
 	}
 
 	// Wait for service to be ready
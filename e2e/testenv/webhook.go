@@ -0,0 +1,173 @@
+package testenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// ReceivedWebhook is a raw request captured by a WebhookReceiver.
+type ReceivedWebhook struct {
+	// Header holds the request headers.
+	Header http.Header
+
+	// Body is the raw request body.
+	Body []byte
+
+	// ReceivedAt is when the request arrived.
+	ReceivedAt time.Time
+}
+
+// WebhookReceiver is an HTTP sink standing in for the n8n webhook destination.
+//
+// Point the service's WEBHOOK_DESTINATION_URL at URL and every overload alert
+// it sends is captured for assertions. Setup does this automatically and
+// exposes the receiver as env.Webhooks.
+type WebhookReceiver struct {
+	// URL is the address the service should post to.
+	URL string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	received []ReceivedWebhook
+	alerts   []models.WebhookAlertPayload
+	status   int
+	notify   chan struct{}
+}
+
+// StartWebhookReceiver starts a receiver on a random local port.
+//
+// Always call Close when done:
+//
+//	receiver := testenv.StartWebhookReceiver()
+//	defer receiver.Close()
+func StartWebhookReceiver() *WebhookReceiver {
+	r := &WebhookReceiver{
+		status: http.StatusOK,
+		notify: make(chan struct{}),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	r.URL = r.server.URL
+	return r
+}
+
+// handle records the request and replies with the configured status.
+func (r *WebhookReceiver) handle(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.received = append(r.received, ReceivedWebhook{
+		Header:     req.Header.Clone(),
+		Body:       body,
+		ReceivedAt: time.Now(),
+	})
+	var alert models.WebhookAlertPayload
+	if err := json.Unmarshal(body, &alert); err == nil && alert.PersonEmail != "" {
+		r.alerts = append(r.alerts, alert)
+	}
+	status := r.status
+
+	// Wake any waiters
+	close(r.notify)
+	r.notify = make(chan struct{})
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+// SetStatus changes the status code returned to the service, e.g. to
+// simulate a failing destination.
+func (r *WebhookReceiver) SetStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// Requests returns every request received so far.
+func (r *WebhookReceiver) Requests() []ReceivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReceivedWebhook(nil), r.received...)
+}
+
+// Alerts returns every overload alert received so far.
+func (r *WebhookReceiver) Alerts() []models.WebhookAlertPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.WebhookAlertPayload(nil), r.alerts...)
+}
+
+// AlertFor returns the most recent alert for email on date (YYYY-MM-DD).
+func (r *WebhookReceiver) AlertFor(email, date string) (*models.WebhookAlertPayload, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.findAlert(email, date)
+}
+
+// ReceivedAlertFor reports whether an alert for email on date (YYYY-MM-DD)
+// has arrived.
+//
+//	a.True(env.Webhooks.ReceivedAlertFor("alice@example.com", "2025-01-15"))
+func (r *WebhookReceiver) ReceivedAlertFor(email, date string) bool {
+	_, ok := r.AlertFor(email, date)
+	return ok
+}
+
+// WaitForAlert blocks until an alert for email on date arrives or timeout
+// elapses. Alerts are sent asynchronously after an upsert, so prefer this
+// over ReceivedAlertFor right after triggering one.
+func (r *WebhookReceiver) WaitForAlert(email, date string, timeout time.Duration) (*models.WebhookAlertPayload, error) {
+	deadline := time.After(timeout)
+	for {
+		r.mu.Lock()
+		alert, ok := r.findAlert(email, date)
+		notify := r.notify
+		r.mu.Unlock()
+
+		if ok {
+			return alert, nil
+		}
+
+		select {
+		case <-notify:
+		case <-deadline:
+			return nil, fmt.Errorf("no webhook alert for %s on %s within %s", email, date, timeout)
+		}
+	}
+}
+
+// Reset discards everything received and restores the 200 response.
+func (r *WebhookReceiver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = nil
+	r.alerts = nil
+	r.status = http.StatusOK
+}
+
+// Close shuts the receiver down.
+func (r *WebhookReceiver) Close() {
+	r.server.Close()
+}
+
+// findAlert scans alerts newest first. Callers must hold r.mu.
+func (r *WebhookReceiver) findAlert(email, date string) (*models.WebhookAlertPayload, bool) {
+	for i := len(r.alerts) - 1; i >= 0; i-- {
+		alert := r.alerts[i]
+		if alert.PersonEmail == email && alert.Date.Format("2006-01-02") == date {
+			return &alert, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,126 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestLoadAcknowledgment verifies that assignees can acknowledge their loads,
+// that acknowledgments survive re-syncs, and that only assignees who leave a
+// heavy load unacknowledged long enough are reminded, once.
+func TestLoadAcknowledgment(t *testing.T) {
+	if env.Webhooks == nil {
+		t.Skip("service is not wired to a webhook receiver")
+	}
+
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+	env.Webhooks.Reset()
+
+	diligent := fixtures.NewPerson("ack-diligent@example.com")
+	forgetful := fixtures.NewPerson("ack-forgetful@example.com")
+	a.NoError(fixtures.NewScenario().Add(diligent, forgetful).Insert(ctx, env.DB), "should seed scenario")
+
+	login := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		client := session.Client(env.ServiceURL())
+		return client
+	}
+	diligentClient := login(diligent.ID())
+	forgetfulClient := login(forgetful.ID())
+
+	date := time.Now().UTC().AddDate(0, 0, 7).Format("2006-01-02")
+	upsert := func() int {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": "ack-load",
+			"title":       "Quarterly Audit",
+			"date":        date,
+			"assignees": []map[string]interface{}{
+				{"email": diligent.ID(), "weight": 3},
+				{"email": forgetful.ID(), "weight": 3},
+			},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+		var r struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&r))
+		return r.LoadID
+	}
+	unacknowledged := func(client *helpers.APIClient) []int {
+		resp, err := client.Call("GET", "/api/my-loads/unacknowledged", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var pending []struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&pending))
+		ids := make([]int, 0, len(pending))
+		for _, p := range pending {
+			ids = append(ids, p.LoadID)
+		}
+		return ids
+	}
+
+	loadID := upsert()
+	a.Equal([]int{loadID}, unacknowledged(diligentClient))
+
+	resp, err := diligentClient.Call("POST", fmt.Sprintf("/api/loads/%d/acknowledge", loadID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "acknowledging should succeed: %s", resp.String())
+	a.Empty(unacknowledged(diligentClient))
+
+	a.Equal(loadID, upsert(), "re-sync keeps the load")
+	a.Empty(unacknowledged(diligentClient), "re-syncing keeps the acknowledgment")
+	a.Equal([]int{loadID}, unacknowledged(forgetfulClient))
+
+	// Pretend both were assigned long enough ago to be reminded
+	_, err = env.DB.Exec(ctx, `
+		UPDATE load_calendar_data.load_acknowledgments
+		SET assigned_at = NOW() - INTERVAL '2 days'
+		WHERE load_id = $1
+	`, loadID)
+	a.NoError(err)
+
+	type reminder struct {
+		Event       string `json:"event"`
+		PersonEmail string `json:"person_email"`
+		LoadID      int    `json:"load_id"`
+	}
+	reminders := func() []reminder {
+		var found []reminder
+		for _, req := range env.Webhooks.Requests() {
+			var r reminder
+			if json.Unmarshal(req.Body, &r) == nil && r.Event == "load_unacknowledged" {
+				found = append(found, r)
+			}
+		}
+		return found
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(reminders()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Give the job a few more sweeps to send anything it should not
+	time.Sleep(time.Second)
+
+	sent := reminders()
+	if a.Len(sent, 1, "only the forgetful assignee is reminded, and only once") {
+		a.Equal(forgetful.ID(), sent[0].PersonEmail)
+		a.Equal(loadID, sent[0].LoadID)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestAPIMetrics verifies that requests to the API-key routes are counted
+// per key and day, with their errors and payload sizes, for admins to see.
+func TestAPIMetrics(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("api-metrics-person@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(testenv.AdminEmail)
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+	admin := session.Client(env.ServiceURL())
+
+	type metric struct {
+		Key           string  `json:"key"`
+		Date          string  `json:"date"`
+		Requests      int64   `json:"requests"`
+		ClientErrors  int64   `json:"client_errors"`
+		ServerErrors  int64   `json:"server_errors"`
+		ErrorRate     float64 `json:"error_rate"`
+		RequestBytes  int64   `json:"request_bytes"`
+		ResponseBytes int64   `json:"response_bytes"`
+	}
+	metricsOf := func(key string) metric {
+		t.Helper()
+		resp, err := admin.Call("GET", "/api/admin/api-metrics?key="+key, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get metrics: %s", resp.String())
+		var report struct {
+			Metrics []metric `json:"metrics"`
+		}
+		a.NoError(resp.JSON(&report))
+		for _, m := range report.Metrics {
+			if m.Date == time.Now().UTC().Format("2006-01-02") {
+				return m
+			}
+		}
+		return metric{}
+	}
+	before := metricsOf("api_key")
+
+	resp, err := env.API.Call("PUT", "/api/entities/"+person.ID(), map[string]interface{}{"title": "Counted"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should update: %s", resp.String())
+	resp, err = env.API.Call("POST", "/api/v1/loads/upsert", map[string]interface{}{"title": "No date or assignees"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "malformed loads are refused")
+
+	after := metricsOf("api_key")
+	a.Equal(before.Requests+2, after.Requests, "both versions count under the same key")
+	a.Equal(before.ClientErrors+1, after.ClientErrors)
+	a.True(after.RequestBytes > before.RequestBytes, "request bodies are counted")
+	a.True(after.ResponseBytes > before.ResponseBytes, "and responses")
+	a.True(after.ErrorRate > 0, "the refused upsert is an error")
+
+	anonymous := helpers.NewAPIClient(env.ServiceURL())
+	resp, err = anonymous.Call("PUT", "/api/v1/entities/"+person.ID(), map[string]interface{}{"title": "Refused"})
+	a.NoError(err)
+	a.Equal(http.StatusUnauthorized, resp.StatusCode)
+	refused := metricsOf("unauthenticated")
+	a.True(refused.Requests >= 1, "refused keys are counted too")
+	a.Equal(refused.Requests, refused.ClientErrors)
+}
@@ -0,0 +1,143 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestCapacityApproval verifies that capacity reductions and long zero-capacity
+// runs from a group member wait for a group owner, are only applied once
+// approved, and that other changes still apply directly.
+func TestCapacityApproval(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	member := fixtures.NewPerson("approval-member@example.com").WithCapacity(5)
+	owner := fixtures.NewPerson("approval-owner@example.com")
+	group := fixtures.NewGroup("approval-team").WithMembers(member, owner)
+	a.NoError(fixtures.NewScenario().Add(member, owner, group).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("POST", "/api/groups/"+group.ID()+"/owners", map[string]string{"person_email": owner.ID()})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "adding an owner should succeed: %s", resp.String())
+
+	login := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		client := session.Client(env.ServiceURL())
+		return client
+	}
+	memberClient := login(member.ID())
+	ownerClient := login(owner.ID())
+
+	defaultCapacity := func() float64 {
+		var capacity float64
+		rows, err := env.DB.Query(ctx, `SELECT default_capacity FROM load_calendar_data.entities WHERE id = $1`, member.ID())
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&capacity))
+		}
+		rows.Close()
+		return capacity
+	}
+	zeroOverrides := func() int {
+		var n int
+		rows, err := env.DB.Query(ctx, `SELECT COUNT(*) FROM load_calendar_data.capacity_overrides WHERE entity_id = $1 AND capacity = 0`, member.ID())
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&n))
+		}
+		rows.Close()
+		return n
+	}
+
+	type change struct {
+		ID       int    `json:"id"`
+		EntityID string `json:"entity_id"`
+		Reason   string `json:"reason"`
+		Status   string `json:"status"`
+	}
+	submit := func(body interface{}, wantStatus int) change {
+		resp, err := memberClient.Call("POST", "/api/my-capacity", body)
+		a.NoError(err)
+		a.Equal(wantStatus, resp.StatusCode, "unexpected status: %s", resp.String())
+		var c change
+		if wantStatus == http.StatusAccepted {
+			a.NoError(resp.JSON(&c))
+		}
+		return c
+	}
+
+	// Raising capacity needs no approval
+	submit(map[string]float64{"default_capacity": 6}, http.StatusOK)
+	a.Equal(6.0, defaultCapacity(), "raise should apply directly")
+
+	// A reduction waits until rejected, and is never applied
+	reduction := submit(map[string]float64{"default_capacity": 2}, http.StatusAccepted)
+	a.Equal("pending", reduction.Status)
+	a.Contains(reduction.Reason, "default capacity reduced")
+	a.Equal(6.0, defaultCapacity(), "pending reduction should not apply")
+
+	resp, err = ownerClient.Call("POST", fmt.Sprintf("/api/capacity-approvals/%d/reject", reduction.ID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "reject should succeed: %s", resp.String())
+	a.Equal(6.0, defaultCapacity(), "rejected reduction should not apply")
+
+	// A run of zero-capacity days is surfaced to the owner and applied on approval
+	start := time.Now().UTC().AddDate(0, 0, 7)
+	var overrides []map[string]interface{}
+	for i := 0; i < 3; i++ {
+		overrides = append(overrides, map[string]interface{}{"date": start.AddDate(0, 0, i).Format("2006-01-02"), "capacity": 0})
+	}
+	leave := submit(map[string]interface{}{"date_overrides": overrides}, http.StatusAccepted)
+	a.Equal(0, zeroOverrides(), "pending leave should not apply")
+
+	resp, err = memberClient.Call("GET", "/api/capacity-approvals", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var own []change
+	a.NoError(resp.JSON(&own))
+	a.Empty(own, "members should not see their own changes to approve")
+
+	resp, err = ownerClient.Call("GET", "/api/capacity-approvals", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var approvals []change
+	a.NoError(resp.JSON(&approvals))
+	if len(approvals) != 1 {
+		t.Fatalf("expected one pending approval, got %+v", approvals)
+	}
+	a.Equal(leave.ID, approvals[0].ID)
+	a.Equal(member.ID(), approvals[0].EntityID)
+	a.Equal("3 consecutive days at zero capacity", approvals[0].Reason)
+
+	resp, err = memberClient.Call("POST", fmt.Sprintf("/api/capacity-approvals/%d/approve", leave.ID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode, "members cannot approve their own changes")
+
+	resp, err = ownerClient.Call("POST", fmt.Sprintf("/api/capacity-approvals/%d/approve", leave.ID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "approve should succeed: %s", resp.String())
+	a.Equal(3, zeroOverrides(), "approved leave should apply")
+
+	resp, err = ownerClient.Call("POST", fmt.Sprintf("/api/capacity-approvals/%d/approve", leave.ID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusConflict, resp.StatusCode, "a decided change cannot be decided again")
+
+	// Without an owner there is nobody to approve, so changes apply directly
+	resp, err = env.API.Call("DELETE", "/api/groups/"+group.ID()+"/owners/"+owner.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	submit(map[string]float64{"default_capacity": 3}, http.StatusOK)
+	a.Equal(3.0, defaultCapacity(), "reduction without approvers should apply directly")
+}
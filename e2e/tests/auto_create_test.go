@@ -0,0 +1,103 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestAutoCreatedReview verifies that assignees created by an upsert wait in
+// the review queue, that confirming keeps them, and that rejecting deletes
+// them with their assignments.
+func TestAutoCreatedReview(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	known := fixtures.NewPerson("known@example.com")
+	a.NoError(known.Insert(ctx, env.DB), "should seed person")
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "auto-create-1",
+		"title":       "Sync with typos",
+		"source":      "jira",
+		"date":        time.Now().UTC().Format("2006-01-02"),
+		"assignees": []map[string]interface{}{
+			{"email": known.ID()},
+			{"email": "new-hire@example.com"},
+			{"email": "typo@exmaple.com"},
+		},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	type pending struct {
+		Email  string `json:"email"`
+		Source string `json:"source"`
+		Loads  int    `json:"loads"`
+	}
+	list := func() []pending {
+		resp, err := env.API.Call("GET", "/api/people/auto-created", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "listing should succeed: %s", resp.String())
+		var persons []pending
+		a.NoError(resp.JSON(&persons))
+		return persons
+	}
+
+	persons := list()
+	a.Len(persons, 2, "only the unknown assignees should be queued")
+	for _, p := range persons {
+		a.NotEqual(known.ID(), p.Email)
+		a.Equal("jira", p.Source)
+		a.Equal(1, p.Loads)
+	}
+
+	type review struct {
+		Reviewed []string `json:"reviewed"`
+		Skipped  []string `json:"skipped"`
+	}
+	resp, err = env.API.Call("POST", "/api/people/auto-created/confirm", map[string]interface{}{
+		"emails": []string{"new-hire@example.com", known.ID()},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "confirm should succeed: %s", resp.String())
+	var confirmed review
+	a.NoError(resp.JSON(&confirmed))
+	a.Equal([]string{"new-hire@example.com"}, confirmed.Reviewed)
+	a.Equal([]string{known.ID()}, confirmed.Skipped, "persons never queued should be skipped")
+
+	resp, err = env.API.Call("POST", "/api/people/auto-created/reject", map[string]interface{}{
+		"emails": []string{"typo@exmaple.com", "new-hire@example.com"},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "reject should succeed: %s", resp.String())
+	var rejected review
+	a.NoError(resp.JSON(&rejected))
+	a.Equal([]string{"typo@exmaple.com"}, rejected.Reviewed)
+	a.Equal([]string{"new-hire@example.com"}, rejected.Skipped, "confirmed persons cannot be rejected")
+
+	a.Empty(list(), "the queue should be empty after review")
+
+	count := func(query string) int {
+		var n int
+		rows, err := env.DB.Query(ctx, query)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&n))
+		}
+		rows.Close()
+		return n
+	}
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.entities WHERE id IN ('typo@exmaple.com', 'new-hire@example.com')`),
+		"only the rejected person should be deleted")
+	a.Equal(0, count(`SELECT COUNT(*) FROM load_calendar_data.load_assignments WHERE person_email = 'typo@exmaple.com'`),
+		"the rejected person's assignments should go with them")
+}
@@ -0,0 +1,88 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestBlackoutDates verifies that upserts newly assigning someone in their
+// own or their group's blackout are flagged, and that re-syncing a load
+// already assigned before the blackout is not.
+func TestBlackoutDates(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	member := fixtures.NewPerson("blackout-member@example.com")
+	student := fixtures.NewPerson("blackout-student@example.com")
+	free := fixtures.NewPerson("blackout-free@example.com")
+	group := fixtures.NewGroup("blackout-team").WithMembers(member)
+	a.NoError(fixtures.NewScenario().Add(member, student, free, group).Insert(ctx, env.DB), "should seed scenario")
+
+	start := time.Now().UTC().AddDate(0, 0, 10)
+	day := func(offset int) string { return start.AddDate(0, 0, offset).Format("2006-01-02") }
+
+	type blackoutConflict struct {
+		PersonEmail string `json:"person_email"`
+		EntityID    string `json:"entity_id"`
+		Date        string `json:"date"`
+		Reason      string `json:"reason"`
+	}
+	type upsertResult struct {
+		LoadID    int                `json:"load_id"`
+		Blackouts []blackoutConflict `json:"blackouts"`
+	}
+	upsert := func(externalID, date string, assignees ...string) upsertResult {
+		list := make([]map[string]interface{}, 0, len(assignees))
+		for _, email := range assignees {
+			list = append(list, map[string]interface{}{"email": email})
+		}
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Blackout Load",
+			"date":        date,
+			"assignees":   list,
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed in warn mode: %s", resp.String())
+		var r upsertResult
+		a.NoError(resp.JSON(&r))
+		return r
+	}
+
+	// Already assigned before the freeze is declared
+	a.Empty(upsert("blackout-existing", day(1), member.ID()).Blackouts)
+
+	for _, b := range []struct {
+		entity string
+		body   map[string]string
+	}{
+		{group.ID(), map[string]string{"start_date": day(0), "end_date": day(2), "reason": "Release freeze"}},
+		{student.ID(), map[string]string{"start_date": day(1), "reason": "Exam week"}},
+	} {
+		resp, err := env.API.Call("POST", "/api/entities/"+b.entity+"/blackouts", b.body)
+		a.NoError(err)
+		a.Equal(http.StatusCreated, resp.StatusCode, "blackout should be created: %s", resp.String())
+	}
+
+	r := upsert("blackout-new", day(1), member.ID(), student.ID(), free.ID())
+	if a.Len(r.Blackouts, 2, "only the member and the student are blacked out") {
+		a.Equal(member.ID(), r.Blackouts[0].PersonEmail)
+		a.Equal(group.ID(), r.Blackouts[0].EntityID, "the member inherits the group's freeze")
+		a.Equal("Release freeze", r.Blackouts[0].Reason)
+		a.Equal(student.ID(), r.Blackouts[1].PersonEmail)
+		a.Equal("Exam week", r.Blackouts[1].Reason)
+		a.Equal(day(1), r.Blackouts[1].Date)
+	}
+
+	a.Empty(upsert("blackout-existing", day(1), member.ID()).Blackouts, "re-syncing an existing assignment is not flagged")
+	a.Empty(upsert("blackout-later", day(3), member.ID(), student.ID()).Blackouts, "blackouts end on their end date")
+}
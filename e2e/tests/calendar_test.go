@@ -0,0 +1,81 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestEntityCalendar verifies that a person's and a group's loads are served
+// as an iCalendar feed, with multi-day loads spanning their days, recurring
+// loads carrying an RRULE, and private persons left out.
+func TestEntityCalendar(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("subscriber@example.com").WithTitle("Subscriber")
+	private := fixtures.NewPerson("hidden@example.com")
+	group := fixtures.NewGroup("calendar-team").WithTitle("Calendar Team").WithMembers(person, private)
+	a.NoError(fixtures.NewScenario().Add(person, private, group).Insert(ctx, env.DB), "should seed scenario")
+	_, err := env.DB.Exec(ctx, `UPDATE load_calendar_data.entities SET private = true WHERE id = $1`, private.ID())
+	a.NoError(err, "should make the person private")
+
+	start := time.Now().UTC().AddDate(0, 0, 3)
+	date := func(offset int) string { return start.AddDate(0, 0, offset).Format("2006-01-02") }
+	ics := func(offset int) string { return start.AddDate(0, 0, offset).Format("20060102") }
+	upsert := func(body map[string]interface{}) {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", body)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+	upsert(map[string]interface{}{
+		"external_id": "calendar-offsite", "title": "Offsite, day one", "source": "gcal",
+		"date": date(0), "end_date": date(2),
+		"assignees": []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+	})
+	upsert(map[string]interface{}{
+		"external_id": "calendar-standup", "title": "Standup",
+		"date": date(1), "recurrence": map[string]interface{}{"frequency": "weekly", "count": 4},
+		"assignees": []map[string]interface{}{{"email": person.ID()}, {"email": private.ID()}},
+	})
+	upsert(map[string]interface{}{
+		"external_id": "calendar-secret", "title": "Secret", "date": date(0),
+		"assignees": []map[string]interface{}{{"email": private.ID()}},
+	})
+
+	resp, err := env.API.Call("GET", "/api/entities/"+person.ID()+"/calendar.ics", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "person feed should be served: %s", resp.String())
+	a.Contains(resp.Headers.Get("Content-Type"), "text/calendar")
+	feed := resp.String()
+	a.Contains(feed, "X-WR-CALNAME:Subscriber\r\n")
+	a.Equal(2, strings.Count(feed, "BEGIN:VEVENT"))
+	a.Contains(feed, "DTSTART;VALUE=DATE:"+ics(0)+"\r\nDTEND;VALUE=DATE:"+ics(3)+"\r\n", "offsite should span three days")
+	a.Contains(feed, "SUMMARY:Offsite\\, day one\r\n")
+	a.Contains(feed, "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+	a.NotContains(feed, private.ID(), "private co-assignees should be left out")
+
+	resp, err = env.API.Call("GET", "/api/entities/"+group.ID()+"/calendar.ics", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "group feed should be served: %s", resp.String())
+	feed = resp.String()
+	a.Equal(2, strings.Count(feed, "BEGIN:VEVENT"), "loads only private members have should be left out")
+	a.NotContains(feed, "Secret")
+
+	resp, err = env.API.Call("GET", "/api/entities/"+private.ID()+"/calendar.ics", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "private feeds should not be found anonymously")
+
+	resp, err = env.API.Call("GET", "/api/entities/missing@example.com/calendar.ics", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
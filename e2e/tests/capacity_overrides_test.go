@@ -0,0 +1,97 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestDeleteCapacityOverrides verifies that a mistaken vacation is undone by
+// removing every override in a date range at once, by the person or an
+// admin, and that only the range's overrides go.
+func TestDeleteCapacityOverrides(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("overrides-person@example.com").WithCapacity(5)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	client := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		c := session.Client(env.ServiceURL())
+		return c
+	}
+	user, admin := client(person.ID()), client(testenv.AdminEmail)
+
+	start := time.Now().UTC().AddDate(0, 0, 7)
+	day := func(i int) string { return start.AddDate(0, 0, i).Format("2006-01-02") }
+	vacation := make([]map[string]interface{}, 5)
+	for i := range vacation {
+		vacation[i] = map[string]interface{}{"date": day(i), "capacity": 0}
+	}
+	resp, err := user.Call("POST", "/api/my-capacity", map[string]interface{}{"date_overrides": vacation})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should enter the vacation: %s", resp.String())
+
+	overrides := func() int {
+		var count int
+		rows, err := env.DB.Query(ctx,
+			`SELECT COUNT(*) FROM load_calendar_data.capacity_overrides WHERE entity_id = $1`, person.ID())
+		a.NoError(err)
+		a.True(rows.Next(), "should count")
+		a.NoError(rows.Scan(&count))
+		rows.Close()
+		return count
+	}
+	a.Equal(5, overrides())
+
+	var result struct {
+		Deleted int    `json:"deleted"`
+		From    string `json:"from"`
+		To      string `json:"to"`
+	}
+	resp, err = user.Call("DELETE", "/api/my-capacity/overrides?from="+day(0)+"&to="+day(2), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should remove the range: %s", resp.String())
+	a.NoError(resp.JSON(&result))
+	a.Equal(3, result.Deleted, "every override in the range is removed")
+	a.Equal(day(0), result.From)
+	a.Equal(2, overrides(), "overrides outside the range are kept")
+
+	resp, err = user.Call("GET", "/api/my-capacity/audit", nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "3 overrides from "+day(0)+" to "+day(2)+" removed", "the removal is audited once")
+
+	resp, err = user.Call("DELETE", "/api/my-capacity/overrides?from="+day(2)+"&to="+day(0), nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "from must not be after to")
+
+	adminPath := "/api/admin/capacity/" + person.ID() + "/overrides?from=" + day(0) + "&to=" + day(9)
+	resp, err = user.Call("DELETE", adminPath, nil)
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode, "only admins remove anyone's overrides")
+	a.Equal(2, overrides())
+
+	resp, err = admin.Call("DELETE", adminPath, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "an admin removes the rest: %s", resp.String())
+	a.NoError(resp.JSON(&result))
+	a.Equal(2, result.Deleted)
+	a.Equal(0, overrides())
+
+	resp, err = admin.Call("DELETE", adminPath, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&result))
+	a.Equal(0, result.Deleted, "an empty range removes nothing")
+}
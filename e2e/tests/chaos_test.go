@@ -0,0 +1,96 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestDatabaseChaos verifies how the service behaves when its database is
+// slow or failing: latency shows up in response times, failures surface as
+// 500s with a JSON error rather than hangs or crashes, and the service
+// recovers once the database is healthy again.
+func TestDatabaseChaos(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	ded, err := env.NewDedicatedEnv(ctx, t.Name(), testenv.WithChaos())
+	if err != nil {
+		t.Fatalf("failed to create dedicated env: %v", err)
+	}
+	defer ded.Teardown()
+
+	if ded.API == nil {
+		t.Skip("service is not running")
+	}
+
+	person := fixtures.NewPerson("chaos@example.com").WithCapacity(5)
+	a.NoError(person.Insert(ctx, ded.DB), "should seed person")
+
+	get := func(t *testing.T, path string) (*helpers.Response, time.Duration) {
+		t.Helper()
+		start := time.Now()
+		resp, err := ded.API.Call("GET", path, nil)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		return resp, time.Since(start)
+	}
+
+	// Healthy baseline
+	resp, _ := get(t, "/api/entities/"+person.ID())
+	a.Equal(http.StatusOK, resp.StatusCode, "baseline should succeed")
+
+	t.Run("latency", func(t *testing.T) {
+		a := helpers.NewAssert(t)
+		ded.Chaos.SetLatency(300*time.Millisecond, 0)
+		defer ded.Chaos.Disable()
+
+		resp, elapsed := get(t, "/api/entities/"+person.ID())
+		a.Equal(http.StatusOK, resp.StatusCode, "slow database should still answer")
+		a.True(elapsed >= 300*time.Millisecond, "latency should reach the client, took %s", elapsed)
+		a.True(ded.Chaos.Stats().Delayed > 0, "proxy should have delayed packets")
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		a := helpers.NewAssert(t)
+		ded.Chaos.SetFailureRate(1)
+		defer ded.Chaos.Disable()
+
+		for _, path := range []string{"/api/entities", "/api/entities/" + person.ID()} {
+			resp, elapsed := get(t, path)
+			a.Equal(http.StatusInternalServerError, resp.StatusCode, "%s should fail with 500: %s", path, resp.String())
+			a.True(elapsed < 15*time.Second, "%s should fail instead of hanging, took %s", path, elapsed)
+
+			var body map[string]string
+			a.NoError(resp.JSON(&body), "error response should be JSON")
+			a.NotEmpty(body["error"], "error response should carry a message")
+		}
+		a.True(ded.Chaos.Stats().Failures > 0, "proxy should have reset connections")
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		a := helpers.NewAssert(t)
+		ded.Chaos.Disable()
+
+		// The pool may hand out a connection that was reset mid-failure;
+		// the service must recover without a restart
+		var status int
+		for i := 0; i < 5; i++ {
+			resp, _ := get(t, "/api/entities/"+person.ID())
+			status = resp.StatusCode
+			if status == http.StatusOK {
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		a.Equal(http.StatusOK, status, "service should recover once the database is healthy")
+	})
+}
@@ -0,0 +1,119 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestConfidentialLoads verifies that a group's confidential load is only
+// shown in day details to the group's members and admins, that day totals
+// still count it, that only members can pin it, and that its assignees must
+// be members of the group.
+func TestConfidentialLoads(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	dealer := fixtures.NewPerson("confidential-dealer@example.com").WithCapacity(5)
+	partner := fixtures.NewPerson("confidential-partner@example.com").WithCapacity(5)
+	outsider := fixtures.NewPerson("confidential-outsider@example.com").WithCapacity(5)
+	deals := fixtures.NewGroup("confidential-deals").WithMembers(dealer, partner)
+	everyone := fixtures.NewGroup("confidential-everyone").WithMembers(dealer, partner, outsider)
+	a.NoError(fixtures.NewScenario().Add(dealer, partner, outsider, deals, everyone).Insert(ctx, env.DB), "should seed scenario")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID, title, confidentialGroup string, assignees ...string) *helpers.Response {
+		req := map[string]interface{}{
+			"external_id":        externalID,
+			"title":              title,
+			"date":               tomorrow,
+			"confidential_group": confidentialGroup,
+		}
+		var list []map[string]interface{}
+		for _, email := range assignees {
+			list = append(list, map[string]interface{}{"email": email, "weight": 1})
+		}
+		req["assignees"] = list
+		resp, err := env.API.Call("POST", "/api/loads/upsert", req)
+		a.NoError(err)
+		return resp
+	}
+
+	resp := upsert("confidential-merger", "Project Falcon", deals.ID(), dealer.ID())
+	a.Equal(http.StatusOK, resp.StatusCode, "should upsert the confidential load: %s", resp.String())
+	var upserted struct {
+		LoadID int `json:"load_id"`
+	}
+	a.NoError(resp.JSON(&upserted))
+	resp = upsert("confidential-standup", "Standup", "", dealer.ID())
+	a.Equal(http.StatusOK, resp.StatusCode, "should upsert the ordinary load: %s", resp.String())
+
+	resp = upsert("confidential-leak", "Project Falcon", deals.ID(), dealer.ID(), outsider.ID())
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "assignees must be members of the group")
+	resp = upsert("confidential-person", "Project Falcon", dealer.ID(), dealer.ID())
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "the confidential group must be a group")
+
+	client := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		c := session.Client(env.ServiceURL())
+		c.SetHeader("Accept", "application/json")
+		return c
+	}
+	member, nonMember, admin := client(partner.ID()), client(outsider.ID()), client(testenv.AdminEmail)
+
+	type dayDetails struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Load struct {
+				Title string `json:"title"`
+			} `json:"load"`
+		} `json:"loads"`
+	}
+	titles := func(c *helpers.APIClient, entityID string) ([]string, float64) {
+		resp, err := c.Call("GET", "/api/heatmap/"+entityID+"/day/"+tomorrow, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get day details: %s", resp.String())
+		var day dayDetails
+		a.NoError(resp.JSON(&day))
+		var got []string
+		for _, l := range day.Loads {
+			got = append(got, l.Load.Title)
+		}
+		return got, day.TotalLoad
+	}
+
+	for _, entityID := range []string{dealer.ID(), everyone.ID()} {
+		got, total := titles(nonMember, entityID)
+		a.Equal([]string{"Standup"}, got, "a non-member should not see the confidential load on %s", entityID)
+		a.Equal(2.0, total, "the day total still counts it")
+
+		got, _ = titles(member, entityID)
+		a.Len(got, 2, "a member should see the confidential load on %s", entityID)
+		got, _ = titles(admin, entityID)
+		a.Len(got, 2, "an admin should see the confidential load on %s", entityID)
+	}
+
+	pin := "/api/loads/" + strconv.Itoa(upserted.LoadID) + "/pin"
+	resp, err := nonMember.Call("POST", pin, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "a non-member cannot pin the confidential load")
+	resp, err = member.Call("POST", pin, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "a member can pin it: %s", resp.String())
+
+	resp = upsert("confidential-merger", "Project Falcon", "", dealer.ID())
+	a.Equal(http.StatusOK, resp.StatusCode)
+	got, _ := titles(nonMember, dealer.ID())
+	a.Len(got, 2, "upserting without the group makes the load visible again")
+}
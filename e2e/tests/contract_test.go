@@ -0,0 +1,792 @@
+//go:build e2e
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/contract"
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// contractClient replays requests against the running service and checks
+// every request and response against docs/swagger.json.
+type contractClient struct {
+	t       *testing.T
+	spec    *contract.Spec
+	baseURL string
+	client  *http.Client
+	covered map[string]bool
+}
+
+// contractCall describes one replayed request.
+type contractCall struct {
+	method  string
+	path    string
+	body    interface{}
+	apiKey  bool
+	session *fixtures.SessionBuilder
+	// accept sets the Accept header, for negotiated responses.
+	accept string
+	want   int
+	// invalid marks bodies that intentionally violate the spec.
+	invalid bool
+}
+
+func newContractClient(t *testing.T, spec *contract.Spec) *contractClient {
+	return &contractClient{
+		t:       t,
+		spec:    spec,
+		baseURL: env.ServiceURL(),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Redirects are part of the contract, so do not follow them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		covered: make(map[string]bool),
+	}
+}
+
+// do sends the call, asserts the expected status, validates both sides
+// against the spec, and returns the decoded JSON body (if any).
+func (c *contractClient) do(call contractCall) map[string]interface{} {
+	c.t.Helper()
+
+	var body []byte
+	if call.body != nil {
+		var err error
+		body, err = json.Marshal(call.body)
+		if err != nil {
+			c.t.Fatalf("failed to marshal body: %v", err)
+		}
+	}
+
+	if template, _, ok := c.spec.Match(call.method, call.path); ok {
+		c.covered[contract.Route{Method: call.method, Path: template}.String()] = true
+	}
+
+	if !call.invalid && body != nil {
+		if err := c.spec.ValidateRequest(call.method, call.path, body); err != nil {
+			c.t.Errorf("request does not match spec: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(call.method, c.baseURL+call.path, bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("failed to create request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if call.apiKey {
+		req.Header.Set("x-api-key", env.Config.Service.APIKey)
+	}
+	if call.session != nil {
+		req.AddCookie(&http.Cookie{Name: "session_token", Value: call.session.Token()})
+	}
+	if call.accept != "" {
+		req.Header.Set("Accept", call.accept)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s failed: %v", call.method, call.path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != call.want {
+		c.t.Errorf("%s %s: expected status %d, got %d: %s", call.method, call.path, call.want, resp.StatusCode, respBody)
+	}
+
+	if err := c.spec.ValidateResponse(call.method, call.path, resp.StatusCode, resp.Header.Get("Content-Type"), respBody); err != nil {
+		c.t.Errorf("response does not match spec: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	_ = json.Unmarshal(respBody, &decoded)
+	return decoded
+}
+
+// TestContract replays representative traffic for every documented
+// operation and fails when the handlers and docs/swagger.json disagree.
+func TestContract(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	spec, err := contract.LoadDefault()
+	a.NoError(err, "should load spec")
+
+	person := fixtures.NewPerson("contract@example.com").WithTitle("Contract Person").WithEmployeeID("EMP-CONTRACT")
+	group := fixtures.NewGroup("contract-group").WithTitle("Contract Group").WithMembers(person)
+	a.NoError(fixtures.NewScenario().Add(person, group).Insert(ctx, env.DB), "should seed scenario")
+
+	personSession := fixtures.NewSession(person.ID())
+	a.NoError(personSession.Insert(ctx, env.DB), "should create session")
+
+	today := time.Now().Format("2006-01-02")
+	c := newContractClient(t, spec)
+
+	// Public reads
+	c.do(contractCall{method: "GET", path: "/health", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities?type=person", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/changes?since=2025-01-01T00:00:00Z", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/changes", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/entities/" + person.ID(), want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/missing@example.com", want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/entities/" + group.ID() + "/calendar.ics", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/missing@example.com/calendar.ics", want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID(), want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID(), accept: "application/json", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/json", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/nobody@example.com/json", want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "?days=14", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/json?from=" + today + "&to=" + today, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "?days=400", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/json?from=not-a-date", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/day/" + today, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/day/not-a-date", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + group.ID() + "/day/" + today + "/members", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + group.ID() + "/day/" + today + "/members", accept: "application/json", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + group.ID() + "/day/not-a-date/members", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/day/" + today + "/members", want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/missing-group/day/" + today + "/members", want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/rebalance/" + group.ID() + "?date=" + today, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/rebalance/" + group.ID(), want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/api/rebalance/missing-group", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/least-loaded?date=" + today + "&weight=2", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/least-loaded?date=not-a-date", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/least-loaded?weight=heavy", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + person.ID() + "/least-loaded", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/groups/missing-group/least-loaded", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/reports/overload-resolution?from=" + today + "&to=" + today, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/reports/overload-resolution?from=not-a-date", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/metrics", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/reports/utilization", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/reports/utilization?quarter=2020-Q1&format=csv", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/reports/utilization?quarter=2020-Q5", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/reports/utilization?format=xml", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/reports/utilization", want: http.StatusUnauthorized})
+
+	// Authentication
+	c.do(contractCall{method: "POST", path: "/auth/request-otp", body: map[string]string{"email": "not-an-email"}, want: http.StatusBadRequest})
+	c.do(contractCall{method: "POST", path: "/auth/request-otp", body: map[string]string{"email": "nobody@example.com"}, want: http.StatusNotFound})
+	c.do(contractCall{method: "POST", path: "/auth/verify-otp", body: map[string]string{"email": person.ID(), "otp": "000000"}, want: http.StatusUnauthorized})
+	c.do(contractCall{method: "POST", path: "/auth/logout", want: http.StatusFound})
+	c.do(contractCall{method: "GET", path: "/auth/oidc/login", want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/auth/oidc/callback?state=x&code=y", want: http.StatusNotFound})
+
+	// Session-protected capacity endpoints
+	c.do(contractCall{method: "POST", path: "/api/my-capacity", body: map[string]float64{"default_capacity": 4}, want: http.StatusUnauthorized})
+	c.do(contractCall{method: "POST", path: "/api/my-capacity", body: map[string]float64{"default_capacity": 4}, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/my-capacity/override/" + today, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/my-capacity/overrides?from=" + today + "&to=" + today, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/my-capacity/overrides?from=" + today, session: personSession, want: http.StatusBadRequest})
+	c.do(contractCall{method: "DELETE", path: "/api/my-capacity/overrides?from=" + today + "&to=" + today, want: http.StatusUnauthorized})
+	weekly := func(weekday string, capacity interface{}) map[string]interface{} {
+		return map[string]interface{}{"weekly_pattern": []map[string]interface{}{{"weekday": weekday, "capacity": capacity}}}
+	}
+	c.do(contractCall{method: "POST", path: "/api/my-capacity", body: weekly("friday", 6), session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/my-capacity", body: weekly("friday", nil), session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/my-capacity", body: weekly("someday", 6), session: personSession, want: http.StatusBadRequest})
+
+	// Entity management
+	newPerson := "contract-new@example.com"
+	c.do(contractCall{method: "POST", path: "/api/entities", apiKey: true, want: http.StatusCreated,
+		body: map[string]interface{}{"id": newPerson, "title": "New Person", "type": "person", "default_capacity": 5}})
+	c.do(contractCall{method: "POST", path: "/api/entities", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"id": "bad", "title": "Bad", "type": "team"}})
+	c.do(contractCall{method: "POST", path: "/api/entities", apiKey: true, want: http.StatusConflict,
+		body: map[string]interface{}{"id": newPerson, "title": "New Person", "type": "person"}})
+	c.do(contractCall{method: "POST", path: "/api/entities?upsert=true", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"id": newPerson, "title": "New Person", "type": "person"}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + newPerson, apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"title": "Renamed Person"}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/missing@example.com", apiKey: true, want: http.StatusNotFound,
+		body: map[string]interface{}{"title": "Nobody"}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + newPerson, apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"manager_email": person.ID()}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + newPerson, apiKey: true, want: http.StatusBadRequest,
+		body: map[string]interface{}{"capacity_mode": "members"}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID(), apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"capacity_mode": "members"}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID(), apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"capacity_mode": "static"}})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/reports", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + newPerson + "/reports", want: http.StatusNotFound})
+
+	// Group membership
+	c.do(contractCall{method: "POST", path: "/api/groups/" + group.ID() + "/members", apiKey: true, want: http.StatusOK,
+		body: map[string]string{"person_email": newPerson}})
+	c.do(contractCall{method: "POST", path: "/api/groups/missing-group/members", apiKey: true, want: http.StatusNotFound,
+		body: map[string]string{"person_email": newPerson}})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/members", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "PUT", path: "/api/groups/" + group.ID() + "/heatmap-exclusions/" + newPerson, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "PUT", path: "/api/groups/" + group.ID() + "/heatmap-exclusions/missing@example.com", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/heatmap-exclusions", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + group.ID() + "/json?exclude=" + person.ID(), want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/groups/" + group.ID() + "/heatmap-exclusions/" + newPerson, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/groups/" + group.ID() + "/members/" + newPerson, apiKey: true, want: http.StatusOK})
+
+	// Group ownership and capacity approvals
+	c.do(contractCall{method: "POST", path: "/api/groups/" + group.ID() + "/owners", apiKey: true, want: http.StatusOK,
+		body: map[string]string{"person_email": newPerson}})
+	c.do(contractCall{method: "POST", path: "/api/groups/missing-group/owners", apiKey: true, want: http.StatusNotFound,
+		body: map[string]string{"person_email": newPerson}})
+	c.do(contractCall{method: "GET", path: "/api/groups/" + group.ID() + "/owners", apiKey: true, want: http.StatusOK})
+
+	ownerSession := fixtures.NewSession(newPerson)
+	a.NoError(ownerSession.Insert(ctx, env.DB), "should create owner session")
+
+	approvalPath := func(change map[string]interface{}, decision string) string {
+		id, ok := change["id"].(float64)
+		if !ok {
+			t.Fatalf("capacity change response missing id: %v", change)
+		}
+		return fmt.Sprintf("/api/capacity-approvals/%d/%s", int(id), decision)
+	}
+	reduced := c.do(contractCall{method: "POST", path: "/api/my-capacity", body: map[string]float64{"default_capacity": 2}, session: personSession, want: http.StatusAccepted})
+	c.do(contractCall{method: "GET", path: "/api/capacity-approvals", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/api/capacity-approvals", session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: approvalPath(reduced, "approve"), session: personSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "POST", path: approvalPath(reduced, "approve"), session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: approvalPath(reduced, "reject"), session: ownerSession, want: http.StatusConflict})
+	reduced = c.do(contractCall{method: "POST", path: "/api/my-capacity", body: map[string]float64{"default_capacity": 1}, session: personSession, want: http.StatusAccepted})
+	c.do(contractCall{method: "POST", path: approvalPath(reduced, "reject"), session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/capacity-approvals/999999/approve", session: ownerSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: "/api/groups/" + group.ID() + "/owners/" + newPerson, apiKey: true, want: http.StatusOK})
+
+	// Delegated capacity management
+	onBehalf := "?person=" + url.QueryEscape(person.ID())
+	c.do(contractCall{method: "POST", path: "/api/my-delegations", session: personSession, want: http.StatusOK,
+		body: map[string]string{"assistant_email": newPerson}})
+	c.do(contractCall{method: "POST", path: "/api/my-delegations", session: personSession, want: http.StatusBadRequest,
+		body: map[string]string{"assistant_email": person.ID()}})
+	c.do(contractCall{method: "POST", path: "/api/my-delegations", session: personSession, want: http.StatusNotFound,
+		body: map[string]string{"assistant_email": "missing@example.com"}})
+	c.do(contractCall{method: "GET", path: "/api/my-delegations", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/api/my-delegations", session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/my-capacity" + onBehalf, body: map[string]float64{"default_capacity": 5}, session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/my-capacity/override/" + today + onBehalf, session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/my-capacity/audit" + onBehalf, session: ownerSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/my-capacity/audit", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/my-capacity" + onBehalf, session: ownerSession, accept: "application/json", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/my-capacity", accept: "application/json", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "DELETE", path: "/api/my-delegations/" + newPerson, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/my-delegations/" + newPerson, session: personSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/my-capacity/audit" + onBehalf, session: ownerSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "GET", path: "/my-capacity" + onBehalf, session: ownerSession, accept: "application/json", want: http.StatusForbidden})
+
+	// Public dashboards
+	c.do(contractCall{method: "GET", path: "/api/dashboard/" + group.ID(), want: http.StatusNotFound})
+	c.do(contractCall{method: "PUT", path: "/api/groups/" + group.ID() + "/dashboard", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "PUT", path: "/api/groups/missing-group/dashboard", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "PUT", path: "/api/groups/" + person.ID() + "/dashboard", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/dashboard/" + group.ID(), want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/groups/" + group.ID() + "/dashboard", apiKey: true, want: http.StatusOK})
+
+	// Weekly Lark digests
+	digestPath := "/api/groups/" + group.ID() + "/lark-digest"
+	c.do(contractCall{method: "GET", path: digestPath, apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "PUT", path: digestPath, apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"chat_id": "oc_contract"}})
+	c.do(contractCall{method: "PUT", path: digestPath, apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"enabled": false}})
+	c.do(contractCall{method: "PUT", path: "/api/groups/" + person.ID() + "/lark-digest", apiKey: true, want: http.StatusBadRequest,
+		body: map[string]interface{}{"chat_id": "oc_contract"}})
+	c.do(contractCall{method: "GET", path: digestPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: digestPath, want: http.StatusUnauthorized})
+	c.do(contractCall{method: "DELETE", path: digestPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/groups/missing-group/lark-digest", apiKey: true, want: http.StatusNotFound})
+
+	// Onboarding and offboarding
+	onboarded := "contract-onboarded@example.com"
+	c.do(contractCall{method: "POST", path: "/api/people/onboard", apiKey: true, want: http.StatusCreated,
+		body: map[string]interface{}{
+			"email":   onboarded,
+			"title":   "Onboarded Person",
+			"groups":  []string{group.ID()},
+			"ramp_up": map[string]interface{}{"days": 5, "capacity": 2},
+		}})
+	c.do(contractCall{method: "POST", path: "/api/people/onboard", apiKey: true, want: http.StatusConflict,
+		body: map[string]interface{}{"email": onboarded, "title": "Onboarded Again"}})
+	c.do(contractCall{method: "POST", path: "/api/people/" + onboarded + "/offboard", apiKey: true, want: http.StatusOK,
+		body: map[string]string{"last_day": today}})
+	c.do(contractCall{method: "POST", path: "/api/people/missing@example.com/offboard", apiKey: true, want: http.StatusNotFound})
+	renamed := "contract-renamed@example.com"
+	c.do(contractCall{method: "POST", path: "/api/entities/" + onboarded + "/change-id", apiKey: true, want: http.StatusOK,
+		body: map[string]string{"new_id": renamed}})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/change-id", apiKey: true, want: http.StatusConflict,
+		body: map[string]string{"new_id": person.ID()}})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/change-id", apiKey: true, want: http.StatusBadRequest,
+		body: map[string]string{"new_id": "not-an-email"}})
+	c.do(contractCall{method: "POST", path: "/api/entities/missing@example.com/change-id", apiKey: true, want: http.StatusNotFound,
+		body: map[string]string{"new_id": "found@example.com"}})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/restore", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/restore", apiKey: true, want: http.StatusConflict})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/archive", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/entities/" + renamed + "/archive", apiKey: true, want: http.StatusConflict})
+	c.do(contractCall{method: "POST", path: "/api/entities/missing@example.com/archive", apiKey: true, want: http.StatusNotFound})
+
+	// Auto-created persons review
+	c.do(contractCall{method: "GET", path: "/api/people/auto-created", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/people/auto-created/confirm", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"emails": []string{person.ID()}}})
+	c.do(contractCall{method: "POST", path: "/api/people/auto-created/reject", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"emails": []string{"contract-never-created@example.com"}}})
+	c.do(contractCall{method: "POST", path: "/api/people/auto-created/reject", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"emails": []string{}}})
+
+	// Group import
+	imported := map[string]interface{}{"rows": []map[string]string{
+		{"group": "contract-imported", "member": person.ID()},
+		{"group": "contract-imported", "member": "contract-imported@example.com"},
+	}}
+	c.do(contractCall{method: "POST", path: "/api/groups/import", apiKey: true, want: http.StatusOK, body: imported})
+	c.do(contractCall{method: "POST", path: "/api/groups/import", apiKey: true, want: http.StatusOK, body: imported})
+	c.do(contractCall{method: "POST", path: "/api/groups/import", apiKey: true, want: http.StatusBadRequest,
+		body: map[string]interface{}{"rows": []map[string]string{{"group": person.ID(), "member": "contract-imported@example.com"}}}})
+
+	// Assignment suggestions
+	c.do(contractCall{method: "POST", path: "/api/suggest-assignee", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"date": today, "weight": 1, "skill": "go"}})
+	c.do(contractCall{method: "POST", path: "/api/suggest-assignee", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"date": today}})
+
+	// What-if scenarios
+	created := c.do(contractCall{method: "POST", path: "/api/scenarios", apiKey: true, want: http.StatusCreated,
+		body: map[string]interface{}{"name": "Contract Scenario", "description": "Contract test scenario"}})
+	c.do(contractCall{method: "POST", path: "/api/scenarios", apiKey: true, want: http.StatusConflict,
+		body: map[string]interface{}{"name": "Contract Scenario"}})
+
+	scenarioID, ok := created["id"].(float64)
+	if !ok {
+		t.Fatalf("create scenario response missing id: %v", created)
+	}
+	scenarioPath := fmt.Sprintf("/api/scenarios/%d", int(scenarioID))
+
+	c.do(contractCall{method: "GET", path: "/api/scenarios", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/scenarios/999999", want: http.StatusNotFound})
+	detail := c.do(contractCall{method: "POST", path: scenarioPath + "/loads", apiKey: true, want: http.StatusCreated,
+		body: map[string]interface{}{
+			"title":     "Hypothetical Load",
+			"date":      today,
+			"assignees": []map[string]interface{}{{"email": person.ID(), "weight": 2}},
+		}})
+	c.do(contractCall{method: "POST", path: scenarioPath + "/loads", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"title": "No assignees", "date": today}})
+	c.do(contractCall{method: "PUT", path: scenarioPath + "/capacity", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"entity_id": person.ID(), "date": today, "capacity": 2}})
+	c.do(contractCall{method: "GET", path: scenarioPath, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: scenarioPath + "/heatmap/" + person.ID(), want: http.StatusOK})
+
+	scenarioLoads, _ := detail["loads"].([]interface{})
+	if len(scenarioLoads) != 1 {
+		t.Fatalf("expected one scenario load, got %v", detail["loads"])
+	}
+	scenarioLoadID := scenarioLoads[0].(map[string]interface{})["id"].(float64)
+	c.do(contractCall{method: "DELETE", path: fmt.Sprintf("%s/loads/%d", scenarioPath, int(scenarioLoadID)), apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: scenarioPath + "/loads/999999", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: scenarioPath + "/capacity/" + person.ID() + "/" + today, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: scenarioPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: scenarioPath, apiKey: true, want: http.StatusNotFound})
+
+	// Shadow sources
+	c.do(contractCall{method: "POST", path: "/api/shadow-sources", apiKey: true, want: http.StatusCreated,
+		body: map[string]string{"source": "contract-shadow"}})
+	c.do(contractCall{method: "POST", path: "/api/shadow-sources", apiKey: true, want: http.StatusConflict,
+		body: map[string]string{"source": "contract-shadow"}})
+	c.do(contractCall{method: "POST", path: "/api/shadow-sources", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]string{}})
+	c.do(contractCall{method: "GET", path: "/api/shadow-sources", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/shadow-sources/contract-shadow", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/shadow-sources/contract-shadow", apiKey: true, want: http.StatusNotFound})
+
+	// Blackout dates
+	blackoutsPath := "/api/entities/" + group.ID() + "/blackouts"
+	blackout := c.do(contractCall{method: "POST", path: blackoutsPath, apiKey: true, want: http.StatusCreated,
+		body: map[string]string{"start_date": "2099-01-01", "end_date": "2099-01-05", "reason": "Release freeze"}})
+	c.do(contractCall{method: "POST", path: blackoutsPath, apiKey: true, want: http.StatusBadRequest,
+		body: map[string]string{"start_date": "2099-01-05", "end_date": "2099-01-01", "reason": "Backwards"}})
+	c.do(contractCall{method: "POST", path: "/api/entities/missing@example.com/blackouts", apiKey: true, want: http.StatusNotFound,
+		body: map[string]string{"start_date": "2099-01-01", "reason": "Nobody"}})
+	c.do(contractCall{method: "GET", path: blackoutsPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/missing@example.com/blackouts", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{
+			"external_id": "contract-blackout-load",
+			"title":       "Load In Freeze",
+			"date":        "2099-01-02",
+			"assignees":   []map[string]interface{}{{"email": person.ID()}},
+		}})
+	blackoutID, ok := blackout["id"].(float64)
+	if !ok {
+		t.Fatalf("blackout response missing id: %v", blackout)
+	}
+	c.do(contractCall{method: "DELETE", path: fmt.Sprintf("%s/%d", blackoutsPath, int(blackoutID)), apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: blackoutsPath + "/999999", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: "/api/loads/by-external-id/contract-blackout-load", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/loads/by-external-id/contract-blackout-load", apiKey: true, want: http.StatusNotFound})
+
+	// Loads
+	upserted := c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{
+			"external_id": "contract-load-1",
+			"title":       "Contract Load",
+			"source":      "e2e-test",
+			"date":        today,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 1.5}},
+		}})
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"title": "Missing fields"}})
+	// Dry runs answer with a DryRunResult, checked in dry_run_test.go; only
+	// their refusals share the documented error bodies
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert?dry_run=maybe", apiKey: true, want: http.StatusBadRequest,
+		body: map[string]interface{}{
+			"external_id": "contract-dry-run",
+			"title":       "Dry Run",
+			"date":        today,
+			"assignees":   []map[string]interface{}{{"email": person.ID()}},
+		}})
+	multiDay := map[string]interface{}{
+		"external_id": "contract-multi-day",
+		"title":       "Contract Offsite",
+		"date":        "2099-02-02",
+		"end_date":    "2099-02-06",
+		"spread":      "per_day",
+		"assignees":   []map[string]interface{}{{"email": person.ID()}},
+	}
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusOK, body: multiDay})
+	multiDay["end_date"] = "2099-02-01"
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusBadRequest, body: multiDay})
+	c.do(contractCall{method: "DELETE", path: "/api/loads/by-external-id/contract-multi-day", apiKey: true, want: http.StatusOK})
+	recurring := map[string]interface{}{
+		"external_id": "contract-recurring",
+		"title":       "Contract Standup",
+		"date":        "2099-03-02",
+		"recurrence":  map[string]interface{}{"frequency": "weekly", "count": 4},
+		"assignees":   []map[string]interface{}{{"email": person.ID()}},
+	}
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusOK, body: recurring})
+	recurring["end_date"] = "2099-03-10"
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert", apiKey: true, want: http.StatusBadRequest, body: recurring})
+	c.do(contractCall{method: "DELETE", path: "/api/loads/by-external-id/contract-recurring", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert-by-employee-id", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{
+			"external_id": "contract-load-2",
+			"title":       "Contract Load By Employee",
+			"date":        today,
+			"assignees":   []map[string]interface{}{{"employee_id": "EMP-CONTRACT"}},
+		}})
+	c.do(contractCall{method: "POST", path: "/api/loads/upsert-by-employee-id", apiKey: true, want: http.StatusNotFound,
+		body: map[string]interface{}{
+			"external_id": "contract-load-3",
+			"title":       "Unknown Employee",
+			"date":        today,
+			"assignees":   []map[string]interface{}{{"employee_id": "EMP-MISSING"}},
+		}})
+	issue := map[string]interface{}{
+		"webhookEvent": "jira:issue_updated",
+		"issue": map[string]interface{}{
+			"key": "CONTRACT-1",
+			"fields": map[string]interface{}{
+				"summary":           "Contract issue",
+				"assignee":          map[string]interface{}{"emailAddress": person.ID()},
+				"duedate":           today,
+				"customfield_10016": 2,
+			},
+		},
+	}
+	c.do(contractCall{method: "POST", path: "/api/loads/from-issue", apiKey: true, want: http.StatusOK, body: issue})
+	issue["webhookEvent"] = "jira:issue_deleted"
+	c.do(contractCall{method: "POST", path: "/api/loads/from-issue", apiKey: true, want: http.StatusOK, body: issue})
+	c.do(contractCall{method: "POST", path: "/api/loads/from-issue", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"issue": map[string]interface{}{"fields": map[string]interface{}{}}}})
+	c.do(contractCall{method: "POST", path: "/api/loads/from-issue?dry_run=true", apiKey: true, want: http.StatusBadRequest, body: issue})
+
+	loadID, ok := upserted["load_id"].(float64)
+	if !ok {
+		t.Fatalf("upsert response missing load_id: %v", upserted)
+	}
+	loadPath := fmt.Sprintf("/api/loads/%d/assignees", int(loadID))
+
+	c.do(contractCall{method: "POST", path: loadPath, apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"assignees": []map[string]interface{}{{"email": newPerson, "weight": 1}}}})
+	c.do(contractCall{method: "POST", path: "/api/loads/999999/assignees", apiKey: true, want: http.StatusNotFound,
+		body: map[string]interface{}{"assignees": []map[string]interface{}{{"email": newPerson}}}})
+	c.do(contractCall{method: "DELETE", path: loadPath + "/" + newPerson, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: loadPath + "/nobody@example.com", apiKey: true, want: http.StatusNotFound})
+
+	// The load was just upserted, so it is not stale and is skipped
+	c.do(contractCall{method: "GET", path: "/api/loads?source=e2e-test&limit=10", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/loads?from=2025-03-10&to=2025-03-01", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/loads", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/api/loads/stale?source=e2e-test", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/loads/stale/delete", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"load_ids": []int{int(loadID)}}})
+	c.do(contractCall{method: "POST", path: "/api/loads/stale/delete", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"load_ids": []int{}}})
+	c.do(contractCall{method: "GET", path: "/api/utilization?entities=" + newPerson + "&limit=10", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/utilization", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/utilization?entities=nobody@example.com", apiKey: true, want: http.StatusNotFound})
+
+	ackPath := fmt.Sprintf("/api/loads/%d/acknowledge", int(loadID))
+	c.do(contractCall{method: "GET", path: "/api/my-loads/unacknowledged", session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/my-loads/unacknowledged", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "POST", path: ackPath, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: ackPath, session: ownerSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "POST", path: "/api/loads/abc/acknowledge", session: personSession, want: http.StatusBadRequest})
+	c.do(contractCall{method: "POST", path: ackPath, want: http.StatusUnauthorized})
+
+	actualPath := fmt.Sprintf("/api/loads/%d/actual", int(loadID))
+	c.do(contractCall{method: "POST", path: actualPath, session: personSession, want: http.StatusOK,
+		body: map[string]float64{"actual": 1.5}})
+	c.do(contractCall{method: "POST", path: actualPath, session: personSession, want: http.StatusBadRequest,
+		body: map[string]float64{"actual": -1}, invalid: true})
+	c.do(contractCall{method: "POST", path: actualPath, session: ownerSession, want: http.StatusNotFound,
+		body: map[string]float64{"actual": 1}})
+	c.do(contractCall{method: "POST", path: actualPath, want: http.StatusUnauthorized,
+		body: map[string]float64{"actual": 1}})
+	c.do(contractCall{method: "GET", path: "/api/reports/calibration", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/reports/calibration?from=2025-02-30", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/integrations/health", want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/events?limit=10", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/events?after=-1", apiKey: true, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/events", want: http.StatusUnauthorized})
+
+	// Bulk operations answer with a job to poll
+	bulk := c.do(contractCall{method: "POST", path: "/api/loads/bulk-upsert", apiKey: true, want: http.StatusAccepted,
+		body: map[string]interface{}{"loads": []map[string]interface{}{{
+			"external_id": "contract-bulk-1",
+			"title":       "Bulk Load",
+			"date":        "2099-03-02",
+			"assignees":   []map[string]interface{}{{"email": person.ID()}},
+		}}}})
+	c.do(contractCall{method: "POST", path: "/api/loads/bulk-upsert", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"loads": []map[string]interface{}{}}})
+	c.do(contractCall{method: "POST", path: "/api/loads/reassign", apiKey: true, want: http.StatusAccepted,
+		body: map[string]string{"from": newPerson, "to": person.ID(), "from_date": "2099-12-01"}})
+	c.do(contractCall{method: "POST", path: "/api/loads/reassign", apiKey: true, want: http.StatusNotFound,
+		body: map[string]string{"from": newPerson, "to": "missing@example.com"}})
+	c.do(contractCall{method: "POST", path: "/api/loads/reassign", apiKey: true, want: http.StatusBadRequest,
+		body: map[string]string{"from": newPerson, "to": newPerson}})
+	c.do(contractCall{method: "POST", path: "/api/snapshots/backfill", apiKey: true, want: http.StatusAccepted,
+		body: map[string]interface{}{"entity_ids": []string{person.ID()}}})
+	bulkID, ok := bulk["id"].(float64)
+	if !ok {
+		t.Fatalf("bulk upsert response missing id: %v", bulk)
+	}
+	c.do(contractCall{method: "GET", path: fmt.Sprintf("/api/jobs/%d", int64(bulkID)), apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/jobs/999999", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/jobs/abc", apiKey: true, want: http.StatusBadRequest})
+
+	// Webhook endpoints; kept disabled so nothing is delivered to them
+	hook := c.do(contractCall{method: "POST", path: "/api/webhooks", apiKey: true, want: http.StatusCreated,
+		body: map[string]interface{}{"url": "https://hooks.example.com/contract", "events": []string{"load_created"}, "enabled": false}})
+	c.do(contractCall{method: "POST", path: "/api/webhooks", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"url": "https://hooks.example.com/contract", "events": []string{}}})
+	c.do(contractCall{method: "GET", path: "/api/webhooks", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/webhooks", want: http.StatusUnauthorized})
+	hookID, ok := hook["id"].(float64)
+	if !ok {
+		t.Fatalf("webhook response missing id: %v", hook)
+	}
+	hookPath := fmt.Sprintf("/api/webhooks/%d", int(hookID))
+	c.do(contractCall{method: "GET", path: hookPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "PUT", path: hookPath, apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"events": []string{"load_created", "capacity_changed"}}})
+	c.do(contractCall{method: "PUT", path: hookPath, apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"url": "not a url"}})
+	c.do(contractCall{method: "DELETE", path: hookPath, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: hookPath, apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "GET", path: "/api/webhooks/abc", apiKey: true, want: http.StatusBadRequest})
+
+	// Custom field definitions
+	c.do(contractCall{method: "PUT", path: "/api/custom-fields/contract/meeting_link", apiKey: true, want: http.StatusOK,
+		body: map[string]interface{}{"label": "Meeting", "type": "url", "display": true}})
+	c.do(contractCall{method: "PUT", path: "/api/custom-fields/contract/meeting_link", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"label": "Meeting", "type": "date"}})
+	c.do(contractCall{method: "GET", path: "/api/custom-fields?source=contract", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/custom-fields", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "DELETE", path: "/api/custom-fields/contract/meeting_link", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/custom-fields/contract/meeting_link", apiKey: true, want: http.StatusNotFound})
+
+	c.do(contractCall{method: "GET", path: "/api/holidays/ID?year=2025", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/holidays/ID", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "POST", path: "/api/holidays/ID/import", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: "not a calendar"})
+	c.do(contractCall{method: "POST", path: "/api/holidays/ID/fetch", apiKey: true, want: http.StatusBadRequest, invalid: true,
+		body: map[string]interface{}{"year": 1800}})
+	c.do(contractCall{method: "DELETE", path: "/api/holidays/ID/2025-01-01", apiKey: true, want: http.StatusNotFound})
+
+	notesPath := fmt.Sprintf("/api/loads/%d/notes", int(loadID))
+	c.do(contractCall{method: "POST", path: notesPath, session: personSession, want: http.StatusCreated,
+		body: map[string]string{"body": "Needs the staging database"}})
+	c.do(contractCall{method: "POST", path: notesPath, session: personSession, want: http.StatusBadRequest,
+		body: map[string]string{"body": strings.Repeat("x", 281)}, invalid: true})
+	c.do(contractCall{method: "POST", path: "/api/loads/999999/notes", session: personSession, want: http.StatusNotFound,
+		body: map[string]string{"body": "Lost"}})
+	c.do(contractCall{method: "POST", path: notesPath, want: http.StatusUnauthorized,
+		body: map[string]string{"body": "Anonymous"}})
+	dayNote := c.do(contractCall{method: "POST", path: "/api/my-notes", session: personSession, want: http.StatusCreated,
+		body: map[string]string{"date": today, "body": "Travel day"}})
+	c.do(contractCall{method: "POST", path: "/api/my-notes", session: personSession, want: http.StatusBadRequest,
+		body: map[string]string{"date": "not-a-date", "body": "Travel day"}})
+	c.do(contractCall{method: "GET", path: "/api/heatmap/" + person.ID() + "/day/" + today, accept: "application/json", want: http.StatusOK})
+	dayNoteID, ok := dayNote["id"].(float64)
+	if !ok {
+		t.Fatalf("note response missing id: %v", dayNote)
+	}
+	c.do(contractCall{method: "DELETE", path: fmt.Sprintf("/api/my-notes/%d", int(dayNoteID)), session: ownerSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: fmt.Sprintf("/api/my-notes/%d", int(dayNoteID)), session: personSession, want: http.StatusOK})
+
+	// Incident notes on the status page are for admins only
+	adminSession := fixtures.NewSession(testenv.AdminEmail)
+	a.NoError(adminSession.Insert(ctx, env.DB), "should create admin session")
+	incident := c.do(contractCall{method: "POST", path: "/api/status/incidents", session: adminSession, want: http.StatusCreated,
+		body: map[string]string{"body": "Jira sync is delayed"}})
+	c.do(contractCall{method: "POST", path: "/api/status/incidents", session: personSession, want: http.StatusForbidden,
+		body: map[string]string{"body": "Jira sync is delayed"}})
+	c.do(contractCall{method: "POST", path: "/api/status/incidents", session: adminSession, want: http.StatusBadRequest,
+		body: map[string]string{"body": strings.Repeat("x", 1001)}, invalid: true})
+	c.do(contractCall{method: "POST", path: "/api/status/incidents", want: http.StatusUnauthorized,
+		body: map[string]string{"body": "Anonymous"}})
+	incidentID, ok := incident["id"].(float64)
+	if !ok {
+		t.Fatalf("incident response missing id: %v", incident)
+	}
+	incidentPath := fmt.Sprintf("/api/status/incidents/%d", int(incidentID))
+	c.do(contractCall{method: "PUT", path: incidentPath, session: adminSession, want: http.StatusOK,
+		body: map[string]interface{}{"resolved": true}})
+	c.do(contractCall{method: "PUT", path: "/api/status/incidents/999999", session: adminSession, want: http.StatusNotFound,
+		body: map[string]interface{}{"resolved": true}})
+	c.do(contractCall{method: "DELETE", path: incidentPath, session: personSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "DELETE", path: incidentPath, session: adminSession, want: http.StatusOK})
+
+	// Settings are for admins only; the default capacity is left as it is
+	c.do(contractCall{method: "GET", path: "/settings", accept: "application/json", session: adminSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/settings", accept: "application/json", session: personSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "PUT", path: "/api/settings", session: adminSession, want: http.StatusOK,
+		body: map[string]interface{}{"default_capacity": 5}})
+	c.do(contractCall{method: "PUT", path: "/api/settings", session: adminSession, want: http.StatusBadRequest,
+		body: map[string]interface{}{"color_thresholds": []float64{0.8, 0.6}}})
+	c.do(contractCall{method: "PUT", path: "/api/settings", session: personSession, want: http.StatusForbidden,
+		body: map[string]interface{}{"default_capacity": 5}})
+	c.do(contractCall{method: "PUT", path: "/api/settings", want: http.StatusUnauthorized,
+		body: map[string]interface{}{"default_capacity": 5}})
+
+	// So are API key metrics
+	c.do(contractCall{method: "GET", path: "/api/admin/api-metrics?from=" + today + "&to=" + today, session: adminSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/admin/api-metrics?key=api_key", session: adminSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/admin/api-metrics?from=not-a-date", session: adminSession, want: http.StatusBadRequest})
+	c.do(contractCall{method: "GET", path: "/api/admin/api-metrics", session: personSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "GET", path: "/api/admin/api-metrics", want: http.StatusUnauthorized})
+
+	// And removing anyone's capacity overrides
+	overridesPath := "/api/admin/capacity/" + person.ID() + "/overrides?from=" + today + "&to=" + today
+	c.do(contractCall{method: "DELETE", path: overridesPath, session: adminSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/admin/capacity/missing@example.com/overrides?from=" + today + "&to=" + today, session: adminSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: overridesPath, session: personSession, want: http.StatusForbidden})
+	c.do(contractCall{method: "DELETE", path: overridesPath, want: http.StatusUnauthorized})
+
+	// And the notes and links beside a heatmap
+	charter := map[string]interface{}{
+		"notes": "Standup at 09:30",
+		"links": []map[string]string{{"title": "Charter", "url": "https://wiki.example.com/charter"}},
+	}
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID() + "/info", session: adminSession, want: http.StatusOK, body: charter})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID() + "/info", session: adminSession, want: http.StatusBadRequest,
+		body: map[string]interface{}{"links": []map[string]string{{"title": "Script", "url": "javascript:alert(1)"}}}})
+	c.do(contractCall{method: "PUT", path: "/api/entities/missing-group/info", session: adminSession, want: http.StatusNotFound, body: charter})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID() + "/info", session: personSession, want: http.StatusForbidden, body: charter})
+	c.do(contractCall{method: "PUT", path: "/api/entities/" + group.ID() + "/info", want: http.StatusUnauthorized, body: charter})
+
+	c.do(contractCall{method: "POST", path: "/api/presence/entity/" + person.ID() + "?mode=editing", session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/presence/entity/" + person.ID(), want: http.StatusUnauthorized})
+	c.do(contractCall{method: "POST", path: "/api/presence/load/999999", session: personSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "POST", path: "/api/presence/widget/1", session: personSession, want: http.StatusBadRequest})
+
+	pinPath := fmt.Sprintf("/api/loads/%d/pin", int(loadID))
+	c.do(contractCall{method: "POST", path: pinPath, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "POST", path: "/api/loads/999999/pin", session: personSession, want: http.StatusNotFound})
+	c.do(contractCall{method: "POST", path: "/api/loads/abc/pin", session: personSession, want: http.StatusBadRequest})
+	c.do(contractCall{method: "POST", path: pinPath, want: http.StatusUnauthorized})
+	c.do(contractCall{method: "GET", path: "/api/my-pins", session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/my-pins", want: http.StatusUnauthorized})
+
+	// Heatmap preferences
+	c.do(contractCall{method: "GET", path: "/api/my-preferences", session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/my-preferences", want: http.StatusUnauthorized})
+	c.do(contractCall{method: "PUT", path: "/api/my-preferences", session: personSession, want: http.StatusOK,
+		body: map[string]interface{}{"week_start": "sunday"}})
+	c.do(contractCall{method: "PUT", path: "/api/my-preferences", session: personSession, want: http.StatusBadRequest,
+		body: map[string]interface{}{"week_start": "someday"}})
+	c.do(contractCall{method: "PUT", path: "/api/my-preferences", session: personSession, want: http.StatusOK,
+		body: map[string]interface{}{"week_start": nil}})
+	c.do(contractCall{method: "PUT", path: "/api/my-preferences", session: personSession, want: http.StatusOK,
+		body: map[string]interface{}{"week_start": nil, "hidden_sources": []string{}}})
+	c.do(contractCall{method: "DELETE", path: pinPath, session: personSession, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: pinPath, session: personSession, want: http.StatusNotFound})
+
+	// Entity deletion last so earlier calls can reference it
+	c.do(contractCall{method: "GET", path: "/api/entities/" + newPerson + "/delete-preview", apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "GET", path: "/api/entities/missing@example.com/delete-preview", apiKey: true, want: http.StatusNotFound})
+	c.do(contractCall{method: "DELETE", path: "/api/entities/" + newPerson, apiKey: true, want: http.StatusOK})
+	c.do(contractCall{method: "DELETE", path: "/api/entities/missing@example.com", apiKey: true, want: http.StatusNotFound})
+
+	// Every operation documented as ApiKeyAuth must reject missing keys
+	for _, route := range spec.Routes() {
+		op, _ := spec.Operation(route.Method, route.Path)
+		if !op.RequiresAPIKey() {
+			continue
+		}
+		c.do(contractCall{method: route.Method, path: samplePath(route.Path), want: http.StatusUnauthorized, invalid: true})
+	}
+
+	// Every documented operation must be exercised at least once
+	var uncovered []string
+	for _, route := range spec.Routes() {
+		if !c.covered[route.String()] {
+			uncovered = append(uncovered, route.String())
+		}
+	}
+	sort.Strings(uncovered)
+	a.Empty(uncovered, fmt.Sprintf("documented operations without contract coverage: %v", uncovered))
+}
+
+// samplePath fills path parameters with placeholder values.
+func samplePath(template string) string {
+	var out []byte
+	for i := 0; i < len(template); i++ {
+		if template[i] != '{' {
+			out = append(out, template[i])
+			continue
+		}
+		for i < len(template) && template[i] != '}' {
+			i++
+		}
+		out = append(out, "1"...)
+	}
+	return string(out)
+}
@@ -0,0 +1,108 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestCustomFields verifies that upserts keep custom fields as sent, that
+// fields defined for the source must have their type, and that day details
+// show only the defined fields marked for display, in position order.
+func TestCustomFields(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("fields-person@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	define := func(key string, field map[string]interface{}) {
+		resp, err := env.API.Call("PUT", "/api/custom-fields/gcal/"+key, field)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should define %s: %s", key, resp.String())
+	}
+	define("meeting_link", map[string]interface{}{"label": "Meeting", "type": "url", "display": true, "position": 1})
+	define("room", map[string]interface{}{"label": "Room", "type": "string", "display": true, "position": 2})
+	define("attendees", map[string]interface{}{"label": "Attendees", "type": "number"})
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(fields map[string]interface{}) *helpers.Response {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id":   "fields-load",
+			"title":         "Planning",
+			"source":        "gcal",
+			"date":          tomorrow,
+			"assignees":     []map[string]interface{}{{"email": person.ID(), "weight": 1}},
+			"custom_fields": fields,
+		})
+		a.NoError(err)
+		return resp
+	}
+
+	resp := upsert(map[string]interface{}{"attendees": "twelve"})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "defined fields must have their type: %s", resp.String())
+	a.Contains(resp.String(), "attendees must be a number")
+
+	resp = upsert(map[string]interface{}{
+		"room":         "Orchid",
+		"meeting_link": "https://meet.example.com/abc",
+		"attendees":    12,
+		"organizer":    "carol@example.com",
+	})
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err := client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+tomorrow, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "day details should load: %s", resp.String())
+
+	var details struct {
+		Loads []struct {
+			Load struct {
+				CustomFields map[string]interface{} `json:"custom_fields"`
+			} `json:"load"`
+			Fields []struct {
+				Key   string      `json:"key"`
+				Type  string      `json:"type"`
+				Value interface{} `json:"value"`
+			} `json:"fields"`
+		} `json:"loads"`
+	}
+	a.NoError(resp.JSON(&details))
+	if !a.Len(details.Loads, 1) {
+		return
+	}
+	load := details.Loads[0]
+	a.Equal("carol@example.com", load.Load.CustomFields["organizer"], "undefined fields are kept")
+	a.Equal(12.0, load.Load.CustomFields["attendees"])
+	if !a.Len(load.Fields, 2, "only fields marked for display are shown") {
+		return
+	}
+	a.Equal("meeting_link", load.Fields[0].Key)
+	a.Equal("url", load.Fields[0].Type)
+	a.Equal("room", load.Fields[1].Key)
+	a.Equal("Orchid", load.Fields[1].Value)
+
+	client = helpers.NewAPIClient(env.ServiceURL())
+	resp, err = client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+tomorrow, nil)
+	a.NoError(err)
+	a.Contains(resp.String(), `href="https://meet.example.com/abc"`, "links are shown as links")
+	a.Contains(resp.String(), "Room: ")
+	a.NotContains(resp.String(), "Attendees", "fields not marked for display are hidden")
+
+	resp, err = env.API.Call("DELETE", "/api/custom-fields/gcal/room", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	resp, err = client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+tomorrow, nil)
+	a.NoError(err)
+	a.NotContains(resp.String(), "Orchid", "undefined fields are not displayed")
+}
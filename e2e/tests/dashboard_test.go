@@ -0,0 +1,76 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestAnonymizedDashboard verifies that a group's dashboard is only served
+// once enabled for that group, shows aggregate utilization without member or
+// load details, and disappears again when disabled.
+func TestAnonymizedDashboard(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	date := time.Now().UTC().AddDate(0, 0, 3)
+	alice := fixtures.NewPerson("dash-alice@example.com").WithTitle("Alice Dashboard")
+	bob := fixtures.NewPerson("dash-bob@example.com").WithTitle("Bob Dashboard")
+	group := fixtures.NewGroup("dash-team").WithTitle("Dashboard Team").WithCapacity(10).WithMembers(alice, bob)
+	load := fixtures.NewLoad("dash-load").WithTitle("Secret Project").OnDate(date).AssignedTo(alice, 2).AssignedTo(bob, 3)
+	a.NoError(fixtures.NewScenario().Add(alice, bob, group, load).Insert(ctx, env.DB), "should seed scenario")
+
+	client := helpers.NewAPIClient(env.ServiceURL())
+	get := func(path string) *helpers.Response {
+		resp, err := client.Call("GET", path, nil)
+		a.NoError(err)
+		return resp
+	}
+
+	a.Equal(http.StatusNotFound, get("/dashboard/"+group.ID()).StatusCode, "dashboards are off by default")
+	a.Equal(http.StatusNotFound, get("/api/dashboard/"+group.ID()).StatusCode, "dashboards are off by default")
+
+	resp, err := env.API.Call("PUT", "/api/groups/"+group.ID()+"/dashboard", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "enabling should succeed: %s", resp.String())
+	var enabled struct {
+		URL string `json:"url"`
+	}
+	a.NoError(resp.JSON(&enabled))
+	a.Equal("/dashboard/"+group.ID(), enabled.URL)
+
+	resp, err = env.API.Call("PUT", "/api/groups/"+alice.ID()+"/dashboard", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "only groups have dashboards")
+
+	page := get("/dashboard/" + group.ID())
+	a.Equal(http.StatusOK, page.StatusCode)
+	a.Contains(page.String(), "Dashboard Team")
+
+	partial := get("/api/dashboard/" + group.ID())
+	a.Equal(http.StatusOK, partial.StatusCode)
+	for _, body := range []string{page.String(), partial.String()} {
+		a.Contains(body, "Utilization: 50%", "should show the day's aggregate utilization")
+		for _, hidden := range []string{alice.ID(), bob.ID(), "Alice Dashboard", "Bob Dashboard", "Secret Project", "showDayDetails"} {
+			a.NotContains(body, hidden, "dashboard should not reveal %q", hidden)
+		}
+	}
+
+	// Dashboards poll, so an unchanged heatmap is answered 304
+	client.SetHeader("If-None-Match", partial.Headers.Get("ETag"))
+	a.Equal(http.StatusNotModified, get("/api/dashboard/"+group.ID()).StatusCode)
+	client.SetHeader("If-None-Match", "")
+
+	resp, err = env.API.Call("DELETE", "/api/groups/"+group.ID()+"/dashboard", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Equal(http.StatusNotFound, get("/dashboard/"+group.ID()).StatusCode, "disabled dashboards are gone")
+}
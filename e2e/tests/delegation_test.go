@@ -0,0 +1,99 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestCapacityDelegation verifies that an assistant can manage the capacity
+// of a person who delegated it to them, that their changes are audited with
+// both identities, and that the access ends when the delegation is removed.
+func TestCapacityDelegation(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("delegating@example.com").WithTitle("Delegating Person").WithCapacity(5)
+	assistant := fixtures.NewPerson("assistant@example.com").WithTitle("Assistant").WithCapacity(5)
+	stranger := fixtures.NewPerson("stranger@example.com").WithCapacity(5)
+	a.NoError(fixtures.NewScenario().Add(person, assistant, stranger).Insert(ctx, env.DB), "should seed scenario")
+
+	login := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		client := session.Client(env.ServiceURL())
+		return client
+	}
+	personClient := login(person.ID())
+	assistantClient := login(assistant.ID())
+	strangerClient := login(stranger.ID())
+
+	call := func(client *helpers.APIClient, method, path string, body interface{}) *helpers.Response {
+		resp, err := client.Call(method, path, body)
+		a.NoError(err)
+		return resp
+	}
+	defaultCapacity := func(email string) float64 {
+		var capacity float64
+		rows, err := env.DB.Query(ctx, `SELECT default_capacity FROM load_calendar_data.entities WHERE id = $1`, email)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&capacity))
+		}
+		rows.Close()
+		return capacity
+	}
+	onBehalf := "?person=" + url.QueryEscape(person.ID())
+
+	resp := call(personClient, "POST", "/api/my-delegations", map[string]string{"assistant_email": assistant.ID()})
+	a.Equal(http.StatusOK, resp.StatusCode, "delegating should succeed: %s", resp.String())
+
+	// The assistant switches to the person from their own page
+	page := call(assistantClient, "GET", "/my-capacity", nil)
+	a.Equal(http.StatusOK, page.StatusCode)
+	a.Contains(page.String(), "Switch person")
+	a.Contains(page.String(), person.ID())
+	page = call(assistantClient, "GET", "/my-capacity"+onBehalf, nil)
+	a.Equal(http.StatusOK, page.StatusCode)
+	a.Contains(page.String(), "Delegating Person")
+
+	a.Equal(http.StatusForbidden, call(strangerClient, "GET", "/my-capacity"+onBehalf, nil).StatusCode,
+		"only assistants may manage someone else's capacity")
+	a.Equal(http.StatusForbidden, call(strangerClient, "POST", "/api/my-capacity"+onBehalf,
+		map[string]float64{"default_capacity": 1}).StatusCode)
+
+	resp = call(assistantClient, "POST", "/api/my-capacity"+onBehalf, map[string]float64{"default_capacity": 6})
+	a.Equal(http.StatusOK, resp.StatusCode, "assistant update should succeed: %s", resp.String())
+	a.Equal(6.0, defaultCapacity(person.ID()), "the person's capacity changes")
+	a.Equal(5.0, defaultCapacity(assistant.ID()), "the assistant's own capacity does not")
+
+	var entries []struct {
+		PersonEmail string `json:"person_email"`
+		ActorEmail  string `json:"actor_email"`
+		Action      string `json:"action"`
+		Detail      string `json:"detail"`
+	}
+	resp = call(personClient, "GET", "/api/my-capacity/audit", nil)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&entries))
+	if a.Len(entries, 1) {
+		a.Equal(person.ID(), entries[0].PersonEmail)
+		a.Equal(assistant.ID(), entries[0].ActorEmail, "the audit names who acted")
+		a.Equal("update", entries[0].Action)
+		a.Equal("default capacity 6.0", entries[0].Detail)
+	}
+
+	resp = call(personClient, "DELETE", "/api/my-delegations/"+assistant.ID(), nil)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Equal(http.StatusForbidden, call(assistantClient, "POST", "/api/my-capacity"+onBehalf,
+		map[string]float64{"default_capacity": 7}).StatusCode, "removed assistants lose access")
+	a.Equal(6.0, defaultCapacity(person.ID()))
+}
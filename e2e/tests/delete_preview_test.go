@@ -0,0 +1,64 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestEntityDeletePreview verifies that the delete preview counts what
+// deleting a person or a group would cascade to, and changes nothing.
+func TestEntityDeletePreview(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	person := fixtures.NewPerson("leaving@example.com").WithCapacityOn(today.AddDate(0, 0, 2), 1)
+	colleague := fixtures.NewPerson("staying@example.com")
+	group := fixtures.NewGroup("preview-team").WithMembers(person, colleague)
+	a.NoError(fixtures.NewScenario().Add(
+		person, colleague, group,
+		fixtures.NewLoad("preview-past").OnDate(today.AddDate(0, 0, -3)).AssignedTo(person, 1),
+		fixtures.NewLoad("preview-shared").OnDate(today.AddDate(0, 0, 1)).AssignedTo(person, 1).AssignedTo(colleague, 1),
+		fixtures.NewLoad("preview-solo").OnDate(today).AssignedTo(person, 2),
+	).Insert(ctx, env.DB), "should seed scenario")
+
+	preview := func(id string) map[string]interface{} {
+		resp, err := env.API.Call("GET", "/api/entities/"+id+"/delete-preview", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "preview should succeed: %s", resp.String())
+		var body map[string]interface{}
+		a.NoError(resp.JSON(&body))
+		return body
+	}
+
+	counts := preview(person.ID())
+	a.Equal("person", counts["type"])
+	a.Equal(3.0, counts["assignments"])
+	a.Equal(2.0, counts["future_loads"], "the past load is not counted")
+	a.Equal(1.0, counts["unassigned_loads"], "only the solo load loses its last assignee")
+	a.Equal(1.0, counts["memberships"])
+	a.Equal(1.0, counts["capacity_overrides"])
+	a.Equal(0.0, counts["weekly_capacity"])
+
+	counts = preview(group.ID())
+	a.Equal("group", counts["type"])
+	a.Equal(2.0, counts["memberships"], "a group's memberships are its members")
+	a.Equal(0.0, counts["assignments"])
+
+	resp, err := env.API.Call("GET", "/api/entities/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "the preview should not delete anything")
+
+	resp, err = env.API.Call("GET", "/api/entities/missing@example.com/delete-preview", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,177 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestDryRun verifies that writes sent with ?dry_run=true report what they
+// would do, with the resulting day totals, and leave everything as it was.
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	date := tomorrow.Format("2006-01-02")
+	person := fixtures.NewPerson("dry-run-person@example.com")
+	existing := fixtures.NewLoad("dry-run-existing").OnDate(tomorrow).AssignedTo(person, 2)
+	a.NoError(fixtures.NewScenario().Add(person, existing).Insert(ctx, env.DB), "should seed scenario")
+
+	type dryRunDay struct {
+		EntityID       string  `json:"entity_id"`
+		Date           string  `json:"date"`
+		LoadBefore     float64 `json:"load_before"`
+		Load           float64 `json:"load"`
+		CapacityBefore float64 `json:"capacity_before"`
+		Capacity       float64 `json:"capacity"`
+		Overloaded     bool    `json:"overloaded"`
+	}
+	type dryRunResult struct {
+		DryRun      bool        `json:"dry_run"`
+		Action      string      `json:"action"`
+		AutoCreated []string    `json:"auto_created"`
+		Days        []dryRunDay `json:"days"`
+	}
+	dryRun := func(client *helpers.APIClient, method, path string, body interface{}) dryRunResult {
+		t.Helper()
+		resp, err := client.Call(method, path, body)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "dry run should succeed: %s", resp.String())
+		var result dryRunResult
+		a.NoError(resp.JSON(&result))
+		a.True(result.DryRun)
+		return result
+	}
+	dayOf := func(result dryRunResult, entityID string) dryRunDay {
+		t.Helper()
+		for _, d := range result.Days {
+			if d.EntityID == entityID && d.Date == date {
+				return d
+			}
+		}
+		t.Fatalf("dry run reports no day %s for %s: %+v", date, entityID, result.Days)
+		return dryRunDay{}
+	}
+	loadOf := func() float64 {
+		t.Helper()
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == date {
+				return d.Load
+			}
+		}
+		t.Fatalf("heatmap has no day %s", date)
+		return 0
+	}
+
+	// A new load, with an assignee who would be created
+	newcomer := "dry-run-newcomer@example.com"
+	result := dryRun(env.API, "POST", "/api/loads/upsert?dry_run=true", map[string]interface{}{
+		"external_id": "dry-run-new",
+		"title":       "Dry Run Load",
+		"date":        date,
+		"assignees": []map[string]interface{}{
+			{"email": person.ID(), "weight": 4},
+			{"email": newcomer, "weight": 1},
+		},
+	})
+	a.Equal("create", result.Action)
+	a.Equal([]string{newcomer}, result.AutoCreated)
+	day := dayOf(result, person.ID())
+	a.Equal(2.0, day.LoadBefore)
+	a.Equal(6.0, day.Load)
+	a.Equal(5.0, day.Capacity)
+	a.True(day.Overloaded, "6 is over a capacity of 5")
+	day = dayOf(result, newcomer)
+	a.Equal(1.0, day.Load)
+	a.Equal(5.0, day.Capacity, "the newcomer would get the default capacity")
+
+	a.Equal(2.0, loadOf(), "nothing was upserted")
+	resp, err := env.API.Call("GET", "/api/entities/"+newcomer, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "nobody was created")
+
+	// Changing and deleting the load already there
+	result = dryRun(env.API, "POST", "/api/v1/loads/upsert?dry_run=true", map[string]interface{}{
+		"external_id": "dry-run-existing",
+		"title":       "Lighter",
+		"date":        date,
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 0.5}},
+	})
+	a.Equal("update", result.Action)
+	a.Equal(0.5, dayOf(result, person.ID()).Load)
+
+	result = dryRun(env.API, "DELETE", "/api/loads/by-external-id/dry-run-existing?dry_run=true", nil)
+	a.Equal("delete", result.Action)
+	a.Equal(0.0, dayOf(result, person.ID()).Load)
+	a.Equal(2.0, loadOf(), "the load is still there")
+
+	// Entities
+	result = dryRun(env.API, "PUT", "/api/entities/"+person.ID()+"?dry_run=true", map[string]interface{}{"title": "Renamed"})
+	a.Equal("update", result.Action)
+	result = dryRun(env.API, "DELETE", "/api/entities/"+person.ID()+"?dry_run=true", nil)
+	a.Equal("delete", result.Action)
+	resp, err = env.API.Call("GET", "/api/entities/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "the person is still there")
+	var entity struct {
+		Title string `json:"title"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.Equal(person.ID(), entity.Title, "and keeps their title")
+
+	// Capacity, as the person
+	personSession := fixtures.NewSession(person.ID())
+	a.NoError(personSession.Insert(ctx, env.DB), "should create session")
+	session := personSession.Client(env.ServiceURL())
+	session.SetHeader("Accept", "application/json")
+
+	// Without group owners to approve it, the lower capacity would apply at once
+	result = dryRun(session, "POST", "/api/my-capacity?dry_run=true", map[string]interface{}{"default_capacity": 1})
+	a.Equal("update", result.Action)
+	day = dayOf(result, person.ID())
+	a.Equal(5.0, day.CapacityBefore)
+	a.Equal(1.0, day.Capacity)
+	a.Equal(2.0, day.Load)
+	a.True(day.Overloaded, "the existing load is over the lower capacity")
+	resp, err = env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+	a.NoError(err)
+	var heatmap struct {
+		Days []struct {
+			Date     time.Time `json:"date"`
+			Capacity float64   `json:"capacity"`
+		} `json:"days"`
+	}
+	a.NoError(resp.JSON(&heatmap))
+	for _, d := range heatmap.Days {
+		if d.Date.Format("2006-01-02") == date {
+			a.Equal(5.0, d.Capacity, "the capacity is unchanged")
+		}
+	}
+
+	// Writes that do not support dry runs refuse them
+	resp, err = env.API.Call("POST", "/api/loads/stale/delete?dry_run=true", map[string]interface{}{"ids": []int{}})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "should refuse the dry run: %s", resp.String())
+	resp, err = env.API.Call("POST", "/api/loads/upsert?dry_run=sometimes", map[string]interface{}{})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+}
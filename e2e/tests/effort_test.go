@@ -0,0 +1,100 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestEffortCalibration verifies that assignees can record the effort they
+// actually spent on past loads and that the calibration report compares it to
+// the planned weights per source.
+func TestEffortCalibration(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("effort-person@example.com")
+	other := fixtures.NewPerson("effort-other@example.com")
+	a.NoError(fixtures.NewScenario().Add(person, other).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(person.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+	client := session.Client(env.ServiceURL())
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID, source, date string, weight float64) int {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Effort " + externalID,
+			"source":      source,
+			"date":        date,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": weight}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+		var r struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&r))
+		return r.LoadID
+	}
+	jira := upsert("effort-jira", "jira", yesterday, 2)
+	gcal := upsert("effort-gcal", "gcal", yesterday, 1)
+	future := upsert("effort-future", "jira", tomorrow, 2)
+
+	record := func(client *helpers.APIClient, loadID int, actual float64) *helpers.Response {
+		resp, err := client.Call("POST", fmt.Sprintf("/api/loads/%d/actual", loadID), map[string]float64{"actual": actual})
+		a.NoError(err)
+		return resp
+	}
+
+	resp := record(client, jira, 1)
+	a.Equal(http.StatusOK, resp.StatusCode, "recording should succeed: %s", resp.String())
+	resp = record(client, jira, 3)
+	a.Equal(http.StatusOK, resp.StatusCode, "recording again replaces the actual")
+	var recorded struct {
+		Planned float64 `json:"planned"`
+		Actual  float64 `json:"actual"`
+	}
+	a.NoError(resp.JSON(&recorded))
+	a.Equal(2.0, recorded.Planned, "the assignment weight is the planned effort")
+	a.Equal(3.0, recorded.Actual)
+	a.Equal(http.StatusOK, record(client, gcal, 1).StatusCode)
+
+	a.Equal(http.StatusBadRequest, record(client, future, 1).StatusCode, "future loads have no actual effort yet")
+	otherSession := fixtures.NewSession(other.ID())
+	a.NoError(otherSession.Insert(ctx, env.DB), "should create session")
+	otherClient := otherSession.Client(env.ServiceURL())
+	a.Equal(http.StatusNotFound, record(otherClient, jira, 1).StatusCode, "only assignees record actuals")
+
+	resp, err := env.API.Call("GET", "/api/reports/calibration", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var report struct {
+		Sources []struct {
+			Source       string  `json:"source"`
+			Samples      int     `json:"samples"`
+			PlannedTotal float64 `json:"planned_total"`
+			ActualTotal  float64 `json:"actual_total"`
+			Ratio        float64 `json:"ratio"`
+		} `json:"sources"`
+	}
+	a.NoError(resp.JSON(&report))
+	if a.Len(report.Sources, 2) {
+		a.Equal("gcal", report.Sources[0].Source)
+		a.Equal(1.0, report.Sources[0].Ratio)
+		a.Equal("jira", report.Sources[1].Source)
+		a.Equal(1, report.Sources[1].Samples)
+		a.Equal(1.5, report.Sources[1].Ratio, "jira loads took 1.5 times their weight")
+	}
+}
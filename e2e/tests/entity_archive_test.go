@@ -0,0 +1,90 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestArchiveEntity verifies that archiving hides a person from entity lists
+// without removing their loads or memberships, and that restoring brings
+// them back as they were.
+func TestArchiveEntity(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	person := fixtures.NewPerson("archive-person@example.com")
+	other := fixtures.NewPerson("archive-other@example.com")
+	team := fixtures.NewGroup("archive-team").WithMembers(person, other)
+	load := fixtures.NewLoad("archive-load").OnDate(tomorrow).AssignedTo(person, 2)
+	a.NoError(fixtures.NewScenario().Add(person, other, team, load).Insert(ctx, env.DB), "should seed scenario")
+
+	listed := func() map[string]bool {
+		resp, err := env.API.Call("GET", "/api/entities", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should list entities: %s", resp.String())
+		var entities []struct {
+			ID string `json:"id"`
+		}
+		a.NoError(resp.JSON(&entities))
+		ids := make(map[string]bool, len(entities))
+		for _, e := range entities {
+			ids[e.ID] = true
+		}
+		return ids
+	}
+	a.True(listed()[person.ID()], "an active person is listed")
+
+	resp, err := env.API.Call("POST", "/api/entities/"+person.ID()+"/archive", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should archive: %s", resp.String())
+	var entity struct {
+		ArchivedAt *time.Time `json:"archived_at"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.NotNil(entity.ArchivedAt, "the response shows when it was archived")
+
+	a.False(listed()[person.ID()], "an archived person is not listed")
+	a.True(listed()[team.ID()], "their group still is")
+
+	resp, err = env.API.Call("POST", "/api/entities/"+person.ID()+"/archive", nil)
+	a.NoError(err)
+	a.Equal(http.StatusConflict, resp.StatusCode, "already archived")
+
+	rows, err := env.DB.Query(ctx, `
+		SELECT (SELECT COUNT(*) FROM load_calendar_data.load_assignments WHERE person_email = $1),
+		       (SELECT COUNT(*) FROM load_calendar_data.group_members WHERE person_email = $1)
+	`, person.ID())
+	a.NoError(err)
+	var assignments, memberships int
+	a.True(rows.Next(), "should count")
+	a.NoError(rows.Scan(&assignments, &memberships))
+	rows.Close()
+	a.Equal(1, assignments, "archiving keeps loads")
+	a.Equal(1, memberships, "archiving keeps memberships")
+
+	resp, err = env.API.Call("POST", "/api/entities/"+person.ID()+"/restore", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should restore: %s", resp.String())
+	entity.ArchivedAt = nil
+	a.NoError(resp.JSON(&entity))
+	a.Nil(entity.ArchivedAt, "a restored person is active")
+	a.True(listed()[person.ID()], "a restored person is listed again")
+
+	resp, err = env.API.Call("POST", "/api/entities/"+person.ID()+"/restore", nil)
+	a.NoError(err)
+	a.Equal(http.StatusConflict, resp.StatusCode, "not archived")
+
+	resp, err = env.API.Call("POST", "/api/entities/missing@example.com/restore", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,106 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestEntityListConditionalGet verifies that the entity list carries an ETag
+// and answers 304 until an entity changes.
+func TestEntityListConditionalGet(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+	a.NoError(fixtures.NewPerson("listed@example.com").Insert(ctx, env.DB), "should seed person")
+
+	get := func(etag string) *helpers.Response {
+		client := helpers.NewAPIClient(env.ServiceURL())
+		if etag != "" {
+			client.SetHeader("If-None-Match", etag)
+		}
+		resp, err := client.Call("GET", "/api/entities", nil)
+		a.NoError(err)
+		return resp
+	}
+
+	first := get("")
+	a.Equal(http.StatusOK, first.StatusCode)
+	etag := first.Headers.Get("ETag")
+	a.NotEmpty(etag, "the list should carry an ETag")
+	a.Equal(http.StatusNotModified, get(etag).StatusCode, "same ETag should get 304")
+
+	a.NoError(fixtures.NewPerson("added@example.com").Insert(ctx, env.DB), "should seed another person")
+	a.Equal(http.StatusOK, get(etag).StatusCode, "a new entity should change the list")
+}
+
+// TestEntityChanges verifies that the delta endpoint lists the entities
+// created or updated since a time, and the IDs of those archived or deleted.
+func TestEntityChanges(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	kept := fixtures.NewPerson("kept@example.com")
+	leaving := fixtures.NewPerson("leaving@example.com")
+	group := fixtures.NewGroup("changes-team")
+	a.NoError(fixtures.NewScenario().Add(kept, leaving, group).Insert(ctx, env.DB), "should seed scenario")
+
+	var changes struct {
+		Changed []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"changed"`
+		Removed []string  `json:"removed"`
+		Until   time.Time `json:"until"`
+	}
+	fetch := func(since time.Time) {
+		resp, err := env.API.Call("GET", "/api/entities/changes?since="+url.QueryEscape(since.Format(time.RFC3339Nano)), nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "changes should succeed: %s", resp.String())
+		a.NoError(resp.JSON(&changes))
+	}
+
+	fetch(time.Now().Add(-time.Hour))
+	a.Len(changes.Changed, 3, "everything seeded is new")
+	a.Empty(changes.Removed)
+
+	fetch(changes.Until)
+	a.Empty(changes.Changed, "nothing changed since")
+	a.Empty(changes.Removed)
+	since := changes.Until
+
+	resp, err := env.API.Call("PUT", "/api/entities/"+kept.ID(), map[string]interface{}{"title": "Kept Renamed"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "rename should succeed: %s", resp.String())
+	resp, err = env.API.Call("POST", "/api/people/"+leaving.ID()+"/offboard", map[string]string{
+		"last_day": time.Now().Format("2006-01-02"),
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "offboard should succeed: %s", resp.String())
+	resp, err = env.API.Call("DELETE", "/api/entities/"+group.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "delete should succeed: %s", resp.String())
+
+	fetch(since)
+	a.Len(changes.Changed, 1, "only the renamed person is still listed")
+	if len(changes.Changed) == 1 {
+		a.Equal("Kept Renamed", changes.Changed[0].Title)
+	}
+	sort.Strings(changes.Removed)
+	a.Equal([]string{group.ID(), leaving.ID()}, changes.Removed, "archived and deleted entities are removed")
+
+	resp, err = env.API.Call("GET", "/api/entities/changes?since=yesterday", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+}
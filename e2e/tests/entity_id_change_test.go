@@ -0,0 +1,123 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestChangeEntityID verifies that moving a person to a new email keeps
+// their loads, memberships and sessions, and leaves the old ID unknown.
+func TestChangeEntityID(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	oldEmail := "id-change-old@example.com"
+	newEmail := "id-change-new@example.com"
+	person := fixtures.NewPerson(oldEmail).WithCapacity(4)
+	other := fixtures.NewPerson("id-change-other@example.com")
+	team := fixtures.NewGroup("id-change-team").WithMembers(person, other)
+	load := fixtures.NewLoad("id-change-load").OnDate(tomorrow).AssignedTo(person, 3)
+	a.NoError(fixtures.NewScenario().Add(person, other, team, load).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(oldEmail)
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+	_, err := env.DB.Exec(ctx, `
+		INSERT INTO load_calendar_data.capacity_change_requests (entity_id, change, reason, status, decided_by, decided_at)
+		VALUES ($1, '{}', 'reduction', 'approved', $2, NOW())
+	`, other.ID(), oldEmail)
+	a.NoError(err, "should create a request the person decided")
+
+	resp, err := env.API.Call("POST", "/api/entities/"+oldEmail+"/change-id", map[string]string{"new_id": "not-an-email"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "a person's ID must be an email")
+	resp, err = env.API.Call("POST", "/api/entities/"+oldEmail+"/change-id", map[string]string{"new_id": other.ID()})
+	a.NoError(err)
+	a.Equal(http.StatusConflict, resp.StatusCode, "the new ID is taken")
+
+	resp, err = env.API.Call("POST", "/api/entities/"+oldEmail+"/change-id", map[string]string{"new_id": newEmail})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should change the ID: %s", resp.String())
+	var result struct {
+		OldID    string `json:"old_id"`
+		NewID    string `json:"new_id"`
+		Type     string `json:"type"`
+		Sessions int    `json:"sessions"`
+	}
+	a.NoError(resp.JSON(&result))
+	a.Equal(oldEmail, result.OldID)
+	a.Equal(newEmail, result.NewID)
+	a.Equal("person", result.Type)
+	a.Equal(1, result.Sessions)
+	var decider string
+	rows, err := env.DB.Query(ctx, `
+		SELECT decided_by FROM load_calendar_data.capacity_change_requests WHERE entity_id = $1
+	`, other.ID())
+	a.NoError(err)
+	if rows.Next() {
+		a.NoError(rows.Scan(&decider))
+	}
+	rows.Close()
+	a.Equal(newEmail, decider, "the approval names the new ID")
+
+	resp, err = env.API.Call("GET", "/api/entities/"+oldEmail, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "the old ID is gone")
+	resp, err = env.API.Call("GET", "/api/entities/"+newEmail, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "the person has the new ID")
+	var entity struct {
+		DefaultCapacity float64 `json:"default_capacity"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.Equal(4.0, entity.DefaultCapacity)
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+newEmail+"/json", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+	var heatmap struct {
+		Days []struct {
+			Date time.Time `json:"date"`
+			Load float64   `json:"load"`
+		} `json:"days"`
+	}
+	a.NoError(resp.JSON(&heatmap))
+	found := false
+	for _, d := range heatmap.Days {
+		if d.Date.Format("2006-01-02") == tomorrow.Format("2006-01-02") {
+			a.Equal(3.0, d.Load, "the load moved with the person")
+			found = true
+		}
+	}
+	a.True(found, "heatmap should have tomorrow")
+
+	resp, err = env.API.Call("GET", "/api/groups/"+team.ID()+"/members", nil)
+	a.NoError(err)
+	a.Contains(resp.String(), newEmail, "the membership moved with the person")
+	a.NotContains(resp.String(), oldEmail)
+
+	resp, err = session.Client(env.ServiceURL()).Call("GET", "/api/my-loads/unacknowledged", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "the login is kept: %s", resp.String())
+	var pending []struct {
+		LoadID int `json:"load_id"`
+	}
+	a.NoError(resp.JSON(&pending))
+	if a.Len(pending, 1) {
+		a.Equal(load.ID(), pending[0].LoadID, "and sees the person's loads")
+	}
+
+	resp, err = env.API.Call("GET", "/api/entities/changes?since=2000-01-01T00:00:00Z", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), oldEmail, "the old ID is reported as deleted")
+}
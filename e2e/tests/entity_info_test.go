@@ -0,0 +1,87 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestEntityInfo verifies that admins keep notes and links on an entity,
+// shown beside its heatmap, and that nobody else may change them.
+func TestEntityInfo(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	lead := fixtures.NewPerson("entity-info-lead@example.com")
+	team := fixtures.NewGroup("entity-info-team").WithMembers(lead)
+	a.NoError(fixtures.NewScenario().Add(lead, team).Insert(ctx, env.DB), "should seed scenario")
+
+	client := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		c := session.Client(env.ServiceURL())
+		return c
+	}
+	user, admin := client(lead.ID()), client(testenv.AdminEmail)
+
+	page := func(c *helpers.APIClient) string {
+		t.Helper()
+		resp, err := c.Call("GET", "/?entity="+team.ID(), nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		return resp.String()
+	}
+	a.NotContains(page(user), `id="entity-info"`, "no panel while there is nothing to show")
+	a.Contains(page(admin), "Nothing noted yet.", "admins get the panel to fill in")
+
+	resp, err := admin.Call("PUT", "/api/entities/"+team.ID()+"/info", map[string]interface{}{
+		"notes": "  Ask in #platform before paging.  ",
+		"links": []map[string]string{
+			{"title": "Team charter", "url": "https://wiki.example.com/charter"},
+			{"title": "On-call schedule", "url": "https://oncall.example.com/platform"},
+		},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should save: %s", resp.String())
+	var info struct {
+		Notes string `json:"notes"`
+		Links []struct {
+			Title string `json:"title"`
+			URL   string `json:"url"`
+		} `json:"links"`
+		UpdatedBy string `json:"updated_by"`
+	}
+	a.NoError(resp.JSON(&info))
+	a.Equal("Ask in #platform before paging.", info.Notes)
+	a.Len(info.Links, 2)
+	a.Equal(testenv.AdminEmail, info.UpdatedBy)
+
+	html := page(user)
+	a.Contains(html, "Ask in #platform before paging.")
+	a.Contains(html, `href="https://wiki.example.com/charter"`)
+	a.Contains(html, "On-call schedule")
+	a.NotContains(html, "saveEntityInfo", "only admins can edit")
+
+	resp, err = user.Call("PUT", "/api/entities/"+team.ID()+"/info", map[string]interface{}{"notes": "Mine now"})
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode)
+	resp, err = admin.Call("PUT", "/api/entities/"+team.ID()+"/info", map[string]interface{}{
+		"links": []map[string]string{{"title": "Script", "url": "javascript:alert(1)"}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "only http and https links")
+
+	// Saving replaces everything
+	resp, err = admin.Call("PUT", "/api/entities/"+team.ID()+"/info", map[string]interface{}{"notes": ""})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NotContains(page(user), `id="entity-info"`, "the panel goes once emptied")
+}
@@ -0,0 +1,87 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestDomainEvents verifies that load and group writes are recorded in the
+// domain event log in order, against the entities they changed, that the log
+// can be paged and filtered, and that its rows cannot be changed.
+func TestDomainEvents(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("audited@example.com")
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+	group := fixtures.NewGroup("audited-team")
+	a.NoError(group.Insert(ctx, env.DB), "should seed group")
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "audited-load",
+		"title":       "Audited load",
+		"date":        time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02"),
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 2}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	resp, err = env.API.Call("POST", "/api/groups/"+group.ID()+"/members", map[string]string{"person_email": person.ID()})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "member should be added: %s", resp.String())
+	resp, err = env.API.Call("DELETE", "/api/loads/by-external-id/audited-load", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "load should be deleted: %s", resp.String())
+
+	type event struct {
+		ID        int64                  `json:"id"`
+		Type      string                 `json:"type"`
+		EntityIDs []string               `json:"entity_ids"`
+		Payload   map[string]interface{} `json:"payload"`
+	}
+	list := func(query string) []event {
+		resp, err := env.API.Call("GET", "/api/events"+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "events should be listed: %s", resp.String())
+		var events []event
+		a.NoError(resp.JSON(&events))
+		return events
+	}
+
+	events := list("")
+	if a.Len(events, 3, "one event per write") {
+		a.Equal("load.upserted", events[0].Type)
+		a.Equal([]string{person.ID()}, events[0].EntityIDs)
+		load, _ := events[0].Payload["load"].(map[string]interface{})
+		a.Equal("Audited load", load["title"], "the payload holds the upserted load")
+		a.Equal("group.member_added", events[1].Type)
+		a.Equal([]string{group.ID(), person.ID()}, events[1].EntityIDs)
+		a.Equal("load.deleted", events[2].Type)
+		a.True(events[0].ID < events[1].ID && events[1].ID < events[2].ID, "events are listed oldest first")
+
+		a.Len(list(fmt.Sprintf("?after=%d", events[0].ID)), 2, "after skips earlier events")
+		a.Len(list("?limit=1"), 1)
+		if filtered := list("?entity=" + group.ID()); a.Len(filtered, 1, "only the membership changed the group") {
+			a.Equal("group.member_added", filtered[0].Type)
+		}
+	}
+
+	resp, err = env.API.Call("GET", "/api/events?after=first", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	_, err = env.DB.Exec(ctx, `UPDATE load_calendar_data.domain_events SET type = 'load.forged'`)
+	a.Error(err, "events cannot be changed")
+	_, err = env.DB.Exec(ctx, `DELETE FROM load_calendar_data.domain_events`)
+	a.Error(err, "events cannot be deleted")
+	a.Len(list(""), 3)
+}
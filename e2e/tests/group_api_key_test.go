@@ -0,0 +1,80 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestGroupScopedAPIKey verifies that an API key restricted to a group can
+// write loads, blackouts and memberships for that group alone, and is kept
+// off routes that do not check its groups.
+func TestGroupScopedAPIKey(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	member := fixtures.NewPerson("scoped-member@example.com").WithCapacity(5)
+	outsider := fixtures.NewPerson("scoped-outsider@example.com").WithCapacity(5)
+	team := fixtures.NewGroup(testenv.GroupAPIKeyGroup).WithMembers(member)
+	other := fixtures.NewGroup("scoped-other-team").WithMembers(outsider)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	const theirsID = "scoped-theirs"
+	theirs := fixtures.NewLoad(theirsID).OnDate(tomorrow).AssignedTo(outsider, 1)
+	a.NoError(fixtures.NewScenario().Add(member, outsider, team, other, theirs).Insert(ctx, env.DB), "should seed scenario")
+
+	scoped := helpers.NewAPIClient(env.ServiceURL())
+	scoped.SetHeader("x-api-key", testenv.GroupAPIKey)
+	call := func(method, path string, body interface{}, want int, msg string) {
+		t.Helper()
+		resp, err := scoped.Call(method, "/api/v1"+path, body)
+		a.NoError(err)
+		a.Equal(want, resp.StatusCode, "%s: %s", msg, resp.String())
+	}
+	upsert := func(externalID, email string) map[string]interface{} {
+		return map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Scoped " + externalID,
+			"source":      "team-bot",
+			"date":        tomorrow.Format("2006-01-02"),
+			"assignees":   []map[string]interface{}{{"email": email, "weight": 1}},
+		}
+	}
+
+	call("POST", "/loads/upsert", upsert("scoped-ours", member.ID()), http.StatusOK, "members' loads are in scope")
+	call("POST", "/loads/upsert", upsert("scoped-new", outsider.ID()), http.StatusForbidden, "others' loads are not")
+	call("POST", "/loads/upsert", upsert(theirsID, member.ID()), http.StatusForbidden, "another team's load cannot be taken over")
+	call("DELETE", "/loads/by-external-id/"+url.PathEscape(theirsID), nil, http.StatusForbidden, "nor deleted")
+	call("DELETE", "/loads/by-external-id/scoped-ours", nil, http.StatusOK, "its own loads can be deleted")
+
+	var count int
+	a.NoError(env.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM load_calendar_data.loads WHERE external_id = $1
+	`, theirsID).Scan(&count))
+	a.Equal(1, count, "the other team's load is untouched")
+
+	blackout := map[string]string{"start_date": tomorrow.Format("2006-01-02"), "reason": "Offsite"}
+	call("POST", "/entities/"+member.ID()+"/blackouts", blackout, http.StatusCreated, "members' blackouts are in scope")
+	call("POST", "/entities/"+outsider.ID()+"/blackouts", blackout, http.StatusForbidden, "others' are not")
+
+	call("POST", "/entities", map[string]string{"id": "scoped-new-team", "title": "New Team", "type": "group"}, http.StatusForbidden, "groups cannot be created")
+	call("POST", "/entities", map[string]string{"id": "scoped-hire@example.com", "title": "New Hire", "type": "person"}, http.StatusCreated, "persons can be created")
+	call("POST", "/loads/upsert", upsert("scoped-hire", "scoped-hire@example.com"), http.StatusForbidden, "until they join the group")
+	call("POST", "/groups/"+team.ID()+"/members", map[string]string{"person_email": "scoped-hire@example.com"}, http.StatusOK, "its own group's members can be managed")
+	call("POST", "/loads/upsert", upsert("scoped-hire", "scoped-hire@example.com"), http.StatusOK, "new members' loads are in scope")
+	call("POST", "/groups/"+other.ID()+"/members", map[string]string{"person_email": "scoped-hire@example.com"}, http.StatusForbidden, "other groups' members cannot")
+	call("PUT", "/entities/"+outsider.ID(), map[string]string{"title": "Renamed"}, http.StatusForbidden, "other teams' people cannot be updated")
+	call("PUT", "/groups/"+other.ID()+"/dashboard", nil, http.StatusForbidden, "other groups' dashboards cannot be enabled")
+
+	call("POST", "/scenarios", map[string]string{"name": "Scoped scenario"}, http.StatusForbidden, "routes that do not check groups are closed")
+	call("GET", "/groups/"+other.ID()+"/members", nil, http.StatusOK, "reads are not restricted")
+}
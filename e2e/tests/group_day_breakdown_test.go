@@ -0,0 +1,77 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestGroupDayBreakdown verifies that a group's day can be expanded into each
+// member's load against their own capacity, fullest first.
+func TestGroupDayBreakdown(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	date := tomorrow.Format("2006-01-02")
+	busy := fixtures.NewPerson("breakdown-busy@example.com").WithCapacity(4)
+	calm := fixtures.NewPerson("breakdown-calm@example.com").WithCapacity(5)
+	idle := fixtures.NewPerson("breakdown-idle@example.com").WithCapacity(3)
+	team := fixtures.NewGroup("breakdown-team").WithMembers(busy, calm, idle)
+	release := fixtures.NewLoad("breakdown-release").OnDate(tomorrow).AssignedTo(busy, 3).AssignedTo(calm, 1)
+	review := fixtures.NewLoad("breakdown-review").OnDate(tomorrow).AssignedTo(busy, 2)
+	a.NoError(fixtures.NewScenario().Add(busy, calm, idle, team, release, review).Insert(ctx, env.DB), "should seed scenario")
+
+	path := "/api/heatmap/" + team.ID() + "/day/" + date + "/members"
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err := client.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get the breakdown: %s", resp.String())
+	var breakdown struct {
+		GroupID string `json:"group_id"`
+		Date    string `json:"date"`
+		Members []struct {
+			PersonEmail string  `json:"person_email"`
+			Load        float64 `json:"load"`
+			Capacity    float64 `json:"capacity"`
+			Overloaded  bool    `json:"overloaded"`
+		} `json:"members"`
+	}
+	a.NoError(resp.JSON(&breakdown))
+	a.Equal(team.ID(), breakdown.GroupID)
+	a.Equal(date, breakdown.Date)
+	if a.Len(breakdown.Members, 3) {
+		a.Equal(busy.ID(), breakdown.Members[0].PersonEmail, "fullest first")
+		a.Equal(5.0, breakdown.Members[0].Load)
+		a.Equal(4.0, breakdown.Members[0].Capacity)
+		a.True(breakdown.Members[0].Overloaded)
+		a.Equal(calm.ID(), breakdown.Members[1].PersonEmail)
+		a.Equal(1.0, breakdown.Members[1].Load)
+		a.False(breakdown.Members[1].Overloaded)
+		a.Equal(idle.ID(), breakdown.Members[2].PersonEmail)
+		a.Equal(0.0, breakdown.Members[2].Load)
+	}
+
+	resp, err = env.API.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.True(strings.Contains(resp.String(), "overloaded"), "the HTML marks the busy member")
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+team.ID()+"/day/"+date, nil)
+	a.NoError(err)
+	a.True(strings.Contains(resp.String(), "/day/"+date+"/members"), "group day details link the breakdown")
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+busy.ID()+"/day/"+date+"/members", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "persons have no members")
+}
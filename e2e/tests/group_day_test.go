@@ -0,0 +1,91 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestGroupDayByMember verifies that a group's day details group its loads
+// per member, with each member's subtotal against their own capacity,
+// fullest first, and that persons' day details are not grouped.
+func TestGroupDayByMember(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	busy := fixtures.NewPerson("busy@example.com").WithCapacity(2)
+	steady := fixtures.NewPerson("steady@example.com").WithCapacity(5)
+	idle := fixtures.NewPerson("idle@example.com").WithCapacity(4)
+	group := fixtures.NewGroup("day-team").WithMembers(busy, steady, idle)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	a.NoError(fixtures.NewScenario().Add(
+		busy, steady, idle, group,
+		fixtures.NewLoad("group-day-shared").WithTitle("Shared").OnDate(tomorrow).AssignedTo(busy, 2).AssignedTo(steady, 1),
+		fixtures.NewLoad("group-day-solo").WithTitle("Solo").OnDate(tomorrow).AssignedTo(busy, 1),
+	).Insert(ctx, env.DB), "should seed scenario")
+
+	path := "/api/heatmap/" + group.ID() + "/day/" + tomorrow.Format("2006-01-02")
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err := client.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "day details should load: %s", resp.String())
+
+	var details struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Load struct {
+				Title string `json:"title"`
+			} `json:"load"`
+		} `json:"loads"`
+		Members []struct {
+			PersonEmail string  `json:"person_email"`
+			Load        float64 `json:"load"`
+			Capacity    float64 `json:"capacity"`
+			Loads       []struct {
+				Load struct {
+					Title string `json:"title"`
+				} `json:"load"`
+				Assignments []struct {
+					PersonEmail string  `json:"person_email"`
+					Weight      float64 `json:"weight"`
+				} `json:"assignments"`
+			} `json:"loads"`
+		} `json:"members"`
+	}
+	a.NoError(resp.JSON(&details))
+	a.Equal(4.0, details.TotalLoad)
+	a.Len(details.Loads, 2, "the flat list is kept")
+	if a.Len(details.Members, 3, "every member has a section") {
+		a.Equal(busy.ID(), details.Members[0].PersonEmail, "the overloaded member comes first")
+		a.Equal(3.0, details.Members[0].Load)
+		a.Equal(2.0, details.Members[0].Capacity)
+		a.Len(details.Members[0].Loads, 2)
+		a.Equal(steady.ID(), details.Members[1].PersonEmail)
+		a.Equal(1.0, details.Members[1].Load)
+		if a.Len(details.Members[1].Loads, 1) && a.Len(details.Members[1].Loads[0].Assignments, 1) {
+			a.Equal(steady.ID(), details.Members[1].Loads[0].Assignments[0].PersonEmail, "only the member's own assignment")
+		}
+		a.Equal(idle.ID(), details.Members[2].PersonEmail)
+		a.Equal(0.0, details.Members[2].Load)
+		a.Equal(4.0, details.Members[2].Capacity)
+	}
+
+	resp, err = env.API.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "<details", "members are collapsible sections")
+	a.Contains(resp.String(), "3.0 / 2.0", "the member's subtotal against their capacity")
+
+	resp, err = client.Call("GET", "/api/heatmap/"+busy.ID()+"/day/"+tomorrow.Format("2006-01-02"), nil)
+	a.NoError(err)
+	a.NotContains(resp.String(), `"members"`, "a person's day is not grouped")
+}
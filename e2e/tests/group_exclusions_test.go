@@ -0,0 +1,105 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestGroupHeatmapExclusions verifies that a group heatmap leaves out the
+// members listed in ?exclude= and those the group excludes, on its day
+// details too, without touching their own heatmaps.
+func TestGroupHeatmapExclusions(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	lead := fixtures.NewPerson("exclusions-lead@example.com")
+	dev := fixtures.NewPerson("exclusions-dev@example.com")
+	team := fixtures.NewGroup("exclusions-team").WithCapacity(10).WithMembers(lead, dev)
+	meetings := fixtures.NewLoad("exclusions-meetings").OnDate(tomorrow).AssignedTo(lead, 4)
+	work := fixtures.NewLoad("exclusions-work").OnDate(tomorrow).AssignedTo(dev, 1)
+	a.NoError(fixtures.NewScenario().Add(lead, dev, team, meetings, work).Insert(ctx, env.DB), "should seed scenario")
+
+	loadOf := func(path string) float64 {
+		t.Helper()
+		resp, err := env.API.Call("GET", path, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == tomorrow.Format("2006-01-02") {
+				return d.Load
+			}
+		}
+		t.Fatalf("heatmap has no day %s", tomorrow.Format("2006-01-02"))
+		return 0
+	}
+	teamPath := "/api/heatmap/" + team.ID() + "/json"
+
+	reader := helpers.NewAPIClient(env.ServiceURL())
+	reader.SetHeader("Accept", "application/json")
+	type dayDetails struct {
+		TotalLoad float64 `json:"total_load"`
+		Members   []struct {
+			PersonEmail string `json:"person_email"`
+		} `json:"members"`
+	}
+	detailsOf := func(query string) dayDetails {
+		t.Helper()
+		resp, err := reader.Call("GET", "/api/heatmap/"+team.ID()+"/day/"+tomorrow.Format("2006-01-02")+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get day details: %s", resp.String())
+		var details dayDetails
+		a.NoError(resp.JSON(&details))
+		return details
+	}
+
+	a.Equal(5.0, loadOf(teamPath), "everyone counts by default")
+	a.Equal(1.0, loadOf(teamPath+"?exclude=Exclusions-Lead@example.com"), "the lead is left out of this request")
+	a.Equal(5.0, loadOf(teamPath), "and only this request")
+	details := detailsOf("?exclude=" + lead.ID())
+	a.Equal(1.0, details.TotalLoad, "day details leave the lead out the same way")
+	if a.Len(details.Members, 1) {
+		a.Equal(dev.ID(), details.Members[0].PersonEmail, "and list no section for them")
+	}
+
+	resp, err := env.API.Call("PUT", "/api/groups/"+team.ID()+"/heatmap-exclusions/"+lead.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "lead should be excluded: %s", resp.String())
+	resp, err = env.API.Call("PUT", "/api/groups/"+team.ID()+"/heatmap-exclusions/stranger@example.com", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "only members can be excluded")
+
+	resp, err = env.API.Call("GET", "/api/groups/"+team.ID()+"/heatmap-exclusions", nil)
+	a.NoError(err)
+	var exclusions struct {
+		Members []string `json:"members"`
+	}
+	a.NoError(resp.JSON(&exclusions))
+	a.Equal([]string{lead.ID()}, exclusions.Members)
+
+	a.Equal(1.0, loadOf(teamPath), "the group leaves the lead out")
+	a.Equal(1.0, detailsOf("").TotalLoad, "and so do its day details")
+	a.Equal(0.0, loadOf(teamPath+"?exclude="+dev.ID()), "on top of its own exclusions")
+	a.Equal(4.0, loadOf("/api/heatmap/"+lead.ID()+"/json"), "the lead's own heatmap is unchanged")
+
+	resp, err = env.API.Call("DELETE", "/api/groups/"+team.ID()+"/heatmap-exclusions/"+lead.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "lead should be included again: %s", resp.String())
+	a.Equal(5.0, loadOf(teamPath), "the lead counts again")
+}
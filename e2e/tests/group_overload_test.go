@@ -0,0 +1,102 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// TestGroupOverloadAlert verifies that an upsert pushing a group's summed
+// load over the group's capacity sends a group overload alert listing the
+// members with load that day, even when no member is overloaded alone.
+func TestGroupOverloadAlert(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	receiver := testenv.StartWebhookReceiver()
+	defer receiver.Close()
+
+	alice := fixtures.NewPerson("group-alert-alice@example.com").WithCapacity(5)
+	bob := fixtures.NewPerson("group-alert-bob@example.com").WithCapacity(5)
+	idle := fixtures.NewPerson("group-alert-idle@example.com").WithCapacity(5)
+	team := fixtures.NewGroup("group-alert-team").WithCapacity(3).WithMembers(alice, bob, idle)
+	a.NoError(fixtures.NewScenario().Add(alice, bob, idle, team).Insert(ctx, env.DB), "should seed scenario")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	resp, err := env.API.Call("POST", "/api/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"group_overload_alert"},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "create should return 201: %s", resp.String())
+	var endpoint struct {
+		ID int `json:"id"`
+	}
+	a.NoError(resp.JSON(&endpoint))
+	defer func() {
+		_, _ = env.API.Call("DELETE", "/api/webhooks/"+strconv.Itoa(endpoint.ID), nil)
+	}()
+
+	upsert := func(externalID string, assignees ...map[string]interface{}) {
+		t.Helper()
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Group alert " + externalID,
+			"date":        tomorrow,
+			"assignees":   assignees,
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should return 200: %s", resp.String())
+	}
+	alerts := func() []models.WebhookGroupOverloadPayload {
+		var found []models.WebhookGroupOverloadPayload
+		for _, req := range receiver.Requests() {
+			var alert models.WebhookGroupOverloadPayload
+			if json.Unmarshal(req.Body, &alert) == nil && alert.Event == models.WebhookEventGroupOverload {
+				found = append(found, alert)
+			}
+		}
+		return found
+	}
+
+	// Within the group's capacity: no alert
+	upsert("group-alert-1", map[string]interface{}{"email": alice.ID(), "weight": 2})
+	time.Sleep(500 * time.Millisecond)
+	a.Len(alerts(), 0, "a group within capacity should not be alerted")
+
+	// Over it, though each member is within their own
+	upsert("group-alert-2", map[string]interface{}{"email": bob.ID(), "weight": 2})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(alerts()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	got := alerts()
+	a.Len(got, 1, "the overloaded group should be alerted once")
+	if len(got) == 0 {
+		return
+	}
+	alert := got[0]
+	a.Equal(team.ID(), alert.GroupID)
+	a.Equal(tomorrow, alert.Date)
+	a.Equal(4.0, alert.Load)
+	a.Equal(3.0, alert.Capacity)
+	a.Len(alert.Members, 2, "only members with load contribute: %v", alert.Members)
+	emails := []string{}
+	for _, m := range alert.Members {
+		emails = append(emails, m.Email)
+	}
+	a.Equal([]string{alice.ID(), bob.ID()}, emails, "equally loaded members are listed by email")
+	a.Len(receiver.Alerts(), 0, "no member is overloaded alone")
+}
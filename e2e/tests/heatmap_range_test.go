@@ -0,0 +1,66 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestHeatmapRange verifies that heatmaps cover the days chosen with from,
+// to and days, and refuse ranges that are invalid or too long.
+func TestHeatmapRange(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	farAhead := today.AddDate(0, 10, 0)
+	person := fixtures.NewPerson("heatmap-range@example.com")
+	later := fixtures.NewLoad("heatmap-range-later").OnDate(farAhead).AssignedTo(person, 2)
+	a.NoError(fixtures.NewScenario().Add(person, later).Insert(ctx, env.DB), "should seed scenario")
+
+	type heatmapDay struct {
+		Date time.Time `json:"date"`
+		Load float64   `json:"load"`
+	}
+	days := func(query string) []heatmapDay {
+		t.Helper()
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json"+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []heatmapDay `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		return heatmap.Days
+	}
+
+	sprint := days("?days=14")
+	if a.Len(sprint, 14) {
+		a.Equal(today.Format("2006-01-02"), sprint[0].Date.Format("2006-01-02"), "days count from today")
+	}
+
+	from := today.AddDate(0, 9, 0).Format("2006-01-02")
+	to := today.AddDate(0, 11, 0).Format("2006-01-02")
+	found := false
+	for _, d := range days("?from=" + from + "&to=" + to) {
+		if d.Date.Format("2006-01-02") == farAhead.Format("2006-01-02") {
+			a.Equal(2.0, d.Load, "loads past the default window show in a chosen range")
+			found = true
+		}
+	}
+	a.True(found, "the range should include the load's day")
+
+	for _, query := range []string{"?days=367", "?from=" + to + "&to=" + from, "?days=7&to=" + to, "?from=soon"} {
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusBadRequest, resp.StatusCode, "%s should be refused", query)
+	}
+}
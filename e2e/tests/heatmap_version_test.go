@@ -0,0 +1,102 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestHeatmapConditionalGet verifies that the heatmap partial answers 304 to
+// clients that already have the current version, and that writes, deletions,
+// and loads moved out of the window all produce a new version for the person
+// and their group.
+func TestHeatmapConditionalGet(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("polled@example.com")
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+	group := fixtures.NewGroup("polled-team").WithMembers(person)
+	a.NoError(group.Insert(ctx, env.DB), "should seed group")
+
+	// get fetches a heatmap with a fresh client, so conditional headers do not
+	// leak into other requests
+	get := func(entity, header, value string) *helpers.Response {
+		client := helpers.NewAPIClient(env.ServiceURL())
+		if header != "" {
+			client.SetHeader(header, value)
+		}
+		resp, err := client.Call("GET", "/api/heatmap/"+entity, nil)
+		a.NoError(err)
+		return resp
+	}
+	versions := func() (string, string) {
+		p, g := get(person.ID(), "", ""), get(group.ID(), "", "")
+		a.Equal(http.StatusOK, p.StatusCode, "person heatmap: %s", p.String())
+		a.Equal(http.StatusOK, g.StatusCode, "group heatmap: %s", g.String())
+		return p.Headers.Get("ETag"), g.Headers.Get("ETag")
+	}
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "polled-load",
+		"title":       "Polled load",
+		"date":        time.Now().AddDate(0, 0, 3).Format("2006-01-02"),
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 2}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	var created struct {
+		LoadID int `json:"load_id"`
+	}
+	a.NoError(resp.JSON(&created))
+
+	first := get(person.ID(), "", "")
+	a.Equal(http.StatusOK, first.StatusCode)
+	etag, lastModified := first.Headers.Get("ETag"), first.Headers.Get("Last-Modified")
+	a.NotEmpty(etag, "heatmap should carry an ETag")
+	a.NotEmpty(lastModified, "heatmap should carry Last-Modified")
+
+	unchanged := get(person.ID(), "If-None-Match", etag)
+	a.Equal(http.StatusNotModified, unchanged.StatusCode, "same ETag should get 304")
+	a.Empty(unchanged.Body, "304 should have no body")
+	a.Equal(http.StatusNotModified, get(person.ID(), "If-Modified-Since", lastModified).StatusCode,
+		"same Last-Modified should get 304")
+
+	personTag, groupTag := versions()
+	a.Equal(etag, personTag, "version should be stable without writes")
+
+	// Removing the only assignee deletes rows, which must still change the version
+	resp, err = env.API.Call("DELETE", fmt.Sprintf("/api/loads/%d/assignees/%s", created.LoadID, person.ID()), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "remove assignee should succeed: %s", resp.String())
+
+	afterDelete, groupAfterDelete := versions()
+	a.NotEqual(personTag, afterDelete, "deleting an assignment should change the person's version")
+	a.NotEqual(groupTag, groupAfterDelete, "deleting a member's assignment should change the group's version")
+	a.Equal(http.StatusOK, get(person.ID(), "If-None-Match", personTag).StatusCode, "stale ETag should get the heatmap")
+
+	// Moving a load out of the window bypasses the API and removes it from the
+	// range the version covers
+	resp, err = env.API.Call("POST", fmt.Sprintf("/api/loads/%d/assignees", created.LoadID), map[string]interface{}{
+		"assignees": []map[string]interface{}{{"email": person.ID()}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "add assignee should succeed: %s", resp.String())
+
+	beforeMove, _ := versions()
+	a.NotEqual(afterDelete, beforeMove, "adding an assignment should change the version")
+
+	_, err = env.DB.Exec(ctx, `UPDATE load_calendar_data.loads SET date = date + 400 WHERE id = $1`, created.LoadID)
+	a.NoError(err)
+	afterMove, _ := versions()
+	a.NotEqual(beforeMove, afterMove, "moving a load out of the window should change the version")
+}
@@ -0,0 +1,106 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestHiddenSourcesPreference verifies that a logged-in user can leave load
+// sources out of the heatmaps they view, without changing what anyone else
+// sees.
+func TestHiddenSourcesPreference(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	viewer := fixtures.NewPerson("fyi-viewer@example.com")
+	a.NoError(viewer.Insert(ctx, env.DB), "should seed person")
+
+	session := fixtures.NewSession(viewer.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+
+	client := func(loggedIn bool) *helpers.APIClient {
+		if loggedIn {
+			return session.Client(env.ServiceURL())
+		}
+		return helpers.NewAPIClient(env.ServiceURL())
+	}
+
+	date := time.Now().UTC().AddDate(0, 0, 3).Format("2006-01-02")
+	for _, load := range []struct {
+		id, source string
+		weight     float64
+	}{{"fyi-standup", "gcal", 1}, {"fyi-ticket", "jira", 2}} {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": load.id, "title": load.id, "source": load.source, "date": date,
+			"assignees": []map[string]interface{}{{"email": viewer.ID(), "weight": load.weight}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+
+	loadOn := func(session bool) (float64, string) {
+		resp, err := client(session).Call("GET", "/api/heatmap/"+viewer.ID()+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == date {
+				return d.Load, resp.Headers.Get("ETag")
+			}
+		}
+		return 0, resp.Headers.Get("ETag")
+	}
+
+	load, _ := loadOn(true)
+	a.Equal(3.0, load, "every source counts by default")
+
+	resp, err := client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"hidden_sources": []string{"gcal", " gcal", ""}})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "update should succeed: %s", resp.String())
+	var prefs struct {
+		WeekStart     string   `json:"week_start"`
+		HiddenSources []string `json:"hidden_sources"`
+	}
+	a.NoError(resp.JSON(&prefs))
+	a.Equal([]string{"gcal"}, prefs.HiddenSources, "sources are trimmed and deduplicated")
+
+	mine, mineTag := loadOn(true)
+	a.Equal(2.0, mine, "hidden sources are left out of the viewer's heatmap")
+	theirs, theirTag := loadOn(false)
+	a.Equal(3.0, theirs, "other viewers still see every source")
+	a.NotEqual(theirTag, mineTag, "heatmaps with hidden sources are tagged apart")
+
+	grid, err := client(true).Call("GET", "/api/heatmap/"+viewer.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, grid.StatusCode)
+	shared, err := client(false).Call("GET", "/api/heatmap/"+viewer.ID(), nil)
+	a.NoError(err)
+	a.NotEqual(shared.String(), grid.String(), "the viewer's grid is rendered for them alone")
+
+	resp, err = client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"week_start": "sunday"})
+	a.NoError(err)
+	a.NoError(resp.JSON(&prefs))
+	a.Equal([]string{"gcal"}, prefs.HiddenSources, "omitting hidden_sources leaves them unchanged")
+
+	resp, err = client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"week_start": nil, "hidden_sources": []string{}})
+	a.NoError(err)
+	a.NoError(resp.JSON(&prefs))
+	a.Len(prefs.HiddenSources, 0)
+	load, _ = loadOn(true)
+	a.Equal(3.0, load, "an empty list shows every source again")
+}
@@ -0,0 +1,167 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestHolidays verifies that holidays imported from an iCalendar file take
+// the capacity of persons in their region, on their heatmaps, day details,
+// group member breakdowns and rankings, are marked on their heatmaps, and give it back when
+// deleted.
+func TestHolidays(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	local := fixtures.NewPerson("holiday-local@example.com").WithCapacity(5)
+	abroad := fixtures.NewPerson("holiday-abroad@example.com").WithCapacity(5)
+	group := fixtures.NewGroup("holiday-team").WithCapacity(10).WithMembers(local, abroad)
+	a.NoError(fixtures.NewScenario().Add(local, abroad, group).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("PUT", "/api/entities/"+local.ID(), map[string]interface{}{"region": " id "})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should set the region: %s", resp.String())
+	var entity struct {
+		Region string `json:"region"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.Equal("ID", entity.Region, "regions are uppercased")
+
+	holiday := time.Now().UTC().AddDate(0, 0, 5)
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:" + holiday.Format("20060102") + "\r\n" +
+		"DTEND;VALUE=DATE:" + holiday.AddDate(0, 0, 1).Format("20060102") + "\r\n" +
+		"SUMMARY:Hari Raya Nyepi\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	req, err := http.NewRequest("POST", env.ServiceURL()+"/api/holidays/id/import", strings.NewReader(ics))
+	a.NoError(err)
+	req.Header.Set("Content-Type", "text/calendar")
+	req.Header.Set("x-api-key", env.Config.Service.APIKey)
+	httpResp, err := http.DefaultClient.Do(req)
+	a.NoError(err)
+	body, _ := io.ReadAll(httpResp.Body)
+	_ = httpResp.Body.Close()
+	a.Equal(http.StatusOK, httpResp.StatusCode, "should import the calendar: %s", body)
+	a.Contains(string(body), `"imported":1`)
+
+	resp, err = env.API.Call("GET", "/api/holidays/ID?year="+strconv.Itoa(holiday.Year()), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var holidays []struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+	a.NoError(resp.JSON(&holidays))
+	a.Len(holidays, 1)
+	a.Equal("Hari Raya Nyepi", holidays[0].Name)
+
+	type heatmapDay struct {
+		Date     time.Time `json:"date"`
+		Capacity float64   `json:"capacity"`
+		Holiday  string    `json:"holiday"`
+	}
+	reader := helpers.NewAPIClient(env.ServiceURL())
+	reader.SetHeader("Accept", "application/json")
+	dayOf := func(entityID string) heatmapDay {
+		resp, err := reader.Call("GET", "/api/heatmap/"+entityID+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []heatmapDay `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == holiday.Format("2006-01-02") {
+				return d
+			}
+		}
+		t.Fatalf("heatmap of %s has no day %s", entityID, holiday.Format("2006-01-02"))
+		return heatmapDay{}
+	}
+
+	day := dayOf(local.ID())
+	a.Equal(0.0, day.Capacity, "a holiday in the person's region has no capacity")
+	a.Equal("Hari Raya Nyepi", day.Holiday)
+	day = dayOf(abroad.ID())
+	a.Equal(5.0, day.Capacity, "persons outside the region keep their capacity")
+	a.Equal("", day.Holiday)
+
+	var details struct {
+		Capacity float64 `json:"capacity"`
+		Members  []struct {
+			PersonEmail string  `json:"person_email"`
+			Capacity    float64 `json:"capacity"`
+		} `json:"members"`
+	}
+	resp, err = reader.Call("GET", "/api/heatmap/"+local.ID()+"/day/"+holiday.Format("2006-01-02"), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get day details: %s", resp.String())
+	a.NoError(resp.JSON(&details))
+	a.Equal(0.0, details.Capacity, "day details have no capacity on the holiday either")
+
+	resp, err = reader.Call("GET", "/api/heatmap/"+group.ID()+"/day/"+holiday.Format("2006-01-02"), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get group day details: %s", resp.String())
+	a.NoError(resp.JSON(&details))
+	memberCapacities := make(map[string]float64)
+	for _, m := range details.Members {
+		memberCapacities[m.PersonEmail] = m.Capacity
+	}
+	a.Equal(map[string]float64{local.ID(): 0, abroad.ID(): 5}, memberCapacities,
+		"each member's section has their own holiday capacity")
+
+	resp, err = env.API.Call("GET", "/api/groups/"+group.ID()+"/least-loaded?date="+holiday.Format("2006-01-02"), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should rank members: %s", resp.String())
+	var ranking struct {
+		Members []struct {
+			Email    string  `json:"email"`
+			Capacity float64 `json:"capacity"`
+		} `json:"members"`
+	}
+	a.NoError(resp.JSON(&ranking))
+	memberCapacities = make(map[string]float64)
+	for _, m := range ranking.Members {
+		memberCapacities[m.Email] = m.Capacity
+	}
+	a.Equal(map[string]float64{local.ID(): 0, abroad.ID(): 5}, memberCapacities,
+		"members on holiday have nothing left to take")
+
+	resp, err = reader.Call("GET", "/api/heatmap/"+group.ID()+"/day/"+holiday.Format("2006-01-02")+"/members", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get the member breakdown: %s", resp.String())
+	var breakdown struct {
+		Members []struct {
+			PersonEmail string  `json:"person_email"`
+			Capacity    float64 `json:"capacity"`
+		} `json:"members"`
+	}
+	a.NoError(resp.JSON(&breakdown))
+	memberCapacities = make(map[string]float64)
+	for _, m := range breakdown.Members {
+		memberCapacities[m.PersonEmail] = m.Capacity
+	}
+	a.Equal(map[string]float64{local.ID(): 0, abroad.ID(): 5}, memberCapacities,
+		"the breakdown shows each member's heatmap capacity")
+
+	resp, err = env.API.Call("DELETE", "/api/holidays/ID/"+holiday.Format("2006-01-02"), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should delete the holiday: %s", resp.String())
+	day = dayOf(local.ID())
+	a.Equal(5.0, day.Capacity, "deleting the holiday gives the capacity back")
+	a.Equal("", day.Holiday)
+}
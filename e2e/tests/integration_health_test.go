@@ -0,0 +1,111 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestIntegrationHealth verifies that the integration health report shows
+// each source's syncs and failed upserts, and webhook deliveries.
+func TestIntegrationHealth(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("health@example.com").WithCapacity(2)
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID, source, title string) *helpers.Response {
+		t.Helper()
+		resp, err := env.API.Call("POST", "/api/v1/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       title,
+			"source":      source,
+			"date":        tomorrow,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+		})
+		a.NoError(err, "upsert request should succeed")
+		return resp
+	}
+
+	a.Equal(http.StatusOK, upsert("health-ok-1", "health-ok", "Synced").StatusCode)
+	a.Equal(http.StatusBadRequest, upsert("health-bad-1", "health-bad", "").StatusCode, "missing title should fail")
+	a.Equal(http.StatusBadRequest, upsert("health-bad-2", "health-bad", "").StatusCode, "missing title should fail")
+
+	if env.Webhooks != nil {
+		_, err := env.Webhooks.WaitForAlert(person.ID(), tomorrow, 10*time.Second)
+		a.NoError(err, "the overload should be alerted")
+		// The delivery is recorded once the receiver has answered
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	var report struct {
+		Sources []struct {
+			Source       string     `json:"source"`
+			LastSyncAt   *time.Time `json:"last_sync_at"`
+			LoadsSynced  int        `json:"loads_synced_24h"`
+			Errors       int        `json:"errors_24h"`
+			RecentErrors []struct {
+				Operation string `json:"operation"`
+				Status    int    `json:"status"`
+				Message   string `json:"message"`
+			} `json:"recent_errors"`
+		} `json:"sources"`
+		Webhooks []struct {
+			Event       string  `json:"event"`
+			Delivered   int     `json:"delivered"`
+			SuccessRate float64 `json:"success_rate"`
+		} `json:"webhooks"`
+	}
+	client := helpers.NewAPIClient(env.ServiceURL())
+	resp, err := client.Call("GET", "/api/integrations/health", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "report should load: %s", resp.String())
+	a.NoError(resp.JSON(&report), "report should be JSON: %s", resp.String())
+
+	a.Len(report.Sources, 2, "one entry per source")
+	if len(report.Sources) == 2 {
+		bad, ok := report.Sources[0], report.Sources[1]
+		a.Equal("health-bad", bad.Source, "sources with errors come first")
+		a.Nil(bad.LastSyncAt, "a source that never synced has no sync time")
+		a.Equal(2, bad.Errors)
+		a.Len(bad.RecentErrors, 2)
+		if len(bad.RecentErrors) > 0 {
+			a.Equal("/api/v1/loads/upsert", bad.RecentErrors[0].Operation)
+			a.Equal(http.StatusBadRequest, bad.RecentErrors[0].Status)
+			a.Contains(bad.RecentErrors[0].Message, "Title")
+		}
+
+		a.Equal("health-ok", ok.Source)
+		a.NotNil(ok.LastSyncAt, "a synced source has a sync time")
+		a.Equal(1, ok.LoadsSynced)
+		a.Equal(0, ok.Errors)
+	}
+
+	if env.Webhooks != nil {
+		found := false
+		for _, w := range report.Webhooks {
+			if w.Event == "overload_alert" {
+				found = true
+				a.True(w.Delivered >= 1, "the alert should be counted as delivered")
+				a.Equal(1.0, w.SuccessRate)
+			}
+		}
+		a.True(found, "overload alerts should be reported")
+	}
+
+	page, err := client.Call("GET", "/integrations", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, page.StatusCode)
+	a.Contains(page.String(), "Integration Health")
+	a.Contains(page.String(), "health-bad")
+}
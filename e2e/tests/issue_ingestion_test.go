@@ -0,0 +1,87 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestLoadFromIssue verifies that Jira-style issue webhooks upsert a load on
+// the issue's due date weighted by its story points, and delete it once the
+// issue is done.
+func TestLoadFromIssue(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	dev := fixtures.NewPerson("issue-dev@example.com")
+	a.NoError(dev.Insert(ctx, env.DB), "should seed person")
+
+	due := time.Now().AddDate(0, 0, 4).Format("2006-01-02")
+	hook := func(event, status string) map[string]interface{} {
+		return map[string]interface{}{
+			"webhookEvent": event,
+			"issue": map[string]interface{}{
+				"key":  "SPRINT-42",
+				"self": "https://acme.atlassian.net/rest/api/2/issue/10042",
+				"fields": map[string]interface{}{
+					"summary":           "Ship the export",
+					"assignee":          map[string]interface{}{"emailAddress": dev.ID()},
+					"duedate":           due,
+					"status":            map[string]interface{}{"statusCategory": map[string]interface{}{"key": status}},
+					"customfield_10016": 3,
+				},
+			},
+		}
+	}
+	var result struct {
+		ExternalID string `json:"external_id"`
+		Action     string `json:"action"`
+		Reason     string `json:"reason"`
+	}
+
+	resp, err := env.API.Call("POST", "/api/loads/from-issue", hook("jira:issue_created", "new"))
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "issue should be ingested: %s", resp.String())
+	a.NoError(resp.JSON(&result))
+	a.Equal("SPRINT-42", result.ExternalID)
+	a.Equal("upserted", result.Action)
+
+	var title, source, url, date string
+	var weight float64
+	err = env.Pool.QueryRow(ctx, `
+		SELECT l.title, l.source, l.url, l.date::text, la.weight
+		FROM load_calendar_data.loads l
+		JOIN load_calendar_data.load_assignments la ON la.load_id = l.id
+		WHERE l.external_id = 'SPRINT-42' AND la.person_email = $1`, dev.ID()).Scan(&title, &source, &url, &date, &weight)
+	a.NoError(err, "the issue should have a load")
+	a.Equal("SPRINT-42: Ship the export", title)
+	a.Equal("jira", source)
+	a.Equal("https://acme.atlassian.net/browse/SPRINT-42", url)
+	a.Equal(due, date)
+	a.Equal(3.0, weight, "one weight per story point")
+
+	resp, err = env.API.Call("POST", "/api/loads/from-issue", hook("jira:issue_updated", "done"))
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "done issue should be handled: %s", resp.String())
+	a.NoError(resp.JSON(&result))
+	a.Equal("deleted", result.Action)
+	a.Equal("issue done", result.Reason)
+
+	var count int
+	a.NoError(env.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM load_calendar_data.loads WHERE external_id = 'SPRINT-42'`).Scan(&count))
+	a.Equal(0, count, "a done issue's load is deleted")
+
+	resp, err = env.API.Call("POST", "/api/loads/from-issue", hook("jira:issue_deleted", "done"))
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&result))
+	a.Equal("ignored", result.Action, "nothing left to delete")
+}
@@ -0,0 +1,146 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+type jobStatus struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	Errors    []struct {
+		Item  int    `json:"item"`
+		ID    string `json:"id"`
+		Error string `json:"error"`
+	} `json:"errors"`
+}
+
+// awaitJob polls the job a bulk request answered 202 with until it finishes
+func awaitJob(a *helpers.Assert, resp *helpers.Response) jobStatus {
+	var job jobStatus
+	if !a.Equal(http.StatusAccepted, resp.StatusCode, "job should be queued: %s", resp.String()) {
+		return job
+	}
+	location := resp.Headers.Get("Location")
+	a.NoError(resp.JSON(&job))
+	a.Equal("/api/jobs/"+strconv.FormatInt(job.ID, 10), location, "Location points at the job")
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := env.API.Call("GET", location, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "job should be readable: %s", resp.String())
+		a.NoError(resp.JSON(&job))
+		if job.Status == "succeeded" || job.Status == "failed" {
+			return job
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	a.True(false, "job %d did not finish: %+v", job.ID, job)
+	return job
+}
+
+// TestBulkJobs verifies that bulk upserts, reassignments and snapshot
+// backfills run as background jobs that report progress and per-item
+// failures.
+func TestBulkJobs(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	leaver := fixtures.NewPerson("leaver@example.com")
+	successor := fixtures.NewPerson("successor@example.com")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	past := fixtures.NewLoad("job-past").OnDate(tomorrow.AddDate(0, 0, -30)).AssignedTo(leaver, 1)
+	shared := fixtures.NewLoad("job-shared").OnDate(tomorrow).AssignedTo(leaver, 1).AssignedTo(successor, 0.5)
+	a.NoError(fixtures.NewScenario().Add(leaver, successor, past, shared).Insert(ctx, env.DB), "should seed scenario")
+
+	count := func(query string, args ...interface{}) int {
+		var n int
+		rows, err := env.DB.Query(ctx, query, args...)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&n))
+		}
+		rows.Close()
+		return n
+	}
+
+	// Bulk upsert: one good load, one invalid, one tombstone for nothing
+	resp, err := env.API.Call("POST", "/api/loads/bulk-upsert", map[string]interface{}{
+		"loads": []map[string]interface{}{
+			{
+				"external_id": "job-bulk-1",
+				"title":       "Bulk load",
+				"date":        tomorrow.Format("2006-01-02"),
+				"assignees":   []map[string]interface{}{{"email": leaver.ID(), "weight": 2}},
+			},
+			{"external_id": "job-bulk-2", "title": "No date"},
+			{"external_id": "job-bulk-missing", "deleted": true},
+		},
+	})
+	a.NoError(err)
+	job := awaitJob(a, resp)
+	a.Equal("bulk_upsert", job.Kind)
+	a.Equal("succeeded", job.Status, "failed items do not fail the job")
+	a.Equal(3, job.Total)
+	a.Equal(3, job.Processed)
+	a.Equal(2, job.Failed)
+	if a.Len(job.Errors, 2) {
+		a.Equal(1, job.Errors[0].Item)
+		a.Equal("job-bulk-2", job.Errors[0].ID)
+		a.Equal("job-bulk-missing", job.Errors[1].ID)
+	}
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.loads WHERE external_id = 'job-bulk-1'`), "the good load is stored")
+
+	// Reassign the leaver's current loads; the past one stays
+	resp, err = env.API.Call("POST", "/api/loads/reassign", map[string]string{
+		"from": leaver.ID(),
+		"to":   successor.ID(),
+	})
+	a.NoError(err)
+	job = awaitJob(a, resp)
+	a.Equal("reassign", job.Kind)
+	a.Equal("succeeded", job.Status)
+	a.Equal(2, job.Total, "the shared and bulk loads")
+	a.Equal(0, job.Failed)
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.load_assignments WHERE person_email = $1`, leaver.ID()),
+		"only the past load is left")
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.load_assignments la
+		JOIN load_calendar_data.loads l ON l.id = la.load_id
+		WHERE l.external_id = 'job-shared' AND la.person_email = $1 AND la.weight = 1.5`, successor.ID()),
+		"shared weights are added together")
+
+	resp, err = env.API.Call("POST", "/api/loads/reassign", map[string]string{"from": leaver.ID(), "to": "nobody@example.com"})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "unknown successor: %s", resp.String())
+
+	// Backfill: the unknown entity fails on its own
+	resp, err = env.API.Call("POST", "/api/snapshots/backfill", map[string]interface{}{
+		"entity_ids": []string{successor.ID(), "ghost@example.com"},
+	})
+	a.NoError(err)
+	job = awaitJob(a, resp)
+	a.Equal("snapshot_backfill", job.Kind)
+	a.Equal(2, job.Processed)
+	if a.Equal(1, job.Failed) && a.Len(job.Errors, 1) {
+		a.Equal("ghost@example.com", job.Errors[0].ID)
+	}
+
+	resp, err = env.API.Call("GET", "/api/jobs/999999", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
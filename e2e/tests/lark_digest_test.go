@@ -0,0 +1,71 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestLarkDigestSettings verifies that a group's weekly Lark digest can be
+// set up, paused and removed, and that a group-scoped API key may only set
+// up its own group's.
+func TestLarkDigestSettings(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	member := fixtures.NewPerson("digest-member@example.com")
+	team := fixtures.NewGroup(testenv.GroupAPIKeyGroup).WithMembers(member)
+	other := fixtures.NewGroup("digest-other-team")
+	a.NoError(fixtures.NewScenario().Add(member, team, other).Insert(ctx, env.DB), "should seed scenario")
+
+	path := "/api/groups/" + team.ID() + "/lark-digest"
+	var digest struct {
+		GroupID string `json:"group_id"`
+		ChatID  string `json:"chat_id"`
+		Enabled bool   `json:"enabled"`
+	}
+
+	resp, err := env.API.Call("PUT", path, map[string]interface{}{"chat_id": " oc_team "})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should set up the digest: %s", resp.String())
+	a.NoError(resp.JSON(&digest))
+	a.Equal(team.ID(), digest.GroupID)
+	a.Equal("oc_team", digest.ChatID)
+	a.True(digest.Enabled, "digests start enabled")
+
+	resp, err = env.API.Call("PUT", path, map[string]interface{}{"chat_id": "oc_team", "enabled": false})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	resp, err = env.API.Call("GET", path, nil)
+	a.NoError(err)
+	a.NoError(resp.JSON(&digest))
+	a.False(digest.Enabled, "digests can be paused")
+
+	resp, err = env.API.Call("PUT", "/api/groups/"+member.ID()+"/lark-digest", map[string]interface{}{"chat_id": "oc_person"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "only groups get digests")
+
+	scoped := helpers.NewAPIClient(env.ServiceURL())
+	scoped.SetHeader("x-api-key", testenv.GroupAPIKey)
+	resp, err = scoped.Call("PUT", "/api/v1"+path, map[string]interface{}{"chat_id": "oc_scoped"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "a scoped key may set up its own group's digest: %s", resp.String())
+	resp, err = scoped.Call("PUT", "/api/v1/groups/"+other.ID()+"/lark-digest", map[string]interface{}{"chat_id": "oc_scoped"})
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode, "but not another group's")
+
+	resp, err = env.API.Call("DELETE", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	resp, err = env.API.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,70 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestLeastLoaded verifies that a group's members are ranked by what they
+// would have left after a new load, with those it would overload last.
+func TestLeastLoaded(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	date := tomorrow.Format("2006-01-02")
+	busy := fixtures.NewPerson("least-loaded-busy@example.com").WithCapacity(5)
+	free := fixtures.NewPerson("least-loaded-free@example.com").WithCapacity(5)
+	small := fixtures.NewPerson("least-loaded-small@example.com").WithCapacity(2)
+	team := fixtures.NewGroup("least-loaded-team").WithMembers(busy, free, small)
+	meeting := fixtures.NewLoad("least-loaded-meeting").OnDate(tomorrow).AssignedTo(busy, 4)
+	a.NoError(fixtures.NewScenario().Add(busy, free, small, team, meeting).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("GET", "/api/groups/"+team.ID()+"/least-loaded?date="+date+"&weight=2", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should rank members: %s", resp.String())
+	var ranked struct {
+		GroupID string  `json:"group_id"`
+		Date    string  `json:"date"`
+		Weight  float64 `json:"weight"`
+		Members []struct {
+			Email      string  `json:"email"`
+			Load       float64 `json:"load"`
+			Remaining  float64 `json:"remaining"`
+			Overloaded bool    `json:"overloaded"`
+		} `json:"members"`
+	}
+	a.NoError(resp.JSON(&ranked))
+	a.Equal(team.ID(), ranked.GroupID)
+	a.Equal(date, ranked.Date)
+	a.Equal(2.0, ranked.Weight)
+	a.Len(ranked.Members, 3)
+	a.Equal(free.ID(), ranked.Members[0].Email)
+	a.Equal(3.0, ranked.Members[0].Remaining)
+	a.Equal(small.ID(), ranked.Members[1].Email)
+	a.Equal(0.0, ranked.Members[1].Remaining, "the weight exactly fills their day")
+	a.False(ranked.Members[1].Overloaded)
+	a.Equal(busy.ID(), ranked.Members[2].Email)
+	a.Equal(4.0, ranked.Members[2].Load)
+	a.Equal(-1.0, ranked.Members[2].Remaining)
+	a.True(ranked.Members[2].Overloaded, "the meeting leaves no room for the new load")
+
+	resp, err = env.API.Call("GET", "/api/groups/"+team.ID()+"/least-loaded?date="+date, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&ranked))
+	a.Equal(1.0, ranked.Weight, "the weight defaults to 1")
+
+	resp, err = env.API.Call("GET", "/api/groups/"+team.ID()+"/least-loaded?weight=-1", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "negative weights are refused")
+}
@@ -0,0 +1,78 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestNotificationLinks verifies that overload alerts carry signed links to
+// the person's day and settings, that the links open without a session, and
+// that altered links are refused.
+func TestNotificationLinks(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	if env.Webhooks == nil {
+		t.Skip("service is not wired to a webhook receiver")
+	}
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("linked@example.com").WithTitle("Linked Person").WithCapacity(2)
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "linked-overload",
+		"title":       "Linked Overload",
+		"date":        tomorrow,
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+	})
+	a.NoError(err, "upsert should succeed")
+	a.Equal(200, resp.StatusCode, "upsert should return 200: %s", resp.String())
+
+	alert, err := env.Webhooks.WaitForAlert(person.ID(), tomorrow, 10*time.Second)
+	a.NoError(err, "overloaded person should trigger an alert")
+	if alert == nil || alert.Links == nil {
+		t.Fatal("alert should include signed links")
+	}
+	a.Contains(alert.Links.Day, "/links/day/"+person.ID()+"/"+tomorrow+"?")
+	a.Contains(alert.Links.Settings, "/links/settings/"+person.ID()+"?")
+
+	// No session and no API key
+	anonymous := helpers.NewAPIClient(env.ServiceURL())
+
+	day, err := anonymous.Call("GET", alert.Links.Day, nil)
+	a.NoError(err, "day link request should succeed")
+	a.Equal(200, day.StatusCode, "day link should open: %s", day.String())
+	a.Contains(day.String(), "Linked Overload")
+	a.Contains(day.String(), "Read-only view")
+	a.Equal("no-referrer", day.Headers.Get("Referrer-Policy"), "the signed URL should not leak to load links")
+
+	settings, err := anonymous.Call("GET", alert.Links.Settings, nil)
+	a.NoError(err, "settings link request should succeed")
+	a.Equal(200, settings.StatusCode, "settings link should open: %s", settings.String())
+	a.Contains(settings.String(), "Linked Person")
+
+	// Pointing a link at another day or person breaks its signature
+	otherDay := strings.Replace(alert.Links.Day, tomorrow, time.Now().AddDate(0, 0, 2).Format("2006-01-02"), 1)
+	resp, err = anonymous.Call("GET", otherDay, nil)
+	a.NoError(err, "request should succeed")
+	a.Equal(403, resp.StatusCode, "link for another day should be refused")
+
+	otherPerson := strings.Replace(alert.Links.Settings, person.ID(), "someone@example.com", 1)
+	resp, err = anonymous.Call("GET", otherPerson, nil)
+	a.NoError(err, "request should succeed")
+	a.Equal(403, resp.StatusCode, "link for another person should be refused")
+
+	resp, err = anonymous.Call("GET", "/links/settings/"+person.ID(), nil)
+	a.NoError(err, "request should succeed")
+	a.Equal(403, resp.StatusCode, "unsigned link should be refused")
+}
@@ -0,0 +1,109 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestListLoads verifies that loads can be read back through the API,
+// filtered by date range, source, assignee and group, and paged with the
+// returned cursor.
+func TestListLoads(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	alice := fixtures.NewPerson("list-alice@example.com")
+	a.NoError(alice.Insert(ctx, env.DB), "should seed alice")
+	bob := fixtures.NewPerson("list-bob@example.com")
+	a.NoError(bob.Insert(ctx, env.DB), "should seed bob")
+	group := fixtures.NewGroup("list-team")
+	a.NoError(group.Insert(ctx, env.DB), "should seed group")
+	_, err := env.DB.Exec(ctx,
+		`INSERT INTO load_calendar_data.group_members (group_id, person_email) VALUES ($1, $2)`,
+		group.ID(), alice.ID())
+	a.NoError(err, "should add alice to the group")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format("2006-01-02") }
+	upsert := func(externalID, source string, offset int, assignee string) {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       externalID,
+			"source":      source,
+			"date":        day(offset),
+			"assignees":   []map[string]interface{}{{"email": assignee, "weight": 1}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+	upsert("list-1", "jira", 1, alice.ID())
+	upsert("list-2", "gcal", 2, bob.ID())
+	upsert("list-3", "jira", 3, bob.ID())
+	upsert("list-far", "jira", 60, alice.ID())
+
+	type page struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Loads []struct {
+			Load struct {
+				ExternalID string `json:"external_id"`
+			} `json:"load"`
+			Assignments []struct {
+				PersonEmail string `json:"person_email"`
+			} `json:"assignments"`
+		} `json:"loads"`
+		NextCursor string `json:"next_cursor"`
+	}
+	list := func(query url.Values) page {
+		resp, err := env.API.Call("GET", "/api/loads?"+query.Encode(), nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "loads should be listed: %s", resp.String())
+		var p page
+		a.NoError(resp.JSON(&p))
+		return p
+	}
+	ids := func(p page) []string {
+		out := []string{}
+		for _, l := range p.Loads {
+			out = append(out, l.Load.ExternalID)
+		}
+		return out
+	}
+
+	all := list(url.Values{})
+	a.Equal(day(0), all.From, "the range starts today by default")
+	a.Equal(day(30), all.To, "and spans 30 days")
+	a.Equal([]string{"list-1", "list-2", "list-3"}, ids(all), "loads outside the range are left out")
+	if a.Len(all.Loads, 3) {
+		a.Equal(alice.ID(), all.Loads[0].Assignments[0].PersonEmail, "loads come with their assignees")
+	}
+	a.Empty(all.NextCursor, "a single page has no cursor")
+
+	a.Equal([]string{"list-1", "list-3"}, ids(list(url.Values{"source": {"jira"}})))
+	a.Equal([]string{"list-2", "list-3"}, ids(list(url.Values{"assignee": {bob.ID()}})))
+	a.Equal([]string{"list-1", "list-far"}, ids(list(url.Values{"group": {group.ID()}, "to": {day(90)}})))
+	a.Equal([]string{"list-2"}, ids(list(url.Values{"from": {day(2)}, "to": {day(2)}})))
+
+	first := list(url.Values{"limit": {"2"}})
+	a.Equal([]string{"list-1", "list-2"}, ids(first))
+	a.NotEmpty(first.NextCursor, "more loads follow")
+	second := list(url.Values{"limit": {"2"}, "cursor": {first.NextCursor}})
+	a.Equal([]string{"list-3"}, ids(second), "the cursor continues after the first page")
+	a.Empty(second.NextCursor)
+
+	for _, query := range []string{"from=tomorrow", "from=" + day(5) + "&to=" + day(1), "cursor=bogus", "limit=0"} {
+		resp, err := env.API.Call("GET", "/api/loads?"+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusBadRequest, resp.StatusCode, "%s should be rejected", query)
+	}
+}
@@ -0,0 +1,127 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestDeleteLoadByExternalID verifies that source systems can propagate
+// deletions, by the delete endpoint or an upsert tombstone, and that each
+// assignee's capacity is re-checked by webhook.
+func TestDeleteLoadByExternalID(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+	if env.Webhooks != nil {
+		env.Webhooks.Reset()
+	}
+
+	person := fixtures.NewPerson("deletion-person@example.com").WithCapacity(2)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID string, weight float64) {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Deletion " + externalID,
+			"date":        tomorrow,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": weight}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+	dayLoad := func() float64 {
+		client := helpers.NewAPIClient(env.ServiceURL())
+		client.SetHeader("Accept", "application/json")
+		resp, err := client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+tomorrow, nil)
+		a.NoError(err)
+		var details struct {
+			TotalLoad float64 `json:"total_load"`
+		}
+		a.NoError(resp.JSON(&details))
+		return details.TotalLoad
+	}
+
+	// Calendar event IDs may hold characters that need escaping
+	calendarID := "gcal/evt 1@example.com"
+	upsert(calendarID, 2)
+	upsert("jira-DEL-1", 1)
+	a.Equal(3.0, dayLoad())
+
+	resp, err := env.API.Call("DELETE", "/api/v1/loads/by-external-id/"+url.PathEscape(calendarID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "delete should succeed: %s", resp.String())
+	var deleted struct {
+		ExternalID string   `json:"external_id"`
+		Date       string   `json:"date"`
+		Assignees  []string `json:"assignees"`
+	}
+	a.NoError(resp.JSON(&deleted))
+	a.Equal(calendarID, deleted.ExternalID)
+	a.Equal(tomorrow, deleted.Date)
+	a.Equal([]string{person.ID()}, deleted.Assignees)
+	a.Equal(1.0, dayLoad(), "the deleted load no longer counts")
+
+	resp, err = env.API.Call("DELETE", "/api/v1/loads/by-external-id/"+url.PathEscape(calendarID), nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "deleting again finds nothing")
+
+	// A tombstone through the upsert endpoint deletes the same way
+	resp, err = env.API.Call("POST", "/api/v1/loads/upsert", map[string]interface{}{
+		"external_id": "jira-DEL-1",
+		"deleted":     true,
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "tombstone should succeed: %s", resp.String())
+	a.Equal(0.0, dayLoad())
+
+	resp, err = env.API.Call("POST", "/api/v1/loads/upsert", map[string]interface{}{"deleted": true})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "tombstones need an external_id")
+
+	if env.Webhooks == nil {
+		return
+	}
+	type notice struct {
+		Event       string  `json:"event"`
+		PersonEmail string  `json:"person_email"`
+		ExternalID  string  `json:"external_id"`
+		Load        float64 `json:"load"`
+		Capacity    float64 `json:"capacity"`
+		Overloaded  bool    `json:"overloaded"`
+	}
+	notices := func() map[string]notice {
+		found := make(map[string]notice)
+		for _, req := range env.Webhooks.Requests() {
+			var n notice
+			if json.Unmarshal(req.Body, &n) == nil && n.Event == "load_deleted" {
+				found[n.ExternalID] = n
+			}
+		}
+		return found
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(notices()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	sent := notices()
+	if n, ok := sent[calendarID]; a.True(ok, "deletion should be reported") {
+		a.Equal(person.ID(), n.PersonEmail)
+		a.Equal(1.0, n.Load, "the remaining load")
+		a.Equal(2.0, n.Capacity)
+		a.False(n.Overloaded, "the person is back within capacity")
+	}
+	_, ok := sent["jira-DEL-1"]
+	a.True(ok, "tombstones are reported too")
+}
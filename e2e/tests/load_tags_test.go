@@ -0,0 +1,122 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestLoadTags verifies that upserted tags are kept with a load, that
+// heatmaps and day details can be filtered by tag, and that day details break
+// the loads down per tag.
+func TestLoadTags(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("tagged-person@example.com").WithCapacity(5)
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	date := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	for _, load := range []struct {
+		id     string
+		tags   []string
+		weight float64
+	}{
+		{"tagged-standup", []string{"Meeting", " meeting "}, 1},
+		{"tagged-feature", []string{"project"}, 2},
+		{"tagged-errand", nil, 0.5},
+	} {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": load.id, "title": load.id, "date": date, "tags": load.tags,
+			"assignees": []map[string]interface{}{{"email": person.ID(), "weight": load.weight}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+
+	type dayDetails struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Load struct {
+				ExternalID string   `json:"external_id"`
+				Tags       []string `json:"tags"`
+			} `json:"load"`
+		} `json:"loads"`
+		Tags []struct {
+			Tag   string  `json:"tag"`
+			Load  float64 `json:"load"`
+			Color string  `json:"color"`
+		} `json:"tags"`
+	}
+	reader := helpers.NewAPIClient(env.ServiceURL())
+	reader.SetHeader("Accept", "application/json")
+	getDay := func(query string) dayDetails {
+		resp, err := reader.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+date+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get day details: %s", resp.String())
+		var day dayDetails
+		a.NoError(resp.JSON(&day))
+		return day
+	}
+
+	day := getDay("")
+	a.Len(day.Loads, 3)
+	a.Equal(3.5, day.TotalLoad)
+	for _, l := range day.Loads {
+		if l.Load.ExternalID == "tagged-standup" {
+			a.Equal([]string{"meeting"}, l.Load.Tags, "tags are lowercased and deduplicated")
+		}
+	}
+	a.Len(day.Tags, 3, "a breakdown per tag, and the untagged loads")
+	a.Equal("project", day.Tags[0].Tag, "heaviest tag first")
+	a.Equal(2.0, day.Tags[0].Load)
+	a.Equal("meeting", day.Tags[1].Tag)
+	a.Equal("", day.Tags[2].Tag, "untagged loads last")
+	a.Equal(0.5, day.Tags[2].Load)
+	a.NotEqual("", day.Tags[0].Color)
+
+	day = getDay("?tag=Meeting")
+	a.Len(day.Loads, 1, "only loads with the tag are listed")
+	a.Equal("tagged-standup", day.Loads[0].Load.ExternalID)
+	a.Equal(1.0, day.TotalLoad, "and totaled")
+
+	loadOn := func(query string) float64 {
+		resp, err := reader.Call("GET", "/api/heatmap/"+person.ID()+"/json"+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == date {
+				return d.Load
+			}
+		}
+		t.Fatalf("heatmap has no day %s", date)
+		return 0
+	}
+	a.Equal(3.5, loadOn(""))
+	a.Equal(2.0, loadOn("?tag=project"), "a heatmap filtered by tag only counts its loads")
+	a.Equal(3.5, loadOn(""), "the unfiltered heatmap is unchanged")
+
+	// Tags are replaced on upsert
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "tagged-feature", "title": "tagged-feature", "date": date,
+		"assignees": []map[string]interface{}{{"email": person.ID(), "weight": 2}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Equal(0.0, loadOn("?tag=project"), "upserting without tags clears them")
+}
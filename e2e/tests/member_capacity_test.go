@@ -0,0 +1,89 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestMemberCapacityGroup verifies that a group summing its members'
+// capacities follows their leave, holidays and exclusions, and goes back to
+// its own capacity when switched to static.
+func TestMemberCapacityGroup(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	later := tomorrow.AddDate(0, 0, 1)
+	lead := fixtures.NewPerson("member-capacity-lead@example.com").WithCapacity(5)
+	dev := fixtures.NewPerson("member-capacity-dev@example.com").WithCapacity(3).OnLeave(tomorrow, tomorrow)
+	team := fixtures.NewGroup("member-capacity-team").WithCapacity(10).WithMembers(lead, dev)
+	a.NoError(fixtures.NewScenario().Add(lead, dev, team).Insert(ctx, env.DB), "should seed scenario")
+
+	capacityOn := func(path string, date time.Time) float64 {
+		t.Helper()
+		resp, err := env.API.Call("GET", path, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []struct {
+				Date     time.Time `json:"date"`
+				Capacity float64   `json:"capacity"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == date.Format("2006-01-02") {
+				return d.Capacity
+			}
+		}
+		t.Fatalf("heatmap has no day %s", date.Format("2006-01-02"))
+		return 0
+	}
+	teamPath := "/api/heatmap/" + team.ID() + "/json"
+
+	a.Equal(10.0, capacityOn(teamPath, tomorrow), "groups have their own capacity by default")
+
+	resp, err := env.API.Call("PUT", "/api/entities/"+team.ID(), map[string]interface{}{"capacity_mode": "members"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should sum member capacities: %s", resp.String())
+	var entity struct {
+		CapacityMode string `json:"capacity_mode"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.Equal("members", entity.CapacityMode)
+
+	a.Equal(5.0, capacityOn(teamPath, tomorrow), "the dev is on leave")
+	a.Equal(8.0, capacityOn(teamPath, later), "and back the day after")
+	a.Equal(3.0, capacityOn(teamPath+"?exclude="+lead.ID(), later), "excluded members bring no capacity")
+
+	resp, err = env.API.Call("PUT", "/api/entities/"+lead.ID(), map[string]interface{}{"region": "id"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should set the region: %s", resp.String())
+	_, err = env.DB.Exec(ctx, `
+		INSERT INTO load_calendar_data.holidays (region, date, name)
+		VALUES ('ID', $1, 'Hari Raya Nyepi')
+	`, later.Format("2006-01-02"))
+	a.NoError(err, "should add a holiday")
+	a.Equal(3.0, capacityOn(teamPath, later), "the lead is off on their region's holiday")
+
+	resp, err = env.API.Call("PUT", "/api/entities/"+lead.ID(), map[string]interface{}{"capacity_mode": "members"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "persons cannot sum member capacities")
+	resp, err = env.API.Call("PUT", "/api/entities/"+team.ID(), map[string]interface{}{"capacity_mode": "sometimes"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = env.API.Call("PUT", "/api/entities/"+team.ID(), map[string]interface{}{"capacity_mode": "static"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should switch back: %s", resp.String())
+	a.Equal(10.0, capacityOn(teamPath, tomorrow), "the group has its own capacity again")
+}
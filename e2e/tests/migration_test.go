@@ -0,0 +1,82 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestMigrationRoundTrip applies migrations up, down, and up again on an
+// empty database and verifies the schema comes back identical each time,
+// also after stepping back one version and after adopting a schema created
+// before versions were recorded.
+func TestMigrationRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	scratch, err := env.NewScratchDatabase(ctx, t.Name())
+	if err != nil {
+		t.Fatalf("failed to create scratch database: %v", err)
+	}
+	defer scratch.Teardown()
+
+	db := scratch.DB
+
+	checksum := func() string {
+		t.Helper()
+		sum, err := testenv.SchemaChecksum(ctx, db.Pool, testenv.AppSchema)
+		a.NoError(err, "should compute schema checksum")
+		return sum
+	}
+
+	empty := checksum()
+
+	// Up
+	a.NoError(db.RunMigrations(ctx), "first up should succeed")
+	first := checksum()
+	a.NotEqual(empty, first, "up should create schema objects")
+
+	description, err := testenv.SchemaDescription(ctx, db.Pool, testenv.AppSchema)
+	a.NoError(err, "should describe schema")
+	for _, table := range []string{"entities", "group_members", "capacity_overrides", "loads", "load_assignments", "load_tags", "holidays", "settings", "heatmap_tombstones", "entity_tombstones", "heatmap_snapshots", "scenarios", "scenario_loads", "scenario_capacity_overrides", "overload_days", "utilization_reports", "group_dashboards", "group_owners", "capacity_change_requests", "otp_records", "sessions", "schema_migrations"} {
+		a.Contains(description, "column "+table+".", "schema should contain table %s", table)
+	}
+
+	// Up again must be a no-op
+	a.NoError(db.RunMigrations(ctx), "repeated up should succeed")
+	a.Equal(first, checksum(), "repeated up should not change the schema")
+
+	status, err := db.GetMigrationStatus(ctx)
+	a.NoError(err, "should read migration status")
+	for _, m := range status {
+		a.NotNil(m.AppliedAt, "migration %04d_%s should be recorded", m.Version, m.Name)
+	}
+
+	// Step back one version and forward again
+	a.NoError(db.MigrateDown(ctx, 1), "one step down should succeed")
+	a.NotEqual(first, checksum(), "one step down should change the schema")
+	a.NoError(db.RunMigrations(ctx), "up after one step down should succeed")
+	a.Equal(first, checksum(), "up after one step down should reproduce the schema")
+
+	// A database migrated before schema_migrations existed adopts the
+	// baseline in place
+	_, err = db.Pool.Exec(ctx, `DROP TABLE load_calendar_data.schema_migrations`)
+	a.NoError(err, "should forget applied versions")
+	a.NoError(db.RunMigrations(ctx), "up on an unversioned schema should succeed")
+	a.Equal(first, checksum(), "adopting the baseline should not change the schema")
+
+	// Down
+	a.NoError(db.RollbackMigrations(ctx), "down should succeed")
+	a.Equal(empty, checksum(), "down should remove every schema object")
+
+	// Up after down must reproduce the original schema
+	a.NoError(db.RunMigrations(ctx), "second up should succeed")
+	a.Equal(first, checksum(), "up after down should reproduce the schema")
+
+	// The rebuilt schema must be usable
+	a.NoError(db.SeedData(ctx), "seeding should succeed after round-trip")
+}
@@ -0,0 +1,108 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestMultiDayLoads verifies that a load spanning several days counts on
+// each of them in the heatmap and day details, with its weight split evenly
+// or counted in full per day, and that invalid ranges are rejected.
+func TestMultiDayLoads(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("traveler@example.com")
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	day := func(offset int) string {
+		return time.Now().UTC().AddDate(0, 0, offset).Format("2006-01-02")
+	}
+	upsert := func(body map[string]interface{}) *helpers.Response {
+		body["assignees"] = []map[string]interface{}{{"email": person.ID(), "weight": body["weight"]}}
+		delete(body, "weight")
+		resp, err := env.API.Call("POST", "/api/loads/upsert", body)
+		a.NoError(err)
+		return resp
+	}
+
+	resp := upsert(map[string]interface{}{
+		"external_id": "offsite", "title": "Offsite", "weight": 5,
+		"date": day(2), "end_date": day(6),
+	})
+	a.Equal(http.StatusOK, resp.StatusCode, "even load should be accepted: %s", resp.String())
+	resp = upsert(map[string]interface{}{
+		"external_id": "on-call", "title": "On call", "weight": 2, "spread": "per_day",
+		"date": day(5), "end_date": day(7),
+	})
+	a.Equal(http.StatusOK, resp.StatusCode, "per-day load should be accepted: %s", resp.String())
+
+	resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var heatmap struct {
+		Days []struct {
+			Date time.Time `json:"date"`
+			Load float64   `json:"load"`
+		} `json:"days"`
+	}
+	a.NoError(resp.JSON(&heatmap))
+	loads := map[string]float64{}
+	for _, d := range heatmap.Days {
+		loads[d.Date.Format("2006-01-02")] = d.Load
+	}
+	a.Equal(0.0, loads[day(1)], "nothing before the first day")
+	a.Equal(1.0, loads[day(2)], "even weight is split across the days")
+	a.Equal(3.0, loads[day(5)], "per-day weight counts in full on each day")
+	a.Equal(3.0, loads[day(6)], "last day of the even load")
+	a.Equal(2.0, loads[day(7)], "last day of the per-day load")
+	a.Equal(0.0, loads[day(8)], "nothing after the last day")
+
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err = client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+day(6), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var details struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Load struct {
+				Title   string `json:"title"`
+				EndDate string `json:"end_date"`
+			} `json:"load"`
+			Assignments []struct {
+				Weight float64 `json:"weight"`
+			} `json:"assignments"`
+		} `json:"loads"`
+	}
+	a.NoError(resp.JSON(&details))
+	a.Equal(3.0, details.TotalLoad)
+	a.Len(details.Loads, 2, "both loads cover the day")
+	for _, l := range details.Loads {
+		a.NotEmpty(l.Load.EndDate, "%s should list its last day", l.Load.Title)
+		a.Len(l.Assignments, 1)
+		if l.Load.Title == "Offsite" {
+			a.Equal(1.0, l.Assignments[0].Weight, "day details show the day's share")
+		}
+	}
+
+	resp = upsert(map[string]interface{}{
+		"external_id": "backwards", "title": "Backwards", "weight": 1,
+		"date": day(3), "end_date": day(2),
+	})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "end_date before date should fail: %s", resp.String())
+	resp = upsert(map[string]interface{}{
+		"external_id": "sideways", "title": "Sideways", "weight": 1, "spread": "weekly",
+		"date": day(3), "end_date": day(4),
+	})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "unknown spread should fail: %s", resp.String())
+}
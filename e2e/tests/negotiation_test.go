@@ -0,0 +1,105 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestContentNegotiation verifies that heatmap and capacity endpoints answer
+// with JSON or HTML according to the Accept header, and that HTMX requests
+// always get HTML.
+func TestContentNegotiation(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("negotiation-person@example.com").WithCapacity(4)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(person.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+
+	call := func(method, path string, body interface{}, headers map[string]string) *helpers.Response {
+		client := session.Client(env.ServiceURL())
+		for key, value := range headers {
+			client.SetHeader(key, value)
+		}
+		resp, err := client.Call(method, path, body)
+		a.NoError(err)
+		return resp
+	}
+	asJSON := map[string]string{"Accept": "application/json"}
+	asHTMX := map[string]string{"Accept": "application/json", "HX-Request": "true"}
+
+	// Heatmap: HTML by default, days as JSON on request
+	resp := call("GET", "/api/heatmap/"+person.ID(), nil, nil)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.Headers.Get("Content-Type"), "text/html")
+	a.Contains(strings.Join(resp.Headers.Values("Vary"), ","), "Accept")
+
+	resp = call("GET", "/api/heatmap/"+person.ID(), nil, asJSON)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var heatmap struct {
+		Entity struct {
+			ID string `json:"id"`
+		} `json:"entity"`
+		Days []struct {
+			Capacity float64 `json:"capacity"`
+		} `json:"days"`
+	}
+	a.NoError(resp.JSON(&heatmap), "heatmap should be JSON: %s", resp.String())
+	a.Equal(person.ID(), heatmap.Entity.ID)
+	a.NotEmpty(heatmap.Days)
+	a.NotEqual(resp.Headers.Get("ETag"), call("GET", "/api/heatmap/"+person.ID(), nil, nil).Headers.Get("ETag"),
+		"the JSON and HTML forms are tagged apart")
+
+	resp = call("GET", "/api/heatmap/"+person.ID(), nil, asHTMX)
+	a.Contains(resp.Headers.Get("Content-Type"), "text/html", "HTMX always gets the partial")
+
+	// The same JSON at its own URL, whatever the Accept header
+	json := call("GET", "/api/heatmap/"+person.ID()+"/json", nil, nil)
+	a.Equal(http.StatusOK, json.StatusCode)
+	a.Contains(json.Headers.Get("Content-Type"), "application/json")
+	a.NoError(json.JSON(&heatmap), "heatmap should be JSON: %s", json.String())
+	a.Equal(person.ID(), heatmap.Entity.ID)
+	a.NotEmpty(heatmap.Days)
+	a.Equal(call("GET", "/api/heatmap/"+person.ID(), nil, asJSON).Headers.Get("ETag"), json.Headers.Get("ETag"),
+		"both JSON forms share a tag")
+
+	resp = call("GET", "/api/heatmap/"+person.ID()+"/json", nil, map[string]string{"If-None-Match": json.Headers.Get("ETag")})
+	a.Equal(http.StatusNotModified, resp.StatusCode, "unchanged heatmap should answer 304")
+
+	// Capacity page: the same settings as JSON
+	resp = call("GET", "/my-capacity", nil, asJSON)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var settings struct {
+		Entity struct {
+			DefaultCapacity float64 `json:"default_capacity"`
+		} `json:"entity"`
+		Overrides []interface{} `json:"overrides"`
+	}
+	a.NoError(resp.JSON(&settings), "settings should be JSON: %s", resp.String())
+	a.Equal(4.0, settings.Entity.DefaultCapacity)
+	a.NotNil(settings.Overrides)
+
+	// Capacity updates: JSON by default, HTML fragments for HTMX or browsers
+	update := map[string]float64{"default_capacity": 5}
+	resp = call("POST", "/api/my-capacity", update, nil)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.Headers.Get("Content-Type"), "application/json")
+
+	resp = call("POST", "/api/my-capacity", update, asHTMX)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "Capacity updated successfully!")
+
+	resp = call("POST", "/api/my-capacity", update, map[string]string{"Accept": "text/html"})
+	a.Contains(resp.Headers.Get("Content-Type"), "text/html")
+}
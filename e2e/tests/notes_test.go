@@ -0,0 +1,98 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestNotes verifies that load and day notes appear in the day details of
+// the people and groups they concern, in both the HTML and JSON forms.
+func TestNotes(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	traveller := fixtures.NewPerson("notes-traveller@example.com")
+	other := fixtures.NewPerson("notes-other@example.com")
+	group := fixtures.NewGroup("notes-team").WithMembers(traveller)
+	a.NoError(fixtures.NewScenario().Add(traveller, other, group).Insert(ctx, env.DB), "should seed scenario")
+
+	login := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		client := session.Client(env.ServiceURL())
+		return client
+	}
+	travellerClient := login(traveller.ID())
+	otherClient := login(other.ID())
+
+	date := time.Now().UTC().AddDate(0, 0, 3).Format("2006-01-02")
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "notes-load",
+		"title":       "Customer Visit",
+		"date":        date,
+		"assignees":   []map[string]interface{}{{"email": traveller.ID()}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	var upserted struct {
+		LoadID int `json:"load_id"`
+	}
+	a.NoError(resp.JSON(&upserted))
+
+	add := func(client *helpers.APIClient, path string, body map[string]string) {
+		resp, err := client.Call("POST", path, body)
+		a.NoError(err)
+		a.Equal(http.StatusCreated, resp.StatusCode, "note should be created: %s", resp.String())
+	}
+	add(otherClient, fmt.Sprintf("/api/loads/%d/notes", upserted.LoadID), map[string]string{"body": "Bring the demo laptop"})
+	add(travellerClient, "/api/my-notes", map[string]string{"date": date, "body": "Travel day"})
+	add(otherClient, "/api/my-notes", map[string]string{"date": date, "body": "Dentist"})
+
+	type note struct {
+		AuthorEmail string `json:"author_email"`
+		LoadID      *int   `json:"load_id"`
+		LoadTitle   string `json:"load_title"`
+		Date        string `json:"date"`
+		Body        string `json:"body"`
+	}
+	dayNotes := func(entityID string) []note {
+		client := helpers.NewAPIClient(env.ServiceURL())
+		client.SetHeader("Accept", "application/json")
+		resp, err := client.Call("GET", "/api/heatmap/"+entityID+"/day/"+date, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var details struct {
+			Notes []note `json:"notes"`
+		}
+		a.NoError(resp.JSON(&details))
+		return details.Notes
+	}
+
+	notes := dayNotes(traveller.ID())
+	if a.Len(notes, 2, "the load note and the traveller's own day note") {
+		a.Equal(other.ID(), notes[0].AuthorEmail, "anyone may comment on a load")
+		a.Equal("Customer Visit", notes[0].LoadTitle)
+		a.Equal(date, notes[0].Date)
+		a.Equal("Travel day", notes[1].Body)
+		a.Nil(notes[1].LoadID)
+	}
+	a.Len(dayNotes(group.ID()), 2, "groups show their members' notes")
+	if notes := dayNotes(other.ID()); a.Len(notes, 1) {
+		a.Equal("Dentist", notes[0].Body)
+	}
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+traveller.ID()+"/day/"+date, nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "Travel day", "the partial lists notes")
+	a.Contains(resp.String(), "Bring the demo laptop")
+}
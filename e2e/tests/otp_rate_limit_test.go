@@ -0,0 +1,53 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestOTPRateLimits verifies that an email is sent at most five OTPs an hour
+// and that an OTP is dropped after five wrong codes, so the right one no
+// longer works.
+func TestOTPRateLimits(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("otp-limit@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	for i := 0; i < 5; i++ {
+		resp, err := env.API.Call("POST", "/auth/request-otp", map[string]string{"email": person.ID()})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "OTP request %d should be sent: %s", i+1, resp.String())
+	}
+	resp, err := env.API.Call("POST", "/auth/request-otp", map[string]string{"email": person.ID()})
+	a.NoError(err)
+	a.Equal(http.StatusTooManyRequests, resp.StatusCode, "the sixth OTP request in an hour is refused")
+
+	var otp string
+	a.NoError(env.Pool.QueryRow(ctx,
+		`SELECT otp FROM load_calendar_data.otp_records WHERE email = $1`, person.ID()).Scan(&otp))
+	wrong := "000000"
+	if otp == wrong {
+		wrong = "111111"
+	}
+
+	verify := func(code string) int {
+		resp, err := env.API.Call("POST", "/auth/verify-otp", map[string]string{"email": person.ID(), "otp": code})
+		a.NoError(err)
+		return resp.StatusCode
+	}
+	for i := 0; i < 4; i++ {
+		a.Equal(http.StatusUnauthorized, verify(wrong), "wrong code %d", i+1)
+	}
+	a.Equal(http.StatusTooManyRequests, verify(wrong), "the fifth wrong code drops the OTP")
+	a.Equal(http.StatusUnauthorized, verify(otp), "the dropped OTP no longer works")
+}
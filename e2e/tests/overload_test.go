@@ -0,0 +1,91 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestOverloadResolutionTracking verifies that a person-day going over
+// capacity is tracked as open, that bringing it back under resolves it with a
+// time to resolution, and that both show in the group report and metrics.
+func TestOverloadResolutionTracking(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("sla@example.com").WithCapacity(2)
+	group := fixtures.NewGroup("sla-team").WithMembers(person)
+	a.NoError(fixtures.NewScenario().Add(person, group).Insert(ctx, env.DB), "should seed scenario")
+
+	date := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	upsert := func(weight float64) {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": "sla-load",
+			"title":       "SLA load",
+			"date":        date,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": weight}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+
+	type groupSummary struct {
+		GroupID            string   `json:"group_id"`
+		Overloaded         int      `json:"overloaded"`
+		Resolved           int      `json:"resolved"`
+		Open               int      `json:"open"`
+		MeanHoursToResolve *float64 `json:"mean_hours_to_resolve"`
+	}
+
+	// waitFor polls the report until the group matches, since overloads are
+	// recorded by a background sweep
+	waitFor := func(desc string, match func(groupSummary) bool) groupSummary {
+		path := fmt.Sprintf("/api/reports/overload-resolution?from=%s&to=%s", date, date)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			resp, err := env.API.Call("GET", path, nil)
+			a.NoError(err)
+			a.Equal(http.StatusOK, resp.StatusCode, "report should load: %s", resp.String())
+			var report struct {
+				Groups []groupSummary `json:"groups"`
+			}
+			a.NoError(resp.JSON(&report))
+			for _, g := range report.Groups {
+				if g.GroupID == group.ID() && match(g) {
+					return g
+				}
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s: %+v", desc, report.Groups)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	upsert(3)
+	open := waitFor("an open overload", func(g groupSummary) bool { return g.Open == 1 })
+	a.Equal(1, open.Overloaded)
+	a.Equal(0, open.Resolved)
+	a.Nil(open.MeanHoursToResolve, "nothing resolved yet")
+
+	upsert(1)
+	resolved := waitFor("the overload to resolve", func(g groupSummary) bool { return g.Resolved == 1 })
+	a.Equal(1, resolved.Overloaded, "the same person-day should be tracked once")
+	a.Equal(0, resolved.Open)
+	a.NotNil(resolved.MeanHoursToResolve, "a resolved day should have a time to resolution")
+
+	resp, err := env.API.Call("GET", "/metrics", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "heatmap_overload_resolved_days 1")
+	a.Contains(resp.String(), "heatmap_overload_open_days 0")
+}
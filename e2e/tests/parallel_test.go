@@ -0,0 +1,44 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestParallelDedicatedEnvs verifies that tests running in parallel against
+// dedicated databases can truncate and seed without seeing each other's data.
+func TestParallelDedicatedEnvs(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			a := helpers.NewAssert(t)
+
+			ded, err := env.NewDedicatedEnv(ctx, t.Name())
+			if err != nil {
+				t.Fatalf("failed to create dedicated env: %v", err)
+			}
+			defer ded.Teardown()
+
+			a.NoError(ded.CleanupTestData(ctx), "cleanup should only touch this test's database")
+
+			email := name + "@example.com"
+			err = fixtures.NewPerson(email).WithTitle("Parallel "+name).Insert(ctx, ded.DB)
+			a.NoError(err, "should seed dedicated database")
+
+			resp, err := ded.API.Call("GET", "/api/entities", nil)
+			a.NoError(err)
+			a.Equal(200, resp.StatusCode)
+
+			var entities []map[string]interface{}
+			a.NoError(resp.JSON(&entities))
+			a.Len(entities, 1, "dedicated service should only see its own entity")
+			a.Contains(resp.String(), email)
+		})
+	}
+}
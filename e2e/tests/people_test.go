@@ -0,0 +1,208 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestOnboardOffboard verifies that onboarding creates a person with their
+// memberships and ramp-up capacity in one call, and that offboarding archives
+// them, removes only their future assignments, and cannot be repeated.
+func TestOnboardOffboard(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	colleague := fixtures.NewPerson("colleague@example.com")
+	group := fixtures.NewGroup("onboarding-team").WithMembers(colleague)
+	a.NoError(fixtures.NewScenario().Add(colleague, group).Insert(ctx, env.DB), "should seed scenario")
+
+	const email = "newhire@example.com"
+	today := time.Now().UTC()
+	count := func(query string, args ...interface{}) int {
+		var n int
+		rows, err := env.DB.Query(ctx, query, args...)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&n))
+		}
+		rows.Close()
+		return n
+	}
+
+	resp, err := env.API.Call("POST", "/api/people/onboard", map[string]interface{}{
+		"email":            email,
+		"title":            "New Hire",
+		"default_capacity": 6,
+		"groups":           []string{group.ID(), group.ID()},
+		"start_date":       today.Format("2006-01-02"),
+		"ramp_up":          map[string]interface{}{"days": 10, "capacity": 2},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "onboard should succeed: %s", resp.String())
+	var onboarded struct {
+		Groups      []string `json:"groups"`
+		RampUpUntil string   `json:"ramp_up_until"`
+	}
+	a.NoError(resp.JSON(&onboarded))
+	a.Equal([]string{group.ID()}, onboarded.Groups, "duplicate groups should be collapsed")
+	a.Equal(today.AddDate(0, 0, 9).Format("2006-01-02"), onboarded.RampUpUntil)
+
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.group_members WHERE group_id = $1 AND person_email = $2`,
+		group.ID(), email), "onboarding should add the membership")
+	a.Equal(10, count(`SELECT COUNT(*) FROM load_calendar_data.capacity_overrides WHERE entity_id = $1 AND capacity = 2`,
+		email), "onboarding should schedule the ramp-up")
+
+	resp, err = env.API.Call("POST", "/api/people/onboard", map[string]interface{}{
+		"email":  "orphan@example.com",
+		"title":  "Orphan",
+		"groups": []string{"missing-team"},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "unknown group should fail: %s", resp.String())
+	a.Equal(0, count(`SELECT COUNT(*) FROM load_calendar_data.entities WHERE id = 'orphan@example.com'`),
+		"failed onboarding should not leave the person behind")
+
+	upsert := func(externalID string, date time.Time, emails ...string) {
+		assignees := make([]map[string]interface{}, 0, len(emails))
+		for _, e := range emails {
+			assignees = append(assignees, map[string]interface{}{"email": e})
+		}
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       externalID,
+			"date":        date.Format("2006-01-02"),
+			"assignees":   assignees,
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+	upsert("past-load", today.AddDate(0, 0, -3), email)
+	upsert("solo-load", today.AddDate(0, 0, 5), email)
+	upsert("shared-load", today.AddDate(0, 0, 6), email, colleague.ID())
+
+	resp, err = env.API.Call("POST", "/api/people/"+email+"/offboard", map[string]string{
+		"last_day": today.Format("2006-01-02"),
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "offboard should succeed: %s", resp.String())
+	var offboarded struct {
+		Groups             []string `json:"groups"`
+		RemovedAssignments []struct {
+			Title      string `json:"title"`
+			Unassigned bool   `json:"unassigned"`
+		} `json:"removed_assignments"`
+	}
+	a.NoError(resp.JSON(&offboarded))
+	a.Equal([]string{group.ID()}, offboarded.Groups, "offboarding should report the person's groups")
+	a.Len(offboarded.RemovedAssignments, 2, "only future assignments should be removed")
+	for _, removed := range offboarded.RemovedAssignments {
+		a.Equal(removed.Title == "solo-load", removed.Unassigned, "%s unassigned flag", removed.Title)
+	}
+
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.load_assignments WHERE person_email = $1`, email),
+		"past assignments should be kept")
+	a.Equal(0, count(`SELECT COUNT(*) FROM load_calendar_data.capacity_overrides WHERE entity_id = $1 AND date > $2`,
+		email, today.Format("2006-01-02")), "future overrides should be removed")
+
+	resp, err = env.API.Call("GET", "/api/entities", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NotContains(resp.String(), email, "archived person should be hidden from the entity list")
+
+	resp, err = env.API.Call("POST", "/auth/request-otp", map[string]string{"email": email})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "archived person should not be able to log in")
+
+	resp, err = env.API.Call("POST", "/api/people/"+email+"/offboard", nil)
+	a.NoError(err)
+	a.Equal(http.StatusConflict, resp.StatusCode, "repeat offboard should conflict: %s", resp.String())
+
+	// Onboarding the same email again restores the archived person
+	resp, err = env.API.Call("POST", "/api/people/onboard", map[string]interface{}{
+		"email": email,
+		"title": "Returning Hire",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "re-onboarding should restore the person: %s", resp.String())
+}
+
+// TestImportGroups verifies that a group import creates missing groups,
+// persons and memberships in one call, that repeating it changes nothing,
+// and that a failing row leaves nothing behind.
+func TestImportGroups(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	existing := fixtures.NewPerson("existing@example.com").WithCapacity(7)
+	platform := fixtures.NewGroup("platform").WithMembers(existing)
+	a.NoError(fixtures.NewScenario().Add(existing, platform).Insert(ctx, env.DB), "should seed scenario")
+
+	count := func(query string, args ...interface{}) int {
+		var n int
+		rows, err := env.DB.Query(ctx, query, args...)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&n))
+		}
+		rows.Close()
+		return n
+	}
+
+	rows := []map[string]string{
+		{"group": platform.ID(), "member": existing.ID()},
+		{"group": platform.ID(), "member": "hire@example.com"},
+		{"group": "data", "member": existing.ID()},
+		{"group": " data ", "member": "hire@example.com"},
+		{"group": "data", "member": "hire@example.com"},
+	}
+	type importResult struct {
+		GroupsCreated       []string `json:"groups_created"`
+		PeopleCreated       []string `json:"people_created"`
+		MembershipsAdded    int      `json:"memberships_added"`
+		MembershipsExisting int      `json:"memberships_existing"`
+	}
+
+	resp, err := env.API.Call("POST", "/api/groups/import", map[string]interface{}{"rows": rows})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "import should succeed: %s", resp.String())
+	var first importResult
+	a.NoError(resp.JSON(&first))
+	a.Equal([]string{"data"}, first.GroupsCreated)
+	a.Equal([]string{"hire@example.com"}, first.PeopleCreated)
+	a.Equal(3, first.MembershipsAdded, "repeated rows should be counted once")
+	a.Equal(1, first.MembershipsExisting)
+
+	a.Equal(2, count(`SELECT COUNT(*) FROM load_calendar_data.group_members WHERE group_id = 'data'`))
+	a.Equal(1, count(`SELECT COUNT(*) FROM load_calendar_data.entities WHERE id = $1 AND default_capacity = 7`,
+		existing.ID()), "existing persons should be kept as they are")
+
+	resp, err = env.API.Call("POST", "/api/groups/import", map[string]interface{}{"rows": rows})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "repeated import should succeed: %s", resp.String())
+	var second importResult
+	a.NoError(resp.JSON(&second))
+	a.Empty(second.GroupsCreated)
+	a.Empty(second.PeopleCreated)
+	a.Equal(0, second.MembershipsAdded, "repeated import should add nothing")
+	a.Equal(4, second.MembershipsExisting)
+
+	resp, err = env.API.Call("POST", "/api/groups/import", map[string]interface{}{"rows": []map[string]string{
+		{"group": "search", "member": "searcher@example.com"},
+		{"group": existing.ID(), "member": "searcher@example.com"},
+	}})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "a person used as a group should fail: %s", resp.String())
+	a.Equal(0, count(`SELECT COUNT(*) FROM load_calendar_data.entities WHERE id IN ('search', 'searcher@example.com')`),
+		"a failed import should not leave anything behind")
+}
@@ -0,0 +1,101 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestPins verifies that a pinned load comes first in the pinning user's day
+// details and is named in their heatmap tooltip, while other viewers see the
+// usual order.
+func TestPins(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("pins-person@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(person.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+	client := session.Client(env.ServiceURL())
+
+	date := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	var loadIDs []int
+	for _, title := range []string{"Routine Sync", "Board Presentation"} {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": "pins-" + strings.ToLower(strings.ReplaceAll(title, " ", "-")),
+			"title":       title,
+			"date":        date,
+			"assignees":   []map[string]interface{}{{"email": person.ID()}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+		var r struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&r))
+		loadIDs = append(loadIDs, r.LoadID)
+	}
+
+	type dayLoad struct {
+		Load struct {
+			Title string `json:"title"`
+		} `json:"load"`
+		Pinned bool `json:"pinned"`
+	}
+	dayLoads := func(loggedIn bool) []dayLoad {
+		c := helpers.NewAPIClient(env.ServiceURL())
+		if loggedIn {
+			c = session.Client(env.ServiceURL())
+		}
+		c.SetHeader("Accept", "application/json")
+		resp, err := c.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+date, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var details struct {
+			Loads []dayLoad `json:"loads"`
+		}
+		a.NoError(resp.JSON(&details))
+		return details.Loads
+	}
+
+	resp, err := client.Call("POST", fmt.Sprintf("/api/loads/%d/pin", loadIDs[1]), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "pinning should succeed: %s", resp.String())
+
+	loads := dayLoads(true)
+	if a.Len(loads, 2) {
+		a.Equal("Board Presentation", loads[0].Load.Title, "the pinned load comes first")
+		a.True(loads[0].Pinned)
+		a.False(loads[1].Pinned)
+	}
+	if loads := dayLoads(false); a.Len(loads, 2) {
+		a.Equal("Routine Sync", loads[0].Load.Title, "pins are per user")
+		a.False(loads[0].Pinned)
+	}
+
+	resp, err = client.Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "Pinned: Board Presentation", "the tooltip names the pinned load")
+	resp, err = env.API.Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.NotContains(resp.String(), "Pinned:", "anonymous viewers see no pins")
+
+	resp, err = client.Call("DELETE", fmt.Sprintf("/api/loads/%d/pin", loadIDs[1]), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	if loads := dayLoads(true); a.Len(loads, 2) {
+		a.Equal("Routine Sync", loads[0].Load.Title, "unpinning restores the order")
+	}
+}
@@ -0,0 +1,104 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestPresence verifies that people on the same entity or load see each
+// other, and that a heartbeat lapses after 30 seconds.
+func TestPresence(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	alice := fixtures.NewPerson("presence-alice@example.com").WithTitle("Alice")
+	bob := fixtures.NewPerson("presence-bob@example.com").WithTitle("Bob")
+	team := fixtures.NewGroup("presence-team").WithMembers(alice, bob)
+	load := fixtures.NewLoad("presence-load").OnDate(time.Now().UTC()).AssignedTo(alice, 1)
+	a.NoError(fixtures.NewScenario().Add(alice, bob, team, load).Insert(ctx, env.DB), "should seed scenario")
+
+	client := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		return session.Client(env.ServiceURL())
+	}
+	aliceClient, bobClient := client(alice.ID()), client(bob.ID())
+
+	type presence struct {
+		Subject string `json:"subject"`
+		Others  []struct {
+			Email string `json:"email"`
+			Name  string `json:"name"`
+			Mode  string `json:"mode"`
+		} `json:"others"`
+	}
+	heartbeat := func(c *helpers.APIClient, path string) presence {
+		t.Helper()
+		resp, err := c.Call("POST", path, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "heartbeat should return 200: %s", resp.String())
+		var p presence
+		a.NoError(resp.JSON(&p))
+		return p
+	}
+
+	// Alice alone on the group sees no one
+	p := heartbeat(aliceClient, "/api/presence/entity/"+team.ID())
+	a.Equal("entity:"+team.ID(), p.Subject)
+	a.Len(p.Others, 0)
+
+	// Bob editing sees Alice viewing, and Alice then sees Bob editing
+	p = heartbeat(bobClient, "/api/presence/entity/"+team.ID()+"?mode=editing")
+	a.Len(p.Others, 1)
+	if len(p.Others) == 1 {
+		a.Equal(alice.ID(), p.Others[0].Email)
+		a.Equal("Alice", p.Others[0].Name)
+		a.Equal("viewing", p.Others[0].Mode)
+	}
+	p = heartbeat(aliceClient, "/api/presence/entity/"+team.ID())
+	a.Len(p.Others, 1)
+	if len(p.Others) == 1 {
+		a.Equal(bob.ID(), p.Others[0].Email)
+		a.Equal("editing", p.Others[0].Mode)
+	}
+
+	// HTMX polls get the indicator
+	aliceClient.SetHeader("HX-Request", "true")
+	resp, err := aliceClient.Call("POST", "/api/presence/entity/"+team.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "Bob is also editing")
+	aliceClient.SetHeader("HX-Request", "")
+
+	// Loads are tracked apart from entities
+	loadPath := "/api/presence/load/" + strconv.Itoa(load.ID())
+	p = heartbeat(aliceClient, loadPath)
+	a.Equal("load:"+strconv.Itoa(load.ID()), p.Subject)
+	a.Len(p.Others, 0, "Bob is on the group, not the load")
+
+	// A heartbeat lapses after 30 seconds
+	_, err = env.DB.Exec(ctx, `
+		UPDATE load_calendar_data.presence SET seen_at = NOW() - INTERVAL '1 minute'
+		WHERE person_email = $1
+	`, bob.ID())
+	a.NoError(err)
+	p = heartbeat(aliceClient, "/api/presence/entity/"+team.ID())
+	a.Len(p.Others, 0, "Bob's presence should have lapsed")
+
+	resp, err = aliceClient.Call("POST", "/api/presence/entity/presence-nobody", nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+	resp, err = aliceClient.Call("POST", "/api/presence/entity/"+team.ID()+"?mode=typing", nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+}
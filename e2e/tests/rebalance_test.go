@@ -0,0 +1,91 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestRebalanceGroup verifies that the rebalance plan moves load off an
+// overloaded member onto the member with the most room, skips members
+// already on the load, and changes nothing in the database.
+func TestRebalanceGroup(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	alice := fixtures.NewPerson("alice@example.com").WithCapacity(4)
+	bob := fixtures.NewPerson("bob@example.com").WithCapacity(4)
+	carol := fixtures.NewPerson("carol@example.com").WithCapacity(4)
+	group := fixtures.NewGroup("rebalance-team").WithMembers(alice, bob, carol)
+	a.NoError(fixtures.NewScenario().Add(alice, bob, carol, group).Insert(ctx, env.DB), "should seed scenario")
+
+	date := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+	upsert := func(externalID string, assignees ...map[string]interface{}) {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       externalID,
+			"date":        date,
+			"assignees":   assignees,
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+	upsert("review", map[string]interface{}{"email": alice.ID(), "weight": 3})
+	upsert("pairing", map[string]interface{}{"email": alice.ID(), "weight": 2},
+		map[string]interface{}{"email": carol.ID(), "weight": 1})
+	upsert("support", map[string]interface{}{"email": bob.ID(), "weight": 3})
+
+	resp, err := env.API.Call("GET", "/api/rebalance/"+group.ID()+"?date="+date, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "rebalance should succeed: %s", resp.String())
+
+	var plan struct {
+		Moves []struct {
+			Title  string  `json:"title"`
+			Weight float64 `json:"weight"`
+			From   string  `json:"from"`
+			To     string  `json:"to"`
+		} `json:"moves"`
+		Members []struct {
+			Email     string  `json:"email"`
+			LoadAfter float64 `json:"load_after"`
+		} `json:"members"`
+		Resolved bool `json:"resolved"`
+	}
+	a.NoError(resp.JSON(&plan))
+	a.True(plan.Resolved, "the plan should resolve the overload")
+	if len(plan.Moves) != 1 {
+		t.Fatalf("expected 1 move, got %+v", plan.Moves)
+	}
+	// Alice is 1 over. "pairing" is the smaller load, but carol is already on
+	// it and bob has no room, so "review" goes to carol instead
+	a.Equal("review", plan.Moves[0].Title)
+	a.Equal(alice.ID(), plan.Moves[0].From)
+	a.Equal(carol.ID(), plan.Moves[0].To)
+	a.Equal(3.0, plan.Moves[0].Weight)
+	for _, m := range plan.Members {
+		a.True(m.LoadAfter <= 4, "%s should be within capacity after the moves", m.Email)
+	}
+
+	var aliceAssignments int
+	rows, err := env.DB.Query(ctx,
+		`SELECT COUNT(*) FROM load_calendar_data.load_assignments WHERE person_email = $1`, alice.ID())
+	a.NoError(err)
+	if rows.Next() {
+		a.NoError(rows.Scan(&aliceAssignments))
+	}
+	rows.Close()
+	a.Equal(2, aliceAssignments, "the plan should be a dry run")
+
+	resp, err = env.API.Call("GET", "/api/rebalance/"+alice.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "a person cannot be rebalanced")
+}
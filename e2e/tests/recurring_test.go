@@ -0,0 +1,120 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestRecurringLoads verifies that a repeating load counts on each of its
+// occurrences in the heatmap and day details, that re-upserting it without a
+// recurrence drops the later occurrences, and that invalid rules are
+// rejected.
+func TestRecurringLoads(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("standup@example.com")
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	day := func(offset int) string {
+		return time.Now().UTC().AddDate(0, 0, offset).Format("2006-01-02")
+	}
+	upsert := func(body map[string]interface{}) *helpers.Response {
+		body["external_id"] = "weekly-review"
+		body["title"] = "Weekly review"
+		body["assignees"] = []map[string]interface{}{{"email": person.ID(), "weight": 2}}
+		resp, err := env.API.Call("POST", "/api/loads/upsert", body)
+		a.NoError(err)
+		return resp
+	}
+	heatmap := func() map[string]float64 {
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		loads := map[string]float64{}
+		for _, d := range heatmap.Days {
+			loads[d.Date.Format("2006-01-02")] = d.Load
+		}
+		return loads
+	}
+
+	resp := upsert(map[string]interface{}{
+		"date":       day(1),
+		"recurrence": map[string]interface{}{"frequency": "weekly", "count": 3},
+	})
+	a.Equal(http.StatusOK, resp.StatusCode, "recurring load should be accepted: %s", resp.String())
+
+	loads := heatmap()
+	a.Equal(2.0, loads[day(1)], "first occurrence")
+	a.Equal(0.0, loads[day(2)], "nothing between occurrences")
+	a.Equal(2.0, loads[day(8)], "second occurrence")
+	a.Equal(2.0, loads[day(15)], "third occurrence")
+	a.Equal(0.0, loads[day(22)], "the count ends the rule")
+
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err := client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+day(8), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var details struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Load struct {
+				Title      string `json:"title"`
+				Recurrence *struct {
+					Frequency string `json:"frequency"`
+					Interval  int    `json:"interval"`
+					Count     *int   `json:"count"`
+				} `json:"recurrence"`
+			} `json:"load"`
+		} `json:"loads"`
+	}
+	a.NoError(resp.JSON(&details))
+	a.Equal(2.0, details.TotalLoad)
+	if a.Len(details.Loads, 1, "the occurrence is listed on its day") && a.NotNil(details.Loads[0].Load.Recurrence) {
+		rule := details.Loads[0].Load.Recurrence
+		a.Equal("weekly", rule.Frequency)
+		a.Equal(1, rule.Interval)
+		if a.NotNil(rule.Count) {
+			a.Equal(3, *rule.Count)
+		}
+	}
+
+	resp = upsert(map[string]interface{}{"date": day(1)})
+	a.Equal(http.StatusOK, resp.StatusCode, "re-upsert as a one-off should succeed: %s", resp.String())
+	loads = heatmap()
+	a.Equal(2.0, loads[day(1)], "the load itself remains")
+	a.Equal(0.0, loads[day(8)], "later occurrences are dropped")
+
+	resp = upsert(map[string]interface{}{
+		"date": day(1), "end_date": day(8),
+		"recurrence": map[string]interface{}{"frequency": "weekly"},
+	})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "overlapping occurrences should fail: %s", resp.String())
+	resp = upsert(map[string]interface{}{
+		"date":       day(1),
+		"recurrence": map[string]interface{}{"frequency": "daily", "until": day(5), "count": 2},
+	})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "until and count together should fail: %s", resp.String())
+	resp = upsert(map[string]interface{}{
+		"date":       day(1),
+		"recurrence": map[string]interface{}{"frequency": "yearly"},
+	})
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "unknown frequency should fail: %s", resp.String())
+}
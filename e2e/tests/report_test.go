@@ -0,0 +1,107 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestQuarterlyUtilizationReport verifies the per-person and per-group
+// quarterly summary, its CSV export, that a finished quarter's report is
+// kept as first generated, and that private persons are left out of it.
+func TestQuarterlyUtilizationReport(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	day := func(d int) time.Time { return time.Date(2020, time.January, d, 0, 0, 0, 0, time.UTC) }
+	person := fixtures.NewPerson("quarterly@example.com").WithTitle("Quarterly Person").WithCapacity(5)
+	hidden := fixtures.NewPerson("quarterly-private@example.com").WithCapacity(5)
+	group := fixtures.NewGroup("quarterly-team").WithCapacity(10).WithMembers(person, hidden)
+	a.NoError(fixtures.NewScenario().Add(
+		person, hidden, group,
+		fixtures.NewLoad("quarterly-1").WithSource("jira").OnDate(day(10)).AssignedTo(person, 7),
+		fixtures.NewLoad("quarterly-2").WithSource("gcal").OnDate(day(11)).AssignedTo(person, 2),
+		fixtures.NewLoad("quarterly-3").WithSource("jira").OnDate(day(12)).AssignedTo(person, 3),
+		fixtures.NewLoad("quarterly-private").WithSource("jira").OnDate(day(10)).AssignedTo(hidden, 4),
+	).Insert(ctx, env.DB), "should seed scenario")
+	_, err := env.DB.Exec(ctx, `UPDATE load_calendar_data.entities SET private = true WHERE id = $1`, hidden.ID())
+	a.NoError(err, "should make person private")
+
+	type summary struct {
+		EntityID       string  `json:"entity_id"`
+		Days           int     `json:"days"`
+		AverageLoad    float64 `json:"average_load"`
+		OverloadedDays int     `json:"overloaded_days"`
+		TopSources     []struct {
+			Source string  `json:"source"`
+			Load   float64 `json:"load"`
+		} `json:"top_sources"`
+	}
+	type report struct {
+		Quarter  string    `json:"quarter"`
+		From     string    `json:"from"`
+		To       string    `json:"to"`
+		Complete bool      `json:"complete"`
+		People   []summary `json:"people"`
+		Groups   []summary `json:"groups"`
+	}
+	getReport := func() report {
+		resp, err := env.API.Call("GET", "/api/reports/utilization?quarter=2020-Q1", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "report should load: %s", resp.String())
+		var r report
+		a.NoError(resp.JSON(&r))
+		if len(r.People) != 1 || len(r.Groups) != 1 {
+			t.Fatalf("expected one person and one group, got %+v", r)
+		}
+		return r
+	}
+
+	r := getReport()
+	a.Equal("2020-Q1", r.Quarter)
+	a.Equal("2020-01-01", r.From)
+	a.Equal("2020-03-31", r.To)
+	a.True(r.Complete, "a past quarter is complete")
+
+	p := r.People[0]
+	a.Equal(person.ID(), p.EntityID)
+	a.Equal(91, p.Days, "every day of the leap-year quarter")
+	a.Equal(0.13, p.AverageLoad)
+	a.Equal(1, p.OverloadedDays, "only the 7-point day is over capacity 5")
+	if a.Len(p.TopSources, 2) {
+		a.Equal("jira", p.TopSources[0].Source)
+		a.Equal(10.0, p.TopSources[0].Load)
+		a.Equal("gcal", p.TopSources[1].Source)
+	}
+	a.Equal(group.ID(), r.Groups[0].EntityID)
+	a.Equal(1, r.Groups[0].OverloadedDays, "the private person's load still counts for the group")
+
+	// The stored report is served even after the quarter's loads change
+	a.NoError(fixtures.NewLoad("quarterly-late").WithSource("jira").OnDate(day(20)).AssignedTo(person, 9).
+		Insert(ctx, env.DB), "should insert late load")
+	a.Equal(1, getReport().People[0].OverloadedDays, "a finished quarter's report should not change")
+
+	resp, err := env.API.Call("GET", "/api/reports/utilization?quarter=2020-Q1&format=csv", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.Headers.Get("Content-Type"), "text/csv")
+	a.Contains(resp.Headers.Get("Content-Disposition"), "utilization-2020-Q1.csv")
+	lines := strings.Split(strings.TrimSpace(resp.String()), "\n")
+	a.Equal("quarter,type,entity_id,title,days,average_load,average_utilization,p95_load,overloaded_days,top_sources", lines[0])
+	a.Contains(resp.String(), "2020-Q1,person,quarterly@example.com,Quarterly Person,91,0.13,2.6,0,1,jira:10;gcal:2")
+	a.NotContains(resp.String(), hidden.ID(), "private persons are left out")
+
+	future := time.Now().UTC().AddDate(1, 0, 0).Format("2006") + "-Q1"
+	resp, err = env.API.Call("GET", "/api/reports/utilization?quarter="+future, nil)
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "future quarters cannot be reported")
+}
@@ -0,0 +1,79 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestRollupHeatmap verifies that a manager's roll-up heatmap sums the loads
+// and capacities of everyone reporting to them, directly or transitively,
+// following manager_email rather than explicit groups.
+func TestRollupHeatmap(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	boss := fixtures.NewPerson("rollup-boss@example.com").WithCapacity(5)
+	lead := fixtures.NewPerson("rollup-lead@example.com").WithCapacity(5)
+	dev1 := fixtures.NewPerson("rollup-dev1@example.com").WithCapacity(5)
+	dev2 := fixtures.NewPerson("rollup-dev2@example.com").WithCapacity(5)
+	outsider := fixtures.NewPerson("rollup-outsider@example.com").WithCapacity(5)
+	a.NoError(fixtures.NewScenario().Add(boss, lead, dev1, dev2, outsider).Insert(ctx, env.DB), "should seed scenario")
+
+	setManager := func(person, manager string) {
+		resp, err := env.API.Call("PUT", "/api/entities/"+person, map[string]string{"manager_email": manager})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "manager update should succeed: %s", resp.String())
+	}
+	setManager(lead.ID(), boss.ID())
+	setManager(dev1.ID(), lead.ID())
+	setManager(dev2.ID(), lead.ID())
+
+	date := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	for i, assignee := range []string{lead.ID(), dev2.ID(), outsider.ID(), boss.ID()} {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": "rollup-" + string(rune('a'+i)),
+			"title":       "Roll-up Work",
+			"date":        date,
+			"assignees":   []map[string]interface{}{{"email": assignee, "weight": 3}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+
+	rollup := func(manager string) *helpers.Response {
+		resp, err := env.API.Call("GET", "/api/heatmap/"+manager+"/reports", nil)
+		a.NoError(err)
+		return resp
+	}
+
+	resp := rollup(boss.ID())
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "Utilization: 40%", "6 load over the 15 capacity of the boss's three reports")
+
+	resp = rollup(lead.ID())
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "Utilization: 30%", "3 load over the 10 capacity of the lead's two reports")
+
+	a.Equal(http.StatusNotFound, rollup(dev1.ID()).StatusCode, "nobody reports to dev1")
+	a.Equal(http.StatusNotFound, rollup("rollup-nobody@example.com").StatusCode)
+
+	// A cycle in the directory data ends the walk instead of looping
+	setManager(boss.ID(), dev1.ID())
+	resp = rollup(boss.ID())
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), "Utilization: 40%", "the manager stays out of their own roll-up")
+
+	setManager(dev2.ID(), "")
+	resp = rollup(lead.ID())
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NotContains(resp.String(), "Utilization: 30%", "clearing the manager drops the report")
+}
@@ -0,0 +1,117 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestScenarioOverlay verifies that a scenario's loads and capacities show up
+// in its heatmap for the person and their group, never in the real heatmap,
+// and are removed with the scenario.
+func TestScenarioOverlay(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("whatif@example.com").WithCapacity(5)
+	group := fixtures.NewGroup("whatif-team").WithMembers(person)
+	a.NoError(fixtures.NewScenario().Add(person, group).Insert(ctx, env.DB), "should seed scenario")
+
+	date := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "whatif-real",
+		"title":       "Real load",
+		"date":        date,
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 1}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	realHeatmap := func() *helpers.Response {
+		client := helpers.NewAPIClient(env.ServiceURL())
+		resp, err := client.Call("GET", "/api/heatmap/"+person.ID(), nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "real heatmap: %s", resp.String())
+		return resp
+	}
+	before := realHeatmap()
+	a.Contains(before.String(), "Total Load: 1.0")
+
+	resp, err = env.API.Call("POST", "/api/scenarios", map[string]interface{}{
+		"name":        "Hire freeze",
+		"description": "What if the team takes the migration too",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "create should succeed: %s", resp.String())
+	var scenario struct {
+		ID int `json:"id"`
+	}
+	a.NoError(resp.JSON(&scenario))
+	scenarioPath := fmt.Sprintf("/api/scenarios/%d", scenario.ID)
+
+	resp, err = env.API.Call("POST", scenarioPath+"/loads", map[string]interface{}{
+		"title":     "Migration",
+		"date":      date,
+		"assignees": []map[string]interface{}{{"email": person.ID(), "weight": 2.5}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "add load should succeed: %s", resp.String())
+
+	resp, err = env.API.Call("PUT", scenarioPath+"/capacity", map[string]interface{}{
+		"entity_id": person.ID(),
+		"date":      date,
+		"capacity":  2,
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "set capacity should succeed: %s", resp.String())
+
+	resp, err = env.API.Call("POST", scenarioPath+"/loads", map[string]interface{}{
+		"title":     "Unknown person",
+		"date":      date,
+		"assignees": []map[string]interface{}{{"email": "nobody@example.com"}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "unknown person should be rejected: %s", resp.String())
+
+	for _, entity := range []string{person.ID(), group.ID()} {
+		resp, err = env.API.Call("GET", scenarioPath+"/heatmap/"+entity, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "scenario heatmap for %s: %s", entity, resp.String())
+		a.Contains(resp.String(), "Hire freeze", "partial should name the scenario")
+		a.Contains(resp.String(), "Total Load: 3.5", "%s should include the scenario load", entity)
+	}
+
+	after := realHeatmap()
+	a.Contains(after.String(), "Total Load: 1.0", "real heatmap should keep the real load")
+	a.NotContains(after.String(), "Total Load: 3.5", "scenario load must not reach the real heatmap")
+	a.Equal(before.Headers.Get("ETag"), after.Headers.Get("ETag"), "scenario writes must not change the real version")
+
+	resp, err = env.API.Call("DELETE", scenarioPath, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "delete should succeed: %s", resp.String())
+
+	var remaining int
+	rows, err := env.DB.Query(ctx, `
+		SELECT (SELECT COUNT(*) FROM load_calendar_data.scenario_loads WHERE scenario_id = $1)
+		     + (SELECT COUNT(*) FROM load_calendar_data.scenario_capacity_overrides WHERE scenario_id = $1)
+	`, scenario.ID)
+	a.NoError(err)
+	if rows.Next() {
+		a.NoError(rows.Scan(&remaining))
+	}
+	rows.Close()
+	a.Equal(0, remaining, "deleting a scenario should remove its loads and capacities")
+
+	resp, err = env.API.Call("GET", scenarioPath+"/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "deleted scenario should be gone")
+}
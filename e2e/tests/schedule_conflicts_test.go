@@ -0,0 +1,110 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestScheduleConflicts verifies that loads whose time windows overlap for
+// the same person are flagged in day details and reported by webhook.
+func TestScheduleConflicts(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+	env.Webhooks.Reset()
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	date := tomorrow.Format("2006-01-02")
+	person := fixtures.NewPerson("conflicts-person@example.com")
+	other := fixtures.NewPerson("conflicts-other@example.com")
+	a.NoError(fixtures.NewScenario().Add(person, other).Insert(ctx, env.DB), "should seed scenario")
+
+	upsert := func(externalID, start, end string, assignee *fixtures.PersonBuilder) int {
+		t.Helper()
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id":   externalID,
+			"title":         externalID,
+			"source":        "calendar",
+			"date":          date,
+			"custom_fields": map[string]interface{}{"start_time": start, "end_time": end},
+			"assignees":     []map[string]interface{}{{"email": assignee.ID(), "weight": 1}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+		var r struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&r))
+		return r.LoadID
+	}
+	planning := upsert("conflicts-planning", "09:00", "10:00", person)
+	review := upsert("conflicts-review", "09:30", "10:30", person)
+	lunch := upsert("conflicts-lunch", "12:00", "13:00", person)
+	upsert("conflicts-elsewhere", "09:00", "10:00", other)
+
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("Accept", "application/json")
+	resp, err := client.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+date, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should get day details: %s", resp.String())
+	var details struct {
+		Loads []struct {
+			Load struct {
+				ID int `json:"id"`
+			} `json:"load"`
+			Conflicts []int `json:"conflicts"`
+		} `json:"loads"`
+	}
+	a.NoError(resp.JSON(&details))
+	conflicts := map[int][]int{}
+	for _, l := range details.Loads {
+		conflicts[l.Load.ID] = l.Conflicts
+	}
+	a.Equal([]int{review}, conflicts[planning])
+	a.Equal([]int{planning}, conflicts[review])
+	a.Empty(conflicts[lunch], "lunch overlaps nothing")
+
+	type conflictAlert struct {
+		Event       string `json:"event"`
+		PersonEmail string `json:"person_email"`
+		Date        string `json:"date"`
+		Load        struct {
+			LoadID int `json:"load_id"`
+		} `json:"load"`
+		Conflicts []struct {
+			LoadID int    `json:"load_id"`
+			Start  string `json:"start"`
+		} `json:"conflicts"`
+	}
+	alerts := func() []conflictAlert {
+		var found []conflictAlert
+		for _, req := range env.Webhooks.Requests() {
+			var alert conflictAlert
+			if json.Unmarshal(req.Body, &alert) == nil && alert.Event == "schedule_conflict" {
+				found = append(found, alert)
+			}
+		}
+		return found
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(alerts()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	found := alerts()
+	a.Len(found, 1, "only the review made a conflict")
+	a.Equal(person.ID(), found[0].PersonEmail)
+	a.Equal(date, found[0].Date)
+	a.Equal(review, found[0].Load.LoadID)
+	a.Len(found[0].Conflicts, 1)
+	a.Equal(planning, found[0].Conflicts[0].LoadID)
+	a.Equal("09:00", found[0].Conflicts[0].Start)
+}
@@ -0,0 +1,143 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestSettings verifies that admins change color thresholds, the default
+// capacity and the webhook secret at runtime, that the secret is never
+// returned, and that every change lands in the event log.
+func TestSettings(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	person := fixtures.NewPerson("settings-person@example.com")
+	load := fixtures.NewLoad("settings-load").OnDate(tomorrow).AssignedTo(person, 2.5)
+	a.NoError(fixtures.NewScenario().Add(person, load).Insert(ctx, env.DB), "should seed scenario")
+
+	client := func(email string) *helpers.APIClient {
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		c := session.Client(env.ServiceURL())
+		c.SetHeader("Accept", "application/json")
+		return c
+	}
+	user, admin := client(person.ID()), client(testenv.AdminEmail)
+
+	// Settings live in memory until the next refresh, so put the defaults back
+	t.Cleanup(func() {
+		resp, err := admin.Call("PUT", "/api/settings", map[string]interface{}{
+			"color_thresholds": []float64{0.2, 0.4, 0.6, 0.8, 1.0},
+			"default_capacity": 5,
+			"webhook_secret":   "",
+		})
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("settings should be reset: %v", err)
+		}
+	})
+
+	type settings struct {
+		ColorThresholds  []float64 `json:"color_thresholds"`
+		DefaultCapacity  float64   `json:"default_capacity"`
+		WebhookSecretSet bool      `json:"webhook_secret_set"`
+		UpdatedBy        string    `json:"updated_by"`
+	}
+	colorOf := func() string {
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []struct {
+				Date  time.Time `json:"date"`
+				Color string    `json:"color"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == tomorrow.Format("2006-01-02") {
+				return d.Color
+			}
+		}
+		t.Fatalf("heatmap has no day %s", tomorrow.Format("2006-01-02"))
+		return ""
+	}
+
+	resp, err := admin.Call("GET", "/settings", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "admins may read settings: %s", resp.String())
+	var current settings
+	a.NoError(resp.JSON(&current))
+	a.Equal([]float64{0.2, 0.4, 0.6, 0.8, 1.0}, current.ColorThresholds)
+	a.Equal(5.0, current.DefaultCapacity)
+	a.Equal("#fbbf24", colorOf(), "half the capacity is amber by default")
+
+	// Only admins change settings
+	resp, err = user.Call("GET", "/settings", nil)
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode)
+	resp, err = user.Call("PUT", "/api/settings", map[string]interface{}{"default_capacity": 8})
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode, "non-admins may not change settings: %s", resp.String())
+
+	resp, err = admin.Call("PUT", "/api/settings", map[string]interface{}{"color_thresholds": []float64{0.4, 0.2, 0.6, 0.8, 1.0}})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "thresholds must ascend")
+
+	resp, err = admin.Call("PUT", "/api/settings", map[string]interface{}{
+		"color_thresholds": []float64{0.1, 0.2, 0.3, 0.4, 0.5},
+		"default_capacity": 8,
+		"webhook_secret":   "s3cret",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "admins may change settings: %s", resp.String())
+	a.NotContains(resp.String(), "s3cret", "the secret is never returned")
+	a.NoError(resp.JSON(&current))
+	a.Equal(8.0, current.DefaultCapacity)
+	a.True(current.WebhookSecretSet)
+	a.Equal(testenv.AdminEmail, current.UpdatedBy)
+
+	a.Equal("#dc2626", colorOf(), "the same load is red under the new thresholds")
+
+	// Persons created without a capacity take the new default
+	resp, err = env.API.Call("POST", "/api/entities", map[string]interface{}{
+		"id": "settings-new@example.com", "title": "New Person", "type": "person",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "entity should be created: %s", resp.String())
+	var entity struct {
+		DefaultCapacity float64 `json:"default_capacity"`
+	}
+	a.NoError(resp.JSON(&entity))
+	a.Equal(8.0, entity.DefaultCapacity)
+
+	resp, err = env.API.Call("GET", "/api/events", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var events []struct {
+		Type    string                 `json:"type"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	a.NoError(resp.JSON(&events))
+	var changes []map[string]interface{}
+	for _, e := range events {
+		if e.Type == "settings.updated" {
+			changes = append(changes, e.Payload)
+		}
+	}
+	if a.Len(changes, 1, "one event per change") {
+		a.Equal(8.0, changes[0]["default_capacity"])
+		a.Equal(true, changes[0]["webhook_secret"], "the event notes only that a secret was set")
+	}
+}
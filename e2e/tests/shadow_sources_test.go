@@ -0,0 +1,129 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestShadowSources verifies that loads upserted by a source in shadow mode
+// land in its scenario rather than in real heatmaps, and that the source's
+// upserts count again once it goes live.
+func TestShadowSources(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	person := fixtures.NewPerson("shadow-person@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("POST", "/api/shadow-sources", map[string]string{"source": "new-crm"})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "should put the source in shadow mode: %s", resp.String())
+	var shadow struct {
+		ScenarioID int `json:"scenario_id"`
+	}
+	a.NoError(resp.JSON(&shadow))
+	a.NotEqual(0, shadow.ScenarioID, "a scenario keeps the source's loads")
+
+	upsert := map[string]interface{}{
+		"external_id": "crm-1",
+		"title":       "CRM Import",
+		"source":      "new-crm",
+		"date":        tomorrow,
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+	}
+	resp, err = env.API.Call("POST", "/api/loads/upsert", upsert)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should upsert: %s", resp.String())
+	var result struct {
+		LoadID int  `json:"load_id"`
+		Shadow bool `json:"shadow"`
+	}
+	a.NoError(resp.JSON(&result))
+	a.True(result.Shadow, "the load is kept in shadow mode")
+	a.Equal(0, result.LoadID, "no real load was created")
+
+	dayLoad := func() float64 {
+		resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID()+"/json", nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get heatmap: %s", resp.String())
+		var heatmap struct {
+			Days []struct {
+				Date time.Time `json:"date"`
+				Load float64   `json:"load"`
+			} `json:"days"`
+		}
+		a.NoError(resp.JSON(&heatmap))
+		for _, d := range heatmap.Days {
+			if d.Date.Format("2006-01-02") == tomorrow {
+				return d.Load
+			}
+		}
+		t.Fatalf("heatmap has no %s", tomorrow)
+		return 0
+	}
+	a.Equal(0.0, dayLoad(), "shadow loads are not in the real heatmap")
+
+	scenarioPath := fmt.Sprintf("/api/scenarios/%d", shadow.ScenarioID)
+	scenarioLoads := func() int {
+		resp, err := env.API.Call("GET", scenarioPath, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "should get scenario: %s", resp.String())
+		var detail struct {
+			Loads []struct {
+				Weight float64 `json:"weight"`
+			} `json:"loads"`
+		}
+		a.NoError(resp.JSON(&detail))
+		return len(detail.Loads)
+	}
+	a.Equal(1, scenarioLoads(), "the scenario holds the load")
+
+	resp, err = env.API.Call("POST", "/api/loads/upsert", upsert)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Equal(1, scenarioLoads(), "upserting again replaces the shadow load")
+
+	resp, err = env.API.Call("GET", scenarioPath+"/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "the scenario heatmap previews the load")
+
+	resp, err = env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "crm-1", "source": "new-crm", "deleted": true,
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "a tombstone removes the shadow load: %s", resp.String())
+	a.Equal(0, scenarioLoads())
+
+	resp, err = env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "crm-2",
+		"title":       "Unknown",
+		"source":      "new-crm",
+		"date":        tomorrow,
+		"assignees":   []map[string]interface{}{{"email": "shadow-unknown@example.com"}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode, "shadow mode does not create persons")
+
+	resp, err = env.API.Call("DELETE", "/api/shadow-sources/new-crm", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should take the source live: %s", resp.String())
+
+	resp, err = env.API.Call("POST", "/api/loads/upsert", upsert)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	result.Shadow = false
+	a.NoError(resp.JSON(&result))
+	a.False(result.Shadow, "a live source's loads are real")
+	a.Equal(3.0, dayLoad(), "the load counts once the source is live")
+}
@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gti/heatmap-internal/e2e/fixtures"
 	"github.com/gti/heatmap-internal/e2e/helpers"
 	"github.com/gti/heatmap-internal/e2e/testenv"
 )
@@ -188,6 +189,7 @@ func TestSmoke(t *testing.T) {
 	// Get browser (lazy initialization)
 	browser, err := env.Browser()
 	a.NoError(err, "should start browser")
+	browser.ScreenshotOnFailure(t)
 
 	// Navigate to the home page
 	err = browser.Navigate(env.ServiceURL())
@@ -217,6 +219,15 @@ func TestSmoke(t *testing.T) {
 	a.NoError(err, "should get login page text")
 	t.Logf("Login page loaded, content preview: %.100s...", loginPageText)
 
+	// The login form must post to the OTP endpoint and swap its own container
+	hxPost, err := browser.Attr("form[hx-post]", "hx-post")
+	a.NoError(err, "should read login form hx-post")
+	a.Equal("/auth/request-otp", hxPost, "login form should post to request-otp")
+
+	hxTarget, err := browser.Attr("form[hx-post]", "hx-target")
+	a.NoError(err, "should read login form hx-target")
+	a.Equal("#login-form-container", hxTarget, "login form should swap its container")
+
 	t.Log("Browser automation verified successfully")
 
 	// =========================================================================
@@ -343,6 +354,53 @@ func TestAPIEntityCRUD(t *testing.T) {
 	t.Log("Entity CRUD operations verified")
 }
 
+// TestCreateDuplicateEntity verifies that creating a taken ID answers 409
+// with the existing entity, and that an upsert updates it instead, keeping
+// fields the request leaves out.
+func TestCreateDuplicateEntity(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx))
+	person := fixtures.NewPerson("synced@example.com").WithTitle("Synced").WithCapacity(3)
+	a.NoError(fixtures.NewScenario(person, fixtures.NewGroup("synced-team")).Insert(ctx, env.DB))
+
+	resp, err := env.API.Call("POST", "/api/entities", map[string]interface{}{
+		"id": person.ID(), "title": "Synced Again", "type": "person",
+	})
+	a.NoError(err)
+	a.Equal(409, resp.StatusCode, "duplicate should conflict: %s", resp.String())
+	var conflict struct {
+		Error  string                 `json:"error"`
+		Entity map[string]interface{} `json:"entity"`
+	}
+	a.NoError(resp.JSON(&conflict))
+	a.Equal("Synced", conflict.Entity["title"], "the existing entity should be returned unchanged")
+
+	resp, err = env.API.Call("POST", "/api/entities?upsert=true", map[string]interface{}{
+		"id": person.ID(), "title": "Synced Again", "type": "person", "employee_id": "E-42",
+	})
+	a.NoError(err)
+	a.Equal(200, resp.StatusCode, "upsert should update: %s", resp.String())
+	var entity map[string]interface{}
+	a.NoError(resp.JSON(&entity))
+	a.Equal("Synced Again", entity["title"])
+	a.Equal("E-42", entity["employee_id"])
+	a.Equal(3.0, entity["default_capacity"], "capacity left out should be kept")
+
+	resp, err = env.API.Call("POST", "/api/entities?upsert=true", map[string]interface{}{
+		"id": "synced-team", "title": "Now a person", "type": "person",
+	})
+	a.NoError(err)
+	a.Equal(409, resp.StatusCode, "an upsert should not change an entity's type")
+
+	resp, err = env.API.Call("POST", "/api/entities?upsert=true", map[string]interface{}{
+		"id": "fresh@example.com", "title": "Fresh", "type": "person",
+	})
+	a.NoError(err)
+	a.Equal(201, resp.StatusCode, "an upsert of a new ID should create it")
+}
+
 // TestGroupMembership tests group member management via API.
 func TestGroupMembership(t *testing.T) {
 	ctx := context.Background()
@@ -353,12 +411,10 @@ func TestGroupMembership(t *testing.T) {
 	a.NoError(err)
 
 	// Create a person and a group
-	_, err = env.DB.Exec(ctx, `
-		INSERT INTO load_calendar_data.entities (id, title, type, default_capacity)
-		VALUES
-			('member@example.com', 'Group Member', 'person', 5.0),
-			('test-group', 'Test Group', 'group', 10.0)
-	`)
+	err = fixtures.NewScenario(
+		fixtures.NewPerson("member@example.com").WithTitle("Group Member"),
+		fixtures.NewGroup("test-group").WithTitle("Test Group"),
+	).Insert(ctx, env.DB)
 	a.NoError(err)
 
 	// Add member to group via API
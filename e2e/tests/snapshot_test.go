@@ -0,0 +1,72 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestHeatmapSnapshots verifies that a heatmap is stored as a snapshot when
+// first computed, served from it while nothing changes, and recomputed once a
+// load write changes the heatmap.
+func TestHeatmapSnapshots(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("snapshot@example.com")
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	snapshotCount := func() int {
+		var count int
+		rows, err := env.DB.Query(ctx,
+			`SELECT COUNT(*) FROM load_calendar_data.heatmap_snapshots WHERE entity_id = $1`, person.ID())
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&count))
+		}
+		rows.Close()
+		return count
+	}
+
+	a.Equal(0, snapshotCount(), "no snapshot before the first view")
+	resp, err := env.API.Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "heatmap should load: %s", resp.String())
+	a.Equal(1, snapshotCount(), "first view should store a snapshot")
+
+	// Mark the snapshot so the test can tell when it is served
+	const marker = "#0badf0"
+	_, err = env.DB.Exec(ctx,
+		`UPDATE load_calendar_data.heatmap_snapshots
+		 SET days = jsonb_set(days, '{0,color}', to_jsonb($2::text))
+		 WHERE entity_id = $1`, person.ID(), marker)
+	a.NoError(err)
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), marker, "unchanged heatmap should be served from its snapshot")
+
+	resp, err = env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "snapshot-load",
+		"title":       "Snapshot load",
+		"date":        time.Now().AddDate(0, 0, 2).Format("2006-01-02"),
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	resp, err = env.API.Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NotContains(resp.String(), marker, "a load write should recompute the heatmap")
+	a.Equal(1, snapshotCount(), "recompute should replace the snapshot")
+}
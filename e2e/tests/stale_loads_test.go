@@ -0,0 +1,127 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestStaleLoadReview verifies that loads flagged stale are listed for
+// review, that a source upserting a load again clears its flag, and that bulk
+// removal only deletes loads still flagged.
+func TestStaleLoadReview(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("stale-person@example.com").WithCapacity(5)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID, source string) int {
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Stale " + externalID,
+			"source":      source,
+			"date":        tomorrow,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 1}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+		var r struct {
+			LoadID int `json:"load_id"`
+		}
+		a.NoError(resp.JSON(&r))
+		return r.LoadID
+	}
+	// Stand in for the stale load check, which runs on an interval
+	flag := func(externalIDs ...string) {
+		_, err := env.DB.Exec(ctx, `
+			UPDATE load_calendar_data.loads
+			SET last_seen_at = NOW() - INTERVAL '10 days', stale_since = NOW()
+			WHERE external_id = ANY($1)
+		`, externalIDs)
+		a.NoError(err, "should flag loads")
+	}
+	type report struct {
+		Sources []struct {
+			Source string `json:"source"`
+			Loads  int    `json:"loads"`
+		} `json:"sources"`
+		Loads []struct {
+			Load struct {
+				ID         int    `json:"id"`
+				ExternalID string `json:"external_id"`
+			} `json:"load"`
+			Assignments []struct {
+				PersonEmail string `json:"person_email"`
+			} `json:"assignments"`
+		} `json:"loads"`
+	}
+	review := func(query string) report {
+		resp, err := env.API.Call("GET", "/api/v1/loads/stale"+query, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "review should succeed: %s", resp.String())
+		var r report
+		a.NoError(resp.JSON(&r))
+		return r
+	}
+
+	dead := upsert("gcal-dead", "gcal")
+	revived := upsert("gcal-revived", "gcal")
+	other := upsert("jira-dead", "jira")
+	fresh := upsert("gcal-fresh", "gcal")
+	a.Empty(review("").Loads, "nothing is flagged yet")
+
+	flag("gcal-dead", "gcal-revived", "jira-dead")
+
+	r := review("")
+	a.Len(r.Loads, 3)
+	a.Len(r.Sources, 2)
+	a.Equal("gcal", r.Sources[0].Source)
+	a.Equal(2, r.Sources[0].Loads)
+	a.Equal(person.ID(), r.Loads[0].Assignments[0].PersonEmail)
+
+	r = review("?source=jira")
+	a.Len(r.Loads, 1)
+	a.Equal("jira-dead", r.Loads[0].Load.ExternalID)
+
+	// The source sends one load again after it was reviewed
+	upsert("gcal-revived", "gcal")
+	a.Len(review("?source=gcal").Loads, 1, "upserting again clears the flag")
+
+	resp, err := env.API.Call("POST", "/api/v1/loads/stale/delete", map[string]interface{}{
+		"load_ids": []int{dead, revived, fresh},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "delete should succeed: %s", resp.String())
+	var result struct {
+		Deleted []struct {
+			LoadID     int    `json:"load_id"`
+			ExternalID string `json:"external_id"`
+		} `json:"deleted"`
+		Skipped []int `json:"skipped"`
+	}
+	a.NoError(resp.JSON(&result))
+	a.Len(result.Deleted, 1)
+	a.Equal(dead, result.Deleted[0].LoadID)
+	a.Equal("gcal-dead", result.Deleted[0].ExternalID)
+	a.Equal([]int{revived, fresh}, result.Skipped, "live loads are never removed")
+
+	var remaining int
+	a.NoError(env.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM load_calendar_data.loads WHERE id = ANY($1)
+	`, []int{dead, revived, other, fresh}).Scan(&remaining))
+	a.Equal(3, remaining)
+
+	resp, err = env.API.Call("POST", "/api/v1/loads/stale/delete", map[string]interface{}{"load_ids": []int{}})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "load_ids are required")
+}
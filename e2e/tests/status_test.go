@@ -0,0 +1,97 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestStatusPage verifies that /status shows anyone the database and source
+// syncs, and that only admins may post, resolve and delete incident notes.
+func TestStatusPage(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("status-person@example.com")
+	load := fixtures.NewLoad("status-load").OnDate(time.Now().UTC()).AssignedTo(person, 1)
+	a.NoError(fixtures.NewScenario().Add(person, load).Insert(ctx, env.DB), "should seed scenario")
+
+	client := func(email string) *helpers.APIClient {
+		if email == "" {
+			return helpers.NewAPIClient(env.ServiceURL())
+		}
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		return session.Client(env.ServiceURL())
+	}
+	anonymous, user, admin := client(""), client(person.ID()), client(testenv.AdminEmail)
+
+	resp, err := anonymous.Call("GET", "/status", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	page := resp.String()
+	a.Contains(page, "All systems operational")
+	a.Contains(page, "Connected")
+	a.Contains(page, "No recent incidents.")
+	a.NotContains(page, "Post incident", "only admins get the incident form")
+
+	// Only admins edit incident notes
+	resp, err = anonymous.Call("POST", "/api/status/incidents", map[string]string{"body": "Down"})
+	a.NoError(err)
+	a.Equal(http.StatusUnauthorized, resp.StatusCode)
+	resp, err = user.Call("POST", "/api/status/incidents", map[string]string{"body": "Down"})
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode, "non-admins may not post: %s", resp.String())
+
+	resp, err = admin.Call("POST", "/api/status/incidents", map[string]string{"body": "  "})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "blank notes are rejected")
+	resp, err = admin.Call("POST", "/api/status/incidents", map[string]string{"body": "Jira sync is delayed"})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "admins may post: %s", resp.String())
+	var incident struct {
+		ID          int        `json:"id"`
+		AuthorEmail string     `json:"author_email"`
+		ResolvedAt  *time.Time `json:"resolved_at"`
+	}
+	a.NoError(resp.JSON(&incident))
+	a.Equal(testenv.AdminEmail, incident.AuthorEmail)
+	a.Nil(incident.ResolvedAt, "incidents start open")
+	path := "/api/status/incidents/" + strconv.Itoa(incident.ID)
+
+	resp, err = anonymous.Call("GET", "/status", nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "Jira sync is delayed")
+	a.Contains(resp.String(), "open")
+
+	resp, err = user.Call("PUT", path, map[string]interface{}{"resolved": true})
+	a.NoError(err)
+	a.Equal(http.StatusForbidden, resp.StatusCode)
+	resp, err = admin.Call("PUT", path, map[string]interface{}{"resolved": true})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "admins may resolve: %s", resp.String())
+	a.NoError(resp.JSON(&incident))
+	a.NotNil(incident.ResolvedAt)
+
+	resp, err = admin.Call("GET", "/status", nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "Post incident", "admins get the incident form")
+	a.Contains(resp.String(), "Reopen")
+
+	resp, err = admin.Call("DELETE", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	resp, err = admin.Call("DELETE", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,91 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestSuggestAssignee verifies that suggestions only include active persons
+// with the skill, rank them by remaining capacity on the date, honour
+// capacity overrides, and leave out anyone the weight would overload.
+func TestSuggestAssignee(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	date := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+
+	create := func(email string, skills ...string) {
+		resp, err := env.API.Call("POST", "/api/entities", map[string]interface{}{
+			"id":               email,
+			"title":            email,
+			"type":             "person",
+			"default_capacity": 5,
+			"skills":           skills,
+		})
+		a.NoError(err)
+		a.Equal(http.StatusCreated, resp.StatusCode, "create should succeed: %s", resp.String())
+	}
+	create("idle@example.com", "Go", "postgres")
+	create("loaded@example.com", "go")
+	create("full@example.com", "go")
+	create("dayoff@example.com", "go")
+	create("designer@example.com", "figma")
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "suggest-load",
+		"title":       "Existing work",
+		"date":        date,
+		"assignees": []map[string]interface{}{
+			{"email": "loaded@example.com", "weight": 2},
+			{"email": "full@example.com", "weight": 4.5},
+		},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	_, err = env.DB.Exec(ctx,
+		`INSERT INTO load_calendar_data.capacity_overrides (entity_id, date, capacity) VALUES ($1, $2, 0)`,
+		"dayoff@example.com", date)
+	a.NoError(err)
+
+	resp, err = env.API.Call("POST", "/api/suggest-assignee", map[string]interface{}{
+		"date":   date,
+		"weight": 1,
+		"skill":  " GO ",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "suggest should succeed: %s", resp.String())
+
+	var result struct {
+		Skill       string `json:"skill"`
+		Suggestions []struct {
+			Email     string  `json:"email"`
+			Remaining float64 `json:"remaining"`
+		} `json:"suggestions"`
+	}
+	a.NoError(resp.JSON(&result))
+	a.Equal("go", result.Skill, "skill should be matched case-insensitively")
+	// Only people with room for the weight are suggested
+	if len(result.Suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", result.Suggestions)
+	}
+	a.Equal("idle@example.com", result.Suggestions[0].Email)
+	a.Equal(5.0, result.Suggestions[0].Remaining)
+	a.Equal("loaded@example.com", result.Suggestions[1].Email)
+	a.Equal(3.0, result.Suggestions[1].Remaining)
+
+	resp, err = env.API.Call("POST", "/api/suggest-assignee", map[string]interface{}{
+		"date":  "next week",
+		"skill": "go",
+	})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "invalid date should be rejected")
+}
@@ -0,0 +1,175 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestUpsertCreatesMissingAssignees verifies that upserting a load creates
+// persons for unknown assignees in the same request, leaves existing
+// entities untouched, and assigns the load to both.
+func TestUpsertCreatesMissingAssignees(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	existing := fixtures.NewPerson("existing@example.com").WithTitle("Existing Person").WithCapacity(3)
+	a.NoError(existing.Insert(ctx, env.DB), "should seed existing person")
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "upsert-new-assignees",
+		"title":       "Mixed assignees",
+		"date":        time.Now().AddDate(0, 0, 1).Format("2006-01-02"),
+		"assignees": []map[string]interface{}{
+			{"email": existing.ID(), "weight": 1},
+			{"email": "newcomer@example.com", "weight": 2},
+			{"email": "other-newcomer@example.com"},
+		},
+	})
+	a.NoError(err)
+	a.Equal(200, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	type entityRow struct {
+		title    string
+		kind     string
+		capacity float64
+	}
+	entities := map[string]entityRow{}
+	rows, err := env.DB.Query(ctx,
+		`SELECT id, title, type, default_capacity FROM load_calendar_data.entities`)
+	a.NoError(err)
+	for rows.Next() {
+		var id string
+		var row entityRow
+		a.NoError(rows.Scan(&id, &row.title, &row.kind, &row.capacity))
+		entities[id] = row
+	}
+	rows.Close()
+
+	a.Len(entities, 3, "two missing assignees should be created")
+	a.Equal(entityRow{"Existing Person", "person", 3}, entities[existing.ID()], "existing person should be untouched")
+	a.Equal(entityRow{"newcomer@example.com", "person", 5}, entities["newcomer@example.com"])
+	a.Equal(entityRow{"other-newcomer@example.com", "person", 5}, entities["other-newcomer@example.com"])
+
+	var assignments int
+	rows, err = env.DB.Query(ctx, `SELECT COUNT(*) FROM load_calendar_data.load_assignments`)
+	a.NoError(err)
+	if rows.Next() {
+		a.NoError(rows.Scan(&assignments))
+	}
+	rows.Close()
+	a.Equal(3, assignments, "load should be assigned to all three")
+}
+
+// TestUpsertAppliesWeightRules verifies that assignees without a weight get
+// one from the configured rules (weight_rules.example.json) by source,
+// duration, and all-day flag, while explicit weights are kept.
+func TestUpsertAppliesWeightRules(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	date := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	upsert := func(externalID string, fields map[string]interface{}, assignees ...map[string]interface{}) {
+		body := map[string]interface{}{
+			"external_id": externalID,
+			"title":       externalID,
+			"date":        date,
+			"assignees":   assignees,
+		}
+		for k, v := range fields {
+			body[k] = v
+		}
+		resp, err := env.API.Call("POST", "/api/loads/upsert", body)
+		a.NoError(err)
+		a.Equal(200, resp.StatusCode, "upsert should succeed: %s", resp.String())
+	}
+
+	upsert("rules-standup", map[string]interface{}{"source": "gcal", "duration_minutes": 30},
+		map[string]interface{}{"email": "ruled@example.com"},
+		map[string]interface{}{"email": "explicit@example.com", "weight": 3})
+	upsert("rules-offsite", map[string]interface{}{"source": "gcal", "all_day": true},
+		map[string]interface{}{"email": "ruled@example.com"})
+	upsert("rules-unknown", map[string]interface{}{"source": "github"},
+		map[string]interface{}{"email": "ruled@example.com"})
+
+	weights := map[string]float64{}
+	rows, err := env.DB.Query(ctx, `
+		SELECT l.external_id || '/' || la.person_email, la.weight
+		FROM load_calendar_data.load_assignments la
+		JOIN load_calendar_data.loads l ON l.id = la.load_id
+	`)
+	a.NoError(err)
+	for rows.Next() {
+		var key string
+		var weight float64
+		a.NoError(rows.Scan(&key, &weight))
+		weights[key] = weight
+	}
+	rows.Close()
+
+	a.Equal(map[string]float64{
+		"rules-standup/ruled@example.com":    0.25,
+		"rules-standup/explicit@example.com": 3,
+		"rules-offsite/ruled@example.com":    2,
+		"rules-unknown/ruled@example.com":    1,
+	}, weights)
+}
+
+// TestUpsertReportsAssigneeLoads verifies that an upsert answers with each
+// assignee's resulting load and capacity on each day of the load, flagging
+// those it puts over capacity.
+func TestUpsertReportsAssigneeLoads(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	start := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	busy := fixtures.NewPerson("soft-limit-busy@example.com").WithCapacity(2)
+	free := fixtures.NewPerson("soft-limit-free@example.com").WithCapacity(8).WithCapacityOn(start.AddDate(0, 0, 1), 0)
+	existing := fixtures.NewLoad("soft-limit-existing").OnDate(start).AssignedTo(busy, 1.5)
+	a.NoError(fixtures.NewScenario().Add(busy, free, existing).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "soft-limit-new",
+		"title":       "Two-day review",
+		"date":        start.Format("2006-01-02"),
+		"end_date":    start.AddDate(0, 0, 1).Format("2006-01-02"),
+		"assignees": []map[string]interface{}{
+			{"email": busy.ID(), "weight": 2},
+			{"email": free.ID(), "weight": 2},
+		},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	type assigneeLoad struct {
+		PersonEmail string  `json:"person_email"`
+		Date        string  `json:"date"`
+		Load        float64 `json:"load"`
+		Capacity    float64 `json:"capacity"`
+		Overloaded  bool    `json:"overloaded"`
+	}
+	var result struct {
+		Assignees []assigneeLoad `json:"assignees"`
+	}
+	a.NoError(resp.JSON(&result))
+
+	day := func(offset int) string { return start.AddDate(0, 0, offset).Format("2006-01-02") }
+	a.Equal([]assigneeLoad{
+		{busy.ID(), day(0), 2.5, 2, true},
+		{busy.ID(), day(1), 1, 2, false},
+		{free.ID(), day(0), 1, 8, false},
+		{free.ID(), day(1), 1, 0, true},
+	}, result.Assignees, "the weight is split across the days, on top of existing loads")
+}
@@ -0,0 +1,96 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestUtilizationAPI verifies that per-day utilization rows are listed per
+// entity and day, paged with a cursor, and limited to a group-scoped API
+// key's groups.
+func TestUtilizationAPI(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	member := fixtures.NewPerson("utilization-member@example.com").WithCapacity(5)
+	idle := fixtures.NewPerson("utilization-idle@example.com").WithCapacity(0)
+	team := fixtures.NewGroup(testenv.GroupAPIKeyGroup).WithMembers(member)
+	a.NoError(fixtures.NewScenario().Add(member, idle, team).Insert(ctx, env.DB), "should seed scenario")
+
+	from := time.Now().UTC().AddDate(0, 0, 3)
+	to := from.AddDate(0, 0, 2)
+	resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "utilization-review", "title": "Review", "date": from.Format("2006-01-02"),
+		"assignees": []map[string]interface{}{{"email": member.ID(), "weight": 2}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "upsert should succeed: %s", resp.String())
+
+	type utilizationList struct {
+		Rows []struct {
+			Entity   string   `json:"entity"`
+			Date     string   `json:"date"`
+			Load     float64  `json:"load"`
+			Capacity float64  `json:"capacity"`
+			Ratio    *float64 `json:"ratio"`
+		} `json:"rows"`
+		NextCursor string `json:"next_cursor"`
+	}
+	list := func(client *helpers.APIClient, entities, cursor string, limit string) (*helpers.Response, utilizationList) {
+		query := url.Values{
+			"entities": {entities},
+			"from":     {from.Format("2006-01-02")},
+			"to":       {to.Format("2006-01-02")},
+			"cursor":   {cursor},
+			"limit":    {limit},
+		}
+		resp, err := client.Call("GET", "/api/utilization?"+query.Encode(), nil)
+		a.NoError(err)
+		var page utilizationList
+		if resp.StatusCode == http.StatusOK {
+			a.NoError(resp.JSON(&page))
+		}
+		return resp, page
+	}
+
+	resp, first := list(env.API, member.ID()+","+idle.ID(), "", "4")
+	a.Equal(http.StatusOK, resp.StatusCode, "should list utilization: %s", resp.String())
+	a.Len(first.Rows, 4, "a page holds at most limit rows")
+	a.NotEqual("", first.NextCursor, "more rows follow")
+	a.Equal(member.ID(), first.Rows[0].Entity, "rows are ordered by entity as listed")
+	a.Equal(from.Format("2006-01-02"), first.Rows[0].Date, "then by date")
+	a.Equal(2.0, first.Rows[0].Load)
+	a.Equal(5.0, first.Rows[0].Capacity)
+	a.NotNil(first.Rows[0].Ratio)
+	a.Equal(0.4, *first.Rows[0].Ratio)
+	a.Equal(idle.ID(), first.Rows[3].Entity)
+
+	resp, second := list(env.API, member.ID()+","+idle.ID(), first.NextCursor, "4")
+	a.Equal(http.StatusOK, resp.StatusCode, "should list the next page: %s", resp.String())
+	a.Len(second.Rows, 2, "the next page continues after the cursor")
+	a.Equal("", second.NextCursor, "no rows follow the last page")
+	a.Equal(idle.ID(), second.Rows[0].Entity)
+	a.Nil(second.Rows[0].Ratio, "days without capacity have no ratio")
+
+	resp, _ = list(env.API, member.ID(), "not-a-cursor", "")
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "unknown cursors are rejected")
+
+	scoped := helpers.NewAPIClient(env.ServiceURL())
+	scoped.SetHeader("x-api-key", testenv.GroupAPIKey)
+	resp, page := list(scoped, team.ID()+","+member.ID(), "", "")
+	a.Equal(http.StatusOK, resp.StatusCode, "a scoped key may list its group and members: %s", resp.String())
+	a.Len(page.Rows, 6)
+	resp, _ = list(scoped, member.ID()+","+idle.ID(), "", "")
+	a.Equal(http.StatusForbidden, resp.StatusCode, "a scoped key may not list people outside its groups")
+}
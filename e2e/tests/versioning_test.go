@@ -0,0 +1,70 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestAPIVersions verifies that integration routes are served under /api/v1
+// unchanged and under /api/v2 with structured errors, while the unversioned
+// paths keep working but announce their deprecation.
+func TestAPIVersions(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("versioned-person@example.com")
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	blackouts := "/entities/" + person.ID() + "/blackouts"
+
+	resp, err := env.API.Call("GET", "/api"+blackouts, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "unversioned routes keep working")
+	a.Equal("true", resp.Headers.Get("Deprecation"))
+	a.Equal(`</api/v1`+blackouts+`>; rel="successor-version"`, resp.Headers.Get("Link"))
+
+	resp, err = env.API.Call("GET", "/api/v1"+blackouts, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Empty(resp.Headers.Get("Deprecation"), "v1 is not deprecated")
+
+	missing := map[string]string{"title": "Nobody"}
+	resp, err = env.API.Call("PUT", "/api/v1/entities/versioned-missing@example.com", missing)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+	var flat struct {
+		Error string `json:"error"`
+	}
+	a.NoError(resp.JSON(&flat), "v1 errors keep the flat shape")
+	a.NotEmpty(flat.Error)
+
+	resp, err = env.API.Call("PUT", "/api/v2/entities/versioned-missing@example.com", missing)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+	var envelope struct {
+		Error struct {
+			Status  int    `json:"status"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	a.NoError(resp.JSON(&envelope), "v2 errors are structured: %s", resp.String())
+	a.Equal(http.StatusNotFound, envelope.Error.Status)
+	a.Equal("not_found", envelope.Error.Code)
+	a.Equal(flat.Error, envelope.Error.Message)
+
+	anonymous := helpers.NewAPIClient(env.ServiceURL())
+	resp, err = anonymous.Call("GET", "/api/v2"+blackouts, nil)
+	a.NoError(err)
+	a.Equal(http.StatusUnauthorized, resp.StatusCode)
+	a.NoError(resp.JSON(&envelope))
+	a.Equal("unauthorized", envelope.Error.Code, "authentication errors use the envelope too")
+}
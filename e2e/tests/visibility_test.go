@@ -0,0 +1,122 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestPrivateHeatmap verifies that a private person's heatmap is only
+// served to themselves, owners of their groups and admins, that they are left
+// out of the public selector and listings, and that their assignments are
+// hidden from others' day details.
+func TestPrivateHeatmap(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	private := fixtures.NewPerson("private-person@example.com").WithTitle("Private Person").WithCapacity(5)
+	colleague := fixtures.NewPerson("private-colleague@example.com").WithTitle("Private Colleague").WithCapacity(5)
+	owner := fixtures.NewPerson("private-owner@example.com").WithCapacity(5)
+	group := fixtures.NewGroup("private-team").WithMembers(private, colleague, owner)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	load := fixtures.NewLoad("private-shared").WithTitle("Shared Load").OnDate(tomorrow).
+		AssignedTo(private, 2).AssignedTo(colleague, 1)
+	a.NoError(fixtures.NewScenario().Add(private, colleague, owner, group, load).Insert(ctx, env.DB), "should seed scenario")
+
+	resp, err := env.API.Call("POST", "/api/groups/"+group.ID()+"/owners", map[string]string{"person_email": owner.ID()})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should add owner: %s", resp.String())
+
+	client := func(email string) *helpers.APIClient {
+		if email == "" {
+			return helpers.NewAPIClient(env.ServiceURL())
+		}
+		session := fixtures.NewSession(email)
+		a.NoError(session.Insert(ctx, env.DB), "should create session")
+		return session.Client(env.ServiceURL())
+	}
+	anonymous, self, other, groupOwner, admin := client(""), client(private.ID()), client(colleague.ID()), client(owner.ID()), client(testenv.AdminEmail)
+
+	resp, err = self.Call("PUT", "/api/my-preferences", map[string]interface{}{"private": true})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "should make the heatmap private: %s", resp.String())
+	var prefs struct {
+		Private bool `json:"private"`
+	}
+	a.NoError(resp.JSON(&prefs))
+	a.True(prefs.Private)
+
+	heatmap := "/api/heatmap/" + private.ID()
+	dayDetails := heatmap + "/day/" + tomorrow.Format("2006-01-02")
+	for name, c := range map[string]*helpers.APIClient{"anonymous": anonymous, "colleague": other} {
+		for _, path := range []string{heatmap, heatmap + "/json", dayDetails, "/api/entities/" + private.ID()} {
+			resp, err := c.Call("GET", path, nil)
+			a.NoError(err)
+			a.Equal(http.StatusNotFound, resp.StatusCode, "%s should not see %s", name, path)
+		}
+
+		resp, err := c.Call("GET", "/", nil)
+		a.NoError(err)
+		a.NotContains(resp.String(), "Private Person", "%s's selector should leave the private person out", name)
+		a.Contains(resp.String(), "Private Colleague")
+
+		resp, err = c.Call("GET", "/api/entities?type=person", nil)
+		a.NoError(err)
+		a.NotContains(resp.String(), private.ID(), "%s's listing should leave the private person out", name)
+	}
+	for name, c := range map[string]*helpers.APIClient{"self": self, "group owner": groupOwner, "admin": admin} {
+		resp, err := c.Call("GET", heatmap, nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "%s should see the heatmap", name)
+
+		resp, err = c.Call("GET", "/", nil)
+		a.NoError(err)
+		a.Contains(resp.String(), "Private Person", "%s's selector should list the private person", name)
+	}
+
+	// The shared load stays on the colleague's day, without the private
+	// person's assignment, though the day's total still counts it
+	var day struct {
+		TotalLoad float64 `json:"total_load"`
+		Loads     []struct {
+			Assignments []struct {
+				PersonEmail string `json:"person_email"`
+			} `json:"assignments"`
+		} `json:"loads"`
+	}
+	colleagueDay := "/api/heatmap/" + colleague.ID() + "/day/" + tomorrow.Format("2006-01-02")
+	other.SetHeader("Accept", "application/json")
+	resp, err = other.Call("GET", colleagueDay, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&day))
+	a.Len(day.Loads, 1)
+	a.Len(day.Loads[0].Assignments, 1)
+	a.Equal(colleague.ID(), day.Loads[0].Assignments[0].PersonEmail)
+
+	groupOwner.SetHeader("Accept", "application/json")
+	resp, err = groupOwner.Call("GET", colleagueDay, nil)
+	a.NoError(err)
+	a.NoError(resp.JSON(&day))
+	a.Len(day.Loads[0].Assignments, 2, "the group owner sees every assignee")
+
+	resp, err = env.API.Call("PUT", "/api/entities/"+group.ID(), map[string]interface{}{"private": true})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "groups cannot be private")
+
+	resp, err = env.API.Call("PUT", "/api/entities/"+private.ID(), map[string]interface{}{"private": false})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	resp, err = anonymous.Call("GET", heatmap, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "a public heatmap is visible to everyone again")
+}
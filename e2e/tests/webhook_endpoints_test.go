@@ -0,0 +1,137 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/e2e/testenv"
+)
+
+// TestWebhookEndpoints verifies that webhook endpoints added through the API
+// receive only the events they subscribe to, and nothing once disabled or
+// deleted.
+func TestWebhookEndpoints(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	receiver := testenv.StartWebhookReceiver()
+	defer receiver.Close()
+
+	person := fixtures.NewPerson("subscribed@example.com").WithCapacity(1)
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	resp, err := env.API.Call("POST", "/api/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"load_exploded"},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "unknown event: %s", resp.String())
+
+	resp, err = env.API.Call("POST", "/api/webhooks", map[string]interface{}{
+		"url":         receiver.URL,
+		"description": "Load feed",
+		"events":      []string{"load_created", "capacity_changed", "load_created"},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusCreated, resp.StatusCode, "create should return 201: %s", resp.String())
+	var endpoint struct {
+		ID      int      `json:"id"`
+		URL     string   `json:"url"`
+		Events  []string `json:"events"`
+		Enabled bool     `json:"enabled"`
+	}
+	a.NoError(resp.JSON(&endpoint))
+	a.Equal([]string{"capacity_changed", "load_created"}, endpoint.Events, "events are kept once each")
+	a.True(endpoint.Enabled, "endpoints start enabled")
+	path := "/api/webhooks/" + strconv.Itoa(endpoint.ID)
+
+	resp, err = env.API.Call("GET", "/api/webhooks", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.Contains(resp.String(), receiver.URL)
+
+	upsert := func(externalID string) {
+		t.Helper()
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Subscribed " + externalID,
+			"date":        tomorrow,
+			"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 2}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "upsert should return 200: %s", resp.String())
+	}
+	// received counts the events the endpoint got, by event and external ID
+	received := func() map[string]int {
+		found := make(map[string]int)
+		for _, req := range receiver.Requests() {
+			var body struct {
+				Event      string `json:"event"`
+				ExternalID string `json:"external_id"`
+			}
+			if json.Unmarshal(req.Body, &body) == nil {
+				found[body.Event+" "+body.ExternalID]++
+			}
+		}
+		return found
+	}
+
+	// A new load is announced once; updating it is not, and the overload it
+	// causes goes only to WEBHOOK_DESTINATION_URL
+	upsert("endpoint-1")
+	upsert("endpoint-1")
+	deadline := time.Now().Add(5 * time.Second)
+	for received()["load_created endpoint-1"] == 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	time.Sleep(500 * time.Millisecond)
+	got := received()
+	a.Equal(1, got["load_created endpoint-1"], "the created load should be sent once: %v", got)
+	a.Len(receiver.Requests(), 1, "unsubscribed events are not sent: %v", got)
+
+	// Deliveries are recorded against the endpoint
+	var recorded int
+	rows, err := env.DB.Query(ctx,
+		`SELECT COUNT(*) FROM load_calendar_data.webhook_deliveries WHERE endpoint_id = $1 AND event = 'load_created'`,
+		endpoint.ID)
+	a.NoError(err)
+	if rows.Next() {
+		a.NoError(rows.Scan(&recorded))
+	}
+	rows.Close()
+	a.Equal(1, recorded)
+
+	// Disabled endpoints receive nothing
+	resp, err = env.API.Call("PUT", path, map[string]interface{}{"enabled": false})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "update should return 200: %s", resp.String())
+	a.NoError(resp.JSON(&endpoint))
+	a.False(endpoint.Enabled)
+	a.Equal([]string{"capacity_changed", "load_created"}, endpoint.Events, "omitted fields are kept")
+
+	upsert("endpoint-2")
+	time.Sleep(500 * time.Millisecond)
+	a.Equal(0, received()["load_created endpoint-2"], "a disabled endpoint should not be sent events")
+
+	resp, err = env.API.Call("DELETE", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "delete should return 200: %s", resp.String())
+
+	resp, err = env.API.Call("GET", path, nil)
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+	resp, err = env.API.Call("PUT", path, map[string]interface{}{"enabled": true})
+	a.NoError(err)
+	a.Equal(http.StatusNotFound, resp.StatusCode)
+}
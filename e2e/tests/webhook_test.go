@@ -0,0 +1,126 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestOverloadAlertWebhook verifies that upserting a load that pushes a person
+// over capacity posts an alert to the webhook destination, and that loads
+// within capacity do not.
+func TestOverloadAlertWebhook(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	if env.Webhooks == nil {
+		t.Skip("service is not wired to a webhook receiver")
+	}
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	busy := fixtures.NewPerson("busy@example.com").WithCapacity(2)
+	idle := fixtures.NewPerson("idle@example.com").WithCapacity(5)
+	a.NoError(fixtures.NewScenario().Add(busy, idle).Insert(ctx, env.DB), "should seed persons")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	upsert := func(externalID, email string, weight float64) {
+		t.Helper()
+		resp, err := env.API.Call("POST", "/api/loads/upsert", map[string]interface{}{
+			"external_id": externalID,
+			"title":       "Webhook " + externalID,
+			"date":        tomorrow,
+			"assignees":   []map[string]interface{}{{"email": email, "weight": weight}},
+		})
+		a.NoError(err, "upsert should succeed")
+		a.Equal(200, resp.StatusCode, "upsert should return 200: %s", resp.String())
+	}
+
+	upsert("webhook-idle", idle.ID(), 1)
+	upsert("webhook-busy", busy.ID(), 3)
+
+	alert, err := env.Webhooks.WaitForAlert(busy.ID(), tomorrow, 10*time.Second)
+	a.NoError(err, "overloaded person should trigger an alert")
+	if alert != nil {
+		a.Equal(3.0, alert.Load, "alert should report total load")
+		a.Equal(2.0, alert.Capacity, "alert should report capacity")
+		a.Contains(alert.Message, "overloaded")
+	}
+	a.True(env.Webhooks.ReceivedAlertFor(busy.ID(), tomorrow))
+
+	// Give the idle person's check time to finish before asserting silence
+	time.Sleep(500 * time.Millisecond)
+	a.False(env.Webhooks.ReceivedAlertFor(idle.ID(), tomorrow), "person within capacity should not trigger an alert")
+}
+
+// TestWebhookTraceIDs verifies that an alert carries the trace of the upsert
+// that caused it, continuing the caller's traceparent, in its metadata, its
+// traceparent header and its delivery log row.
+func TestWebhookTraceIDs(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	if env.Webhooks == nil {
+		t.Skip("service is not wired to a webhook receiver")
+	}
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+	env.Webhooks.Reset()
+
+	person := fixtures.NewPerson("traced@example.com").WithCapacity(1)
+	a.NoError(person.Insert(ctx, env.DB), "should seed person")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	client := helpers.NewAPIClient(env.ServiceURL())
+	client.SetHeader("x-api-key", env.Config.Service.APIKey)
+	client.SetHeader("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	resp, err := client.Call("POST", "/api/loads/upsert", map[string]interface{}{
+		"external_id": "webhook-traced",
+		"title":       "Traced",
+		"date":        tomorrow,
+		"assignees":   []map[string]interface{}{{"email": person.ID(), "weight": 3}},
+	})
+	a.NoError(err)
+	a.Equal(200, resp.StatusCode, "upsert should return 200: %s", resp.String())
+	traceparent := resp.Headers.Get("traceparent")
+	a.True(strings.HasPrefix(traceparent, "00-"+traceID+"-"), "response should continue the caller's trace: %s", traceparent)
+	spanID := strings.Split(traceparent, "-")[2]
+
+	alert, err := env.Webhooks.WaitForAlert(person.ID(), tomorrow, 10*time.Second)
+	a.NoError(err, "overloaded person should trigger an alert")
+	if alert != nil && a.NotNil(alert.Metadata) {
+		a.Equal(traceID, alert.Metadata.TraceID)
+		a.Equal(spanID, alert.Metadata.SpanID, "the alert should name the upsert's span")
+	}
+	requests := env.Webhooks.Requests()
+	if a.NotEmpty(requests) {
+		a.Equal(traceparent, requests[len(requests)-1].Header.Get("traceparent"))
+	}
+
+	// The delivery row is written once the receiver has answered
+	var recorded int
+	for i := 0; i < 50 && recorded == 0; i++ {
+		rows, err := env.DB.Query(ctx,
+			`SELECT COUNT(*) FROM load_calendar_data.webhook_deliveries
+			 WHERE event = 'overload_alert' AND trace_id = $1 AND span_id = $2`,
+			traceID, spanID)
+		a.NoError(err)
+		if rows.Next() {
+			a.NoError(rows.Scan(&recorded))
+		}
+		rows.Close()
+		if recorded == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	a.Equal(1, recorded, "the delivery should be logged with the trace")
+}
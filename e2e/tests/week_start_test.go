@@ -0,0 +1,92 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestWeekStartPreference verifies that heatmap grids start weeks on
+// Monday by default, on the viewer's chosen weekday otherwise, and label
+// each row with its ISO week number.
+func TestWeekStartPreference(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	person := fixtures.NewPerson("week-start-person@example.com").WithCapacity(4)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(person.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+
+	client := func(loggedIn bool) *helpers.APIClient {
+		if loggedIn {
+			return session.Client(env.ServiceURL())
+		}
+		return helpers.NewAPIClient(env.ServiceURL())
+	}
+	// The first heading of each month's grid names the week start
+	firstColumn := func(body string) string {
+		const heading = `text-gray-400 text-center">`
+		i := strings.Index(body, heading)
+		if i < 0 {
+			return ""
+		}
+		return body[i+len(heading) : i+len(heading)+2]
+	}
+
+	_, thisWeek := time.Now().UTC().ISOWeek()
+	anonymous, err := client(false).Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, anonymous.StatusCode)
+	a.Equal("Mo", firstColumn(anonymous.String()), "weeks start on Monday by default")
+	a.Contains(anonymous.String(), fmt.Sprintf(">W%d<", thisWeek), "rows carry ISO week numbers")
+
+	resp, err := client(true).Call("GET", "/api/my-preferences", nil)
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	var prefs struct {
+		WeekStart string `json:"week_start"`
+		Custom    bool   `json:"custom"`
+	}
+	a.NoError(resp.JSON(&prefs))
+	a.Equal("monday", prefs.WeekStart)
+	a.False(prefs.Custom)
+
+	resp, err = client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"week_start": "Sunday"})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode, "update should succeed: %s", resp.String())
+	a.NoError(resp.JSON(&prefs))
+	a.Equal("sunday", prefs.WeekStart)
+	a.True(prefs.Custom)
+
+	mine, err := client(true).Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal("Su", firstColumn(mine.String()), "the viewer's week start applies")
+	a.NotEqual(anonymous.Headers.Get("ETag"), mine.Headers.Get("ETag"), "layouts are tagged apart")
+
+	again, err := client(false).Call("GET", "/api/heatmap/"+person.ID(), nil)
+	a.NoError(err)
+	a.Equal("Mo", firstColumn(again.String()), "other viewers keep their layout")
+
+	resp, err = client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"week_start": "someday"})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = client(true).Call("PUT", "/api/my-preferences", map[string]interface{}{"week_start": nil})
+	a.NoError(err)
+	a.Equal(http.StatusOK, resp.StatusCode)
+	a.NoError(resp.JSON(&prefs))
+	a.Equal("monday", prefs.WeekStart, "null follows the server default again")
+	a.False(prefs.Custom)
+}
@@ -0,0 +1,74 @@
+//go:build e2e
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/fixtures"
+	"github.com/gti/heatmap-internal/e2e/helpers"
+)
+
+// TestWeeklyCapacity verifies that a weekly pattern sets a person's capacity
+// on its weekdays, that date overrides still win, and that clearing a weekday
+// restores the default capacity.
+func TestWeeklyCapacity(t *testing.T) {
+	ctx := context.Background()
+	a := helpers.NewAssert(t)
+
+	a.NoError(env.CleanupTestData(ctx), "cleanup should succeed")
+
+	today := time.Now().UTC()
+	friday := today.AddDate(0, 0, (int(time.Friday)-int(today.Weekday())+7)%7+7)
+	nextFriday := friday.AddDate(0, 0, 7)
+	thursday := friday.AddDate(0, 0, -1)
+
+	person := fixtures.NewPerson("weekly-person@example.com").WithCapacity(5).WithCapacityOn(nextFriday, 1)
+	a.NoError(fixtures.NewScenario().Add(person).Insert(ctx, env.DB), "should seed scenario")
+
+	session := fixtures.NewSession(person.ID())
+	a.NoError(session.Insert(ctx, env.DB), "should create session")
+	client := session.Client(env.ServiceURL())
+
+	setWeekday := func(weekday string, capacity interface{}) {
+		resp, err := client.Call("POST", "/api/my-capacity", map[string]interface{}{
+			"weekly_pattern": []map[string]interface{}{{"weekday": weekday, "capacity": capacity}},
+		})
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode, "pattern update should succeed: %s", resp.String())
+	}
+	capacityOn := func(date time.Time) float64 {
+		c := helpers.NewAPIClient(env.ServiceURL())
+		c.SetHeader("Accept", "application/json")
+		resp, err := c.Call("GET", "/api/heatmap/"+person.ID()+"/day/"+date.Format("2006-01-02"), nil)
+		a.NoError(err)
+		a.Equal(http.StatusOK, resp.StatusCode)
+		var details struct {
+			Capacity float64 `json:"capacity"`
+		}
+		a.NoError(resp.JSON(&details))
+		return details.Capacity
+	}
+
+	setWeekday("friday", 3)
+	a.Equal(3.0, capacityOn(friday), "the pattern sets Friday's capacity")
+	a.Equal(5.0, capacityOn(thursday), "other weekdays keep the default")
+	a.Equal(1.0, capacityOn(nextFriday), "date overrides win over the pattern")
+
+	resp, err := client.Call("GET", "/my-capacity", nil)
+	a.NoError(err)
+	a.Contains(resp.String(), "Weekly Pattern")
+	a.Contains(resp.String(), "friday: 3.0")
+
+	setWeekday("friday", nil)
+	a.Equal(5.0, capacityOn(friday), "clearing the weekday restores the default")
+
+	resp, err = client.Call("POST", "/api/my-capacity", map[string]interface{}{
+		"weekly_pattern": []map[string]interface{}{{"weekday": "fri", "capacity": 3}},
+	})
+	a.NoError(err)
+	a.Equal(http.StatusBadRequest, resp.StatusCode, "unknown weekdays are rejected")
+}
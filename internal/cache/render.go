@@ -0,0 +1,225 @@
+// Package cache holds in-memory caches for rendered pages.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// GroupResolver returns the groups a person belongs to. It matches
+// repository.GroupRepository.GetGroupsForPerson.
+type GroupResolver func(ctx context.Context, personEmail string) ([]string, error)
+
+// Key identifies one rendered heatmap: the entity, the date window it covers,
+// the weekday its weeks start on, and the entity's version when rendering
+// started.
+type Key struct {
+	EntityID  string
+	Start     string
+	End       string
+	WeekStart time.Weekday // set by callers rendering for a viewer's week start
+	Version   uint64
+}
+
+// Stats reports cache effectiveness.
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// RenderCache keeps rendered heatmap partials in memory so that many viewers
+// of the same team heatmap share one render. Writes call Invalidate for the
+// entities they touch, which bumps their version; a render that started
+// before the write is stored under the old version and never served again.
+//
+// Writes that bypass this process (another replica, cmd/demo, manual SQL)
+// cannot invalidate it, so entries also expire after maxAge.
+//
+// A nil *RenderCache is valid and caches nothing.
+type RenderCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxAge     time.Duration
+	groupsOf   GroupResolver
+	now        func() time.Time
+
+	generation uint64
+	versions   map[string]uint64
+
+	order   *list.List // front is most recently used
+	entries map[Key]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type renderEntry struct {
+	key     Key
+	body    []byte
+	expires time.Time
+}
+
+// NewRenderCache creates a cache holding up to maxEntries renders for at most
+// maxAge each, evicting the least recently used. groupsOf is used to
+// invalidate a person's groups along with the person, since group heatmaps
+// sum their members' loads. Returns nil (caching disabled) when maxEntries
+// or maxAge is not positive.
+func NewRenderCache(maxEntries int, maxAge time.Duration, groupsOf GroupResolver) *RenderCache {
+	if maxEntries <= 0 || maxAge <= 0 {
+		return nil
+	}
+	return &RenderCache{
+		maxEntries: maxEntries,
+		maxAge:     maxAge,
+		groupsOf:   groupsOf,
+		now:        time.Now,
+		versions:   make(map[string]uint64),
+		order:      list.New(),
+		entries:    make(map[Key]*list.Element),
+	}
+}
+
+// Key returns the key for an entity's heatmap over [start, end] at its
+// current version. Take the key before loading data so that a concurrent
+// write moves later readers to a new key.
+func (c *RenderCache) Key(entityID string, start, end time.Time) Key {
+	key := Key{
+		EntityID: entityID,
+		Start:    start.Format("2006-01-02"),
+		End:      end.Format("2006-01-02"),
+	}
+	if c == nil {
+		return key
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key.Version = c.generation + c.versions[entityID]
+	return key
+}
+
+// Get returns the cached render for key if it has not expired.
+func (c *RenderCache) Get(key Key) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok && c.now().After(elem.Value.(*renderEntry).expires) {
+		c.remove(elem)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*renderEntry).body, true
+}
+
+// Set stores a render under key. Renders for an outdated version are
+// dropped, since no reader can ask for them any more.
+func (c *RenderCache) Set(key Key, body []byte) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key.Version != c.generation+c.versions[key.EntityID] {
+		return
+	}
+	expires := c.now().Add(c.maxAge)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*renderEntry)
+		entry.body, entry.expires = body, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&renderEntry{key: key, body: body, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate drops cached renders for the given entities and for every
+// group they belong to. Call it after the write has committed. If group
+// membership cannot be read, the whole cache is invalidated instead.
+func (c *RenderCache) Invalidate(ctx context.Context, entityIDs ...string) {
+	if c == nil || len(entityIDs) == 0 {
+		return
+	}
+
+	affected := make(map[string]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		affected[id] = true
+		if c.groupsOf == nil {
+			continue
+		}
+		groups, err := c.groupsOf(ctx, id)
+		if err != nil {
+			log.Printf("Render cache: failed to get groups for %s, invalidating all: %v", id, err)
+			c.InvalidateAll()
+			return
+		}
+		for _, g := range groups {
+			affected[g] = true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range affected {
+		c.versions[id]++
+	}
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if affected[elem.Value.(*renderEntry).key.EntityID] {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// InvalidateAll drops every cached render. Use it for writes whose reach is
+// not known, such as deleting an entity with its memberships.
+func (c *RenderCache) InvalidateAll() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.order.Init()
+	c.entries = make(map[Key]*list.Element)
+}
+
+// Stats returns hit and miss counts and the current number of entries.
+func (c *RenderCache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Hits: c.hits, Misses: c.misses, Entries: c.order.Len()}
+}
+
+// remove deletes an element; the caller holds c.mu.
+func (c *RenderCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*renderEntry).key)
+}
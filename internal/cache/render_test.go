@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	windowStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd   = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func groupsFrom(memberships map[string][]string) GroupResolver {
+	return func(_ context.Context, personEmail string) ([]string, error) {
+		return memberships[personEmail], nil
+	}
+}
+
+func TestRenderCacheHitAndMiss(t *testing.T) {
+	c := NewRenderCache(10, time.Minute, nil)
+
+	key := c.Key("alice@example.com", windowStart, windowEnd)
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	c.Set(key, []byte("<div>alice</div>"))
+	body, ok := c.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "<div>alice</div>", string(body))
+
+	// A different window is a different render
+	_, ok = c.Get(c.Key("alice@example.com", windowStart, windowEnd.AddDate(0, 0, 1)))
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 2, Entries: 1}, c.Stats())
+}
+
+func TestRenderCacheInvalidateIncludesGroups(t *testing.T) {
+	c := NewRenderCache(10, time.Minute, groupsFrom(map[string][]string{
+		"alice@example.com": {"team-a"},
+	}))
+
+	for _, id := range []string{"alice@example.com", "team-a", "team-b"} {
+		c.Set(c.Key(id, windowStart, windowEnd), []byte(id))
+	}
+
+	c.Invalidate(context.Background(), "alice@example.com")
+
+	_, ok := c.Get(c.Key("alice@example.com", windowStart, windowEnd))
+	assert.False(t, ok, "person should be invalidated")
+	_, ok = c.Get(c.Key("team-a", windowStart, windowEnd))
+	assert.False(t, ok, "person's group should be invalidated")
+	_, ok = c.Get(c.Key("team-b", windowStart, windowEnd))
+	assert.True(t, ok, "unrelated group should stay cached")
+}
+
+func TestRenderCacheDropsRendersStartedBeforeWrite(t *testing.T) {
+	c := NewRenderCache(10, time.Minute, nil)
+
+	// A reader takes its key, then a write lands before it stores the render
+	stale := c.Key("team-a", windowStart, windowEnd)
+	c.Invalidate(context.Background(), "team-a")
+	c.Set(stale, []byte("stale"))
+
+	_, ok := c.Get(c.Key("team-a", windowStart, windowEnd))
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestRenderCacheInvalidateAllOnResolverError(t *testing.T) {
+	c := NewRenderCache(10, time.Minute, func(context.Context, string) ([]string, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	c.Set(c.Key("team-b", windowStart, windowEnd), []byte("team-b"))
+	c.Invalidate(context.Background(), "alice@example.com")
+
+	_, ok := c.Get(c.Key("team-b", windowStart, windowEnd))
+	assert.False(t, ok, "unknown memberships should invalidate everything")
+}
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewRenderCache(2, time.Minute, nil)
+
+	a := c.Key("a", windowStart, windowEnd)
+	b := c.Key("b", windowStart, windowEnd)
+	c.Set(a, []byte("a"))
+	c.Set(b, []byte("b"))
+	c.Get(a)
+	c.Set(c.Key("c", windowStart, windowEnd), []byte("c"))
+
+	_, ok := c.Get(a)
+	assert.True(t, ok)
+	_, ok = c.Get(b)
+	assert.False(t, ok, "least recently used entry should be evicted")
+	assert.Equal(t, 2, c.Stats().Entries)
+}
+
+func TestRenderCacheExpires(t *testing.T) {
+	c := NewRenderCache(10, time.Minute, nil)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	key := c.Key("team-a", windowStart, windowEnd)
+	c.Set(key, []byte("team-a"))
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get(key)
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get(key)
+	assert.False(t, ok, "entry should expire after maxAge")
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestRenderCacheDisabled(t *testing.T) {
+	c := NewRenderCache(0, time.Minute, nil)
+	assert.Nil(t, c)
+
+	key := c.Key("a", windowStart, windowEnd)
+	c.Set(key, []byte("a"))
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+	c.Invalidate(context.Background(), "a")
+	c.InvalidateAll()
+	assert.Equal(t, Stats{}, c.Stats())
+}
@@ -1,7 +1,17 @@
 package config
 
 import (
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -9,13 +19,99 @@ import (
 type Config struct {
 	DatabaseURL           string
 	APIKey                string
+	GroupAPIKeys          map[string][]string // API keys restricted to writing for some groups, to those groups
 	SessionSecret         string
 	LarkAppID             string
 	LarkAppSecret         string
-	WebhookDestinationURL string
+	WebhookDestinationURL string // Deprecated: receives every event; use webhook endpoints
 	Port                  string
+	RequestTimeout        time.Duration
+	RenderCacheSize       int
+	RenderCacheTTL        time.Duration
+	LoadShedWait          time.Duration
+	LoadShedRetryAfter    time.Duration
+	SnapshotRefresh       bool          // refresh heatmap snapshots nightly
+	SnapshotRefreshAt     time.Duration // offset from midnight UTC
+	LarkDigest            bool          // post groups' weekly heatmaps to their Lark chats
+	LarkDigestAt          time.Duration // offset from midnight UTC on Mondays
+	OverloadSweepInterval time.Duration // 0 disables overload tracking
+	WeightRulesFile       string        // JSON weight rules for upserts, optional
+	IssueStoryPointsField string        // issue webhook field holding story points
+	IssuePointWeight      float64       // load weight per story point of issue webhooks
+	HolidaysAPIURL        string        // public holidays of a year in a region, with {year} and {region}; empty for Nager.Date
+	CapacityApprovalDays  int           // zero-capacity run needing approval, 0 disables approval
+	QuarterlyReportCheck  time.Duration // 0 disables storing quarterly reports in the background
+	RecurrenceExpansion   time.Duration // how often to expand recurring loads ahead, 0 disables
+	RejectBlackouts       bool          // reject upserts on blackout dates instead of warning
+	AutoCreatePersons     bool          // create unknown assignees named by upserts as persons
+	AutoCreateDailyLimit  int           // persons each source may auto-create per UTC day, 0 for no limit
+	OTPRequestLimit       int           // OTPs each email may be sent an hour, 0 for no limit
+	OTPIPLimit            int           // calls each client IP may make to each OTP endpoint an hour, 0 for no limit
+	TrustedProxies        []*net.IPNet  // proxies whose X-Forwarded-For gives the client IP, empty to use the connection's address
+	AckReminderDays       int           // days a load may stay unacknowledged, 0 disables reminders
+	AckReminderMinWeight  float64       // lightest load worth a reminder
+	AckReminderInterval   time.Duration // how often to look for unacknowledged loads
+	AnomalyCheckInterval  time.Duration // how often to look for load spikes, 0 disables anomaly alerts
+	AnomalyFactor         float64       // times a person's recent average a day's load must exceed
+	LegacyAPISunset       time.Time     // removal date of the unversioned API routes, zero if not set
+	Production            bool          // APP_ENV=production, locks down defaults
+	ReadOnly              bool          // serve reads only, from a read-only database replica
+	DevMode               bool          // reload templates on every request and show their errors
+	CORSAllowOrigins      []string      // origins allowed cross-origin requests, empty for same-origin only
+	CORSAllowMethods      []string      // methods allowed cross-origin
+	CORSAllowCredentials  bool          // let allowed origins send cookies
+	WeekStart             time.Weekday  // first day of heatmap weeks, unless a user chose another
+	AdminEmails           []string      // may view every private heatmap
+	PublicURL             string        // base of links sent in notifications, empty for relative links
+	BasePath              string        // path prefix every route is served under, e.g. /heatmap, empty for the root
+	NotificationLinkTTL   time.Duration // how long signed links in notifications work, 0 disables them
+
+	// How long a source may go without re-upserting a load before it is
+	// flagged stale: StaleLoadWindow for sources without their own window,
+	// 0 for never. No windows at all disables the stale load check.
+	StaleLoadWindow        time.Duration
+	StaleLoadSourceWindows map[string]time.Duration
+	StaleLoadCheckInterval time.Duration
+
+	// How login codes are sent: OTPDelivery is "lark", "mailgun", "smtp" or
+	// "console". Mailgun and SMTP email them from OTPEmailFrom.
+	OTPDelivery   string
+	OTPEmailFrom  string
+	MailgunAPIKey string
+	MailgunDomain string
+	MailgunAPIURL string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+
+	// Single sign-on with an OpenID Connect identity provider besides OTP,
+	// off without OIDCIssuerURL. The provider redirects back to
+	// OIDCRedirectURL, by default /auth/oidc/callback under PUBLIC_URL.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCEmailClaim   string // ID token claim holding the user's email
+	OIDCTenantID     string // tid claim ID tokens must carry; needed unless the email claim is email
+	OIDCProviderName string // shown on the login button
+
+	// Nightly export of person-day facts for analytics. ExportDestination is
+	// "s3", "gs" or "table", or empty when the export is off; ExportTarget is
+	// the bucket or the table. The endpoint and keys are for buckets only,
+	// with an empty endpoint meaning AWS.
+	ExportDestination     string
+	ExportTarget          string
+	ExportPrefix          string
+	ExportAt              time.Duration
+	ExportEndpoint        string
+	ExportRegion          string
+	ExportAccessKeyID     string
+	ExportSecretAccessKey string
 }
 
+const defaultSessionSecret = "default-secret-change-in-production"
+
 func Load() (*Config, error) {
 	// Load .env file if it exists (ignore error if not found)
 	_ = godotenv.Load()
@@ -23,16 +119,594 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		DatabaseURL:           getEnv("DATABASE_URL", "postgres://localhost:5432/load_calendar?sslmode=disable"),
 		APIKey:                getEnv("API_KEY", ""),
-		SessionSecret:         getEnv("SESSION_SECRET", "default-secret-change-in-production"),
+		SessionSecret:         getEnv("SESSION_SECRET", defaultSessionSecret),
 		LarkAppID:             getEnv("LARK_APP_ID", ""),
 		LarkAppSecret:         getEnv("LARK_APP_SECRET", ""),
 		WebhookDestinationURL: getEnv("WEBHOOK_DESTINATION_URL", ""),
 		Port:                  getEnv("PORT", "8080"),
+		WeightRulesFile:       getEnv("WEIGHT_RULES_FILE", ""),
+		IssueStoryPointsField: getEnv("ISSUE_STORY_POINTS_FIELD", "customfield_10016"),
+		HolidaysAPIURL:        getEnv("HOLIDAYS_API_URL", ""),
+	}
+
+	pointWeight, err := strconv.ParseFloat(getEnv("ISSUE_POINT_WEIGHT", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ISSUE_POINT_WEIGHT: %w", err)
+	}
+	if pointWeight <= 0 {
+		return nil, fmt.Errorf("invalid ISSUE_POINT_WEIGHT: must be positive")
+	}
+	cfg.IssuePointWeight = pointWeight
+
+	timeout, err := time.ParseDuration(getEnv("REQUEST_TIMEOUT", "15s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REQUEST_TIMEOUT: %w", err)
+	}
+	cfg.RequestTimeout = timeout
+
+	cacheSize, err := strconv.Atoi(getEnv("RENDER_CACHE_SIZE", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RENDER_CACHE_SIZE: %w", err)
+	}
+	cfg.RenderCacheSize = cacheSize
+
+	cacheTTL, err := time.ParseDuration(getEnv("RENDER_CACHE_TTL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RENDER_CACHE_TTL: %w", err)
+	}
+	cfg.RenderCacheTTL = cacheTTL
+
+	shedWait, err := time.ParseDuration(getEnv("LOAD_SHED_WAIT", "250ms"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOAD_SHED_WAIT: %w", err)
+	}
+	cfg.LoadShedWait = shedWait
+
+	retryAfter, err := time.ParseDuration(getEnv("LOAD_SHED_RETRY_AFTER", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOAD_SHED_RETRY_AFTER: %w", err)
+	}
+	cfg.LoadShedRetryAfter = retryAfter
+
+	// HH:MM in UTC, or "off"
+	refreshAt := getEnv("SNAPSHOT_REFRESH_AT", "00:05")
+	if refreshAt != "off" {
+		t, err := time.Parse("15:04", refreshAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SNAPSHOT_REFRESH_AT: %w", err)
+		}
+		cfg.SnapshotRefresh = true
+		cfg.SnapshotRefreshAt = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	// HH:MM in UTC on Mondays, or "off"; digests need the Lark app
+	digestAt := getEnv("LARK_DIGEST_AT", "09:00")
+	if digestAt != "off" {
+		t, err := time.Parse("15:04", digestAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LARK_DIGEST_AT: %w", err)
+		}
+		cfg.LarkDigest = cfg.LarkAppID != "" && cfg.LarkAppSecret != ""
+		cfg.LarkDigestAt = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	// Duration, or "off"
+	if sweep := getEnv("OVERLOAD_SWEEP_INTERVAL", "1m"); sweep != "off" {
+		interval, err := time.ParseDuration(sweep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVERLOAD_SWEEP_INTERVAL: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid OVERLOAD_SWEEP_INTERVAL: must be positive")
+		}
+		cfg.OverloadSweepInterval = interval
+	}
+
+	// Duration, or "off"
+	if check := getEnv("QUARTERLY_REPORT_INTERVAL", "1h"); check != "off" {
+		interval, err := time.ParseDuration(check)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QUARTERLY_REPORT_INTERVAL: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid QUARTERLY_REPORT_INTERVAL: must be positive")
+		}
+		cfg.QuarterlyReportCheck = interval
+	}
+
+	// Duration, or "off"
+	if expand := getEnv("RECURRING_LOAD_EXPAND_INTERVAL", "24h"); expand != "off" {
+		interval, err := time.ParseDuration(expand)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RECURRING_LOAD_EXPAND_INTERVAL: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid RECURRING_LOAD_EXPAND_INTERVAL: must be positive")
+		}
+		cfg.RecurrenceExpansion = interval
+	}
+
+	// Days, or "off"
+	if days := getEnv("CAPACITY_APPROVAL_ZERO_DAYS", "off"); days != "off" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAPACITY_APPROVAL_ZERO_DAYS: %w", err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid CAPACITY_APPROVAL_ZERO_DAYS: must be positive")
+		}
+		cfg.CapacityApprovalDays = n
+	}
+
+	switch mode := getEnv("BLACKOUT_MODE", "warn"); mode {
+	case "warn":
+	case "reject":
+		cfg.RejectBlackouts = true
+	default:
+		return nil, fmt.Errorf("invalid BLACKOUT_MODE: must be warn or reject, got %q", mode)
+	}
+
+	autoCreate, err := strconv.ParseBool(getEnv("AUTO_CREATE_PERSONS", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTO_CREATE_PERSONS: %w", err)
+	}
+	cfg.AutoCreatePersons = autoCreate
+
+	// Persons per source per day, or "off"
+	if limit := getEnv("AUTO_CREATE_DAILY_LIMIT", "50"); limit != "off" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTO_CREATE_DAILY_LIMIT: %w", err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid AUTO_CREATE_DAILY_LIMIT: must be positive")
+		}
+		cfg.AutoCreateDailyLimit = n
+	}
+
+	// Per hour, or "off"
+	if limit := getEnv("OTP_REQUEST_LIMIT", "5"); limit != "off" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTP_REQUEST_LIMIT: %w", err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid OTP_REQUEST_LIMIT: must be positive")
+		}
+		cfg.OTPRequestLimit = n
+	}
+	if limit := getEnv("OTP_IP_LIMIT", "20"); limit != "off" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTP_IP_LIMIT: %w", err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid OTP_IP_LIMIT: must be positive")
+		}
+		cfg.OTPIPLimit = n
+	}
+	for _, proxy := range splitList(getEnv("TRUSTED_PROXIES", "")) {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, network)
+	}
+
+	// Days, or "off"
+	if days := getEnv("ACK_REMINDER_DAYS", "off"); days != "off" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACK_REMINDER_DAYS: %w", err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid ACK_REMINDER_DAYS: must be positive")
+		}
+		cfg.AckReminderDays = n
+	}
+
+	minWeight, err := strconv.ParseFloat(getEnv("ACK_REMINDER_MIN_WEIGHT", "2"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACK_REMINDER_MIN_WEIGHT: %w", err)
+	}
+	cfg.AckReminderMinWeight = minWeight
+
+	ackInterval, err := time.ParseDuration(getEnv("ACK_REMINDER_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACK_REMINDER_INTERVAL: %w", err)
+	}
+	if ackInterval <= 0 {
+		return nil, fmt.Errorf("invalid ACK_REMINDER_INTERVAL: must be positive")
+	}
+	cfg.AckReminderInterval = ackInterval
+
+	// Duration, or "off"
+	if check := getEnv("ANOMALY_CHECK_INTERVAL", "1h"); check != "off" {
+		interval, err := time.ParseDuration(check)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ANOMALY_CHECK_INTERVAL: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid ANOMALY_CHECK_INTERVAL: must be positive")
+		}
+		cfg.AnomalyCheckInterval = interval
+	}
+
+	factor, err := strconv.ParseFloat(getEnv("ANOMALY_FACTOR", "2"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_FACTOR: %w", err)
+	}
+	if factor <= 1 {
+		return nil, fmt.Errorf("invalid ANOMALY_FACTOR: must be over 1")
+	}
+	cfg.AnomalyFactor = factor
+
+	// Date, or "off"
+	if sunset := getEnv("LEGACY_API_SUNSET", "off"); sunset != "off" {
+		date, err := time.Parse("2006-01-02", sunset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEGACY_API_SUNSET: %w", err)
+		}
+		cfg.LegacyAPISunset = date
+	}
+
+	switch env := getEnv("APP_ENV", "development"); env {
+	case "development":
+	case "production":
+		cfg.Production = true
+	default:
+		return nil, fmt.Errorf("invalid APP_ENV: must be development or production, got %q", env)
+	}
+
+	devMode, err := strconv.ParseBool(getEnv("DEV_MODE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEV_MODE: %w", err)
+	}
+	if devMode && cfg.Production {
+		// Template errors show the app's internals
+		return nil, fmt.Errorf("invalid DEV_MODE: not allowed in production")
+	}
+	cfg.DevMode = devMode
+
+	// Any origin during development; in production only the origins listed,
+	// so the default is same-origin requests only
+	defaultOrigins := "*"
+	if cfg.Production {
+		defaultOrigins = "off"
+	}
+	if origins := getEnv("CORS_ALLOWED_ORIGINS", defaultOrigins); origins != "off" {
+		cfg.CORSAllowOrigins = splitList(origins)
+	}
+	cfg.CORSAllowMethods = splitList(getEnv("CORS_ALLOWED_METHODS", "GET,HEAD,PUT,PATCH,POST,DELETE"))
+
+	credentials, err := strconv.ParseBool(getEnv("CORS_ALLOW_CREDENTIALS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: %w", err)
+	}
+	if credentials && slices.Contains(cfg.CORSAllowOrigins, "*") {
+		// Browsers refuse credentials for "*", and echoing back any origin
+		// instead would let every site act with the user's session
+		return nil, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: cannot be used with CORS_ALLOWED_ORIGINS=*")
+	}
+	cfg.CORSAllowCredentials = credentials
+
+	weekStart, err := parseWeekday(getEnv("WEEK_START", "monday"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEEK_START: %w", err)
+	}
+	cfg.WeekStart = weekStart
+
+	cfg.AdminEmails = splitList(getEnv("ADMIN_EMAILS", ""))
+
+	// group=key pairs, e.g. "platform=k1,infra=k1,design=k2"; a key listed
+	// for several groups may write for all of them
+	for _, pair := range splitList(getEnv("GROUP_API_KEYS", "")) {
+		group, key, ok := strings.Cut(pair, "=")
+		group, key = strings.TrimSpace(group), strings.TrimSpace(key)
+		if !ok || group == "" || key == "" {
+			return nil, fmt.Errorf("invalid GROUP_API_KEYS: expected group=key, got %q", pair)
+		}
+		if key == cfg.APIKey {
+			return nil, fmt.Errorf("invalid GROUP_API_KEYS for %s: must differ from API_KEY", group)
+		}
+		if cfg.GroupAPIKeys == nil {
+			cfg.GroupAPIKeys = make(map[string][]string)
+		}
+		cfg.GroupAPIKeys[key] = append(cfg.GroupAPIKeys[key], group)
+	}
+
+	cfg.PublicURL = strings.TrimRight(getEnv("PUBLIC_URL", ""), "/")
+
+	// A path prefix for serving behind a reverse proxy, e.g. /heatmap
+	if basePath := strings.Trim(getEnv("BASE_PATH", ""), "/"); basePath != "" {
+		if strings.ContainsAny(basePath, "?#:* ") {
+			return nil, fmt.Errorf("invalid BASE_PATH: must be a plain path such as /heatmap, got %q", basePath)
+		}
+		cfg.BasePath = "/" + basePath
+	}
+
+	// Duration, or "off"
+	if ttl := getEnv("NOTIFICATION_LINK_TTL", "168h"); ttl != "off" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NOTIFICATION_LINK_TTL: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid NOTIFICATION_LINK_TTL: must be positive")
+		}
+		cfg.NotificationLinkTTL = d
+	}
+	if cfg.NotificationLinkTTL > 0 && cfg.SessionSecret == defaultSessionSecret {
+		// Links are signed with the session secret; the default one would let
+		// anyone forge them
+		if cfg.Production {
+			return nil, fmt.Errorf("invalid SESSION_SECRET: must be set in production while NOTIFICATION_LINK_TTL is not off")
+		}
+		log.Println("SESSION_SECRET is not set; notification links are off")
+		cfg.NotificationLinkTTL = 0
+	}
+
+	// Duration, or "off"
+	if window := getEnv("STALE_LOAD_WINDOW", "off"); window != "off" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STALE_LOAD_WINDOW: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid STALE_LOAD_WINDOW: must be positive")
+		}
+		cfg.StaleLoadWindow = d
+	}
+
+	// source=duration pairs, e.g. "gcal=48h,jira=168h"; "off" keeps a
+	// source's loads from going stale
+	for _, pair := range splitList(getEnv("STALE_LOAD_SOURCE_WINDOWS", "")) {
+		source, window, ok := strings.Cut(pair, "=")
+		source = strings.TrimSpace(source)
+		if !ok || source == "" {
+			return nil, fmt.Errorf("invalid STALE_LOAD_SOURCE_WINDOWS: expected source=duration, got %q", pair)
+		}
+		var d time.Duration
+		if window = strings.TrimSpace(window); window != "off" {
+			d, err = time.ParseDuration(window)
+			if err != nil {
+				return nil, fmt.Errorf("invalid STALE_LOAD_SOURCE_WINDOWS for %s: %w", source, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("invalid STALE_LOAD_SOURCE_WINDOWS for %s: must be positive", source)
+			}
+		}
+		if cfg.StaleLoadSourceWindows == nil {
+			cfg.StaleLoadSourceWindows = make(map[string]time.Duration)
+		}
+		cfg.StaleLoadSourceWindows[source] = d
+	}
+
+	staleInterval, err := time.ParseDuration(getEnv("STALE_LOAD_CHECK_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STALE_LOAD_CHECK_INTERVAL: %w", err)
+	}
+	if staleInterval <= 0 {
+		return nil, fmt.Errorf("invalid STALE_LOAD_CHECK_INTERVAL: must be positive")
+	}
+	cfg.StaleLoadCheckInterval = staleInterval
+
+	// s3://bucket/prefix, gs://bucket/prefix, table:schema.table, or "off"
+	if dest := getEnv("EXPORT_DESTINATION", "off"); dest != "off" {
+		if err := parseExportDestination(cfg, dest); err != nil {
+			return nil, fmt.Errorf("invalid EXPORT_DESTINATION: %w", err)
+		}
+	}
+
+	// HH:MM in UTC, after the day being exported has ended
+	exportAt, err := time.Parse("15:04", getEnv("EXPORT_AT", "02:00"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_AT: %w", err)
+	}
+	cfg.ExportAt = time.Duration(exportAt.Hour())*time.Hour + time.Duration(exportAt.Minute())*time.Minute
+
+	cfg.ExportEndpoint = strings.TrimRight(getEnv("EXPORT_ENDPOINT", ""), "/")
+	cfg.ExportRegion = getEnv("EXPORT_REGION", "us-east-1")
+	cfg.ExportAccessKeyID = getEnv("EXPORT_ACCESS_KEY_ID", "")
+	cfg.ExportSecretAccessKey = getEnv("EXPORT_SECRET_ACCESS_KEY", "")
+	if cfg.ExportDestination == "gs" {
+		// Cloud Storage's S3-compatible XML API, signed with HMAC keys
+		if cfg.ExportEndpoint == "" {
+			cfg.ExportEndpoint = "https://storage.googleapis.com"
+		}
+		cfg.ExportRegion = getEnv("EXPORT_REGION", "auto")
+	}
+	if (cfg.ExportDestination == "s3" || cfg.ExportDestination == "gs") &&
+		(cfg.ExportAccessKeyID == "" || cfg.ExportSecretAccessKey == "") {
+		return nil, fmt.Errorf("invalid EXPORT_DESTINATION: bucket exports need EXPORT_ACCESS_KEY_ID and EXPORT_SECRET_ACCESS_KEY")
+	}
+
+	if err := parseOTPDelivery(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := parseOIDC(cfg); err != nil {
+		return nil, err
+	}
+
+	readOnly, err := strconv.ParseBool(getEnv("READ_ONLY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid READ_ONLY: %w", err)
+	}
+	cfg.ReadOnly = readOnly
+	if cfg.ReadOnly {
+		// The background jobs all write, so they are left to the primary
+		cfg.SnapshotRefresh = false
+		cfg.LarkDigest = false
+		cfg.OverloadSweepInterval = 0
+		cfg.QuarterlyReportCheck = 0
+		cfg.RecurrenceExpansion = 0
+		cfg.AckReminderDays = 0
+		cfg.AnomalyCheckInterval = 0
+		cfg.StaleLoadWindow = 0
+		cfg.StaleLoadSourceWindows = nil
+		cfg.ExportDestination = ""
 	}
 
 	return cfg, nil
 }
 
+// parseOTPDelivery sets how login codes are sent. Without OTP_DELIVERY it is
+// Lark when the Lark app is set up, else Mailgun when that is, else the
+// console.
+func parseOTPDelivery(cfg *Config) error {
+	cfg.MailgunAPIKey = getEnv("MAILGUN_API_KEY", "")
+	cfg.MailgunDomain = getEnv("MAILGUN_DOMAIN", "")
+	cfg.MailgunAPIURL = getEnv("MAILGUN_API_URL", "https://api.mailgun.net")
+	cfg.SMTPHost = getEnv("SMTP_HOST", "")
+	cfg.SMTPPort = getEnv("SMTP_PORT", "587")
+	cfg.SMTPUsername = getEnv("SMTP_USERNAME", "")
+	cfg.SMTPPassword = getEnv("SMTP_PASSWORD", "")
+
+	lark := cfg.LarkAppID != "" && cfg.LarkAppSecret != ""
+	mailgun := cfg.MailgunAPIKey != "" && cfg.MailgunDomain != ""
+	cfg.OTPDelivery = getEnv("OTP_DELIVERY", "")
+	if cfg.OTPDelivery == "" {
+		switch {
+		case lark:
+			cfg.OTPDelivery = "lark"
+		case mailgun:
+			cfg.OTPDelivery = "mailgun"
+		default:
+			cfg.OTPDelivery = "console"
+		}
+	}
+
+	defaultFrom := ""
+	if cfg.MailgunDomain != "" {
+		defaultFrom = "Heatmap <heatmap@" + cfg.MailgunDomain + ">"
+	}
+	cfg.OTPEmailFrom = getEnv("OTP_EMAIL_FROM", defaultFrom)
+
+	switch cfg.OTPDelivery {
+	case "console":
+	case "lark":
+		if !lark {
+			return fmt.Errorf("invalid OTP_DELIVERY: lark needs LARK_APP_ID and LARK_APP_SECRET")
+		}
+	case "mailgun":
+		if !mailgun {
+			return fmt.Errorf("invalid OTP_DELIVERY: mailgun needs MAILGUN_API_KEY and MAILGUN_DOMAIN")
+		}
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.OTPEmailFrom == "" {
+			return fmt.Errorf("invalid OTP_DELIVERY: smtp needs SMTP_HOST and OTP_EMAIL_FROM")
+		}
+	default:
+		return fmt.Errorf("invalid OTP_DELIVERY: must be lark, mailgun, smtp or console, got %q", cfg.OTPDelivery)
+	}
+	if cfg.OTPDelivery == "mailgun" || cfg.OTPDelivery == "smtp" {
+		if _, err := mail.ParseAddress(cfg.OTPEmailFrom); err != nil {
+			return fmt.Errorf("invalid OTP_EMAIL_FROM: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseOIDC sets up single sign-on when OIDC_ISSUER_URL is set. The redirect
+// URL defaults to the callback under PUBLIC_URL and BASE_PATH, so one of
+// them must be set.
+func parseOIDC(cfg *Config) error {
+	cfg.OIDCIssuerURL = getEnv("OIDC_ISSUER_URL", "")
+	if cfg.OIDCIssuerURL == "" {
+		return nil
+	}
+	issuer, err := url.Parse(cfg.OIDCIssuerURL)
+	if err != nil || (issuer.Scheme != "https" && issuer.Scheme != "http") || issuer.Host == "" {
+		return fmt.Errorf("invalid OIDC_ISSUER_URL: must be an http or https URL, got %q", cfg.OIDCIssuerURL)
+	}
+	if cfg.Production && issuer.Scheme != "https" {
+		return fmt.Errorf("invalid OIDC_ISSUER_URL: must be https in production")
+	}
+
+	cfg.OIDCClientID = getEnv("OIDC_CLIENT_ID", "")
+	cfg.OIDCClientSecret = getEnv("OIDC_CLIENT_SECRET", "")
+	if cfg.OIDCClientID == "" || cfg.OIDCClientSecret == "" {
+		return fmt.Errorf("invalid OIDC_ISSUER_URL: needs OIDC_CLIENT_ID and OIDC_CLIENT_SECRET")
+	}
+
+	defaultRedirect := ""
+	if cfg.PublicURL != "" {
+		defaultRedirect = cfg.PublicURL + cfg.BasePath + "/auth/oidc/callback"
+	}
+	cfg.OIDCRedirectURL = getEnv("OIDC_REDIRECT_URL", defaultRedirect)
+	if cfg.OIDCRedirectURL == "" {
+		return fmt.Errorf("invalid OIDC_ISSUER_URL: needs OIDC_REDIRECT_URL or PUBLIC_URL")
+	}
+	if redirect, err := url.Parse(cfg.OIDCRedirectURL); err != nil || !redirect.IsAbs() {
+		return fmt.Errorf("invalid OIDC_REDIRECT_URL: must be an absolute URL, got %q", cfg.OIDCRedirectURL)
+	}
+
+	// Only the email claim is verified; other claims are trusted from one
+	// tenant only, since another tenant's users could set them to any email
+	cfg.OIDCEmailClaim = getEnv("OIDC_EMAIL_CLAIM", "email")
+	cfg.OIDCTenantID = getEnv("OIDC_TENANT_ID", "")
+	if cfg.OIDCEmailClaim != "email" && cfg.OIDCTenantID == "" {
+		return fmt.Errorf("invalid OIDC_EMAIL_CLAIM: claims other than email need OIDC_TENANT_ID")
+	}
+	cfg.OIDCProviderName = getEnv("OIDC_PROVIDER_NAME", "SSO")
+	return nil
+}
+
+// exportTablePattern matches a table name, optionally schema-qualified
+var exportTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// parseExportDestination sets the export destination, target and prefix
+// from a value such as "s3://bucket/prefix" or "table:analytics.facts"
+func parseExportDestination(cfg *Config, dest string) error {
+	if table, ok := strings.CutPrefix(dest, "table:"); ok {
+		if !exportTablePattern.MatchString(table) {
+			return fmt.Errorf("expected table:schema.table, got %q", dest)
+		}
+		cfg.ExportDestination, cfg.ExportTarget = "table", table
+		return nil
+	}
+
+	scheme, path, ok := strings.Cut(dest, "://")
+	if !ok || (scheme != "s3" && scheme != "gs") {
+		return fmt.Errorf("must be s3://, gs:// or table:, got %q", dest)
+	}
+	bucket, prefix, _ := strings.Cut(path, "/")
+	if bucket == "" {
+		return fmt.Errorf("missing bucket in %q", dest)
+	}
+	cfg.ExportDestination, cfg.ExportTarget, cfg.ExportPrefix = scheme, bucket, strings.Trim(prefix, "/")
+	return nil
+}
+
+// parseWeekday parses a weekday name such as "sunday"
+func parseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("must be a weekday name such as monday, got %q", name)
+}
+
+// splitList splits a comma-separated value, dropping blanks.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
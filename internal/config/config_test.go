@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationLinksNeedSessionSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		appEnv  string
+		secret  string
+		wantTTL time.Duration
+		wantErr bool
+	}{
+		{"development without secret", "development", "", 0, false},
+		{"development with secret", "development", "a-secret-only-this-deployment-knows", 168 * time.Hour, false},
+		{"production without secret", "production", "", 0, true},
+		{"production with secret", "production", "a-secret-only-this-deployment-knows", 168 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.appEnv)
+			t.Setenv("SESSION_SECRET", tt.secret)
+			t.Setenv("NOTIFICATION_LINK_TTL", "")
+
+			cfg, err := Load()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "SESSION_SECRET")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTTL, cfg.NotificationLinkTTL)
+		})
+	}
+}
+
+func TestOIDCEmailClaimNeedsTenant(t *testing.T) {
+	tests := []struct {
+		name    string
+		claim   string
+		tenant  string
+		wantErr bool
+	}{
+		{"email without tenant", "", "", false},
+		{"other claim without tenant", "preferred_username", "", true},
+		{"other claim with tenant", "preferred_username", "tenant-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", "development")
+			t.Setenv("OIDC_ISSUER_URL", "https://login.example.com/tenant-1/v2.0")
+			t.Setenv("OIDC_CLIENT_ID", "client-1")
+			t.Setenv("OIDC_CLIENT_SECRET", "secret")
+			t.Setenv("OIDC_REDIRECT_URL", "https://heatmap.example.com/auth/oidc/callback")
+			t.Setenv("OIDC_EMAIL_CLAIM", tt.claim)
+			t.Setenv("OIDC_TENANT_ID", tt.tenant)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "OIDC_TENANT_ID")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.tenant, cfg.OIDCTenantID)
+		})
+	}
+}
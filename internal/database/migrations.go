@@ -2,108 +2,199 @@ package database
 
 import (
 	"context"
+	"embed"
 	"fmt"
+	"io/fs"
 	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// RunMigrations creates the database schema
+// migrationFiles holds the schema migrations, one pair per version named
+// NNNN_description.up.sql and NNNN_description.down.sql. Versions are applied
+// in order and never edited once released; change the schema by adding the
+// next version.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID keys the advisory lock that keeps replicas starting at the
+// same time from applying migrations twice
+const migrationLockID = 7_346_201
+
+// migration is one schema version with the SQL to apply and revert it
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// loadMigrations reads the migration files in dir of fsys, ordered by version.
+// Every version needs both an up and a down file.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		if !ok || !strings.HasSuffix(file, ".sql") || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_description.up.sql or .down.sql", file)
+		}
+		number, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version number", file)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		} else if m.name != name {
+			return nil, fmt.Errorf("migration %s: version %d is also named %s", file, version, m.name)
+		}
+		if direction == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: needs both an up and a down file", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// withMigrationLock runs fn on one connection while holding the migration
+// lock, after making sure the schema_migrations table exists
+func (db *DB) withMigrationLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer func() {
+		// A fresh context so a cancelled caller still releases the lock
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			log.Printf("Failed to unlock migrations: %v", err)
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `
+		CREATE SCHEMA IF NOT EXISTS load_calendar_data;
+		CREATE TABLE IF NOT EXISTS load_calendar_data.schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions returns the versions recorded in schema_migrations
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int]bool, error) {
+	rows, err := conn.Query(ctx, `SELECT version FROM load_calendar_data.schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// runMigration applies or reverts m and records it in schema_migrations in
+// one transaction, so a failing migration leaves no trace
+func runMigration(ctx context.Context, conn *pgxpool.Conn, m migration, up bool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	body, record := m.down, `DELETE FROM load_calendar_data.schema_migrations WHERE version = $1`
+	if up {
+		body, record = m.up, `INSERT INTO load_calendar_data.schema_migrations (version, name) VALUES ($1, $2)`
+	}
+	if _, err := tx.Exec(ctx, body); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %w", m.version, m.name, err)
+	}
+	args := []interface{}{m.version}
+	if up {
+		args = append(args, m.name)
+	}
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.version, m.name, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RunMigrations applies every migration not yet recorded in
+// schema_migrations, in version order, each in its own transaction.
+// Databases created before versioned migrations adopt the baseline in place.
+// Versions recorded by a newer release are left alone, so an older replica
+// can still start during a rolling deploy.
 func (db *DB) RunMigrations(ctx context.Context) error {
 	log.Println("Running database migrations...")
 
-	// Create schema for this application
-	schema := `
-	-- Create custom schema for load calendar data
-	CREATE SCHEMA IF NOT EXISTS load_calendar_data;
-
-	-- Create entities table (persons and groups)
-	CREATE TABLE IF NOT EXISTS load_calendar_data.entities (
-		id TEXT PRIMARY KEY,
-		title TEXT NOT NULL,
-		type TEXT CHECK (type IN ('person', 'group')),
-		employee_id TEXT,
-		default_capacity FLOAT DEFAULT 5.0,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	-- Create group_members table
-	CREATE TABLE IF NOT EXISTS load_calendar_data.group_members (
-		group_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
-		person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
-		PRIMARY KEY (group_id, person_email)
-	);
-
-	-- Create capacity_overrides table
-	CREATE TABLE IF NOT EXISTS load_calendar_data.capacity_overrides (
-		entity_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
-		date DATE NOT NULL,
-		capacity FLOAT NOT NULL,
-		PRIMARY KEY (entity_id, date)
-	);
-
-	-- Create loads table
-	CREATE TABLE IF NOT EXISTS load_calendar_data.loads (
-		id SERIAL PRIMARY KEY,
-		external_id TEXT UNIQUE,
-		title TEXT NOT NULL,
-		source TEXT,
-		url TEXT,
-		date DATE NOT NULL
-	);
-
-	-- Create load_assignments table
-	CREATE TABLE IF NOT EXISTS load_calendar_data.load_assignments (
-		load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
-		person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
-		weight FLOAT DEFAULT 1.0,
-		PRIMARY KEY (load_id, person_email)
-	);
-
-	-- Add URL column to loads table if it doesn't exist (migration for existing databases)
-	DO $$
-	BEGIN
-		IF NOT EXISTS (
-			SELECT 1 FROM information_schema.columns
-			WHERE table_schema='load_calendar_data' AND table_name='loads' AND column_name='url'
-		) THEN
-			ALTER TABLE load_calendar_data.loads ADD COLUMN url TEXT;
-		END IF;
-	END $$;
-
-	-- Add employee_id column to entities table if it doesn't exist (migration for existing databases)
-	DO $$
-	BEGIN
-		IF NOT EXISTS (
-			SELECT 1 FROM information_schema.columns
-			WHERE table_schema='load_calendar_data' AND table_name='entities' AND column_name='employee_id'
-		) THEN
-			ALTER TABLE load_calendar_data.entities ADD COLUMN employee_id TEXT;
-		END IF;
-	END $$;
-
-	-- Create indexes for performance
-	CREATE INDEX IF NOT EXISTS idx_loads_date ON load_calendar_data.loads(date);
-	CREATE INDEX IF NOT EXISTS idx_loads_external_id ON load_calendar_data.loads(external_id);
-	CREATE INDEX IF NOT EXISTS idx_load_assignments_person ON load_calendar_data.load_assignments(person_email);
-	CREATE INDEX IF NOT EXISTS idx_capacity_overrides_date ON load_calendar_data.capacity_overrides(entity_id, date);
-
-	-- Create OTP sessions table (in-memory alternative would be better for production)
-	CREATE TABLE IF NOT EXISTS load_calendar_data.otp_records (
-		email TEXT PRIMARY KEY,
-		otp TEXT NOT NULL,
-		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
-	);
-
-	-- Create sessions table
-	CREATE TABLE IF NOT EXISTS load_calendar_data.sessions (
-		token TEXT PRIMARY KEY,
-		email TEXT NOT NULL,
-		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
-	);
-	CREATE INDEX IF NOT EXISTS idx_sessions_email ON load_calendar_data.sessions(email);
-	`
-
-	_, err := db.Pool.Exec(ctx, schema)
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return err
+	}
+
+	err = db.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if applied[m.version] {
+				continue
+			}
+			log.Printf("Applying migration %04d_%s", m.version, m.name)
+			if err := runMigration(ctx, conn, m, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -112,6 +203,128 @@ func (db *DB) RunMigrations(ctx context.Context) error {
 	return nil
 }
 
+// MigrateDown reverts the latest steps applied migrations, newest first
+func (db *DB) MigrateDown(ctx context.Context, steps int) error {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return err
+	}
+
+	return db.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		return revertMigrations(ctx, conn, migrations, steps)
+	})
+}
+
+// revertMigrations reverts up to steps applied migrations, newest first. It
+// fails on an applied version this release has no files for.
+func revertMigrations(ctx context.Context, conn *pgxpool.Conn, migrations []migration, steps int) error {
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[int]migration, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = m
+	}
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	for i, v := range versions {
+		if i == steps {
+			break
+		}
+		m, ok := known[v]
+		if !ok {
+			return fmt.Errorf("migration %04d was applied by a newer release; revert it with that release", v)
+		}
+		log.Printf("Reverting migration %04d_%s", m.version, m.name)
+		if err := runMigration(ctx, conn, m, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrationStatus is one known migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// GetMigrationStatus lists every migration this release knows, oldest first
+func (db *DB) GetMigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[int]time.Time)
+	err = db.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, `SELECT version, applied_at FROM load_calendar_data.schema_migrations`)
+		if err != nil {
+			return fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var at time.Time
+			if err := rows.Scan(&version, &at); err != nil {
+				return fmt.Errorf("failed to scan schema_migrations: %w", err)
+			}
+			appliedAt[version] = at
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Version: m.version, Name: m.name}
+		if at, ok := appliedAt[m.version]; ok {
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// RollbackMigrations reverts every migration, then drops schema_migrations and
+// the application schema. It exists so tests can verify that migrations
+// survive an up/down/up round-trip; never run it against a database with real
+// data.
+func (db *DB) RollbackMigrations(ctx context.Context) error {
+	log.Println("Rolling back database migrations...")
+
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return err
+	}
+
+	err = db.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		if err := revertMigrations(ctx, conn, migrations, len(migrations)); err != nil {
+			return err
+		}
+		// Without CASCADE, so a down migration that leaves objects behind fails here
+		_, err := conn.Exec(ctx, `
+			DROP TABLE load_calendar_data.schema_migrations;
+			DROP SCHEMA load_calendar_data`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	log.Println("Database migrations rolled back successfully")
+	return nil
+}
+
 // SeedData populates the database with sample data if empty
 func (db *DB) SeedData(ctx context.Context) error {
 	// Check if data already exists
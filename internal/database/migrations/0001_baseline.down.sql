@@ -0,0 +1,39 @@
+-- Drops everything the baseline created, leaving the schema itself and
+-- schema_migrations to the runner
+DROP TABLE IF EXISTS
+	load_calendar_data.sessions,
+	load_calendar_data.otp_records,
+	load_calendar_data.domain_events,
+	load_calendar_data.webhook_deliveries,
+	load_calendar_data.integration_errors,
+	load_calendar_data.load_actuals,
+	load_calendar_data.pins,
+	load_calendar_data.notes,
+	load_calendar_data.capacity_audit_log,
+	load_calendar_data.delegations,
+	load_calendar_data.capacity_change_requests,
+	load_calendar_data.group_owners,
+	load_calendar_data.blackout_dates,
+	load_calendar_data.load_acknowledgments,
+	load_calendar_data.group_dashboards,
+	load_calendar_data.utilization_reports,
+	load_calendar_data.overload_days,
+	load_calendar_data.scenario_capacity_overrides,
+	load_calendar_data.scenario_loads,
+	load_calendar_data.scenarios,
+	load_calendar_data.heatmap_snapshots,
+	load_calendar_data.heatmap_tombstones,
+	load_calendar_data.load_occurrences,
+	load_calendar_data.recurring_loads,
+	load_calendar_data.load_assignments,
+	load_calendar_data.loads,
+	load_calendar_data.weekly_capacity,
+	load_calendar_data.capacity_overrides,
+	load_calendar_data.group_members,
+	load_calendar_data.entities
+	CASCADE;
+
+DROP FUNCTION IF EXISTS load_calendar_data.reject_domain_event_change();
+DROP FUNCTION IF EXISTS load_calendar_data.track_load_acknowledgment();
+DROP FUNCTION IF EXISTS load_calendar_data.record_heatmap_tombstone();
+DROP FUNCTION IF EXISTS load_calendar_data.touch_updated_at();
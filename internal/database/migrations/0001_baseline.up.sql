@@ -0,0 +1,471 @@
+-- Baseline: the schema as it stood before versioned migrations. Every
+-- statement is idempotent so databases created before schema_migrations
+-- existed adopt this version in place; later migrations need not be.
+
+-- Create entities table (persons and groups)
+CREATE TABLE IF NOT EXISTS load_calendar_data.entities (
+	id TEXT PRIMARY KEY,
+	title TEXT NOT NULL,
+	type TEXT CHECK (type IN ('person', 'group')),
+	employee_id TEXT,
+	default_capacity FLOAT DEFAULT 5.0,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create group_members table
+CREATE TABLE IF NOT EXISTS load_calendar_data.group_members (
+	group_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	PRIMARY KEY (group_id, person_email)
+);
+
+-- Create capacity_overrides table
+CREATE TABLE IF NOT EXISTS load_calendar_data.capacity_overrides (
+	entity_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	capacity FLOAT NOT NULL,
+	PRIMARY KEY (entity_id, date)
+);
+
+-- Capacity by ISO weekday (1 = Monday), between overrides and the
+-- default capacity
+CREATE TABLE IF NOT EXISTS load_calendar_data.weekly_capacity (
+	entity_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7),
+	capacity FLOAT NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+	PRIMARY KEY (entity_id, weekday)
+);
+
+-- Create loads table
+CREATE TABLE IF NOT EXISTS load_calendar_data.loads (
+	id SERIAL PRIMARY KEY,
+	external_id TEXT UNIQUE,
+	title TEXT NOT NULL,
+	source TEXT,
+	url TEXT,
+	date DATE NOT NULL
+);
+
+-- Create load_assignments table
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_assignments (
+	load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	weight FLOAT DEFAULT 1.0,
+	PRIMARY KEY (load_id, person_email)
+);
+
+-- Add URL column to loads table if it doesn't exist (migration for existing databases)
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema='load_calendar_data' AND table_name='loads' AND column_name='url'
+	) THEN
+		ALTER TABLE load_calendar_data.loads ADD COLUMN url TEXT;
+	END IF;
+END $$;
+
+-- Add employee_id column to entities table if it doesn't exist (migration for existing databases)
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema='load_calendar_data' AND table_name='entities' AND column_name='employee_id'
+	) THEN
+		ALTER TABLE load_calendar_data.entities ADD COLUMN employee_id TEXT;
+	END IF;
+END $$;
+
+-- Offboarded persons are archived rather than deleted, keeping their history
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+-- Skill tags used to suggest assignees, stored lowercase
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}';
+
+-- Who a person reports to, kept in sync from the directory; roll-up
+-- heatmaps follow it down the reporting chain
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS manager_email TEXT;
+CREATE INDEX IF NOT EXISTS idx_entities_manager ON load_calendar_data.entities(manager_email) WHERE manager_email IS NOT NULL;
+
+-- ISO weekday (1 = Monday) a person's heatmap weeks start on; unset
+-- follows the server's WEEK_START
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS week_start SMALLINT CHECK (week_start BETWEEN 1 AND 7);
+
+-- Private heatmaps are visible only to the person, owners of their
+-- groups, and ADMIN_EMAILS
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Load sources a person leaves out of the heatmaps they view, such as
+-- calendar events only shared for information
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS hidden_sources TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_loads_date ON load_calendar_data.loads(date);
+CREATE INDEX IF NOT EXISTS idx_entities_skills ON load_calendar_data.entities USING GIN (skills);
+CREATE INDEX IF NOT EXISTS idx_loads_date_id ON load_calendar_data.loads(date, id); -- keyset pagination
+CREATE INDEX IF NOT EXISTS idx_loads_external_id ON load_calendar_data.loads(external_id);
+CREATE INDEX IF NOT EXISTS idx_load_assignments_person ON load_calendar_data.load_assignments(person_email);
+CREATE INDEX IF NOT EXISTS idx_capacity_overrides_date ON load_calendar_data.capacity_overrides(entity_id, date);
+
+-- Loads may span several days, from date through end_date (NULL for a
+-- single day). Their weight is split evenly across the days, or with
+-- spread 'per_day' counted in full on each of them.
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS end_date DATE;
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS spread TEXT NOT NULL DEFAULT 'even' CHECK (spread IN ('even', 'per_day'));
+CREATE INDEX IF NOT EXISTS idx_loads_end_date ON load_calendar_data.loads((COALESCE(end_date, date)));
+
+-- Recurring loads repeat daily, weekly or monthly from their date, like
+-- an iCalendar RRULE, until a day or for a number of occurrences. The
+-- load itself is the first occurrence; the server expands the later ones
+-- into load_occurrences through expanded_through, a year ahead, and keeps
+-- extending rules without an end.
+CREATE TABLE IF NOT EXISTS load_calendar_data.recurring_loads (
+	load_id INTEGER PRIMARY KEY REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+	repeat_every INTEGER NOT NULL DEFAULT 1 CHECK (repeat_every >= 1),
+	until_date DATE,
+	occurrence_count INTEGER CHECK (occurrence_count >= 1),
+	expanded_through DATE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_occurrences (
+	load_id INTEGER NOT NULL REFERENCES load_calendar_data.recurring_loads(load_id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	PRIMARY KEY (load_id, date)
+);
+
+-- One row per day a load covers, on each of its occurrences, with the
+-- share of its weight that falls on that day, and the load's source for
+-- viewers hiding some; daily totals sum la.weight * share over it
+CREATE OR REPLACE VIEW load_calendar_data.load_days AS
+SELECT l.id AS load_id, (o.start + d)::date AS date,
+	CASE WHEN l.spread = 'per_day' THEN 1.0
+	     ELSE 1.0 / (COALESCE(l.end_date, l.date) - l.date + 1) END::FLOAT AS share,
+	l.source
+FROM load_calendar_data.loads l
+CROSS JOIN LATERAL (
+	SELECT l.date
+	UNION ALL
+	SELECT lo.date FROM load_calendar_data.load_occurrences lo WHERE lo.load_id = l.id
+) AS o(start)
+CROSS JOIN LATERAL generate_series(0, COALESCE(l.end_date, l.date) - l.date) AS d;
+
+-- Track when heatmap inputs change so polling clients can get 304 Not Modified.
+-- clock_timestamp() rather than NOW() keeps long transactions from stamping
+-- rows earlier than changes a client has already seen.
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp();
+ALTER TABLE load_calendar_data.group_members ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp();
+ALTER TABLE load_calendar_data.capacity_overrides ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp();
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp();
+ALTER TABLE load_calendar_data.load_assignments ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp();
+
+CREATE OR REPLACE FUNCTION load_calendar_data.touch_updated_at() RETURNS trigger AS $$
+BEGIN
+	NEW.updated_at := clock_timestamp();
+	RETURN NEW;
+END $$ LANGUAGE plpgsql;
+
+-- Deleted rows leave no updated_at behind, so deletions (and loads moved to
+-- another date) record a tombstone against the entity whose heatmap changed
+CREATE TABLE IF NOT EXISTS load_calendar_data.heatmap_tombstones (
+	entity_id TEXT NOT NULL,
+	deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp()
+);
+CREATE INDEX IF NOT EXISTS idx_heatmap_tombstones_entity ON load_calendar_data.heatmap_tombstones(entity_id, deleted_at);
+
+CREATE OR REPLACE FUNCTION load_calendar_data.record_heatmap_tombstone() RETURNS trigger AS $$
+BEGIN
+	IF TG_TABLE_NAME = 'load_assignments' THEN
+		INSERT INTO load_calendar_data.heatmap_tombstones (entity_id) VALUES (OLD.person_email);
+	ELSIF TG_TABLE_NAME IN ('capacity_overrides', 'weekly_capacity') THEN
+		INSERT INTO load_calendar_data.heatmap_tombstones (entity_id) VALUES (OLD.entity_id);
+	ELSIF TG_TABLE_NAME = 'group_members' THEN
+		INSERT INTO load_calendar_data.heatmap_tombstones (entity_id) VALUES (OLD.group_id);
+	ELSIF TG_TABLE_NAME = 'loads' THEN
+		INSERT INTO load_calendar_data.heatmap_tombstones (entity_id)
+		SELECT person_email FROM load_calendar_data.load_assignments WHERE load_id = OLD.id;
+	END IF;
+	RETURN NULL;
+END $$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+	t TEXT;
+BEGIN
+	FOREACH t IN ARRAY ARRAY['entities', 'group_members', 'capacity_overrides', 'weekly_capacity', 'loads', 'load_assignments'] LOOP
+		EXECUTE format('DROP TRIGGER IF EXISTS touch_updated_at ON load_calendar_data.%I', t);
+		EXECUTE format('CREATE TRIGGER touch_updated_at BEFORE UPDATE ON load_calendar_data.%I
+			FOR EACH ROW EXECUTE FUNCTION load_calendar_data.touch_updated_at()', t);
+	END LOOP;
+
+	FOREACH t IN ARRAY ARRAY['group_members', 'capacity_overrides', 'weekly_capacity', 'load_assignments'] LOOP
+		EXECUTE format('DROP TRIGGER IF EXISTS record_heatmap_tombstone ON load_calendar_data.%I', t);
+		EXECUTE format('CREATE TRIGGER record_heatmap_tombstone AFTER DELETE ON load_calendar_data.%I
+			FOR EACH ROW EXECUTE FUNCTION load_calendar_data.record_heatmap_tombstone()', t);
+	END LOOP;
+
+	DROP TRIGGER IF EXISTS record_heatmap_tombstone ON load_calendar_data.loads;
+	CREATE TRIGGER record_heatmap_tombstone AFTER UPDATE OF date, end_date ON load_calendar_data.loads
+		FOR EACH ROW WHEN (OLD.date IS DISTINCT FROM NEW.date OR OLD.end_date IS DISTINCT FROM NEW.end_date)
+		EXECUTE FUNCTION load_calendar_data.record_heatmap_tombstone();
+END $$;
+
+-- Precomputed heatmaps, refreshed nightly and recomputed on read once a
+-- write changes the heatmap version they were built from
+CREATE TABLE IF NOT EXISTS load_calendar_data.heatmap_snapshots (
+	entity_id TEXT PRIMARY KEY REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	window_start DATE NOT NULL,
+	window_end DATE NOT NULL,
+	version_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	version_rows BIGINT NOT NULL,
+	days JSONB NOT NULL,
+	computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- What-if scenarios: hypothetical loads and capacity changes overlaid on
+-- real data when rendering. Nothing reads these into production totals.
+CREATE TABLE IF NOT EXISTS load_calendar_data.scenarios (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS load_calendar_data.scenario_loads (
+	id SERIAL PRIMARY KEY,
+	scenario_id INTEGER NOT NULL REFERENCES load_calendar_data.scenarios(id) ON DELETE CASCADE,
+	title TEXT NOT NULL,
+	date DATE NOT NULL,
+	person_email TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	weight FLOAT NOT NULL DEFAULT 1.0
+);
+CREATE INDEX IF NOT EXISTS idx_scenario_loads_scenario_date ON load_calendar_data.scenario_loads(scenario_id, date);
+
+CREATE TABLE IF NOT EXISTS load_calendar_data.scenario_capacity_overrides (
+	scenario_id INTEGER REFERENCES load_calendar_data.scenarios(id) ON DELETE CASCADE,
+	entity_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	capacity FLOAT NOT NULL,
+	PRIMARY KEY (scenario_id, entity_id, date)
+);
+
+-- Person-days that went over capacity: when they first did, and when they
+-- last came back under. A past date still unresolved was never fixed.
+CREATE TABLE IF NOT EXISTS load_calendar_data.overload_days (
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	overloaded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	resolved_at TIMESTAMP WITH TIME ZONE,
+	PRIMARY KEY (person_email, date)
+);
+CREATE INDEX IF NOT EXISTS idx_overload_days_date ON load_calendar_data.overload_days(date);
+
+-- Quarterly utilization reports, kept as generated once the quarter ends
+-- so performance-cycle inputs do not shift with later edits
+CREATE TABLE IF NOT EXISTS load_calendar_data.utilization_reports (
+	quarter TEXT PRIMARY KEY,
+	report JSONB NOT NULL,
+	generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Groups whose anonymized heatmap may be shown on public dashboards
+CREATE TABLE IF NOT EXISTS load_calendar_data.group_dashboards (
+	group_id TEXT PRIMARY KEY REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	enabled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- When each assignee was first given a load and whether they have
+-- acknowledged it. Triggers keep it in step with load_assignments; the
+-- removal check is deferred to commit so upserts, which rewrite a load's
+-- assignments in one transaction, keep existing acknowledgments.
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_acknowledgments (
+	load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	acknowledged_at TIMESTAMP WITH TIME ZONE,
+	reminded_at TIMESTAMP WITH TIME ZONE,
+	PRIMARY KEY (load_id, person_email)
+);
+CREATE INDEX IF NOT EXISTS idx_load_acknowledgments_pending ON load_calendar_data.load_acknowledgments(assigned_at) WHERE acknowledged_at IS NULL AND reminded_at IS NULL;
+
+INSERT INTO load_calendar_data.load_acknowledgments (load_id, person_email)
+SELECT load_id, person_email FROM load_calendar_data.load_assignments
+ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION load_calendar_data.track_load_acknowledgment() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO load_calendar_data.load_acknowledgments (load_id, person_email)
+		VALUES (NEW.load_id, NEW.person_email)
+		ON CONFLICT DO NOTHING;
+	ELSE
+		DELETE FROM load_calendar_data.load_acknowledgments k
+		WHERE k.load_id = OLD.load_id AND k.person_email = OLD.person_email
+		AND NOT EXISTS (
+			SELECT 1 FROM load_calendar_data.load_assignments la
+			WHERE la.load_id = OLD.load_id AND la.person_email = OLD.person_email);
+	END IF;
+	RETURN NULL;
+END $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS track_load_acknowledgment_insert ON load_calendar_data.load_assignments;
+CREATE TRIGGER track_load_acknowledgment_insert AFTER INSERT ON load_calendar_data.load_assignments
+	FOR EACH ROW EXECUTE FUNCTION load_calendar_data.track_load_acknowledgment();
+DROP TRIGGER IF EXISTS track_load_acknowledgment_delete ON load_calendar_data.load_assignments;
+CREATE CONSTRAINT TRIGGER track_load_acknowledgment_delete AFTER DELETE ON load_calendar_data.load_assignments
+	DEFERRABLE INITIALLY DEFERRED
+	FOR EACH ROW EXECUTE FUNCTION load_calendar_data.track_load_acknowledgment();
+
+-- Date ranges in which an entity takes no new loads, such as a release
+-- freeze; a group's blackout covers its members
+CREATE TABLE IF NOT EXISTS load_calendar_data.blackout_dates (
+	id SERIAL PRIMARY KEY,
+	entity_id TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	reason TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	CHECK (end_date >= start_date)
+);
+CREATE INDEX IF NOT EXISTS idx_blackout_dates_entity ON load_calendar_data.blackout_dates(entity_id, end_date);
+
+-- Group owners approve their members' capacity reductions
+CREATE TABLE IF NOT EXISTS load_calendar_data.group_owners (
+	group_id TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	PRIMARY KEY (group_id, person_email)
+);
+
+-- Capacity changes held for approval; change is the UpdateCapacityRequest
+-- applied once an owner approves
+CREATE TABLE IF NOT EXISTS load_calendar_data.capacity_change_requests (
+	id SERIAL PRIMARY KEY,
+	entity_id TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	change JSONB NOT NULL,
+	reason TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	decided_by TEXT,
+	decided_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_capacity_change_requests_pending ON load_calendar_data.capacity_change_requests(entity_id) WHERE status = 'pending';
+
+-- Assistants a person has allowed to manage their capacity
+CREATE TABLE IF NOT EXISTS load_calendar_data.delegations (
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	assistant_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (person_email, assistant_email)
+);
+CREATE INDEX IF NOT EXISTS idx_delegations_assistant ON load_calendar_data.delegations(assistant_email);
+
+-- Capacity changes with who made them; actor_email differs from
+-- person_email when an assistant or approver acted for the person
+CREATE TABLE IF NOT EXISTS load_calendar_data.capacity_audit_log (
+	id SERIAL PRIMARY KEY,
+	person_email TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	actor_email TEXT NOT NULL,
+	action TEXT NOT NULL,
+	detail TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_capacity_audit_log_person ON load_calendar_data.capacity_audit_log(person_email, created_at);
+
+-- Short comments on a load, or on the author's own day when load_id is
+-- unset
+CREATE TABLE IF NOT EXISTS load_calendar_data.notes (
+	id SERIAL PRIMARY KEY,
+	author_email TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	note_date DATE,
+	body TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	CHECK ((load_id IS NULL) <> (note_date IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_notes_load ON load_calendar_data.notes(load_id) WHERE load_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notes_day ON load_calendar_data.notes(note_date, author_email) WHERE note_date IS NOT NULL;
+
+-- Loads each user pinned to show first in day details and heatmap tooltips
+CREATE TABLE IF NOT EXISTS load_calendar_data.pins (
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	pinned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (person_email, load_id)
+);
+
+-- Effort an assignee actually spent on a past load, next to the weight
+-- planned when it was recorded, for calibrating weight rules
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_actuals (
+	load_id INTEGER REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	person_email TEXT REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	planned FLOAT NOT NULL,
+	actual FLOAT NOT NULL CHECK (actual >= 0),
+	recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (load_id, person_email)
+);
+
+-- When a source last upserted each load, and since when the stale load
+-- check has flagged it for not being upserted within its source's
+-- freshness window. Flagged loads wait for review before removal.
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS stale_since TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_loads_stale ON load_calendar_data.loads(date) WHERE stale_since IS NOT NULL;
+
+-- Failed load syncs and webhook deliveries, for the integration health
+-- report. Rows older than a week are pruned as new ones are recorded.
+CREATE TABLE IF NOT EXISTS load_calendar_data.integration_errors (
+	id SERIAL PRIMARY KEY,
+	source TEXT NOT NULL, -- empty when the request did not name one
+	operation TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	message TEXT NOT NULL,
+	occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_integration_errors_occurred ON load_calendar_data.integration_errors(occurred_at);
+
+CREATE TABLE IF NOT EXISTS load_calendar_data.webhook_deliveries (
+	id SERIAL PRIMARY KEY,
+	event TEXT NOT NULL,
+	succeeded BOOLEAN NOT NULL,
+	error TEXT,
+	delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_delivered ON load_calendar_data.webhook_deliveries(delivered_at);
+
+-- Every change to loads, capacity, entities and groups, in order, for
+-- auditing and for rebuilding derived tables. Rows are never updated or
+-- deleted.
+CREATE TABLE IF NOT EXISTS load_calendar_data.domain_events (
+	id BIGSERIAL PRIMARY KEY,
+	type TEXT NOT NULL,
+	actor TEXT NOT NULL DEFAULT '', -- empty for API-key and background changes
+	entity_ids TEXT[] NOT NULL DEFAULT '{}', -- persons and groups whose data changed
+	payload JSONB NOT NULL DEFAULT '{}',
+	occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_domain_events_entity_ids ON load_calendar_data.domain_events USING GIN (entity_ids);
+
+CREATE OR REPLACE FUNCTION load_calendar_data.reject_domain_event_change() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'domain_events is append-only';
+END $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS append_only ON load_calendar_data.domain_events;
+CREATE TRIGGER append_only BEFORE UPDATE OR DELETE ON load_calendar_data.domain_events
+	FOR EACH ROW EXECUTE FUNCTION load_calendar_data.reject_domain_event_change();
+
+-- Create OTP sessions table (in-memory alternative would be better for production)
+CREATE TABLE IF NOT EXISTS load_calendar_data.otp_records (
+	email TEXT PRIMARY KEY,
+	otp TEXT NOT NULL,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create sessions table
+CREATE TABLE IF NOT EXISTS load_calendar_data.sessions (
+	token TEXT PRIMARY KEY,
+	email TEXT NOT NULL,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_email ON load_calendar_data.sessions(email);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS load_calendar_data.auto_created_persons;
@@ -0,0 +1,10 @@
+-- Persons created because a load upsert named an unknown assignee, kept for
+-- review in case the email was mistyped. Confirmed rows stay so they still
+-- count against their source's daily quota; rejecting deletes the person.
+CREATE TABLE IF NOT EXISTS load_calendar_data.auto_created_persons (
+	person_email TEXT PRIMARY KEY REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	source TEXT NOT NULL, -- empty when the upsert did not name one
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	confirmed_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_auto_created_persons_source ON load_calendar_data.auto_created_persons(source, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS load_calendar_data.idx_webhook_deliveries_trace;
+ALTER TABLE load_calendar_data.webhook_deliveries DROP COLUMN IF EXISTS span_id;
+ALTER TABLE load_calendar_data.webhook_deliveries DROP COLUMN IF EXISTS trace_id;
@@ -0,0 +1,6 @@
+-- W3C trace and span IDs of the request, or background job, that caused each
+-- webhook delivery, as also sent in the payload's metadata. NULL for rows
+-- recorded before deliveries were traced.
+ALTER TABLE load_calendar_data.webhook_deliveries ADD COLUMN IF NOT EXISTS trace_id TEXT;
+ALTER TABLE load_calendar_data.webhook_deliveries ADD COLUMN IF NOT EXISTS span_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_trace ON load_calendar_data.webhook_deliveries(trace_id);
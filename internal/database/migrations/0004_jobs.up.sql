@@ -0,0 +1,20 @@
+-- Background jobs for bulk operations that answer 202 and are polled at
+-- /api/jobs/:id. errors holds the items that failed, as
+-- [{"item": 3, "id": "ext-1", "error": "..."}], capped at the first 1000;
+-- error is why the job as a whole failed. Running jobs touch updated_at
+-- regularly, so ones left behind by a stopped server can be failed.
+CREATE TABLE IF NOT EXISTS load_calendar_data.jobs (
+	id BIGSERIAL PRIMARY KEY,
+	kind TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+	total INTEGER NOT NULL,
+	processed INTEGER NOT NULL DEFAULT 0,
+	failed INTEGER NOT NULL DEFAULT 0,
+	errors JSONB NOT NULL DEFAULT '[]',
+	error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	started_at TIMESTAMP WITH TIME ZONE,
+	finished_at TIMESTAMP WITH TIME ZONE,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_unfinished ON load_calendar_data.jobs(updated_at) WHERE status IN ('queued', 'running');
@@ -0,0 +1,2 @@
+ALTER TABLE load_calendar_data.webhook_deliveries DROP COLUMN IF EXISTS endpoint_id;
+DROP TABLE IF EXISTS load_calendar_data.webhook_endpoints;
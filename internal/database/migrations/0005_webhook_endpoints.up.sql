@@ -0,0 +1,17 @@
+-- Webhook destinations managed through /api/webhooks. Each receives only the
+-- events it subscribes to, such as overload_alert or capacity_changed, while
+-- enabled. WEBHOOK_DESTINATION_URL, if still set, receives every event besides.
+CREATE TABLE IF NOT EXISTS load_calendar_data.webhook_endpoints (
+	id SERIAL PRIMARY KEY,
+	url TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	events TEXT[] NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- The endpoint each delivery went to; NULL for WEBHOOK_DESTINATION_URL and
+-- for rows recorded before endpoints existed
+ALTER TABLE load_calendar_data.webhook_deliveries ADD COLUMN IF NOT EXISTS endpoint_id INTEGER
+	REFERENCES load_calendar_data.webhook_endpoints(id) ON DELETE SET NULL;
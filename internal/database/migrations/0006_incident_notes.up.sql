@@ -0,0 +1,11 @@
+-- Incident notes shown on the public /status page, written by admins
+-- (ADMIN_EMAILS). A note is open until resolved_at is set.
+CREATE TABLE IF NOT EXISTS load_calendar_data.incident_notes (
+	id SERIAL PRIMARY KEY,
+	body TEXT NOT NULL,
+	author_email TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	resolved_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_incident_notes_created ON load_calendar_data.incident_notes(created_at DESC);
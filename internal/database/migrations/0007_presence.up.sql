@@ -0,0 +1,10 @@
+-- Who is viewing or editing an entity or a load, refreshed by the pages'
+-- heartbeats. Rows not seen for a while are stale and purged.
+CREATE TABLE IF NOT EXISTS load_calendar_data.presence (
+	subject TEXT NOT NULL, -- "entity:<id>" or "load:<id>"
+	person_email TEXT NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'viewing' CHECK (mode IN ('viewing', 'editing')),
+	seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (subject, person_email)
+);
+CREATE INDEX IF NOT EXISTS idx_presence_seen ON load_calendar_data.presence(seen_at);
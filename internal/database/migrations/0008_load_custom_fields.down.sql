@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS load_calendar_data.custom_field_definitions;
+ALTER TABLE load_calendar_data.loads DROP COLUMN IF EXISTS custom_fields;
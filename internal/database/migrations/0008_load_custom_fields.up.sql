@@ -0,0 +1,18 @@
+-- Extra data sources send with a load, such as a meeting link or a ticket's
+-- priority, kept as sent
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- The custom fields each source is known to send: their type, checked on
+-- upsert, and whether day details show them. Fields without a definition are
+-- kept but neither checked nor shown.
+CREATE TABLE IF NOT EXISTS load_calendar_data.custom_field_definitions (
+	source TEXT NOT NULL,
+	key TEXT NOT NULL,
+	label TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'url')),
+	display BOOLEAN NOT NULL DEFAULT FALSE,
+	position INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (source, key)
+);
@@ -0,0 +1,11 @@
+-- Groups whose upcoming two weeks are posted as a heatmap image to a Lark
+-- chat every Monday. last_sent_on is the Monday of the latest post, claimed
+-- before posting so that only one server posts each week.
+CREATE TABLE IF NOT EXISTS load_calendar_data.lark_digests (
+	group_id TEXT PRIMARY KEY REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE,
+	chat_id TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	last_sent_on DATE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
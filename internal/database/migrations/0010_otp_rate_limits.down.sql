@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS load_calendar_data.rate_limits;
+ALTER TABLE load_calendar_data.otp_records DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,11 @@
+-- Wrong codes entered against each OTP; past the limit the OTP is dropped
+ALTER TABLE load_calendar_data.otp_records ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+
+-- Fixed-window request counters shared by every server, such as OTP
+-- requests per email and per client IP. A key's window restarts with its
+-- first hit after the previous one ended.
+CREATE TABLE IF NOT EXISTS load_calendar_data.rate_limits (
+	key TEXT PRIMARY KEY,
+	window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+	hits INTEGER NOT NULL
+);
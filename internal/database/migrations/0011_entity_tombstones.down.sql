@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS record_entity_tombstone ON load_calendar_data.entities;
+DROP FUNCTION IF EXISTS load_calendar_data.record_entity_tombstone();
+DROP INDEX IF EXISTS load_calendar_data.idx_entities_updated_at;
+DROP TABLE IF EXISTS load_calendar_data.entity_tombstones;
@@ -0,0 +1,21 @@
+-- Deleted entities, so clients syncing the entity list by updated_at learn
+-- which ones to drop. Archived entities keep their row and show up by
+-- updated_at instead.
+CREATE TABLE IF NOT EXISTS load_calendar_data.entity_tombstones (
+	id TEXT NOT NULL,
+	type TEXT,
+	private BOOLEAN NOT NULL DEFAULT FALSE,
+	deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp()
+);
+CREATE INDEX IF NOT EXISTS idx_entity_tombstones_deleted_at ON load_calendar_data.entity_tombstones(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_entities_updated_at ON load_calendar_data.entities(updated_at);
+
+CREATE OR REPLACE FUNCTION load_calendar_data.record_entity_tombstone() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO load_calendar_data.entity_tombstones (id, type, private) VALUES (OLD.id, OLD.type, OLD.private);
+	RETURN NULL;
+END $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_entity_tombstone ON load_calendar_data.entities;
+CREATE TRIGGER record_entity_tombstone AFTER DELETE ON load_calendar_data.entities
+	FOR EACH ROW EXECUTE FUNCTION load_calendar_data.record_entity_tombstone();
@@ -0,0 +1 @@
+ALTER TABLE load_calendar_data.loads DROP COLUMN IF EXISTS confidential_group;
@@ -0,0 +1,4 @@
+-- The group a confidential load belongs to: only the group's members, and
+-- admins, see it in day details and calendar feeds. NULL for loads everyone
+-- may see. A load whose group is deleted stays hidden from everyone else.
+ALTER TABLE load_calendar_data.loads ADD COLUMN IF NOT EXISTS confidential_group TEXT;
@@ -0,0 +1,9 @@
+-- Categories a load is tagged with, such as meeting or project work, so
+-- heatmaps and day details can be filtered and broken down by them. Tags are
+-- lowercase.
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_tags (
+	load_id INTEGER NOT NULL REFERENCES load_calendar_data.loads(id) ON DELETE CASCADE,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (load_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_load_tags_tag ON load_calendar_data.load_tags(tag);
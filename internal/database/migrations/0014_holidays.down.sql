@@ -0,0 +1,2 @@
+ALTER TABLE load_calendar_data.entities DROP COLUMN IF EXISTS region;
+DROP TABLE IF EXISTS load_calendar_data.holidays;
@@ -0,0 +1,11 @@
+-- Public holidays per region, such as a country code. Persons and groups
+-- whose region is set have no capacity on their region's holidays.
+-- updated_at versions the heatmaps they fall on.
+CREATE TABLE IF NOT EXISTS load_calendar_data.holidays (
+	region TEXT NOT NULL,
+	date DATE NOT NULL,
+	name TEXT NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+	PRIMARY KEY (region, date)
+);
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS region TEXT;
@@ -0,0 +1,8 @@
+-- Settings admins edit at runtime, such as heatmap color thresholds, by key
+-- with a JSON value. Keys without a row take their defaults.
+CREATE TABLE IF NOT EXISTS load_calendar_data.settings (
+	key TEXT PRIMARY KEY,
+	value JSONB NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+	updated_by TEXT NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE load_calendar_data.group_members DROP COLUMN IF EXISTS heatmap_excluded;
@@ -0,0 +1,4 @@
+-- Members whose loads a group's heatmap leaves out, such as a lead whose
+-- calendar is always full, so they do not skew the team's utilization.
+-- Their own heatmap is unchanged.
+ALTER TABLE load_calendar_data.group_members ADD COLUMN IF NOT EXISTS heatmap_excluded BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+ALTER TABLE load_calendar_data.entities DROP COLUMN IF EXISTS capacity_mode;
@@ -0,0 +1,6 @@
+-- How a group's daily capacity is worked out: 'static' uses the group's own
+-- default capacity, weekly pattern and overrides; 'members' sums the
+-- effective capacities of its active members, so leave, overrides and
+-- holidays show up on the group heatmap. Persons are always 'static'.
+ALTER TABLE load_calendar_data.entities ADD COLUMN IF NOT EXISTS capacity_mode TEXT NOT NULL DEFAULT 'static'
+    CHECK (capacity_mode IN ('static', 'members'));
@@ -0,0 +1,20 @@
+-- Entity IDs are emails for persons, and people get renamed. Every foreign
+-- key to entities follows a changed ID, so POST /api/entities/:id/change-id
+-- can move an entity's history to its new ID in a single UPDATE.
+DO $$
+DECLARE
+	fk RECORD;
+BEGIN
+	FOR fk IN
+		SELECT con.conrelid::regclass AS tbl, con.conname, att.attname
+		FROM pg_constraint con
+		JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = con.conkey[1]
+		WHERE con.contype = 'f'
+		  AND con.confrelid = 'load_calendar_data.entities'::regclass
+		  AND con.confupdtype <> 'c'
+	LOOP
+		EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I, ADD CONSTRAINT %I FOREIGN KEY (%I)
+			REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE ON UPDATE CASCADE',
+			fk.tbl, fk.conname, fk.conname, fk.attname);
+	END LOOP;
+END $$;
@@ -0,0 +1,14 @@
+-- Requests, errors and payload sizes per API key per UTC day, so admins can
+-- tell which integration is hammering the service or sending malformed
+-- data. Keys are stored by name, never the key itself.
+CREATE TABLE IF NOT EXISTS load_calendar_data.api_key_metrics (
+	key_name TEXT NOT NULL,
+	date DATE NOT NULL,
+	requests BIGINT NOT NULL DEFAULT 0,
+	client_errors BIGINT NOT NULL DEFAULT 0,
+	server_errors BIGINT NOT NULL DEFAULT 0,
+	request_bytes BIGINT NOT NULL DEFAULT 0,
+	response_bytes BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (key_name, date)
+);
+CREATE INDEX IF NOT EXISTS idx_api_key_metrics_date ON load_calendar_data.api_key_metrics(date);
@@ -0,0 +1,10 @@
+-- Freeform notes and important links, such as a team charter or on-call
+-- schedule, that admins keep on an entity and show beside its heatmap. Links
+-- are a JSON array of {"title", "url"} objects, in the order shown.
+CREATE TABLE IF NOT EXISTS load_calendar_data.entity_info (
+	entity_id TEXT PRIMARY KEY REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	notes TEXT NOT NULL DEFAULT '',
+	links JSONB NOT NULL DEFAULT '[]',
+	updated_by TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Person-days whose load spiked over their trailing 30-day average, such as
+-- after an import bug, and were alerted on, so each is alerted on once
+CREATE TABLE IF NOT EXISTS load_calendar_data.load_anomalies (
+	person_email TEXT NOT NULL REFERENCES load_calendar_data.entities(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	date DATE NOT NULL,
+	load DOUBLE PRECISION NOT NULL,
+	baseline DOUBLE PRECISION NOT NULL,
+	alerted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (person_email, date)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS load_calendar_data.idx_scenario_loads_external_id;
+ALTER TABLE load_calendar_data.scenario_loads DROP COLUMN IF EXISTS external_id;
+DROP TABLE IF EXISTS load_calendar_data.shadow_sources;
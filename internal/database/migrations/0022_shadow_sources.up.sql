@@ -0,0 +1,13 @@
+-- Connectors in shadow mode: loads their source upserts are kept in the
+-- source's scenario, by external ID, instead of in loads, so mapping rules
+-- can be checked on preview heatmaps before they count. Deleting the
+-- scenario takes the source out of shadow mode.
+CREATE TABLE IF NOT EXISTS load_calendar_data.shadow_sources (
+	source TEXT PRIMARY KEY,
+	scenario_id INTEGER NOT NULL REFERENCES load_calendar_data.scenarios(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE load_calendar_data.scenario_loads ADD COLUMN IF NOT EXISTS external_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_scenario_loads_external_id
+	ON load_calendar_data.scenario_loads(scenario_id, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS load_calendar_data.oidc_logins;
@@ -0,0 +1,9 @@
+-- OpenID Connect logins in progress: the nonce and PKCE verifier of each
+-- authorization request, by its state, until the identity provider redirects
+-- back or the login expires
+CREATE TABLE IF NOT EXISTS load_calendar_data.oidc_logins (
+	state TEXT PRIMARY KEY,
+	nonce TEXT NOT NULL,
+	code_verifier TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_color.up.sql":   {Data: []byte("ALTER TABLE t ADD COLUMN color TEXT;")},
+		"m/0002_add_color.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN color;")},
+		"m/0001_baseline.up.sql":    {Data: []byte("CREATE TABLE t (id INT);")},
+		"m/0001_baseline.down.sql":  {Data: []byte("DROP TABLE t;")},
+	}
+
+	migrations, err := loadMigrations(fsys, "m")
+	assert.NoError(t, err)
+	if assert.Len(t, migrations, 2) {
+		assert.Equal(t, 1, migrations[0].version, "ordered by version")
+		assert.Equal(t, "baseline", migrations[0].name)
+		assert.Equal(t, "DROP TABLE t;", migrations[0].down)
+		assert.Equal(t, 2, migrations[1].version)
+		assert.Equal(t, "ALTER TABLE t ADD COLUMN color TEXT;", migrations[1].up)
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"missing down": {"m/0001_baseline.up.sql": {Data: []byte("CREATE TABLE t (id INT);")}},
+		"no version":   {"m/baseline.up.sql": {}, "m/baseline.down.sql": {}},
+		"no direction": {"m/0001_baseline.sql": {}},
+		"other file":   {"m/README.md": {}},
+		"two names":    {"m/0001_a.up.sql": {}, "m/0001_b.down.sql": {}},
+	}
+	for name, fsys := range invalid {
+		_, err := loadMigrations(fsys, "m")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	assert.NoError(t, err)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version, "versions are numbered without gaps")
+	}
+}
@@ -0,0 +1,275 @@
+// Package demo generates believable fake teams and workloads for demo
+// environments.
+//
+// Generation is deterministic for a given Options.Seed, so a demo can be
+// rebuilt identically. The generated dataset contains people grouped into
+// teams, recurring meetings, bursts of project work, background tasks, and
+// vacations (zero-capacity overrides with no work assigned).
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// EmailDomain is used for every generated person so demo data is easy to
+// tell apart from real data.
+const EmailDomain = "demo.example.com"
+
+// ExternalIDPrefix prefixes every generated load's external ID.
+const ExternalIDPrefix = "demo-"
+
+// Options controls the size and shape of the generated dataset.
+type Options struct {
+	Teams    int       // Number of teams (groups)
+	TeamSize int       // Persons per team
+	Start    time.Time // First day of generated work
+	Days     int       // Number of days of generated work
+	Seed     int64     // Random seed; the same seed yields the same dataset
+}
+
+// DefaultOptions returns a small dataset starting a month before today and
+// covering the same window as the heatmap.
+func DefaultOptions() Options {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return Options{
+		Teams:    3,
+		TeamSize: 6,
+		Start:    today.AddDate(0, -1, 0),
+		Days:     210,
+		Seed:     1,
+	}
+}
+
+// Dataset is the generated demo data, ready to be seeded.
+type Dataset struct {
+	Persons     []models.Entity
+	Groups      []models.Entity
+	Memberships []models.GroupMember
+	Overrides   []models.CapacityOverride
+	Loads       []models.LoadWithAssignments
+}
+
+// generator carries state while building a dataset.
+type generator struct {
+	opts     Options
+	rng      *rand.Rand
+	ds       *Dataset
+	vacation map[string]map[time.Time]bool
+	seq      int
+}
+
+// Generate builds a dataset from opts.
+func Generate(opts Options) (*Dataset, error) {
+	if opts.Teams < 1 || opts.TeamSize < 1 {
+		return nil, fmt.Errorf("teams and team size must be at least 1")
+	}
+	if opts.Teams > len(teamNames) {
+		return nil, fmt.Errorf("at most %d teams are supported", len(teamNames))
+	}
+	if opts.Days < 1 {
+		return nil, fmt.Errorf("days must be at least 1")
+	}
+
+	g := &generator{
+		opts:     opts,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		ds:       &Dataset{},
+		vacation: make(map[string]map[time.Time]bool),
+	}
+	g.opts.Start = opts.Start.UTC().Truncate(24 * time.Hour)
+
+	used := make(map[string]bool)
+	for t := 0; t < opts.Teams; t++ {
+		team := teamNames[t]
+		groupID := "demo-" + slug(team)
+
+		var members []models.Entity
+		var capacity float64
+		for i := 0; i < opts.TeamSize; i++ {
+			person := g.person(used)
+			members = append(members, person)
+			capacity += person.DefaultCapacity
+			g.ds.Memberships = append(g.ds.Memberships, models.GroupMember{GroupID: groupID, PersonEmail: person.ID})
+		}
+		g.ds.Persons = append(g.ds.Persons, members...)
+		g.ds.Groups = append(g.ds.Groups, models.Entity{
+			ID:              groupID,
+			Title:           team + " Team",
+			Type:            models.EntityTypeGroup,
+			DefaultCapacity: capacity,
+		})
+
+		for _, person := range members {
+			g.vacations(person)
+		}
+		g.meetings(team, members)
+		g.pushes(team, members)
+		for _, person := range members {
+			g.tasks(person)
+		}
+	}
+
+	return g.ds, nil
+}
+
+// person creates a person with a unique name-based email.
+func (g *generator) person(used map[string]bool) models.Entity {
+	var first, last, email string
+	for n := 0; ; n++ {
+		first = firstNames[g.rng.Intn(len(firstNames))]
+		last = lastNames[g.rng.Intn(len(lastNames))]
+		local := strings.ToLower(first + "." + last)
+		if n > 10 {
+			local = fmt.Sprintf("%s%d", local, n)
+		}
+		email = local + "@" + EmailDomain
+		if !used[email] {
+			break
+		}
+	}
+	used[email] = true
+
+	employeeID := fmt.Sprintf("DEMO-%04d", len(used))
+	return models.Entity{
+		ID:              email,
+		Title:           first + " " + last,
+		Type:            models.EntityTypePerson,
+		EmployeeID:      &employeeID,
+		DefaultCapacity: float64(4 + g.rng.Intn(3)),
+	}
+}
+
+// vacations gives roughly a third of people one block of 3-7 workdays off.
+func (g *generator) vacations(person models.Entity) {
+	if g.rng.Intn(3) != 0 {
+		return
+	}
+
+	length := 3 + g.rng.Intn(5)
+	start := g.opts.Start.AddDate(0, 0, g.rng.Intn(g.opts.Days))
+	days := make(map[time.Time]bool)
+	for day := start; len(days) < length && g.inRange(day); day = day.AddDate(0, 0, 1) {
+		if isWeekend(day) {
+			continue
+		}
+		days[day] = true
+		g.ds.Overrides = append(g.ds.Overrides, models.CapacityOverride{EntityID: person.ID, Date: day, Capacity: 0})
+	}
+	g.vacation[person.ID] = days
+}
+
+// meetings adds a daily standup, Monday planning, and a fortnightly retro.
+func (g *generator) meetings(team string, members []models.Entity) {
+	for i := 0; i < g.opts.Days; i++ {
+		day := g.opts.Start.AddDate(0, 0, i)
+		if isWeekend(day) {
+			continue
+		}
+
+		g.load(fmt.Sprintf("%s Standup", team), "gcal", day, members, 0.25)
+
+		switch {
+		case day.Weekday() == time.Monday:
+			g.load(fmt.Sprintf("%s Sprint Planning", team), "gcal", day, members, 1.0)
+		case day.Weekday() == time.Friday && isoWeek(day)%2 == 0:
+			g.load(fmt.Sprintf("%s Retro", team), "gcal", day, members, 0.5)
+		}
+	}
+}
+
+// pushes adds one or two project crunches where part of the team carries
+// heavy work for one to two weeks.
+func (g *generator) pushes(team string, members []models.Entity) {
+	for p := 1 + g.rng.Intn(2); p > 0; p-- {
+		project := projectNames[g.rng.Intn(len(projectNames))]
+		start := g.opts.Start.AddDate(0, 0, g.rng.Intn(g.opts.Days))
+		length := 5 + g.rng.Intn(6)
+
+		crew := make([]models.Entity, 0, len(members))
+		for _, m := range members {
+			if g.rng.Intn(2) == 0 {
+				crew = append(crew, m)
+			}
+		}
+		if len(crew) == 0 {
+			crew = append(crew, members[g.rng.Intn(len(members))])
+		}
+
+		for day, worked := start, 0; worked < length && g.inRange(day); day = day.AddDate(0, 0, 1) {
+			if isWeekend(day) {
+				continue
+			}
+			worked++
+			task := pushTasks[g.rng.Intn(len(pushTasks))]
+			weight := 1.5 + float64(g.rng.Intn(4))*0.5
+			g.load(fmt.Sprintf("%s: %s (%s)", project, task, team), "jira", day, crew, weight)
+		}
+	}
+}
+
+// tasks sprinkles zero to two background tasks per workday.
+func (g *generator) tasks(person models.Entity) {
+	sources := []string{"jira", "lark", "crm"}
+	for i := 0; i < g.opts.Days; i++ {
+		day := g.opts.Start.AddDate(0, 0, i)
+		if isWeekend(day) {
+			continue
+		}
+		for n := g.rng.Intn(3); n > 0; n-- {
+			title := taskTitles[g.rng.Intn(len(taskTitles))]
+			weight := 0.5 + float64(g.rng.Intn(4))*0.5
+			g.load(title, sources[g.rng.Intn(len(sources))], day, []models.Entity{person}, weight)
+		}
+	}
+}
+
+// load appends a load assigned to everyone in assignees who is not on
+// vacation that day. Nothing is added if everyone is away.
+func (g *generator) load(title, source string, day time.Time, assignees []models.Entity, weight float64) {
+	var assignments []models.LoadAssignment
+	for _, a := range assignees {
+		if g.vacation[a.ID][day] {
+			continue
+		}
+		assignments = append(assignments, models.LoadAssignment{PersonEmail: a.ID, Weight: weight})
+	}
+	if len(assignments) == 0 {
+		return
+	}
+
+	g.seq++
+	externalID := fmt.Sprintf("%s%06d", ExternalIDPrefix, g.seq)
+	src := source
+	g.ds.Loads = append(g.ds.Loads, models.LoadWithAssignments{
+		Load: models.Load{
+			ExternalID: &externalID,
+			Title:      title,
+			Source:     &src,
+			Date:       day,
+		},
+		Assignments: assignments,
+	})
+}
+
+// inRange reports whether day falls inside the generated window.
+func (g *generator) inRange(day time.Time) bool {
+	return !day.Before(g.opts.Start) && day.Before(g.opts.Start.AddDate(0, 0, g.opts.Days))
+}
+
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
+
+func isoWeek(day time.Time) int {
+	_, week := day.ISOWeek()
+	return week
+}
+
+func slug(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), " ", "-")
+}
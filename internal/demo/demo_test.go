@@ -0,0 +1,89 @@
+package demo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return Options{
+		Teams:    3,
+		TeamSize: 5,
+		Start:    time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		Days:     60,
+		Seed:     42,
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	first, err := Generate(testOptions())
+	require.NoError(t, err)
+	second, err := Generate(testOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+
+	opts := testOptions()
+	opts.Seed = 7
+	other, err := Generate(opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Persons, other.Persons)
+}
+
+func TestGenerateShape(t *testing.T) {
+	opts := testOptions()
+	ds, err := Generate(opts)
+	require.NoError(t, err)
+
+	assert.Len(t, ds.Groups, opts.Teams)
+	assert.Len(t, ds.Persons, opts.Teams*opts.TeamSize)
+	assert.Len(t, ds.Memberships, opts.Teams*opts.TeamSize)
+	assert.NotEmpty(t, ds.Loads)
+
+	emails := make(map[string]bool)
+	for _, p := range ds.Persons {
+		assert.True(t, strings.HasSuffix(p.ID, "@"+EmailDomain), "unexpected email %s", p.ID)
+		assert.False(t, emails[p.ID], "duplicate email %s", p.ID)
+		emails[p.ID] = true
+	}
+
+	vacation := make(map[string]map[time.Time]bool)
+	for _, o := range ds.Overrides {
+		assert.Equal(t, 0.0, o.Capacity)
+		if vacation[o.EntityID] == nil {
+			vacation[o.EntityID] = make(map[time.Time]bool)
+		}
+		vacation[o.EntityID][o.Date] = true
+	}
+
+	end := opts.Start.AddDate(0, 0, opts.Days)
+	externalIDs := make(map[string]bool)
+	for _, l := range ds.Loads {
+		assert.False(t, isWeekend(l.Load.Date), "load %q on weekend %s", l.Load.Title, l.Load.Date)
+		assert.False(t, l.Load.Date.Before(opts.Start) || !l.Load.Date.Before(end), "load outside range")
+		assert.NotEmpty(t, l.Assignments)
+		assert.False(t, externalIDs[*l.Load.ExternalID], "duplicate external ID")
+		externalIDs[*l.Load.ExternalID] = true
+
+		for _, a := range l.Assignments {
+			assert.True(t, emails[a.PersonEmail], "assignment to unknown person %s", a.PersonEmail)
+			assert.False(t, vacation[a.PersonEmail][l.Load.Date], "%s has work while on vacation", a.PersonEmail)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidOptions(t *testing.T) {
+	opts := testOptions()
+	opts.Teams = len(teamNames) + 1
+	_, err := Generate(opts)
+	assert.Error(t, err)
+
+	opts = testOptions()
+	opts.TeamSize = 0
+	_, err = Generate(opts)
+	assert.Error(t, err)
+}
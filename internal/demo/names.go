@@ -0,0 +1,40 @@
+package demo
+
+// Word lists used to build believable names. They are deliberately plain so
+// demo screenshots do not distract from the heatmap itself.
+
+var firstNames = []string{
+	"Ava", "Ben", "Chloe", "Daniel", "Elena", "Farah", "Gabriel", "Hana",
+	"Ivan", "Julia", "Kenji", "Laila", "Marco", "Nadia", "Omar", "Priya",
+	"Quinn", "Rafael", "Sofia", "Tariq", "Uma", "Victor", "Wen", "Ximena",
+	"Yusuf", "Zara", "Adi", "Bella", "Chen", "Dewi", "Eko", "Fitri",
+}
+
+var lastNames = []string{
+	"Anderson", "Bakker", "Castillo", "Dubois", "Eriksen", "Fernandes",
+	"Gunawan", "Hartono", "Ibrahim", "Jensen", "Kowalski", "Lestari",
+	"Martins", "Nakamura", "Okafor", "Pratama", "Rossi", "Santoso",
+	"Tanaka", "Utomo", "Vargas", "Wijaya", "Yamada", "Zhang",
+}
+
+var teamNames = []string{
+	"Platform", "Payments", "Growth", "Mobile", "Data", "Design",
+	"Support", "Infrastructure", "Security", "Partnerships",
+}
+
+var projectNames = []string{
+	"Checkout Revamp", "Q3 Launch", "Billing Migration", "Search v2",
+	"Onboarding Flow", "Data Warehouse", "Mobile Release", "SOC 2 Audit",
+	"Pricing Update", "API Deprecation",
+}
+
+var taskTitles = []string{
+	"Code review", "Bug triage", "Customer call", "Write spec",
+	"Incident follow-up", "Pair programming", "Interview", "Design review",
+	"Documentation", "Deploy to production", "Vendor meeting", "Report prep",
+}
+
+var pushTasks = []string{
+	"Implementation", "QA pass", "Load testing", "Stakeholder demo",
+	"Release checklist", "Migration dry run", "Bug bash",
+}
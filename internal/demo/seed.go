@@ -0,0 +1,81 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// Seeder writes a Dataset through the repositories.
+type Seeder struct {
+	entityRepo   *repository.EntityRepository
+	groupRepo    *repository.GroupRepository
+	capacityRepo *repository.CapacityRepository
+	loadRepo     *repository.LoadRepository
+}
+
+// NewSeeder creates a new demo seeder
+func NewSeeder(
+	entityRepo *repository.EntityRepository,
+	groupRepo *repository.GroupRepository,
+	capacityRepo *repository.CapacityRepository,
+	loadRepo *repository.LoadRepository,
+) *Seeder {
+	return &Seeder{
+		entityRepo:   entityRepo,
+		groupRepo:    groupRepo,
+		capacityRepo: capacityRepo,
+		loadRepo:     loadRepo,
+	}
+}
+
+// Seed inserts the dataset. It is safe to run repeatedly: existing entities
+// are left alone and loads are upserted by external ID.
+func (s *Seeder) Seed(ctx context.Context, ds *Dataset) error {
+	// Persons first so group memberships can reference them
+	entities := append(append([]models.Entity{}, ds.Persons...), ds.Groups...)
+	for i := range entities {
+		if err := s.createIfMissing(ctx, &entities[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range ds.Memberships {
+		if err := s.groupRepo.AddMember(ctx, m.GroupID, m.PersonEmail); err != nil {
+			return fmt.Errorf("failed to add %s to %s: %w", m.PersonEmail, m.GroupID, err)
+		}
+	}
+
+	for i := range ds.Overrides {
+		if err := s.capacityRepo.SetOverride(ctx, &ds.Overrides[i]); err != nil {
+			return fmt.Errorf("failed to set vacation for %s: %w", ds.Overrides[i].EntityID, err)
+		}
+	}
+
+	for i := range ds.Loads {
+		load := &ds.Loads[i]
+		if _, _, _, err := s.loadRepo.UpsertByExternalID(ctx, &load.Load, load.Assignments, nil); err != nil {
+			return fmt.Errorf("failed to upsert load %s: %w", *load.Load.ExternalID, err)
+		}
+	}
+
+	return nil
+}
+
+// createIfMissing creates entity unless one with the same ID already exists.
+func (s *Seeder) createIfMissing(ctx context.Context, entity *models.Entity) error {
+	exists, err := s.entityRepo.Exists(ctx, entity.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check entity %s: %w", entity.ID, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := s.entityRepo.Create(ctx, entity); err != nil {
+		return fmt.Errorf("failed to create entity %s: %w", entity.ID, err)
+	}
+	return nil
+}
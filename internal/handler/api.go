@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/cache"
+	"github.com/gti/heatmap-internal/internal/middleware"
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
 	"github.com/gti/heatmap-internal/internal/service"
@@ -13,118 +19,251 @@ import (
 )
 
 type APIHandler struct {
-	loadService *service.LoadService
-	entityRepo  *repository.EntityRepository
-	groupRepo   *repository.GroupRepository
-	validate    *validator.Validate
+	loadService        *service.LoadService
+	heatmapService     *service.HeatmapService
+	integrationService *service.IntegrationService
+	settingsService    *service.SettingsService
+	events             *service.EventLog
+	entityRepo         *repository.EntityRepository
+	groupRepo          *repository.GroupRepository
+	renderCache        *cache.RenderCache
+	validate           *validator.Validate
 }
 
 func NewAPIHandler(
 	loadService *service.LoadService,
+	heatmapService *service.HeatmapService,
+	integrationService *service.IntegrationService,
+	settingsService *service.SettingsService,
+	events *service.EventLog,
 	entityRepo *repository.EntityRepository,
 	groupRepo *repository.GroupRepository,
+	renderCache *cache.RenderCache,
 ) *APIHandler {
 	return &APIHandler{
-		loadService: loadService,
-		entityRepo:  entityRepo,
-		groupRepo:   groupRepo,
-		validate:    validator.New(),
+		loadService:        loadService,
+		heatmapService:     heatmapService,
+		integrationService: integrationService,
+		settingsService:    settingsService,
+		events:             events,
+		entityRepo:         entityRepo,
+		groupRepo:          groupRepo,
+		renderCache:        renderCache,
+		validate:           validator.New(),
 	}
 }
 
 // UpsertLoad handles the n8n integration endpoint for creating/updating loads
 // @Summary Upsert a load
-// @Description Create or update a load item with assignments (for n8n integration)
+// @Description Create or update a load item with assignments (for n8n integration). New assignments on an assignee's or their group's blackout dates are listed under blackouts, or rejected with 409 when BLACKOUT_MODE is reject. assignees lists each assignee's resulting total load and capacity on each day of the load, the first occurrence of a recurring one, and whether they are overloaded, so workflows can branch on overloads without waiting for the overload_alert webhook. A tombstone, {"external_id": ..., "deleted": true}, deletes the load instead, as DELETE /api/loads/by-external-id/{external_id} does, answering with the deleted load or 404. A load spanning several days sets end_date; its weights are split evenly across the days, or with spread per_day counted in full on each. A recurrence repeats the load daily, weekly or monthly, every interval days, weeks or months from date, until a day or for count occurrences, or without end; the server expands its occurrences, so the source only sends the load once. Unknown assignees are created as persons and queued for review at GET /api/people/auto-created, up to AUTO_CREATE_DAILY_LIMIT a day per source; with AUTO_CREATE_PERSONS=false they are rejected instead. custom_fields are kept with the load as sent; those defined for the source at /api/custom-fields must have the defined type. Loads from a source in shadow mode, see /api/shadow-sources, are kept in its scenario instead and answer with shadow true.
 // @Tags Loads
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param load body models.UpsertLoadRequest true "Load data to upsert"
-// @Success 200 {object} map[string]interface{} "Success with load ID"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
+// @Success 200 {object} models.UpsertLoadResponse "Load ID, blackouts the new assignments fall on, and each assignee's resulting load and capacity"
+// @Failure 400 {object} map[string]string "Invalid request body, dates, recurrence, custom fields or confidential group"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Tombstoned load not found, or an unknown assignee while auto-creation is disabled"
+// @Failure 409 {object} map[string]string "Assignee on a blackout date"
+// @Failure 429 {object} map[string]string "Source reached its daily quota of auto-created persons"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/loads/upsert [post]
 func (h *APIHandler) UpsertLoad(c echo.Context) error {
 	var req models.UpsertLoadRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "invalid request body",
-		})
+		return h.syncFailed(c, req.Source, http.StatusBadRequest, "invalid request body")
+	}
+
+	// Tombstones only need the external ID
+	if req.Deleted {
+		if req.ExternalID == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "external_id is required",
+			})
+		}
+		return h.deleteByExternalID(c, req.Source, req.ExternalID)
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return h.syncFailed(c, req.Source, http.StatusBadRequest, err.Error())
 	}
 
-	loadID, err := h.loadService.UpsertLoad(c.Request().Context(), &req)
+	resp, err := h.loadService.UpsertLoad(c.Request().Context(), &req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		if errors.Is(err, service.ErrOutOfScope) {
+			return h.syncFailed(c, req.Source, http.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, service.ErrUnknownAssignee) {
+			return h.syncFailed(c, req.Source, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, repository.ErrAutoCreateQuota) {
+			return h.syncFailed(c, req.Source, http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, service.ErrBlackout) {
+			return h.syncFailed(c, req.Source, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrInvalidRecurrence) ||
+			errors.Is(err, service.ErrInvalidCustomField) || errors.Is(err, service.ErrInvalidConfidentialGroup) {
+			return h.syncFailed(c, req.Source, http.StatusBadRequest, err.Error())
+		}
+		return h.syncFailed(c, req.Source, http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"load_id": loadID,
-	})
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // UpsertLoadByEmployeeID handles the endpoint for creating/updating loads using employee_id
 // @Summary Upsert a load by employee ID
-// @Description Create or update a load item with assignments using employee_id instead of email
+// @Description Create or update a load item with assignments using employee_id instead of email. Blackout dates, multi-day and recurring loads, custom fields, tombstones and the assignees' resulting loads are handled as for /api/loads/upsert.
 // @Tags Loads
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param load body models.UpsertLoadByEmployeeIDRequest true "Load data to upsert"
-// @Success 200 {object} map[string]interface{} "Success with load ID"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
+// @Success 200 {object} models.UpsertLoadResponse "Load ID, blackouts the new assignments fall on, and each assignee's resulting load and capacity"
+// @Failure 400 {object} map[string]string "Invalid request body, dates, recurrence, custom fields or confidential group"
 // @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Assignee not found"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Assignee, or tombstoned load, not found"
+// @Failure 409 {object} map[string]string "Assignee on a blackout date"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/loads/upsert-by-employee-id [post]
 func (h *APIHandler) UpsertLoadByEmployeeID(c echo.Context) error {
 	var req models.UpsertLoadByEmployeeIDRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "invalid request body",
-		})
+		return h.syncFailed(c, req.Source, http.StatusBadRequest, "invalid request body")
+	}
+
+	// Tombstones only need the external ID
+	if req.Deleted {
+		if req.ExternalID == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "external_id is required",
+			})
+		}
+		return h.deleteByExternalID(c, req.Source, req.ExternalID)
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return h.syncFailed(c, req.Source, http.StatusBadRequest, err.Error())
 	}
 
-	loadID, err := h.loadService.UpsertLoadByEmployeeID(c.Request().Context(), &req)
+	resp, err := h.loadService.UpsertLoadByEmployeeID(c.Request().Context(), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return h.syncFailed(c, req.Source, http.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, service.ErrBlackout) {
+			return h.syncFailed(c, req.Source, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrInvalidRecurrence) ||
+			errors.Is(err, service.ErrInvalidCustomField) || errors.Is(err, service.ErrInvalidConfidentialGroup) {
+			return h.syncFailed(c, req.Source, http.StatusBadRequest, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": err.Error(),
-			})
+			return h.syncFailed(c, req.Source, http.StatusNotFound, err.Error())
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
+		return h.syncFailed(c, req.Source, http.StatusInternalServerError, err.Error())
+	}
+
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpsertLoadFromIssue keeps the load of an issue tracker issue up to date
+// @Summary Upsert a load from an issue webhook
+// @Description Point a Jira webhook for issue created, updated and deleted events here, or send the same shape from another tracker, to keep sprint work on the heatmap. The issue key is the load's external_id and its source is jira; the load falls on the issue's due date (fields.duedate), assigned to fields.assignee.emailAddress and titled with the key and fields.summary. Its weight is the issue's story points, from ISSUE_STORY_POINTS_FIELD (default customfield_10016), times ISSUE_POINT_WEIGHT (default 1); without points, fields.timeoriginalestimate is the duration the weight rules go by. An issue that is deleted, done (status category done), unassigned or without a due date has its load deleted, or is ignored when it has none. Blackout dates, auto-created assignees and the assignees' resulting loads are handled as for /api/loads/upsert.
+// @Tags Loads
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param issue body models.IssueWebhook true "Jira-style issue webhook"
+// @Success 200 {object} models.IssueLoadResult "Whether the issue's load was upserted, deleted or ignored, and the upsert's response"
+// @Failure 400 {object} map[string]string "Invalid request body, issue key or due date"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Unknown assignee while auto-creation is disabled"
+// @Failure 409 {object} map[string]string "Assignee on a blackout date"
+// @Failure 429 {object} map[string]string "Source reached its daily quota of auto-created persons"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/from-issue [post]
+func (h *APIHandler) UpsertLoadFromIssue(c echo.Context) error {
+	source := service.IssueSource
+
+	var hook models.IssueWebhook
+	if err := c.Bind(&hook); err != nil {
+		return h.syncFailed(c, source, http.StatusBadRequest, "invalid request body")
+	}
+
+	result, err := h.loadService.UpsertFromIssue(c.Request().Context(), &hook)
+	if err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return h.syncFailed(c, source, http.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, service.ErrUnknownAssignee) {
+			return h.syncFailed(c, source, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, repository.ErrAutoCreateQuota) {
+			return h.syncFailed(c, source, http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, service.ErrBlackout) {
+			return h.syncFailed(c, source, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidIssue) || errors.Is(err, service.ErrInvalidDate) {
+			return h.syncFailed(c, source, http.StatusBadRequest, err.Error())
+		}
+		return h.syncFailed(c, source, http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// syncFailed answers a failed upsert, recording it against the source for
+// the integration health report
+func (h *APIHandler) syncFailed(c echo.Context, source string, status int, message string) error {
+	// Recorded even when the request timed out, which is worth knowing about;
+	// dry runs are not syncs
+	if service.DryRun(c.Request().Context()) == nil {
+		h.integrationService.RecordError(context.WithoutCancel(c.Request().Context()), source, c.Request().URL.Path, status, message)
+	}
+	return c.JSON(status, map[string]string{"error": message})
+}
+
+// scopeError answers a failed key scope check, 403 for writes outside a
+// group-scoped API key's groups
+func scopeError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrOutOfScope) {
+		return c.JSON(http.StatusForbidden, map[string]string{
 			"error": err.Error(),
 		})
 	}
-
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"load_id": loadID,
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": err.Error(),
 	})
 }
 
 // ListEntities returns all entities
 // @Summary List all entities
-// @Description Returns all entities or filters by type (person/group)
+// @Description Returns all entities or filters by type (person/group). Private persons are listed only for viewers who may see their heatmap: themselves, owners of their groups, and admins.
 // @Tags Entities
 // @Produce json
 // @Param type query string false "Filter by entity type (person or group)"
+// @Param If-None-Match header string false "ETag of a previous response"
 // @Success 200 {array} models.Entity "List of entities"
+// @Success 304 "List unchanged since the given ETag"
+// @Header 200 {string} ETag "List version"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/entities [get]
 func (h *APIHandler) ListEntities(c echo.Context) error {
@@ -141,6 +280,9 @@ func (h *APIHandler) ListEntities(c echo.Context) error {
 	default:
 		entities, err = h.entityRepo.ListAll(c.Request().Context())
 	}
+	if err == nil {
+		entities, err = h.heatmapService.VisibleEntities(c.Request().Context(), middleware.GetUserEmail(c), entities)
+	}
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -151,9 +293,61 @@ func (h *APIHandler) ListEntities(c echo.Context) error {
 	return c.JSON(http.StatusOK, entities)
 }
 
+// ListEntityChanges returns what changed in the entity list since a time
+// @Summary List entity changes
+// @Description Returns the entities created or updated after since, and the IDs of those archived or deleted after it, for clients keeping a copy of the entity list in sync without fetching all of it. Pass until from the response as the next since; an entity may be listed again when it changed while the list was read. Private persons the viewer may not see are left out, so a person who turns private is not reported as removed.
+// @Tags Entities
+// @Produce json
+// @Param since query string true "RFC 3339 time, usually until from the previous response"
+// @Success 200 {object} models.EntityChanges "Changes since the given time"
+// @Failure 400 {object} map[string]string "Missing or invalid since"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/entities/changes [get]
+func (h *APIHandler) ListEntityChanges(c echo.Context) error {
+	since, err := time.Parse(time.RFC3339Nano, c.QueryParam("since"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "since must be an RFC 3339 time",
+		})
+	}
+
+	ctx := c.Request().Context()
+	viewer := middleware.GetUserEmail(c)
+	changed, deleted, until, err := h.entityRepo.ListChangedSince(ctx, since)
+	if err == nil {
+		changed, err = h.heatmapService.VisibleEntities(ctx, viewer, changed)
+	}
+	if err == nil {
+		deleted, err = h.heatmapService.VisibleEntities(ctx, viewer, deleted)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	changes := models.EntityChanges{Changed: []models.Entity{}, Removed: []string{}, Until: until}
+	listed := make(map[string]bool, len(changed))
+	for _, e := range changed {
+		listed[e.ID] = true
+		if e.ArchivedAt != nil {
+			changes.Removed = append(changes.Removed, e.ID)
+		} else {
+			changes.Changed = append(changes.Changed, e)
+		}
+	}
+	// An ID deleted and then created again is listed as it is now
+	for _, e := range deleted {
+		if !listed[e.ID] {
+			changes.Removed = append(changes.Removed, e.ID)
+		}
+	}
+	return c.JSON(http.StatusOK, changes)
+}
+
 // GetEntity returns a single entity by ID
 // @Summary Get entity by ID
-// @Description Returns a single entity by its ID
+// @Description Returns a single entity by its ID. Private persons are not found for viewers who may not see their heatmap.
 // @Tags Entities
 // @Produce json
 // @Param id path string true "Entity ID (email for persons, string ID for groups)"
@@ -165,8 +359,11 @@ func (h *APIHandler) GetEntity(c echo.Context) error {
 	id := c.Param("id")
 
 	entity, err := h.entityRepo.GetByID(c.Request().Context(), id)
+	if err == nil {
+		err = h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), id)
+	}
 	if err != nil {
-		if errors.Is(err, repository.ErrEntityNotFound) {
+		if errors.Is(err, repository.ErrEntityNotFound) || errors.Is(err, service.ErrHeatmapPrivate) {
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "entity not found",
 			})
@@ -179,18 +376,72 @@ func (h *APIHandler) GetEntity(c echo.Context) error {
 	return c.JSON(http.StatusOK, entity)
 }
 
+// GetEntityCalendar returns an entity's loads as an iCalendar feed
+// @Summary Entity calendar feed
+// @Description The loads of a person, or of any member of a group, from 90 days ago to a year ahead as an iCalendar (.ics) feed, to subscribe to from Google Calendar or Outlook. Each load is one all-day event over its days, with its assignees and weights in the description; recurring loads repeat through an RRULE. Private persons are not found for viewers who may not see their heatmap, and are left out of other feeds.
+// @Tags Entities
+// @Produce text/calendar
+// @Param id path string true "Entity ID (email for persons, string ID for groups)"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/entities/{id}/calendar.ics [get]
+func (h *APIHandler) GetEntityCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	viewer := middleware.GetUserEmail(c)
+
+	entity, err := h.entityRepo.GetByID(ctx, id)
+	if err == nil {
+		err = h.heatmapService.CheckVisible(ctx, viewer, id)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) || errors.Is(err, service.ErrHeatmapPrivate) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "entity not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	now := time.Now().UTC()
+	loads, err := h.loadService.CalendarLoads(ctx, entity, now.Truncate(24*time.Hour))
+	if err == nil {
+		loads, err = h.heatmapService.HidePrivateAssignees(ctx, viewer, loads)
+	}
+	if err == nil {
+		loads, err = h.heatmapService.HideConfidentialLoads(ctx, viewer, loads)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="calendar.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", service.RenderICS(entity.Title, loads, now))
+}
+
 // CreateEntity creates a new entity
 // @Summary Create a new entity
-// @Description Create a new person or group entity
+// @Description Create a new person or group entity. When the ID is taken the existing entity is returned with 409, unless upsert is set: then an active entity of the same type is updated with the fields given, and fields left out keep their values, as with PUT. Archived persons are not restored; onboard them instead. Without default_capacity the entity gets the default capacity from the settings, 5.0 unless an admin changed it. A group with capacity_mode members has the summed effective capacities of its active members each day, in place of its own.
 // @Tags Entities
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param entity body models.CreateEntityRequest true "Entity to create"
+// @Param upsert query bool false "Update the entity if it exists, for idempotent directory syncs"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
+// @Success 200 {object} models.Entity "Existing entity, updated by an upsert"
 // @Success 201 {object} models.Entity "Created entity"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} map[string]string "Invalid request body, a private group, or a person with member capacities"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 409 {object} models.EntityConflictResponse "ID taken, with the existing entity"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/entities [post]
 func (h *APIHandler) CreateEntity(c echo.Context) error {
 	var req models.CreateEntityRequest
@@ -200,15 +451,41 @@ func (h *APIHandler) CreateEntity(c echo.Context) error {
 		})
 	}
 
+	upsert := false
+	if s := c.QueryParam("upsert"); s != "" {
+		var err error
+		if upsert, err = strconv.ParseBool(s); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "upsert must be true or false",
+			})
+		}
+	}
+
 	if err := h.validate.Struct(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
+	if _, scoped := service.KeyScope(c.Request().Context()); scoped && req.Type != string(models.EntityTypePerson) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "group-scoped API keys cannot create groups",
+		})
+	}
+	if req.Private && req.Type != string(models.EntityTypePerson) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "only persons can be private",
+		})
+	}
+	if req.CapacityMode == models.CapacityModeMembers && req.Type != string(models.EntityTypeGroup) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "only groups can sum member capacities",
+		})
+	}
+
 	capacity := req.DefaultCapacity
 	if capacity == 0 {
-		capacity = 5.0 // Default
+		capacity = h.settingsService.DefaultCapacity()
 	}
 
 	entity := &models.Entity{
@@ -217,31 +494,123 @@ func (h *APIHandler) CreateEntity(c echo.Context) error {
 		Type:            models.EntityType(req.Type),
 		EmployeeID:      req.EmployeeID,
 		DefaultCapacity: capacity,
+		Skills:          req.Skills,
+		ManagerEmail:    req.ManagerEmail,
+		Private:         req.Private,
+		Region:          service.NormalizeRegion(req.Region),
+		CapacityMode:    req.CapacityMode,
+	}
+
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		exists, err := h.entityRepo.Exists(c.Request().Context(), entity.ID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if exists {
+			return h.createExistingEntity(c, &req, upsert)
+		}
+		dry.Action = models.DryRunCreate
+		dry.Result = entity
+		return c.JSON(http.StatusOK, dry)
 	}
 
-	if err := h.entityRepo.Create(c.Request().Context(), entity); err != nil {
+	err := h.entityRepo.Create(c.Request().Context(), entity)
+	if errors.Is(err, repository.ErrEntityExists) {
+		return h.createExistingEntity(c, &req, upsert)
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
 	}
+	h.events.Record(c.Request().Context(), models.EventEntityCreated, "", []string{entity.ID}, entity)
 
 	return c.JSON(http.StatusCreated, entity)
 }
 
+// createExistingEntity answers a create whose ID is taken: with the existing
+// entity and 409, or for an upsert of an active entity of the same type, by
+// updating it with the fields the request sets
+func (h *APIHandler) createExistingEntity(c echo.Context, req *models.CreateEntityRequest, upsert bool) error {
+	ctx := c.Request().Context()
+	existing, err := h.entityRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	conflict := models.EntityConflictResponse{Error: "entity already exists", Entity: existing}
+	if err := h.loadService.CheckEntityScope(ctx, existing.ID); err != nil {
+		if !errors.Is(err, service.ErrOutOfScope) {
+			return scopeError(c, err)
+		}
+		conflict.Entity = nil
+		return c.JSON(http.StatusConflict, conflict)
+	}
+	if !upsert || string(existing.Type) != req.Type || existing.ArchivedAt != nil {
+		return c.JSON(http.StatusConflict, conflict)
+	}
+
+	existing.Title = req.Title
+	if req.EmployeeID != nil {
+		existing.EmployeeID = req.EmployeeID
+	}
+	if req.DefaultCapacity != 0 {
+		existing.DefaultCapacity = req.DefaultCapacity
+	}
+	if req.Skills != nil {
+		existing.Skills = req.Skills
+	}
+	if req.ManagerEmail != nil {
+		existing.ManagerEmail = req.ManagerEmail
+	}
+	if req.Private {
+		existing.Private = true
+	}
+	if req.Region != nil {
+		existing.Region = service.NormalizeRegion(req.Region)
+	}
+	if req.CapacityMode != "" {
+		existing.CapacityMode = req.CapacityMode
+	}
+
+	if dry := service.DryRun(ctx); dry != nil {
+		dry.Action = models.DryRunUpdate
+		dry.Result = existing
+		return c.JSON(http.StatusOK, dry)
+	}
+
+	if err := h.entityRepo.Update(ctx, existing); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	h.renderCache.Invalidate(ctx, existing.ID)
+	h.events.Record(ctx, models.EventEntityUpdated, "", []string{existing.ID}, existing)
+
+	return c.JSON(http.StatusOK, existing)
+}
+
 // UpdateEntity updates an existing entity
 // @Summary Update an entity
-// @Description Update an entity's title, employee_id, and/or default_capacity
+// @Description Update an entity's title, employee_id, default_capacity, skills, manager_email, private, region, and/or capacity_mode. A group with capacity_mode members has the summed effective capacities of its active members each day, in place of its own.
 // @Tags Entities
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Entity ID"
 // @Param entity body models.UpdateEntityRequest true "Entity fields to update"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} models.Entity "Updated entity"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} map[string]string "Invalid request body, a private group, or a person with member capacities"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 404 {object} map[string]string "Entity not found"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/entities/{id} [put]
 func (h *APIHandler) UpdateEntity(c echo.Context) error {
 	id := c.Param("id")
@@ -265,6 +634,9 @@ func (h *APIHandler) UpdateEntity(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), id); err != nil {
+		return scopeError(c, err)
+	}
 
 	// Update fields if provided
 	if req.Title != nil {
@@ -276,6 +648,48 @@ func (h *APIHandler) UpdateEntity(c echo.Context) error {
 	if req.DefaultCapacity != nil {
 		entity.DefaultCapacity = *req.DefaultCapacity
 	}
+	if req.Skills != nil {
+		entity.Skills = req.Skills
+	}
+	if req.ManagerEmail != nil {
+		entity.ManagerEmail = req.ManagerEmail
+		if *req.ManagerEmail == "" {
+			entity.ManagerEmail = nil
+		}
+	}
+	if req.Private != nil {
+		if *req.Private && entity.Type != models.EntityTypePerson {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "only persons can be private",
+			})
+		}
+		entity.Private = *req.Private
+	}
+	if req.Region != nil {
+		entity.Region = service.NormalizeRegion(req.Region)
+	}
+	if req.CapacityMode != nil {
+		switch *req.CapacityMode {
+		case models.CapacityModeStatic:
+		case models.CapacityModeMembers:
+			if entity.Type != models.EntityTypeGroup {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "only groups can sum member capacities",
+				})
+			}
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "capacity_mode must be static or members",
+			})
+		}
+		entity.CapacityMode = *req.CapacityMode
+	}
+
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		dry.Action = models.DryRunUpdate
+		dry.Result = entity
+		return c.JSON(http.StatusOK, dry)
+	}
 
 	// Save updated entity
 	if err := h.entityRepo.Update(c.Request().Context(), entity); err != nil {
@@ -283,10 +697,47 @@ func (h *APIHandler) UpdateEntity(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	h.renderCache.Invalidate(c.Request().Context(), id)
+	h.events.Record(c.Request().Context(), models.EventEntityUpdated, "", []string{id}, entity)
 
 	return c.JSON(http.StatusOK, entity)
 }
 
+// GetDeletePreview counts what deleting an entity would remove
+// @Summary Preview an entity deletion
+// @Description Counts what DELETE /api/entities/{id} would remove along with the entity: the person's load assignments, the loads not yet over that would lose them and how many of those would be left with no assignee, group memberships, capacity overrides, and weekly capacity. Nothing is changed.
+// @Tags Entities
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Success 200 {object} models.EntityDeletePreview "What the deletion would remove"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/delete-preview [get]
+func (h *APIHandler) GetDeletePreview(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), id); err != nil {
+		return scopeError(c, err)
+	}
+
+	preview, err := h.entityRepo.GetDeletePreview(c.Request().Context(), id, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "entity not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
 // DeleteEntity deletes an entity
 // @Summary Delete an entity
 // @Description Delete an entity by its ID
@@ -294,13 +745,37 @@ func (h *APIHandler) UpdateEntity(c echo.Context) error {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Entity ID"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 404 {object} map[string]string "Entity not found"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/entities/{id} [delete]
 func (h *APIHandler) DeleteEntity(c echo.Context) error {
 	id := c.Param("id")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), id); err != nil {
+		return scopeError(c, err)
+	}
+
+	// A dry run answers with what the deletion would remove, as the preview
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		preview, err := h.entityRepo.GetDeletePreview(c.Request().Context(), id, time.Now().UTC())
+		if err != nil {
+			if errors.Is(err, repository.ErrEntityNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "entity not found",
+				})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		dry.Action = models.DryRunDelete
+		dry.Result = preview
+		return c.JSON(http.StatusOK, dry)
+	}
 
 	if err := h.entityRepo.Delete(c.Request().Context(), id); err != nil {
 		if errors.Is(err, repository.ErrEntityNotFound) {
@@ -313,11 +788,83 @@ func (h *APIHandler) DeleteEntity(c echo.Context) error {
 		})
 	}
 
+	// Memberships are gone with the entity, so its groups cannot be looked up
+	h.renderCache.InvalidateAll()
+	h.events.Record(c.Request().Context(), models.EventEntityDeleted, "", []string{id}, map[string]string{"id": id})
+
 	return c.JSON(http.StatusOK, map[string]string{
 		"success": "entity deleted",
 	})
 }
 
+// ArchiveEntity archives an entity
+// @Summary Archive an entity
+// @Description Hide a person or group from entity lists and heatmap selectors without deleting anything: its loads, memberships, capacity and history are kept, and an archived person cannot log in. Restore it with POST /api/entities/{id}/restore.
+// @Tags Entities
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Success 200 {object} models.Entity "Archived entity"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 409 {object} map[string]string "Entity is already archived"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/archive [post]
+func (h *APIHandler) ArchiveEntity(c echo.Context) error {
+	return h.setArchived(c, h.entityRepo.Archive, models.EventEntityArchived)
+}
+
+// RestoreEntity restores an archived entity
+// @Summary Restore an archived entity
+// @Description Bring an archived person or group back into entity lists and heatmap selectors, with the loads, memberships and capacity it had. Assignments an offboarding removed stay removed.
+// @Tags Entities
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Success 200 {object} models.Entity "Restored entity"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 409 {object} map[string]string "Entity is not archived"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/restore [post]
+func (h *APIHandler) RestoreEntity(c echo.Context) error {
+	return h.setArchived(c, h.entityRepo.Restore, models.EventEntityRestored)
+}
+
+// setArchived archives or restores the entity of the request with change,
+// recording it as eventType, and answers with the entity as it is now
+func (h *APIHandler) setArchived(c echo.Context, change func(context.Context, string) error, eventType models.DomainEventType) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	if err := h.loadService.CheckEntityScope(ctx, id); err != nil {
+		return scopeError(c, err)
+	}
+
+	if err := change(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "entity not found"})
+		case errors.Is(err, repository.ErrEntityArchived), errors.Is(err, repository.ErrNotArchived):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Groups summing member capacity count only active members
+	h.renderCache.InvalidateAll()
+	h.events.Record(ctx, eventType, "", []string{id}, map[string]string{"id": id})
+
+	entity, err := h.entityRepo.GetByID(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, entity)
+}
+
 // GetGroupMembers returns members of a group
 // @Summary Get group members
 // @Description Returns all members of a group
@@ -328,6 +875,7 @@ func (h *APIHandler) DeleteEntity(c echo.Context) error {
 // @Success 200 {object} map[string]interface{} "Group members"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/groups/{id}/members [get]
 func (h *APIHandler) GetGroupMembers(c echo.Context) error {
 	groupID := c.Param("id")
@@ -357,8 +905,10 @@ func (h *APIHandler) GetGroupMembers(c echo.Context) error {
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 400 {object} map[string]string "Invalid request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 404 {object} map[string]string "Group or person not found"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/groups/{id}/members [post]
 func (h *APIHandler) AddGroupMember(c echo.Context) error {
 	groupID := c.Param("id")
@@ -388,6 +938,9 @@ func (h *APIHandler) AddGroupMember(c echo.Context) error {
 			"error": "entity is not a group",
 		})
 	}
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
 
 	// Verify person exists
 	person, err := h.entityRepo.GetByID(c.Request().Context(), req.PersonEmail)
@@ -407,6 +960,8 @@ func (h *APIHandler) AddGroupMember(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	h.renderCache.Invalidate(c.Request().Context(), groupID)
+	h.events.Record(c.Request().Context(), models.EventGroupMemberAdded, "", []string{groupID, req.PersonEmail}, req)
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"success": "member added",
@@ -423,37 +978,361 @@ func (h *APIHandler) AddGroupMember(c echo.Context) error {
 // @Param member path string true "Member email to remove"
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/groups/{id}/members/{member} [delete]
 func (h *APIHandler) RemoveGroupMember(c echo.Context) error {
 	groupID := c.Param("id")
 	memberEmail := c.Param("member")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
 
 	if err := h.groupRepo.RemoveMember(c.Request().Context(), groupID, memberEmail); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
 	}
+	h.renderCache.Invalidate(c.Request().Context(), groupID)
+	h.events.Record(c.Request().Context(), models.EventGroupMemberRemoved, "", []string{groupID, memberEmail},
+		models.AddGroupMemberRequest{PersonEmail: memberEmail})
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"success": "member removed",
 	})
 }
 
-// AddAssigneesToLoad adds one or more assignees to an existing load
-// @Summary Add assignees to a load
-// @Description Add one or more assignees to an existing load with optional weight
-// @Tags Loads
-// @Accept json
+// GetGroupHeatmapExclusions returns the members a group's heatmap leaves out
+// @Summary Get members excluded from a group heatmap
+// @Description Returns the members whose loads the group's heatmap, utilization, digests and overload alerts leave out, such as a lead whose calendar is always full. Their own heatmaps are unchanged.
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} map[string]interface{} "Excluded members"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/heatmap-exclusions [get]
+func (h *APIHandler) GetGroupHeatmapExclusions(c echo.Context) error {
+	groupID := c.Param("id")
+
+	members, err := h.groupRepo.GetHeatmapExcluded(c.Request().Context(), groupID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group_id": groupID,
+		"members":  members,
+	})
+}
+
+// ExcludeGroupMember leaves a member's loads out of a group's heatmap
+// @Summary Exclude member from group heatmap
+// @Description Leave a member's loads out of the group's heatmap, utilization, digests and overload alerts, so a figurehead whose calendar is always full does not skew the team's. The member stays in the group and keeps their own heatmap. To leave members out of a single request instead, pass exclude to GET /api/heatmap/{entity}.
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param member path string true "Member email to exclude"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Not a member of the group"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/heatmap-exclusions/{member} [put]
+func (h *APIHandler) ExcludeGroupMember(c echo.Context) error {
+	return h.setHeatmapExcluded(c, true)
+}
+
+// IncludeGroupMember counts an excluded member's loads in a group's heatmap
+// again
+// @Summary Include member in group heatmap again
+// @Description Count a member's loads in the group's heatmap, utilization, digests and overload alerts again
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param member path string true "Member email to include"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Not a member of the group"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/heatmap-exclusions/{member} [delete]
+func (h *APIHandler) IncludeGroupMember(c echo.Context) error {
+	return h.setHeatmapExcluded(c, false)
+}
+
+// setHeatmapExcluded leaves the member in the path out of the group's
+// heatmap, or counts them again
+func (h *APIHandler) setHeatmapExcluded(c echo.Context, excluded bool) error {
+	groupID := c.Param("id")
+	memberEmail := c.Param("member")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	member, err := h.groupRepo.SetHeatmapExcluded(c.Request().Context(), groupID, memberEmail, excluded)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if !member {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "not a member of the group",
+		})
+	}
+	h.renderCache.Invalidate(c.Request().Context(), groupID)
+
+	event, message := models.EventGroupMemberExcluded, "member excluded from the group heatmap"
+	if !excluded {
+		event, message = models.EventGroupMemberIncluded, "member included in the group heatmap"
+	}
+	h.events.Record(c.Request().Context(), event, "", []string{groupID, memberEmail},
+		models.AddGroupMemberRequest{PersonEmail: memberEmail})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": message,
+	})
+}
+
+// GetGroupOwners returns owners of a group
+// @Summary Get group owners
+// @Description Returns the owners of a group, who approve its members' capacity changes
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} map[string]interface{} "Group owners"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/owners [get]
+func (h *APIHandler) GetGroupOwners(c echo.Context) error {
+	groupID := c.Param("id")
+
+	owners, err := h.groupRepo.GetOwners(c.Request().Context(), groupID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group_id": groupID,
+		"owners":   owners,
+	})
+}
+
+// AddGroupOwner makes a person an owner of a group
+// @Summary Add owner to group
+// @Description Make a person an owner of a group, able to approve its members' capacity changes
+// @Tags Groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param owner body models.AddGroupOwnerRequest true "Owner to add"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group or person not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/owners [post]
+func (h *APIHandler) AddGroupOwner(c echo.Context) error {
+	groupID := c.Param("id")
+
+	var req models.AddGroupOwnerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	// Verify group exists
+	group, err := h.entityRepo.GetByID(c.Request().Context(), groupID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "group not found",
+		})
+	}
+	if group.Type != models.EntityTypeGroup {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "entity is not a group",
+		})
+	}
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	// Verify person exists
+	person, err := h.entityRepo.GetByID(c.Request().Context(), req.PersonEmail)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "person not found",
+		})
+	}
+	if person.Type != models.EntityTypePerson {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "entity is not a person",
+		})
+	}
+
+	if err := h.groupRepo.AddOwner(c.Request().Context(), groupID, req.PersonEmail); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	h.events.Record(c.Request().Context(), models.EventGroupOwnerAdded, "", []string{groupID}, req)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "owner added",
+	})
+}
+
+// RemoveGroupOwner removes an owner from a group
+// @Summary Remove owner from group
+// @Description Remove a person from a group's owners
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param owner path string true "Owner email to remove"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/owners/{owner} [delete]
+func (h *APIHandler) RemoveGroupOwner(c echo.Context) error {
+	groupID := c.Param("id")
+	ownerEmail := c.Param("owner")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	if err := h.groupRepo.RemoveOwner(c.Request().Context(), groupID, ownerEmail); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	h.events.Record(c.Request().Context(), models.EventGroupOwnerRemoved, "", []string{groupID},
+		models.AddGroupOwnerRequest{PersonEmail: ownerEmail})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "owner removed",
+	})
+}
+
+// EnableGroupDashboard shows a group's anonymized heatmap on public dashboards
+// @Summary Enable group dashboard
+// @Description Allow a group's heatmap to be shown at /dashboard/{id} with member identities hidden and only aggregate utilization displayed
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} map[string]string "Dashboard URL"
+// @Failure 400 {object} map[string]string "Entity is not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/dashboard [put]
+func (h *APIHandler) EnableGroupDashboard(c echo.Context) error {
+	groupID := c.Param("id")
+
+	group, err := h.entityRepo.GetByID(c.Request().Context(), groupID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "group not found",
+		})
+	}
+	if group.Type != models.EntityTypeGroup {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "entity is not a group",
+		})
+	}
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	if err := h.groupRepo.EnableDashboard(c.Request().Context(), groupID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	h.events.Record(c.Request().Context(), models.EventDashboardEnabled, "", []string{groupID}, map[string]string{"group_id": groupID})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "dashboard enabled",
+		"url":     middleware.AppPath(c, "/dashboard/"+url.PathEscape(groupID)),
+	})
+}
+
+// DisableGroupDashboard takes a group off public dashboards
+// @Summary Disable group dashboard
+// @Description Stop showing a group's anonymized heatmap on public dashboards
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/dashboard [delete]
+func (h *APIHandler) DisableGroupDashboard(c echo.Context) error {
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), c.Param("id")); err != nil {
+		return scopeError(c, err)
+	}
+	if err := h.groupRepo.DisableDashboard(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	h.events.Record(c.Request().Context(), models.EventDashboardDisabled, "", []string{c.Param("id")}, map[string]string{"group_id": c.Param("id")})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "dashboard disabled",
+	})
+}
+
+// AddAssigneesToLoad adds one or more assignees to an existing load
+// @Summary Add assignees to a load
+// @Description Add one or more assignees to an existing load with optional weight. Unknown assignees are created and queued for review as for /api/loads/upsert, counting against the quota of the load's source.
+// @Tags Loads
+// @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "Load ID"
 // @Param assignees body models.AddAssigneeRequest true "Assignees to add"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} map[string]string "Success message"
-// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 400 {object} map[string]string "Invalid request, or an unknown assignee while auto-creation is disabled"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 404 {object} map[string]string "Load not found"
+// @Failure 429 {object} map[string]string "Source reached its daily quota of auto-created persons"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/loads/{id}/assignees [post]
 func (h *APIHandler) AddAssigneesToLoad(c echo.Context) error {
 	loadID := 0
@@ -477,22 +1356,35 @@ func (h *APIHandler) AddAssigneesToLoad(c echo.Context) error {
 	}
 
 	if err := h.loadService.AddAssignees(c.Request().Context(), loadID, &req); err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		errMsg := err.Error()
 		if strings.HasPrefix(errMsg, "load not found") {
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "load not found",
 			})
 		}
-		if strings.HasPrefix(errMsg, "assignee not found") {
+		if errors.Is(err, service.ErrUnknownAssignee) {
 			return c.JSON(http.StatusBadRequest, map[string]string{
 				"error": errMsg,
 			})
 		}
+		if errors.Is(err, repository.ErrAutoCreateQuota) {
+			return c.JSON(http.StatusTooManyRequests, map[string]string{
+				"error": errMsg,
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
 	return c.JSON(http.StatusOK, map[string]string{
 		"success": "assignees added",
 	})
@@ -506,11 +1398,14 @@ func (h *APIHandler) AddAssigneesToLoad(c echo.Context) error {
 // @Security ApiKeyAuth
 // @Param id path int true "Load ID"
 // @Param email path string true "Assignee email to remove"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 400 {object} map[string]string "Invalid request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
 // @Failure 404 {object} map[string]string "Load or assignee not found"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
 // @Router /api/loads/{id}/assignees/{email} [delete]
 func (h *APIHandler) RemoveAssigneeFromLoad(c echo.Context) error {
 	loadID := 0
@@ -528,6 +1423,11 @@ func (h *APIHandler) RemoveAssigneeFromLoad(c echo.Context) error {
 	}
 
 	if err := h.loadService.RemoveAssignee(c.Request().Context(), loadID, email); err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		errMsg := err.Error()
 		if strings.HasPrefix(errMsg, "load not found") {
 			return c.JSON(http.StatusNotFound, map[string]string{
@@ -544,7 +1444,661 @@ func (h *APIHandler) RemoveAssigneeFromLoad(c echo.Context) error {
 		})
 	}
 
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
 	return c.JSON(http.StatusOK, map[string]string{
 		"success": "assignee removed",
 	})
 }
+
+// DeleteLoadByExternalID deletes a load its source system deleted
+// @Summary Delete a load by external ID
+// @Description Delete the load a source system knows by this external ID, e.g. a deleted calendar event (for n8n integration). The same can be sent to the upsert endpoints as a tombstone, {"external_id": ..., "deleted": true}. For an upcoming load, each assignee's load and capacity on its date are re-checked and sent as a load_deleted webhook.
+// @Tags Loads
+// @Produce json
+// @Security ApiKeyAuth
+// @Param external_id path string true "External ID of the load, URL-encoded"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
+// @Success 200 {object} models.DeletedLoad "Deleted load and its former assignees"
+// @Failure 400 {object} map[string]string "Invalid external ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Load not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/by-external-id/{external_id} [delete]
+func (h *APIHandler) DeleteLoadByExternalID(c echo.Context) error {
+	externalID, err := url.PathUnescape(c.Param("external_id"))
+	if err != nil || externalID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid external ID",
+		})
+	}
+	return h.deleteByExternalID(c, "", externalID)
+}
+
+// deleteByExternalID answers a deletion, from the delete endpoint or an
+// upsert tombstone
+func (h *APIHandler) deleteByExternalID(c echo.Context, source, externalID string) error {
+	// A tombstone from a source in shadow mode removes its shadow load
+	shadow, err := h.loadService.DeleteShadowLoad(c.Request().Context(), source, externalID)
+	if shadow {
+		switch {
+		case errors.Is(err, repository.ErrLoadNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case err != nil:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if dry := service.DryRun(c.Request().Context()); dry != nil {
+			return c.JSON(http.StatusOK, dry)
+		}
+		return c.JSON(http.StatusOK, models.DeletedLoad{
+			ExternalID: externalID,
+			Deleted:    true,
+			Assignees:  []string{},
+			Shadow:     true,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	deleted, err := h.loadService.DeleteLoadByExternalID(c.Request().Context(), externalID)
+	if err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, repository.ErrLoadNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
+	return c.JSON(http.StatusOK, deleted)
+}
+
+// GetStaleLoads lists imported loads their source stopped sending
+// @Summary List stale loads
+// @Description List upcoming loads the stale load check flagged because their source has not upserted them within its freshness window (STALE_LOAD_WINDOW, STALE_LOAD_SOURCE_WINDOWS), oldest first, with a count per source. Upserting a load again clears its flag. Review the list, then remove dead loads with POST /api/loads/stale/delete.
+// @Tags Loads
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source query string false "Only loads from this source"
+// @Success 200 {object} models.StaleLoadReport "Stale loads"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/stale [get]
+func (h *APIHandler) GetStaleLoads(c echo.Context) error {
+	report, err := h.loadService.GetStaleLoadReport(c.Request().Context(), c.QueryParam("source"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ListLoads pages through loads
+// @Summary List loads
+// @Description List loads on any day of a date range with their assignees, ordered by first day and id, optionally only those from one source, assigned to one person, or assigned to any member of one group. When more loads follow, next_cursor is set; pass it as cursor, with the same filters, for the next page.
+// @Tags Loads
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "First day (YYYY-MM-DD, default today)"
+// @Param to query string false "Last day (YYYY-MM-DD, default 30 days after from)"
+// @Param source query string false "Only loads from this source"
+// @Param assignee query string false "Only loads assigned to this email"
+// @Param group query string false "Only loads assigned to a member of this group"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum loads to return (default 100, at most 1000)"
+// @Success 200 {object} models.LoadList "Loads"
+// @Failure 400 {object} map[string]string "Invalid date, cursor or limit"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads [get]
+func (h *APIHandler) ListLoads(c echo.Context) error {
+	limit := 0
+	if s := c.QueryParam("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit must be a positive number",
+			})
+		}
+	}
+
+	filter := repository.LoadFilter{
+		Source:      c.QueryParam("source"),
+		PersonEmail: c.QueryParam("assignee"),
+		GroupID:     c.QueryParam("group"),
+	}
+	list, err := h.loadService.ListLoads(c.Request().Context(), c.QueryParam("from"), c.QueryParam("to"),
+		filter, c.QueryParam("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ListUtilization pages through per-day utilization rows for BI tools
+// @Summary List daily utilization
+// @Description One flat row per entity and day of a date range, with the day's load, capacity, and their ratio (null on days without capacity), for ingestion by BI tools such as Metabase or Power BI. Rows are ordered by entity, as listed, then by date. When more rows follow, next_cursor is set; pass it as cursor, with the same entities and range, for the next page. A group-scoped API key may only list its groups and their members.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param entities query string true "Comma-separated person emails and group IDs"
+// @Param from query string false "First day (YYYY-MM-DD, default today)"
+// @Param to query string false "Last day (YYYY-MM-DD, default 30 days after from)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum rows to return (default 1000, at most 10000)"
+// @Success 200 {object} models.UtilizationList "Utilization rows"
+// @Failure 400 {object} map[string]string "Missing entities, or invalid date, cursor or limit"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/utilization [get]
+func (h *APIHandler) ListUtilization(c echo.Context) error {
+	var entityIDs []string
+	for _, id := range strings.Split(c.QueryParam("entities"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			entityIDs = append(entityIDs, id)
+		}
+	}
+	if len(entityIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "entities is required",
+		})
+	}
+
+	limit := 0
+	if s := c.QueryParam("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit must be a positive number",
+			})
+		}
+	}
+
+	ctx := c.Request().Context()
+	for _, id := range entityIDs {
+		if err := h.loadService.CheckEntityScope(ctx, id); err != nil {
+			return scopeError(c, err)
+		}
+	}
+
+	list, err := h.heatmapService.ListUtilization(ctx, entityIDs, c.QueryParam("from"), c.QueryParam("to"),
+		c.QueryParam("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrInvalidCursor):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// DeleteStaleLoads removes reviewed stale loads
+// @Summary Delete stale loads
+// @Description Delete the listed loads that are still flagged stale, after reviewing GET /api/loads/stale. Loads upserted again since the review, already deleted, or now in the past are kept and returned as skipped, as are loads outside a group-scoped API key's groups. Each deleted upcoming load sends load_deleted webhooks like a deletion from its source.
+// @Tags Loads
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.DeleteStaleLoadsRequest true "Reviewed load IDs"
+// @Success 200 {object} models.DeleteStaleLoadsResponse "Deleted and skipped loads"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/stale/delete [post]
+func (h *APIHandler) DeleteStaleLoads(c echo.Context) error {
+	var req models.DeleteStaleLoadsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := h.loadService.DeleteStaleLoads(c.Request().Context(), req.LoadIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// AcknowledgeLoad records that the logged-in assignee has seen a load
+// @Summary Acknowledge load
+// @Description Confirm that the currently logged-in user has seen a load assigned to them. Acknowledging again keeps the original time. Unacknowledged loads may trigger a reminder webhook.
+// @Tags Loads
+// @Produce json
+// @Param id path int true "Load ID"
+// @Success 200 {object} models.AcknowledgeLoadResponse "Acknowledgment"
+// @Failure 400 {object} map[string]string "Invalid load ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Not assigned to this load"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/loads/{id}/acknowledge [post]
+func (h *APIHandler) AcknowledgeLoad(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	loadID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid load ID",
+		})
+	}
+
+	ack, err := h.loadService.AcknowledgeLoad(c.Request().Context(), loadID, userEmail)
+	if err != nil {
+		if errors.Is(err, repository.ErrAssignmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, ack)
+}
+
+// RecordLoadActual records the effort the logged-in assignee actually spent
+// @Summary Record actual effort
+// @Description Record the effort the currently logged-in user actually spent on a load assigned to them, in the same units as weights, once the load's date has come. The current weight is kept next to it as the planned effort; recording again replaces both. Feeds the calibration report.
+// @Tags Loads
+// @Accept json
+// @Produce json
+// @Param id path int true "Load ID"
+// @Param actual body models.RecordActualRequest true "Actual effort"
+// @Success 200 {object} models.LoadActual "Recorded effort"
+// @Failure 400 {object} map[string]string "Invalid request or load not yet happened"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Not assigned to this load"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/loads/{id}/actual [post]
+func (h *APIHandler) RecordLoadActual(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	loadID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid load ID",
+		})
+	}
+
+	var req models.RecordActualRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	actual, err := h.loadService.RecordActual(c.Request().Context(), loadID, userEmail, *req.Actual)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAssignmentNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrLoadNotPast):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, actual)
+}
+
+// ListMyUnacknowledgedLoads lists the logged-in user's unacknowledged loads
+// @Summary Unacknowledged loads
+// @Description List upcoming loads assigned to the currently logged-in user that they have not acknowledged yet, soonest first
+// @Tags Loads
+// @Produce json
+// @Success 200 {array} models.PendingAcknowledgment "Unacknowledged loads"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-loads/unacknowledged [get]
+func (h *APIHandler) ListMyUnacknowledgedLoads(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	pending, err := h.loadService.ListUnacknowledged(c.Request().Context(), userEmail)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, pending)
+}
+
+// SuggestAssignee ranks people with a skill by their slack on a date
+// @Summary Suggest assignees
+// @Description Rank active persons with a skill by remaining capacity on a date, leaving out anyone the weight would overload
+// @Tags Loads
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.SuggestAssigneeRequest true "Date, weight, and required skill"
+// @Success 200 {object} models.SuggestAssigneeResponse "Ranked suggestions"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/suggest-assignee [post]
+func (h *APIHandler) SuggestAssignee(c echo.Context) error {
+	var req models.SuggestAssigneeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := h.loadService.SuggestAssignees(c.Request().Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RebalanceGroup proposes moves that resolve a group's overloads on a date
+// @Summary Propose a group rebalance
+// @Description Dry-run plan of load moves between members that resolves overloads on a date without overloading anyone else
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param group path string true "Group ID"
+// @Param date query string false "Date (YYYY-MM-DD), default today"
+// @Success 200 {object} models.RebalancePlan "Proposed moves"
+// @Failure 400 {object} map[string]string "Invalid date or not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/rebalance/{group} [get]
+func (h *APIHandler) RebalanceGroup(c echo.Context) error {
+	groupID := c.Param("group")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	plan, err := h.loadService.RebalanceGroup(c.Request().Context(), groupID, c.QueryParam("date"))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "group not found",
+			})
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, repository.ErrNotAGroup):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, plan)
+}
+
+// GetLeastLoaded ranks a group's members by their room for a new load
+// @Summary Find a group's least-loaded members
+// @Description Rank a group's active members by the capacity they would have left on a date after taking a load of the given weight, most first, for "assign to whoever is free" automations. Members the weight would overload are listed last with overloaded set. Nothing is written.
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param date query string false "Date (YYYY-MM-DD), default today"
+// @Param weight query number false "Weight of the new load, default 1.0"
+// @Success 200 {object} models.LeastLoadedResponse "Members, most remaining capacity first"
+// @Failure 400 {object} map[string]string "Invalid date or weight, or not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/least-loaded [get]
+func (h *APIHandler) GetLeastLoaded(c echo.Context) error {
+	groupID := c.Param("id")
+	if err := h.loadService.CheckEntityScope(c.Request().Context(), groupID); err != nil {
+		return scopeError(c, err)
+	}
+
+	var weight float64
+	if s := c.QueryParam("weight"); s != "" {
+		w, err := strconv.ParseFloat(s, 64)
+		if err != nil || w < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "weight must be a number of at least 0",
+			})
+		}
+		weight = w
+	}
+
+	resp, err := h.loadService.LeastLoaded(c.Request().Context(), groupID, c.QueryParam("date"), weight)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "group not found",
+			})
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, repository.ErrNotAGroup):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListBlackouts returns an entity's current and upcoming blackout dates
+// @Summary List blackout dates
+// @Description List the blackouts of a person or group that have not yet ended, in date order
+// @Tags Entities
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Success 200 {array} models.BlackoutDate "Blackouts"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/blackouts [get]
+func (h *APIHandler) ListBlackouts(c echo.Context) error {
+	blackouts, err := h.loadService.ListBlackouts(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "entity not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, blackouts)
+}
+
+// AddBlackout declares blackout dates for an entity
+// @Summary Add blackout dates
+// @Description Declare a date range, such as a release freeze or exam week, in which a person or group takes no new loads. Upserts newly assigning someone in their own or a group's blackout are warned about or rejected, per BLACKOUT_MODE.
+// @Tags Entities
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Param blackout body models.CreateBlackoutRequest true "Blackout to add"
+// @Success 201 {object} models.BlackoutDate "Created blackout"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/blackouts [post]
+func (h *APIHandler) AddBlackout(c echo.Context) error {
+	var req models.CreateBlackoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	blackout, err := h.loadService.AddBlackout(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDate):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "entity not found",
+			})
+		case errors.Is(err, service.ErrOutOfScope):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, blackout)
+}
+
+// DeleteBlackout removes one of an entity's blackouts
+// @Summary Delete blackout dates
+// @Description Remove a blackout from a person or group
+// @Tags Entities
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Entity ID"
+// @Param blackout path int true "Blackout ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid blackout ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Blackout not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/blackouts/{blackout} [delete]
+func (h *APIHandler) DeleteBlackout(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("blackout"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid blackout id",
+		})
+	}
+
+	if err := h.loadService.DeleteBlackout(c.Request().Context(), c.Param("id"), id); err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, repository.ErrBlackoutNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "blackout deleted",
+	})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// APIMetricsHandler serves admins each API key's daily traffic, to find the
+// integration hammering the service or sending malformed data
+type APIMetricsHandler struct {
+	metricsService *service.APIMetricsService
+}
+
+func NewAPIMetricsHandler(metricsService *service.APIMetricsService) *APIMetricsHandler {
+	return &APIMetricsHandler{metricsService: metricsService}
+}
+
+// GetAPIMetrics reports requests, errors and payload sizes per API key per day
+// @Summary API key metrics
+// @Description Per API key and UTC day between from and to: requests, 4xx and 5xx responses, the share answered with an error, and request and response bytes. Keys are named, never shown: api_key for API_KEY, groups:<ids> for a group-scoped key, and unauthenticated for requests whose key was missing or wrong. Sorted by date, then most requests first. Counts reach the database within a minute. Only admins (ADMIN_EMAILS) may.
+// @Tags Reports
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD), default 7 days before to"
+// @Param to query string false "Last date (YYYY-MM-DD), default today"
+// @Param key query string false "Only this key's metrics, by name"
+// @Success 200 {object} models.APIKeyMetricsReport "API key metrics"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/api-metrics [get]
+func (h *APIMetricsHandler) GetAPIMetrics(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var report *models.APIKeyMetricsReport
+	report, err := h.metricsService.GetReport(c.Request().Context(), userEmail,
+		c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("key"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotMetricsAdmin):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidDate):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
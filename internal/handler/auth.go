@@ -1,7 +1,8 @@
 package handler
 
 import (
-	"html/template"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -22,14 +23,14 @@ const (
 type AuthHandler struct {
 	authService *service.AuthService
 	entityRepo  *repository.EntityRepository
-	templates   *template.Template
+	templates   Templates
 	validate    *validator.Validate
 }
 
 func NewAuthHandler(
 	authService *service.AuthService,
 	entityRepo *repository.EntityRepository,
-	templates *template.Template,
+	templates Templates,
 ) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
@@ -43,19 +44,33 @@ func NewAuthHandler(
 func (h *AuthHandler) LoginPage(c echo.Context) error {
 	// If already authenticated, redirect to home
 	if middleware.IsAuthenticated(c) {
-		return c.Redirect(http.StatusFound, "/")
+		return c.Redirect(http.StatusFound, middleware.AppPath(c, "/"))
 	}
 
 	data := map[string]interface{}{
-		"Step": "email",
+		"Step":     "email",
+		"OIDCName": h.authService.OIDCName(),
 	}
 
 	return h.templates.ExecuteTemplate(c.Response().Writer, "login", data)
 }
 
+// loginFailed renders the login form with why single sign-on failed
+func (h *AuthHandler) loginFailed(c echo.Context, status int, message string) error {
+	data := map[string]interface{}{
+		"Step":     "email",
+		"OIDCName": h.authService.OIDCName(),
+		"Error":    message,
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(status)
+	return h.templates.ExecuteTemplate(c.Response().Writer, "login", data)
+}
+
 // RequestOTP sends an OTP to the user's email
 // @Summary Request OTP
-// @Description Send an OTP code to the user's email for authentication
+// @Description Send an OTP code to the user's email for authentication. Each email may be sent OTP_REQUEST_LIMIT codes an hour, and each client IP may call this OTP_IP_LIMIT times an hour.
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -63,6 +78,7 @@ func (h *AuthHandler) LoginPage(c echo.Context) error {
 // @Success 200 {object} map[string]string "OTP sent successfully"
 // @Failure 400 {object} map[string]string "Invalid email"
 // @Failure 404 {object} map[string]string "Email not found"
+// @Failure 429 {object} map[string]string "Too many OTP requests for the email or from the client IP"
 // @Failure 500 {object} map[string]string "Failed to send OTP"
 // @Router /auth/request-otp [post]
 func (h *AuthHandler) RequestOTP(c echo.Context) error {
@@ -85,8 +101,11 @@ func (h *AuthHandler) RequestOTP(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	// Verify user exists (must be a registered person)
-	_, err := h.entityRepo.GetByID(c.Request().Context(), req.Email)
+	// Verify user exists (must be a registered, active person)
+	entity, err := h.entityRepo.GetByID(c.Request().Context(), req.Email)
+	if err == nil && entity.ArchivedAt != nil {
+		err = repository.ErrEntityArchived
+	}
 	if err != nil {
 		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
 			return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Email not found in system</div>`)
@@ -95,7 +114,14 @@ func (h *AuthHandler) RequestOTP(c echo.Context) error {
 	}
 
 	// Send OTP
-	if err := h.authService.SendOTP(c.Request().Context(), req.Email); err != nil {
+	err = h.authService.SendOTP(c.Request().Context(), req.Email)
+	if errors.Is(err, service.ErrOTPRateLimited) {
+		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
+			return c.HTML(http.StatusTooManyRequests, `<div class="text-red-500">Too many codes requested. Please try again later.</div>`)
+		}
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
 		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
 			return c.HTML(http.StatusInternalServerError, `<div class="text-red-500">Failed to send code. Please try again.</div>`)
 		}
@@ -115,13 +141,15 @@ func (h *AuthHandler) RequestOTP(c echo.Context) error {
 
 // VerifyOTP verifies the OTP and creates a session
 // @Summary Verify OTP
-// @Description Verify the OTP and create a session
+// @Description Verify the OTP and create a session. An OTP is dropped after 5 wrong codes, and each client IP may call this OTP_IP_LIMIT times an hour.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body models.VerifyOTPRequest true "Email and OTP"
 // @Success 200 {object} map[string]string "OTP verified, session created"
-// @Failure 400 {object} map[string]string "Invalid OTP"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Invalid or expired OTP"
+// @Failure 429 {object} map[string]string "Too many wrong codes for the OTP, or calls from the client IP"
 // @Failure 500 {object} map[string]string "Failed to create session"
 // @Router /auth/verify-otp [post]
 func (h *AuthHandler) VerifyOTP(c echo.Context) error {
@@ -146,6 +174,12 @@ func (h *AuthHandler) VerifyOTP(c echo.Context) error {
 
 	// Verify OTP
 	valid, err := h.authService.VerifyOTP(c.Request().Context(), req.Email, req.OTP)
+	if errors.Is(err, service.ErrOTPAttempts) {
+		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
+			return c.HTML(http.StatusTooManyRequests, `<div class="text-red-500">Too many wrong codes. Please request a new code.</div>`)
+		}
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
 	if err != nil || !valid {
 		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
 			return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Invalid or expired code</div>`)
@@ -167,13 +201,97 @@ func (h *AuthHandler) VerifyOTP(c echo.Context) error {
 
 	// For HTMX, redirect via header
 	if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
-		c.Response().Header().Set("HX-Redirect", "/")
+		c.Response().Header().Set("HX-Redirect", middleware.AppPath(c, "/"))
 		return c.String(http.StatusOK, "")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"success": "logged in"})
 }
 
+// OIDCLogin sends the user to sign in at the identity provider
+// @Summary Start single sign-on
+// @Description Redirect to the OpenID Connect identity provider (OIDC_ISSUER_URL), such as Google Workspace or Azure AD, to sign in by the authorization code flow with PKCE. The login must be finished at /auth/oidc/callback within 10 minutes from the same browser.
+// @Tags Authentication
+// @Produce html
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} map[string]string "Single sign-on is not configured"
+// @Failure 502 {string} string "Identity provider unreachable"
+// @Router /auth/oidc/login [get]
+func (h *AuthHandler) OIDCLogin(c echo.Context) error {
+	if h.authService.OIDCName() == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "single sign-on is not configured"})
+	}
+
+	authURL, state, err := h.authService.StartOIDCLogin(c.Request().Context())
+	if err != nil {
+		log.Printf("Single sign-on: %v", err)
+		return h.loginFailed(c, http.StatusBadGateway, "Could not reach "+h.authService.OIDCName()+". Please try again.")
+	}
+
+	middleware.SetLoginStateCookie(c, state)
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback finishes a single sign-on login and creates a session
+// @Summary Finish single sign-on
+// @Description Where the identity provider sends the user back. The ID token's signature, issuer, audience, expiry and nonce are checked, and its email must be verified and belong to a registered, active person, as for OTP. Redirects home with a session cookie; failures render the login page.
+// @Tags Authentication
+// @Produce html
+// @Param code query string false "Authorization code"
+// @Param state query string true "State of the login"
+// @Param error query string false "Why the identity provider did not sign the user in"
+// @Success 302 "Redirect to home page with a session"
+// @Failure 400 {string} string "Login expired or not started from this browser"
+// @Failure 401 {string} string "Sign-in refused or not confirmed"
+// @Failure 403 {string} string "Email not found in system"
+// @Failure 404 {object} map[string]string "Single sign-on is not configured"
+// @Failure 502 {string} string "Identity provider unreachable"
+// @Router /auth/oidc/callback [get]
+func (h *AuthHandler) OIDCCallback(c echo.Context) error {
+	if h.authService.OIDCName() == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "single sign-on is not configured"})
+	}
+
+	cookie, err := c.Cookie(middleware.LoginStateCookieName)
+	middleware.ClearLoginStateCookie(c)
+	state := c.QueryParam("state")
+	if err != nil || state == "" || cookie.Value != state {
+		return h.loginFailed(c, http.StatusBadRequest, "Sign-in expired or was not started here. Please try again.")
+	}
+	if reason := c.QueryParam("error"); reason != "" {
+		return h.loginFailed(c, http.StatusUnauthorized, "Sign-in was refused: "+reason)
+	}
+
+	email, err := h.authService.FinishOIDCLogin(c.Request().Context(), state, c.QueryParam("code"))
+	switch {
+	case errors.Is(err, service.ErrOIDCState):
+		return h.loginFailed(c, http.StatusBadRequest, "Sign-in expired or was not started here. Please try again.")
+	case errors.Is(err, service.ErrOIDCToken), errors.Is(err, service.ErrOIDCEmail):
+		log.Printf("Single sign-on: %v", err)
+		return h.loginFailed(c, http.StatusUnauthorized, "Could not confirm your sign-in. Please try again.")
+	case err != nil:
+		log.Printf("Single sign-on: %v", err)
+		return h.loginFailed(c, http.StatusBadGateway, "Could not reach "+h.authService.OIDCName()+". Please try again.")
+	}
+
+	// Only registered, active persons may log in, as with OTP
+	entity, err := h.entityRepo.GetByID(c.Request().Context(), email)
+	if err == nil && entity.ArchivedAt != nil {
+		err = repository.ErrEntityArchived
+	}
+	if err != nil {
+		return h.loginFailed(c, http.StatusForbidden, "Email not found in system")
+	}
+
+	token, err := h.authService.CreateSession(c.Request().Context(), email)
+	if err != nil {
+		return h.loginFailed(c, http.StatusInternalServerError, "Failed to create session")
+	}
+	middleware.SetSessionCookie(c, token)
+
+	return c.Redirect(http.StatusFound, middleware.AppPath(c, "/"))
+}
+
 // Logout clears the session
 // @Summary Logout
 // @Description End the current session
@@ -191,9 +309,9 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 
 	// For HTMX, redirect
 	if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
-		c.Response().Header().Set("HX-Redirect", "/")
+		c.Response().Header().Set("HX-Redirect", middleware.AppPath(c, "/"))
 		return c.String(http.StatusOK, "")
 	}
 
-	return c.Redirect(http.StatusFound, "/")
+	return c.Redirect(http.StatusFound, middleware.AppPath(c, "/"))
 }
@@ -1,28 +1,30 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
-	"html/template"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gti/heatmap-internal/internal/middleware"
 	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
 	"github.com/gti/heatmap-internal/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
 type CapacityHandler struct {
 	capacityService *service.CapacityService
-	templates       *template.Template
+	templates       Templates
 	validate        *validator.Validate
 }
 
 func NewCapacityHandler(
 	capacityService *service.CapacityService,
-	templates *template.Template,
+	templates Templates,
 ) *CapacityHandler {
 	return &CapacityHandler{
 		capacityService: capacityService,
@@ -32,39 +34,118 @@ func NewCapacityHandler(
 }
 
 // MyCapacityPage renders the capacity management form for the logged-in user
+// @Summary Get capacity settings
+// @Description Renders the capacity management page for the currently logged-in user, or for a person who delegated their capacity to them, or, when the Accept header asks for application/json, returns the same settings as JSON: default capacity, date overrides, weekly pattern, and capacity changes awaiting approval
+// @Tags Capacity
+// @Produce text/html
+// @Produce json
+// @Param person query string false "Email of a person who delegated their capacity to the user; default the user"
+// @Success 200 {object} models.CapacitySettings "Capacity page, or its settings as JSON"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {string} string "Not the person's assistant"
+// @Failure 500 {string} string "Failed to load capacity data"
+// @Router /my-capacity [get]
 func (h *CapacityHandler) MyCapacityPage(c echo.Context) error {
+	resp := negotiate(c, false)
 	userEmail := middleware.GetUserEmail(c)
 	log.Printf("MyCapacityPage: userEmail from context = '%s'", userEmail)
 	if userEmail == "" {
+		if resp.JSON() {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		}
 		log.Printf("MyCapacityPage: No userEmail, redirecting to /login")
-		return c.Redirect(http.StatusFound, "/login")
+		return c.Redirect(http.StatusFound, middleware.AppPath(c, "/login"))
 	}
 
-	entity, overrides, err := h.capacityService.GetCapacityInfo(c.Request().Context(), userEmail)
+	person, err := h.capacityService.ResolvePerson(c.Request().Context(), userEmail, c.QueryParam("person"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotDelegate) {
+			return c.String(http.StatusForbidden, "You do not manage this person's capacity")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load capacity data")
+	}
+
+	entity, overrides, err := h.capacityService.GetCapacityInfo(c.Request().Context(), person)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load capacity data")
+	}
+
+	pattern, err := h.capacityService.GetWeeklyPattern(c.Request().Context(), person)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load capacity data")
+	}
+	type weekdayCapacity struct {
+		Weekday  string
+		Capacity float64
+	}
+	weeklyPattern := make([]weekdayCapacity, 0, len(pattern))
+	for _, day := range pattern {
+		weeklyPattern = append(weeklyPattern, weekdayCapacity{Weekday: day.Weekday, Capacity: *day.Capacity})
+	}
+
+	pending, approvals, err := h.capacityService.ListPendingChanges(c.Request().Context(), person)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load capacity data")
+	}
+
+	// Assistants see the switcher, but only approve changes on their own page
+	delegations, err := h.capacityService.ListDelegations(c.Request().Context(), userEmail)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "Failed to load capacity data")
 	}
+	onBehalf := person != userEmail
+	if onBehalf {
+		approvals = nil
+	}
 
 	data := map[string]interface{}{
 		"Entity":          entity,
 		"Overrides":       overrides,
+		"WeeklyPattern":   weeklyPattern,
+		"PendingChanges":  pending,
+		"Approvals":       approvals,
+		"Delegators":      delegations.Delegators,
+		"OnBehalf":        onBehalf,
 		"IsAuthenticated": true,
 		"UserEmail":       userEmail,
 	}
+	settings := models.CapacitySettings{
+		Entity:         *entity,
+		Overrides:      overrides,
+		WeeklyPattern:  pattern,
+		PendingChanges: pending,
+		Approvals:      approvals,
+	}
+	if settings.Overrides == nil {
+		settings.Overrides = []models.CapacityOverride{}
+	}
+	if settings.WeeklyPattern == nil {
+		settings.WeeklyPattern = []models.WeekdayCapacity{}
+	}
+	if settings.PendingChanges == nil {
+		settings.PendingChanges = []models.CapacityChangeRequest{}
+	}
+	if settings.Approvals == nil {
+		settings.Approvals = []models.CapacityChangeRequest{}
+	}
 
-	return h.templates.ExecuteTemplate(c.Response().Writer, "capacity_form", data)
+	return resp.Render(http.StatusOK, h.templates, "capacity_form", data, settings)
 }
 
 // UpdateMyCapacity handles the capacity update request for the logged-in user
 // @Summary Update user capacity
-// @Description Update capacity settings for the currently logged-in user
+// @Description Update capacity settings for the currently logged-in user, or for a person who delegated their capacity to them. A weekly pattern sets the capacity of a weekday (monday .. sunday) in every week, used unless a date override applies; a null capacity clears the weekday. When capacity approval is on, lowering the default capacity, setting a weekday below it, or setting a long run of zero-capacity days is held for an owner of one of the user's groups to approve.
 // @Tags Capacity
 // @Accept json
 // @Produce json
 // @Param capacity body models.UpdateCapacityRequest true "Capacity update request"
+// @Param person query string false "Email of a person who delegated their capacity to the user; default the user"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} map[string]string "Success message"
+// @Success 202 {object} models.CapacityChangeRequest "Change held for approval"
 // @Failure 400 {object} map[string]string "Invalid request"
 // @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not the person's assistant"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/my-capacity [post]
 //
@@ -75,11 +156,18 @@ func (h *CapacityHandler) UpdateMyCapacity(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
 	}
 
+	person, err := h.capacityService.ResolvePerson(c.Request().Context(), userEmail, c.QueryParam("person"))
+	if err != nil {
+		return capacityPersonError(c, err)
+	}
+
+	resp := negotiate(c, true)
 	var req models.UpdateCapacityRequest
 
 	// Parse form data manually since Echo's Bind() doesn't handle nested arrays properly
+	// Query parameters such as person and dry_run are not form data
 	formParams, err := c.FormParams()
-	if err == nil && len(formParams) > 0 {
+	if err == nil && len(c.Request().PostForm) > 0 {
 		// Parse default_capacity
 		if defaultCapStr := c.FormValue("default_capacity"); defaultCapStr != "" {
 			var defaultCap float64
@@ -135,37 +223,41 @@ func (h *CapacityHandler) UpdateMyCapacity(c echo.Context) error {
 	} else {
 		// Fall back to JSON binding
 		if err := c.Bind(&req); err != nil {
-			if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
-				return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Invalid request</div>`)
-			}
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+			return resp.Message(http.StatusBadRequest, "text-red-500", "Invalid request", map[string]string{"error": "invalid request"})
 		}
 	}
 
-	if err := h.capacityService.UpdateCapacity(c.Request().Context(), userEmail, &req); err != nil {
-		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
-			return c.HTML(http.StatusInternalServerError, `<div class="text-red-500">Failed to update capacity</div>`)
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	pending, err := h.capacityService.UpdateCapacity(c.Request().Context(), userEmail, person, &req)
+	if errors.Is(err, service.ErrInvalidWeekday) {
+		return resp.Error(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return resp.Message(http.StatusInternalServerError, "text-red-500", "Failed to update capacity", map[string]string{"error": err.Error()})
+	}
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
 	}
 
-	if c.Request().Header.Get("HX-Request") == "true" {
-		return c.HTML(http.StatusOK, `<div class="text-green-500">Capacity updated successfully!</div>`)
+	if pending != nil {
+		return resp.Message(http.StatusAccepted, "text-yellow-600", "Change sent for approval: "+pending.Reason, pending)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"success": "capacity updated"})
+	return resp.Message(http.StatusOK, "text-green-500", "Capacity updated successfully!", map[string]string{"success": "capacity updated"})
 }
 
 // DeleteMyCapacityOverride handles deletion of a specific capacity override
 // @Summary Delete capacity override
-// @Description Delete a specific date override for the currently logged-in user
+// @Description Delete a specific date override for the currently logged-in user, or for a person who delegated their capacity to them
 // @Tags Capacity
 // @Accept json
 // @Produce json
 // @Param date path string true "Date in YYYY-MM-DD format"
+// @Param person query string false "Email of a person who delegated their capacity to the user; default the user"
+// @Param dry_run query bool false "Check the write and answer with what it would do, as a DryRunResult, without making it"
 // @Success 200 {object} map[string]string "Success message"
 // @Failure 400 {object} map[string]string "Invalid date format"
 // @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not the person's assistant"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/my-capacity/override/{date} [delete]
 func (h *CapacityHandler) DeleteMyCapacityOverride(c echo.Context) error {
@@ -174,18 +266,235 @@ func (h *CapacityHandler) DeleteMyCapacityOverride(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
 	}
 
+	person, err := h.capacityService.ResolvePerson(c.Request().Context(), userEmail, c.QueryParam("person"))
+	if err != nil {
+		return capacityPersonError(c, err)
+	}
+
 	dateStr := c.Param("date")
 	if dateStr == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "date parameter required"})
 	}
 
-	if err := h.capacityService.DeleteDateOverride(c.Request().Context(), userEmail, dateStr); err != nil {
+	if err := h.capacityService.DeleteDateOverride(c.Request().Context(), userEmail, person, dateStr); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if dry := service.DryRun(c.Request().Context()); dry != nil {
+		return c.JSON(http.StatusOK, dry)
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{"success": "override deleted"})
 }
 
+// DeleteMyCapacityOverrides removes every capacity override in a date range
+// @Summary Delete capacity overrides in a range
+// @Description Remove every date override from from to to, both included, for the currently logged-in user, or for a person who delegated their capacity to them, to undo mistaken vacation entries in one call. Answers with how many were removed; the removal is audited as one entry.
+// @Tags Capacity
+// @Produce json
+// @Param from query string true "First date (YYYY-MM-DD)"
+// @Param to query string true "Last date (YYYY-MM-DD)"
+// @Param person query string false "Email of a person who delegated their capacity to the user; default the user"
+// @Success 200 {object} models.DeleteOverridesResponse "Overrides removed"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not the person's assistant"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-capacity/overrides [delete]
+func (h *CapacityHandler) DeleteMyCapacityOverrides(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	person, err := h.capacityService.ResolvePerson(c.Request().Context(), userEmail, c.QueryParam("person"))
+	if err != nil {
+		return capacityPersonError(c, err)
+	}
+
+	result, err := h.capacityService.DeleteOverrides(c.Request().Context(), userEmail, person,
+		c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return deleteOverridesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// DeleteCapacityOverrides removes every capacity override of any entity in a
+// date range, as an admin
+// @Summary Delete an entity's capacity overrides in a range
+// @Description Remove every date override of a person or group from from to to, both included, such as vacation entered on the wrong dates. Answers with how many were removed; the removal is audited as one entry. Only admins (ADMIN_EMAILS) may.
+// @Tags Capacity
+// @Produce json
+// @Param id path string true "Entity ID"
+// @Param from query string true "First date (YYYY-MM-DD)"
+// @Param to query string true "Last date (YYYY-MM-DD)"
+// @Success 200 {object} models.DeleteOverridesResponse "Overrides removed"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/capacity/{id}/overrides [delete]
+func (h *CapacityHandler) DeleteCapacityOverrides(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	result, err := h.capacityService.DeleteEntityOverrides(c.Request().Context(), userEmail, c.Param("id"),
+		c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return deleteOverridesError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// deleteOverridesError answers a failed override range deletion
+func deleteOverridesError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidDate):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotCapacityAdmin):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, repository.ErrEntityNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "entity not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
+
+// GetMyCapacityAudit lists recent changes to the user's capacity
+// @Summary Capacity audit log
+// @Description List the most recent changes to the capacity of the currently logged-in user, or of a person who delegated their capacity to them, newest first. Each entry names both the person and who made the change.
+// @Tags Capacity
+// @Produce json
+// @Param person query string false "Email of a person who delegated their capacity to the user; default the user"
+// @Success 200 {array} models.CapacityAuditEntry "Audit entries"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not the person's assistant"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-capacity/audit [get]
+func (h *CapacityHandler) GetMyCapacityAudit(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	person, err := h.capacityService.ResolvePerson(c.Request().Context(), userEmail, c.QueryParam("person"))
+	if err != nil {
+		return capacityPersonError(c, err)
+	}
+
+	entries, err := h.capacityService.ListAuditLog(c.Request().Context(), person)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// ListMyDelegations lists the user's assistants and the people they assist
+// @Summary List delegations
+// @Description List who manages the currently logged-in user's capacity and whose capacity they manage
+// @Tags Capacity
+// @Produce json
+// @Success 200 {object} models.DelegationsResponse "Delegations"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-delegations [get]
+func (h *CapacityHandler) ListMyDelegations(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	delegations, err := h.capacityService.ListDelegations(c.Request().Context(), userEmail)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, delegations)
+}
+
+// AddMyDelegation lets an assistant manage the user's capacity
+// @Summary Add delegation
+// @Description Let another person manage the currently logged-in user's capacity. Their changes are audited under both identities.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param delegation body models.AddDelegationRequest true "Assistant to add"
+// @Success 200 {object} models.Delegation "Delegation"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Assistant not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-delegations [post]
+func (h *CapacityHandler) AddMyDelegation(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.AddDelegationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	delegation, err := h.capacityService.AddDelegate(c.Request().Context(), userEmail, req.AssistantEmail)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDelegate):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "assistant not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, delegation)
+}
+
+// RemoveMyDelegation stops an assistant managing the user's capacity
+// @Summary Remove delegation
+// @Description Stop another person managing the currently logged-in user's capacity
+// @Tags Capacity
+// @Produce json
+// @Param assistant path string true "Assistant email"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Delegation not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-delegations/{assistant} [delete]
+func (h *CapacityHandler) RemoveMyDelegation(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	if err := h.capacityService.RemoveDelegate(c.Request().Context(), userEmail, c.Param("assistant")); err != nil {
+		if errors.Is(err, repository.ErrDelegationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"success": "delegation removed"})
+}
+
+// capacityPersonError answers a request for a person whose capacity the user
+// cannot manage
+func capacityPersonError(c echo.Context, err error) error {
+	status := http.StatusInternalServerError
+	if errors.Is(err, service.ErrNotDelegate) {
+		status = http.StatusForbidden
+	}
+	return negotiate(c, true).Error(status, err.Error())
+}
+
 // GetCapacityForm returns the capacity form partial (HTMX)
 func (h *CapacityHandler) GetCapacityForm(c echo.Context) error {
 	userEmail := middleware.GetUserEmail(c)
@@ -205,3 +514,90 @@ func (h *CapacityHandler) GetCapacityForm(c echo.Context) error {
 
 	return h.templates.ExecuteTemplate(c.Response().Writer, "capacity_form_partial.html", data)
 }
+
+// ListCapacityApprovals lists the capacity changes waiting on the logged-in user
+// @Summary List capacity approvals
+// @Description List pending capacity changes from members of groups the currently logged-in user owns
+// @Tags Capacity
+// @Produce json
+// @Success 200 {array} models.CapacityChangeRequest "Pending capacity changes"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/capacity-approvals [get]
+func (h *CapacityHandler) ListCapacityApprovals(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	approvals, err := h.capacityService.ListApprovals(c.Request().Context(), userEmail)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, approvals)
+}
+
+// ApproveCapacityChange approves and applies a pending capacity change
+// @Summary Approve capacity change
+// @Description Approve a pending capacity change from a member of a group the currently logged-in user owns, applying it
+// @Tags Capacity
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Success 200 {object} models.CapacityChangeRequest "Approved change"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an approver"
+// @Failure 404 {object} map[string]string "Change request not found"
+// @Failure 409 {object} map[string]string "Already decided"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/capacity-approvals/{id}/approve [post]
+func (h *CapacityHandler) ApproveCapacityChange(c echo.Context) error {
+	return h.decideCapacityChange(c, true)
+}
+
+// RejectCapacityChange rejects a pending capacity change
+// @Summary Reject capacity change
+// @Description Reject a pending capacity change from a member of a group the currently logged-in user owns, discarding it
+// @Tags Capacity
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Success 200 {object} models.CapacityChangeRequest "Rejected change"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an approver"
+// @Failure 404 {object} map[string]string "Change request not found"
+// @Failure 409 {object} map[string]string "Already decided"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/capacity-approvals/{id}/reject [post]
+func (h *CapacityHandler) RejectCapacityChange(c echo.Context) error {
+	return h.decideCapacityChange(c, false)
+}
+
+func (h *CapacityHandler) decideCapacityChange(c echo.Context, approve bool) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid change request id"})
+	}
+
+	decided, err := h.capacityService.DecideChange(c.Request().Context(), userEmail, id, approve)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrNotApprover):
+			status = http.StatusForbidden
+		case errors.Is(err, repository.ErrChangeRequestNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, repository.ErrChangeRequestDecided):
+			status = http.StatusConflict
+		}
+		return negotiate(c, true).Error(status, err.Error())
+	}
+
+	return negotiate(c, true).Message(http.StatusOK, "text-green-500", "Change "+string(decided.Status), decided)
+}
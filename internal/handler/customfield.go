@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// CustomFieldHandler manages the registry of custom fields sources send with
+// their loads
+type CustomFieldHandler struct {
+	customFieldService *service.CustomFieldService
+	validate           *validator.Validate
+}
+
+func NewCustomFieldHandler(customFieldService *service.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+		validate:           validator.New(),
+	}
+}
+
+// ListCustomFields returns the defined custom fields
+// @Summary List custom fields
+// @Description List the custom fields defined for a source's loads, or for every source, ordered by source, then position.
+// @Tags Custom Fields
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source query string false "Only this source's fields"
+// @Success 200 {array} models.CustomField "Custom fields"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/custom-fields [get]
+func (h *CustomFieldHandler) ListCustomFields(c echo.Context) error {
+	fields, err := h.customFieldService.List(c.Request().Context(), c.QueryParam("source"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, fields)
+}
+
+// PutCustomField defines a custom field for a source
+// @Summary Define a custom field
+// @Description Define, or redefine, a custom field a source sends under custom_fields in its upserts: its label, its type (string, number, boolean or url), whether day details display it, and in what position. Upserts from the source must then send the field with that type; fields that are not defined are kept but neither checked nor displayed.
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source path string true "Source, as sent in upserts"
+// @Param key path string true "Key under custom_fields"
+// @Param field body models.PutCustomFieldRequest true "Field definition"
+// @Success 200 {object} models.CustomField "Custom field"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/custom-fields/{source}/{key} [put]
+func (h *CustomFieldHandler) PutCustomField(c echo.Context) error {
+	var req models.PutCustomFieldRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	field, err := h.customFieldService.Put(c.Request().Context(), c.Param("source"), c.Param("key"), &req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, field)
+}
+
+// DeleteCustomField removes a custom field's definition
+// @Summary Delete a custom field
+// @Description Remove a custom field's definition. Loads keep the values they were sent, but they are no longer checked or displayed.
+// @Tags Custom Fields
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source path string true "Source"
+// @Param key path string true "Key under custom_fields"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Custom field not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/custom-fields/{source}/{key} [delete]
+func (h *CustomFieldHandler) DeleteCustomField(c echo.Context) error {
+	err := h.customFieldService.Delete(c.Request().Context(), c.Param("source"), c.Param("key"))
+	if errors.Is(err, repository.ErrCustomFieldNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": "custom field deleted"})
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// DigestHandler sets up the weekly heatmap images posted to groups' Lark
+// chats
+type DigestHandler struct {
+	digestService *service.DigestService
+	validate      *validator.Validate
+}
+
+func NewDigestHandler(digestService *service.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		validate:      validator.New(),
+	}
+}
+
+// GetLarkDigest returns a group's weekly Lark digest
+// @Summary Get group Lark digest
+// @Description Get the Lark chat a group's upcoming two weeks are posted to every Monday as a heatmap image, whether that is enabled, and the Monday of the latest post
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} models.LarkDigest "Lark digest"
+// @Failure 400 {object} map[string]string "Entity is not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found, or it has no Lark digest"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/lark-digest [get]
+func (h *DigestHandler) GetLarkDigest(c echo.Context) error {
+	digest, err := h.digestService.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(digestErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, digest)
+}
+
+// PutLarkDigest posts a group's upcoming two weeks to a Lark chat weekly
+// @Summary Set group Lark digest
+// @Description Post a group's upcoming two weeks every Monday (LARK_DIGEST_AT, UTC) to a Lark chat as a heatmap image: a row for the group, then one for each member whose heatmap is not private, captioned with who is over capacity and linked back to the heatmaps. The Lark app must be in the chat. Set enabled to false to pause it.
+// @Tags Groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Param digest body models.PutLarkDigestRequest true "Lark chat ID, and whether the digest is enabled"
+// @Success 200 {object} models.LarkDigest "Lark digest"
+// @Failure 400 {object} map[string]string "Invalid request body, or entity is not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/lark-digest [put]
+func (h *DigestHandler) PutLarkDigest(c echo.Context) error {
+	var req models.PutLarkDigestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	digest, err := h.digestService.Put(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		return c.JSON(digestErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, digest)
+}
+
+// DeleteLarkDigest stops posting a group's weekly Lark digest
+// @Summary Delete group Lark digest
+// @Description Stop posting a group's upcoming two weeks to its Lark chat
+// @Tags Groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Entity is not a group"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Outside the API key's groups"
+// @Failure 404 {object} map[string]string "Group not found, or it has no Lark digest"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/{id}/lark-digest [delete]
+func (h *DigestHandler) DeleteLarkDigest(c echo.Context) error {
+	if err := h.digestService.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(digestErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": "lark digest deleted"})
+}
+
+// digestErrorStatus maps Lark digest errors to HTTP statuses
+func digestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotAGroup):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrOutOfScope):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrEntityNotFound), errors.Is(err, repository.ErrLarkDigestNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type EventHandler struct {
+	events *service.EventLog
+}
+
+func NewEventHandler(events *service.EventLog) *EventHandler {
+	return &EventHandler{events: events}
+}
+
+// ListEvents pages through the domain event log
+// @Summary List domain events
+// @Description Every change to loads, capacity, entities and groups, oldest first, from the append-only domain event log. Pass the last event's id as after to read the next page. Each event lists the persons and groups whose data it changed under entity_ids; filter on one with entity.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param after query int false "Only events after this ID"
+// @Param entity query string false "Only events that changed this person or group"
+// @Param limit query int false "Maximum events to return (default 100, at most 1000)"
+// @Success 200 {array} models.DomainEvent "Events"
+// @Failure 400 {object} map[string]string "Invalid after or limit"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/events [get]
+func (h *EventHandler) ListEvents(c echo.Context) error {
+	var after int64
+	if s := c.QueryParam("after"); s != "" {
+		var err error
+		after, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || after < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "after must be an event ID"})
+		}
+	}
+	limit := 0
+	if s := c.QueryParam("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive number"})
+		}
+	}
+
+	var events []models.DomainEvent
+	events, err := h.events.List(c.Request().Context(), after, c.QueryParam("entity"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/database"
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	db *database.DB
+}
+
+func NewHealthHandler(db *database.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// Health reports whether the service can reach its database
+// @Summary Health check
+// @Description Returns 200 when the service and its database are reachable
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]string "status: ok"
+// @Failure 503 {object} map[string]string "Database unreachable"
+// @Router /health [get]
+func (h *HealthHandler) Health(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 2*time.Second)
+	defer cancel()
+
+	if err := h.db.Health(ctx); err != nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"status": "unavailable",
+			"error":  "database unreachable",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
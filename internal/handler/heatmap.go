@@ -1,10 +1,14 @@
 package handler
 
 import (
-	"html/template"
+	"bytes"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/gti/heatmap-internal/internal/cache"
 	"github.com/gti/heatmap-internal/internal/middleware"
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
@@ -13,29 +17,68 @@ import (
 )
 
 type HeatmapHandler struct {
-	heatmapService *service.HeatmapService
-	entityRepo     *repository.EntityRepository
-	templates      *template.Template
+	heatmapService     *service.HeatmapService
+	noteService        *service.NoteService
+	pinService         *service.PinService
+	customFieldService *service.CustomFieldService
+	entityRepo         *repository.EntityRepository
+	scenarioRepo       *repository.ScenarioRepository
+	templates          Templates
+	renderCache        *cache.RenderCache
 }
 
 func NewHeatmapHandler(
 	heatmapService *service.HeatmapService,
+	noteService *service.NoteService,
+	pinService *service.PinService,
+	customFieldService *service.CustomFieldService,
 	entityRepo *repository.EntityRepository,
-	templates *template.Template,
+	scenarioRepo *repository.ScenarioRepository,
+	templates Templates,
+	renderCache *cache.RenderCache,
 ) *HeatmapHandler {
 	return &HeatmapHandler{
-		heatmapService: heatmapService,
-		entityRepo:     entityRepo,
-		templates:      templates,
+		heatmapService:     heatmapService,
+		noteService:        noteService,
+		pinService:         pinService,
+		customFieldService: customFieldService,
+		entityRepo:         entityRepo,
+		scenarioRepo:       scenarioRepo,
+		templates:          templates,
+		renderCache:        renderCache,
 	}
 }
 
+// dayQuery returns the query string that opens a day's details filtered to
+// the heatmap's tag and leaving out its excluded members, or "" for neither
+func dayQuery(tag string, excluded []string) string {
+	query := url.Values{}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if len(excluded) > 0 {
+		query.Set("exclude", strings.Join(excluded, ","))
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
 // Index renders the main heatmap page
 func (h *HeatmapHandler) Index(c echo.Context) error {
 	entityID := c.QueryParam("entity")
+	tag := service.NormalizeTag(c.QueryParam("tag"))
+	excluded := service.ParseExcludedMembers(c.QueryParam("exclude"))
+	from, to, days := c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("days")
+	start, end, rangeErr := service.ParseHeatmapRange(from, to, days, time.Now())
 
-	// Get list of all entities for the selector
+	// Get list of all entities for the selector, without private heatmaps
+	// the viewer may not see
 	entities, err := h.entityRepo.ListAll(c.Request().Context())
+	if err == nil {
+		entities, err = h.heatmapService.VisibleEntities(c.Request().Context(), middleware.GetUserEmail(c), entities)
+	}
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "Failed to load entities")
 	}
@@ -45,57 +88,247 @@ func (h *HeatmapHandler) Index(c echo.Context) error {
 		"SelectedEntity":  entityID,
 		"IsAuthenticated": middleware.IsAuthenticated(c),
 		"UserEmail":       middleware.GetUserEmail(c),
+		"Tag":             tag,
+		"Excluded":        excluded,
+		"DayQuery":        dayQuery(tag, excluded),
+	}
+	if rangeErr == nil && (from != "" || to != "" || days != "") {
+		data["RangeStart"] = start.Format("2006-01-02")
+		data["RangeEnd"] = end.Format("2006-01-02")
 	}
 
 	// If entity is selected, load heatmap data
-	if entityID != "" {
-		heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), entityID, 90)
-		if err != nil {
+	if entityID != "" && rangeErr != nil {
+		data["Error"] = rangeErr.Error()
+	} else if entityID != "" {
+		var heatmapData *models.HeatmapData
+		var weekStart time.Weekday
+		err := h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), entityID)
+		if err == nil {
+			heatmapData, err = h.heatmapService.GetHeatmapData(c.Request().Context(), middleware.GetUserEmail(c), entityID, tag, excluded, start, end)
+		}
+		if err == nil {
+			weekStart, err = h.heatmapService.WeekStart(c.Request().Context(), middleware.GetUserEmail(c))
+		}
+		if errors.Is(err, service.ErrHeatmapPrivate) {
+			data["Error"] = "Entity not found"
+		} else if err != nil {
 			data["Error"] = "Failed to load heatmap data"
 		} else {
 			data["HeatmapData"] = heatmapData
-			data["Months"] = groupDaysByMonth(heatmapData.Days)
+			months := groupDaysByMonth(heatmapData.Days, weekStart)
+			// Pins only decorate tooltips; the page still works without them
+			if pins, err := h.pinService.ForEntity(c.Request().Context(), middleware.GetUserEmail(c), entityID, start, end); err == nil {
+				attachPins(months, pins)
+			}
+			data["Months"] = months
+
+			// Notes and links are a side panel; the page still works without them
+			if info, err := h.noteService.EntityInfo(c.Request().Context(), entityID); err == nil {
+				data["EntityInfo"] = info
+			}
+			data["CanEditInfo"] = h.noteService.IsAdmin(middleware.GetUserEmail(c))
+		}
+
+		// Scenarios to compare against; the page still works without them
+		scenarios, err := h.scenarioRepo.List(c.Request().Context())
+		if err == nil {
+			data["Scenarios"] = scenarios
 		}
 	}
 
 	return h.templates.ExecuteTemplate(c.Response().Writer, "heatmap", data)
 }
 
-// GetHeatmapPartial returns the heatmap grid as an HTMX partial
+// GetHeatmapPartial returns the heatmap grid as an HTMX partial, or the
+// heatmap days as JSON
 // @Summary Get heatmap partial for entity
-// @Description Returns the heatmap grid partial for an entity
+// @Description Returns the heatmap grid partial for an entity, or, when the Accept header asks for application/json, its days as JSON. Cell tooltips name the loads a logged-in viewer pinned, and loads from the sources they hide are left out. Given a tag, only loads tagged with it count. For groups, loads of the members listed in exclude do not count, on top of the members the group always excludes. The heatmap covers 1 month back to 6 months ahead, or from from to to, either defaulting to that window's end, or days days from from or today; a range spans at most 366 days.
 // @Tags Heatmap
 // @Produce text/html
+// @Produce json
 // @Param entity path string true "Entity ID"
-// @Success 200 {string} string "HTML partial for heatmap grid"
+// @Param tag query string false "Only count loads with this tag"
+// @Param exclude query string false "Comma-separated emails of group members whose loads do not count"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param days query int false "Number of days from from, or from today; not with to"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Param If-Modified-Since header string false "Last-Modified of a previous response"
+// @Success 200 {object} models.HeatmapData "HTML partial for heatmap grid, or the heatmap days as JSON"
+// @Success 304 "Heatmap unchanged since the given ETag or date"
+// @Header 200 {string} ETag "Heatmap version"
+// @Header 200 {string} Last-Modified "Time of the last change to the heatmap"
+// @Failure 400 {string} string "Invalid or too long date range"
+// @Failure 404 {string} string "Entity not found, or private and not visible to the viewer"
 // @Failure 500 {string} string "Failed to load heatmap"
 // @Router /api/heatmap/{entity} [get]
 func (h *HeatmapHandler) GetHeatmapPartial(c echo.Context) error {
 	entityID := c.Param("entity")
+	tag := service.NormalizeTag(c.QueryParam("tag"))
+	excluded := service.ParseExcludedMembers(c.QueryParam("exclude"))
+	now := time.Now()
+	start, end, err := service.ParseHeatmapRange(c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("days"), now)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	// Before anything cached, so a private heatmap never answers 304 either
+	if err := h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), entityID); err != nil {
+		if errors.Is(err, service.ErrHeatmapPrivate) {
+			return c.String(http.StatusNotFound, "Entity not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+
+	// Dashboards poll this endpoint, so check the cheap version first and
+	// skip building the heatmap when the client already has it
+	etag, lastModified, err := h.heatmapService.GetHeatmapVersion(c.Request().Context(), entityID, start, end, now)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	if negotiate(c, false).JSON() {
+		return h.heatmapJSON(c, entityID, tag, excluded, start, end, etag, lastModified)
+	}
+
+	pins, err := h.pinService.ForEntity(c.Request().Context(), middleware.GetUserEmail(c), entityID, start, end)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	etag, lastModified = service.PinnedVersion(etag, lastModified, pins)
+	weekStart, err := h.heatmapService.WeekStart(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	etag = weekStartETag(etag, weekStart)
+	hiddenSources, err := h.heatmapService.HiddenSources(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	etag = service.TagVersion(service.HiddenSourcesVersion(etag, hiddenSources), tag)
+	etag = service.ExcludedMembersVersion(etag, excluded)
+	if middleware.NotModified(c, etag, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	// Most traffic is many viewers of the same team heatmap, so serve the
+	// rendered grid from cache until a load or capacity write invalidates it.
+	// Grids showing the viewer's pins, hiding their sources, filtered to a
+	// tag or leaving members out are rendered for them alone.
+	key := h.renderCache.Key(entityID, start, end)
+	key.WeekStart = weekStart
+	shared := len(pins) == 0 && len(hiddenSources) == 0 && tag == "" && len(excluded) == 0
+	if shared {
+		if body, ok := h.renderCache.Get(key); ok {
+			return c.HTMLBlob(http.StatusOK, body)
+		}
+	}
 
-	heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), entityID, 90)
+	heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), middleware.GetUserEmail(c), entityID, tag, excluded, start, end)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
 	}
 
+	months := groupDaysByMonth(heatmapData.Days, weekStart)
+	attachPins(months, pins)
 	data := map[string]interface{}{
 		"HeatmapData": heatmapData,
-		"Months":      groupDaysByMonth(heatmapData.Days),
+		"Months":      months,
 		"EntityID":    entityID,
 	}
 
-	return h.templates.ExecuteTemplate(c.Response().Writer, "heatmap_grid.html", data)
+	var buf bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&buf, "heatmap_grid", data); err != nil {
+		return err
+	}
+	if shared {
+		h.renderCache.Set(key, buf.Bytes())
+	}
+
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// GetHeatmapJSON returns an entity's heatmap as JSON
+// @Summary Get heatmap data for entity
+// @Description Returns an entity's heatmap as JSON, for dashboards and single-page apps: the entity and, for each day of the window, its total load, capacity and color, leaving out loads from the sources a logged-in viewer hides, given a tag, loads without it, and for groups, loads of the members listed in exclude and of those the group always excludes. from, to and days choose the days as for GET /api/heatmap/{entity}. The same as GET /api/heatmap/{entity} with Accept: application/json, without having to set the header.
+// @Tags Heatmap
+// @Produce json
+// @Param entity path string true "Entity ID"
+// @Param tag query string false "Only count loads with this tag"
+// @Param exclude query string false "Comma-separated emails of group members whose loads do not count"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param days query int false "Number of days from from, or from today; not with to"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Param If-Modified-Since header string false "Last-Modified of a previous response"
+// @Success 200 {object} models.HeatmapData "Heatmap data"
+// @Success 304 "Heatmap unchanged since the given ETag or date"
+// @Header 200 {string} ETag "Heatmap version"
+// @Header 200 {string} Last-Modified "Time of the last change to the heatmap"
+// @Failure 400 {object} map[string]string "Invalid or too long date range"
+// @Failure 404 {object} map[string]string "Entity not found, or private and not visible to the viewer"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/heatmap/{entity}/json [get]
+func (h *HeatmapHandler) GetHeatmapJSON(c echo.Context) error {
+	entityID := c.Param("entity")
+	now := time.Now()
+	start, end, err := service.ParseHeatmapRange(c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("days"), now)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), entityID); err != nil {
+		if errors.Is(err, service.ErrHeatmapPrivate) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "entity not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	etag, lastModified, err := h.heatmapService.GetHeatmapVersion(c.Request().Context(), entityID, start, end, now)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return h.heatmapJSON(c, entityID, service.NormalizeTag(c.QueryParam("tag")),
+		service.ParseExcludedMembers(c.QueryParam("exclude")), start, end, etag, lastModified)
+}
+
+// heatmapJSON writes an entity's heatmap data from start to end as JSON, or
+// 304 when the client has this version
+func (h *HeatmapHandler) heatmapJSON(c echo.Context, entityID, tag string, excluded []string, start, end time.Time, etag string, lastModified time.Time) error {
+	hiddenSources, err := h.heatmapService.HiddenSources(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	etag = service.TagVersion(service.HiddenSourcesVersion(etag, hiddenSources), tag)
+	etag = service.ExcludedMembersVersion(etag, excluded)
+
+	// Pins only show in the grid, and the two forms need their own tag
+	if middleware.NotModified(c, strings.TrimSuffix(etag, `"`)+`-json"`, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), middleware.GetUserEmail(c), entityID, tag, excluded, start, end)
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, heatmapData)
 }
 
 // GetDayDetails returns the tasks/loads for a specific day (HTMX partial)
 // @Summary Get day details for entity
-// @Description Returns the tasks/loads for a specific day
+// @Description Returns the tasks/loads, with the logged-in viewer's pinned loads first, and notes for a specific day, as an HTML partial or, when the Accept header asks for application/json, as JSON. For a group, the loads are also grouped per member under members, each with the member's subtotal against their own capacity, fullest first. Assignments of private persons the viewer may not see are left out, though the day's total load still counts them. Each load lists, under fields, its custom fields that are defined for its source to be displayed. A load whose time window, from its start_time and end_time custom fields, overlaps another's for an assignee they share lists the other loads' IDs under conflicts and is badged; in a group's member sections only the member's own loads count. When any load is tagged, tags breaks the loads down per tag, each with its color for the legend. Given a tag, only loads tagged with it are listed and totaled. For groups, as on their heatmap, assignments and member sections of the members listed in exclude and of those the group always excludes are left out and not totaled.
 // @Tags Heatmap
 // @Produce text/html
+// @Produce json
 // @Param entity path string true "Entity ID"
 // @Param date path string true "Date in YYYY-MM-DD format"
-// @Success 200 {string} string "HTML partial for day details"
+// @Param tag query string false "Only list loads with this tag"
+// @Param exclude query string false "Comma-separated emails of group members whose loads do not count"
+// @Success 200 {object} models.DayDetailsResponse "HTML partial for day details, or its JSON form"
 // @Failure 400 {string} string "Invalid date format"
+// @Failure 404 {string} string "Entity not found, or private and not visible to the viewer"
 // @Failure 500 {string} string "Failed to load day details"
 // @Router /api/heatmap/{entity}/day/{date} [get]
 func (h *HeatmapHandler) GetDayDetails(c echo.Context) error {
@@ -106,22 +339,393 @@ func (h *HeatmapHandler) GetDayDetails(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "Invalid date format")
 	}
+	if err := h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), entityID); err != nil {
+		if errors.Is(err, service.ErrHeatmapPrivate) {
+			return c.String(http.StatusNotFound, "Entity not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
 
-	loads, totalLoad, capacity, err := h.heatmapService.GetDayDetails(c.Request().Context(), entityID, date)
+	excluded := service.ParseExcludedMembers(c.QueryParam("exclude"))
+	loads, totalLoad, capacity, err := h.heatmapService.GetDayDetails(c.Request().Context(), entityID, date, service.NormalizeTag(c.QueryParam("tag")), excluded)
+	if err == nil {
+		loads, err = h.heatmapService.HidePrivateAssignees(c.Request().Context(), middleware.GetUserEmail(c), loads)
+	}
+	if err == nil {
+		loads, err = h.heatmapService.HideConfidentialLoads(c.Request().Context(), middleware.GetUserEmail(c), loads)
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+	notes, err := h.noteService.ForDay(c.Request().Context(), entityID, date, loads)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+	pins, err := h.pinService.ForEntity(c.Request().Context(), middleware.GetUserEmail(c), entityID, date, date)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+	loads = service.PinFirst(loads, pins)
+	if err := h.customFieldService.Display(c.Request().Context(), loads); err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+	members, err := h.heatmapService.GroupDayByMember(c.Request().Context(), middleware.GetUserEmail(c), entityID, date, loads, excluded)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "Failed to load day details")
 	}
+	service.MarkConflicts(loads)
+	for i := range members {
+		service.MarkConflicts(members[i].Loads)
+	}
+	tags := service.TagBreakdown(loads)
 
 	data := map[string]interface{}{
 		"Date":      date,
 		"DateStr":   dateStr,
 		"Loads":     loads,
+		"Members":   members,
+		"Tags":      tags,
+		"Notes":     notes,
 		"TotalLoad": totalLoad,
 		"Capacity":  capacity,
 		"EntityID":  entityID,
+		"Viewer":    middleware.GetUserEmail(c),
+	}
+	if loads == nil {
+		loads = []models.LoadWithAssignments{}
 	}
 
-	return h.templates.ExecuteTemplate(c.Response().Writer, "day_tasks", data)
+	return negotiate(c, false).Render(http.StatusOK, h.templates, "day_tasks", data, models.DayDetailsResponse{
+		EntityID:  entityID,
+		Date:      dateStr,
+		TotalLoad: totalLoad,
+		Capacity:  capacity,
+		Loads:     loads,
+		Notes:     notes,
+		Members:   members,
+		Tags:      tags,
+	})
+}
+
+// GetGroupDayBreakdown returns each group member's load against their own
+// capacity on a day (HTMX partial)
+// @Summary Get a group's per-member day breakdown
+// @Description Returns each active member of a group with their own total load and capacity on a day, the color their day has on their heatmap, and whether they are overloaded, fullest first, as an HTML partial expanded from the group's day details or, when the Accept header asks for application/json, as JSON. Members whose heatmap is private to the viewer are left out.
+// @Tags Heatmap
+// @Produce text/html
+// @Produce json
+// @Param entity path string true "Group ID"
+// @Param date path string true "Date in YYYY-MM-DD format"
+// @Success 200 {object} models.GroupDayBreakdownResponse "HTML partial of the members, or its JSON form"
+// @Failure 400 {string} string "Invalid date format, or not a group"
+// @Failure 404 {string} string "Entity not found, or private and not visible to the viewer"
+// @Failure 500 {string} string "Failed to load the breakdown"
+// @Router /api/heatmap/{entity}/day/{date}/members [get]
+func (h *HeatmapHandler) GetGroupDayBreakdown(c echo.Context) error {
+	entityID := c.Param("entity")
+	dateStr := c.Param("date")
+
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid date format")
+	}
+	if err := h.heatmapService.CheckVisible(c.Request().Context(), middleware.GetUserEmail(c), entityID); err != nil {
+		if errors.Is(err, service.ErrHeatmapPrivate) {
+			return c.String(http.StatusNotFound, "Entity not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load the breakdown")
+	}
+
+	members, err := h.heatmapService.GetGroupDayBreakdown(c.Request().Context(), middleware.GetUserEmail(c), entityID, date)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.String(http.StatusNotFound, "Entity not found")
+		case errors.Is(err, repository.ErrNotAGroup):
+			return c.String(http.StatusBadRequest, "Not a group")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load the breakdown")
+	}
+
+	data := map[string]interface{}{
+		"EntityID": entityID,
+		"DateStr":  dateStr,
+		"Members":  members,
+	}
+	return negotiate(c, false).Render(http.StatusOK, h.templates, "day_members", data, models.GroupDayBreakdownResponse{
+		GroupID: entityID,
+		Date:    dateStr,
+		Members: members,
+	})
+}
+
+// PinLoad pins a load for the logged-in user
+// @Summary Pin load
+// @Description Pin a load for the currently logged-in user, so it is listed first in day details and named in the heatmap cell tooltip when they view it. Pinning again keeps the original time.
+// @Tags Pins
+// @Produce json
+// @Param id path int true "Load ID"
+// @Success 200 {object} models.Pin "Pin"
+// @Failure 400 {object} map[string]string "Invalid load ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Load not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/loads/{id}/pin [post]
+func (h *HeatmapHandler) PinLoad(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	loadID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid load ID"})
+	}
+
+	pin, err := h.pinService.PinLoad(c.Request().Context(), userEmail, loadID)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoadNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, pin)
+}
+
+// UnpinLoad removes the logged-in user's pin on a load
+// @Summary Unpin load
+// @Description Remove the currently logged-in user's pin on a load
+// @Tags Pins
+// @Produce json
+// @Param id path int true "Load ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid load ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Pin not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/loads/{id}/pin [delete]
+func (h *HeatmapHandler) UnpinLoad(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	loadID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid load ID"})
+	}
+
+	if err := h.pinService.UnpinLoad(c.Request().Context(), userEmail, loadID); err != nil {
+		if errors.Is(err, repository.ErrPinNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"success": "load unpinned"})
+}
+
+// ListMyPins lists the logged-in user's pinned loads
+// @Summary List pins
+// @Description List the loads the currently logged-in user pinned, from today on, in date order
+// @Tags Pins
+// @Produce json
+// @Success 200 {array} models.Pin "Pins"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-pins [get]
+func (h *HeatmapHandler) ListMyPins(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	pins, err := h.pinService.ListPins(c.Request().Context(), userEmail)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, pins)
+}
+
+// GetMyPreferences returns the logged-in user's heatmap preferences
+// @Summary Get heatmap preferences
+// @Description Get how heatmap grids are laid out for the currently logged-in user, the weekday each week starts on, their own choice or the server's WEEK_START, whether their own heatmap is private, and the load sources left out of the heatmaps they view
+// @Tags Heatmap
+// @Produce json
+// @Success 200 {object} models.HeatmapPreferences "Preferences"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Not a person"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-preferences [get]
+func (h *HeatmapHandler) GetMyPreferences(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	prefs, err := h.heatmapService.GetPreferences(c.Request().Context(), userEmail)
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateMyPreferences sets the logged-in user's heatmap preferences
+// @Summary Update heatmap preferences
+// @Description Set the weekday (monday .. sunday) the currently logged-in user's heatmap weeks start on, for every heatmap they view. Grid columns follow it and rows are labelled with ISO week numbers. A null or missing week_start follows the server's WEEK_START again. private: true hides the user's heatmap from everyone but themselves, owners of their groups, and admins; omitted, it is left unchanged. hidden_sources replaces the load sources (e.g. gcal) left out of every heatmap the user views, an empty list showing them all again; omitted, it is left unchanged.
+// @Tags Heatmap
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateHeatmapPreferencesRequest true "Preferences"
+// @Success 200 {object} models.HeatmapPreferences "Updated preferences"
+// @Failure 400 {object} map[string]string "Invalid weekday"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Not a person"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-preferences [put]
+func (h *HeatmapHandler) UpdateMyPreferences(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.UpdateHeatmapPreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	prefs, err := h.heatmapService.UpdatePreferences(c.Request().Context(), userEmail, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWeekday) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// Dashboard renders a group's anonymized heatmap page for public dashboards
+func (h *HeatmapHandler) Dashboard(c echo.Context) error {
+	groupID := c.Param("group")
+
+	if err := h.heatmapService.CheckDashboard(c.Request().Context(), groupID); err != nil {
+		if errors.Is(err, service.ErrDashboardNotEnabled) {
+			return c.String(http.StatusNotFound, "Dashboard not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+
+	// Dashboards are shared screens, so no viewer's hidden sources apply
+	start, end := service.HeatmapWindow(time.Now())
+	heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), "", groupID, "", nil, start, end)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+	weekStart, err := h.heatmapService.WeekStart(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+
+	data := map[string]interface{}{
+		"Group":  heatmapData.Entity,
+		"Months": groupDaysByMonth(heatmapData.Days, weekStart),
+	}
+
+	return h.templates.ExecuteTemplate(c.Response().Writer, "dashboard", data)
+}
+
+// GetDashboardPartial returns a group's anonymized heatmap grid
+// @Summary Get anonymized group heatmap partial
+// @Description Returns a group's heatmap grid with only aggregate utilization per day, for groups shown on public dashboards. Members and loads are not included.
+// @Tags Heatmap
+// @Produce text/html
+// @Param group path string true "Group ID"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Param If-Modified-Since header string false "Last-Modified of a previous response"
+// @Success 200 {string} string "HTML partial for the anonymized heatmap grid"
+// @Success 304 "Heatmap unchanged since the given ETag or date"
+// @Header 200 {string} ETag "Heatmap version"
+// @Header 200 {string} Last-Modified "Time of the last change to the heatmap"
+// @Failure 404 {string} string "Group not shown on dashboards"
+// @Failure 500 {string} string "Failed to load dashboard"
+// @Router /api/dashboard/{group} [get]
+func (h *HeatmapHandler) GetDashboardPartial(c echo.Context) error {
+	groupID := c.Param("group")
+	now := time.Now()
+
+	if err := h.heatmapService.CheckDashboard(c.Request().Context(), groupID); err != nil {
+		if errors.Is(err, service.ErrDashboardNotEnabled) {
+			return c.String(http.StatusNotFound, "Dashboard not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+
+	start, end := service.HeatmapWindow(now)
+	etag, lastModified, err := h.heatmapService.GetHeatmapVersion(c.Request().Context(), groupID, start, end, now)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+	weekStart, err := h.heatmapService.WeekStart(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+	if middleware.NotModified(c, weekStartETag(etag, weekStart), lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	heatmapData, err := h.heatmapService.GetHeatmapData(c.Request().Context(), "", groupID, "", nil, start, end)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load dashboard")
+	}
+
+	data := map[string]interface{}{
+		"Months": groupDaysByMonth(heatmapData.Days, weekStart),
+	}
+
+	return h.templates.ExecuteTemplate(c.Response().Writer, "dashboard_grid", data)
+}
+
+// GetRollupHeatmap returns the combined heatmap grid of a manager's reports
+// @Summary Get roll-up heatmap partial for a manager
+// @Description Returns one heatmap grid for everyone reporting to a person, directly or through their own managers, following manager_email from directory sync. Each day shows the reports' summed load as a share of their summed capacity; no explicit group is needed.
+// @Tags Heatmap
+// @Produce text/html
+// @Param entity path string true "Manager's email"
+// @Success 200 {string} string "HTML partial for the roll-up heatmap grid"
+// @Failure 404 {string} string "Manager not found or has no reports"
+// @Failure 500 {string} string "Failed to load heatmap"
+// @Router /api/heatmap/{entity}/reports [get]
+func (h *HeatmapHandler) GetRollupHeatmap(c echo.Context) error {
+	heatmapData, err := h.heatmapService.GetRollupHeatmapData(c.Request().Context(), c.Param("entity"))
+	if errors.Is(err, repository.ErrEntityNotFound) {
+		return c.String(http.StatusNotFound, "Entity not found")
+	}
+	if errors.Is(err, service.ErrNoReports) {
+		return c.String(http.StatusNotFound, "No one reports to this person")
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	weekStart, err := h.heatmapService.WeekStart(c.Request().Context(), middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+
+	data := map[string]interface{}{
+		"Months": groupDaysByMonth(heatmapData.Days, weekStart),
+	}
+
+	return h.templates.ExecuteTemplate(c.Response().Writer, "dashboard_grid", data)
 }
 
 // MonthData represents grouped days for a month
@@ -130,6 +734,46 @@ type MonthData struct {
 	Month     time.Month
 	MonthName string
 	Days      []DayData
+	// WeekStart is the weekday in the first column of the grid
+	WeekStart time.Weekday
+}
+
+// WeekData is one row of a month's grid
+type WeekData struct {
+	// Number is the ISO 8601 week number, of the row's Monday
+	Number int
+	// Days holds seven cells from the week start, nil for dates outside the
+	// month or the heatmap window
+	Days []*DayData
+}
+
+// Weeks lays out the month's days in rows of seven starting on WeekStart, so
+// each column of the grid is one weekday
+func (m MonthData) Weeks() []WeekData {
+	var weeks []WeekData
+	var rowStart time.Time
+	for i := range m.Days {
+		day := &m.Days[i]
+		col := (int(day.Date.Weekday()) - int(m.WeekStart) + 7) % 7
+		if start := day.Date.AddDate(0, 0, -col); len(weeks) == 0 || !start.Equal(rowStart) {
+			rowStart = start
+			monday := start.AddDate(0, 0, (int(time.Monday)-int(m.WeekStart)+7)%7)
+			_, number := monday.ISOWeek()
+			weeks = append(weeks, WeekData{Number: number, Days: make([]*DayData, 7)})
+		}
+		weeks[len(weeks)-1].Days[col] = day
+	}
+	return weeks
+}
+
+// Weekdays returns the grid's column headings, two-letter weekday names
+// from WeekStart
+func (m MonthData) Weekdays() []string {
+	names := make([]string, 7)
+	for i := range names {
+		names[i] = ((m.WeekStart + time.Weekday(i)) % 7).String()[:2]
+	}
+	return names
 }
 
 // DayData represents a single day in the heatmap
@@ -141,10 +785,43 @@ type DayData struct {
 	Capacity float64
 	Color    string
 	IsToday  bool
+	// Holiday names the public holiday on this day in the entity's region
+	Holiday string
+	// Utilization is load as a percentage of capacity, 0 without capacity
+	Utilization float64
+	// Pinned holds the titles of loads the viewer pinned on this day
+	Pinned []string
 }
 
-// groupDaysByMonth groups heatmap days by month for template rendering
-func groupDaysByMonth(days []models.HeatmapDay) []MonthData {
+// weekStartETag tags grids laid out from a week start other than Monday
+// apart, since viewers of the same heatmap may start weeks on different days
+func weekStartETag(etag string, weekStart time.Weekday) string {
+	if weekStart == time.Monday {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + strings.ToLower(weekStart.String()) + `"`
+}
+
+// attachPins lists the titles of the viewer's pinned loads on their days
+func attachPins(months []MonthData, pins []models.Pin) {
+	if len(pins) == 0 {
+		return
+	}
+
+	titles := make(map[string][]string)
+	for _, p := range pins {
+		titles[p.Date] = append(titles[p.Date], p.Title)
+	}
+	for i := range months {
+		for j := range months[i].Days {
+			months[i].Days[j].Pinned = titles[months[i].Days[j].DateStr]
+		}
+	}
+}
+
+// groupDaysByMonth groups heatmap days by month for template rendering, with
+// weeks starting on weekStart
+func groupDaysByMonth(days []models.HeatmapDay, weekStart time.Weekday) []MonthData {
 	today := time.Now().Truncate(24 * time.Hour)
 	monthMap := make(map[string]*MonthData)
 	var monthOrder []string
@@ -157,6 +834,7 @@ func groupDaysByMonth(days []models.HeatmapDay) []MonthData {
 				Month:     day.Date.Month(),
 				MonthName: day.Date.Month().String(),
 				Days:      []DayData{},
+				WeekStart: weekStart,
 			}
 			monthOrder = append(monthOrder, key)
 		}
@@ -169,7 +847,12 @@ func groupDaysByMonth(days []models.HeatmapDay) []MonthData {
 			Capacity: day.Capacity,
 			Color:    day.Color,
 			IsToday:  day.Date.Equal(today),
+			Holiday:  day.Holiday,
 		})
+		if day.Capacity > 0 {
+			days := monthMap[key].Days
+			days[len(days)-1].Utilization = day.Load / day.Capacity * 100
+		}
 	}
 
 	result := make([]MonthData, 0, len(monthOrder))
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/cache"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// HolidayHandler imports the public holidays that persons and groups of a
+// region have no capacity on
+type HolidayHandler struct {
+	holidayService *service.HolidayService
+	renderCache    *cache.RenderCache
+	validate       *validator.Validate
+}
+
+func NewHolidayHandler(holidayService *service.HolidayService, renderCache *cache.RenderCache) *HolidayHandler {
+	return &HolidayHandler{
+		holidayService: holidayService,
+		renderCache:    renderCache,
+		validate:       validator.New(),
+	}
+}
+
+// ListHolidays returns a region's holidays in a year
+// @Summary List holidays
+// @Description List a region's public holidays in a year, in date order
+// @Tags Holidays
+// @Produce json
+// @Security ApiKeyAuth
+// @Param region path string true "Region, such as a country code"
+// @Param year query int false "Year, default this year"
+// @Success 200 {array} models.Holiday "Holidays"
+// @Failure 400 {object} map[string]string "Invalid region or year"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/holidays/{region} [get]
+func (h *HolidayHandler) ListHolidays(c echo.Context) error {
+	year := time.Now().UTC().Year()
+	if s := c.QueryParam("year"); s != "" {
+		var err error
+		if year, err = strconv.Atoi(s); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "year must be a number",
+			})
+		}
+	}
+
+	holidays, err := h.holidayService.List(c.Request().Context(), c.Param("region"), year)
+	if err != nil {
+		return c.JSON(holidayErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, holidays)
+}
+
+// ImportHolidays stores the events of an iCalendar file as holidays
+// @Summary Import holidays from a calendar
+// @Description Store the events of an iCalendar (.ics) file, such as a public holidays calendar exported from Google Calendar, as a region's holidays. Each day of a multi-day event is a holiday named by its SUMMARY. Holidays already stored for a day are renamed; others are kept. Persons and groups whose region is set have no capacity on their region's holidays, and heatmaps mark them.
+// @Tags Holidays
+// @Accept text/calendar
+// @Produce json
+// @Security ApiKeyAuth
+// @Param region path string true "Region, such as a country code"
+// @Param calendar body string true "iCalendar file, at most 1 MiB"
+// @Success 200 {object} models.HolidayImport "Imported holidays"
+// @Failure 400 {object} map[string]string "Invalid region or calendar"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/holidays/{region}/import [post]
+func (h *HolidayHandler) ImportHolidays(c echo.Context) error {
+	result, err := h.holidayService.ImportICS(c.Request().Context(), c.Param("region"), c.Request().Body)
+	if err != nil {
+		return c.JSON(holidayErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	h.renderCache.InvalidateAll()
+	return c.JSON(http.StatusOK, result)
+}
+
+// FetchHolidays imports a region's public holidays from the holidays API
+// @Summary Import holidays from the holidays API
+// @Description Import a region's public holidays of a year from the holidays API (HOLIDAYS_API_URL, Nager.Date by default, where regions are ISO 3166-1 alpha-2 country codes such as ID). Holidays of only some of the region's provinces are left out. Holidays already stored for a day are renamed; others are kept.
+// @Tags Holidays
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param region path string true "Region, such as a country code"
+// @Param request body models.FetchHolidaysRequest true "Year to import"
+// @Success 200 {object} models.HolidayImport "Imported holidays"
+// @Failure 400 {object} map[string]string "Invalid region or year, or no holidays found"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error, or the holidays API failed"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/holidays/{region}/fetch [post]
+func (h *HolidayHandler) FetchHolidays(c echo.Context) error {
+	var req models.FetchHolidaysRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.holidayService.Fetch(c.Request().Context(), c.Param("region"), req.Year)
+	if err != nil {
+		return c.JSON(holidayErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	h.renderCache.InvalidateAll()
+	return c.JSON(http.StatusOK, result)
+}
+
+// DeleteHoliday removes one of a region's holidays
+// @Summary Delete a holiday
+// @Description Remove one of a region's holidays, such as one a calendar listed by mistake, giving persons and groups of the region their capacity back that day
+// @Tags Holidays
+// @Produce json
+// @Security ApiKeyAuth
+// @Param region path string true "Region, such as a country code"
+// @Param date path string true "Holiday date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]string "Holiday deleted"
+// @Failure 400 {object} map[string]string "Invalid region or date"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Holiday not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/holidays/{region}/{date} [delete]
+func (h *HolidayHandler) DeleteHoliday(c echo.Context) error {
+	if err := h.holidayService.Delete(c.Request().Context(), c.Param("region"), c.Param("date")); err != nil {
+		return c.JSON(holidayErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	h.renderCache.InvalidateAll()
+	return c.JSON(http.StatusOK, map[string]string{"success": "holiday deleted"})
+}
+
+// holidayErrorStatus maps holiday errors to HTTP statuses
+func holidayErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRegion), errors.Is(err, service.ErrInvalidHolidays),
+		errors.Is(err, service.ErrInvalidDate):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrHolidayNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
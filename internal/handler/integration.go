@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type IntegrationHandler struct {
+	integrationService *service.IntegrationService
+	templates          Templates
+}
+
+func NewIntegrationHandler(integrationService *service.IntegrationService, templates Templates) *IntegrationHandler {
+	return &IntegrationHandler{integrationService: integrationService, templates: templates}
+}
+
+// GetIntegrationHealth returns how each integration fared over the past day
+// @Summary Integration health
+// @Description Per source system: the last successful load sync, loads synced in the past 24 hours, failed upserts in the past 24 hours and the latest few of them. Per webhook event: deliveries and failures in the past 24 hours, the success rate, and the latest failure. Sources with errors are listed first. Failures are kept for a week.
+// @Tags Reports
+// @Produce json
+// @Success 200 {object} models.IntegrationHealthReport "Integration health"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/integrations/health [get]
+func (h *IntegrationHandler) GetIntegrationHealth(c echo.Context) error {
+	report, err := h.integrationService.GetHealthReport(c.Request().Context(), time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// IntegrationsPage renders the integration health report for ops
+func (h *IntegrationHandler) IntegrationsPage(c echo.Context) error {
+	report, err := h.integrationService.GetHealthReport(c.Request().Context(), time.Now())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load integration health")
+	}
+
+	type webhookRow struct {
+		models.WebhookHealth
+		SuccessPercent float64
+	}
+	webhooks := make([]webhookRow, 0, len(report.Webhooks))
+	for _, w := range report.Webhooks {
+		webhooks = append(webhooks, webhookRow{WebhookHealth: w, SuccessPercent: w.SuccessRate * 100})
+	}
+
+	data := map[string]interface{}{
+		"GeneratedAt": report.GeneratedAt,
+		"Sources":     report.Sources,
+		"Webhooks":    webhooks,
+	}
+	return h.templates.ExecuteTemplate(c.Response().Writer, "integrations", data)
+}
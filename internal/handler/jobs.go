@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// JobHandler starts bulk operations as background jobs and reports their
+// progress
+type JobHandler struct {
+	jobs           *service.JobRunner
+	loadService    *service.LoadService
+	heatmapService *service.HeatmapService
+	validate       *validator.Validate
+}
+
+func NewJobHandler(jobs *service.JobRunner, loadService *service.LoadService, heatmapService *service.HeatmapService) *JobHandler {
+	return &JobHandler{
+		jobs:           jobs,
+		loadService:    loadService,
+		heatmapService: heatmapService,
+		validate:       validator.New(),
+	}
+}
+
+// BulkUpsertLoads upserts many loads in a background job
+// @Summary Upsert loads in bulk
+// @Description Upsert up to 10000 loads, each as POST /api/loads/upsert would, tombstones included, in a background job. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. A load that fails does not stop the others; it is counted under failed and listed under errors by its index and external_id.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param loads body models.BulkUpsertLoadsRequest true "Loads to upsert"
+// @Success 202 {object} models.Job "Queued job"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/bulk-upsert [post]
+func (h *JobHandler) BulkUpsertLoads(c echo.Context) error {
+	var req models.BulkUpsertLoadsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	loads := req.Loads
+	return h.submit(c, &service.BulkJob{
+		Kind:  models.JobKindBulkUpsert,
+		Total: len(loads),
+		Run: func(ctx context.Context, p *service.JobProgress) error {
+			for i := range loads {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err := h.upsertOne(ctx, &loads[i]); err != nil {
+					p.Fail(i, loads[i].ExternalID, err)
+					continue
+				}
+				p.Done()
+			}
+			return nil
+		},
+	})
+}
+
+// upsertOne applies one load of a bulk upsert
+func (h *JobHandler) upsertOne(ctx context.Context, load *models.UpsertLoadRequest) error {
+	if load.Deleted {
+		if load.ExternalID == "" {
+			return errors.New("external_id is required")
+		}
+		_, err := h.loadService.DeleteLoadByExternalID(ctx, load.ExternalID)
+		return err
+	}
+	if err := h.validate.Struct(load); err != nil {
+		return err
+	}
+	_, err := h.loadService.UpsertLoad(ctx, load)
+	return err
+}
+
+// ReassignLoads moves a person's loads to someone else in a background job
+// @Summary Reassign a person's loads
+// @Description Move every load assigned to from that lasts through from_date (default today), optionally only those of one source, to to, in a background job. to must be an active person; where they already share a load, the weights are added together. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. Loads that fail are listed under errors by their ID.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reassign body models.ReassignLoadsRequest true "Persons to reassign between"
+// @Success 202 {object} models.Job "Queued job"
+// @Failure 400 {object} map[string]string "Invalid request body or from_date, or to is not a person"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 409 {object} map[string]string "to is offboarded"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/loads/reassign [post]
+func (h *JobHandler) ReassignLoads(c echo.Context) error {
+	var req models.ReassignLoadsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	job, err := h.loadService.ReassignLoads(c.Request().Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, repository.ErrNotAPerson):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, repository.ErrEntityArchived):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return h.submit(c, job)
+}
+
+// BackfillSnapshots rebuilds heatmap snapshots in a background job
+// @Summary Backfill heatmap snapshots
+// @Description Discard and recompute the heatmap snapshots of the given entities, or of every entity when entity_ids is empty, one entity at a time in a background job. Answers 202 with the job at once; poll GET /api/jobs/{id}, also given in the Location header, for its progress. Entities that fail, or do not exist, are listed under errors by their ID.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param backfill body models.BackfillSnapshotsRequest false "Entities to backfill"
+// @Success 202 {object} models.Job "Queued job"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/snapshots/backfill [post]
+func (h *JobHandler) BackfillSnapshots(c echo.Context) error {
+	var req models.BackfillSnapshotsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	job, err := h.heatmapService.BackfillSnapshots(c.Request().Context(), req.EntityIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return h.submit(c, job)
+}
+
+// submit queues job and answers 202 pointing at it
+func (h *JobHandler) submit(c echo.Context, job *service.BulkJob) error {
+	stored, err := h.jobs.Submit(c.Request().Context(), job)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set(echo.HeaderLocation, middleware.AppPath(c, "/api/jobs/")+strconv.FormatInt(stored.ID, 10))
+	return c.JSON(http.StatusAccepted, stored)
+}
+
+// GetJob reports a background job's progress
+// @Summary Get a background job
+// @Description A bulk operation's status (queued, running, succeeded or failed), how many of its items it has processed and how many failed, and the first 1000 failed items with their errors. A job fails as a whole only when it cannot go on, such as when the server stops; the reason is under error.
+// @Tags Jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} models.Job "Job"
+// @Failure 400 {object} map[string]string "Invalid job ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Job not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/jobs/{id} [get]
+func (h *JobHandler) GetJob(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobs.Get(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, job)
+}
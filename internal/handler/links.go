@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// LinkHandler serves the read-only pages behind the signed links in
+// notifications. The signature stands in for a session, so nothing here
+// writes, and every page shows only the linked person's own data.
+type LinkHandler struct {
+	links           *service.LinkSigner
+	heatmapService  *service.HeatmapService
+	noteService     *service.NoteService
+	capacityService *service.CapacityService
+	templates       Templates
+}
+
+// NewLinkHandler returns a handler for links signed by links, nil when
+// notification links are disabled
+func NewLinkHandler(
+	links *service.LinkSigner,
+	heatmapService *service.HeatmapService,
+	noteService *service.NoteService,
+	capacityService *service.CapacityService,
+	templates Templates,
+) *LinkHandler {
+	return &LinkHandler{
+		links:           links,
+		heatmapService:  heatmapService,
+		noteService:     noteService,
+		capacityService: capacityService,
+		templates:       templates,
+	}
+}
+
+// LinkedDay renders a person's loads and notes on a day from a signed link
+func (h *LinkHandler) LinkedDay(c echo.Context) error {
+	email := c.Param("email")
+	dateStr := c.Param("date")
+
+	if h.links == nil {
+		return c.String(http.StatusNotFound, "Not found")
+	}
+	if err := h.links.VerifyDay(email, dateStr, c.QueryParam("expires"), c.QueryParam("sig"), time.Now()); err != nil {
+		return c.String(http.StatusForbidden, "This link is invalid or has expired")
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid date format")
+	}
+
+	ctx := c.Request().Context()
+	loads, totalLoad, capacity, err := h.heatmapService.GetDayDetails(ctx, email, date, "", nil)
+	if err == nil {
+		// Seen as the person themselves would see it
+		loads, err = h.heatmapService.HidePrivateAssignees(ctx, email, loads)
+	}
+	if err == nil {
+		loads, err = h.heatmapService.HideConfidentialLoads(ctx, email, loads)
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+	notes, err := h.noteService.ForDay(ctx, email, date, loads)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load day details")
+	}
+
+	data := map[string]interface{}{
+		"Date":      date,
+		"DateStr":   dateStr,
+		"Loads":     loads,
+		"Tags":      service.TagBreakdown(loads),
+		"Notes":     notes,
+		"TotalLoad": totalLoad,
+		"Capacity":  capacity,
+		"EntityID":  email,
+	}
+	return h.render(c, "linked_day", data)
+}
+
+// LinkedSettings renders a person's heatmap preferences and capacity from a
+// signed link
+func (h *LinkHandler) LinkedSettings(c echo.Context) error {
+	email := c.Param("email")
+
+	if h.links == nil {
+		return c.String(http.StatusNotFound, "Not found")
+	}
+	if err := h.links.VerifySettings(email, c.QueryParam("expires"), c.QueryParam("sig"), time.Now()); err != nil {
+		return c.String(http.StatusForbidden, "This link is invalid or has expired")
+	}
+
+	ctx := c.Request().Context()
+	person, overrides, err := h.capacityService.GetCapacityInfo(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return c.String(http.StatusNotFound, "Person not found")
+		}
+		return c.String(http.StatusInternalServerError, "Failed to load settings")
+	}
+	pattern, err := h.capacityService.GetWeeklyPattern(ctx, email)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load settings")
+	}
+	type weekdayCapacity struct {
+		Weekday  string
+		Capacity float64
+	}
+	weeklyPattern := make([]weekdayCapacity, 0, len(pattern))
+	for _, day := range pattern {
+		weeklyPattern = append(weeklyPattern, weekdayCapacity{Weekday: day.Weekday, Capacity: *day.Capacity})
+	}
+	prefs, err := h.heatmapService.GetPreferences(ctx, email)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load settings")
+	}
+
+	data := map[string]interface{}{
+		"Person":        person,
+		"Overrides":     overrides,
+		"WeeklyPattern": weeklyPattern,
+		"Preferences":   prefs,
+	}
+	return h.render(c, "linked_settings", data)
+}
+
+// render writes a linked page. The signed URL is as good as a password until
+// it expires, so it is neither cached nor sent on as a Referer to the load
+// links on the page.
+func (h *LinkHandler) render(c echo.Context, name string, data map[string]interface{}) error {
+	header := c.Response().Header()
+	header.Set(echo.HeaderCacheControl, "private, no-store")
+	header.Set("Referrer-Policy", "no-referrer")
+	return h.templates.ExecuteTemplate(c.Response().Writer, name, data)
+}
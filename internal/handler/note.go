@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type NoteHandler struct {
+	noteService *service.NoteService
+	validate    *validator.Validate
+}
+
+func NewNoteHandler(noteService *service.NoteService) *NoteHandler {
+	return &NoteHandler{
+		noteService: noteService,
+		validate:    validator.New(),
+	}
+}
+
+// AddLoadNote comments on a load as the logged-in user
+// @Summary Add load note
+// @Description Attach a short comment to a load as the currently logged-in user. Notes are shown in the day details of the load's assignees and groups.
+// @Tags Notes
+// @Accept json
+// @Produce json
+// @Param id path int true "Load ID"
+// @Param note body models.CreateLoadNoteRequest true "Note text, at most 280 characters"
+// @Success 201 {object} models.Note "Created note"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Load not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/loads/{id}/notes [post]
+func (h *NoteHandler) AddLoadNote(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	loadID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid load ID"})
+	}
+
+	var req models.CreateLoadNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note, err := h.noteService.AddLoadNote(c.Request().Context(), userEmail, loadID, req.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmptyNote):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, repository.ErrLoadNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, note)
+}
+
+// AddMyDayNote notes something about the logged-in user's own day
+// @Summary Add day note
+// @Description Attach a short note, such as "travel day", to a day of the currently logged-in user. It is shown in their day details and those of their groups.
+// @Tags Notes
+// @Accept json
+// @Produce json
+// @Param note body models.CreateDayNoteRequest true "Day and note text, at most 280 characters"
+// @Success 201 {object} models.Note "Created note"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-notes [post]
+func (h *NoteHandler) AddMyDayNote(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.CreateDayNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note, err := h.noteService.AddDayNote(c.Request().Context(), userEmail, req.Date, req.Body)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrEmptyNote) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, note)
+}
+
+// DeleteMyNote removes one of the logged-in user's notes
+// @Summary Delete note
+// @Description Remove a load or day note written by the currently logged-in user
+// @Tags Notes
+// @Produce json
+// @Param id path int true "Note ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid note ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Note not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/my-notes/{id} [delete]
+func (h *NoteHandler) DeleteMyNote(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid note id"})
+	}
+
+	if err := h.noteService.DeleteNote(c.Request().Context(), userEmail, id); err != nil {
+		if errors.Is(err, repository.ErrNoteNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"success": "note deleted"})
+}
+
+// UpdateEntityInfo replaces the notes and links kept on an entity
+// @Summary Update entity notes and links
+// @Description Replace the freeform notes and important links, such as a team charter or on-call schedule, shown in a panel beside an entity's heatmap. Links are shown in the order given and must be http or https. Only admins (ADMIN_EMAILS) may.
+// @Tags Notes
+// @Accept json
+// @Produce json
+// @Param id path string true "Entity ID"
+// @Param info body models.UpdateEntityInfoRequest true "Notes, at most 5000 characters, and at most 20 links"
+// @Success 200 {object} models.EntityInfo "Notes and links after the change"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/entities/{id}/info [put]
+func (h *NoteHandler) UpdateEntityInfo(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.UpdateEntityInfoRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	info, err := h.noteService.UpdateEntityInfo(c.Request().Context(), userEmail, c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotInfoAdmin):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidEntityLink):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, repository.ErrEntityNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "entity not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, info)
+}
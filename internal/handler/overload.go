@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type OverloadHandler struct {
+	overloadService *service.OverloadService
+}
+
+func NewOverloadHandler(overloadService *service.OverloadService) *OverloadHandler {
+	return &OverloadHandler{overloadService: overloadService}
+}
+
+// GetResolutionReport reports how quickly each group's overloads get fixed
+// @Summary Overload resolution report
+// @Description Per group, how many member person-days between from and to went over capacity, how many were brought back under, stayed open, or passed unresolved, and the mean time to resolution
+// @Tags Reports
+// @Security ApiKeyAuth
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD), default 30 days before to"
+// @Param to query string false "Last date (YYYY-MM-DD), default today"
+// @Success 200 {object} models.OverloadResolutionReport "Resolution report"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Missing or invalid API key"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/reports/overload-resolution [get]
+func (h *OverloadHandler) GetResolutionReport(c echo.Context) error {
+	report, err := h.overloadService.GetResolutionReport(c.Request().Context(), c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// Metrics exposes overload tracking counters for scraping
+// @Summary Overload metrics
+// @Description Overload tracking counters in the Prometheus text format: open, resolved, and unresolved person-days and total time to resolution
+// @Tags Reports
+// @Produce plain
+// @Success 200 {string} string "Metrics in the Prometheus text format"
+// @Failure 500 {string} string "Failed to get metrics"
+// @Router /metrics [get]
+func (h *OverloadHandler) Metrics(c echo.Context) error {
+	m, err := h.overloadService.GetMetrics(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to get metrics")
+	}
+
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(formatOverloadMetrics(m)))
+}
+
+// formatOverloadMetrics renders overload counters in the Prometheus text format
+func formatOverloadMetrics(m *models.OverloadMetrics) string {
+	metrics := []struct {
+		name, help string
+		value      float64
+	}{
+		{"heatmap_overload_open_days", "Person-days from today on that are over capacity.", float64(m.Open)},
+		{"heatmap_overload_unresolved_days", "Past person-days still over capacity when their date passed.", float64(m.Unresolved)},
+		{"heatmap_overload_resolved_days", "Person-days brought back under capacity.", float64(m.Resolved)},
+		{"heatmap_overload_resolution_seconds_sum", "Total time resolved person-days spent over capacity.", m.ResolutionSeconds},
+	}
+
+	var b strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", metric.name, metric.help, metric.name, metric.name, metric.value)
+	}
+	return b.String()
+}
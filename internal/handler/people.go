@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type PeopleHandler struct {
+	peopleService *service.PeopleService
+	validate      *validator.Validate
+}
+
+func NewPeopleHandler(peopleService *service.PeopleService) *PeopleHandler {
+	return &PeopleHandler{
+		peopleService: peopleService,
+		validate:      validator.New(),
+	}
+}
+
+// OnboardPerson creates a person with groups and capacity in one call
+// @Summary Onboard a person
+// @Description Create a person, add them to groups, and set their capacity with an optional ramp-up
+// @Tags People
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param person body models.OnboardPersonRequest true "Person to onboard"
+// @Success 201 {object} models.OnboardPersonResponse "Onboarded person"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 409 {object} map[string]string "Entity already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/people/onboard [post]
+func (h *PeopleHandler) OnboardPerson(c echo.Context) error {
+	var req models.OnboardPersonRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := h.peopleService.Onboard(c.Request().Context(), &req)
+	if err != nil {
+		return c.JSON(peopleErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// OffboardPerson archives a person and frees their future loads
+// @Summary Offboard a person
+// @Description Archive a person, remove them from loads after their last day, and notify the webhook destination
+// @Tags People
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param email path string true "Person email"
+// @Param offboarding body models.OffboardPersonRequest false "Last working day"
+// @Success 200 {object} models.OffboardPersonResponse "Offboarding result"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 409 {object} map[string]string "Person already offboarded"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/people/{email}/offboard [post]
+func (h *PeopleHandler) OffboardPerson(c echo.Context) error {
+	email := c.Param("email")
+
+	var req models.OffboardPersonRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	resp, err := h.peopleService.Offboard(c.Request().Context(), email, &req)
+	if err != nil {
+		return c.JSON(peopleErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ChangeEntityID moves an entity to a new ID
+// @Summary Change an entity's ID
+// @Description Move an entity to a new ID, such as a person whose email was renamed. Their loads, capacity, group memberships and ownerships, notes, history and logins move with them in one transaction; the old ID is then unknown, and shows up as deleted in GET /api/entities/changes. A person's new ID must be an email.
+// @Tags Entities
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Current entity ID"
+// @Param change body models.ChangeEntityIDRequest true "New ID"
+// @Success 200 {object} models.ChangeEntityIDResponse "Entity moved"
+// @Failure 400 {object} map[string]string "Invalid request, or a new ID that is the current one or, for a person, not an email"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 409 {object} map[string]string "New ID already in use"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/entities/{id}/change-id [post]
+func (h *PeopleHandler) ChangeEntityID(c echo.Context) error {
+	id := c.Param("id")
+
+	var req models.ChangeEntityIDRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := h.peopleService.ChangeID(c.Request().Context(), id, &req)
+	if err != nil {
+		return c.JSON(peopleErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ImportGroups creates groups and memberships from a (group, member) mapping
+// @Summary Import groups
+// @Description Load an org structure in one call, such as an HR export. Send JSON rows, or a CSV with Content-Type text/csv whose header names a "group" and a "member" column (other columns are ignored). Missing groups are created with their ID as title, and unknown members as persons with the default capacity from the settings (5.0 unless an admin changed it). Existing groups, persons and memberships are left as they are, so the same import can be repeated safely. Nothing is written if any row fails.
+// @Tags Groups
+// @Accept json,text/csv
+// @Produce json
+// @Security ApiKeyAuth
+// @Param import body models.GroupImportRequest true "Rows to import"
+// @Success 200 {object} models.GroupImportResponse "Import result"
+// @Failure 400 {object} map[string]string "Invalid rows, or a name that is both a group and a member or has the other type"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 409 {object} map[string]string "Group or person is archived"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/groups/import [post]
+func (h *PeopleHandler) ImportGroups(c echo.Context) error {
+	var req models.GroupImportRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		rows, err := service.ParseGroupImportCSV(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		req.Rows = rows
+	} else if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := h.peopleService.ImportGroups(c.Request().Context(), req.Rows)
+	if err != nil {
+		return c.JSON(peopleErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListAutoCreatedPersons lists auto-created persons waiting for review
+// @Summary List auto-created persons
+// @Description Persons created because an upsert named an unknown assignee, oldest first, with the source of the upsert and how many loads they are assigned. Confirm the real ones with POST /api/people/auto-created/confirm and delete mistyped emails with POST /api/people/auto-created/reject.
+// @Tags People
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.AutoCreatedPerson "Persons waiting for review"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/people/auto-created [get]
+func (h *PeopleHandler) ListAutoCreatedPersons(c echo.Context) error {
+	persons, err := h.peopleService.ListAutoCreated(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, persons)
+}
+
+// ConfirmAutoCreatedPersons keeps reviewed auto-created persons
+// @Summary Confirm auto-created persons
+// @Description Keep the listed auto-created persons and take them off the review queue. They still count against their source's quota for the day they were created. Persons not waiting for review are listed as skipped.
+// @Tags People
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.ReviewAutoCreatedRequest true "Reviewed emails"
+// @Success 200 {object} models.ReviewAutoCreatedResponse "Confirmed and skipped persons"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/people/auto-created/confirm [post]
+func (h *PeopleHandler) ConfirmAutoCreatedPersons(c echo.Context) error {
+	return h.reviewAutoCreated(c, h.peopleService.ConfirmAutoCreated)
+}
+
+// RejectAutoCreatedPersons deletes reviewed auto-created persons
+// @Summary Reject auto-created persons
+// @Description Delete the listed auto-created persons, such as mistyped emails, with their load assignments. Persons already confirmed, or no longer there, are kept and listed as skipped.
+// @Tags People
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.ReviewAutoCreatedRequest true "Reviewed emails"
+// @Success 200 {object} models.ReviewAutoCreatedResponse "Deleted and skipped persons"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/people/auto-created/reject [post]
+func (h *PeopleHandler) RejectAutoCreatedPersons(c echo.Context) error {
+	return h.reviewAutoCreated(c, h.peopleService.RejectAutoCreated)
+}
+
+// reviewAutoCreated binds a review request and answers with what review did
+func (h *PeopleHandler) reviewAutoCreated(c echo.Context, review func(context.Context, []string) (*models.ReviewAutoCreatedResponse, error)) error {
+	var req models.ReviewAutoCreatedRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	resp, err := review(c.Request().Context(), req.Emails)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// peopleErrorStatus maps onboarding, offboarding and import errors to HTTP
+// statuses
+func peopleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidDate), errors.Is(err, service.ErrInvalidImport), errors.Is(err, service.ErrInvalidEntityID),
+		errors.Is(err, repository.ErrNotAPerson), errors.Is(err, repository.ErrNotAGroup):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrEntityNotFound), errors.Is(err, repository.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrEntityExists), errors.Is(err, repository.ErrEntityArchived):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
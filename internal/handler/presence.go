@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// PresenceHandler tells people viewing or editing the same entity or load
+// about each other
+type PresenceHandler struct {
+	presenceService *service.PresenceService
+	templates       Templates
+}
+
+func NewPresenceHandler(presenceService *service.PresenceService, templates Templates) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presenceService,
+		templates:       templates,
+	}
+}
+
+// Heartbeat records that the logged-in user is on an entity or load and
+// returns who else is
+// @Summary Presence heartbeat
+// @Description Record that the currently logged-in user is viewing or editing an entity (a person or group) or a load, and list who else has been on it in the last 30 seconds, editors first. Pages poll this every 10 seconds; HTMX requests get the "X is also editing" indicator as HTML.
+// @Tags Presence
+// @Produce json
+// @Produce text/html
+// @Param kind path string true "What is viewed or edited: entity or load"
+// @Param id path string true "Entity ID or load ID"
+// @Param mode query string false "viewing (default) or editing"
+// @Success 200 {object} models.PresenceResponse "Who else is on it, or the HTML indicator"
+// @Failure 400 {object} map[string]string "Invalid kind or mode"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "Entity or load not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/presence/{kind}/{id} [post]
+func (h *PresenceHandler) Heartbeat(c echo.Context) error {
+	resp := negotiate(c, true)
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return resp.Error(http.StatusUnauthorized, "not authenticated")
+	}
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = models.PresenceViewing
+	}
+
+	presence, err := h.presenceService.Heartbeat(c.Request().Context(), userEmail,
+		c.Param("kind"), c.Param("id"), mode, time.Now())
+	if err != nil {
+		return resp.Error(presenceErrorStatus(err), err.Error())
+	}
+	return resp.Render(http.StatusOK, h.templates, "presence", presence, presence)
+}
+
+// presenceErrorStatus maps presence errors to HTTP statuses
+func presenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidPresenceSubject), errors.Is(err, service.ErrInvalidPresenceMode):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrEntityNotFound), errors.Is(err, repository.ErrLoadNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
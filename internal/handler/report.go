@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	reportService  *service.ReportService
+	heatmapService *service.HeatmapService
+}
+
+func NewReportHandler(reportService *service.ReportService, heatmapService *service.HeatmapService) *ReportHandler {
+	return &ReportHandler{reportService: reportService, heatmapService: heatmapService}
+}
+
+// GetUtilizationReport returns a quarter's utilization report
+// @Summary Quarterly utilization report
+// @Description Per person and per group over a quarter: average load and utilization, p95 daily load, overloaded-day count, and top sources by load. Finished quarters are stored when first generated and served unchanged afterwards; the current quarter is reported up to today. Private persons are left out of the people; group summaries still count them.
+// @Tags Reports
+// @Security ApiKeyAuth
+// @Produce json
+// @Produce text/csv
+// @Param quarter query string false "Quarter as YYYY-QN, default the last finished quarter"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} models.UtilizationReport "Utilization report"
+// @Failure 400 {object} map[string]string "Invalid quarter or format"
+// @Failure 401 {object} map[string]string "Missing or invalid API key"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/reports/utilization [get]
+func (h *ReportHandler) GetUtilizationReport(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "format must be json or csv",
+		})
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.reportService.GetUtilizationReport(ctx, c.QueryParam("quarter"))
+	if err == nil {
+		report.People, err = h.heatmapService.HidePrivateSummaries(ctx, middleware.GetUserEmail(c), report.People)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if format == "csv" {
+		body, err := utilizationCSV(report)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="utilization-%s.csv"`, report.Quarter))
+		return c.Blob(http.StatusOK, "text/csv; charset=utf-8", body)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetCalibrationReport compares planned weights to recorded actual effort
+// @Summary Effort calibration report
+// @Description Per source, the weights planned for assignments whose assignees recorded the effort they actually spent, next to those actuals: totals, their ratio (above 1 means the source's loads are underestimated), and the mean absolute error. Use it to tune weight rules.
+// @Tags Reports
+// @Security ApiKeyAuth
+// @Produce json
+// @Param from query string false "First load date, YYYY-MM-DD, default 90 days before to"
+// @Param to query string false "Last load date, YYYY-MM-DD, default today"
+// @Success 200 {object} models.CalibrationReport "Calibration report"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Missing or invalid API key"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/reports/calibration [get]
+func (h *ReportHandler) GetCalibrationReport(c echo.Context) error {
+	report, err := h.reportService.GetCalibrationReport(c.Request().Context(), c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// utilizationCSV renders a utilization report with one row per person and
+// group. Top sources are listed as source:load separated by semicolons.
+func utilizationCSV(report *models.UtilizationReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"quarter", "type", "entity_id", "title", "days", "average_load",
+		"average_utilization", "p95_load", "overloaded_days", "top_sources"}
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	writeRows := func(entityType models.EntityType, summaries []models.UtilizationSummary) error {
+		for _, s := range summaries {
+			sources := make([]string, 0, len(s.TopSources))
+			for _, src := range s.TopSources {
+				sources = append(sources, src.Source+":"+formatFloat(src.Load))
+			}
+			if err := w.Write([]string{
+				report.Quarter, string(entityType), s.EntityID, s.Title, strconv.Itoa(s.Days),
+				formatFloat(s.AverageLoad), formatFloat(s.AverageUtilization), formatFloat(s.P95Load),
+				strconv.Itoa(s.OverloadedDays), strings.Join(sources, ";"),
+			}); err != nil {
+				return fmt.Errorf("failed to write csv: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := writeRows(models.EntityTypePerson, report.People); err != nil {
+		return nil, err
+	}
+	if err := writeRows(models.EntityTypeGroup, report.Groups); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// responder writes a handler's result as HTML, for the browser and HTMX, or
+// as JSON, for scripts and integrations, so one endpoint serves both.
+type responder struct {
+	c    echo.Context
+	json bool
+}
+
+// negotiate picks the representation for a request. HTMX requests always get
+// HTML, since htmx swaps the body into the page. Otherwise the Accept header
+// decides between application/json and text/html by quality, and
+// fallbackJSON settles headers that prefer neither, such as curl's */*.
+func negotiate(c echo.Context, fallbackJSON bool) responder {
+	header := c.Response().Header()
+	header.Add(echo.HeaderVary, echo.HeaderAccept)
+	header.Add(echo.HeaderVary, htmxRequestHeader)
+
+	req := c.Request()
+	if req.Header.Get(htmxRequestHeader) == htmxRequestValue {
+		return responder{c: c}
+	}
+
+	jsonQ := acceptQuality(req.Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON)
+	htmlQ := acceptQuality(req.Header.Get(echo.HeaderAccept), echo.MIMETextHTML)
+	wantsJSON := jsonQ > htmlQ || (jsonQ == htmlQ && fallbackJSON)
+	return responder{c: c, json: wantsJSON}
+}
+
+// JSON reports whether the response is written as JSON.
+func (r responder) JSON() bool {
+	return r.json
+}
+
+// Render writes payload as JSON, or executes the named template with view.
+func (r responder) Render(status int, templates Templates, name string, view, payload interface{}) error {
+	if r.json {
+		return r.c.JSON(status, payload)
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, view); err != nil {
+		return err
+	}
+	return r.c.HTMLBlob(status, buf.Bytes())
+}
+
+// Message writes payload as JSON, or message as an HTML fragment styled with
+// class, like the status lines under the capacity form.
+func (r responder) Message(status int, class, message string, payload interface{}) error {
+	if r.json {
+		return r.c.JSON(status, payload)
+	}
+	return r.c.HTML(status, `<div class="`+class+`">`+template.HTMLEscapeString(message)+`</div>`)
+}
+
+// Error writes message as {"error": message}, or as a red HTML fragment.
+func (r responder) Error(status int, message string) error {
+	return r.Message(status, "text-red-500", message, map[string]string{"error": message})
+}
+
+// acceptQuality returns the quality an Accept header gives mediaType, 0 when
+// it does not name it. Wildcards are ignored, so "*/*" alone prefers nothing.
+func acceptQuality(accept, mediaType string) float64 {
+	best := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || name != mediaType {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > best {
+			best = q
+		}
+	}
+	return best
+}
@@ -0,0 +1,537 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type ScenarioHandler struct {
+	scenarioRepo   *repository.ScenarioRepository
+	heatmapService *service.HeatmapService
+	templates      Templates
+	validate       *validator.Validate
+}
+
+func NewScenarioHandler(
+	scenarioRepo *repository.ScenarioRepository,
+	heatmapService *service.HeatmapService,
+	templates Templates,
+) *ScenarioHandler {
+	return &ScenarioHandler{
+		scenarioRepo:   scenarioRepo,
+		heatmapService: heatmapService,
+		templates:      templates,
+		validate:       validator.New(),
+	}
+}
+
+// ListScenarios returns all what-if scenarios
+// @Summary List scenarios
+// @Description List all what-if scenarios, newest first
+// @Tags Scenarios
+// @Produce json
+// @Success 200 {array} models.Scenario "List of scenarios"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/scenarios [get]
+func (h *ScenarioHandler) ListScenarios(c echo.Context) error {
+	scenarios, err := h.scenarioRepo.List(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, scenarios)
+}
+
+// GetScenario returns a scenario with its loads and capacities
+// @Summary Get a scenario
+// @Description Get a what-if scenario with its hypothetical loads and capacities
+// @Tags Scenarios
+// @Produce json
+// @Param id path int true "Scenario ID"
+// @Success 200 {object} models.ScenarioDetail "Scenario details"
+// @Failure 400 {object} map[string]string "Invalid scenario ID"
+// @Failure 404 {object} map[string]string "Scenario not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/scenarios/{id} [get]
+func (h *ScenarioHandler) GetScenario(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario ID",
+		})
+	}
+
+	detail, err := h.scenarioRepo.GetDetail(c.Request().Context(), scenarioID)
+	if err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, detail)
+}
+
+// CreateScenario creates a what-if scenario
+// @Summary Create a scenario
+// @Description Create a named what-if scenario
+// @Tags Scenarios
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param scenario body models.CreateScenarioRequest true "Scenario to create"
+// @Success 201 {object} models.Scenario "Created scenario"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 409 {object} map[string]string "Scenario name already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios [post]
+func (h *ScenarioHandler) CreateScenario(c echo.Context) error {
+	var req models.CreateScenarioRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	scenario := &models.Scenario{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := h.scenarioRepo.Create(c.Request().Context(), scenario); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, scenario)
+}
+
+// DeleteScenario deletes a scenario with everything it overlays
+// @Summary Delete a scenario
+// @Description Delete a what-if scenario with its hypothetical loads and capacities
+// @Tags Scenarios
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Scenario ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid scenario ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Scenario not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios/{id} [delete]
+func (h *ScenarioHandler) DeleteScenario(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario ID",
+		})
+	}
+
+	if err := h.scenarioRepo.Delete(c.Request().Context(), scenarioID); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "scenario deleted",
+	})
+}
+
+// AddScenarioLoad adds a hypothetical load to a scenario
+// @Summary Add a scenario load
+// @Description Add a hypothetical load with its assignees to a scenario
+// @Tags Scenarios
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Scenario ID"
+// @Param load body models.AddScenarioLoadRequest true "Hypothetical load"
+// @Success 201 {object} models.ScenarioDetail "Updated scenario"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Scenario or person not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios/{id}/loads [post]
+func (h *ScenarioHandler) AddScenarioLoad(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario ID",
+		})
+	}
+
+	var req models.AddScenarioLoadRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid date format, use YYYY-MM-DD",
+		})
+	}
+
+	loads := make([]models.ScenarioLoad, 0, len(req.Assignees))
+	for _, a := range req.Assignees {
+		weight := a.Weight
+		if weight == 0 {
+			weight = 1.0
+		}
+		loads = append(loads, models.ScenarioLoad{
+			ScenarioID:  scenarioID,
+			Title:       req.Title,
+			Date:        date,
+			PersonEmail: a.Email,
+			Weight:      weight,
+		})
+	}
+
+	ctx := c.Request().Context()
+	if err := h.scenarioRepo.AddLoads(ctx, loads); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	detail, err := h.scenarioRepo.GetDetail(ctx, scenarioID)
+	if err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, detail)
+}
+
+// DeleteScenarioLoad removes a hypothetical load from a scenario
+// @Summary Delete a scenario load
+// @Description Remove one hypothetical load assignment from a scenario
+// @Tags Scenarios
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Scenario ID"
+// @Param load path int true "Scenario load ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Scenario load not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios/{id}/loads/{load} [delete]
+func (h *ScenarioHandler) DeleteScenarioLoad(c echo.Context) error {
+	scenarioID, loadID := 0, 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).Int("load", &loadID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario or load ID",
+		})
+	}
+
+	if err := h.scenarioRepo.DeleteLoad(c.Request().Context(), scenarioID, loadID); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "scenario load deleted",
+	})
+}
+
+// SetScenarioCapacity sets a hypothetical capacity in a scenario
+// @Summary Set a scenario capacity
+// @Description Set an entity's hypothetical capacity on a date, replacing the real one in the scenario
+// @Tags Scenarios
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Scenario ID"
+// @Param capacity body models.SetScenarioCapacityRequest true "Hypothetical capacity"
+// @Success 200 {object} models.ScenarioCapacity "Scenario capacity"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Scenario or entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios/{id}/capacity [put]
+func (h *ScenarioHandler) SetScenarioCapacity(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario ID",
+		})
+	}
+
+	var req models.SetScenarioCapacityRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid date format, use YYYY-MM-DD",
+		})
+	}
+
+	capacity := &models.ScenarioCapacity{
+		ScenarioID: scenarioID,
+		EntityID:   req.EntityID,
+		Date:       date,
+		Capacity:   req.Capacity,
+	}
+	if err := h.scenarioRepo.SetCapacity(c.Request().Context(), capacity); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, capacity)
+}
+
+// DeleteScenarioCapacity removes a hypothetical capacity from a scenario
+// @Summary Delete a scenario capacity
+// @Description Remove an entity's hypothetical capacity on a date, so the real one applies in the scenario
+// @Tags Scenarios
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Scenario ID"
+// @Param entity path string true "Entity ID"
+// @Param date path string true "Date in YYYY-MM-DD format"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid scenario ID or date"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/scenarios/{id}/capacity/{entity}/{date} [delete]
+func (h *ScenarioHandler) DeleteScenarioCapacity(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid scenario ID",
+		})
+	}
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid date format, use YYYY-MM-DD",
+		})
+	}
+
+	if err := h.scenarioRepo.DeleteCapacity(c.Request().Context(), scenarioID, c.Param("entity"), date); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "scenario capacity deleted",
+	})
+}
+
+// GetScenarioHeatmap renders an entity's heatmap under a scenario, shown
+// below the real one for comparison
+// @Summary Get scenario heatmap partial
+// @Description Returns the heatmap grid partial for an entity with the scenario applied
+// @Tags Scenarios
+// @Produce text/html
+// @Param id path int true "Scenario ID"
+// @Param entity path string true "Entity ID"
+// @Success 200 {string} string "HTML partial for the scenario heatmap grid"
+// @Failure 400 {string} string "Invalid scenario ID"
+// @Failure 404 {string} string "Scenario or entity not found, or the entity is private and not visible to the viewer"
+// @Failure 500 {string} string "Failed to load heatmap"
+// @Router /api/scenarios/{id}/heatmap/{entity} [get]
+func (h *ScenarioHandler) GetScenarioHeatmap(c echo.Context) error {
+	scenarioID := 0
+	if err := echo.PathParamsBinder(c).Int("id", &scenarioID).BindError(); err != nil {
+		return c.String(http.StatusBadRequest, "Invalid scenario ID")
+	}
+	entityID := c.Param("entity")
+	ctx := c.Request().Context()
+
+	scenario, err := h.scenarioRepo.GetByID(ctx, scenarioID)
+	if errors.Is(err, repository.ErrScenarioNotFound) {
+		return c.String(http.StatusNotFound, "Scenario not found")
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+
+	err = h.heatmapService.CheckVisible(ctx, middleware.GetUserEmail(c), entityID)
+	var heatmapData *models.HeatmapData
+	if err == nil {
+		heatmapData, err = h.heatmapService.GetScenarioHeatmapData(ctx, scenarioID, entityID)
+	}
+	if errors.Is(err, repository.ErrEntityNotFound) || errors.Is(err, service.ErrHeatmapPrivate) {
+		return c.String(http.StatusNotFound, "Entity not found")
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+	weekStart, err := h.heatmapService.WeekStart(ctx, middleware.GetUserEmail(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}
+
+	data := map[string]interface{}{
+		"Scenario":    scenario,
+		"HeatmapData": heatmapData,
+		"Months":      groupDaysByMonth(heatmapData.Days, weekStart),
+		"EntityID":    entityID,
+	}
+
+	var buf bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&buf, "scenario_heatmap", data); err != nil {
+		return err
+	}
+
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// ListShadowSources returns the connectors in shadow mode
+// @Summary List shadow sources
+// @Description List the sources in shadow mode, with the scenario their upserted loads are kept in and how many scenario loads, one per assignee and day, it holds
+// @Tags Scenarios
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.ShadowSource "Sources in shadow mode"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/shadow-sources [get]
+func (h *ScenarioHandler) ListShadowSources(c echo.Context) error {
+	sources, err := h.scenarioRepo.ListShadowSources(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, sources)
+}
+
+// CreateShadowSource puts a connector in shadow mode
+// @Summary Put a source in shadow mode
+// @Description Register a new connector's source in shadow mode: the loads it upserts, and its tombstones, go to a scenario named "Shadow: <source>" instead of to loads, so they show only on that scenario's heatmaps and never in real totals, reports or webhooks. Weight rules and custom field checks apply as for live upserts; unknown assignees are rejected rather than created. Upserts answer with shadow true and no load ID.
+// @Tags Scenarios
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source body models.CreateShadowSourceRequest true "Source to put in shadow mode"
+// @Success 201 {object} models.ShadowSource "Source in shadow mode"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 409 {object} map[string]string "Source already in shadow mode, or its scenario name is taken"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/shadow-sources [post]
+func (h *ScenarioHandler) CreateShadowSource(c echo.Context) error {
+	var req models.CreateShadowSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	shadow, err := h.scenarioRepo.CreateShadowSource(c.Request().Context(), req.Source)
+	if err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, shadow)
+}
+
+// DeleteShadowSource takes a connector out of shadow mode
+// @Summary Take a source live
+// @Description Take a source out of shadow mode, so its upserts are saved as loads again. Its scenario is kept to compare with; have the connector send its loads again to bring them live. Deleting the scenario also takes the source live.
+// @Tags Scenarios
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source path string true "Source"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Source not in shadow mode"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/shadow-sources/{source} [delete]
+func (h *ScenarioHandler) DeleteShadowSource(c echo.Context) error {
+	if err := h.scenarioRepo.DeleteShadowSource(c.Request().Context(), c.Param("source")); err != nil {
+		return c.JSON(scenarioErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success": "source is live",
+	})
+}
+
+// scenarioErrorStatus maps scenario errors to HTTP statuses
+func scenarioErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrScenarioNotFound),
+		errors.Is(err, repository.ErrScenarioLoadNotFound),
+		errors.Is(err, repository.ErrEntityNotFound),
+		errors.Is(err, repository.ErrShadowSourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrScenarioExists), errors.Is(err, repository.ErrShadowSourceExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gti/heatmap-internal/internal/cache"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// SettingsHandler serves the admins' settings page, where they tune heatmap
+// colors, default capacity, alert cooldown and the webhook secret without a
+// redeploy
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+	templates       Templates
+	renderCache     *cache.RenderCache
+}
+
+func NewSettingsHandler(settingsService *service.SettingsService, templates Templates, renderCache *cache.RenderCache) *SettingsHandler {
+	return &SettingsHandler{
+		settingsService: settingsService,
+		templates:       templates,
+		renderCache:     renderCache,
+	}
+}
+
+// thresholdColors are the colors days turn at each color threshold, in order
+var thresholdColors = [...]struct{ name, color string }{
+	{"Lime", "#a3e635"},
+	{"Amber", "#fbbf24"},
+	{"Orange", "#f97316"},
+	{"Red", "#dc2626"},
+	{"Blood red", "#8B0000"},
+}
+
+// SettingsPage renders the settings form for admins
+// @Summary Get settings
+// @Description Renders the settings page, or, when the Accept header asks for application/json, returns the settings as JSON: heatmap color thresholds, the default capacity of new persons and groups, the overload alert cooldown, and whether a webhook secret is set. The secret itself is never returned. Only admins (ADMIN_EMAILS) may.
+// @Tags Settings
+// @Produce text/html
+// @Produce json
+// @Success 200 {object} models.Settings "Settings page, or the settings as JSON"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Router /settings [get]
+func (h *SettingsHandler) SettingsPage(c echo.Context) error {
+	resp := negotiate(c, false)
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		if resp.JSON() {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+		}
+		return c.Redirect(http.StatusFound, middleware.AppPath(c, "/login"))
+	}
+	if !h.settingsService.IsAdmin(userEmail) {
+		if resp.JSON() {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": service.ErrNotSettingsAdmin.Error()})
+		}
+		return c.String(http.StatusForbidden, "Only admins may change settings")
+	}
+
+	settings := h.settingsService.Current()
+	type threshold struct {
+		Name  string
+		Color string
+		Ratio float64
+	}
+	thresholds := make([]threshold, 0, len(settings.ColorThresholds))
+	for i, ratio := range settings.ColorThresholds {
+		thresholds = append(thresholds, threshold{Name: thresholdColors[i].name, Color: thresholdColors[i].color, Ratio: ratio})
+	}
+	data := map[string]interface{}{
+		"Settings":   settings,
+		"Thresholds": thresholds,
+		"UserEmail":  userEmail,
+	}
+	return resp.Render(http.StatusOK, h.templates, "settings", data, settings)
+}
+
+// UpdateSettings changes settings
+// @Summary Update settings
+// @Description Change settings, taking effect at once on this server and within 30 seconds on others. Omitted fields are kept. color_thresholds are the five ascending load-to-capacity ratios above which a heatmap day turns lime, amber, orange, red and blood red (default 0.2, 0.4, 0.6, 0.8, 1.0). default_capacity is the daily capacity of persons and groups created without one (default 5.0). alert_cooldown_minutes is how long overload alerts about the same person or group and day are not sent again, up to a week (default 0, no cooldown). webhook_secret, when set, signs every webhook delivery in the X-Heatmap-Signature header as "sha256=" and the hex HMAC-SHA256 of the body; an empty string removes it. Every change is recorded in the event log as settings.updated, without the secret. Only admins (ADMIN_EMAILS) may.
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Param settings body models.UpdateSettingsRequest true "Settings to change"
+// @Success 200 {object} models.Settings "Settings after the change"
+// @Failure 400 {object} map[string]string "Invalid settings"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/settings [put]
+func (h *SettingsHandler) UpdateSettings(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.UpdateSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	settings, err := h.settingsService.Update(c.Request().Context(), userEmail, &req)
+	if err != nil {
+		return c.JSON(settingsErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	// Cached heatmaps carry the old colors
+	if req.ColorThresholds != nil {
+		h.renderCache.InvalidateAll()
+	}
+	return c.JSON(http.StatusOK, settings)
+}
+
+// settingsErrorStatus maps settings errors to HTTP statuses
+func settingsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidSettings):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotSettingsAdmin):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/middleware"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// StatusHandler serves the public status page and the admins' incident
+// notes on it
+type StatusHandler struct {
+	statusService *service.StatusService
+	templates     Templates
+	validate      *validator.Validate
+}
+
+func NewStatusHandler(statusService *service.StatusService, templates Templates) *StatusHandler {
+	return &StatusHandler{
+		statusService: statusService,
+		templates:     templates,
+		validate:      validator.New(),
+	}
+}
+
+// StatusPage renders whether the service is up, for internal users checking
+// whether a problem is theirs alone. Admins also get forms to post, resolve
+// and delete incident notes.
+func (h *StatusHandler) StatusPage(c echo.Context) error {
+	report := h.statusService.GetReport(c.Request().Context(), time.Now())
+
+	status := http.StatusOK
+	if !report.DatabaseOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	data := map[string]interface{}{
+		"Report":  report,
+		"Uptime":  formatUptime(report.Uptime),
+		"IsAdmin": h.statusService.IsAdmin(middleware.GetUserEmail(c)),
+	}
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(status)
+	return h.templates.ExecuteTemplate(c.Response().Writer, "status", data)
+}
+
+// formatUptime shows an uptime in days, hours and minutes
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// AddIncident posts an incident note to the status page
+// @Summary Add incident note
+// @Description Post a note about an incident, open until resolved, to the public /status page. Only admins (ADMIN_EMAILS) may.
+// @Tags Status
+// @Accept json
+// @Produce json
+// @Param incident body models.CreateIncidentNoteRequest true "Note text, at most 1000 characters"
+// @Success 201 {object} models.IncidentNote "Created incident note"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/status/incidents [post]
+func (h *StatusHandler) AddIncident(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	var req models.CreateIncidentNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note, err := h.statusService.AddIncident(c.Request().Context(), userEmail, req.Body)
+	if err != nil {
+		return c.JSON(incidentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, note)
+}
+
+// UpdateIncident edits, resolves or reopens an incident note
+// @Summary Update incident note
+// @Description Change an incident note's text, or resolve or reopen it. Omitted fields are kept. Only admins (ADMIN_EMAILS) may.
+// @Tags Status
+// @Accept json
+// @Produce json
+// @Param id path int true "Incident note ID"
+// @Param incident body models.UpdateIncidentNoteRequest true "Fields to change"
+// @Success 200 {object} models.IncidentNote "Updated incident note"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "Incident note not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/status/incidents/{id} [put]
+func (h *StatusHandler) UpdateIncident(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	id := 0
+	if err := echo.PathParamsBinder(c).Int("id", &id).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid incident note ID"})
+	}
+
+	var req models.UpdateIncidentNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note, err := h.statusService.UpdateIncident(c.Request().Context(), userEmail, id, &req)
+	if err != nil {
+		return c.JSON(incidentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, note)
+}
+
+// DeleteIncident removes an incident note
+// @Summary Delete incident note
+// @Description Remove an incident note from the status page. Only admins (ADMIN_EMAILS) may.
+// @Tags Status
+// @Produce json
+// @Param id path int true "Incident note ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid incident note ID"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "Incident note not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/status/incidents/{id} [delete]
+func (h *StatusHandler) DeleteIncident(c echo.Context) error {
+	userEmail := middleware.GetUserEmail(c)
+	if userEmail == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authenticated"})
+	}
+
+	id := 0
+	if err := echo.PathParamsBinder(c).Int("id", &id).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid incident note ID"})
+	}
+
+	if err := h.statusService.DeleteIncident(c.Request().Context(), userEmail, id); err != nil {
+		return c.JSON(incidentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": "incident note deleted"})
+}
+
+// incidentErrorStatus maps incident note errors to HTTP statuses
+func incidentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrEmptyNote):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrIncidentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
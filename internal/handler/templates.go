@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Templates executes the app's named HTML templates. *template.Template
+// implements it; DevTemplates reloads them from disk on every use.
+type Templates interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// TemplateError is a template that failed to parse or execute, with the
+// template's name; the underlying error gives the file and line.
+type TemplateError struct {
+	Name string
+	Err  error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %q failed: %v", e.Name, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// DevTemplates reparses the templates for every execution, so edits show on
+// the next reload during development. Output is buffered: a template that
+// fails writes nothing and returns a *TemplateError, which
+// TemplateErrorHandler renders, rather than leaving a half-written 200.
+type DevTemplates struct {
+	load func() (*template.Template, error)
+
+	// mu serializes reloads, which read every template file
+	mu sync.Mutex
+}
+
+// NewDevTemplates creates templates that call load for every execution
+func NewDevTemplates(load func() (*template.Template, error)) *DevTemplates {
+	return &DevTemplates{load: load}
+}
+
+func (t *DevTemplates) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	t.mu.Lock()
+	templates, err := t.load()
+	t.mu.Unlock()
+	if err != nil {
+		return &TemplateError{Name: name, Err: err}
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return &TemplateError{Name: name, Err: err}
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// TemplateErrorHandler renders template errors as a page naming the template
+// and the failing line, passing other errors on to next. HTMX only swaps in
+// successful responses, so HTMX requests get the page with 200. It is meant
+// for development: the page shows template internals.
+func TemplateErrorHandler(next echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		var templateErr *TemplateError
+		if !errors.As(err, &templateErr) || c.Response().Committed {
+			next(err, c)
+			return
+		}
+
+		status := http.StatusInternalServerError
+		if c.Request().Header.Get(htmxRequestHeader) == htmxRequestValue {
+			status = http.StatusOK
+		}
+		page := `<div class="m-4 p-4 border border-red-500 bg-red-50 text-red-700">` +
+			`<h1 class="font-bold">Template ` + template.HTMLEscapeString(templateErr.Name) + ` failed</h1>` +
+			`<pre class="mt-2 whitespace-pre-wrap text-sm">` + template.HTMLEscapeString(templateErr.Err.Error()) + `</pre></div>`
+		if err := c.HTML(status, page); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
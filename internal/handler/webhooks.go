@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler manages the webhook endpoints events are delivered to
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+	validate       *validator.Validate
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validate:       validator.New(),
+	}
+}
+
+// ListWebhooks returns every webhook endpoint
+// @Summary List webhook endpoints
+// @Description List every webhook endpoint with the events it subscribes to and whether it is enabled. WEBHOOK_DESTINATION_URL, if set, is not listed; it receives every event.
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.WebhookEndpoint "Webhook endpoints"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c echo.Context) error {
+	endpoints, err := h.webhookService.ListEndpoints(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, endpoints)
+}
+
+// CreateWebhook adds a webhook endpoint
+// @Summary Add a webhook endpoint
+// @Description Add a URL to POST the subscribed events to: overload_alert, group_overload_alert, load_created, load_deleted, capacity_changed, person_offboarded, load_unacknowledged, schedule_conflict or anomaly. The endpoint is enabled unless enabled is false.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param webhook body models.CreateWebhookEndpointRequest true "Webhook endpoint to add"
+// @Success 201 {object} models.WebhookEndpoint "Created webhook endpoint"
+// @Failure 400 {object} map[string]string "Invalid request body, URL or event"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var req models.CreateWebhookEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	endpoint, err := h.webhookService.CreateEndpoint(c.Request().Context(), &req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, endpoint)
+}
+
+// GetWebhook returns one webhook endpoint
+// @Summary Get a webhook endpoint
+// @Description Get a webhook endpoint with the events it subscribes to and whether it is enabled
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Success 200 {object} models.WebhookEndpoint "Webhook endpoint"
+// @Failure 400 {object} map[string]string "Invalid webhook endpoint ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Webhook endpoint not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	id := 0
+	if err := echo.PathParamsBinder(c).Int("id", &id).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook endpoint ID"})
+	}
+
+	endpoint, err := h.webhookService.GetEndpoint(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, endpoint)
+}
+
+// UpdateWebhook changes a webhook endpoint
+// @Summary Update a webhook endpoint
+// @Description Change a webhook endpoint's URL, description or events, or enable or disable it. Omitted fields are kept; events, when given, replaces the subscribed events.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Param webhook body models.UpdateWebhookEndpointRequest true "Fields to change"
+// @Success 200 {object} models.WebhookEndpoint "Updated webhook endpoint"
+// @Failure 400 {object} map[string]string "Invalid webhook endpoint ID, request body, URL or event"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Webhook endpoint not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id := 0
+	if err := echo.PathParamsBinder(c).Int("id", &id).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook endpoint ID"})
+	}
+
+	var req models.UpdateWebhookEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Request().Context(), id, &req)
+	if err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteWebhook removes a webhook endpoint
+// @Summary Delete a webhook endpoint
+// @Description Stop delivering events to a webhook endpoint and remove it. Its past deliveries stay in the integration health report.
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid webhook endpoint ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not allowed for group-scoped API keys"
+// @Failure 404 {object} map[string]string "Webhook endpoint not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Server busy, retry later"
+// @Router /api/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id := 0
+	if err := echo.PathParamsBinder(c).Int("id", &id).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook endpoint ID"})
+	}
+
+	if err := h.webhookService.DeleteEndpoint(c.Request().Context(), id); err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": "webhook endpoint deleted"})
+}
+
+// webhookErrorStatus maps webhook endpoint errors to HTTP status codes
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
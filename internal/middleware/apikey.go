@@ -2,20 +2,41 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/gti/heatmap-internal/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
-// APIKeyAuth returns middleware that validates the x-api-key header
-func APIKeyAuth(apiKey string) echo.MiddlewareFunc {
+// APIKeyNameKey is the context key APIKeyAuth stores the name of a request's
+// API key under: MasterKeyName for API_KEY, or "groups:" and its groups for a
+// group-scoped key. Keys themselves are never stored.
+const APIKeyNameKey = "api_key_name"
+
+// MasterKeyName names API_KEY, and any key in development mode
+const MasterKeyName = "api_key"
+
+// APIKeyAuth returns middleware that validates the x-api-key header. Besides
+// apiKey, which may write anything, groupKeys maps keys restricted to some
+// groups to those groups; their requests carry the groups for the services
+// to enforce.
+func APIKeyAuth(apiKey string, groupKeys map[string][]string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			key := c.Request().Header.Get("x-api-key")
+			if groups, ok := groupKeys[key]; ok && key != "" {
+				ctx := service.WithKeyScope(c.Request().Context(), groups)
+				c.SetRequest(c.Request().WithContext(ctx))
+				c.Set(APIKeyNameKey, "groups:"+strings.Join(groups, ","))
+				return next(c)
+			}
+
 			// Skip if no API key configured (development mode)
 			if apiKey == "" {
+				c.Set(APIKeyNameKey, MasterKeyName)
 				return next(c)
 			}
 
-			key := c.Request().Header.Get("x-api-key")
 			if key == "" {
 				return c.JSON(http.StatusUnauthorized, map[string]string{
 					"error": "missing x-api-key header",
@@ -28,6 +49,22 @@ func APIKeyAuth(apiKey string) echo.MiddlewareFunc {
 				})
 			}
 
+			c.Set(APIKeyNameKey, MasterKeyName)
+			return next(c)
+		}
+	}
+}
+
+// UnscopedAPIKey returns middleware that refuses group-scoped API keys, for
+// routes that do not check what a key's groups may write
+func UnscopedAPIKey() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, scoped := service.KeyScope(c.Request().Context()); scoped {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "not allowed for group-scoped API keys",
+				})
+			}
 			return next(c)
 		}
 	}
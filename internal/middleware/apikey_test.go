@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithAPIKey(apiKey string, groupKeys map[string][]string, key string, extra ...echo.MiddlewareFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Use(APIKeyAuth(apiKey, groupKeys))
+	e.GET("/", func(c echo.Context) error {
+		groups, scoped := service.KeyScope(c.Request().Context())
+		if !scoped {
+			return c.String(http.StatusOK, "all")
+		}
+		return c.String(http.StatusOK, strings.Join(groups, ","))
+	}, extra...)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if key != "" {
+		req.Header.Set("x-api-key", key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyAuthScopesGroupKeys(t *testing.T) {
+	groupKeys := map[string][]string{"team-key": {"platform", "infra"}}
+
+	rec := serveWithAPIKey("full-key", groupKeys, "full-key")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "all", rec.Body.String())
+
+	rec = serveWithAPIKey("full-key", groupKeys, "team-key")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "platform,infra", rec.Body.String())
+
+	rec = serveWithAPIKey("full-key", groupKeys, "other-key")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serveWithAPIKey("full-key", groupKeys, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAPIKeyAuthDevelopmentMode(t *testing.T) {
+	groupKeys := map[string][]string{"team-key": {"platform"}}
+
+	rec := serveWithAPIKey("", groupKeys, "")
+	assert.Equal(t, "all", rec.Body.String(), "no API_KEY lets requests without one through")
+
+	rec = serveWithAPIKey("", groupKeys, "team-key")
+	assert.Equal(t, "platform", rec.Body.String(), "group keys stay scoped")
+}
+
+func TestUnscopedAPIKey(t *testing.T) {
+	groupKeys := map[string][]string{"team-key": {"platform"}}
+
+	rec := serveWithAPIKey("full-key", groupKeys, "full-key", UnscopedAPIKey())
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveWithAPIKey("full-key", groupKeys, "team-key", UnscopedAPIKey())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
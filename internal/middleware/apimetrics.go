@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UnauthenticatedKeyName is what requests without a valid API key are
+// counted under
+const UnauthenticatedKeyName = "unauthenticated"
+
+// APIKeyRecorder counts a request made with the API key named key.
+// *service.APIMetricsService implements it.
+type APIKeyRecorder interface {
+	Record(key string, status int, requestBytes, responseBytes int64)
+}
+
+// APIMetrics returns middleware that counts each request, its status and
+// payload sizes under the name APIKeyAuth, further in, gives its API key,
+// or UnauthenticatedKeyName when it refused the key. A nil recorder counts
+// nothing.
+func APIMetrics(recorder APIKeyRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if recorder == nil {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			err := next(c)
+
+			// Returned errors are written later, by the error handler
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+
+			key, _ := c.Get(APIKeyNameKey).(string)
+			if key == "" {
+				key = UnauthenticatedKeyName
+			}
+			recorder.Record(key, status, c.Request().ContentLength, c.Response().Size)
+			return err
+		}
+	}
+}
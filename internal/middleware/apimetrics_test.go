@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorded struct {
+	key           string
+	status        int
+	requestBytes  int64
+	responseBytes int64
+}
+
+type fakeRecorder struct {
+	requests []recorded
+}
+
+func (f *fakeRecorder) Record(key string, status int, requestBytes, responseBytes int64) {
+	f.requests = append(f.requests, recorded{key, status, requestBytes, responseBytes})
+}
+
+func TestAPIMetricsCountsPerKey(t *testing.T) {
+	recorder := &fakeRecorder{}
+	e := echo.New()
+	e.Use(APIMetrics(recorder), APIKeyAuth("full-key", map[string][]string{"team-key": {"platform", "infra"}}))
+	e.POST("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "done")
+	})
+	e.POST("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "malformed")
+	})
+
+	call := func(path, key, body string) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("x-api-key", key)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	call("/", "full-key", `{"a":1}`)
+	call("/", "team-key", "")
+	call("/", "wrong-key", "")
+	call("/fail", "full-key", "{")
+
+	if assert.Len(t, recorder.requests, 4) {
+		assert.Equal(t, recorded{MasterKeyName, http.StatusOK, 7, 4}, recorder.requests[0])
+		assert.Equal(t, "groups:platform,infra", recorder.requests[1].key)
+		assert.Equal(t, UnauthenticatedKeyName, recorder.requests[2].key)
+		assert.Equal(t, http.StatusUnauthorized, recorder.requests[2].status)
+		assert.Equal(t, http.StatusBadRequest, recorder.requests[3].status, "returned errors count by their status")
+	}
+}
+
+func TestAPIMetricsNilRecorder(t *testing.T) {
+	e := echo.New()
+	e.Use(APIMetrics(nil))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
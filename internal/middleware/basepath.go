@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// BasePathKey is the context key holding the path prefix routes are served
+// under
+const BasePathKey = "base_path"
+
+// BasePath returns middleware that records the path prefix, such as
+// /heatmap, that every route is served under behind a reverse proxy, so
+// redirects, Location headers and cookies can carry it.
+func BasePath(prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(BasePathKey, prefix)
+			return next(c)
+		}
+	}
+}
+
+// AppPath returns path, an absolute path within the app such as /login,
+// under the request's base path
+func AppPath(c echo.Context, path string) string {
+	prefix, _ := c.Get(BasePathKey).(string)
+	return prefix + path
+}
+
+// cookiePath scopes cookies to the base path, or to the whole host without
+// one
+func cookiePath(c echo.Context) string {
+	if prefix, _ := c.Get(BasePathKey).(string); prefix != "" {
+		return prefix
+	}
+	return "/"
+}
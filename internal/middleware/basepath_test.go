@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasePath(t *testing.T) {
+	e := echo.New()
+	e.Use(BasePath("/heatmap"))
+	e.GET("/heatmap/login", func(c echo.Context) error {
+		SetSessionCookie(c, "token")
+		return c.Redirect(http.StatusFound, AppPath(c, "/"))
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/heatmap/login", nil))
+	assert.Equal(t, "/heatmap/", rec.Header().Get(echo.HeaderLocation))
+	cookies := rec.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "/heatmap", cookies[0].Path, "cookies are scoped to the base path")
+	}
+}
+
+func TestBasePathUnset(t *testing.T) {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Equal(t, "/login", AppPath(c, "/login"))
+	assert.Equal(t, "/", cookiePath(c))
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Cache-Control values for the two kinds of cacheable responses.
+const (
+	// CacheStatic lets browsers reuse static assets for an hour, then
+	// revalidate with their ETag. Assets are not fingerprinted, so the
+	// lifetime is kept short.
+	CacheStatic = "public, max-age=3600"
+
+	// CachePartial makes browsers revalidate HTML partials on every load;
+	// with ETag the answer is usually an empty 304.
+	CachePartial = "private, no-cache"
+)
+
+// CacheControl returns middleware that sets the Cache-Control header on
+// every response.
+func CacheControl(value string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderCacheControl, value)
+			return next(c)
+		}
+	}
+}
+
+// ETag returns middleware that buffers successful GET responses, tags them
+// with a weak ETag derived from the body, and answers 304 Not Modified when
+// the client's If-None-Match already has that version.
+//
+// The tag is weak because compression middleware may change the encoded
+// bytes while the content stays the same.
+func ETag() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet {
+				return next(c)
+			}
+
+			res := c.Response()
+			original := res.Writer
+			buf := &bufferedWriter{ResponseWriter: original}
+			res.Writer = buf
+			defer func() { res.Writer = original }()
+
+			if err := next(c); err != nil {
+				// Let the error handler write to the real writer
+				res.Writer = original
+				if buf.body.Len() > 0 || buf.status != 0 {
+					buf.flushTo(original)
+				}
+				return err
+			}
+
+			status := buf.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status != http.StatusOK {
+				buf.flushTo(original)
+				return nil
+			}
+
+			sum := sha256.Sum256(buf.body.Bytes())
+			etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+			original.Header().Set("ETag", etag)
+
+			if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+				original.Header().Del(echo.HeaderContentType)
+				original.Header().Del(echo.HeaderContentLength)
+				original.WriteHeader(http.StatusNotModified)
+				res.Status = http.StatusNotModified
+				return nil
+			}
+
+			buf.flushTo(original)
+			return nil
+		}
+	}
+}
+
+// NotModified sets the ETag and Last-Modified headers for a response whose
+// version the handler knows before building it, and reports whether the
+// request's conditional headers show the client already has that version, in
+// which case the handler can answer 304 without doing the work.
+//
+// If-None-Match takes precedence over If-Modified-Since, as RFC 9110 requires.
+func NotModified(c echo.Context, etag string, lastModified time.Time) bool {
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+
+	req := c.Request()
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	since, err := http.ParseTime(req.Header.Get(echo.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have whole-second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// comparison is used, as RFC 9110 requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds a handler's response until the ETag is known.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status instead of sending it.
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers the body.
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// flushTo sends the buffered status and body to the underlying writer.
+func (w *bufferedWriter) flushTo(dst http.ResponseWriter) {
+	if dst.Header().Get(echo.HeaderContentType) == "" && w.body.Len() > 0 {
+		dst.Header().Set(echo.HeaderContentType, http.DetectContentType(w.body.Bytes()))
+	}
+
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	dst.WriteHeader(status)
+	_, _ = dst.Write(w.body.Bytes())
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveCached(handler echo.HandlerFunc, ifNoneMatch string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.GET("/", handler, CacheControl(CachePartial), ETag())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// renderPartial writes straight to the response writer, like the template
+// handlers do.
+func renderPartial(body string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, err := c.Response().Writer.Write([]byte(body))
+		return err
+	}
+}
+
+func TestETagAndCacheControl(t *testing.T) {
+	rec := serveCached(renderPartial("<div>heatmap</div>"), "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<div>heatmap</div>", rec.Body.String())
+	assert.Equal(t, CachePartial, rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, etag)
+
+	// Same content, same tag
+	again := serveCached(renderPartial("<div>heatmap</div>"), "")
+	assert.Equal(t, etag, again.Header().Get("ETag"))
+
+	// Different content, different tag
+	changed := serveCached(renderPartial("<div>updated</div>"), "")
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
+func TestETagNotModified(t *testing.T) {
+	etag := serveCached(renderPartial("<div>heatmap</div>"), "").Header().Get("ETag")
+
+	for _, header := range []string{etag, `"other", ` + etag, etag[2:], "*"} {
+		rec := serveCached(renderPartial("<div>heatmap</div>"), header)
+		assert.Equal(t, http.StatusNotModified, rec.Code, header)
+		assert.Empty(t, rec.Body.String(), header)
+		assert.Equal(t, etag, rec.Header().Get("ETag"), header)
+	}
+
+	rec := serveCached(renderPartial("<div>updated</div>"), etag)
+	assert.Equal(t, http.StatusOK, rec.Code, "stale tag should get the new body")
+	assert.Equal(t, "<div>updated</div>", rec.Body.String())
+}
+
+func TestETagSkipsErrors(t *testing.T) {
+	rec := serveCached(func(c echo.Context) error {
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	}, "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "Failed to load heatmap", rec.Body.String())
+	assert.Empty(t, rec.Header().Get("ETag"))
+
+	rec = serveCached(func(c echo.Context) error {
+		return errors.New("boom")
+	}, "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `W/"0123456789abcdef"`
+	lastModified := time.Date(2025, 3, 10, 12, 30, 15, 500_000_000, time.UTC)
+	rendered := 0
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.GET("/", func(c echo.Context) error {
+			if NotModified(c, etag, lastModified) {
+				return c.NoContent(http.StatusNotModified)
+			}
+			rendered++
+			return c.HTML(http.StatusOK, "<div>heatmap</div>")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, "Mon, 10 Mar 2025 12:30:15 GMT", rec.Header().Get("Last-Modified"))
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"matching etag", "If-None-Match", etag, http.StatusNotModified},
+		{"strong form of etag", "If-None-Match", `"0123456789abcdef"`, http.StatusNotModified},
+		{"stale etag", "If-None-Match", `W/"fedcba9876543210"`, http.StatusOK},
+		{"same second", "If-Modified-Since", "Mon, 10 Mar 2025 12:30:15 GMT", http.StatusNotModified},
+		{"later", "If-Modified-Since", "Tue, 11 Mar 2025 00:00:00 GMT", http.StatusNotModified},
+		{"earlier", "If-Modified-Since", "Mon, 10 Mar 2025 12:30:14 GMT", http.StatusOK},
+		{"unparseable date", "If-Modified-Since", "yesterday", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered = 0
+			rec := serve(tt.header, tt.value)
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Zero(t, rendered, "heatmap should not be rendered")
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+
+	// If-None-Match wins over If-Modified-Since
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `W/"fedcba9876543210"`)
+	req.Header.Set("If-Modified-Since", "Tue, 11 Mar 2025 00:00:00 GMT")
+	c := e.NewContext(req, httptest.NewRecorder())
+	assert.False(t, NotModified(c, etag, lastModified), "stale etag should not be overridden by a later date")
+}
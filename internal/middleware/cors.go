@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// CORSPolicy controls which other sites may call the server from a browser.
+type CORSPolicy struct {
+	AllowOrigins     []string // "*" allows any origin
+	AllowMethods     []string
+	AllowCredentials bool // let allowed origins send the session cookie
+}
+
+// CORS returns middleware that answers cross-origin requests according to
+// policy. With no allowed origins it adds no CORS headers, so browsers only
+// let same-origin pages read responses.
+func CORS(policy CORSPolicy) echo.MiddlewareFunc {
+	if len(policy.AllowOrigins) == 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+		AllowOrigins:     policy.AllowOrigins,
+		AllowMethods:     policy.AllowMethods,
+		AllowCredentials: policy.AllowCredentials,
+	})
+}
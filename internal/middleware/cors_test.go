@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithCORS(policy CORSPolicy, req *http.Request) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Use(CORS(policy))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func crossOrigin(method, origin string) *http.Request {
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set(echo.HeaderOrigin, origin)
+	if method == http.MethodOptions {
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	}
+	return req
+}
+
+func TestCORSAllowsListedOrigins(t *testing.T) {
+	policy := CORSPolicy{
+		AllowOrigins:     []string{"https://heatmap.example.com"},
+		AllowMethods:     []string{http.MethodGet},
+		AllowCredentials: true,
+	}
+
+	rec := serveWithCORS(policy, crossOrigin(http.MethodGet, "https://heatmap.example.com"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://heatmap.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+
+	rec = serveWithCORS(policy, crossOrigin(http.MethodOptions, "https://heatmap.example.com"))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, http.MethodGet, rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+
+	rec = serveWithCORS(policy, crossOrigin(http.MethodGet, "https://evil.example.com"))
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin), "unlisted origins are not allowed")
+}
+
+func TestCORSWildcard(t *testing.T) {
+	rec := serveWithCORS(CORSPolicy{AllowOrigins: []string{"*"}}, crossOrigin(http.MethodGet, "https://anywhere.example.com"))
+
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+}
+
+func TestCORSSameOriginOnly(t *testing.T) {
+	rec := serveWithCORS(CORSPolicy{}, crossOrigin(http.MethodGet, "https://heatmap.example.com"))
+
+	assert.Equal(t, http.StatusOK, rec.Code, "requests are still served")
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin), "but browsers may not read them cross-origin")
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestDeadline returns middleware that bounds each request's context by
+// timeout, so database queries and outbound calls made with the request
+// context are cancelled instead of holding the connection open indefinitely.
+//
+// Handlers are not interrupted; they see ctx.Done() and fail through their
+// normal error paths. If a handler returns an error after the deadline has
+// passed and nothing has been written yet, the client gets a 504.
+// A timeout of zero or less disables the middleware.
+func RequestDeadline(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Response().Committed {
+				return c.JSON(http.StatusGatewayTimeout, map[string]string{
+					"error": "request timed out",
+				})
+			}
+			return err
+		}
+	}
+}
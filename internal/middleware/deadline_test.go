@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithDeadline(timeout time.Duration, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Use(RequestDeadline(timeout))
+	e.GET("/", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestRequestDeadlineSetsContextDeadline(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	rec := serveWithDeadline(time.Minute, func(c echo.Context) error {
+		deadline, ok = c.Request().Context().Deadline()
+		return c.String(http.StatusOK, "ok")
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, ok, "request context should carry a deadline")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
+
+func TestRequestDeadlineReturnsGatewayTimeout(t *testing.T) {
+	rec := serveWithDeadline(20*time.Millisecond, func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.JSONEq(t, `{"error":"request timed out"}`, rec.Body.String())
+}
+
+func TestRequestDeadlineKeepsHandlerResponse(t *testing.T) {
+	// Handlers that already answered (e.g. a 500 from a cancelled query)
+	// keep their response
+	rec := serveWithDeadline(20*time.Millisecond, func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return c.String(http.StatusInternalServerError, "Failed to load heatmap")
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "Failed to load heatmap", rec.Body.String())
+}
+
+func TestRequestDeadlineDisabled(t *testing.T) {
+	rec := serveWithDeadline(0, func(c echo.Context) error {
+		_, ok := c.Request().Context().Deadline()
+		assert.False(t, ok, "zero timeout should not add a deadline")
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
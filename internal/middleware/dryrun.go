@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// DryRun returns middleware for writes sent with ?dry_run=true. On routes,
+// each "METHOD /path" matched against the end of the route path, the request
+// reaches its handler marked with service.WithDryRun, which checks the write
+// and reports what it would do without making it. Every other write refuses
+// dry_run with 400 rather than make the change the caller only meant to check.
+func DryRun(routes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			s := c.QueryParam("dry_run")
+			if s == "" {
+				return next(c)
+			}
+			dry, err := strconv.ParseBool(s)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "dry_run must be true or false",
+				})
+			}
+			method := c.Request().Method
+			if !dry || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+				return next(c)
+			}
+
+			for _, route := range routes {
+				routeMethod, path, _ := strings.Cut(route, " ")
+				if routeMethod == method && strings.HasSuffix(c.Path(), path) {
+					ctx := service.WithDryRun(c.Request().Context())
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "dry_run is not supported for this request",
+			})
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	e := echo.New()
+	g := e.Group("/api/v1", DryRun("POST /loads/upsert", "DELETE /entities/:id"))
+	// Handlers answer 202 for dry runs, so the test sees the context mark
+	handle := func(c echo.Context) error {
+		if service.DryRun(c.Request().Context()) != nil {
+			return c.NoContent(http.StatusAccepted)
+		}
+		return c.NoContent(http.StatusOK)
+	}
+	g.GET("/entities/:id", handle)
+	g.POST("/loads/upsert", handle)
+	g.POST("/loads/from-issue", handle)
+	g.DELETE("/entities/:id", handle)
+	g.DELETE("/entities/:id/blackouts/:blackout", handle)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/loads/upsert", http.StatusOK},
+		{http.MethodPost, "/api/v1/loads/upsert?dry_run=false", http.StatusOK},
+		{http.MethodPost, "/api/v1/loads/upsert?dry_run=true", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/loads/upsert?dry_run=1", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/loads/upsert?dry_run=maybe", http.StatusBadRequest},
+		{http.MethodDelete, "/api/v1/entities/a@example.com?dry_run=true", http.StatusAccepted},
+		{http.MethodGet, "/api/v1/entities/a@example.com?dry_run=true", http.StatusOK},
+		{http.MethodPost, "/api/v1/loads/from-issue?dry_run=true", http.StatusBadRequest},
+		{http.MethodDelete, "/api/v1/entities/a@example.com/blackouts/1?dry_run=true", http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.want, rec.Code, "%s %s", tc.method, tc.path)
+	}
+}
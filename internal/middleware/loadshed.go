@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PoolStats is the part of *pgxpool.Stat the load shedder reads.
+type PoolStats interface {
+	AcquireCount() int64
+	EmptyAcquireWaitTime() time.Duration
+	AcquiredConns() int32
+	MaxConns() int32
+}
+
+// LoadShedder watches how long requests wait for a database connection and
+// reports the pool as saturated when the average wait over the last sampling
+// interval exceeds a threshold.
+//
+// A nil *LoadShedder never sheds.
+type LoadShedder struct {
+	stats      func() PoolStats
+	threshold  time.Duration
+	retryAfter time.Duration
+
+	mu        sync.Mutex
+	lastCount int64
+	lastWait  time.Duration
+	avgWait   time.Duration
+	shedding  bool
+}
+
+// NewLoadShedder creates a shedder that reads pool statistics from stats.
+// Requests are shed while the average connection wait exceeds threshold,
+// and told to retry after retryAfter. Returns nil (shedding disabled) when
+// threshold is not positive.
+func NewLoadShedder(stats func() PoolStats, threshold, retryAfter time.Duration) *LoadShedder {
+	if threshold <= 0 {
+		return nil
+	}
+
+	s := &LoadShedder{
+		stats:      stats,
+		threshold:  threshold,
+		retryAfter: retryAfter,
+	}
+	st := stats()
+	s.lastCount, s.lastWait = st.AcquireCount(), st.EmptyAcquireWaitTime()
+	return s
+}
+
+// Run samples the pool every interval until ctx is cancelled.
+func (s *LoadShedder) Run(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sample()
+		}
+	}
+}
+
+// Sample updates the saturation state from the pool statistics accumulated
+// since the previous sample.
+func (s *LoadShedder) Sample() {
+	if s == nil {
+		return
+	}
+
+	st := s.stats()
+	count, wait := st.AcquireCount(), st.EmptyAcquireWaitTime()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acquires := count - s.lastCount
+	waited := wait - s.lastWait
+	s.lastCount, s.lastWait = count, wait
+
+	was := s.shedding
+	if acquires == 0 {
+		// Nothing was acquired: the pool is either idle or so stuck that no
+		// waiter got through. Only the latter keeps shedding on.
+		s.avgWait = 0
+		s.shedding = s.shedding && st.AcquiredConns() >= st.MaxConns()
+	} else {
+		s.avgWait = waited / time.Duration(acquires)
+		s.shedding = s.avgWait > s.threshold
+	}
+
+	switch {
+	case s.shedding && !was:
+		log.Printf("Load shedding started: average connection wait %s exceeds %s", s.avgWait, s.threshold)
+	case !s.shedding && was:
+		log.Printf("Load shedding stopped: average connection wait %s", s.avgWait)
+	}
+}
+
+// Saturated reports whether requests should currently be shed, and the
+// average connection wait seen in the last sample.
+func (s *LoadShedder) Saturated() (bool, time.Duration) {
+	if s == nil {
+		return false, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shedding, s.avgWait
+}
+
+// LoadShed returns middleware that answers 503 with a Retry-After header
+// while the shedder reports the database pool as saturated, so clients back
+// off instead of queueing for a connection until their request times out.
+func LoadShed(s *LoadShedder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if s == nil {
+			return next
+		}
+
+		retryAfter := strconv.Itoa(int(math.Ceil(s.retryAfter.Seconds())))
+		return func(c echo.Context) error {
+			if saturated, _ := s.Saturated(); saturated {
+				c.Response().Header().Set(echo.HeaderRetryAfter, retryAfter)
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{
+					"error": "server busy, retry later",
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePool accumulates statistics the way pgxpool does.
+type fakePool struct {
+	acquires int64
+	wait     time.Duration
+	acquired int32
+	max      int32
+}
+
+func (p *fakePool) AcquireCount() int64                 { return p.acquires }
+func (p *fakePool) EmptyAcquireWaitTime() time.Duration { return p.wait }
+func (p *fakePool) AcquiredConns() int32                { return p.acquired }
+func (p *fakePool) MaxConns() int32                     { return p.max }
+
+func (p *fakePool) acquire(n int64, each time.Duration) {
+	p.acquires += n
+	p.wait += time.Duration(n) * each
+}
+
+func newFakeShedder(pool *fakePool) *LoadShedder {
+	return NewLoadShedder(func() PoolStats { return pool }, 100*time.Millisecond, 5*time.Second)
+}
+
+func serveShed(s *LoadShedder) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.POST("/api/loads/upsert", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, LoadShed(s))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/loads/upsert", nil))
+	return rec
+}
+
+func TestLoadShedPassesWhenHealthy(t *testing.T) {
+	pool := &fakePool{max: 25}
+	s := newFakeShedder(pool)
+
+	pool.acquire(100, 5*time.Millisecond)
+	s.Sample()
+
+	saturated, avg := s.Saturated()
+	assert.False(t, saturated)
+	assert.Equal(t, 5*time.Millisecond, avg)
+	assert.Equal(t, http.StatusOK, serveShed(s).Code)
+}
+
+func TestLoadShedRejectsWhenSaturated(t *testing.T) {
+	pool := &fakePool{max: 25}
+	s := newFakeShedder(pool)
+
+	pool.acquire(50, 400*time.Millisecond)
+	s.Sample()
+
+	rec := serveShed(s)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"server busy, retry later"}`, rec.Body.String())
+}
+
+func TestLoadShedRecovers(t *testing.T) {
+	pool := &fakePool{max: 25}
+	s := newFakeShedder(pool)
+
+	pool.acquire(50, 400*time.Millisecond)
+	s.Sample()
+	saturated, _ := s.Saturated()
+	assert.True(t, saturated)
+
+	// Only the wait accumulated since the last sample counts
+	pool.acquire(50, time.Millisecond)
+	s.Sample()
+	saturated, _ = s.Saturated()
+	assert.False(t, saturated)
+	assert.Equal(t, http.StatusOK, serveShed(s).Code)
+}
+
+func TestLoadShedStuckPool(t *testing.T) {
+	pool := &fakePool{max: 25}
+	s := newFakeShedder(pool)
+
+	pool.acquire(50, 400*time.Millisecond)
+	s.Sample()
+
+	// Every connection is busy and no waiter got one: keep shedding
+	pool.acquired = 25
+	s.Sample()
+	saturated, _ := s.Saturated()
+	assert.True(t, saturated)
+
+	// Idle pool: stop
+	pool.acquired = 0
+	s.Sample()
+	saturated, _ = s.Saturated()
+	assert.False(t, saturated)
+}
+
+func TestLoadShedDisabled(t *testing.T) {
+	s := NewLoadShedder(func() PoolStats { return &fakePool{} }, 0, time.Second)
+	assert.Nil(t, s)
+
+	s.Sample()
+	assert.Equal(t, http.StatusOK, serveShed(s).Code)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateCounter counts hits on a key in fixed windows, returning the hits in
+// the current window, this one included, and when it ends.
+// *repository.RateLimitRepository implements it in Postgres, so the count is
+// shared by every server.
+type RateCounter interface {
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+// ClientIP returns the extractor echo should take c.RealIP() from. With no
+// trusted proxies it is the connection's address, as X-Forwarded-For and
+// X-Real-IP are set by clients at will; otherwise it is the address
+// X-Forwarded-For names before the last trusted proxy.
+func ClientIP(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		opts = append(opts, echo.TrustIPRange(proxy))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
+
+// RateLimiter allows each client IP limit requests a window.
+//
+// A nil *RateLimiter never limits.
+type RateLimiter struct {
+	counter RateCounter
+	limit   int
+	window  time.Duration
+}
+
+// NewRateLimiter creates a limiter counting in counter. Returns nil
+// (limiting disabled) when limit is not positive.
+func NewRateLimiter(counter RateCounter, limit int, window time.Duration) *RateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &RateLimiter{counter: counter, limit: limit, window: window}
+}
+
+// RateLimit returns middleware that answers 429 with a Retry-After header
+// once a client IP has gone over the limiter's limit of requests counted
+// under name in the current window. Requests are let through when they
+// cannot be counted.
+func RateLimit(l *RateLimiter, name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if l == nil {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			hits, resetAt, err := l.counter.Hit(c.Request().Context(), name+":ip:"+c.RealIP(), l.window)
+			if err != nil {
+				log.Printf("Rate limit %s: %v", name, err)
+				return next(c)
+			}
+			if hits <= l.limit {
+				return next(c)
+			}
+
+			retryAfter := int(math.Ceil(time.Until(resetAt).Seconds()))
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+			if c.Request().Header.Get("HX-Request") == "true" {
+				return c.HTML(http.StatusTooManyRequests, `<div class="text-red-500">Too many attempts. Please try again later.</div>`)
+			}
+			return c.JSON(http.StatusTooManyRequests, map[string]string{
+				"error": "too many requests, retry later",
+			})
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCounter counts hits in memory, in a single window ending at end.
+type fakeCounter struct {
+	hits map[string]int
+	end  time.Time
+	err  error
+}
+
+func (f *fakeCounter) Hit(_ context.Context, key string, _ time.Duration) (int, time.Time, error) {
+	if f.err != nil {
+		return 0, time.Time{}, f.err
+	}
+	f.hits[key]++
+	return f.hits[key], f.end, nil
+}
+
+func serveLimited(l *RateLimiter, ip string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.POST("/auth/verify-otp", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RateLimit(l, "verify-otp"))
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/verify-otp", nil)
+	req.RemoteAddr = ip + ":1234"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	counter := &fakeCounter{hits: map[string]int{}, end: time.Now().Add(30 * time.Minute)}
+	l := NewRateLimiter(counter, 2, time.Hour)
+
+	assert.Equal(t, http.StatusOK, serveLimited(l, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, serveLimited(l, "10.0.0.1").Code)
+
+	rec := serveLimited(l, "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1800", rec.Header().Get(echo.HeaderRetryAfter))
+
+	assert.Equal(t, http.StatusOK, serveLimited(l, "10.0.0.2").Code, "other IPs have their own count")
+	assert.Equal(t, 3, counter.hits["verify-otp:ip:10.0.0.1"])
+}
+
+func TestRateLimitLetsThroughWhenCountingFails(t *testing.T) {
+	l := NewRateLimiter(&fakeCounter{err: errors.New("database down")}, 1, time.Hour)
+	assert.Equal(t, http.StatusOK, serveLimited(l, "10.0.0.1").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(&fakeCounter{}, 0, time.Hour))
+	assert.Equal(t, http.StatusOK, serveLimited(nil, "10.0.0.1").Code)
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.1.0.0/16")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		proxies []*net.IPNet
+		remote  string
+		xff     string
+		want    string
+	}{
+		{"no trusted proxies ignores the header", nil, "203.0.113.9", "198.51.100.1", "203.0.113.9"},
+		{"trusted proxy", []*net.IPNet{proxies}, "10.1.2.3", "198.51.100.1", "198.51.100.1"},
+		{"client-set entries before the proxy's", []*net.IPNet{proxies}, "10.1.2.3", "192.0.2.7, 198.51.100.1", "198.51.100.1"},
+		{"untrusted peer", []*net.IPNet{proxies}, "203.0.113.9", "198.51.100.1", "203.0.113.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/verify-otp", nil)
+			req.RemoteAddr = tt.remote + ":1234"
+			req.Header.Set(echo.HeaderXForwardedFor, tt.xff)
+			req.Header.Set(echo.HeaderXRealIP, tt.xff)
+			assert.Equal(t, tt.want, ClientIP(tt.proxies)(req))
+		})
+	}
+}
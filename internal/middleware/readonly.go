@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadOnly returns middleware that answers 503 to every request but GET,
+// HEAD and OPTIONS, for a standby server pointed at a read-only database
+// replica. Mounted with Echo.Use, it refuses mutations on every path alike,
+// routed or not.
+func ReadOnly() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{
+				"error": "server is read-only, changes are unavailable",
+			})
+		}
+	}
+}
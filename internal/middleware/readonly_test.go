@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly(t *testing.T) {
+	e := echo.New()
+	e.Use(ReadOnly())
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/api/entities", ok)
+	e.POST("/api/loads/upsert", ok)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/entities", http.StatusOK},
+		{http.MethodPost, "/api/loads/upsert", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/loads/by-external-id/x", http.StatusServiceUnavailable},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.want, rec.Code, "%s %s", tc.method, tc.path)
+	}
+}
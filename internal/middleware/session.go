@@ -10,6 +10,10 @@ import (
 const (
 	SessionCookieName = "session_token"
 	UserEmailKey      = "user_email"
+
+	// LoginStateCookieName binds a single sign-on login to the browser that
+	// started it
+	LoginStateCookieName = "login_state"
 )
 
 // SessionAuth returns middleware that validates session cookies
@@ -34,7 +38,7 @@ func SessionAuth(authService *service.AuthService) echo.MiddlewareFunc {
 				c.SetCookie(&http.Cookie{
 					Name:     SessionCookieName,
 					Value:    "",
-					Path:     "/",
+					Path:     cookiePath(c),
 					MaxAge:   -1,
 					HttpOnly: true,
 				})
@@ -91,7 +95,7 @@ func SetSessionCookie(c echo.Context, token string) {
 	c.SetCookie(&http.Cookie{
 		Name:     SessionCookieName,
 		Value:    token,
-		Path:     "/",
+		Path:     cookiePath(c),
 		MaxAge:   60 * 60 * 24 * 7, // 7 days
 		HttpOnly: true,
 		Secure:   false, // Set to true in production with HTTPS
@@ -104,7 +108,32 @@ func ClearSessionCookie(c echo.Context) {
 	c.SetCookie(&http.Cookie{
 		Name:     SessionCookieName,
 		Value:    "",
-		Path:     "/",
+		Path:     cookiePath(c),
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// SetLoginStateCookie sets the state of a single sign-on login, kept while
+// the user signs in at the identity provider. It is sent along when the
+// provider redirects back, which SameSite=Lax allows.
+func SetLoginStateCookie(c echo.Context, state string) {
+	c.SetCookie(&http.Cookie{
+		Name:     LoginStateCookieName,
+		Value:    state,
+		Path:     cookiePath(c),
+		MaxAge:   10 * 60, // 10 minutes
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearLoginStateCookie clears the single sign-on login state cookie
+func ClearLoginStateCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     LoginStateCookieName,
+		Value:    "",
+		Path:     cookiePath(c),
 		MaxAge:   -1,
 		HttpOnly: true,
 	})
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gti/heatmap-internal/internal/tracing"
+	"github.com/labstack/echo/v4"
+)
+
+// Trace returns middleware that gives each request a span in the caller's
+// W3C trace (from its traceparent header) or in a new one, and answers with
+// the request's traceparent. Webhooks the request causes carry the same IDs,
+// so a delivery can be traced back to it.
+func Trace() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := tracing.Extract(c.Request().Context(), c.Request().Header)
+			c.SetRequest(c.Request().WithContext(ctx))
+			tracing.Inject(ctx, c.Response().Header())
+			return next(c)
+		}
+	}
+}
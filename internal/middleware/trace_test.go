@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/tracing"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceAnswersWithRequestSpan(t *testing.T) {
+	e := echo.New()
+	e.Use(Trace())
+	var traceID, spanID string
+	e.GET("/", func(c echo.Context) error {
+		traceID, spanID = tracing.IDs(c.Request().Context())
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID, "the caller's trace is continued")
+	assert.Equal(t, "00-"+traceID+"-"+spanID+"-01", rec.Header().Get("traceparent"))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID, "a trace is started without one")
+	assert.Equal(t, "00-"+traceID+"-"+spanID+"-00", rec.Header().Get("traceparent"))
+}
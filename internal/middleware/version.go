@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Deprecated returns middleware that marks responses from a deprecated route
+// group: a Deprecation header, a Link to the same path under successor, and,
+// once a removal date is set, a Sunset header (RFC 8594). A zero sunset
+// leaves the header out.
+//
+// prefix is the deprecated group's path prefix, replaced by successor in the
+// Link target, so /api/loads/upsert points at /api/v1/loads/upsert.
+func Deprecated(prefix, successor string, sunset time.Time) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set("Deprecation", "true")
+			if path := c.Request().URL.Path; strings.HasPrefix(path, prefix) {
+				header.Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, strings.TrimPrefix(path, prefix)))
+			}
+			if !sunset.IsZero() {
+				header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			return next(c)
+		}
+	}
+}
+
+// APIError is the structured error envelope returned by version 2 of the API.
+type APIError struct {
+	Status  int                    `json:"status"`
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// ErrorEnvelope returns middleware that rewrites the flat {"error": "..."}
+// bodies handlers return into {"error": {"status", "code", "message",
+// "details"}}, where code is the snake-cased status text and details holds
+// any other fields of the original body. Errors returned to Echo, such as
+// unknown routes or method mismatches, get the same envelope.
+//
+// Successful responses and non-JSON error bodies pass through unchanged.
+func ErrorEnvelope() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			original := res.Writer
+			buf := &bufferedWriter{ResponseWriter: original}
+			res.Writer = buf
+			defer func() { res.Writer = original }()
+
+			err := next(c)
+			res.Writer = original
+
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				res.Committed = false
+				return writeEnvelope(c, he.Code, fmt.Sprint(he.Message), nil)
+			}
+			if err != nil {
+				if buf.body.Len() > 0 || buf.status != 0 {
+					buf.flushTo(original)
+				}
+				return err
+			}
+
+			if buf.status < http.StatusBadRequest || !strings.HasPrefix(original.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				buf.flushTo(original)
+				return nil
+			}
+
+			var body map[string]interface{}
+			if json.Unmarshal(buf.body.Bytes(), &body) != nil {
+				buf.flushTo(original)
+				return nil
+			}
+			message, ok := body["error"].(string)
+			if !ok {
+				buf.flushTo(original)
+				return nil
+			}
+			delete(body, "error")
+			if len(body) == 0 {
+				body = nil
+			}
+
+			original.Header().Del(echo.HeaderContentLength)
+			res.Committed = false
+			return writeEnvelope(c, buf.status, message, body)
+		}
+	}
+}
+
+// writeEnvelope sends an APIError for status.
+func writeEnvelope(c echo.Context, status int, message string, details map[string]interface{}) error {
+	return c.JSON(status, map[string]APIError{"error": {
+		Status:  status,
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: message,
+		Details: details,
+	}})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveVersioned(mw echo.MiddlewareFunc, path string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	g := e.Group("/api", mw)
+	g.GET("/loads/:id", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestDeprecatedSetsHeaders(t *testing.T) {
+	sunset := time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC)
+	rec := serveVersioned(Deprecated("/api", "/api/v1", sunset), "/api/loads/7", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/loads/7>; rel="successor-version"`, rec.Header().Get("Link"))
+	assert.Equal(t, "Wed, 31 Mar 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+}
+
+func TestDeprecatedWithoutSunset(t *testing.T) {
+	rec := serveVersioned(Deprecated("/api", "/api/v1", time.Time{}), "/api/loads/7", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"), "no sunset until a removal date is set")
+}
+
+func TestErrorEnvelopeWrapsErrorBodies(t *testing.T) {
+	rec := serveVersioned(ErrorEnvelope(), "/api/loads/7", func(c echo.Context) error {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error":     "load is locked",
+			"locked_by": "alice@example.com",
+		})
+	})
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":{"status":409,"code":"conflict","message":"load is locked",
+		"details":{"locked_by":"alice@example.com"}}}`, rec.Body.String())
+}
+
+func TestErrorEnvelopeWrapsHTTPErrors(t *testing.T) {
+	rec := serveVersioned(ErrorEnvelope(), "/api/loads/7", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusForbidden, "not allowed")
+	})
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"error":{"status":403,"code":"forbidden","message":"not allowed"}}`, rec.Body.String())
+}
+
+func TestErrorEnvelopeKeepsOtherResponses(t *testing.T) {
+	rec := serveVersioned(ErrorEnvelope(), "/api/loads/7", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"error": "not an error"})
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"error":"not an error"}`, rec.Body.String(), "successful bodies are untouched")
+
+	rec = serveVersioned(ErrorEnvelope(), "/api/loads/7", func(c echo.Context) error {
+		return c.String(http.StatusBadRequest, "plain text")
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "plain text", rec.Body.String(), "non-JSON errors are untouched")
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,6 +13,16 @@ const (
 	EntityTypeGroup  EntityType = "group"
 )
 
+// Capacity modes, how an entity's daily capacity is worked out
+const (
+	// CapacityModeStatic uses the entity's own default capacity, weekly
+	// pattern and overrides
+	CapacityModeStatic = "static"
+	// CapacityModeMembers, for groups, sums the effective capacities of the
+	// group's active members, who have none on their region's holidays
+	CapacityModeMembers = "members"
+)
+
 // Entity represents a person or group in the system
 type Entity struct {
 	ID              string     `json:"id"`               // email for persons, string-id for groups
@@ -19,7 +30,13 @@ type Entity struct {
 	Type            EntityType `json:"type"`             // "person" or "group"
 	EmployeeID      *string    `json:"employee_id,omitempty"` // Optional employee identifier
 	DefaultCapacity float64    `json:"default_capacity"` // Default daily capacity
+	Skills          []string   `json:"skills,omitempty"` // Lowercase skill tags (persons)
+	ManagerEmail    *string    `json:"manager_email,omitempty"` // Who a person reports to, from directory sync
+	Private         bool       `json:"private,omitempty"` // Heatmap hidden from the public selector and endpoints
+	Region          *string    `json:"region,omitempty"` // Region whose public holidays are days off, such as a country code
+	CapacityMode    string     `json:"capacity_mode"` // "static", or for groups "members" to sum member capacities
 	CreatedAt       time.Time  `json:"created_at"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"` // Set when a person is offboarded
 }
 
 // GroupMember represents the relationship between a group and its members
@@ -37,12 +54,55 @@ type CapacityOverride struct {
 
 // Load represents a task/load item
 type Load struct {
-	ID         int       `json:"id"`
-	ExternalID *string   `json:"external_id,omitempty"` // For n8n/external system deduplication
-	Title      string    `json:"title"`
-	Source     *string   `json:"source,omitempty"` // Origin system (gcal, crm, etc.)
-	URL        *string   `json:"url,omitempty"`    // Link back to original platform (gcal, lark, etc.)
-	Date       time.Time `json:"date"`
+	ID         int        `json:"id"`
+	ExternalID *string    `json:"external_id,omitempty"` // For n8n/external system deduplication
+	Title      string     `json:"title"`
+	Source     *string    `json:"source,omitempty"`   // Origin system (gcal, crm, etc.)
+	URL        *string    `json:"url,omitempty"`      // Link back to original platform (gcal, lark, etc.)
+	Date       time.Time  `json:"date"`               // First (or only) day
+	EndDate    *time.Time `json:"end_date,omitempty"` // Last day of a load spanning several days
+	Spread     LoadSpread `json:"spread,omitempty"`   // How assignment weights fall on the days
+	// Recurrence repeats the load from Date, nil for a one-off
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// CustomFields is extra data from the source, such as a meeting link,
+	// as it was sent; see CustomField
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	// Tags categorize the load, such as meeting or project work; lowercase
+	Tags []string `json:"tags,omitempty"`
+	// ConfidentialGroup is the group whose members alone see the load, nil
+	// for a load anyone may see
+	ConfidentialGroup *string `json:"confidential_group,omitempty"`
+}
+
+// LoadSpread is how the weights of a load spanning several days fall on
+// its days
+type LoadSpread string
+
+const (
+	// LoadSpreadEven splits each weight evenly across the days
+	LoadSpreadEven LoadSpread = "even"
+	// LoadSpreadPerDay counts each weight in full on every day
+	LoadSpreadPerDay LoadSpread = "per_day"
+)
+
+// RecurrenceFrequency is how often a recurring load repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+)
+
+// Recurrence repeats a load like an iCalendar RRULE: every Interval days,
+// weeks or months from its first day, until a day or for a number of
+// occurrences, or without end when neither is set. Monthly loads skip
+// months without their day of the month.
+type Recurrence struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+	Interval  int                 `json:"interval"`        // Every Nth day, week or month
+	Until     *time.Time          `json:"until,omitempty"` // Last day an occurrence may start on
+	Count     *int                `json:"count,omitempty"` // Number of occurrences, the first included
 }
 
 // LoadAssignment represents the assignment of a load to a person with a weight
@@ -56,6 +116,42 @@ type LoadAssignment struct {
 type LoadWithAssignments struct {
 	Load        Load             `json:"load"`
 	Assignments []LoadAssignment `json:"assignments"`
+	Pinned      bool             `json:"pinned,omitempty"` // pinned by the viewing user
+	// Fields are the custom fields day details show, in order
+	Fields []CustomFieldValue `json:"fields,omitempty"`
+	// Conflicts are the IDs of the other loads in day details whose time
+	// windows overlap this one's for an assignee they share
+	Conflicts []int `json:"conflicts,omitempty"`
+}
+
+// LoadList is one page of loads from GET /api/loads
+type LoadList struct {
+	From  string                `json:"from"`
+	To    string                `json:"to"`
+	Loads []LoadWithAssignments `json:"loads"`
+
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// UtilizationRow is an entity's load and capacity on one day. Ratio is load
+// over capacity, null on days without capacity.
+type UtilizationRow struct {
+	Entity   string   `json:"entity"`
+	Date     string   `json:"date"`
+	Load     float64  `json:"load"`
+	Capacity float64  `json:"capacity"`
+	Ratio    *float64 `json:"ratio"`
+}
+
+// UtilizationList is a page of per-day utilization rows, for BI tools
+type UtilizationList struct {
+	From string           `json:"from"`
+	To   string           `json:"to"`
+	Rows []UtilizationRow `json:"rows"`
+
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // HeatmapDay represents a single day in the heatmap
@@ -64,6 +160,7 @@ type HeatmapDay struct {
 	Load     float64   `json:"load"`
 	Capacity float64   `json:"capacity"`
 	Color    string    `json:"color"`
+	Holiday  string    `json:"holiday,omitempty"` // Public holiday in the entity's region, a day without capacity
 }
 
 // HeatmapData represents the complete heatmap data for an entity
@@ -72,6 +169,73 @@ type HeatmapData struct {
 	Days   []HeatmapDay `json:"days"`
 }
 
+// HeatmapVersion identifies the state of the rows an entity's heatmap is
+// computed from. It changes whenever any of them is written or deleted.
+type HeatmapVersion struct {
+	LastModified time.Time // latest write or deletion
+	Rows         int64     // number of contributing rows and deletions
+}
+
+// HeatmapSnapshot is a stored heatmap for an entity. It can be served in
+// place of recomputing while its window and version are still current.
+type HeatmapSnapshot struct {
+	EntityID    string
+	WindowStart time.Time
+	WindowEnd   time.Time
+	Version     HeatmapVersion
+	Days        []HeatmapDay
+	ComputedAt  time.Time
+}
+
+// Scenario is a named what-if workspace of hypothetical loads and capacity
+// changes, overlaid on real data only when rendering its heatmaps
+type Scenario struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ScenarioLoad is a hypothetical load assigned to one person in a scenario
+type ScenarioLoad struct {
+	ID          int       `json:"id"`
+	ScenarioID  int       `json:"scenario_id"`
+	Title       string    `json:"title"`
+	Date        time.Time `json:"date"`
+	PersonEmail string    `json:"person_email"`
+	Weight      float64   `json:"weight"`
+}
+
+// ScenarioCapacity is a hypothetical capacity for an entity on a date
+type ScenarioCapacity struct {
+	ScenarioID int       `json:"scenario_id"`
+	EntityID   string    `json:"entity_id"`
+	Date       time.Time `json:"date"`
+	Capacity   float64   `json:"capacity"`
+}
+
+// ScenarioDetail is a scenario with everything it overlays
+type ScenarioDetail struct {
+	Scenario
+	Loads      []ScenarioLoad     `json:"loads"`
+	Capacities []ScenarioCapacity `json:"capacities"`
+}
+
+// ShadowSource is a connector in shadow mode: the loads its source upserts
+// are kept in a scenario, shown only on that scenario's heatmaps
+type ShadowSource struct {
+	Source     string    `json:"source"`
+	ScenarioID int       `json:"scenario_id"`
+	Loads      int       `json:"loads"` // Scenario loads, one per assignee and day
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateShadowSourceRequest is the request body for putting a connector's
+// source in shadow mode
+type CreateShadowSourceRequest struct {
+	Source string `json:"source" validate:"required,max=100"`
+}
+
 // OTPRecord stores OTP information for authentication
 type OTPRecord struct {
 	Email     string
@@ -90,37 +254,391 @@ type Session struct {
 
 // UpsertLoadRequest is the request body for the n8n load upsert endpoint
 type UpsertLoadRequest struct {
-	ExternalID string `json:"external_id" validate:"required"`
-	Title      string `json:"title" validate:"required"`
-	Source     string `json:"source,omitempty"`
-	URL        string `json:"url,omitempty"` // Link back to original platform
-	Date       string `json:"date" validate:"required"` // Format: YYYY-MM-DD
-	Assignees  []struct {
+	ExternalID      string `json:"external_id" validate:"required"`
+	Title           string `json:"title" validate:"required"`
+	Source          string `json:"source,omitempty"`
+	URL             string `json:"url,omitempty"`                                            // Link back to original platform
+	Date            string `json:"date" validate:"required"`                                 // Format: YYYY-MM-DD; the first day of a multi-day load
+	EndDate         string `json:"end_date,omitempty"`                                       // Format: YYYY-MM-DD; the last day, for loads spanning several days
+	Spread          string `json:"spread,omitempty" validate:"omitempty,oneof=even per_day"` // "even" (default) splits weights across the days, "per_day" counts them on each
+	DurationMinutes *int   `json:"duration_minutes,omitempty" validate:"omitempty,min=0"`    // Used by weight rules
+	AllDay          bool   `json:"all_day,omitempty"`                                        // Used by weight rules
+	Assignees       []struct {
 		Email  string  `json:"email" validate:"required,email"`
-		Weight float64 `json:"weight,omitempty"` // Default 1.0
+		Weight float64 `json:"weight,omitempty"` // Default from weight rules, else 1.0
 	} `json:"assignees" validate:"required,min=1,dive"`
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"` // Repeats the load from date; omitted for a one-off
+	Deleted    bool               `json:"deleted,omitempty"`    // Tombstone: delete the load with this external_id; other fields are ignored
+	// CustomFields is extra data kept with the load, replacing what was sent
+	// before. Fields defined for the source must have the defined type.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty" validate:"omitempty,max=50"`
+	// Tags categorize the load, such as "meeting" or "project", replacing
+	// those sent before. They are lowercased.
+	Tags []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=50"`
+	// ConfidentialGroup is a group ID for a sensitive project's load: only the
+	// group's members, and admins, see it. Every assignee must be a member.
+	ConfidentialGroup string `json:"confidential_group,omitempty"`
+}
+
+// RecurrenceRequest repeats an upserted load; see Recurrence. Set until or
+// count, or neither for a load that repeats without end.
+type RecurrenceRequest struct {
+	Frequency string `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	Interval  int    `json:"interval,omitempty" validate:"omitempty,min=1"` // Default 1
+	Until     string `json:"until,omitempty"`                               // Format: YYYY-MM-DD
+	Count     int    `json:"count,omitempty" validate:"omitempty,min=1"`
+}
+
+// DeletedLoad is the response to deleting a load by its external ID
+type DeletedLoad struct {
+	LoadID     int      `json:"load_id"`
+	ExternalID string   `json:"external_id"`
+	Date       string   `json:"date"` // Format: YYYY-MM-DD
+	Deleted    bool     `json:"deleted"`
+	Assignees  []string `json:"assignees"`        // People whose capacity is re-checked
+	Shadow     bool     `json:"shadow,omitempty"` // Removed from the source's shadow scenario; load_id is 0
+}
+
+// StaleLoad is an upcoming load its source has not upserted within the
+// source's freshness window, awaiting review
+type StaleLoad struct {
+	Load        Load             `json:"load"`
+	Assignments []LoadAssignment `json:"assignments"`
+	LastSeenAt  time.Time        `json:"last_seen_at"` // last upsert by the source
+	StaleSince  time.Time        `json:"stale_since"`  // when the stale load check flagged it
+}
+
+// StaleSourceSummary counts one source's stale loads
+type StaleSourceSummary struct {
+	Source     string    `json:"source"` // empty for loads without a source
+	Loads      int       `json:"loads"`
+	LastSeenAt time.Time `json:"last_seen_at"` // oldest last upsert among them
+}
+
+// StaleLoadReport lists stale loads for review before removal
+type StaleLoadReport struct {
+	Sources []StaleSourceSummary `json:"sources"`
+	Loads   []StaleLoad          `json:"loads"`
+}
+
+// IntegrationError is a failed load sync from a source system
+type IntegrationError struct {
+	Source     string    `json:"source"`    // empty when the request did not name one
+	Operation  string    `json:"operation"` // the route called, e.g. /api/v1/loads/upsert
+	Status     int       `json:"status"`
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// SourceHealth summarizes how a source system's load syncs are going
+type SourceHealth struct {
+	Source       string             `json:"source"`                 // empty for loads without a source
+	LastSyncAt   *time.Time         `json:"last_sync_at,omitempty"` // last successful upsert of any of its loads
+	LoadsSynced  int                `json:"loads_synced_24h"`       // loads upserted in the past 24 hours
+	Errors       int                `json:"errors_24h"`             // failed upserts in the past 24 hours
+	RecentErrors []IntegrationError `json:"recent_errors"`          // latest first, at most a few
+}
+
+// WebhookHealth summarizes deliveries of one webhook event in the past 24
+// hours
+type WebhookHealth struct {
+	Event       string     `json:"event"`
+	Delivered   int        `json:"delivered"`
+	Failed      int        `json:"failed"`
+	SuccessRate float64    `json:"success_rate"` // delivered / attempts, 0..1
+	LastError   string     `json:"last_error,omitempty"`
+	LastFailure *time.Time `json:"last_failure_at,omitempty"`
+	// LastFailureTraceID is the trace ID the latest failure was sent with
+	LastFailureTraceID string `json:"last_failure_trace_id,omitempty"`
+}
+
+// IntegrationHealthReport shows ops which integrations are broken
+type IntegrationHealthReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Sources     []SourceHealth  `json:"sources"`
+	Webhooks    []WebhookHealth `json:"webhooks"`
+}
+
+// StatusReport is what the /status page shows internal users checking
+// whether the service is down for everyone
+type StatusReport struct {
+	GeneratedAt   time.Time      `json:"generated_at"`
+	StartedAt     time.Time      `json:"started_at"`
+	Uptime        time.Duration  `json:"uptime"`
+	DatabaseOK    bool           `json:"database_ok"`
+	DatabaseError string         `json:"database_error,omitempty"`
+	Sources       []SourceHealth `json:"sources"`   // last successful sync per source
+	Incidents     []IncidentNote `json:"incidents"` // newest first
+}
+
+// IncidentNote is an admin's note about an incident, shown on /status
+type IncidentNote struct {
+	ID          int        `json:"id"`
+	Body        string     `json:"body"`
+	AuthorEmail string     `json:"author_email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"` // unset while the incident is open
+}
+
+// CreateIncidentNoteRequest is the request body for posting an incident note
+type CreateIncidentNoteRequest struct {
+	Body string `json:"body" validate:"required,max=1000"`
+}
+
+// UpdateIncidentNoteRequest is the request body for editing an incident
+// note; omitted fields are kept
+type UpdateIncidentNoteRequest struct {
+	Body     *string `json:"body,omitempty" validate:"omitempty,min=1,max=1000"`
+	Resolved *bool   `json:"resolved,omitempty"` // true resolves, false reopens
+}
+
+// Settings are what admins tune at runtime on /settings instead of
+// redeploying. Keys never set take their defaults.
+type Settings struct {
+	// ColorThresholds are the load-to-capacity ratios above which a heatmap
+	// day turns lime, amber, orange, red and blood red, in ascending order
+	ColorThresholds []float64 `json:"color_thresholds"`
+	// DefaultCapacity is the daily capacity of persons and groups created
+	// without one
+	DefaultCapacity float64 `json:"default_capacity"`
+	// AlertCooldownMinutes is how long overload alerts about the same person
+	// or group and day are not sent again, 0 for no cooldown
+	AlertCooldownMinutes int `json:"alert_cooldown_minutes"`
+	// WebhookSecret signs webhook deliveries; it is never sent back
+	WebhookSecret    string     `json:"-"`
+	WebhookSecretSet bool       `json:"webhook_secret_set"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"` // unset while every key has its default
+	UpdatedBy        string     `json:"updated_by,omitempty"`
+}
+
+// UpdateSettingsRequest is the request body for changing settings; omitted
+// fields are kept
+type UpdateSettingsRequest struct {
+	ColorThresholds      []float64 `json:"color_thresholds,omitempty"`
+	DefaultCapacity      *float64  `json:"default_capacity,omitempty"`
+	AlertCooldownMinutes *int      `json:"alert_cooldown_minutes,omitempty"`
+	WebhookSecret        *string   `json:"webhook_secret,omitempty"` // "" removes the secret
+}
+
+// Presence subjects: what someone can be viewing or editing
+const (
+	PresenceEntity = "entity" // A person or group: its capacity, members or heatmap
+	PresenceLoad   = "load"
+)
+
+// Presence modes
+const (
+	PresenceViewing = "viewing"
+	PresenceEditing = "editing"
+)
+
+// Presence is someone else recently viewing or editing the same entity or
+// load
+type Presence struct {
+	Email  string    `json:"email"`
+	Name   string    `json:"name"` // Their entity title, or their email
+	Mode   string    `json:"mode"` // "viewing" or "editing"
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// PresenceResponse lists who else is on a subject, as of a heartbeat
+type PresenceResponse struct {
+	Subject string     `json:"subject"` // e.g. "entity:team-a" or "load:42"
+	Others  []Presence `json:"others"`
+}
+
+// DomainEventType names the kind of change a domain event records
+type DomainEventType string
+
+const (
+	EventLoadUpserted        DomainEventType = "load.upserted"
+	EventLoadDeleted         DomainEventType = "load.deleted"
+	EventLoadAssigneesAdded  DomainEventType = "load.assignees_added"
+	EventLoadAssigneeRemoved DomainEventType = "load.assignee_removed"
+	EventLoadAcknowledged    DomainEventType = "load.acknowledged"
+	EventLoadActualRecorded  DomainEventType = "load.actual_recorded"
+	EventLoadReassigned      DomainEventType = "load.reassigned"
+	EventCapacityChanged     DomainEventType = "capacity.changed"
+	EventCapacityRequested   DomainEventType = "capacity.requested"
+	EventCapacityDecided     DomainEventType = "capacity.decided"
+	EventDelegationAdded     DomainEventType = "delegation.added"
+	EventDelegationRemoved   DomainEventType = "delegation.removed"
+	EventBlackoutAdded       DomainEventType = "blackout.added"
+	EventBlackoutDeleted     DomainEventType = "blackout.deleted"
+	EventEntityCreated       DomainEventType = "entity.created"
+	EventEntityUpdated       DomainEventType = "entity.updated"
+	EventEntityDeleted       DomainEventType = "entity.deleted"
+	EventEntityArchived      DomainEventType = "entity.archived"
+	EventEntityRestored      DomainEventType = "entity.restored"
+	EventEntityIDChanged     DomainEventType = "entity.id_changed"
+	EventPreferencesUpdated  DomainEventType = "entity.preferences_updated"
+	EventPersonOnboarded     DomainEventType = "person.onboarded"
+	EventPersonOffboarded    DomainEventType = "person.offboarded"
+	EventPersonsConfirmed    DomainEventType = "persons.confirmed"
+	EventPersonsRejected     DomainEventType = "persons.rejected"
+	EventGroupsImported      DomainEventType = "groups.imported"
+	EventGroupMemberAdded    DomainEventType = "group.member_added"
+	EventGroupMemberRemoved  DomainEventType = "group.member_removed"
+	EventGroupMemberExcluded DomainEventType = "group.member_excluded"
+	EventGroupMemberIncluded DomainEventType = "group.member_included"
+	EventGroupOwnerAdded     DomainEventType = "group.owner_added"
+	EventGroupOwnerRemoved   DomainEventType = "group.owner_removed"
+	EventDashboardEnabled    DomainEventType = "group.dashboard_enabled"
+	EventDashboardDisabled   DomainEventType = "group.dashboard_disabled"
+	EventSettingsUpdated     DomainEventType = "settings.updated"
+)
+
+// DomainEvent is one change recorded in the append-only domain event log
+type DomainEvent struct {
+	ID         int64           `json:"id"`
+	Type       DomainEventType `json:"type"`
+	Actor      string          `json:"actor,omitempty"` // who made the change, when known
+	EntityIDs  []string        `json:"entity_ids"`      // persons and groups whose data changed
+	Payload    json.RawMessage `json:"payload" swaggertype:"object"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// DeleteStaleLoadsRequest is the request body for removing reviewed stale loads
+type DeleteStaleLoadsRequest struct {
+	LoadIDs []int `json:"load_ids" validate:"required,min=1"`
+}
+
+// DeleteStaleLoadsResponse reports which reviewed loads were removed. Loads
+// upserted again since review, or already gone, are kept and listed as skipped.
+type DeleteStaleLoadsResponse struct {
+	Deleted []DeletedLoad `json:"deleted"`
+	Skipped []int         `json:"skipped"`
+}
+
+// BulkUpsertLoadsRequest is the request body for upserting many loads in a
+// background job. Each load is upserted as by POST /api/loads/upsert,
+// tombstones included.
+type BulkUpsertLoadsRequest struct {
+	Loads []UpsertLoadRequest `json:"loads" validate:"required,min=1,max=10000"`
+}
+
+// ReassignLoadsRequest is the request body for moving a person's loads to
+// someone else in a background job
+type ReassignLoadsRequest struct {
+	From     string `json:"from" validate:"required,email"`
+	To       string `json:"to" validate:"required,email,nefield=From"`
+	FromDate string `json:"from_date,omitempty"` // Format: YYYY-MM-DD; loads ending before it stay, default today
+	Source   string `json:"source,omitempty"`    // Only loads from this source
+}
+
+// BackfillSnapshotsRequest is the request body for rebuilding heatmap
+// snapshots in a background job
+type BackfillSnapshotsRequest struct {
+	EntityIDs []string `json:"entity_ids,omitempty"` // Default every entity
 }
 
 // UpsertLoadByEmployeeIDRequest is the request body for the load upsert endpoint using employee_id
 type UpsertLoadByEmployeeIDRequest struct {
-	ExternalID string `json:"external_id" validate:"required"`
-	Title      string `json:"title" validate:"required"`
-	Source     string `json:"source,omitempty"`
-	URL        string `json:"url,omitempty"` // Link back to original platform
-	Date       string `json:"date" validate:"required"` // Format: YYYY-MM-DD
-	Assignees  []struct {
+	ExternalID      string `json:"external_id" validate:"required"`
+	Title           string `json:"title" validate:"required"`
+	Source          string `json:"source,omitempty"`
+	URL             string `json:"url,omitempty"`                                            // Link back to original platform
+	Date            string `json:"date" validate:"required"`                                 // Format: YYYY-MM-DD; the first day of a multi-day load
+	EndDate         string `json:"end_date,omitempty"`                                       // Format: YYYY-MM-DD; the last day, for loads spanning several days
+	Spread          string `json:"spread,omitempty" validate:"omitempty,oneof=even per_day"` // "even" (default) splits weights across the days, "per_day" counts them on each
+	DurationMinutes *int   `json:"duration_minutes,omitempty" validate:"omitempty,min=0"`    // Used by weight rules
+	AllDay          bool   `json:"all_day,omitempty"`                                        // Used by weight rules
+	Assignees       []struct {
 		EmployeeID string  `json:"employee_id" validate:"required"`
-		Weight     float64 `json:"weight,omitempty"` // Default 1.0
+		Weight     float64 `json:"weight,omitempty"` // Default from weight rules, else 1.0
 	} `json:"assignees" validate:"required,min=1,dive"`
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"` // Repeats the load from date; omitted for a one-off
+	Deleted    bool               `json:"deleted,omitempty"`    // Tombstone: delete the load with this external_id; other fields are ignored
+	// CustomFields is extra data kept with the load, replacing what was sent
+	// before. Fields defined for the source must have the defined type.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty" validate:"omitempty,max=50"`
+	// Tags categorize the load; see UpsertLoadRequest
+	Tags []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=50"`
+	// ConfidentialGroup is a group ID for a sensitive project's load; see
+	// UpsertLoadRequest
+	ConfidentialGroup string `json:"confidential_group,omitempty"`
+}
+
+// CustomFieldType is the type of a custom field's value
+type CustomFieldType string
+
+const (
+	CustomFieldString  CustomFieldType = "string"
+	CustomFieldNumber  CustomFieldType = "number"
+	CustomFieldBoolean CustomFieldType = "boolean"
+	CustomFieldURL     CustomFieldType = "url" // An http(s) link, shown as one
+)
+
+// CustomField defines a custom field a source sends with its loads
+type CustomField struct {
+	Source    string          `json:"source"`
+	Key       string          `json:"key"`
+	Label     string          `json:"label"`
+	Type      CustomFieldType `json:"type"`
+	Display   bool            `json:"display"`  // Shown in day details
+	Position  int             `json:"position"` // Order in day details, lowest first
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// PutCustomFieldRequest is the request body for defining a source's custom
+// field
+type PutCustomFieldRequest struct {
+	Label    string `json:"label" validate:"required,max=100"`
+	Type     string `json:"type" validate:"required,oneof=string number boolean url"`
+	Display  bool   `json:"display,omitempty"`  // Show in day details; default false
+	Position int    `json:"position,omitempty"` // Order in day details, lowest first
+}
+
+// CustomFieldValue is a load's value of a custom field day details show
+type CustomFieldValue struct {
+	Key   string          `json:"key"`
+	Label string          `json:"label"`
+	Type  CustomFieldType `json:"type"`
+	Value interface{}     `json:"value"`
+}
+
+// LarkDigest posts a group's upcoming two weeks as a heatmap image to a Lark
+// chat every Monday
+type LarkDigest struct {
+	GroupID    string     `json:"group_id"`
+	ChatID     string     `json:"chat_id"`
+	Enabled    bool       `json:"enabled"`
+	LastSentOn *time.Time `json:"last_sent_on,omitempty"` // Monday of the latest post
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// PutLarkDigestRequest is the request body for setting up a group's weekly
+// Lark digest
+type PutLarkDigestRequest struct {
+	ChatID  string `json:"chat_id" validate:"required,max=100"`
+	Enabled *bool  `json:"enabled,omitempty"` // default true
+}
+
+// WeightRule sets the weight of upserted loads from a source when the
+// assignee has none. Unset conditions match any load.
+type WeightRule struct {
+	Source     string  `json:"source"`
+	AllDay     *bool   `json:"all_day,omitempty"`
+	MinMinutes *int    `json:"min_minutes,omitempty"` // Inclusive
+	MaxMinutes *int    `json:"max_minutes,omitempty"` // Inclusive
+	Weight     float64 `json:"weight"`
 }
 
 // CreateEntityRequest is the request body for creating an entity
 type CreateEntityRequest struct {
-	ID              string  `json:"id" validate:"required"`
-	Title           string  `json:"title" validate:"required"`
-	Type            string  `json:"type" validate:"required,oneof=person group"`
-	EmployeeID      *string `json:"employee_id,omitempty"`
-	DefaultCapacity float64 `json:"default_capacity,omitempty"`
+	ID              string   `json:"id" validate:"required"`
+	Title           string   `json:"title" validate:"required"`
+	Type            string   `json:"type" validate:"required,oneof=person group"`
+	EmployeeID      *string  `json:"employee_id,omitempty"`
+	DefaultCapacity float64  `json:"default_capacity,omitempty"`
+	Skills          []string `json:"skills,omitempty" validate:"dive,required"`
+	ManagerEmail    *string  `json:"manager_email,omitempty" validate:"omitempty,email"`
+	Private         bool     `json:"private,omitempty"`
+	Region          *string  `json:"region,omitempty"`
+	CapacityMode    string   `json:"capacity_mode,omitempty" validate:"omitempty,oneof=static members"` // Default static; members only for groups
 }
 
 // UpdateEntityRequest is the request body for updating an entity
@@ -128,6 +646,18 @@ type UpdateEntityRequest struct {
 	Title           *string  `json:"title,omitempty"`
 	EmployeeID      *string  `json:"employee_id,omitempty"`
 	DefaultCapacity *float64 `json:"default_capacity,omitempty"`
+	Skills          []string `json:"skills,omitempty"` // Replaces the tags; [] clears them
+	ManagerEmail    *string  `json:"manager_email,omitempty"` // "" clears the manager
+	Private         *bool    `json:"private,omitempty"`
+	Region          *string  `json:"region,omitempty"` // "" clears the region
+	CapacityMode    *string  `json:"capacity_mode,omitempty"`
+}
+
+// EntityConflictResponse is returned when creating an entity whose ID is
+// taken, with the entity that holds it
+type EntityConflictResponse struct {
+	Error  string  `json:"error"`
+	Entity *Entity `json:"entity,omitempty"` // Left out when outside the API key's groups
 }
 
 // UpdateCapacityRequest is the request body for updating capacity
@@ -137,6 +667,793 @@ type UpdateCapacityRequest struct {
 		Date     string  `json:"date" validate:"required"` // Format: YYYY-MM-DD
 		Capacity float64 `json:"capacity" validate:"required,min=0"`
 	} `json:"date_overrides,omitempty"`
+	WeeklyPattern []WeekdayCapacity `json:"weekly_pattern,omitempty"`
+}
+
+// WeekdayCapacity is a person's capacity on one day of every week, used when
+// no date override applies. A null capacity clears the weekday so the default
+// capacity applies again.
+type WeekdayCapacity struct {
+	Weekday  string   `json:"weekday"` // monday .. sunday
+	Capacity *float64 `json:"capacity"`
+}
+
+// HeatmapPreferences are how a person sees heatmap grids
+type HeatmapPreferences struct {
+	WeekStart string `json:"week_start"` // monday .. sunday, the first column of each week
+	Custom    bool   `json:"custom"`     // false when following the server's WEEK_START
+	Private   bool   `json:"private"`    // heatmap visible only to you, your group owners and admins
+	// HiddenSources are load sources left out of the heatmaps you view
+	HiddenSources []string `json:"hidden_sources"`
+}
+
+// UpdateHeatmapPreferencesRequest is the request body for updating heatmap
+// preferences. week_start is replaced on every update, a null or missing one
+// following the server's WEEK_START again;
+// omitting private or hidden_sources leaves them unchanged.
+type UpdateHeatmapPreferencesRequest struct {
+	WeekStart *string `json:"week_start"`        // monday .. sunday
+	Private   *bool   `json:"private,omitempty"` // hide your heatmap from everyone but group owners and admins
+	// HiddenSources replaces the load sources left out of the heatmaps you
+	// view; an empty list shows every source again
+	HiddenSources *[]string `json:"hidden_sources,omitempty"`
+}
+
+// CapacityChangeStatus is the state of a capacity change held for approval
+type CapacityChangeStatus string
+
+const (
+	CapacityChangePending  CapacityChangeStatus = "pending"
+	CapacityChangeApproved CapacityChangeStatus = "approved"
+	CapacityChangeRejected CapacityChangeStatus = "rejected"
+)
+
+// CapacityChangeRequest is a capacity change held until an owner of one of
+// the person's groups approves it
+type CapacityChangeRequest struct {
+	ID          int                   `json:"id"`
+	EntityID    string                `json:"entity_id"`
+	Change      UpdateCapacityRequest `json:"change"`
+	Reason      string                `json:"reason"` // Why the change needs approval
+	Status      CapacityChangeStatus  `json:"status"`
+	RequestedAt time.Time             `json:"requested_at"`
+	DecidedBy   *string               `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time            `json:"decided_at,omitempty"`
+}
+
+// CapacitySettings is the JSON form of a person's capacity page
+type CapacitySettings struct {
+	Entity         Entity                  `json:"entity"`
+	Overrides      []CapacityOverride      `json:"overrides"`
+	WeeklyPattern  []WeekdayCapacity       `json:"weekly_pattern"`
+	PendingChanges []CapacityChangeRequest `json:"pending_changes"` // the person's own changes awaiting approval
+	Approvals      []CapacityChangeRequest `json:"approvals"`       // changes the user may approve, empty when acting for someone else
+}
+
+// PendingAcknowledgment is a load assignment its assignee has not yet
+// acknowledged
+type PendingAcknowledgment struct {
+	LoadID      int       `json:"load_id"`
+	Title       string    `json:"title"`
+	Date        time.Time `json:"date"`
+	PersonEmail string    `json:"person_email"`
+	Weight      float64   `json:"weight"`
+	AssignedAt  time.Time `json:"assigned_at"`
+}
+
+// LoadAnomaly is a person-day whose load is unusually high against their
+// recent average
+type LoadAnomaly struct {
+	PersonEmail string    `json:"person_email"`
+	Date        time.Time `json:"date"`
+	Load        float64   `json:"load"`
+	Baseline    float64   `json:"baseline"`
+}
+
+// AcknowledgeLoadResponse confirms an assignee has seen a load
+type AcknowledgeLoadResponse struct {
+	LoadID         int       `json:"load_id"`
+	PersonEmail    string    `json:"person_email"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// RecordActualRequest is the request body for recording the effort actually
+// spent on a load
+type RecordActualRequest struct {
+	Actual *float64 `json:"actual" validate:"required,min=0"` // In the same units as weights
+}
+
+// LoadActual is the effort an assignee actually spent on a load, next to the
+// weight planned for them
+type LoadActual struct {
+	LoadID      int       `json:"load_id"`
+	PersonEmail string    `json:"person_email"`
+	Planned     float64   `json:"planned"`
+	Actual      float64   `json:"actual"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// BlackoutDate is a date range in which an entity takes no new loads, such
+// as a release freeze or exam week. A group's blackout covers its members.
+type BlackoutDate struct {
+	ID        int       `json:"id"`
+	EntityID  string    `json:"entity_id"`
+	StartDate string    `json:"start_date"` // Format: YYYY-MM-DD
+	EndDate   string    `json:"end_date"`   // Format: YYYY-MM-DD, inclusive
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Holiday is a public holiday in a region, on which persons and groups of
+// the region have no capacity
+type Holiday struct {
+	Region string `json:"region"`
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+}
+
+// FetchHolidaysRequest is the request body for importing a region's public
+// holidays of a year from the holidays API
+type FetchHolidaysRequest struct {
+	Year int `json:"year" validate:"required,min=1900,max=2999"`
+}
+
+// HolidayImport reports how many holidays an import stored for a region
+type HolidayImport struct {
+	Region   string `json:"region"`
+	Imported int    `json:"imported"`
+}
+
+// CreateBlackoutRequest is the request body for declaring a blackout
+type CreateBlackoutRequest struct {
+	StartDate string `json:"start_date" validate:"required"` // Format: YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"`             // Format: YYYY-MM-DD, default start_date
+	Reason    string `json:"reason" validate:"required"`
+}
+
+// BlackoutConflict is a new assignment that falls in a blackout, either the
+// assignee's own or one of their groups'
+type BlackoutConflict struct {
+	PersonEmail string `json:"person_email"`
+	EntityID    string `json:"entity_id"` // Entity that declared the blackout
+	Date        string `json:"date"`
+	Reason      string `json:"reason"`
+}
+
+// UpsertLoadResponse is the body of a successful load upsert
+type UpsertLoadResponse struct {
+	Success   bool               `json:"success"`
+	LoadID    int                `json:"load_id"`
+	Blackouts []BlackoutConflict `json:"blackouts,omitempty"` // New assignments on blackout dates
+	Assignees []AssigneeDayLoad  `json:"assignees,omitempty"`
+	Shadow    bool               `json:"shadow,omitempty"` // Kept in the source's shadow scenario, not in loads; load_id is 0
+}
+
+// AssigneeDayLoad is an assignee's total load against their capacity on a
+// day of an upserted load, the load included
+type AssigneeDayLoad struct {
+	PersonEmail string  `json:"person_email"`
+	Date        string  `json:"date"`
+	Load        float64 `json:"load"`
+	Capacity    float64 `json:"capacity"`
+	Overloaded  bool    `json:"overloaded"`
+}
+
+// DryRunAction is what a dry-run write would do
+type DryRunAction string
+
+const (
+	DryRunCreate DryRunAction = "create"
+	DryRunUpdate DryRunAction = "update"
+	DryRunDelete DryRunAction = "delete"
+	// DryRunRequestApproval holds a capacity change for approval
+	DryRunRequestApproval DryRunAction = "request_approval"
+)
+
+// DryRunResult is what a write sent with ?dry_run=true would do, checked as
+// the write would be but not made
+type DryRunResult struct {
+	DryRun bool         `json:"dry_run"`
+	Action DryRunAction `json:"action"`
+	// Result is the load, entity or capacity change as it would be saved,
+	// or what a deletion would remove
+	Result      interface{}        `json:"result,omitempty"`
+	AutoCreated []string           `json:"auto_created,omitempty"` // Unknown assignees that would be created
+	Blackouts   []BlackoutConflict `json:"blackouts,omitempty"`    // New assignments on blackout dates
+	Days        []DryRunDay        `json:"days,omitempty"`
+}
+
+// DryRunDay is an entity's total load and capacity on a day, now and after a
+// dry-run write
+type DryRunDay struct {
+	EntityID       string  `json:"entity_id"`
+	Date           string  `json:"date"`
+	LoadBefore     float64 `json:"load_before"`
+	Load           float64 `json:"load"`
+	CapacityBefore float64 `json:"capacity_before"`
+	Capacity       float64 `json:"capacity"`
+	Overloaded     bool    `json:"overloaded"` // after the write
+}
+
+// IssueWebhook is a Jira-style issue webhook, as Jira sends when an issue is
+// created, updated or deleted
+type IssueWebhook struct {
+	WebhookEvent string `json:"webhookEvent"` // jira:issue_deleted removes the issue's load
+	Issue        Issue  `json:"issue"`
+}
+
+// Issue is the issue a webhook is about. Fields are read as Jira names them:
+// summary, assignee.emailAddress, duedate (YYYY-MM-DD), status.statusCategory.key,
+// timeoriginalestimate (seconds) and the story points field.
+type Issue struct {
+	Key    string                 `json:"key" validate:"required"` // e.g. PROJ-123, the load's external ID
+	Self   string                 `json:"self,omitempty"`          // REST URL of the issue, for the link back to it
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// IssueLoadResult is what an issue webhook did to the issue's load
+type IssueLoadResult struct {
+	ExternalID string              `json:"external_id"`
+	Action     string              `json:"action"`           // "upserted", "deleted", or "ignored" when there was no load to delete
+	Reason     string              `json:"reason,omitempty"` // why the issue has no load, unless upserted
+	Load       *UpsertLoadResponse `json:"load,omitempty"`   // the upsert's response, when upserted
+}
+
+// Delegation lets an assistant manage a person's capacity for them
+type Delegation struct {
+	PersonEmail    string    `json:"person_email"`
+	AssistantEmail string    `json:"assistant_email"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// DelegationsResponse lists who manages a person's capacity and whose
+// capacity they manage
+type DelegationsResponse struct {
+	Assistants []Delegation `json:"assistants"`
+	Delegators []Delegation `json:"delegators"`
+}
+
+// AddDelegationRequest is the request body for delegating capacity management
+type AddDelegationRequest struct {
+	AssistantEmail string `json:"assistant_email" validate:"required,email"`
+}
+
+// CapacityAuditAction is what a capacity audit entry records
+type CapacityAuditAction string
+
+const (
+	CapacityAuditUpdate         CapacityAuditAction = "update"
+	CapacityAuditRequest        CapacityAuditAction = "request"
+	CapacityAuditDeleteOverride CapacityAuditAction = "delete_override"
+	CapacityAuditApprove        CapacityAuditAction = "approve"
+	CapacityAuditReject         CapacityAuditAction = "reject"
+)
+
+// CapacityAuditEntry records a change to a person's capacity and who made
+// it; ActorEmail differs from PersonEmail when someone acted for them
+type CapacityAuditEntry struct {
+	ID          int                 `json:"id"`
+	PersonEmail string              `json:"person_email"`
+	ActorEmail  string              `json:"actor_email"`
+	Action      CapacityAuditAction `json:"action"`
+	Detail      string              `json:"detail"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// DeleteOverridesResponse reports how many capacity overrides a range
+// deletion removed
+type DeleteOverridesResponse struct {
+	EntityID string `json:"entity_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Deleted  int    `json:"deleted"`
+}
+
+// Note is a short comment on a load, or on its author's own day
+type Note struct {
+	ID          int       `json:"id"`
+	AuthorEmail string    `json:"author_email"`
+	LoadID      *int      `json:"load_id,omitempty"` // unset for day notes
+	LoadTitle   string    `json:"load_title,omitempty"`
+	Date        string    `json:"date"` // the load's date or the noted day, YYYY-MM-DD
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateLoadNoteRequest is the request body for commenting on a load
+type CreateLoadNoteRequest struct {
+	Body string `json:"body" validate:"required,max=280"`
+}
+
+// CreateDayNoteRequest is the request body for noting something about the
+// user's own day, such as "travel day"
+type CreateDayNoteRequest struct {
+	Date string `json:"date" validate:"required"` // Format: YYYY-MM-DD
+	Body string `json:"body" validate:"required,max=280"`
+}
+
+// EntityLink is an important link kept on an entity, such as its team
+// charter or on-call schedule
+type EntityLink struct {
+	Title string `json:"title" validate:"required,max=100"`
+	URL   string `json:"url" validate:"required,max=2048"` // http or https
+}
+
+// EntityInfo is the notes and links admins keep on an entity, shown in a
+// panel beside its heatmap
+type EntityInfo struct {
+	EntityID  string       `json:"entity_id"`
+	Notes     string       `json:"notes"`
+	Links     []EntityLink `json:"links"`
+	UpdatedBy string       `json:"updated_by,omitempty"` // unset until first edited
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+}
+
+// UpdateEntityInfoRequest replaces an entity's notes and links
+type UpdateEntityInfoRequest struct {
+	Notes string       `json:"notes" validate:"max=5000"`
+	Links []EntityLink `json:"links" validate:"max=20,dive"`
+}
+
+// Pin is a load a user pinned to show first in day details and in heatmap
+// tooltips
+type Pin struct {
+	LoadID   int       `json:"load_id"`
+	Title    string    `json:"title"`
+	Date     string    `json:"date"` // Format: YYYY-MM-DD
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// DayDetailsResponse is the JSON form of an entity's day details
+type DayDetailsResponse struct {
+	EntityID  string                `json:"entity_id"`
+	Date      string                `json:"date"`
+	TotalLoad float64               `json:"total_load"`
+	Capacity  float64               `json:"capacity"`
+	Loads     []LoadWithAssignments `json:"loads"`
+	Notes     []Note                `json:"notes"`
+	Members   []MemberDayLoad       `json:"members,omitempty"` // Groups only: the loads per member
+	Tags      []TagLoad             `json:"tags,omitempty"`    // Each tag's share of the loads, when any is tagged
+}
+
+// TagLoad is how much of a day's loads carry a tag, with the color the tag
+// is shown in. Untagged loads are counted under an empty tag.
+type TagLoad struct {
+	Tag   string  `json:"tag"`
+	Load  float64 `json:"load"`
+	Color string  `json:"color"`
+}
+
+// MemberDayLoad is one group member's share of a group's day: their loads,
+// each with only their own assignment, and their subtotal against their own
+// capacity
+type MemberDayLoad struct {
+	PersonEmail string                `json:"person_email"`
+	Load        float64               `json:"load"`
+	Capacity    float64               `json:"capacity"`
+	Loads       []LoadWithAssignments `json:"loads"`
+}
+
+// MemberDayBreakdown is a group member's own load against their own
+// capacity on a day
+type MemberDayBreakdown struct {
+	PersonEmail string  `json:"person_email"`
+	Title       string  `json:"title"`
+	Load        float64 `json:"load"`
+	Capacity    float64 `json:"capacity"`
+	Color       string  `json:"color"` // Heatmap color of the member's day
+	Overloaded  bool    `json:"overloaded"`
+}
+
+// GroupDayBreakdownResponse is the JSON form of a group's per-member
+// breakdown of a day
+type GroupDayBreakdownResponse struct {
+	GroupID string               `json:"group_id"`
+	Date    string               `json:"date"`    // Format: YYYY-MM-DD
+	Members []MemberDayBreakdown `json:"members"` // Fullest first
+}
+
+// OnboardPersonRequest is the request body for onboarding a person in one call
+type OnboardPersonRequest struct {
+	Email           string         `json:"email" validate:"required,email"`
+	Title           string         `json:"title" validate:"required"`
+	EmployeeID      *string        `json:"employee_id,omitempty"`
+	DefaultCapacity float64        `json:"default_capacity,omitempty" validate:"min=0"` // Default from the settings, 5.0 unless changed
+	Groups          []string       `json:"groups,omitempty" validate:"dive,required"`
+	Skills          []string       `json:"skills,omitempty" validate:"dive,required"`
+	ManagerEmail    *string        `json:"manager_email,omitempty" validate:"omitempty,email"`
+	StartDate       string         `json:"start_date,omitempty"` // Format: YYYY-MM-DD, default today
+	RampUp          *RampUpRequest `json:"ramp_up,omitempty"`
+}
+
+// RampUpRequest reduces a new person's capacity for their first days
+type RampUpRequest struct {
+	Days     int     `json:"days" validate:"required,min=1,max=365"`
+	Capacity float64 `json:"capacity" validate:"min=0"`
+}
+
+// OnboardPersonResponse describes the person created by onboarding
+type OnboardPersonResponse struct {
+	Entity      Entity   `json:"entity"`
+	Groups      []string `json:"groups"`
+	RampUpUntil string   `json:"ramp_up_until,omitempty"` // Last reduced-capacity day
+}
+
+// OffboardPersonRequest is the request body for offboarding a person
+type OffboardPersonRequest struct {
+	LastDay string `json:"last_day,omitempty"` // Format: YYYY-MM-DD, default today
+}
+
+// OffboardedAssignment is a load a person was removed from when offboarded
+type OffboardedAssignment struct {
+	LoadID     int       `json:"load_id"`
+	Title      string    `json:"title"`
+	Date       time.Time `json:"date"`
+	Weight     float64   `json:"weight"`
+	Unassigned bool      `json:"unassigned"` // No assignees left
+}
+
+// OffboardPersonResponse describes what offboarding changed
+type OffboardPersonResponse struct {
+	Email              string                 `json:"email"`
+	LastDay            string                 `json:"last_day"`
+	Groups             []string               `json:"groups"`
+	RemovedAssignments []OffboardedAssignment `json:"removed_assignments"`
+}
+
+// ChangeEntityIDRequest is the request body for moving an entity to a new ID
+type ChangeEntityIDRequest struct {
+	NewID string `json:"new_id" validate:"required"` // A person's new email
+}
+
+// ChangeEntityIDResponse describes an entity moved to a new ID
+type ChangeEntityIDResponse struct {
+	OldID    string     `json:"old_id"`
+	NewID    string     `json:"new_id"`
+	Type     EntityType `json:"type"`
+	Sessions int        `json:"sessions"` // Logins kept under the new ID
+}
+
+// EntityDeletePreview counts what deleting an entity would remove along
+// with it
+type EntityDeletePreview struct {
+	EntityID          string `json:"entity_id"`
+	Type              string `json:"type"`
+	Assignments       int    `json:"assignments"`        // load assignments of the person
+	FutureLoads       int    `json:"future_loads"`       // loads not yet over that lose the person
+	UnassignedLoads   int    `json:"unassigned_loads"`   // future loads left with no assignee
+	Memberships       int    `json:"memberships"`        // group memberships, of the person or in the group
+	CapacityOverrides int    `json:"capacity_overrides"` // per-date capacity overrides
+	WeeklyCapacity    int    `json:"weekly_capacity"`    // weekdays with their own capacity
+}
+
+// EntityChanges is what changed in the entity list after a point in time,
+// for clients keeping a copy of it in sync
+type EntityChanges struct {
+	Changed []Entity  `json:"changed"` // entities created or updated since, not archived
+	Removed []string  `json:"removed"` // IDs of the entities archived or deleted since
+	Until   time.Time `json:"until"`   // pass as since to fetch the next changes
+}
+
+// GroupImportRow puts one member in one group
+type GroupImportRow struct {
+	Group  string `json:"group" validate:"required"`
+	Member string `json:"member" validate:"required,email"`
+}
+
+// GroupImportRequest is the JSON request body for importing groups and
+// memberships in one call
+type GroupImportRequest struct {
+	Rows []GroupImportRow `json:"rows" validate:"required,min=1,dive"`
+}
+
+// GroupImportResponse describes what a group import changed. Importing the
+// same rows again creates nothing and only counts existing memberships.
+type GroupImportResponse struct {
+	GroupsCreated       []string `json:"groups_created"`
+	PeopleCreated       []string `json:"people_created"` // Members not yet known, created with the default capacity
+	MembershipsAdded    int      `json:"memberships_added"`
+	MembershipsExisting int      `json:"memberships_existing"`
+}
+
+// AutoCreatedPerson is a person created because an upsert named them as an
+// assignee, waiting for review in case the email was mistyped
+type AutoCreatedPerson struct {
+	Email     string    `json:"email"`
+	Source    string    `json:"source"` // source of the upsert, empty when it did not name one
+	CreatedAt time.Time `json:"created_at"`
+	Loads     int       `json:"loads"` // loads assigned to them now
+}
+
+// ReviewAutoCreatedRequest is the request body for confirming or rejecting
+// auto-created persons
+type ReviewAutoCreatedRequest struct {
+	Emails []string `json:"emails" validate:"required,min=1,max=1000"`
+}
+
+// ReviewAutoCreatedResponse reports which persons were confirmed or rejected.
+// Persons no longer waiting for review are listed as skipped.
+type ReviewAutoCreatedResponse struct {
+	Reviewed []string `json:"reviewed"`
+	Skipped  []string `json:"skipped"`
+}
+
+// SuggestAssigneeRequest is the request body for suggesting who can take a load
+type SuggestAssigneeRequest struct {
+	Date   string  `json:"date" validate:"required"`          // Format: YYYY-MM-DD
+	Weight float64 `json:"weight,omitempty" validate:"min=0"` // Default 1.0
+	Skill  string  `json:"skill" validate:"required"`
+	Limit  int     `json:"limit,omitempty" validate:"min=0,max=100"` // Default 5
+}
+
+// AssigneeSuggestion is a person with the skill and their slack on the date
+type AssigneeSuggestion struct {
+	Email     string  `json:"email"`
+	Title     string  `json:"title"`
+	Capacity  float64 `json:"capacity"`
+	Load      float64 `json:"load"`
+	Remaining float64 `json:"remaining"` // Capacity minus load, before the new load
+}
+
+// SuggestAssigneeResponse ranks persons by remaining capacity, most first
+type SuggestAssigneeResponse struct {
+	Date        string               `json:"date"`
+	Skill       string               `json:"skill"`
+	Weight      float64              `json:"weight"`
+	Suggestions []AssigneeSuggestion `json:"suggestions"`
+}
+
+// LeastLoadedMember is a group member's room on a date once a load is added
+type LeastLoadedMember struct {
+	Email      string  `json:"email"`
+	Title      string  `json:"title"`
+	Capacity   float64 `json:"capacity"`
+	Load       float64 `json:"load"`      // Before the new load
+	Remaining  float64 `json:"remaining"` // Capacity minus load and the new load's weight
+	Overloaded bool    `json:"overloaded"`
+}
+
+// LeastLoadedResponse ranks a group's members by remaining capacity after a
+// new load, most first
+type LeastLoadedResponse struct {
+	GroupID string              `json:"group_id"`
+	Date    string              `json:"date"`
+	Weight  float64             `json:"weight"`
+	Members []LeastLoadedMember `json:"members"`
+}
+
+// RebalanceMember is a group member's capacity and load on the rebalanced date
+type RebalanceMember struct {
+	Email      string  `json:"email"`
+	Title      string  `json:"title"`
+	Capacity   float64 `json:"capacity"`
+	LoadBefore float64 `json:"load_before"`
+	LoadAfter  float64 `json:"load_after"` // Load once every move is applied
+}
+
+// RebalanceMove proposes moving one member's share of a load to another member
+type RebalanceMove struct {
+	LoadID int     `json:"load_id"`
+	Title  string  `json:"title"`
+	Weight float64 `json:"weight"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+}
+
+// RebalancePlan is a dry-run plan for resolving a group's overloads on a date.
+// Nothing is changed; each move is applied by removing the assignee from the
+// load and adding the new one with the same weight.
+type RebalancePlan struct {
+	GroupID  string            `json:"group_id"`
+	Date     string            `json:"date"`
+	Moves    []RebalanceMove   `json:"moves"`
+	Members  []RebalanceMember `json:"members"`
+	Resolved bool              `json:"resolved"` // No member is overloaded after the moves
+}
+
+// OverloadDay tracks a person-day that went over capacity
+type OverloadDay struct {
+	PersonEmail  string     `json:"person_email"`
+	Date         time.Time  `json:"date"`
+	OverloadedAt time.Time  `json:"overloaded_at"`         // When it first went over capacity
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"` // When it last came back under; nil while overloaded
+}
+
+// GroupOverloadDay is an overload day of one of a group's members
+type GroupOverloadDay struct {
+	GroupID string `json:"group_id"`
+	OverloadDay
+}
+
+// OverloadResolution summarizes how a group's overloaded person-days were fixed
+type OverloadResolution struct {
+	GroupID            string   `json:"group_id"`
+	Title              string   `json:"title"`
+	Overloaded         int      `json:"overloaded"`                      // Person-days that went over capacity
+	Resolved           int      `json:"resolved"`                        // Brought back under capacity
+	Open               int      `json:"open"`                            // Still overloaded, date not yet passed
+	Unresolved         int      `json:"unresolved"`                      // Date passed while still overloaded
+	MeanHoursToResolve *float64 `json:"mean_hours_to_resolve,omitempty"` // Nil until a day is resolved
+}
+
+// OverloadResolutionReport is the per-group overload resolution report for
+// person-days between From and To
+type OverloadResolutionReport struct {
+	From   string               `json:"from"`
+	To     string               `json:"to"`
+	Groups []OverloadResolution `json:"groups"`
+}
+
+// OverloadMetrics are service-wide overload counters across all tracked days
+type OverloadMetrics struct {
+	Open              int
+	Resolved          int
+	Unresolved        int
+	ResolutionSeconds float64 // Sum over resolved days
+}
+
+// APIKeyMetric is an API key's traffic on a UTC day
+type APIKeyMetric struct {
+	Key           string  `json:"key"` // api_key, groups:<ids> for group-scoped keys, or unauthenticated
+	Date          string  `json:"date"`
+	Requests      int64   `json:"requests"`
+	ClientErrors  int64   `json:"client_errors"` // 4xx responses
+	ServerErrors  int64   `json:"server_errors"` // 5xx responses
+	ErrorRate     float64 `json:"error_rate"`    // Share of requests answered with an error
+	RequestBytes  int64   `json:"request_bytes"`
+	ResponseBytes int64   `json:"response_bytes"`
+}
+
+// APIKeyMetricsReport is every API key's daily traffic between From and To,
+// by date and then busiest key first
+type APIKeyMetricsReport struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Metrics []APIKeyMetric `json:"metrics"`
+}
+
+// JobStatus is where a background job is in its life
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded" // Finished, though items may have failed
+	JobFailed    JobStatus = "failed"    // Stopped early; see Error
+)
+
+// Kinds of background jobs
+const (
+	JobKindBulkUpsert        = "bulk_upsert"
+	JobKindReassign          = "reassign"
+	JobKindSnapshotsBackfill = "snapshot_backfill"
+)
+
+// Job is a bulk operation running in the background, polled at
+// GET /api/jobs/:id
+type Job struct {
+	ID         int64          `json:"id"`
+	Kind       string         `json:"kind"`
+	Status     JobStatus      `json:"status"`
+	Total      int            `json:"total"`     // Items to process
+	Processed  int            `json:"processed"` // Items done so far, failed ones included
+	Failed     int            `json:"failed"`
+	Errors     []JobItemError `json:"errors"` // The first failed items
+	Error      *string        `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// JobItemError is why one item of a job failed
+type JobItemError struct {
+	Item  int    `json:"item"`         // Index in the request, or in the job's own item list
+	ID    string `json:"id,omitempty"` // External ID, load ID or entity ID of the item
+	Error string `json:"error"`
+}
+
+// EntityDayCapacity is an entity's effective capacity on a day it was active
+type EntityDayCapacity struct {
+	EntityID string
+	Date     time.Time
+	Capacity float64
+}
+
+// SourceDayLoad is a person's load from one source on a day
+type SourceDayLoad struct {
+	PersonEmail string
+	Date        time.Time
+	Source      string
+	Load        float64
+}
+
+// PersonDayFact is a person's total load and capacity on a day, as exported
+// to the analytics warehouse
+type PersonDayFact struct {
+	Date        time.Time
+	PersonEmail string
+	Load        float64
+	Capacity    float64
+}
+
+// SourceLoad is the total load from one source
+type SourceLoad struct {
+	Source string  `json:"source"`
+	Load   float64 `json:"load"`
+}
+
+// EffortSample is the planned and actual effort of one assignment
+type EffortSample struct {
+	Source  string
+	Planned float64
+	Actual  float64
+}
+
+// SourceCalibration compares the weights planned for a source's loads to the
+// effort actually spent on them
+type SourceCalibration struct {
+	Source            string  `json:"source"`
+	Samples           int     `json:"samples"` // Assignments with a recorded actual
+	PlannedTotal      float64 `json:"planned_total"`
+	ActualTotal       float64 `json:"actual_total"`
+	Ratio             float64 `json:"ratio"` // Actual over planned; above 1 means underestimated
+	MeanAbsoluteError float64 `json:"mean_absolute_error"`
+}
+
+// CalibrationReport compares planned weights to actual effort per source
+// over a date range
+type CalibrationReport struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Sources []SourceCalibration `json:"sources"`
+}
+
+// UtilizationSummary is a person's or group's utilization over a quarter
+type UtilizationSummary struct {
+	EntityID           string       `json:"entity_id"`
+	Title              string       `json:"title"`
+	Days               int          `json:"days"` // Days active in the quarter
+	AverageLoad        float64      `json:"average_load"`
+	AverageUtilization float64      `json:"average_utilization"` // Total load as a percentage of total capacity
+	P95Load            float64      `json:"p95_load"`
+	OverloadedDays     int          `json:"overloaded_days"`
+	TopSources         []SourceLoad `json:"top_sources"`
+}
+
+// UtilizationReport summarizes every person's and group's utilization over a
+// quarter
+type UtilizationReport struct {
+	Quarter     string               `json:"quarter"` // e.g. 2025-Q1
+	From        string               `json:"from"`
+	To          string               `json:"to"` // Today while the quarter is in progress
+	Complete    bool                 `json:"complete"`
+	GeneratedAt time.Time            `json:"generated_at"`
+	People      []UtilizationSummary `json:"people"`
+	Groups      []UtilizationSummary `json:"groups"`
+}
+
+// CreateScenarioRequest is the request body for creating a scenario
+type CreateScenarioRequest struct {
+	Name        string  `json:"name" validate:"required"`
+	Description *string `json:"description,omitempty"`
+}
+
+// AddScenarioLoadRequest is the request body for adding a hypothetical load
+// to a scenario, stored as one scenario load per assignee
+type AddScenarioLoadRequest struct {
+	Title     string `json:"title" validate:"required"`
+	Date      string `json:"date" validate:"required"` // Format: YYYY-MM-DD
+	Assignees []struct {
+		Email  string  `json:"email" validate:"required,email"`
+		Weight float64 `json:"weight,omitempty"` // Default 1.0
+	} `json:"assignees" validate:"required,min=1,dive"`
+}
+
+// SetScenarioCapacityRequest is the request body for a hypothetical capacity
+type SetScenarioCapacityRequest struct {
+	EntityID string  `json:"entity_id" validate:"required"`
+	Date     string  `json:"date" validate:"required"` // Format: YYYY-MM-DD
+	Capacity float64 `json:"capacity" validate:"min=0"`
 }
 
 // OTPRequest is the request body for requesting an OTP
@@ -157,6 +1474,218 @@ type WebhookAlertPayload struct {
 	Load        float64   `json:"load"`
 	Capacity    float64   `json:"capacity"`
 	Message     string    `json:"message"`
+
+	Links    *NotificationLinks `json:"links,omitempty"`
+	Metadata *WebhookMetadata   `json:"metadata,omitempty"`
+}
+
+// WebhookGroupOverloadPayload is sent to the webhook destination when a
+// group's summed load on a date goes over the group's capacity
+type WebhookGroupOverloadPayload struct {
+	Event    string                `json:"event"` // "group_overload_alert"
+	GroupID  string                `json:"group_id"`
+	Date     string                `json:"date"` // Format: YYYY-MM-DD
+	Load     float64               `json:"load"`
+	Capacity float64               `json:"capacity"`
+	Members  []GroupOverloadMember `json:"members"` // Members with load on the date, most loaded first
+	Message  string                `json:"message"`
+
+	Metadata *WebhookMetadata `json:"metadata,omitempty"`
+}
+
+// GroupOverloadMember is a member contributing to a group overload, with
+// their own load and capacity on the date
+type GroupOverloadMember struct {
+	Email    string  `json:"email"`
+	Title    string  `json:"title"`
+	Load     float64 `json:"load"`
+	Capacity float64 `json:"capacity"`
+}
+
+// WebhookMetadata identifies what caused a webhook: the W3C trace and span
+// of the request, or background job, it was sent for. The delivery carries
+// the same IDs in its traceparent header and in the webhook_deliveries row.
+type WebhookMetadata struct {
+	TraceID string `json:"trace_id"`
+	SpanID  string `json:"span_id"`
+}
+
+// NotificationLinks are signed links that open what a notification is about
+// without logging in, read-only. Omitted when links are disabled.
+type NotificationLinks struct {
+	Day      string `json:"day"`      // the person's loads on the date
+	Settings string `json:"settings"` // the person's heatmap settings and capacity
+}
+
+// WebhookOffboardingPayload is sent to the webhook destination when a person
+// is offboarded, so their groups' owners can reassign the removed loads
+type WebhookOffboardingPayload struct {
+	Event              string                 `json:"event"` // "person_offboarded"
+	PersonEmail        string                 `json:"person_email"`
+	LastDay            string                 `json:"last_day"`
+	Groups             []string               `json:"groups"`
+	RemovedAssignments []OffboardedAssignment `json:"removed_assignments"`
+	Message            string                 `json:"message"`
+
+	Metadata *WebhookMetadata `json:"metadata,omitempty"`
+}
+
+// WebhookAcknowledgmentReminderPayload is sent to the webhook destination
+// when an assignee has not acknowledged a high-weight load in time
+type WebhookAcknowledgmentReminderPayload struct {
+	Event       string    `json:"event"` // "load_unacknowledged"
+	PersonEmail string    `json:"person_email"`
+	LoadID      int       `json:"load_id"`
+	Title       string    `json:"title"`
+	Date        string    `json:"date"` // Format: YYYY-MM-DD
+	Weight      float64   `json:"weight"`
+	AssignedAt  time.Time `json:"assigned_at"`
+	Message     string    `json:"message"`
+
+	Links    *NotificationLinks `json:"links,omitempty"`
+	Metadata *WebhookMetadata   `json:"metadata,omitempty"`
+}
+
+// WebhookAnomalyPayload is sent to the webhook destination when a person's
+// day load spikes over their trailing average, even under capacity
+type WebhookAnomalyPayload struct {
+	Event       string  `json:"event"` // "anomaly"
+	PersonEmail string  `json:"person_email"`
+	Date        string  `json:"date"` // Format: YYYY-MM-DD
+	Load        float64 `json:"load"`
+	Baseline    float64 `json:"baseline"` // Average load of the days with any in the 30 days before the check
+	Ratio       float64 `json:"ratio"`    // Load over baseline
+	Message     string  `json:"message"`
+
+	Links    *NotificationLinks `json:"links,omitempty"`
+	Metadata *WebhookMetadata   `json:"metadata,omitempty"`
+}
+
+// WebhookLoadDeletedPayload is sent to the webhook destination for each
+// assignee of an upcoming load deleted by its source system, with their load
+// and capacity on that date re-checked without it
+type WebhookLoadDeletedPayload struct {
+	Event       string  `json:"event"` // "load_deleted"
+	PersonEmail string  `json:"person_email"`
+	LoadID      int     `json:"load_id"`
+	ExternalID  string  `json:"external_id"`
+	Title       string  `json:"title"`
+	Date        string  `json:"date"` // Format: YYYY-MM-DD
+	Load        float64 `json:"load"`
+	Capacity    float64 `json:"capacity"`
+	Overloaded  bool    `json:"overloaded"`
+	Message     string  `json:"message"`
+
+	Links    *NotificationLinks `json:"links,omitempty"`
+	Metadata *WebhookMetadata   `json:"metadata,omitempty"`
+}
+
+// WebhookLoadCreatedPayload is sent to webhook endpoints when an upsert
+// creates a load, rather than updating one the source sent before
+type WebhookLoadCreatedPayload struct {
+	Event       string           `json:"event"` // "load_created"
+	LoadID      int              `json:"load_id"`
+	ExternalID  string           `json:"external_id"`
+	Title       string           `json:"title"`
+	Source      string           `json:"source,omitempty"`
+	Date        string           `json:"date"`               // Format: YYYY-MM-DD
+	EndDate     string           `json:"end_date,omitempty"` // Format: YYYY-MM-DD
+	Assignments []LoadAssignment `json:"assignments"`
+	Message     string           `json:"message"`
+
+	Metadata *WebhookMetadata `json:"metadata,omitempty"`
+}
+
+// WebhookScheduleConflictPayload is sent to webhook endpoints when an upsert
+// gives an assignee a load whose time window overlaps others of theirs on an
+// upcoming day
+type WebhookScheduleConflictPayload struct {
+	Event       string             `json:"event"` // "schedule_conflict"
+	PersonEmail string             `json:"person_email"`
+	Date        string             `json:"date"` // Format: YYYY-MM-DD
+	Load        ScheduleConflict   `json:"load"`
+	Conflicts   []ScheduleConflict `json:"conflicts"` // The overlapping loads, by start time
+	Message     string             `json:"message"`
+
+	Links    *NotificationLinks `json:"links,omitempty"`
+	Metadata *WebhookMetadata   `json:"metadata,omitempty"`
+}
+
+// ScheduleConflict is a load in a schedule conflict, with its time window
+type ScheduleConflict struct {
+	LoadID     int    `json:"load_id"`
+	ExternalID string `json:"external_id,omitempty"`
+	Title      string `json:"title"`
+	Start      string `json:"start"` // Format: HH:MM, UTC
+	End        string `json:"end"`   // Format: HH:MM, UTC
+}
+
+// WebhookCapacityChangedPayload is sent to webhook endpoints when a person's
+// capacity changes, once the change applies (after approval, if it needed
+// one)
+type WebhookCapacityChangedPayload struct {
+	Event       string                 `json:"event"` // "capacity_changed"
+	PersonEmail string                 `json:"person_email"`
+	ChangedBy   string                 `json:"changed_by,omitempty"`
+	Change      *UpdateCapacityRequest `json:"change,omitempty"` // Omitted when an override was removed
+	Message     string                 `json:"message"`
+
+	Metadata *WebhookMetadata `json:"metadata,omitempty"`
+}
+
+// Webhook events endpoints can subscribe to
+const (
+	WebhookEventOverload           = "overload_alert"
+	WebhookEventGroupOverload      = "group_overload_alert"
+	WebhookEventLoadCreated        = "load_created"
+	WebhookEventLoadDeleted        = "load_deleted"
+	WebhookEventCapacityChanged    = "capacity_changed"
+	WebhookEventPersonOffboarded   = "person_offboarded"
+	WebhookEventLoadUnacknowledged = "load_unacknowledged"
+	WebhookEventScheduleConflict   = "schedule_conflict"
+	WebhookEventAnomaly            = "anomaly"
+)
+
+// WebhookEvents lists every event a webhook endpoint can subscribe to
+var WebhookEvents = []string{
+	WebhookEventOverload,
+	WebhookEventGroupOverload,
+	WebhookEventLoadCreated,
+	WebhookEventLoadDeleted,
+	WebhookEventCapacityChanged,
+	WebhookEventPersonOffboarded,
+	WebhookEventLoadUnacknowledged,
+	WebhookEventScheduleConflict,
+	WebhookEventAnomaly,
+}
+
+// WebhookEndpoint is a webhook destination and the events it receives
+type WebhookEndpoint struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateWebhookEndpointRequest is the request body for adding a webhook
+// endpoint
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,startswith=http"`
+	Description string   `json:"description,omitempty"`
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=overload_alert group_overload_alert load_created load_deleted capacity_changed person_offboarded load_unacknowledged schedule_conflict anomaly"`
+	Enabled     *bool    `json:"enabled,omitempty"` // Default true
+}
+
+// UpdateWebhookEndpointRequest is the request body for changing a webhook
+// endpoint; omitted fields are kept
+type UpdateWebhookEndpointRequest struct {
+	URL         *string  `json:"url,omitempty" validate:"omitempty,url,startswith=http"`
+	Description *string  `json:"description,omitempty"`
+	Events      []string `json:"events,omitempty" validate:"omitempty,min=1,dive,oneof=overload_alert group_overload_alert load_created load_deleted capacity_changed person_offboarded load_unacknowledged schedule_conflict anomaly"`
+	Enabled     *bool    `json:"enabled,omitempty"`
 }
 
 // AddGroupMemberRequest is the request body for adding a member to a group
@@ -164,6 +1693,11 @@ type AddGroupMemberRequest struct {
 	PersonEmail string `json:"person_email" validate:"required,email"`
 }
 
+// AddGroupOwnerRequest is the request body for adding an owner to a group
+type AddGroupOwnerRequest struct {
+	PersonEmail string `json:"person_email" validate:"required,email"`
+}
+
 // AddAssigneeRequest is the request body for adding assignee(s) to a load
 type AddAssigneeRequest struct {
 	Assignees []struct {
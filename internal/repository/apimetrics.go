@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIMetricsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIMetricsRepository(pool *pgxpool.Pool) *APIMetricsRepository {
+	return &APIMetricsRepository{pool: pool}
+}
+
+// Add adds counts to each API key's day, so servers flushing the same key
+// and day add up
+func (r *APIMetricsRepository) Add(ctx context.Context, metrics []models.APIKeyMetric) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, m := range metrics {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO api_key_metrics (key_name, date, requests, client_errors, server_errors, request_bytes, response_bytes)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)
+			 ON CONFLICT (key_name, date) DO UPDATE SET
+				requests = api_key_metrics.requests + EXCLUDED.requests,
+				client_errors = api_key_metrics.client_errors + EXCLUDED.client_errors,
+				server_errors = api_key_metrics.server_errors + EXCLUDED.server_errors,
+				request_bytes = api_key_metrics.request_bytes + EXCLUDED.request_bytes,
+				response_bytes = api_key_metrics.response_bytes + EXCLUDED.response_bytes`,
+			m.Key, m.Date, m.Requests, m.ClientErrors, m.ServerErrors, m.RequestBytes, m.ResponseBytes)
+		if err != nil {
+			return fmt.Errorf("failed to add API key metrics: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// List returns the API key metrics between from and to, of one key when key
+// is not empty, by date and then most requests first
+func (r *APIMetricsRepository) List(ctx context.Context, from, to time.Time, key string) ([]models.APIKeyMetric, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT key_name, date, requests, client_errors, server_errors, request_bytes, response_bytes
+		 FROM api_key_metrics
+		 WHERE date BETWEEN $1 AND $2 AND ($3 = '' OR key_name = $3)
+		 ORDER BY date, requests DESC, key_name`,
+		from, to, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := []models.APIKeyMetric{}
+	for rows.Next() {
+		var m models.APIKeyMetric
+		var date time.Time
+		if err := rows.Scan(&m.Key, &date, &m.Requests, &m.ClientErrors, &m.ServerErrors, &m.RequestBytes, &m.ResponseBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan API key metrics: %w", err)
+		}
+		m.Date = date.Format("2006-01-02")
+		metrics = append(metrics, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list API key metrics: %w", err)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAutoCreateQuota is returned when creating an upsert's unknown assignees
+// would take its source past the persons it may auto-create per day
+var ErrAutoCreateQuota = errors.New("daily quota of auto-created persons reached")
+
+// AutoCreate says how assignees without an entity are created
+type AutoCreate struct {
+	Capacity   float64 // default capacity of the created persons
+	Source     string  // source of the upsert, whose quota they count against
+	DailyLimit int     // persons Source may create per UTC day, 0 for no limit
+}
+
+// createPersons creates the persons among emails that do not exist yet,
+// titled with their email, and queues them for review. The quota is soft:
+// concurrent upserts from one source may each see room for their persons.
+func createPersons(ctx context.Context, tx pgx.Tx, emails []string, opts AutoCreate) error {
+	// One statement instead of an existence check and insert per
+	// assignee; ON CONFLICT skips the ones that already exist
+	rows, err := tx.Query(ctx,
+		`INSERT INTO entities (id, title, type, default_capacity)
+		 SELECT DISTINCT email, email, 'person', $2
+		 FROM unnest($1::text[]) AS email
+		 ON CONFLICT (id) DO NOTHING
+		 RETURNING id`,
+		emails, opts.Capacity)
+	if err != nil {
+		return fmt.Errorf("failed to create assignees: %w", err)
+	}
+	created, err := scanStrings(rows)
+	if err != nil {
+		return fmt.Errorf("failed to create assignees: %w", err)
+	}
+	if len(created) == 0 {
+		return nil
+	}
+
+	if opts.DailyLimit > 0 {
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		var count int
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM auto_created_persons WHERE source = $1 AND created_at >= $2`,
+			opts.Source, today).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to count auto-created persons: %w", err)
+		}
+		if count+len(created) > opts.DailyLimit {
+			return fmt.Errorf("%w: source %q created %d of %d today, cannot add %s",
+				ErrAutoCreateQuota, opts.Source, count, opts.DailyLimit, strings.Join(created, ", "))
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO auto_created_persons (person_email, source)
+		 SELECT unnest($1::text[]), $2`,
+		created, opts.Source)
+	if err != nil {
+		return fmt.Errorf("failed to queue auto-created persons: %w", err)
+	}
+	return nil
+}
+
+// CreatePersons creates the persons among emails that do not exist yet and
+// queues them for review, as upserts do for unknown assignees
+func (r *EntityRepository) CreatePersons(ctx context.Context, emails []string, opts AutoCreate) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := createPersons(ctx, tx, emails, opts); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Missing returns the IDs among ids that have no entity, sorted
+func (r *EntityRepository) Missing(ctx context.Context, ids []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT i.id FROM unnest($1::text[]) AS i(id)
+		 WHERE NOT EXISTS (SELECT 1 FROM entities WHERE id = i.id)`,
+		ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check entities: %w", err)
+	}
+	missing, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan entity: %w", err)
+	}
+	return missing, nil
+}
+
+// ListAutoCreated returns the auto-created persons not yet confirmed, oldest
+// first, with how many loads they are assigned
+func (r *EntityRepository) ListAutoCreated(ctx context.Context) ([]models.AutoCreatedPerson, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT a.person_email, a.source, a.created_at,
+		        (SELECT COUNT(*) FROM load_assignments la WHERE la.person_email = a.person_email)
+		 FROM auto_created_persons a
+		 WHERE a.confirmed_at IS NULL
+		 ORDER BY a.created_at, a.person_email`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-created persons: %w", err)
+	}
+	defer rows.Close()
+
+	persons := []models.AutoCreatedPerson{}
+	for rows.Next() {
+		var p models.AutoCreatedPerson
+		if err := rows.Scan(&p.Email, &p.Source, &p.CreatedAt, &p.Loads); err != nil {
+			return nil, fmt.Errorf("failed to scan auto-created person: %w", err)
+		}
+		persons = append(persons, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read auto-created persons: %w", err)
+	}
+
+	return persons, nil
+}
+
+// ConfirmAutoCreated takes the given persons off the review queue, keeping
+// them, and returns those that were still waiting for review
+func (r *EntityRepository) ConfirmAutoCreated(ctx context.Context, emails []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE auto_created_persons SET confirmed_at = NOW()
+		 WHERE person_email = ANY($1) AND confirmed_at IS NULL
+		 RETURNING person_email`,
+		emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm auto-created persons: %w", err)
+	}
+	confirmed, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan confirmed person: %w", err)
+	}
+	return confirmed, nil
+}
+
+// DeleteAutoCreated deletes the given persons that are still waiting for
+// review, with their load assignments, and returns those deleted
+func (r *EntityRepository) DeleteAutoCreated(ctx context.Context, emails []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`DELETE FROM entities
+		 WHERE id IN (
+		   SELECT person_email FROM auto_created_persons
+		   WHERE person_email = ANY($1) AND confirmed_at IS NULL
+		 )
+		 RETURNING id`,
+		emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete auto-created persons: %w", err)
+	}
+	deleted, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan deleted person: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrBlackoutNotFound = errors.New("blackout not found")
+
+type BlackoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBlackoutRepository(pool *pgxpool.Pool) *BlackoutRepository {
+	return &BlackoutRepository{pool: pool}
+}
+
+// Create stores a blackout for an entity
+func (r *BlackoutRepository) Create(ctx context.Context, entityID string, start, end time.Time, reason string) (*models.BlackoutDate, error) {
+	b := &models.BlackoutDate{
+		EntityID:  entityID,
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Reason:    reason,
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO blackout_dates (entity_id, start_date, end_date, reason)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		entityID, start, end, reason).Scan(&b.ID, &b.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blackout: %w", err)
+	}
+
+	return b, nil
+}
+
+// List returns an entity's blackouts that have not ended before from, in
+// date order
+func (r *BlackoutRepository) List(ctx context.Context, entityID string, from time.Time) ([]models.BlackoutDate, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, entity_id, start_date, end_date, reason, created_at
+		 FROM blackout_dates
+		 WHERE entity_id = $1 AND end_date >= $2
+		 ORDER BY start_date, id`, entityID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blackouts: %w", err)
+	}
+	defer rows.Close()
+
+	blackouts := []models.BlackoutDate{}
+	for rows.Next() {
+		var b models.BlackoutDate
+		var start, end time.Time
+		if err := rows.Scan(&b.ID, &b.EntityID, &start, &end, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan blackout: %w", err)
+		}
+		b.StartDate = start.Format("2006-01-02")
+		b.EndDate = end.Format("2006-01-02")
+		blackouts = append(blackouts, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blackouts: %w", err)
+	}
+
+	return blackouts, nil
+}
+
+// Delete removes one of an entity's blackouts
+func (r *BlackoutRepository) Delete(ctx context.Context, entityID string, id int) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM blackout_dates WHERE entity_id = $1 AND id = $2`, entityID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete blackout: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrBlackoutNotFound
+	}
+
+	return nil
+}
+
+// FindConflicts returns the blackouts, their own or their groups', that
+// cover any day of a load for the given people, each dated on the first day
+// it covers. The load covers each of its starts, one per occurrence of a
+// recurring load, and the span days after it. People the load with
+// externalID already assigns on one of the blackout's days are left out, so
+// re-syncing a load is never blocked by a blackout declared after it was
+// assigned.
+func (r *BlackoutRepository) FindConflicts(ctx context.Context, externalID string, starts []time.Time, span int, emails []string) ([]models.BlackoutConflict, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT p.email, b.entity_id, b.reason, b.id, MIN(GREATEST(b.start_date, o.start))
+		 FROM unnest($4::text[]) AS p(email)
+		 CROSS JOIN unnest($2::date[]) AS o(start)
+		 JOIN blackout_dates b ON b.start_date <= o.start + $3::int AND b.end_date >= o.start AND (
+		   b.entity_id = p.email OR b.entity_id IN (
+		     SELECT group_id FROM group_members WHERE person_email = p.email))
+		 WHERE NOT EXISTS (
+		   SELECT 1 FROM load_days ld
+		   JOIN loads l ON l.id = ld.load_id
+		   JOIN load_assignments la ON la.load_id = l.id
+		   WHERE l.external_id = $1 AND ld.date BETWEEN b.start_date AND b.end_date
+		     AND la.person_email = p.email)
+		 GROUP BY p.email, b.entity_id, b.reason, b.id
+		 ORDER BY p.email, b.id`,
+		externalID, starts, span, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blackout conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []models.BlackoutConflict
+	for rows.Next() {
+		var c models.BlackoutConflict
+		var id int
+		var day time.Time
+		if err := rows.Scan(&c.PersonEmail, &c.EntityID, &c.Reason, &id, &day); err != nil {
+			return nil, fmt.Errorf("failed to scan blackout conflict: %w", err)
+		}
+		c.Date = day.Format("2006-01-02")
+		conflicts = append(conflicts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blackout conflicts: %w", err)
+	}
+
+	return conflicts, nil
+}
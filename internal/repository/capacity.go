@@ -11,6 +11,11 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrChangeRequestNotFound = errors.New("capacity change request not found")
+	ErrChangeRequestDecided  = errors.New("capacity change request already decided")
+)
+
 type CapacityRepository struct {
 	pool *pgxpool.Pool
 }
@@ -91,32 +96,47 @@ func (r *CapacityRepository) DeleteOverride(ctx context.Context, entityID string
 	return nil
 }
 
+// DeleteOverridesRange removes an entity's capacity overrides from start to
+// end inclusive and returns how many were removed
+func (r *CapacityRepository) DeleteOverridesRange(ctx context.Context, entityID string, start, end time.Time) (int, error) {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM capacity_overrides WHERE entity_id = $1 AND date BETWEEN $2 AND $3`,
+		entityID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete capacity overrides: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// effectiveCapacity returns the SQL for the effective capacity of entity e on
+// day, a date expression: none on a holiday of its region, otherwise its
+// override, then its weekly pattern, otherwise its default. Queries reading a
+// day's capacity use it so they agree with the heatmap.
+func effectiveCapacity(day string) string {
+	return `CASE WHEN EXISTS (SELECT 1 FROM holidays h WHERE h.region = e.region AND h.date = ` + day + `) THEN 0
+		ELSE COALESCE(
+			(SELECT co.capacity FROM capacity_overrides co WHERE co.entity_id = e.id AND co.date = ` + day + `),
+			(SELECT wc.capacity FROM weekly_capacity wc WHERE wc.entity_id = e.id AND wc.weekday = EXTRACT(ISODOW FROM ` + day + `)),
+			e.default_capacity) END`
+}
+
 // GetEffectiveCapacity returns the effective capacity for an entity on a date
-// (override if exists, otherwise default)
+// (none on its region's holidays, otherwise override if exists, then the
+// weekly pattern, otherwise default)
 func (r *CapacityRepository) GetEffectiveCapacity(ctx context.Context, entityID string, date time.Time) (float64, error) {
-	// First try to get override
 	var capacity float64
 	err := r.pool.QueryRow(ctx,
-		`SELECT capacity FROM capacity_overrides WHERE entity_id = $1 AND date = $2`,
+		`SELECT `+effectiveCapacity("$2::date")+`
+		 FROM entities e
+		 WHERE e.id = $1`,
 		entityID, date.Truncate(24*time.Hour)).Scan(&capacity)
 
-	if err == nil {
-		return capacity, nil
-	}
-
-	if !errors.Is(err, pgx.ErrNoRows) {
-		return 0, fmt.Errorf("failed to get override: %w", err)
-	}
-
-	// Fall back to default capacity
-	err = r.pool.QueryRow(ctx,
-		`SELECT default_capacity FROM entities WHERE id = $1`, entityID).Scan(&capacity)
-
 	if errors.Is(err, pgx.ErrNoRows) {
 		return 0, ErrEntityNotFound
 	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get default capacity: %w", err)
+		return 0, fmt.Errorf("failed to get effective capacity: %w", err)
 	}
 
 	return capacity, nil
@@ -132,11 +152,20 @@ func (r *CapacityRepository) GetCapacitiesForRange(ctx context.Context, entityID
 		return nil, fmt.Errorf("failed to get default capacity: %w", err)
 	}
 
-	// Initialize map with default capacity for all days (use UTC)
+	pattern, err := r.GetWeeklyPattern(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize map with the weekly pattern or default capacity for all days (use UTC)
 	capacities := make(map[time.Time]float64)
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
 		normalizedDate := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
-		capacities[normalizedDate] = defaultCapacity
+		capacity, ok := pattern[normalizedDate.Weekday()]
+		if !ok {
+			capacity = defaultCapacity
+		}
+		capacities[normalizedDate] = capacity
 	}
 
 	// Get overrides and apply them
@@ -152,3 +181,319 @@ func (r *CapacityRepository) GetCapacitiesForRange(ctx context.Context, entityID
 
 	return capacities, nil
 }
+
+// GetTotalCapacitiesForRange returns a map of date -> the summed effective
+// capacity of a set of entities, with none on their region's holidays
+func (r *CapacityRepository) GetTotalCapacitiesForRange(ctx context.Context, entityIDs []string, start, end time.Time) (map[time.Time]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d::date, SUM(`+effectiveCapacity("d::date")+`)
+		 FROM entities e
+		 CROSS JOIN generate_series($2::date, $3::date, INTERVAL '1 day') d
+		 WHERE e.id = ANY($1)
+		 GROUP BY d`,
+		entityIDs, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total capacities: %w", err)
+	}
+	defer rows.Close()
+
+	capacities := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var capacity float64
+		if err := rows.Scan(&date, &capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity: %w", err)
+		}
+		normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		capacities[normalizedDate] = capacity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read total capacities: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// GetGroupCapacitiesForRange returns a map of date -> capacity for a group,
+// by its capacity mode: its own capacities, or the summed capacities of its
+// members as GetMemberCapacitiesForRange returns them, leaving out excluded
+func (r *CapacityRepository) GetGroupCapacitiesForRange(ctx context.Context, groupID string, start, end time.Time, excluded []string) (map[time.Time]float64, error) {
+	var mode string
+	err := r.pool.QueryRow(ctx,
+		`SELECT capacity_mode FROM entities WHERE id = $1`, groupID).Scan(&mode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacity mode: %w", err)
+	}
+
+	if mode == models.CapacityModeMembers {
+		return r.GetMemberCapacitiesForRange(ctx, groupID, start, end, excluded)
+	}
+	return r.GetCapacitiesForRange(ctx, groupID, start, end)
+}
+
+// GetMemberCapacitiesForRange returns a map of date -> the summed effective
+// capacity of a group's active members. Members excluded from the group's
+// heatmap, and those in excluded (lowercase emails), do not count, and
+// members have no capacity on their region's holidays. Days without members
+// are left out.
+func (r *CapacityRepository) GetMemberCapacitiesForRange(ctx context.Context, groupID string, start, end time.Time, excluded []string) (map[time.Time]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d::date, SUM(`+effectiveCapacity("d::date")+`)
+		 FROM group_members gm
+		 JOIN entities e ON e.id = gm.person_email
+		 CROSS JOIN generate_series($2::date, $3::date, INTERVAL '1 day') d
+		 WHERE gm.group_id = $1 AND e.archived_at IS NULL
+		   AND NOT gm.heatmap_excluded AND lower(gm.person_email) <> ALL($4)
+		 GROUP BY d`,
+		groupID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour), sourceList(excluded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member capacities: %w", err)
+	}
+	defer rows.Close()
+
+	capacities := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var capacity float64
+		if err := rows.Scan(&date, &capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity: %w", err)
+		}
+		normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		capacities[normalizedDate] = capacity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read member capacities: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// GetWeeklyPattern returns an entity's capacity for each weekday it set one
+// for
+func (r *CapacityRepository) GetWeeklyPattern(ctx context.Context, entityID string) (map[time.Weekday]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT weekday, capacity FROM weekly_capacity WHERE entity_id = $1`, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly capacity: %w", err)
+	}
+	defer rows.Close()
+
+	pattern := make(map[time.Weekday]float64)
+	for rows.Next() {
+		var isoWeekday int
+		var capacity float64
+		if err := rows.Scan(&isoWeekday, &capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly capacity: %w", err)
+		}
+		pattern[time.Weekday(isoWeekday%7)] = capacity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read weekly capacity: %w", err)
+	}
+
+	return pattern, nil
+}
+
+// SetWeekdayCapacity creates or updates an entity's capacity on a weekday
+func (r *CapacityRepository) SetWeekdayCapacity(ctx context.Context, entityID string, weekday time.Weekday, capacity float64) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO weekly_capacity (entity_id, weekday, capacity)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (entity_id, weekday) DO UPDATE SET capacity = EXCLUDED.capacity`,
+		entityID, isoWeekday(weekday), capacity)
+	if err != nil {
+		return fmt.Errorf("failed to set weekly capacity: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWeekdayCapacity removes an entity's capacity on a weekday, so its
+// default capacity applies again
+func (r *CapacityRepository) DeleteWeekdayCapacity(ctx context.Context, entityID string, weekday time.Weekday) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM weekly_capacity WHERE entity_id = $1 AND weekday = $2`,
+		entityID, isoWeekday(weekday))
+	if err != nil {
+		return fmt.Errorf("failed to delete weekly capacity: %w", err)
+	}
+
+	return nil
+}
+
+// isoWeekday numbers weekdays from 1 (Monday) to 7 (Sunday), as stored in
+// weekly_capacity
+func isoWeekday(weekday time.Weekday) int {
+	if weekday == time.Sunday {
+		return 7
+	}
+	return int(weekday)
+}
+
+// changeRequestColumns are the capacity_change_requests columns scanned by
+// scanChangeRequest
+const changeRequestColumns = `id, entity_id, change, reason, status, requested_at, decided_by, decided_at`
+
+// CreateChangeRequest stores a capacity change to hold for approval
+func (r *CapacityRepository) CreateChangeRequest(ctx context.Context, req *models.CapacityChangeRequest) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO capacity_change_requests (entity_id, change, reason)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, status, requested_at`,
+		req.EntityID, req.Change, req.Reason).Scan(&req.ID, &req.Status, &req.RequestedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create capacity change request: %w", err)
+	}
+
+	return nil
+}
+
+// GetChangeRequest retrieves a capacity change request by ID
+func (r *CapacityRepository) GetChangeRequest(ctx context.Context, id int) (*models.CapacityChangeRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+changeRequestColumns+` FROM capacity_change_requests WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacity change request: %w", err)
+	}
+	requests, err := scanChangeRequests(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, ErrChangeRequestNotFound
+	}
+
+	return &requests[0], nil
+}
+
+// ListPendingChangeRequests returns an entity's capacity changes awaiting
+// approval, oldest first
+func (r *CapacityRepository) ListPendingChangeRequests(ctx context.Context, entityID string) ([]models.CapacityChangeRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+changeRequestColumns+` FROM capacity_change_requests
+		 WHERE entity_id = $1 AND status = 'pending'
+		 ORDER BY requested_at, id`, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacity change requests: %w", err)
+	}
+	return scanChangeRequests(rows)
+}
+
+// ListPendingApprovals returns the capacity changes awaiting approval from
+// members of the groups a person owns, oldest first
+func (r *CapacityRepository) ListPendingApprovals(ctx context.Context, approverEmail string) ([]models.CapacityChangeRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+changeRequestColumns+` FROM capacity_change_requests ccr
+		 WHERE status = 'pending' AND entity_id <> $1 AND EXISTS (
+		   SELECT 1 FROM group_members gm
+		   JOIN group_owners o ON o.group_id = gm.group_id
+		   WHERE gm.person_email = ccr.entity_id AND o.person_email = $1
+		 )
+		 ORDER BY requested_at, id`, approverEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacity approvals: %w", err)
+	}
+	return scanChangeRequests(rows)
+}
+
+// DecideChangeRequest records an approval or rejection of a pending capacity
+// change request. It fails with ErrChangeRequestDecided once decided, so a
+// change is applied at most once.
+func (r *CapacityRepository) DecideChangeRequest(ctx context.Context, id int, status models.CapacityChangeStatus, decidedBy string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE capacity_change_requests
+		 SET status = $2, decided_by = $3, decided_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`,
+		id, status, decidedBy)
+	if err != nil {
+		return fmt.Errorf("failed to decide capacity change request: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetChangeRequest(ctx, id); err != nil {
+			return err
+		}
+		return ErrChangeRequestDecided
+	}
+
+	return nil
+}
+
+// ReopenChangeRequest returns a decided request to pending, for when applying
+// an approved change fails
+func (r *CapacityRepository) ReopenChangeRequest(ctx context.Context, id int) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE capacity_change_requests
+		 SET status = 'pending', decided_by = NULL, decided_at = NULL
+		 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reopen capacity change request: %w", err)
+	}
+
+	return nil
+}
+
+// scanChangeRequests reads and closes rows selected with changeRequestColumns
+func scanChangeRequests(rows pgx.Rows) ([]models.CapacityChangeRequest, error) {
+	defer rows.Close()
+
+	requests := []models.CapacityChangeRequest{}
+	for rows.Next() {
+		var req models.CapacityChangeRequest
+		if err := rows.Scan(&req.ID, &req.EntityID, &req.Change, &req.Reason, &req.Status,
+			&req.RequestedAt, &req.DecidedBy, &req.DecidedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity change request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read capacity change requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// AddAuditEntry records a change to a person's capacity
+func (r *CapacityRepository) AddAuditEntry(ctx context.Context, entry *models.CapacityAuditEntry) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO capacity_audit_log (person_email, actor_email, action, detail)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		entry.PersonEmail, entry.ActorEmail, entry.Action, entry.Detail).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record capacity audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEntries returns a person's most recent capacity audit entries,
+// newest first
+func (r *CapacityRepository) ListAuditEntries(ctx context.Context, personEmail string, limit int) ([]models.CapacityAuditEntry, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, person_email, actor_email, action, detail, created_at
+		 FROM capacity_audit_log
+		 WHERE person_email = $1
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $2`, personEmail, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacity audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.CapacityAuditEntry{}
+	for rows.Next() {
+		var e models.CapacityAuditEntry
+		if err := rows.Scan(&e.ID, &e.PersonEmail, &e.ActorEmail, &e.Action, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read capacity audit entries: %w", err)
+	}
+
+	return entries, nil
+}
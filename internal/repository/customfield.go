@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrCustomFieldNotFound = errors.New("custom field not found")
+
+type CustomFieldRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCustomFieldRepository(pool *pgxpool.Pool) *CustomFieldRepository {
+	return &CustomFieldRepository{pool: pool}
+}
+
+const customFieldColumns = `source, key, label, type, display, position, created_at, updated_at`
+
+func scanCustomField(row pgx.Row) (*models.CustomField, error) {
+	var f models.CustomField
+	if err := row.Scan(&f.Source, &f.Key, &f.Label, &f.Type, &f.Display, &f.Position, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List returns the custom fields registered for a source, or for every
+// source when source is empty, ordered for display
+func (r *CustomFieldRepository) List(ctx context.Context, source string) ([]models.CustomField, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+customFieldColumns+` FROM custom_field_definitions
+		 WHERE $1 = '' OR source = $1
+		 ORDER BY source, position, key`, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	return collectCustomFields(rows)
+}
+
+// ListBySources returns the custom fields registered for any of sources
+func (r *CustomFieldRepository) ListBySources(ctx context.Context, sources []string) ([]models.CustomField, error) {
+	if len(sources) == 0 {
+		return []models.CustomField{}, nil
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+customFieldColumns+` FROM custom_field_definitions
+		 WHERE source = ANY($1)
+		 ORDER BY source, position, key`, sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	return collectCustomFields(rows)
+}
+
+func collectCustomFields(rows pgx.Rows) ([]models.CustomField, error) {
+	defer rows.Close()
+
+	fields := []models.CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %w", err)
+		}
+		fields = append(fields, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read custom fields: %w", err)
+	}
+
+	return fields, nil
+}
+
+// Put registers a custom field for a source, or replaces its definition
+func (r *CustomFieldRepository) Put(ctx context.Context, source, key string, req *models.PutCustomFieldRequest) (*models.CustomField, error) {
+	f, err := scanCustomField(r.pool.QueryRow(ctx,
+		`INSERT INTO custom_field_definitions (source, key, label, type, display, position)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (source, key) DO UPDATE SET
+		   label = EXCLUDED.label,
+		   type = EXCLUDED.type,
+		   display = EXCLUDED.display,
+		   position = EXCLUDED.position,
+		   updated_at = NOW()
+		 RETURNING `+customFieldColumns,
+		source, key, req.Label, string(req.Type), req.Display, req.Position))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save custom field: %w", err)
+	}
+	return f, nil
+}
+
+// Delete unregisters a custom field. Values already stored on loads are
+// kept, but no longer checked or displayed.
+func (r *CustomFieldRepository) Delete(ctx context.Context, source, key string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM custom_field_definitions WHERE source = $1 AND key = $2`, source, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCustomFieldNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrDelegationNotFound = errors.New("delegation not found")
+
+type DelegationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDelegationRepository(pool *pgxpool.Pool) *DelegationRepository {
+	return &DelegationRepository{pool: pool}
+}
+
+// Add lets an assistant manage a person's capacity. Adding an existing
+// delegation keeps its original creation time.
+func (r *DelegationRepository) Add(ctx context.Context, personEmail, assistantEmail string) (*models.Delegation, error) {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO delegations (person_email, assistant_email)
+		 VALUES ($1, $2)
+		 ON CONFLICT (person_email, assistant_email) DO NOTHING`,
+		personEmail, assistantEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add delegation: %w", err)
+	}
+
+	d := &models.Delegation{PersonEmail: personEmail, AssistantEmail: assistantEmail}
+	err = r.pool.QueryRow(ctx,
+		`SELECT created_at FROM delegations WHERE person_email = $1 AND assistant_email = $2`,
+		personEmail, assistantEmail).Scan(&d.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation: %w", err)
+	}
+
+	return d, nil
+}
+
+// Remove stops an assistant managing a person's capacity
+func (r *DelegationRepository) Remove(ctx context.Context, personEmail, assistantEmail string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM delegations WHERE person_email = $1 AND assistant_email = $2`,
+		personEmail, assistantEmail)
+	if err != nil {
+		return fmt.Errorf("failed to remove delegation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDelegationNotFound
+	}
+
+	return nil
+}
+
+// IsDelegate checks if an assistant may manage a person's capacity. Archived
+// people can neither delegate nor act as assistants.
+func (r *DelegationRepository) IsDelegate(ctx context.Context, personEmail, assistantEmail string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (
+		   SELECT 1 FROM delegations d
+		   JOIN entities p ON p.id = d.person_email AND p.archived_at IS NULL
+		   JOIN entities a ON a.id = d.assistant_email AND a.archived_at IS NULL
+		   WHERE d.person_email = $1 AND d.assistant_email = $2
+		 )`, personEmail, assistantEmail).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delegation: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListAssistants returns the people who manage a person's capacity
+func (r *DelegationRepository) ListAssistants(ctx context.Context, personEmail string) ([]models.Delegation, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d.person_email, d.assistant_email, d.created_at
+		 FROM delegations d
+		 JOIN entities a ON a.id = d.assistant_email AND a.archived_at IS NULL
+		 WHERE d.person_email = $1
+		 ORDER BY d.assistant_email`, personEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assistants: %w", err)
+	}
+	return scanDelegations(rows)
+}
+
+// ListDelegators returns the people whose capacity an assistant manages
+func (r *DelegationRepository) ListDelegators(ctx context.Context, assistantEmail string) ([]models.Delegation, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d.person_email, d.assistant_email, d.created_at
+		 FROM delegations d
+		 JOIN entities p ON p.id = d.person_email AND p.archived_at IS NULL
+		 WHERE d.assistant_email = $1
+		 ORDER BY d.person_email`, assistantEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegators: %w", err)
+	}
+	return scanDelegations(rows)
+}
+
+// scanDelegations reads and closes rows of person, assistant and creation time
+func scanDelegations(rows pgx.Rows) ([]models.Delegation, error) {
+	defer rows.Close()
+
+	delegations := []models.Delegation{}
+	for rows.Next() {
+		var d models.Delegation
+		if err := rows.Scan(&d.PersonEmail, &d.AssistantEmail, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delegation: %w", err)
+		}
+		delegations = append(delegations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read delegations: %w", err)
+	}
+
+	return delegations, nil
+}
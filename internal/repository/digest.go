@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrLarkDigestNotFound = errors.New("lark digest not found")
+
+type DigestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDigestRepository(pool *pgxpool.Pool) *DigestRepository {
+	return &DigestRepository{pool: pool}
+}
+
+const larkDigestColumns = `group_id, chat_id, enabled, last_sent_on, created_at, updated_at`
+
+func scanLarkDigest(row pgx.Row) (*models.LarkDigest, error) {
+	var d models.LarkDigest
+	if err := row.Scan(&d.GroupID, &d.ChatID, &d.Enabled, &d.LastSentOn, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Get returns a group's Lark digest
+func (r *DigestRepository) Get(ctx context.Context, groupID string) (*models.LarkDigest, error) {
+	d, err := scanLarkDigest(r.pool.QueryRow(ctx,
+		`SELECT `+larkDigestColumns+` FROM lark_digests WHERE group_id = $1`, groupID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLarkDigestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lark digest: %w", err)
+	}
+	return d, nil
+}
+
+// ListEnabled returns the digests to post, by group
+func (r *DigestRepository) ListEnabled(ctx context.Context) ([]models.LarkDigest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+larkDigestColumns+` FROM lark_digests WHERE enabled ORDER BY group_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lark digests: %w", err)
+	}
+	defer rows.Close()
+
+	digests := []models.LarkDigest{}
+	for rows.Next() {
+		d, err := scanLarkDigest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lark digest: %w", err)
+		}
+		digests = append(digests, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lark digests: %w", err)
+	}
+
+	return digests, nil
+}
+
+// Put sets the chat a group's digest is posted to, and whether it is
+func (r *DigestRepository) Put(ctx context.Context, groupID, chatID string, enabled bool) (*models.LarkDigest, error) {
+	d, err := scanLarkDigest(r.pool.QueryRow(ctx,
+		`INSERT INTO lark_digests (group_id, chat_id, enabled)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (group_id) DO UPDATE SET
+		   chat_id = EXCLUDED.chat_id,
+		   enabled = EXCLUDED.enabled,
+		   updated_at = NOW()
+		 RETURNING `+larkDigestColumns,
+		groupID, chatID, enabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save lark digest: %w", err)
+	}
+	return d, nil
+}
+
+// Delete stops posting a group's digest
+func (r *DigestRepository) Delete(ctx context.Context, groupID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM lark_digests WHERE group_id = $1`, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to delete lark digest: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLarkDigestNotFound
+	}
+	return nil
+}
+
+// Claim marks a group's digest as posted for the week starting on monday. It
+// reports false when it already was, by this server or another.
+func (r *DigestRepository) Claim(ctx context.Context, groupID string, monday time.Time) (bool, error) {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE lark_digests SET last_sent_on = $2
+		 WHERE group_id = $1 AND enabled AND (last_sent_on IS NULL OR last_sent_on < $2)`,
+		groupID, monday)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim lark digest: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
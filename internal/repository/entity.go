@@ -4,13 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var ErrEntityNotFound = errors.New("entity not found")
+var (
+	ErrEntityNotFound = errors.New("entity not found")
+	ErrEntityExists   = errors.New("entity already exists")
+	ErrEntityArchived = errors.New("entity is archived")
+	ErrNotArchived    = errors.New("entity is not archived")
+	ErrNotAPerson     = errors.New("entity is not a person")
+	ErrNotAGroup      = errors.New("entity is not a group")
+	ErrGroupNotFound  = errors.New("group not found")
+)
 
 type EntityRepository struct {
 	pool *pgxpool.Pool
@@ -24,9 +35,9 @@ func NewEntityRepository(pool *pgxpool.Pool) *EntityRepository {
 func (r *EntityRepository) GetByID(ctx context.Context, id string) (*models.Entity, error) {
 	entity := &models.Entity{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, title, type, employee_id, default_capacity, created_at
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
 		 FROM entities WHERE id = $1`, id).Scan(
-		&entity.ID, &entity.Title, &entity.Type, &entity.EmployeeID, &entity.DefaultCapacity, &entity.CreatedAt)
+		&entity.ID, &entity.Title, &entity.Type, &entity.EmployeeID, &entity.DefaultCapacity, &entity.Skills, &entity.ManagerEmail, &entity.Private, &entity.Region, &entity.CapacityMode, &entity.CreatedAt, &entity.ArchivedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrEntityNotFound
@@ -42,9 +53,9 @@ func (r *EntityRepository) GetByID(ctx context.Context, id string) (*models.Enti
 func (r *EntityRepository) GetByEmployeeID(ctx context.Context, employeeID string) (*models.Entity, error) {
 	entity := &models.Entity{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, title, type, employee_id, default_capacity, created_at
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
 		 FROM entities WHERE employee_id = $1`, employeeID).Scan(
-		&entity.ID, &entity.Title, &entity.Type, &entity.EmployeeID, &entity.DefaultCapacity, &entity.CreatedAt)
+		&entity.ID, &entity.Title, &entity.Type, &entity.EmployeeID, &entity.DefaultCapacity, &entity.Skills, &entity.ManagerEmail, &entity.Private, &entity.Region, &entity.CapacityMode, &entity.CreatedAt, &entity.ArchivedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrEntityNotFound
@@ -56,13 +67,23 @@ func (r *EntityRepository) GetByEmployeeID(ctx context.Context, employeeID strin
 	return entity, nil
 }
 
-// Create creates a new entity
+// Create creates a new entity, or fails with ErrEntityExists when an entity
+// with its ID exists, archived or not
 func (r *EntityRepository) Create(ctx context.Context, entity *models.Entity) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO entities (id, title, type, employee_id, default_capacity)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		entity.ID, entity.Title, entity.Type, entity.EmployeeID, entity.DefaultCapacity)
-
+	entity.Skills = normalizeSkills(entity.Skills)
+	if entity.CapacityMode == "" {
+		entity.CapacityMode = models.CapacityModeStatic
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO entities (id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING created_at`,
+		entity.ID, entity.Title, entity.Type, entity.EmployeeID, entity.DefaultCapacity, entity.Skills, entity.ManagerEmail, entity.Private, entity.Region, entity.CapacityMode).Scan(&entity.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrEntityExists
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create entity: %w", err)
 	}
@@ -72,9 +93,13 @@ func (r *EntityRepository) Create(ctx context.Context, entity *models.Entity) er
 
 // Update updates an existing entity
 func (r *EntityRepository) Update(ctx context.Context, entity *models.Entity) error {
+	entity.Skills = normalizeSkills(entity.Skills)
+	if entity.CapacityMode == "" {
+		entity.CapacityMode = models.CapacityModeStatic
+	}
 	result, err := r.pool.Exec(ctx,
-		`UPDATE entities SET title = $2, employee_id = $3, default_capacity = $4 WHERE id = $1`,
-		entity.ID, entity.Title, entity.EmployeeID, entity.DefaultCapacity)
+		`UPDATE entities SET title = $2, employee_id = $3, default_capacity = $4, skills = $5, manager_email = $6, private = $7, region = $8, capacity_mode = $9 WHERE id = $1`,
+		entity.ID, entity.Title, entity.EmployeeID, entity.DefaultCapacity, entity.Skills, entity.ManagerEmail, entity.Private, entity.Region, entity.CapacityMode)
 
 	if err != nil {
 		return fmt.Errorf("failed to update entity: %w", err)
@@ -104,11 +129,149 @@ func (r *EntityRepository) UpdateDefaultCapacity(ctx context.Context, id string,
 	return nil
 }
 
-// ListPersons returns all person entities
+// GetWeekStart returns the weekday a person's heatmap weeks start on, and
+// false when they have not chosen one
+func (r *EntityRepository) GetWeekStart(ctx context.Context, id string) (time.Weekday, bool, error) {
+	var weekStart *int
+	err := r.pool.QueryRow(ctx,
+		`SELECT week_start FROM entities WHERE id = $1 AND type = 'person'`, id).Scan(&weekStart)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, ErrEntityNotFound
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get week start: %w", err)
+	}
+	if weekStart == nil {
+		return 0, false, nil
+	}
+
+	return time.Weekday(*weekStart % 7), true, nil
+}
+
+// SetWeekStart sets the weekday a person's heatmap weeks start on, or clears
+// it when weekStart is nil
+func (r *EntityRepository) SetWeekStart(ctx context.Context, id string, weekStart *time.Weekday) error {
+	var iso *int
+	if weekStart != nil {
+		day := isoWeekday(*weekStart)
+		iso = &day
+	}
+
+	result, err := r.pool.Exec(ctx,
+		`UPDATE entities SET week_start = $2 WHERE id = $1 AND type = 'person'`,
+		id, iso)
+
+	if err != nil {
+		return fmt.Errorf("failed to set week start: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// IsPrivate reports whether an entity's heatmap is private. Unknown
+// entities are not.
+func (r *EntityRepository) IsPrivate(ctx context.Context, id string) (bool, error) {
+	var private bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT private FROM entities WHERE id = $1`, id).Scan(&private)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check private: %w", err)
+	}
+
+	return private, nil
+}
+
+// ListPrivate returns which of the given entities are private
+func (r *EntityRepository) ListPrivate(ctx context.Context, ids []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id FROM entities WHERE id = ANY($1) AND private`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list private entities: %w", err)
+	}
+	defer rows.Close()
+
+	var private []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		private = append(private, id)
+	}
+
+	return private, nil
+}
+
+// SetPrivate makes a person's heatmap private or public again
+func (r *EntityRepository) SetPrivate(ctx context.Context, id string, private bool) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE entities SET private = $2 WHERE id = $1 AND type = 'person'`,
+		id, private)
+
+	if err != nil {
+		return fmt.Errorf("failed to set private: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// GetHiddenSources returns the load sources a person leaves out of the
+// heatmaps they view
+func (r *EntityRepository) GetHiddenSources(ctx context.Context, id string) ([]string, error) {
+	var sources []string
+	err := r.pool.QueryRow(ctx,
+		`SELECT hidden_sources FROM entities WHERE id = $1 AND type = 'person'`, id).Scan(&sources)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hidden sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// SetHiddenSources replaces the load sources a person leaves out of the
+// heatmaps they view
+func (r *EntityRepository) SetHiddenSources(ctx context.Context, id string, sources []string) error {
+	if sources == nil {
+		sources = []string{}
+	}
+
+	result, err := r.pool.Exec(ctx,
+		`UPDATE entities SET hidden_sources = $2 WHERE id = $1 AND type = 'person'`,
+		id, sources)
+
+	if err != nil {
+		return fmt.Errorf("failed to set hidden sources: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// ListPersons returns all person entities that are not archived
 func (r *EntityRepository) ListPersons(ctx context.Context) ([]models.Entity, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, title, type, employee_id, default_capacity, created_at
-		 FROM entities WHERE type = 'person' ORDER BY title`)
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
+		 FROM entities WHERE type = 'person' AND archived_at IS NULL ORDER BY title`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list persons: %w", err)
 	}
@@ -117,7 +280,7 @@ func (r *EntityRepository) ListPersons(ctx context.Context) ([]models.Entity, er
 	var entities []models.Entity
 	for rows.Next() {
 		var e models.Entity
-		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.CreatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.Skills, &e.ManagerEmail, &e.Private, &e.Region, &e.CapacityMode, &e.CreatedAt, &e.ArchivedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan entity: %w", err)
 		}
 		entities = append(entities, e)
@@ -126,11 +289,11 @@ func (r *EntityRepository) ListPersons(ctx context.Context) ([]models.Entity, er
 	return entities, nil
 }
 
-// ListGroups returns all group entities
+// ListGroups returns all group entities that are not archived
 func (r *EntityRepository) ListGroups(ctx context.Context) ([]models.Entity, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, title, type, employee_id, default_capacity, created_at
-		 FROM entities WHERE type = 'group' ORDER BY title`)
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
+		 FROM entities WHERE type = 'group' AND archived_at IS NULL ORDER BY title`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list groups: %w", err)
 	}
@@ -139,7 +302,7 @@ func (r *EntityRepository) ListGroups(ctx context.Context) ([]models.Entity, err
 	var entities []models.Entity
 	for rows.Next() {
 		var e models.Entity
-		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.CreatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.Skills, &e.ManagerEmail, &e.Private, &e.Region, &e.CapacityMode, &e.CreatedAt, &e.ArchivedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan entity: %w", err)
 		}
 		entities = append(entities, e)
@@ -148,11 +311,11 @@ func (r *EntityRepository) ListGroups(ctx context.Context) ([]models.Entity, err
 	return entities, nil
 }
 
-// ListAll returns all entities
+// ListAll returns all entities that are not archived
 func (r *EntityRepository) ListAll(ctx context.Context) ([]models.Entity, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, title, type, employee_id, default_capacity, created_at
-		 FROM entities ORDER BY type, title`)
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
+		 FROM entities WHERE archived_at IS NULL ORDER BY type, title`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list entities: %w", err)
 	}
@@ -161,7 +324,7 @@ func (r *EntityRepository) ListAll(ctx context.Context) ([]models.Entity, error)
 	var entities []models.Entity
 	for rows.Next() {
 		var e models.Entity
-		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.CreatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.Skills, &e.ManagerEmail, &e.Private, &e.Region, &e.CapacityMode, &e.CreatedAt, &e.ArchivedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan entity: %w", err)
 		}
 		entities = append(entities, e)
@@ -170,6 +333,91 @@ func (r *EntityRepository) ListAll(ctx context.Context) ([]models.Entity, error)
 	return entities, nil
 }
 
+// ListChangedSince returns the entities created or updated after since,
+// archived ones included, and the ID, type and privacy of those deleted
+// after it, along with the database's time as of the query for the next call
+func (r *EntityRepository) ListChangedSince(ctx context.Context, since time.Time) (changed, deleted []models.Entity, until time.Time, err error) {
+	// Read the clock first: a change committed while the query runs is
+	// returned again next time rather than missed
+	if err := r.pool.QueryRow(ctx, `SELECT clock_timestamp()`).Scan(&until); err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to read database time: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
+		 FROM entities WHERE updated_at > $1 ORDER BY type, title`, since)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to list changed entities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e models.Entity
+		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.Skills, &e.ManagerEmail, &e.Private, &e.Region, &e.CapacityMode, &e.CreatedAt, &e.ArchivedAt); err != nil {
+			return nil, nil, time.Time{}, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		changed = append(changed, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to list changed entities: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx,
+		`SELECT DISTINCT ON (id) id, COALESCE(type, ''), private
+		 FROM entity_tombstones WHERE deleted_at > $1 ORDER BY id, deleted_at DESC`, since)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e models.Entity
+		if err := rows.Scan(&e.ID, &e.Type, &e.Private); err != nil {
+			return nil, nil, time.Time{}, fmt.Errorf("failed to scan deleted entity: %w", err)
+		}
+		deleted = append(deleted, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+
+	return changed, deleted, until, nil
+}
+
+// ListReports returns the active persons reporting to a manager, directly or
+// through their own managers, ordered by email. Cycles in the manager data
+// end the walk rather than loop.
+func (r *EntityRepository) ListReports(ctx context.Context, managerEmail string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH RECURSIVE chain(id) AS (
+		   SELECT id FROM entities WHERE manager_email = $1 AND type = 'person'
+		   UNION
+		   SELECT e.id FROM entities e
+		   JOIN chain c ON e.manager_email = c.id
+		   WHERE e.type = 'person'
+		 )
+		 SELECT e.id FROM chain c
+		 JOIN entities e ON e.id = c.id
+		 WHERE e.archived_at IS NULL AND e.id <> $1
+		 ORDER BY e.id`, managerEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reports: %w", err)
+	}
+
+	return reports, nil
+}
+
 // Delete deletes an entity by ID
 func (r *EntityRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.pool.Exec(ctx, `DELETE FROM entities WHERE id = $1`, id)
@@ -184,6 +432,82 @@ func (r *EntityRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Archive hides an entity from entity lists and heatmap selectors, keeping
+// its loads, memberships and capacity so it can be restored. Archived
+// persons cannot log in.
+func (r *EntityRepository) Archive(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE entities SET archived_at = NOW() WHERE id = $1 AND archived_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to archive entity: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return r.archiveConflict(ctx, id, ErrEntityArchived)
+	}
+
+	return nil
+}
+
+// Restore brings an archived entity back as it was when archived
+func (r *EntityRepository) Restore(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE entities SET archived_at = NULL WHERE id = $1 AND archived_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore entity: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return r.archiveConflict(ctx, id, ErrNotArchived)
+	}
+
+	return nil
+}
+
+// archiveConflict tells why archiving or restoring an entity changed
+// nothing: ErrEntityNotFound when it does not exist, conflict otherwise
+func (r *EntityRepository) archiveConflict(ctx context.Context, id string, conflict error) error {
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrEntityNotFound
+	}
+	return conflict
+}
+
+// GetDeletePreview counts the rows Delete would cascade to, with loads
+// ending before today left out of the future load counts
+func (r *EntityRepository) GetDeletePreview(ctx context.Context, id string, today time.Time) (*models.EntityDeletePreview, error) {
+	preview := &models.EntityDeletePreview{EntityID: id}
+	err := r.pool.QueryRow(ctx,
+		`SELECT e.type,
+		   (SELECT COUNT(*) FROM load_assignments WHERE person_email = e.id),
+		   (SELECT COUNT(*) FROM loads l
+		    JOIN load_assignments la ON la.load_id = l.id AND la.person_email = e.id
+		    WHERE COALESCE(l.end_date, l.date) >= $2),
+		   (SELECT COUNT(*) FROM loads l
+		    JOIN load_assignments la ON la.load_id = l.id AND la.person_email = e.id
+		    WHERE COALESCE(l.end_date, l.date) >= $2
+		      AND NOT EXISTS (
+		        SELECT 1 FROM load_assignments other
+		        WHERE other.load_id = l.id AND other.person_email <> e.id
+		      )),
+		   (SELECT COUNT(*) FROM group_members WHERE group_id = e.id OR person_email = e.id),
+		   (SELECT COUNT(*) FROM capacity_overrides WHERE entity_id = e.id),
+		   (SELECT COUNT(*) FROM weekly_capacity WHERE entity_id = e.id)
+		 FROM entities e WHERE e.id = $1`,
+		id, today.Truncate(24*time.Hour)).Scan(
+		&preview.Type, &preview.Assignments, &preview.FutureLoads, &preview.UnassignedLoads,
+		&preview.Memberships, &preview.CapacityOverrides, &preview.WeeklyCapacity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview entity deletion: %w", err)
+	}
+	return preview, nil
+}
+
 // Exists checks if an entity exists
 func (r *EntityRepository) Exists(ctx context.Context, id string) (bool, error) {
 	var exists bool
@@ -194,3 +518,18 @@ func (r *EntityRepository) Exists(ctx context.Context, id string) (bool, error)
 	}
 	return exists, nil
 }
+
+// normalizeSkills trims, lowercases, and dedupes skill tags so matching is
+// case-insensitive. It never returns nil, as the column is NOT NULL.
+func normalizeSkills(skills []string) []string {
+	normalized := make([]string, 0, len(skills))
+	seen := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill != "" && !seen[skill] {
+			seen[skill] = true
+			normalized = append(normalized, skill)
+		}
+	}
+	return normalized
+}
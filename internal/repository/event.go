@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EventRepository appends to and reads the domain event log. Events are
+// never updated or deleted; the table rejects both.
+type EventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventRepository(pool *pgxpool.Pool) *EventRepository {
+	return &EventRepository{pool: pool}
+}
+
+// Append records an event, setting its ID and time
+func (r *EventRepository) Append(ctx context.Context, event *models.DomainEvent) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO domain_events (type, actor, entity_ids, payload)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, occurred_at`,
+		event.Type, event.Actor, event.EntityIDs, event.Payload).Scan(&event.ID, &event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to append domain event: %w", err)
+	}
+	return nil
+}
+
+// List returns up to limit events after the given ID, oldest first,
+// optionally only those that changed entityID
+func (r *EventRepository) List(ctx context.Context, afterID int64, entityID string, limit int) ([]models.DomainEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, type, actor, entity_ids, payload, occurred_at
+		 FROM domain_events
+		 WHERE id > $1 AND ($2 = '' OR entity_ids @> ARRAY[$2])
+		 ORDER BY id
+		 LIMIT $3`,
+		afterID, entityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.DomainEvent{}
+	for rows.Next() {
+		var e models.DomainEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.Actor, &e.EntityIDs, &e.Payload, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan domain event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetChangedEntities returns the existing entities changed by events after
+// the given ID, with the groups of changed persons, whose heatmaps include
+// them. It also returns the last event's ID, afterID when there is none.
+func (r *EventRepository) GetChangedEntities(ctx context.Context, afterID int64) ([]string, int64, error) {
+	var lastID int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(MAX(id), $1) FROM domain_events WHERE id > $1`, afterID).Scan(&lastID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get last domain event: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`WITH changed AS (
+		   SELECT DISTINCT unnest(entity_ids) AS id FROM domain_events WHERE id > $1 AND id <= $2
+		 )
+		 SELECT e.id FROM entities e JOIN changed c ON c.id = e.id
+		 UNION
+		 SELECT gm.group_id FROM group_members gm JOIN changed c ON c.id = gm.person_email
+		 ORDER BY 1`,
+		afterID, lastID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get changed entities: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan changed entity: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read changed entities: %w", err)
+	}
+
+	return ids, lastID, nil
+}
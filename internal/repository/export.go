@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExportRepository writes person-day facts into a table outside the
+// application schema, such as a postgres_fdw foreign table in the warehouse
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{pool: pool}
+}
+
+// ReplaceDayFacts deletes the rows for date from table, a name such as
+// "analytics.person_day_facts", and inserts facts in one transaction. The
+// table needs columns date, person_email, load, capacity and overloaded.
+func (r *ExportRepository) ReplaceDayFacts(ctx context.Context, table string, date time.Time, facts []models.PersonDayFact) error {
+	name := pgx.Identifier(strings.Split(table, ".")).Sanitize()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM `+name+` WHERE date = $1`, date.Truncate(24*time.Hour)); err != nil {
+		return fmt.Errorf("failed to clear exported facts: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, f := range facts {
+		batch.Queue(`INSERT INTO `+name+` (date, person_email, load, capacity, overloaded) VALUES ($1, $2, $3, $4, $5)`,
+			f.Date, f.PersonEmail, f.Load, f.Capacity, f.Load > f.Capacity)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to export facts: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -64,6 +64,46 @@ func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, personEmail
 	return nil
 }
 
+// GetHeatmapExcluded returns the members whose loads the group's heatmap
+// leaves out
+func (r *GroupRepository) GetHeatmapExcluded(ctx context.Context, groupID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT person_email FROM group_members
+		 WHERE group_id = $1 AND heatmap_excluded
+		 ORDER BY person_email`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read excluded members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetHeatmapExcluded leaves a member's loads out of the group's heatmap, or
+// counts them again, and reports whether the person is a member at all
+func (r *GroupRepository) SetHeatmapExcluded(ctx context.Context, groupID, personEmail string, excluded bool) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE group_members SET heatmap_excluded = $3
+		 WHERE group_id = $1 AND person_email = $2`,
+		groupID, personEmail, excluded)
+	if err != nil {
+		return false, fmt.Errorf("failed to set heatmap exclusion: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // GetGroupsForPerson returns all groups a person belongs to
 func (r *GroupRepository) GetGroupsForPerson(ctx context.Context, personEmail string) ([]string, error) {
 	rows, err := r.pool.Query(ctx,
@@ -96,3 +136,169 @@ func (r *GroupRepository) IsMember(ctx context.Context, groupID, personEmail str
 	}
 	return exists, nil
 }
+
+// NotMembers returns which of the given people are not a member of any of
+// the groups, ordered by email
+func (r *GroupRepository) NotMembers(ctx context.Context, groupIDs, emails []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT e.email FROM unnest($2::text[]) AS e(email)
+		 WHERE NOT EXISTS (
+			SELECT 1 FROM group_members WHERE group_id = ANY($1) AND person_email = e.email
+		 )
+		 ORDER BY e.email`,
+		groupIDs, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var outside []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		outside = append(outside, email)
+	}
+
+	return outside, nil
+}
+
+// GetOwners returns all owner emails for a group
+func (r *GroupRepository) GetOwners(ctx context.Context, groupID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT person_email FROM group_owners WHERE group_id = $1 ORDER BY person_email`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan owner: %w", err)
+		}
+		owners = append(owners, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group owners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// AddOwner makes a person an owner of a group
+func (r *GroupRepository) AddOwner(ctx context.Context, groupID, personEmail string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO group_owners (group_id, person_email)
+		 VALUES ($1, $2)
+		 ON CONFLICT (group_id, person_email) DO NOTHING`,
+		groupID, personEmail)
+
+	if err != nil {
+		return fmt.Errorf("failed to add group owner: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveOwner removes a person from a group's owners
+func (r *GroupRepository) RemoveOwner(ctx context.Context, groupID, personEmail string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM group_owners WHERE group_id = $1 AND person_email = $2`,
+		groupID, personEmail)
+
+	if err != nil {
+		return fmt.Errorf("failed to remove group owner: %w", err)
+	}
+
+	return nil
+}
+
+// GetApprovers returns the owners of a person's groups, other than the
+// person, who can approve their capacity changes
+func (r *GroupRepository) GetApprovers(ctx context.Context, personEmail string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT o.person_email
+		 FROM group_members gm
+		 JOIN group_owners o ON o.group_id = gm.group_id
+		 JOIN entities e ON e.id = o.person_email AND e.archived_at IS NULL
+		 WHERE gm.person_email = $1 AND o.person_email <> $1
+		 ORDER BY o.person_email`, personEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvers: %w", err)
+	}
+	defer rows.Close()
+
+	var approvers []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan approver: %w", err)
+		}
+		approvers = append(approvers, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get approvers: %w", err)
+	}
+
+	return approvers, nil
+}
+
+// IsApprover checks if a person owns one of another person's groups
+func (r *GroupRepository) IsApprover(ctx context.Context, approverEmail, personEmail string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM group_members gm
+			JOIN group_owners o ON o.group_id = gm.group_id
+			WHERE gm.person_email = $2 AND o.person_email = $1 AND o.person_email <> $2
+		)`,
+		approverEmail, personEmail).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check approver: %w", err)
+	}
+	return exists, nil
+}
+
+// EnableDashboard allows a group's anonymized heatmap on public dashboards
+func (r *GroupRepository) EnableDashboard(ctx context.Context, groupID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO group_dashboards (group_id)
+		 VALUES ($1)
+		 ON CONFLICT (group_id) DO NOTHING`,
+		groupID)
+
+	if err != nil {
+		return fmt.Errorf("failed to enable group dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// DisableDashboard takes a group off public dashboards
+func (r *GroupRepository) DisableDashboard(ctx context.Context, groupID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM group_dashboards WHERE group_id = $1`,
+		groupID)
+
+	if err != nil {
+		return fmt.Errorf("failed to disable group dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// IsDashboardEnabled checks if a group's anonymized heatmap may be shown on
+// public dashboards
+func (r *GroupRepository) IsDashboardEnabled(ctx context.Context, groupID string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM group_dashboards WHERE group_id = $1)`,
+		groupID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check group dashboard: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrHolidayNotFound = errors.New("holiday not found")
+
+type HolidayRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHolidayRepository(pool *pgxpool.Pool) *HolidayRepository {
+	return &HolidayRepository{pool: pool}
+}
+
+// Upsert stores a region's holidays by date, renaming those already stored
+func (r *HolidayRepository) Upsert(ctx context.Context, region string, holidays map[time.Time]string) error {
+	dates := make([]time.Time, 0, len(holidays))
+	names := make([]string, 0, len(holidays))
+	for date, name := range holidays {
+		dates = append(dates, date)
+		names = append(names, name)
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO holidays (region, date, name)
+		 SELECT $1, h.date, h.name FROM unnest($2::date[], $3::text[]) AS h(date, name)
+		 ON CONFLICT (region, date) DO UPDATE
+		 SET name = EXCLUDED.name, updated_at = clock_timestamp()
+		 WHERE holidays.name <> EXCLUDED.name`,
+		region, dates, names)
+	if err != nil {
+		return fmt.Errorf("failed to upsert holidays: %w", err)
+	}
+
+	return nil
+}
+
+// List returns a region's holidays from start to end, in date order
+func (r *HolidayRepository) List(ctx context.Context, region string, start, end time.Time) ([]models.Holiday, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT region, date, name FROM holidays
+		 WHERE region = $1 AND date BETWEEN $2 AND $3
+		 ORDER BY date`, region, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := []models.Holiday{}
+	for rows.Next() {
+		var h models.Holiday
+		var date time.Time
+		if err := rows.Scan(&h.Region, &date, &h.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		h.Date = date.Format("2006-01-02")
+		holidays = append(holidays, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holidays: %w", err)
+	}
+
+	return holidays, nil
+}
+
+// Delete removes one of a region's holidays
+func (r *HolidayRepository) Delete(ctx context.Context, region string, date time.Time) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM holidays WHERE region = $1 AND date = $2`, region, date)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrHolidayNotFound
+	}
+
+	return nil
+}
+
+// GetForEntity returns the names of the holidays of an entity's region from
+// start to end, by date; none for an entity without a region
+func (r *HolidayRepository) GetForEntity(ctx context.Context, entityID string, start, end time.Time) (map[time.Time]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT h.date, h.name
+		 FROM holidays h
+		 JOIN entities e ON e.region = h.region
+		 WHERE e.id = $1 AND h.date BETWEEN $2 AND $3`, entityID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := make(map[time.Time]string)
+	for rows.Next() {
+		var date time.Time
+		var name string
+		if err := rows.Scan(&date, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holidays[time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holidays: %w", err)
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrIncidentNotFound = errors.New("incident note not found")
+
+type IncidentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIncidentRepository(pool *pgxpool.Pool) *IncidentRepository {
+	return &IncidentRepository{pool: pool}
+}
+
+const incidentColumns = `id, body, author_email, created_at, updated_at, resolved_at`
+
+func scanIncident(row pgx.Row) (*models.IncidentNote, error) {
+	var n models.IncidentNote
+	if err := row.Scan(&n.ID, &n.Body, &n.AuthorEmail, &n.CreatedAt, &n.UpdatedAt, &n.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ListRecent returns the latest incident notes, newest first
+func (r *IncidentRepository) ListRecent(ctx context.Context, limit int) ([]models.IncidentNote, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+incidentColumns+` FROM incident_notes ORDER BY created_at DESC, id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incident notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []models.IncidentNote{}
+	for rows.Next() {
+		n, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident note: %w", err)
+		}
+		notes = append(notes, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read incident notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// Create stores an open incident note
+func (r *IncidentRepository) Create(ctx context.Context, authorEmail, body string) (*models.IncidentNote, error) {
+	n, err := scanIncident(r.pool.QueryRow(ctx,
+		`INSERT INTO incident_notes (body, author_email) VALUES ($1, $2) RETURNING `+incidentColumns,
+		body, authorEmail))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create incident note: %w", err)
+	}
+	return n, nil
+}
+
+// Update replaces an incident note's body, when set, and resolves or
+// reopens it, when resolved is set
+func (r *IncidentRepository) Update(ctx context.Context, id int, body *string, resolved *bool) (*models.IncidentNote, error) {
+	n, err := scanIncident(r.pool.QueryRow(ctx,
+		`UPDATE incident_notes
+		 SET body = COALESCE($2, body),
+		     resolved_at = CASE
+		       WHEN $3::boolean IS NULL THEN resolved_at
+		       WHEN $3 THEN COALESCE(resolved_at, NOW())
+		       ELSE NULL
+		     END,
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+incidentColumns,
+		id, body, resolved))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update incident note: %w", err)
+	}
+	return n, nil
+}
+
+// Delete removes an incident note
+func (r *IncidentRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM incident_notes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete incident note: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrIncidentNotFound
+	}
+	return nil
+}
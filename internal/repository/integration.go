@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IntegrationRepository records failed load syncs and webhook deliveries,
+// and summarizes them with the loads' own sync times for the integration
+// health report
+type IntegrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIntegrationRepository(pool *pgxpool.Pool) *IntegrationRepository {
+	return &IntegrationRepository{pool: pool}
+}
+
+// RecordError stores a failed load sync, pruning those older than a week
+func (r *IntegrationRepository) RecordError(ctx context.Context, e models.IntegrationError) error {
+	_, err := r.pool.Exec(ctx,
+		`WITH pruned AS (
+		   DELETE FROM integration_errors WHERE occurred_at < NOW() - INTERVAL '7 days'
+		 )
+		 INSERT INTO integration_errors (source, operation, status, message) VALUES ($1, $2, $3, $4)`,
+		e.Source, e.Operation, e.Status, e.Message)
+	if err != nil {
+		return fmt.Errorf("failed to record integration error: %w", err)
+	}
+	return nil
+}
+
+// RecordDelivery stores the outcome of a webhook delivery with the trace and
+// span IDs it was sent with, pruning those older than a week. deliveryErr is
+// nil for deliveries that succeeded; endpointID is 0 for deliveries to
+// WEBHOOK_DESTINATION_URL.
+func (r *IntegrationRepository) RecordDelivery(ctx context.Context, event string, endpointID int, traceID, spanID string, deliveryErr error) error {
+	var message *string
+	if deliveryErr != nil {
+		m := deliveryErr.Error()
+		message = &m
+	}
+	_, err := r.pool.Exec(ctx,
+		`WITH pruned AS (
+		   DELETE FROM webhook_deliveries WHERE delivered_at < NOW() - INTERVAL '7 days'
+		 )
+		 INSERT INTO webhook_deliveries (event, succeeded, error, trace_id, span_id, endpoint_id)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0))`,
+		event, deliveryErr == nil, message, traceID, spanID, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetSourceSyncs returns, per source of the stored loads, when it last
+// upserted one and how many it upserted since the given time
+func (r *IntegrationRepository) GetSourceSyncs(ctx context.Context, since time.Time) ([]models.SourceHealth, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT COALESCE(source, ''), MAX(last_seen_at), COUNT(*) FILTER (WHERE last_seen_at >= $1)
+		 FROM loads
+		 GROUP BY COALESCE(source, '')
+		 ORDER BY 1`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source syncs: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []models.SourceHealth
+	for rows.Next() {
+		var s models.SourceHealth
+		var lastSync time.Time
+		if err := rows.Scan(&s.Source, &lastSync, &s.LoadsSynced); err != nil {
+			return nil, fmt.Errorf("failed to scan source sync: %w", err)
+		}
+		s.LastSyncAt = &lastSync
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// GetErrorCounts returns the number of failed syncs per source since the
+// given time
+func (r *IntegrationRepository) GetErrorCounts(ctx context.Context, since time.Time) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT source, COUNT(*) FROM integration_errors WHERE occurred_at >= $1 GROUP BY source`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count integration errors: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var source string
+		var n int
+		if err := rows.Scan(&source, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan integration error count: %w", err)
+		}
+		counts[source] = n
+	}
+	return counts, rows.Err()
+}
+
+// GetRecentErrors returns up to perSource of each source's latest failed
+// syncs, latest first
+func (r *IntegrationRepository) GetRecentErrors(ctx context.Context, perSource int) ([]models.IntegrationError, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT source, operation, status, message, occurred_at FROM (
+		   SELECT *, ROW_NUMBER() OVER (PARTITION BY source ORDER BY occurred_at DESC, id DESC) AS n
+		   FROM integration_errors
+		 ) e
+		 WHERE n <= $1
+		 ORDER BY source, occurred_at DESC, id DESC`,
+		perSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration errors: %w", err)
+	}
+	defer rows.Close()
+
+	var errs []models.IntegrationError
+	for rows.Next() {
+		var e models.IntegrationError
+		if err := rows.Scan(&e.Source, &e.Operation, &e.Status, &e.Message, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan integration error: %w", err)
+		}
+		errs = append(errs, e)
+	}
+	return errs, rows.Err()
+}
+
+// GetWebhookDeliveries counts the webhook deliveries since the given time
+// per event, with the latest failure and its trace ID
+func (r *IntegrationRepository) GetWebhookDeliveries(ctx context.Context, since time.Time) ([]models.WebhookHealth, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT event,
+		   COUNT(*) FILTER (WHERE succeeded),
+		   COUNT(*) FILTER (WHERE NOT succeeded),
+		   (ARRAY_AGG(error ORDER BY delivered_at DESC) FILTER (WHERE NOT succeeded))[1],
+		   (ARRAY_AGG(trace_id ORDER BY delivered_at DESC) FILTER (WHERE NOT succeeded))[1],
+		   MAX(delivered_at) FILTER (WHERE NOT succeeded)
+		 FROM webhook_deliveries
+		 WHERE delivered_at >= $1
+		 GROUP BY event
+		 ORDER BY event`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.WebhookHealth
+	for rows.Next() {
+		var w models.WebhookHealth
+		var lastError, lastTraceID *string
+		if err := rows.Scan(&w.Event, &w.Delivered, &w.Failed, &lastError, &lastTraceID, &w.LastFailure); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook deliveries: %w", err)
+		}
+		if lastError != nil {
+			w.LastError = *lastError
+		}
+		if lastTraceID != nil {
+			w.LastFailureTraceID = *lastTraceID
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
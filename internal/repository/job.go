@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxJobItemErrors is how many failed items a job keeps; later failures are
+// only counted
+const MaxJobItemErrors = 1000
+
+var ErrJobNotFound = errors.New("job not found")
+
+type JobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRepository(pool *pgxpool.Pool) *JobRepository {
+	return &JobRepository{pool: pool}
+}
+
+// Create stores a new queued job of total items
+func (r *JobRepository) Create(ctx context.Context, kind string, total int) (*models.Job, error) {
+	job := &models.Job{Kind: kind, Status: models.JobQueued, Total: total, Errors: []models.JobItemError{}}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO jobs (kind, total) VALUES ($1, $2) RETURNING id, created_at`,
+		kind, total).Scan(&job.ID, &job.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	return job, nil
+}
+
+// Get returns a job with its failed items
+func (r *JobRepository) Get(ctx context.Context, id int64) (*models.Job, error) {
+	job := &models.Job{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, kind, status, total, processed, failed, errors, error, created_at, started_at, finished_at
+		 FROM jobs WHERE id = $1`, id).Scan(
+		&job.ID, &job.Kind, &job.Status, &job.Total, &job.Processed, &job.Failed, &job.Errors, &job.Error,
+		&job.CreatedAt, &job.StartedAt, &job.FinishedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// Start marks a queued job running
+func (r *JobRepository) Start(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'running', started_at = NOW(), updated_at = NOW()
+		 WHERE id = $1 AND status = 'queued'`, id)
+	if err != nil {
+		return fmt.Errorf("failed to start job: %w", err)
+	}
+	return nil
+}
+
+// Progress records how many items a running job has processed and failed,
+// appending newly failed items up to MaxJobItemErrors. Called with nothing
+// new, it only shows the job is still alive.
+func (r *JobRepository) Progress(ctx context.Context, id int64, processed, failed int, newErrors []models.JobItemError) error {
+	if newErrors == nil {
+		newErrors = []models.JobItemError{}
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET processed = $2, failed = $3, updated_at = NOW(),
+		   errors = CASE WHEN jsonb_array_length(errors) >= $5 THEN errors
+		            ELSE (SELECT COALESCE(jsonb_agg(e ORDER BY n), '[]')
+		                  FROM jsonb_array_elements(errors || $4::jsonb) WITH ORDINALITY AS t(e, n)
+		                  WHERE n <= $5) END
+		 WHERE id = $1`,
+		id, processed, failed, newErrors, MaxJobItemErrors)
+	if err != nil {
+		return fmt.Errorf("failed to record job progress: %w", err)
+	}
+	return nil
+}
+
+// Finish marks a job succeeded, or failed with the reason it stopped
+func (r *JobRepository) Finish(ctx context.Context, id int64, jobErr error) error {
+	status, message := models.JobSucceeded, (*string)(nil)
+	if jobErr != nil {
+		status = models.JobFailed
+		msg := jobErr.Error()
+		message = &msg
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = $2, error = $3, finished_at = NOW(), updated_at = NOW() WHERE id = $1`,
+		id, status, message)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// FailAbandoned fails the unfinished jobs not heard from since before, left
+// behind by a server that stopped, and returns how many
+func (r *JobRepository) FailAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'failed', error = 'interrupted: the server running it stopped',
+		   finished_at = NOW(), updated_at = NOW()
+		 WHERE status IN ('queued', 'running') AND updated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrLoadNotFound       = errors.New("load not found")
+	ErrAssignmentNotFound = errors.New("assignee not found for this load")
+	ErrLoadNotPast        = errors.New("load has not happened yet")
+)
+
 type LoadRepository struct {
 	pool *pgxpool.Pool
 }
@@ -17,36 +25,99 @@ func NewLoadRepository(pool *pgxpool.Pool) *LoadRepository {
 	return &LoadRepository{pool: pool}
 }
 
-// UpsertByExternalID creates or updates a load and its assignments by external ID
-func (r *LoadRepository) UpsertByExternalID(ctx context.Context, load *models.Load, assignments []models.LoadAssignment) (int, error) {
+// Expansion is what an upsert writes of a load's recurrence: the
+// occurrences after its first, expanded through a day
+type Expansion struct {
+	Occurrences []time.Time
+	Through     time.Time
+}
+
+// UpsertByExternalID creates or updates a load and its assignments by external ID.
+// With expansion set, it also replaces the load's recurrence rule with
+// load.Recurrence and its occurrences, or removes them when that is nil;
+// without, the recurrence is left as it is.
+// It also returns the emails of the assignees the load had before, so callers
+// can refresh anything derived from their load, and whether the load was
+// created rather than updated.
+func (r *LoadRepository) UpsertByExternalID(ctx context.Context, load *models.Load, assignments []models.LoadAssignment, expansion *Expansion) (int, []string, bool, error) {
+	return r.upsert(ctx, load, assignments, expansion, nil)
+}
+
+// UpsertCreatingAssignees is UpsertByExternalID for assignees that may not
+// exist yet: any assignee without an entity is created as a person in the
+// same transaction, titled with their email, and queued for review. It fails
+// with ErrAutoCreateQuota, creating nothing, past the source's daily quota.
+func (r *LoadRepository) UpsertCreatingAssignees(ctx context.Context, load *models.Load, assignments []models.LoadAssignment, expansion *Expansion, create AutoCreate) (int, []string, bool, error) {
+	return r.upsert(ctx, load, assignments, expansion, &create)
+}
+
+// upsert implements UpsertByExternalID, first creating missing assignees
+// when create is set.
+func (r *LoadRepository) upsert(ctx context.Context, load *models.Load, assignments []models.LoadAssignment, expansion *Expansion, create *AutoCreate) (int, []string, bool, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, nil, false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	if create != nil && len(assignments) > 0 {
+		emails := make([]string, 0, len(assignments))
+		for _, a := range assignments {
+			emails = append(emails, a.PersonEmail)
+		}
+		if err := createPersons(ctx, tx, emails, *create); err != nil {
+			return 0, nil, false, err
+		}
+	}
+
 	var loadID int
+	var created bool
+
+	customFields := load.CustomFields
+	if customFields == nil {
+		customFields = map[string]interface{}{}
+	}
 
-	// Upsert the load
+	// Upsert the load; xmax is 0 only for a row this statement inserted
 	err = tx.QueryRow(ctx,
-		`INSERT INTO loads (external_id, title, source, url, date)
-		 VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO loads (external_id, title, source, url, date, end_date, spread, custom_fields, confidential_group)
+		 VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'even'), $8, $9)
 		 ON CONFLICT (external_id) DO UPDATE SET
 		   title = EXCLUDED.title,
 		   source = EXCLUDED.source,
 		   url = EXCLUDED.url,
-		   date = EXCLUDED.date
-		 RETURNING id`,
-		load.ExternalID, load.Title, load.Source, load.URL, load.Date.Truncate(24*time.Hour)).Scan(&loadID)
+		   date = EXCLUDED.date,
+		   end_date = EXCLUDED.end_date,
+		   spread = EXCLUDED.spread,
+		   custom_fields = EXCLUDED.custom_fields,
+		   confidential_group = EXCLUDED.confidential_group,
+		   last_seen_at = NOW(),
+		   stale_since = NULL
+		 RETURNING id, xmax = 0`,
+		load.ExternalID, load.Title, load.Source, load.URL, load.Date.Truncate(24*time.Hour),
+		load.EndDate, string(load.Spread), customFields, load.ConfidentialGroup).Scan(&loadID, &created)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to upsert load: %w", err)
+		return 0, nil, false, fmt.Errorf("failed to upsert load: %w", err)
 	}
 
 	// Delete existing assignments for this load
-	_, err = tx.Exec(ctx, `DELETE FROM load_assignments WHERE load_id = $1`, loadID)
+	rows, err := tx.Query(ctx, `DELETE FROM load_assignments WHERE load_id = $1 RETURNING person_email`, loadID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete old assignments: %w", err)
+		return 0, nil, false, fmt.Errorf("failed to delete old assignments: %w", err)
+	}
+	var previous []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			rows.Close()
+			return 0, nil, false, fmt.Errorf("failed to scan old assignment: %w", err)
+		}
+		previous = append(previous, email)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, false, fmt.Errorf("failed to delete old assignments: %w", err)
 	}
 
 	// Insert new assignments
@@ -56,26 +127,144 @@ func (r *LoadRepository) UpsertByExternalID(ctx context.Context, load *models.Lo
 			 VALUES ($1, $2, $3)`,
 			loadID, a.PersonEmail, a.Weight)
 		if err != nil {
-			return 0, fmt.Errorf("failed to insert assignment: %w", err)
+			return 0, nil, false, fmt.Errorf("failed to insert assignment: %w", err)
+		}
+	}
+
+	// Replace the load's tags
+	if _, err := tx.Exec(ctx, `DELETE FROM load_tags WHERE load_id = $1`, loadID); err != nil {
+		return 0, nil, false, fmt.Errorf("failed to delete old tags: %w", err)
+	}
+	if len(load.Tags) > 0 {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO load_tags (load_id, tag)
+			 SELECT $1, unnest($2::text[])
+			 ON CONFLICT DO NOTHING`,
+			loadID, load.Tags)
+		if err != nil {
+			return 0, nil, false, fmt.Errorf("failed to insert tags: %w", err)
+		}
+	}
+
+	if expansion != nil {
+		if err := setRecurrence(ctx, tx, loadID, load.Recurrence, *expansion); err != nil {
+			return 0, nil, false, err
 		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return loadID, previous, created, nil
+}
+
+// setRecurrence replaces a load's recurrence rule and its expanded
+// occurrences, or removes them when rule is nil. It touches the load, so the
+// heatmaps of its assignees change version.
+func setRecurrence(ctx context.Context, tx pgx.Tx, loadID int, rule *models.Recurrence, expansion Expansion) error {
+	// Occurrences go with the rule
+	result, err := tx.Exec(ctx, `DELETE FROM recurring_loads WHERE load_id = $1`, loadID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurrence: %w", err)
+	}
+	if rule == nil && result.RowsAffected() == 0 {
+		return nil
+	}
+
+	if rule != nil {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO recurring_loads (load_id, frequency, repeat_every, until_date, occurrence_count, expanded_through)
+			 VALUES ($1, $2, $3, $4, $5, $6)`,
+			loadID, string(rule.Frequency), rule.Interval, rule.Until, rule.Count, expansion.Through.Truncate(24*time.Hour))
+		if err != nil {
+			return fmt.Errorf("failed to insert recurrence: %w", err)
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO load_occurrences (load_id, date) SELECT $1, unnest($2::date[])`,
+			loadID, expansion.Occurrences)
+		if err != nil {
+			return fmt.Errorf("failed to insert occurrences: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE loads SET updated_at = clock_timestamp() WHERE id = $1`, loadID)
+	if err != nil {
+		return fmt.Errorf("failed to touch load: %w", err)
+	}
+	return nil
+}
+
+// ListRecurringLoads returns the recurring loads expanded through a day
+// before horizon whose rule may still have occurrences after it
+func (r *LoadRepository) ListRecurringLoads(ctx context.Context, horizon time.Time) ([]models.Load, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.id, l.date, l.end_date, r.frequency, r.repeat_every, r.until_date, r.occurrence_count
+		 FROM recurring_loads r
+		 JOIN loads l ON l.id = r.load_id
+		 WHERE r.expanded_through < $1
+		   AND (r.until_date IS NULL OR r.until_date > r.expanded_through)
+		 ORDER BY l.id`,
+		horizon.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring loads: %w", err)
+	}
+	defer rows.Close()
+
+	var loads []models.Load
+	for rows.Next() {
+		var l models.Load
+		var rule recurrenceRow
+		if err := rows.Scan(&l.ID, &l.Date, &l.EndDate, &rule.frequency, &rule.every, &rule.until, &rule.count); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring load: %w", err)
+		}
+		l.Recurrence = rule.recurrence()
+		loads = append(loads, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recurring loads: %w", err)
 	}
 
-	return loadID, nil
+	return loads, nil
+}
+
+// AddOccurrences adds the occurrences of a recurring load it does not have
+// yet, and records that it is expanded through the given day
+func (r *LoadRepository) AddOccurrences(ctx context.Context, loadID int, occurrences []time.Time, through time.Time) error {
+	result, err := r.pool.Exec(ctx,
+		`WITH added AS (
+		   INSERT INTO load_occurrences (load_id, date)
+		   SELECT $1, unnest($2::date[])
+		   ON CONFLICT DO NOTHING
+		 )
+		 UPDATE recurring_loads SET expanded_through = $3 WHERE load_id = $1`,
+		loadID, occurrences, through.Truncate(24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to add occurrences: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLoadNotFound
+	}
+	return nil
 }
 
 // GetByID retrieves a load by its ID
 func (r *LoadRepository) GetByID(ctx context.Context, id int) (*models.LoadWithAssignments, error) {
 	load := &models.Load{}
+	var rule recurrenceRow
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, external_id, title, source, url, date FROM loads WHERE id = $1`, id).Scan(
-		&load.ID, &load.ExternalID, &load.Title, &load.Source, &load.URL, &load.Date)
+		`SELECT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.custom_fields, l.confidential_group,
+		        COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM load_tags t WHERE t.load_id = l.id), '{}'),
+		        r.frequency, r.repeat_every, r.until_date, r.occurrence_count
+		 FROM loads l
+		 LEFT JOIN recurring_loads r ON r.load_id = l.id
+		 WHERE l.id = $1`, id).Scan(
+		&load.ID, &load.ExternalID, &load.Title, &load.Source, &load.URL, &load.Date, &load.EndDate, &load.Spread, &load.CustomFields, &load.ConfidentialGroup, &load.Tags,
+		&rule.frequency, &rule.every, &rule.until, &rule.count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get load: %w", err)
 	}
+	load.Recurrence = rule.recurrence()
 
 	rows, err := r.pool.Query(ctx,
 		`SELECT load_id, person_email, weight FROM load_assignments WHERE load_id = $1`, id)
@@ -99,81 +288,254 @@ func (r *LoadRepository) GetByID(ctx context.Context, id int) (*models.LoadWithA
 	}, nil
 }
 
-// GetLoadsByDateRange retrieves all loads within a date range
+// Exists checks if a load exists
+func (r *LoadRepository) Exists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM loads WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check load existence: %w", err)
+	}
+	return exists, nil
+}
+
+// GetLoadsByDateRange retrieves all loads on any day of a date range. It holds the
+// whole result in memory; use StreamLoadsByDateRange or
+// GetLoadsPageByDateRange for large ranges.
 func (r *LoadRepository) GetLoadsByDateRange(ctx context.Context, start, end time.Time) ([]models.LoadWithAssignments, error) {
+	result := []models.LoadWithAssignments{}
+	err := r.StreamLoadsByDateRange(ctx, start, end, func(l models.LoadWithAssignments) error {
+		result = append(result, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LoadCursor is a position in the (date, id) order used by the range
+// loaders. The zero value starts at the beginning of the range.
+type LoadCursor struct {
+	Date time.Time
+	ID   int
+}
+
+// LoadFilter narrows the loads GetLoadsPageByDateRange returns. Empty fields
+// match every load.
+type LoadFilter struct {
+	Source      string
+	PersonEmail string // loads assigned to this person
+	GroupID     string // loads assigned to any member of this group
+}
+
+// LoadPage is one page of loads from GetLoadsPageByDateRange.
+type LoadPage struct {
+	Loads []models.LoadWithAssignments
+
+	// Next is the cursor for the following page, or nil on the last page.
+	Next *LoadCursor
+}
+
+// GetLoadsPageByDateRange returns up to limit loads on any day of a date range
+// that match the filter and come after the cursor, ordered by (first) date and id. Keyset
+// pagination keeps each page an index range scan however deep the caller pages.
+func (r *LoadRepository) GetLoadsPageByDateRange(ctx context.Context, start, end time.Time, filter LoadFilter, after LoadCursor, limit int) (*LoadPage, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	// Fetch one extra load to learn whether another page follows
+	rows, err := r.pool.Query(ctx,
+		`WITH page AS (
+		   SELECT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.custom_fields, l.confidential_group
+		   FROM loads l
+		   WHERE l.date <= $2 AND (COALESCE(l.end_date, l.date) >= $1 OR EXISTS (
+		       SELECT 1 FROM load_occurrences lo
+		       WHERE lo.load_id = l.id AND lo.date <= $2
+		         AND lo.date + (COALESCE(l.end_date, l.date) - l.date) >= $1))
+		     AND (l.date, l.id) > ($3, $4)
+		     AND ($6 = '' OR l.source = $6)
+		     AND ($7 = '' OR EXISTS (
+		       SELECT 1 FROM load_assignments fa
+		       WHERE fa.load_id = l.id AND fa.person_email = $7))
+		     AND ($8 = '' OR EXISTS (
+		       SELECT 1 FROM load_assignments fa
+		       JOIN group_members gm ON gm.person_email = fa.person_email
+		       WHERE fa.load_id = l.id AND gm.group_id = $8))
+		   ORDER BY l.date, l.id
+		   LIMIT $5
+		 )
+		 SELECT p.id, p.external_id, p.title, p.source, p.url, p.date, p.end_date, p.spread, p.custom_fields, p.confidential_group,
+		        COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM load_tags t WHERE t.load_id = p.id), '{}'),
+		        r.frequency, r.repeat_every, r.until_date, r.occurrence_count,
+		        la.person_email, la.weight
+		 FROM page p
+		 LEFT JOIN recurring_loads r ON r.load_id = p.id
+		 LEFT JOIN load_assignments la ON p.id = la.load_id
+		 ORDER BY p.date, p.id`,
+		start.Truncate(24*time.Hour), end.Truncate(24*time.Hour),
+		after.Date.Truncate(24*time.Hour), after.ID, limit+1,
+		filter.Source, filter.PersonEmail, filter.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loads: %w", err)
+	}
+	defer rows.Close()
+
+	page := &LoadPage{Loads: []models.LoadWithAssignments{}}
+	err = scanLoadRows(rows, func(l models.LoadWithAssignments) error {
+		page.Loads = append(page.Loads, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page.Loads) > limit {
+		page.Loads = page.Loads[:limit]
+		last := page.Loads[limit-1].Load
+		page.Next = &LoadCursor{Date: last.Date, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// StreamLoadsByDateRange calls fn for each load on any day of a date range, in
+// date and id order, without holding the range in memory. Rows are read from the
+// connection as fn consumes them, so fn should be quick (e.g. writing to an
+// export); the connection stays checked out until the stream ends. An error
+// from fn stops the stream and is returned.
+func (r *LoadRepository) StreamLoadsByDateRange(ctx context.Context, start, end time.Time, fn func(models.LoadWithAssignments) error) error {
 	rows, err := r.pool.Query(ctx,
-		`SELECT l.id, l.external_id, l.title, l.source, l.url, l.date,
+		`SELECT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.custom_fields, l.confidential_group,
+		        COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM load_tags t WHERE t.load_id = l.id), '{}'),
+		        r.frequency, r.repeat_every, r.until_date, r.occurrence_count,
 		        la.person_email, la.weight
 		 FROM loads l
+		 LEFT JOIN recurring_loads r ON r.load_id = l.id
 		 LEFT JOIN load_assignments la ON l.id = la.load_id
-		 WHERE l.date BETWEEN $1 AND $2
+		 WHERE l.date <= $2 AND (COALESCE(l.end_date, l.date) >= $1 OR EXISTS (
+		     SELECT 1 FROM load_occurrences lo
+		     WHERE lo.load_id = l.id AND lo.date <= $2
+		       AND lo.date + (COALESCE(l.end_date, l.date) - l.date) >= $1))
 		 ORDER BY l.date, l.id`,
 		start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get loads: %w", err)
+		return fmt.Errorf("failed to get loads: %w", err)
 	}
 	defer rows.Close()
 
-	loadMap := make(map[int]*models.LoadWithAssignments)
-	var loadOrder []int
+	return scanLoadRows(rows, fn)
+}
+
+// scanLoadRows groups load rows joined with their assignments into loads and
+// calls fn for each. Rows must be ordered so that each load's rows are
+// adjacent.
+func scanLoadRows(rows pgx.Rows, fn func(models.LoadWithAssignments) error) error {
+	var current *models.LoadWithAssignments
 
 	for rows.Next() {
 		var (
-			loadID      int
-			externalID  *string
-			title       string
-			source      *string
-			url         *string
-			date        time.Time
-			personEmail *string
-			weight      *float64
+			loadID       int
+			externalID   *string
+			title        string
+			source       *string
+			url          *string
+			date         time.Time
+			endDate      *time.Time
+			spread       models.LoadSpread
+			customFields map[string]interface{}
+			confidential *string
+			tags         []string
+			rule         recurrenceRow
+			personEmail  *string
+			weight       *float64
 		)
 
-		if err := rows.Scan(&loadID, &externalID, &title, &source, &url, &date, &personEmail, &weight); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+		if err := rows.Scan(&loadID, &externalID, &title, &source, &url, &date, &endDate, &spread, &customFields, &confidential, &tags,
+			&rule.frequency, &rule.every, &rule.until, &rule.count, &personEmail, &weight); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		if _, exists := loadMap[loadID]; !exists {
-			loadMap[loadID] = &models.LoadWithAssignments{
+		if current == nil || current.Load.ID != loadID {
+			if current != nil {
+				if err := fn(*current); err != nil {
+					return err
+				}
+			}
+			current = &models.LoadWithAssignments{
 				Load: models.Load{
-					ID:         loadID,
-					ExternalID: externalID,
-					Title:      title,
-					Source:     source,
-					URL:        url,
-					Date:       date,
+					ID:                loadID,
+					ExternalID:        externalID,
+					Title:             title,
+					Source:            source,
+					URL:               url,
+					Date:              date,
+					EndDate:           endDate,
+					Spread:            spread,
+					Recurrence:        rule.recurrence(),
+					CustomFields:      customFields,
+					Tags:              tags,
+					ConfidentialGroup: confidential,
 				},
 				Assignments: []models.LoadAssignment{},
 			}
-			loadOrder = append(loadOrder, loadID)
 		}
 
 		if personEmail != nil {
-			loadMap[loadID].Assignments = append(loadMap[loadID].Assignments, models.LoadAssignment{
+			current.Assignments = append(current.Assignments, models.LoadAssignment{
 				LoadID:      loadID,
 				PersonEmail: *personEmail,
 				Weight:      *weight,
 			})
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read loads: %w", err)
+	}
 
-	result := make([]models.LoadWithAssignments, 0, len(loadOrder))
-	for _, id := range loadOrder {
-		result = append(result, *loadMap[id])
+	if current != nil {
+		return fn(*current)
 	}
+	return nil
+}
 
-	return result, nil
+// recurrenceRow scans a load's recurring_loads columns, all NULL for a
+// one-off
+type recurrenceRow struct {
+	frequency *string
+	every     *int
+	until     *time.Time
+	count     *int
+}
+
+// recurrence returns the scanned rule, nil for a one-off
+func (r recurrenceRow) recurrence() *models.Recurrence {
+	if r.frequency == nil {
+		return nil
+	}
+	return &models.Recurrence{
+		Frequency: models.RecurrenceFrequency(*r.frequency),
+		Interval:  *r.every,
+		Until:     r.until,
+		Count:     r.count,
+	}
 }
 
-// GetPersonLoadForDateRange returns the total load per day for a person
-func (r *LoadRepository) GetPersonLoadForDateRange(ctx context.Context, email string, start, end time.Time) (map[time.Time]float64, error) {
+// GetPersonLoadForDateRange returns the total load per day for a person,
+// leaving out loads from the hidden sources and, given a tag, loads without it.
+// Loads spanning several days count their share on each day.
+func (r *LoadRepository) GetPersonLoadForDateRange(ctx context.Context, email string, start, end time.Time, hiddenSources []string, tag string) (map[time.Time]float64, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT l.date, COALESCE(SUM(la.weight), 0) as total_load
-		 FROM loads l
-		 JOIN load_assignments la ON l.id = la.load_id
-		 WHERE la.person_email = $1 AND l.date BETWEEN $2 AND $3
-		 GROUP BY l.date`,
-		email, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+		`SELECT ld.date, COALESCE(SUM(la.weight * ld.share), 0) as total_load
+		 FROM load_days ld
+		 JOIN load_assignments la ON ld.load_id = la.load_id
+		 WHERE la.person_email = $1 AND ld.date BETWEEN $2 AND $3
+		   AND COALESCE(ld.source, '') <> ALL($4)
+		   AND ($5 = '' OR EXISTS (
+		     SELECT 1 FROM load_tags lt WHERE lt.load_id = ld.load_id AND lt.tag = $5))
+		 GROUP BY ld.date`,
+		email, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour), sourceList(hiddenSources), tag)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get person load: %w", err)
 	}
@@ -194,19 +556,59 @@ func (r *LoadRepository) GetPersonLoadForDateRange(ctx context.Context, email st
 	return loads, nil
 }
 
-// GetGroupLoadForDateRange returns the total load per day for a group (sum of all members)
+// GetPeopleLoadForDateRange returns the total load per day of a set of
+// persons
+func (r *LoadRepository) GetPeopleLoadForDateRange(ctx context.Context, emails []string, start, end time.Time) (map[time.Time]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ld.date, COALESCE(SUM(la.weight * ld.share), 0) as total_load
+		 FROM load_days ld
+		 JOIN load_assignments la ON ld.load_id = la.load_id
+		 WHERE la.person_email = ANY($1) AND ld.date BETWEEN $2 AND $3
+		 GROUP BY ld.date`,
+		emails, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get people load: %w", err)
+	}
+	defer rows.Close()
+
+	loads := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var load float64
+		if err := rows.Scan(&date, &load); err != nil {
+			return nil, fmt.Errorf("failed to scan load: %w", err)
+		}
+		// Use UTC to normalize the date key
+		normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		loads[normalizedDate] = load
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read people load: %w", err)
+	}
+
+	return loads, nil
+}
+
+// GetGroupLoadForDateRange returns the total load per day for a group (sum of all members),
+// leaving out loads from the hidden sources and, given a tag, loads without it.
+// Members excluded from the group's heatmap, and those in excluded (lowercase
+// emails), do not count.
 // This is the "killer query" from the spec
-func (r *LoadRepository) GetGroupLoadForDateRange(ctx context.Context, groupID string, start, end time.Time) (map[time.Time]float64, error) {
+func (r *LoadRepository) GetGroupLoadForDateRange(ctx context.Context, groupID string, start, end time.Time, hiddenSources []string, tag string, excluded []string) (map[time.Time]float64, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT
-			l.date,
-			COALESCE(SUM(la.weight), 0) as total_load
-		 FROM loads l
-		 JOIN load_assignments la ON l.id = la.load_id
+			ld.date,
+			COALESCE(SUM(la.weight * ld.share), 0) as total_load
+		 FROM load_days ld
+		 JOIN load_assignments la ON ld.load_id = la.load_id
 		 JOIN group_members gm ON la.person_email = gm.person_email
-		 WHERE gm.group_id = $1 AND l.date BETWEEN $2 AND $3
-		 GROUP BY l.date`,
-		groupID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+		 WHERE gm.group_id = $1 AND ld.date BETWEEN $2 AND $3
+		   AND NOT gm.heatmap_excluded AND lower(gm.person_email) <> ALL($6)
+		   AND COALESCE(ld.source, '') <> ALL($4)
+		   AND ($5 = '' OR EXISTS (
+		     SELECT 1 FROM load_tags lt WHERE lt.load_id = ld.load_id AND lt.tag = $5))
+		 GROUP BY ld.date`,
+		groupID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour), sourceList(hiddenSources), tag, sourceList(excluded))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group load: %w", err)
 	}
@@ -227,14 +629,152 @@ func (r *LoadRepository) GetGroupLoadForDateRange(ctx context.Context, groupID s
 	return loads, nil
 }
 
+// sourceList returns sources, or any other list, as a list for <> ALL,
+// where a nil slice would be NULL and match nothing
+func sourceList(sources []string) []string {
+	if sources == nil {
+		return []string{}
+	}
+	return sources
+}
+
+// GetHeatmapVersion returns the version of the rows an entity's heatmap over a
+// date range is computed from: the entity, its overrides, weekly pattern and
+// group members, the loads assigned to it (or its members), and deletions of
+// any of these. For groups summing their members' capacities, the members'
+// own capacity rows count too.
+// It reads only timestamps, so it is much cheaper than the heatmap itself.
+func (r *LoadRepository) GetHeatmapVersion(ctx context.Context, entityID string, start, end time.Time) (*models.HeatmapVersion, error) {
+	var v models.HeatmapVersion
+	err := r.pool.QueryRow(ctx,
+		`WITH people AS (
+			SELECT $1::text AS id
+			UNION
+			SELECT person_email FROM group_members WHERE group_id = $1
+		), capacity_holders AS (
+			SELECT $1::text AS id
+			UNION
+			SELECT gm.person_email FROM group_members gm
+			JOIN entities g ON g.id = gm.group_id
+			WHERE gm.group_id = $1 AND g.capacity_mode = 'members'
+		), stamps AS (
+			SELECT updated_at FROM entities WHERE id IN (SELECT id FROM capacity_holders)
+			UNION ALL
+			SELECT updated_at FROM group_members WHERE group_id = $1
+			UNION ALL
+			SELECT updated_at FROM capacity_overrides
+			WHERE entity_id IN (SELECT id FROM capacity_holders) AND date BETWEEN $2 AND $3
+			UNION ALL
+			SELECT updated_at FROM weekly_capacity WHERE entity_id IN (SELECT id FROM capacity_holders)
+			UNION ALL
+			SELECT h.updated_at FROM holidays h
+			JOIN entities e ON e.region = h.region
+			WHERE e.id IN (SELECT id FROM capacity_holders) AND h.date BETWEEN $2 AND $3
+			UNION ALL
+			SELECT GREATEST(l.updated_at, la.updated_at)
+			FROM loads l
+			JOIN load_assignments la ON l.id = la.load_id
+			WHERE la.person_email IN (SELECT id FROM people)
+			  AND l.date <= $3 AND (COALESCE(l.end_date, l.date) >= $2 OR EXISTS (
+			      SELECT 1 FROM load_occurrences lo
+			      WHERE lo.load_id = l.id AND lo.date <= $3
+			        AND lo.date + (COALESCE(l.end_date, l.date) - l.date) >= $2))
+			UNION ALL
+			SELECT deleted_at FROM heatmap_tombstones
+			WHERE entity_id IN (SELECT id FROM people)
+		)
+		SELECT COALESCE(MAX(updated_at), 'epoch'), COUNT(*) FROM stamps`,
+		entityID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour)).Scan(&v.LastModified, &v.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heatmap version: %w", err)
+	}
+
+	return &v, nil
+}
+
+// GetSkillAvailability returns every active person tagged with skill, with
+// their effective capacity, none on their region's holidays, and total load
+// on date
+func (r *LoadRepository) GetSkillAvailability(ctx context.Context, skill string, date time.Time) ([]models.AssigneeSuggestion, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT e.id, e.title,
+			`+effectiveCapacity("$2::date")+` AS capacity,
+			COALESCE((
+				SELECT SUM(la.weight * ld.share)
+				FROM load_assignments la
+				JOIN load_days ld ON ld.load_id = la.load_id
+				WHERE la.person_email = e.id AND ld.date = $2
+			), 0) AS total_load
+		 FROM entities e
+		 WHERE e.type = 'person' AND e.archived_at IS NULL AND e.skills @> ARRAY[$1::text]`,
+		skill, date.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skill availability: %w", err)
+	}
+	defer rows.Close()
+
+	var people []models.AssigneeSuggestion
+	for rows.Next() {
+		var p models.AssigneeSuggestion
+		if err := rows.Scan(&p.Email, &p.Title, &p.Capacity, &p.Load); err != nil {
+			return nil, fmt.Errorf("failed to scan skill availability: %w", err)
+		}
+		p.Remaining = p.Capacity - p.Load
+		people = append(people, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get skill availability: %w", err)
+	}
+
+	return people, nil
+}
+
+// GetGroupMemberLoads returns every active member of a group with their
+// effective capacity, none on their region's holidays, and total load on
+// date, ordered by email
+func (r *LoadRepository) GetGroupMemberLoads(ctx context.Context, groupID string, date time.Time) ([]models.RebalanceMember, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT e.id, e.title,
+			`+effectiveCapacity("$2::date")+` AS capacity,
+			COALESCE((
+				SELECT SUM(la.weight * ld.share)
+				FROM load_assignments la
+				JOIN load_days ld ON ld.load_id = la.load_id
+				WHERE la.person_email = e.id AND ld.date = $2
+			), 0) AS total_load
+		 FROM group_members gm
+		 JOIN entities e ON e.id = gm.person_email
+		 WHERE gm.group_id = $1 AND e.archived_at IS NULL
+		 ORDER BY e.id`,
+		groupID, date.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member loads: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.RebalanceMember
+	for rows.Next() {
+		var m models.RebalanceMember
+		if err := rows.Scan(&m.Email, &m.Title, &m.Capacity, &m.LoadBefore); err != nil {
+			return nil, fmt.Errorf("failed to scan group member load: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group member loads: %w", err)
+	}
+
+	return members, nil
+}
+
 // GetPersonLoadForDate returns the total load for a person on a specific date
 func (r *LoadRepository) GetPersonLoadForDate(ctx context.Context, email string, date time.Time) (float64, error) {
 	var load float64
 	err := r.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(la.weight), 0) as total_load
-		 FROM loads l
-		 JOIN load_assignments la ON l.id = la.load_id
-		 WHERE la.person_email = $1 AND l.date = $2`,
+		`SELECT COALESCE(SUM(la.weight * ld.share), 0) as total_load
+		 FROM load_days ld
+		 JOIN load_assignments la ON ld.load_id = la.load_id
+		 WHERE la.person_email = $1 AND ld.date = $2`,
 		email, date.Truncate(24*time.Hour)).Scan(&load)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get person load: %w", err)
@@ -242,25 +782,34 @@ func (r *LoadRepository) GetPersonLoadForDate(ctx context.Context, email string,
 	return load, nil
 }
 
-// GetLoadsForEntityOnDate returns all loads for an entity (person or group members) on a specific date
+// GetLoadsForEntityOnDate returns all loads for an entity (person or group members) on a specific date.
+// The weights of loads spanning several days are the share falling on date.
 func (r *LoadRepository) GetLoadsForEntityOnDate(ctx context.Context, entityID string, entityType models.EntityType, date time.Time) ([]models.LoadWithAssignments, error) {
 	var query string
 	if entityType == models.EntityTypePerson {
 		query = `
-			SELECT l.id, l.external_id, l.title, l.source, l.url, l.date,
-			       la.person_email, la.weight
-			FROM loads l
+			SELECT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.custom_fields, l.confidential_group,
+			       COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM load_tags t WHERE t.load_id = l.id), '{}'),
+			       r.frequency, r.repeat_every, r.until_date, r.occurrence_count,
+			       la.person_email, la.weight * ld.share
+			FROM load_days ld
+			JOIN loads l ON l.id = ld.load_id
+			LEFT JOIN recurring_loads r ON r.load_id = l.id
 			JOIN load_assignments la ON l.id = la.load_id
-			WHERE la.person_email = $1 AND l.date = $2
+			WHERE la.person_email = $1 AND ld.date = $2
 			ORDER BY l.id`
 	} else {
 		query = `
-			SELECT DISTINCT l.id, l.external_id, l.title, l.source, l.url, l.date,
-			       la.person_email, la.weight
-			FROM loads l
+			SELECT DISTINCT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.custom_fields, l.confidential_group,
+			       COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM load_tags t WHERE t.load_id = l.id), '{}'),
+			       r.frequency, r.repeat_every, r.until_date, r.occurrence_count,
+			       la.person_email, la.weight * ld.share
+			FROM load_days ld
+			JOIN loads l ON l.id = ld.load_id
+			LEFT JOIN recurring_loads r ON r.load_id = l.id
 			JOIN load_assignments la ON l.id = la.load_id
 			JOIN group_members gm ON la.person_email = gm.person_email
-			WHERE gm.group_id = $1 AND l.date = $2
+			WHERE gm.group_id = $1 AND ld.date = $2
 			ORDER BY l.id`
 	}
 
@@ -275,29 +824,42 @@ func (r *LoadRepository) GetLoadsForEntityOnDate(ctx context.Context, entityID s
 
 	for rows.Next() {
 		var (
-			loadID      int
-			externalID  *string
-			title       string
-			source      *string
-			url         *string
-			loadDate    time.Time
-			personEmail string
-			weight      float64
+			loadID       int
+			externalID   *string
+			title        string
+			source       *string
+			url          *string
+			loadDate     time.Time
+			endDate      *time.Time
+			spread       models.LoadSpread
+			customFields map[string]interface{}
+			confidential *string
+			tags         []string
+			rule         recurrenceRow
+			personEmail  string
+			weight       float64
 		)
 
-		if err := rows.Scan(&loadID, &externalID, &title, &source, &url, &loadDate, &personEmail, &weight); err != nil {
+		if err := rows.Scan(&loadID, &externalID, &title, &source, &url, &loadDate, &endDate, &spread, &customFields, &confidential, &tags,
+			&rule.frequency, &rule.every, &rule.until, &rule.count, &personEmail, &weight); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		if _, exists := loadMap[loadID]; !exists {
 			loadMap[loadID] = &models.LoadWithAssignments{
 				Load: models.Load{
-					ID:         loadID,
-					ExternalID: externalID,
-					Title:      title,
-					Source:     source,
-					URL:        url,
-					Date:       loadDate,
+					ID:                loadID,
+					ExternalID:        externalID,
+					Title:             title,
+					Source:            source,
+					URL:               url,
+					Date:              loadDate,
+					EndDate:           endDate,
+					Spread:            spread,
+					Recurrence:        rule.recurrence(),
+					CustomFields:      customFields,
+					Tags:              tags,
+					ConfidentialGroup: confidential,
 				},
 				Assignments: []models.LoadAssignment{},
 			}
@@ -340,6 +902,74 @@ func (r *LoadRepository) GetAffectedPersons(ctx context.Context, loadID int) ([]
 	return persons, nil
 }
 
+// GetAffectedPersonsByExternalID returns all persons assigned to the load a
+// source system knows as externalID, none when there is no such load
+func (r *LoadRepository) GetAffectedPersonsByExternalID(ctx context.Context, externalID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT a.person_email FROM load_assignments a
+		 JOIN loads l ON l.id = a.load_id
+		 WHERE l.external_id = $1`, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected persons: %w", err)
+	}
+	defer rows.Close()
+
+	var persons []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		persons = append(persons, email)
+	}
+
+	return persons, nil
+}
+
+// GetIDByExternalID returns the ID of the load a source system knows as
+// externalID, or ErrLoadNotFound
+func (r *LoadRepository) GetIDByExternalID(ctx context.Context, externalID string) (int, error) {
+	var id int
+	err := r.pool.QueryRow(ctx,
+		`SELECT id FROM loads WHERE external_id = $1`, externalID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrLoadNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get load: %w", err)
+	}
+	return id, nil
+}
+
+// GetLoadShares returns the share of its assignment weights a load puts on
+// each day of a date range
+func (r *LoadRepository) GetLoadShares(ctx context.Context, loadID int, start, end time.Time) (map[time.Time]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT date, SUM(share) FROM load_days
+		 WHERE load_id = $1 AND date BETWEEN $2 AND $3
+		 GROUP BY date`,
+		loadID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var share float64
+		if err := rows.Scan(&date, &share); err != nil {
+			return nil, fmt.Errorf("failed to scan load share: %w", err)
+		}
+		shares[time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)] = share
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load shares: %w", err)
+	}
+
+	return shares, nil
+}
+
 // Delete deletes a load by ID
 func (r *LoadRepository) Delete(ctx context.Context, id int) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM loads WHERE id = $1`, id)
@@ -349,6 +979,158 @@ func (r *LoadRepository) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
+// DeleteByExternalID deletes the load a source system knows as externalID and
+// returns it with the assignments it had. It returns ErrLoadNotFound when no
+// load has that external ID.
+func (r *LoadRepository) DeleteByExternalID(ctx context.Context, externalID string) (*models.LoadWithAssignments, error) {
+	// Every part of the statement sees the assignments as they were before the
+	// cascade removes them
+	rows, err := r.pool.Query(ctx, `
+		WITH deleted AS (
+			DELETE FROM loads WHERE external_id = $1
+			RETURNING id, external_id, title, source, url, date, end_date, spread
+		)
+		SELECT d.id, d.external_id, d.title, d.source, d.url, d.date, d.end_date, d.spread, la.person_email, la.weight
+		FROM deleted d
+		LEFT JOIN load_assignments la ON la.load_id = d.id
+		ORDER BY la.person_email`, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete load: %w", err)
+	}
+	deleted, err := scanDeletedLoads(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) == 0 {
+		return nil, ErrLoadNotFound
+	}
+
+	return &deleted[0], nil
+}
+
+// scanDeletedLoads reads and closes rows of deleted loads joined to the
+// assignments they had, ordered by load
+func scanDeletedLoads(rows pgx.Rows) ([]models.LoadWithAssignments, error) {
+	defer rows.Close()
+
+	deleted := []models.LoadWithAssignments{}
+	for rows.Next() {
+		var load models.Load
+		var email *string
+		var weight *float64
+		if err := rows.Scan(&load.ID, &load.ExternalID, &load.Title, &load.Source, &load.URL, &load.Date, &load.EndDate, &load.Spread, &email, &weight); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted load: %w", err)
+		}
+		if len(deleted) == 0 || deleted[len(deleted)-1].Load.ID != load.ID {
+			deleted = append(deleted, models.LoadWithAssignments{Load: load})
+		}
+		if email != nil {
+			last := &deleted[len(deleted)-1]
+			last.Assignments = append(last.Assignments, models.LoadAssignment{
+				LoadID:      load.ID,
+				PersonEmail: *email,
+				Weight:      *weight,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete load: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// MarkStale flags upcoming loads from today on that their source has not
+// upserted since its cutoff, and returns how many were newly flagged. Loads
+// whose source has no cutoff in sourceCutoffs use defaultCutoff; a zero
+// cutoff never flags.
+func (r *LoadRepository) MarkStale(ctx context.Context, sourceCutoffs map[string]time.Time, defaultCutoff, today time.Time) (int64, error) {
+	sources := make([]string, 0, len(sourceCutoffs))
+	cutoffs := make([]time.Time, 0, len(sourceCutoffs))
+	for source, cutoff := range sourceCutoffs {
+		sources = append(sources, source)
+		cutoffs = append(cutoffs, cutoff)
+	}
+
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE loads l SET stale_since = NOW()
+		 WHERE l.stale_since IS NULL AND l.external_id IS NOT NULL AND l.date >= $4
+		   AND l.last_seen_at < COALESCE(
+		     (SELECT w.cutoff FROM unnest($1::text[], $2::timestamptz[]) AS w(source, cutoff) WHERE w.source = l.source),
+		     $3)`,
+		sources, cutoffs, defaultCutoff, today.Truncate(24*time.Hour))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark stale loads: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetStaleLoads returns flagged loads from today on, optionally only those
+// from source, with their assignments, oldest first
+func (r *LoadRepository) GetStaleLoads(ctx context.Context, source string, today time.Time) ([]models.StaleLoad, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.id, l.external_id, l.title, l.source, l.url, l.date, l.end_date, l.spread, l.last_seen_at, l.stale_since,
+		        la.person_email, la.weight
+		 FROM loads l
+		 LEFT JOIN load_assignments la ON la.load_id = l.id
+		 WHERE l.stale_since IS NOT NULL AND l.date >= $1 AND ($2 = '' OR l.source = $2)
+		 ORDER BY l.last_seen_at, l.id, la.person_email`,
+		today.Truncate(24*time.Hour), source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale loads: %w", err)
+	}
+	defer rows.Close()
+
+	stale := []models.StaleLoad{}
+	for rows.Next() {
+		var s models.StaleLoad
+		var email *string
+		var weight *float64
+		if err := rows.Scan(&s.Load.ID, &s.Load.ExternalID, &s.Load.Title, &s.Load.Source, &s.Load.URL, &s.Load.Date,
+			&s.Load.EndDate, &s.Load.Spread, &s.LastSeenAt, &s.StaleSince, &email, &weight); err != nil {
+			return nil, fmt.Errorf("failed to scan stale load: %w", err)
+		}
+		if len(stale) == 0 || stale[len(stale)-1].Load.ID != s.Load.ID {
+			s.Assignments = []models.LoadAssignment{}
+			stale = append(stale, s)
+		}
+		if email != nil {
+			last := &stale[len(stale)-1]
+			last.Assignments = append(last.Assignments, models.LoadAssignment{
+				LoadID:      s.Load.ID,
+				PersonEmail: *email,
+				Weight:      *weight,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stale loads: %w", err)
+	}
+
+	return stale, nil
+}
+
+// DeleteStale deletes those of ids that are still flagged stale and dated
+// from today on, returning them with the assignments they had. A load its
+// source upserted again since it was reviewed is no longer flagged and stays.
+func (r *LoadRepository) DeleteStale(ctx context.Context, ids []int, today time.Time) ([]models.LoadWithAssignments, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH deleted AS (
+			DELETE FROM loads
+			WHERE id = ANY($1) AND stale_since IS NOT NULL AND date >= $2
+			RETURNING id, external_id, title, source, url, date, end_date, spread
+		)
+		SELECT d.id, d.external_id, d.title, d.source, d.url, d.date, d.end_date, d.spread, la.person_email, la.weight
+		FROM deleted d
+		LEFT JOIN load_assignments la ON la.load_id = d.id
+		ORDER BY d.id, la.person_email`, ids, today.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete stale loads: %w", err)
+	}
+	return scanDeletedLoads(rows)
+}
+
 // AddAssignees adds one or more assignees to a load
 // Uses INSERT ON CONFLICT to handle duplicate assignments (updates weight if assignee already exists)
 func (r *LoadRepository) AddAssignees(ctx context.Context, loadID int, assignments []models.LoadAssignment) error {
@@ -390,8 +1172,259 @@ func (r *LoadRepository) RemoveAssignee(ctx context.Context, loadID int, personE
 	// Check if any rows were affected
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("assignee not found for this load")
+		return ErrAssignmentNotFound
+	}
+
+	return nil
+}
+
+// ListAssignedLoadIDs returns the loads assigned to a person that last through
+// from or later, optionally only those of one source
+func (r *LoadRepository) ListAssignedLoadIDs(ctx context.Context, personEmail string, from time.Time, source string) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.id FROM loads l
+		 JOIN load_assignments la ON la.load_id = l.id
+		 WHERE la.person_email = $1
+		   AND ($3 = '' OR l.source = $3)
+		   AND (COALESCE(l.end_date, l.date) >= $2 OR EXISTS (
+		     SELECT 1 FROM load_occurrences lo
+		     WHERE lo.load_id = l.id AND lo.date + (COALESCE(l.end_date, l.date) - l.date) >= $2))
+		 ORDER BY l.id`,
+		personEmail, from, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assigned loads: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan assigned load: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read assigned loads: %w", err)
+	}
+	return ids, nil
+}
+
+// Reassign moves a load's assignment from one person to another. If the new
+// assignee already has the load, the weights are added together.
+func (r *LoadRepository) Reassign(ctx context.Context, loadID int, from, to string) error {
+	result, err := r.pool.Exec(ctx,
+		`WITH moved AS (
+		   DELETE FROM load_assignments WHERE load_id = $1 AND person_email = $2
+		   RETURNING weight
+		 )
+		 INSERT INTO load_assignments (load_id, person_email, weight)
+		 SELECT $1, $3, weight FROM moved
+		 ON CONFLICT (load_id, person_email) DO UPDATE SET
+		   weight = load_assignments.weight + EXCLUDED.weight`,
+		loadID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to reassign load: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAssignmentNotFound
+	}
+	return nil
+}
+
+// Acknowledge records that an assignee has seen a load. Acknowledging again
+// keeps the first time.
+func (r *LoadRepository) Acknowledge(ctx context.Context, loadID int, personEmail string) (time.Time, error) {
+	var acknowledgedAt time.Time
+	err := r.pool.QueryRow(ctx,
+		`UPDATE load_acknowledgments k
+		 SET acknowledged_at = COALESCE(k.acknowledged_at, NOW())
+		 FROM load_assignments la
+		 WHERE la.load_id = k.load_id AND la.person_email = k.person_email
+		   AND k.load_id = $1 AND k.person_email = $2
+		 RETURNING k.acknowledged_at`,
+		loadID, personEmail).Scan(&acknowledgedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to acknowledge load: %w", err)
+	}
+
+	return acknowledgedAt, nil
+}
+
+// GetPendingAcknowledgments returns a person's unacknowledged assignments on
+// or after from, in date order
+func (r *LoadRepository) GetPendingAcknowledgments(ctx context.Context, personEmail string, from time.Time) ([]models.PendingAcknowledgment, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.id, l.title, l.date, la.person_email, la.weight, k.assigned_at
+		 FROM load_acknowledgments k
+		 JOIN load_assignments la ON la.load_id = k.load_id AND la.person_email = k.person_email
+		 JOIN loads l ON l.id = k.load_id
+		 WHERE k.person_email = $1 AND k.acknowledged_at IS NULL AND l.date >= $2
+		 ORDER BY l.date, l.id`,
+		personEmail, from.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending acknowledgments: %w", err)
+	}
+	return scanPendingAcknowledgments(rows)
+}
+
+// GetDueAcknowledgmentReminders returns unacknowledged assignments of at
+// least minWeight, on loads from today on, assigned before cutoff and not yet
+// reminded about
+func (r *LoadRepository) GetDueAcknowledgmentReminders(ctx context.Context, today, cutoff time.Time, minWeight float64) ([]models.PendingAcknowledgment, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.id, l.title, l.date, la.person_email, la.weight, k.assigned_at
+		 FROM load_acknowledgments k
+		 JOIN load_assignments la ON la.load_id = k.load_id AND la.person_email = k.person_email
+		 JOIN loads l ON l.id = k.load_id
+		 WHERE k.acknowledged_at IS NULL AND k.reminded_at IS NULL
+		   AND k.assigned_at <= $2 AND la.weight >= $3 AND l.date >= $1
+		 ORDER BY k.assigned_at, l.id, la.person_email`,
+		today.Truncate(24*time.Hour), cutoff, minWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due acknowledgment reminders: %w", err)
+	}
+	return scanPendingAcknowledgments(rows)
+}
+
+// MarkReminded records that an assignee was reminded to acknowledge a load,
+// so they are reminded once
+func (r *LoadRepository) MarkReminded(ctx context.Context, loadID int, personEmail string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE load_acknowledgments SET reminded_at = NOW()
+		 WHERE load_id = $1 AND person_email = $2`,
+		loadID, personEmail)
+	if err != nil {
+		return fmt.Errorf("failed to mark acknowledgment reminder: %w", err)
+	}
+
+	return nil
+}
+
+// GetLoadAnomalies returns active persons' days from today on whose load is
+// over factor times their baseline, the average load of the days with any
+// in the 30 days before today, leaving out days already alerted on. Persons
+// with no load in those 30 days have no baseline and are left out.
+func (r *LoadRepository) GetLoadAnomalies(ctx context.Context, today time.Time, factor float64) ([]models.LoadAnomaly, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH daily AS (
+			SELECT la.person_email, ld.date, SUM(la.weight * ld.share) AS load
+			FROM load_assignments la
+			JOIN load_days ld ON ld.load_id = la.load_id
+			JOIN entities e ON e.id = la.person_email AND e.type = 'person' AND e.archived_at IS NULL
+			WHERE ld.date >= $1::date - 30
+			GROUP BY la.person_email, ld.date
+		), baselines AS (
+			SELECT person_email, AVG(load) AS baseline
+			FROM daily
+			WHERE date < $1 AND load > 0
+			GROUP BY person_email
+		)
+		SELECT d.person_email, d.date, d.load, b.baseline
+		FROM daily d
+		JOIN baselines b ON b.person_email = d.person_email
+		WHERE d.date >= $1 AND d.load > $2 * b.baseline
+		  AND NOT EXISTS (
+		    SELECT 1 FROM load_anomalies a WHERE a.person_email = d.person_email AND a.date = d.date
+		  )
+		ORDER BY d.date, d.person_email`,
+		today.Truncate(24*time.Hour), factor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := []models.LoadAnomaly{}
+	for rows.Next() {
+		var a models.LoadAnomaly
+		if err := rows.Scan(&a.PersonEmail, &a.Date, &a.Load, &a.Baseline); err != nil {
+			return nil, fmt.Errorf("failed to scan load anomaly: %w", err)
+		}
+		anomalies = append(anomalies, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load anomalies: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+// MarkAnomalyAlerted records that a person-day's load anomaly was alerted
+// on, so it is alerted on once
+func (r *LoadRepository) MarkAnomalyAlerted(ctx context.Context, a models.LoadAnomaly) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO load_anomalies (person_email, date, load, baseline)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (person_email, date) DO NOTHING`,
+		a.PersonEmail, a.Date, a.Load, a.Baseline)
+	if err != nil {
+		return fmt.Errorf("failed to mark load anomaly: %w", err)
 	}
 
 	return nil
 }
+
+// scanPendingAcknowledgments reads and closes rows of load ID, title, date,
+// assignee, weight, and assignment time
+func scanPendingAcknowledgments(rows pgx.Rows) ([]models.PendingAcknowledgment, error) {
+	defer rows.Close()
+
+	pending := []models.PendingAcknowledgment{}
+	for rows.Next() {
+		var p models.PendingAcknowledgment
+		if err := rows.Scan(&p.LoadID, &p.Title, &p.Date, &p.PersonEmail, &p.Weight, &p.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending acknowledgment: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pending acknowledgments: %w", err)
+	}
+
+	return pending, nil
+}
+
+// RecordActual records the effort a person actually spent on a load assigned
+// to them, dated on or before today, next to their current weight. Recording
+// again replaces the previous actual.
+func (r *LoadRepository) RecordActual(ctx context.Context, loadID int, personEmail string, actual float64, today time.Time) (*models.LoadActual, error) {
+	var planned *float64
+	var recordedAt *time.Time
+	err := r.pool.QueryRow(ctx,
+		`WITH a AS (
+		   SELECT la.load_id, la.person_email, la.weight, l.date
+		   FROM load_assignments la
+		   JOIN loads l ON l.id = la.load_id
+		   WHERE la.load_id = $1 AND la.person_email = $2
+		 ), rec AS (
+		   INSERT INTO load_actuals (load_id, person_email, planned, actual)
+		   SELECT load_id, person_email, weight, $3 FROM a WHERE date <= $4
+		   ON CONFLICT (load_id, person_email) DO UPDATE SET
+		     planned = EXCLUDED.planned,
+		     actual = EXCLUDED.actual,
+		     recorded_at = NOW()
+		   RETURNING planned, recorded_at
+		 )
+		 SELECT rec.planned, rec.recorded_at FROM a LEFT JOIN rec ON true`,
+		loadID, personEmail, actual, today.Truncate(24*time.Hour)).Scan(&planned, &recordedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record actual effort: %w", err)
+	}
+	if planned == nil {
+		return nil, ErrLoadNotPast
+	}
+
+	return &models.LoadActual{
+		LoadID:      loadID,
+		PersonEmail: personEmail,
+		Planned:     *planned,
+		Actual:      actual,
+		RecordedAt:  *recordedAt,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoteNotFound = errors.New("note not found")
+
+type NoteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNoteRepository(pool *pgxpool.Pool) *NoteRepository {
+	return &NoteRepository{pool: pool}
+}
+
+// AddLoadNote stores a comment on a load
+func (r *NoteRepository) AddLoadNote(ctx context.Context, authorEmail string, loadID int, body string) (*models.Note, error) {
+	n := &models.Note{AuthorEmail: authorEmail, LoadID: &loadID, Body: body}
+	var date time.Time
+	err := r.pool.QueryRow(ctx,
+		`WITH l AS (
+		   SELECT id, title, date FROM loads WHERE id = $2
+		 ), n AS (
+		   INSERT INTO notes (author_email, load_id, body)
+		   SELECT $1, id, $3 FROM l
+		   RETURNING id, created_at
+		 )
+		 SELECT n.id, n.created_at, l.title, l.date FROM n, l`,
+		authorEmail, loadID, body).Scan(&n.ID, &n.CreatedAt, &n.LoadTitle, &date)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLoadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add load note: %w", err)
+	}
+	n.Date = date.Format("2006-01-02")
+
+	return n, nil
+}
+
+// AddDayNote stores a note on the author's own day
+func (r *NoteRepository) AddDayNote(ctx context.Context, authorEmail string, date time.Time, body string) (*models.Note, error) {
+	n := &models.Note{AuthorEmail: authorEmail, Date: date.Format("2006-01-02"), Body: body}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO notes (author_email, note_date, body)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		authorEmail, date, body).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add day note: %w", err)
+	}
+
+	return n, nil
+}
+
+// Delete removes one of the author's notes
+func (r *NoteRepository) Delete(ctx context.Context, authorEmail string, id int) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM notes WHERE author_email = $1 AND id = $2`, authorEmail, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNoteNotFound
+	}
+
+	return nil
+}
+
+// ListForDay returns the notes shown in an entity's day details: those on
+// the given loads, and the day notes of the entity or, for a group, of its
+// members. Oldest first.
+func (r *NoteRepository) ListForDay(ctx context.Context, entityID string, date time.Time, loadIDs []int) ([]models.Note, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT n.id, n.author_email, n.load_id, COALESCE(l.title, ''), COALESCE(n.note_date, l.date), n.body, n.created_at
+		 FROM notes n
+		 LEFT JOIN loads l ON l.id = n.load_id
+		 WHERE n.load_id = ANY($3)
+		    OR (n.note_date = $2 AND (n.author_email = $1 OR n.author_email IN (
+		      SELECT person_email FROM group_members WHERE group_id = $1)))
+		 ORDER BY n.created_at, n.id`,
+		entityID, date.Truncate(24*time.Hour), loadIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []models.Note{}
+	for rows.Next() {
+		var n models.Note
+		var day time.Time
+		if err := rows.Scan(&n.ID, &n.AuthorEmail, &n.LoadID, &n.LoadTitle, &day, &n.Body, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		n.Date = day.Format("2006-01-02")
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// GetEntityInfo returns the notes and links kept on an entity, empty when
+// none were ever set
+func (r *NoteRepository) GetEntityInfo(ctx context.Context, entityID string) (*models.EntityInfo, error) {
+	info := &models.EntityInfo{EntityID: entityID, Links: []models.EntityLink{}}
+	var updatedAt time.Time
+	err := r.pool.QueryRow(ctx,
+		`SELECT notes, links, updated_by, updated_at FROM entity_info WHERE entity_id = $1`,
+		entityID).Scan(&info.Notes, &info.Links, &info.UpdatedBy, &updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return info, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity info: %w", err)
+	}
+	info.UpdatedAt = &updatedAt
+
+	return info, nil
+}
+
+// PutEntityInfo replaces the notes and links kept on an entity
+func (r *NoteRepository) PutEntityInfo(ctx context.Context, info *models.EntityInfo) error {
+	var updatedAt time.Time
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO entity_info (entity_id, notes, links, updated_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (entity_id) DO UPDATE SET
+			notes = EXCLUDED.notes,
+			links = EXCLUDED.links,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		 RETURNING updated_at`,
+		info.EntityID, info.Notes, info.Links, info.UpdatedBy).Scan(&updatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrEntityNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save entity info: %w", err)
+	}
+	info.UpdatedAt = &updatedAt
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// overloadedPersonDays selects active persons' days from $1 on whose load is
+// over their effective capacity
+const overloadedPersonDays = `
+	WITH overloaded AS (
+		SELECT la.person_email, ld.date
+		FROM load_assignments la
+		JOIN load_days ld ON ld.load_id = la.load_id
+		JOIN entities e ON e.id = la.person_email AND e.type = 'person' AND e.archived_at IS NULL
+		LEFT JOIN capacity_overrides co ON co.entity_id = la.person_email AND co.date = ld.date
+		LEFT JOIN weekly_capacity wc ON wc.entity_id = la.person_email AND wc.weekday = EXTRACT(ISODOW FROM ld.date)
+		WHERE ld.date >= $1
+		GROUP BY la.person_email, ld.date, e.default_capacity, co.capacity, wc.capacity
+		HAVING SUM(la.weight * ld.share) > COALESCE(co.capacity, wc.capacity, e.default_capacity)
+	)`
+
+type OverloadRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOverloadRepository(pool *pgxpool.Pool) *OverloadRepository {
+	return &OverloadRepository{pool: pool}
+}
+
+// Sweep records overload transitions for person-days from today on, as seen
+// at now: days that went over capacity are opened (or reopened, keeping when
+// they first did) and tracked days back under capacity are resolved. Past
+// days are left as they were when their date passed.
+func (r *OverloadRepository) Sweep(ctx context.Context, today, now time.Time) (opened, resolved int64, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	today = today.Truncate(24 * time.Hour)
+	tag, err := tx.Exec(ctx, overloadedPersonDays+`
+		INSERT INTO overload_days (person_email, date, overloaded_at)
+		SELECT person_email, date, $2 FROM overloaded
+		ON CONFLICT (person_email, date) DO UPDATE SET resolved_at = NULL
+		WHERE overload_days.resolved_at IS NOT NULL`,
+		today, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to record overloaded days: %w", err)
+	}
+	opened = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, overloadedPersonDays+`
+		UPDATE overload_days od SET resolved_at = $2
+		WHERE od.resolved_at IS NULL AND od.date >= $1
+		  AND NOT EXISTS (
+		    SELECT 1 FROM overloaded o WHERE o.person_email = od.person_email AND o.date = od.date
+		  )`,
+		today, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to record resolved days: %w", err)
+	}
+	resolved = tag.RowsAffected()
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return opened, resolved, nil
+}
+
+// ListGroupOverloadDays returns the tracked overload days between from and to
+// of every group's members, ordered by group, date, and person
+func (r *OverloadRepository) ListGroupOverloadDays(ctx context.Context, from, to time.Time) ([]models.GroupOverloadDay, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT gm.group_id, od.person_email, od.date, od.overloaded_at, od.resolved_at
+		 FROM overload_days od
+		 JOIN group_members gm ON gm.person_email = od.person_email
+		 WHERE od.date >= $1 AND od.date <= $2
+		 ORDER BY gm.group_id, od.date, od.person_email`,
+		from.Truncate(24*time.Hour), to.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overload days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []models.GroupOverloadDay
+	for rows.Next() {
+		var d models.GroupOverloadDay
+		if err := rows.Scan(&d.GroupID, &d.PersonEmail, &d.Date, &d.OverloadedAt, &d.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan overload day: %w", err)
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get overload days: %w", err)
+	}
+
+	return days, nil
+}
+
+// GetMetrics counts all tracked overload days by state as of today
+func (r *OverloadRepository) GetMetrics(ctx context.Context, today time.Time) (*models.OverloadMetrics, error) {
+	m := &models.OverloadMetrics{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE resolved_at IS NULL AND date >= $1),
+			COUNT(*) FILTER (WHERE resolved_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE resolved_at IS NULL AND date < $1),
+			COALESCE(SUM(EXTRACT(EPOCH FROM resolved_at - overloaded_at)), 0)::float8
+		 FROM overload_days`,
+		today.Truncate(24*time.Hour)).Scan(&m.Open, &m.Resolved, &m.Unresolved, &m.ResolutionSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overload metrics: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Onboard creates a person, adds them to groups, and sets their capacity
+// overrides in one transaction, so a failure leaves nothing half-created.
+// An archived person with the same email is restored instead; an active
+// entity with it fails with ErrEntityExists.
+func (r *EntityRepository) Onboard(ctx context.Context, person *models.Entity, groups []string, overrides []models.CapacityOverride) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	person.Skills = normalizeSkills(person.Skills)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO entities (id, title, type, employee_id, default_capacity, skills, manager_email)
+		 VALUES ($1, $2, 'person', $3, $4, $5, $6)
+		 ON CONFLICT (id) DO UPDATE SET
+		   title = EXCLUDED.title,
+		   employee_id = EXCLUDED.employee_id,
+		   default_capacity = EXCLUDED.default_capacity,
+		   skills = EXCLUDED.skills,
+		   manager_email = EXCLUDED.manager_email,
+		   archived_at = NULL
+		 WHERE entities.type = 'person' AND entities.archived_at IS NOT NULL
+		 RETURNING created_at`,
+		person.ID, person.Title, person.EmployeeID, person.DefaultCapacity, person.Skills, person.ManagerEmail).Scan(&person.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrEntityExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create person: %w", err)
+	}
+	person.Type = models.EntityTypePerson
+	person.ArchivedAt = nil
+
+	if len(groups) > 0 {
+		rows, err := tx.Query(ctx,
+			`SELECT g FROM unnest($1::text[]) AS g
+			 WHERE NOT EXISTS (
+			   SELECT 1 FROM entities e
+			   WHERE e.id = g AND e.type = 'group' AND e.archived_at IS NULL
+			 )`, groups)
+		if err != nil {
+			return fmt.Errorf("failed to check groups: %w", err)
+		}
+		var missing []string
+		for rows.Next() {
+			var g string
+			if err := rows.Scan(&g); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan group: %w", err)
+			}
+			missing = append(missing, g)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to check groups: %w", err)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrGroupNotFound, strings.Join(missing, ", "))
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO group_members (group_id, person_email)
+			 SELECT g, $2 FROM unnest($1::text[]) AS g
+			 ON CONFLICT DO NOTHING`,
+			groups, person.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add group memberships: %w", err)
+		}
+	}
+
+	if len(overrides) > 0 {
+		dates := make([]time.Time, 0, len(overrides))
+		capacities := make([]float64, 0, len(overrides))
+		for _, o := range overrides {
+			dates = append(dates, o.Date.Truncate(24*time.Hour))
+			capacities = append(capacities, o.Capacity)
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO capacity_overrides (entity_id, date, capacity)
+			 SELECT $1, d, c FROM unnest($2::date[], $3::float8[]) AS o(d, c)
+			 ON CONFLICT (entity_id, date) DO UPDATE SET capacity = EXCLUDED.capacity`,
+			person.ID, dates, capacities)
+		if err != nil {
+			return fmt.Errorf("failed to set capacity overrides: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Offboard archives a person in one transaction: it removes them from loads
+// and capacity overrides after lastDay, and ends their sessions. Group
+// memberships and past loads are kept, so historical heatmaps stay intact.
+func (r *EntityRepository) Offboard(ctx context.Context, email string, lastDay time.Time) (*models.OffboardPersonResponse, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityType models.EntityType
+	var archivedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT type, archived_at FROM entities WHERE id = $1 FOR UPDATE`, email).Scan(&entityType, &archivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	if entityType != models.EntityTypePerson {
+		return nil, ErrNotAPerson
+	}
+	if archivedAt != nil {
+		return nil, ErrEntityArchived
+	}
+
+	lastDay = lastDay.Truncate(24 * time.Hour)
+	result := &models.OffboardPersonResponse{
+		Email:              email,
+		LastDay:            lastDay.Format("2006-01-02"),
+		Groups:             []string{},
+		RemovedAssignments: []models.OffboardedAssignment{},
+	}
+
+	// RETURNING sees the other assignees as they were before this delete,
+	// which is what "unassigned" needs
+	rows, err := tx.Query(ctx,
+		`DELETE FROM load_assignments la
+		 USING loads l
+		 WHERE la.load_id = l.id AND la.person_email = $1 AND l.date > $2
+		 RETURNING l.id, l.title, l.date, la.weight, NOT EXISTS (
+		   SELECT 1 FROM load_assignments o WHERE o.load_id = l.id AND o.person_email <> $1
+		 )`, email, lastDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove future assignments: %w", err)
+	}
+	for rows.Next() {
+		var a models.OffboardedAssignment
+		if err := rows.Scan(&a.LoadID, &a.Title, &a.Date, &a.Weight, &a.Unassigned); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan removed assignment: %w", err)
+		}
+		result.RemovedAssignments = append(result.RemovedAssignments, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to remove future assignments: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM capacity_overrides WHERE entity_id = $1 AND date > $2`, email, lastDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove future capacity overrides: %w", err)
+	}
+
+	rows, err = tx.Query(ctx,
+		`SELECT group_id FROM group_members WHERE person_email = $1 ORDER BY group_id`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	for rows.Next() {
+		var groupID string
+		if err := rows.Scan(&groupID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		result.Groups = append(result.Groups, groupID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE email = $1`, email); err != nil {
+		return nil, fmt.Errorf("failed to end sessions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM otp_records WHERE email = $1`, email); err != nil {
+		return nil, fmt.Errorf("failed to remove pending OTP: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE entities SET archived_at = NOW() WHERE id = $1`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive person: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// ChangeID moves an entity to a new ID in one transaction, such as a person
+// whose email changed. Foreign keys to entities follow the ID on their own;
+// the columns holding IDs without one, sessions among them, are rewritten
+// here, so history, memberships and logins carry over. Domain events are
+// append-only and keep naming the old ID. The old ID is tombstoned for
+// clients syncing the entity list. A new ID already in use fails with
+// ErrEntityExists.
+func (r *EntityRepository) ChangeID(ctx context.Context, oldID, newID string) (*models.ChangeEntityIDResponse, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result := &models.ChangeEntityIDResponse{OldID: oldID, NewID: newID}
+	var private bool
+	err = tx.QueryRow(ctx,
+		`SELECT type, private FROM entities WHERE id = $1 FOR UPDATE`, oldID).Scan(&result.Type, &private)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	var taken bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM entities WHERE id = $1)`, newID).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check new ID: %w", err)
+	}
+	if taken {
+		return nil, ErrEntityExists
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE entities SET id = $2 WHERE id = $1`, oldID, newID); err != nil {
+		return nil, fmt.Errorf("failed to change entity ID: %w", err)
+	}
+
+	rewrites := []struct{ what, query string }{
+		{"managers", `UPDATE entities SET manager_email = $2 WHERE manager_email = $1`},
+		{"confidential loads", `UPDATE loads SET confidential_group = $2 WHERE confidential_group = $1`},
+		{"capacity audit log", `UPDATE capacity_audit_log SET actor_email = $2 WHERE actor_email = $1`},
+		{"capacity approvals", `UPDATE capacity_change_requests SET decided_by = $2 WHERE decided_by = $1`},
+		{"settings", `UPDATE settings SET updated_by = $2 WHERE updated_by = $1`},
+		{"heatmap tombstones", `UPDATE heatmap_tombstones SET entity_id = $2 WHERE entity_id = $1`},
+		{"incident notes", `UPDATE incident_notes SET author_email = $2 WHERE author_email = $1`},
+		{"entity notes", `UPDATE entity_info SET updated_by = $2 WHERE updated_by = $1`},
+		{"presence", `UPDATE presence SET person_email = $2 WHERE person_email = $1`},
+		{"presence", `UPDATE presence SET subject = 'entity:' || $2 WHERE subject = 'entity:' || $1`},
+	}
+	for _, rw := range rewrites {
+		if _, err := tx.Exec(ctx, rw.query, oldID, newID); err != nil {
+			return nil, fmt.Errorf("failed to rewrite %s: %w", rw.what, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM otp_records WHERE email = $1`, oldID); err != nil {
+		return nil, fmt.Errorf("failed to remove pending OTP: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE sessions SET email = $2 WHERE email = $1`, oldID, newID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move sessions: %w", err)
+	}
+	result.Sessions = int(tag.RowsAffected())
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO entity_tombstones (id, type, private) VALUES ($1, $2, $3)`, oldID, result.Type, private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tombstone old ID: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// ImportGroups creates the groups and members named by rows and adds the
+// memberships in one transaction. Entities that already exist are kept as
+// they are, so importing the same rows again changes nothing. Members not
+// yet known are created as persons with newPersonCapacity. Names that exist
+// with the other type fail with ErrNotAGroup or ErrNotAPerson, and archived
+// ones with ErrEntityArchived, before anything is written.
+func (r *EntityRepository) ImportGroups(ctx context.Context, rows []models.GroupImportRow, newPersonCapacity float64) (*models.GroupImportResponse, error) {
+	groups := make([]string, 0, len(rows))
+	members := make([]string, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, row.Group)
+		members = append(members, row.Member)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	existing, err := tx.Query(ctx,
+		`SELECT id, type, archived_at IS NOT NULL FROM entities
+		 WHERE id = ANY($1::text[]) OR id = ANY($2::text[])`, groups, members)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check entities: %w", err)
+	}
+	isGroup := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		isGroup[g] = true
+	}
+	var notGroups, notPersons, archived []string
+	for existing.Next() {
+		var id string
+		var entityType models.EntityType
+		var isArchived bool
+		if err := existing.Scan(&id, &entityType, &isArchived); err != nil {
+			existing.Close()
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		switch {
+		case isArchived:
+			archived = append(archived, id)
+		case isGroup[id] && entityType != models.EntityTypeGroup:
+			notGroups = append(notGroups, id)
+		case !isGroup[id] && entityType != models.EntityTypePerson:
+			notPersons = append(notPersons, id)
+		}
+	}
+	existing.Close()
+	if err := existing.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check entities: %w", err)
+	}
+	switch {
+	case len(notGroups) > 0:
+		return nil, fmt.Errorf("%w: %s", ErrNotAGroup, strings.Join(notGroups, ", "))
+	case len(notPersons) > 0:
+		return nil, fmt.Errorf("%w: %s", ErrNotAPerson, strings.Join(notPersons, ", "))
+	case len(archived) > 0:
+		return nil, fmt.Errorf("%w: %s", ErrEntityArchived, strings.Join(archived, ", "))
+	}
+
+	result := &models.GroupImportResponse{}
+
+	created, err := tx.Query(ctx,
+		`INSERT INTO entities (id, title, type)
+		 SELECT DISTINCT g, g, 'group' FROM unnest($1::text[]) AS g
+		 ON CONFLICT (id) DO NOTHING
+		 RETURNING id`, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create groups: %w", err)
+	}
+	if result.GroupsCreated, err = scanStrings(created); err != nil {
+		return nil, fmt.Errorf("failed to create groups: %w", err)
+	}
+
+	created, err = tx.Query(ctx,
+		`INSERT INTO entities (id, title, type, default_capacity)
+		 SELECT DISTINCT email, email, 'person', $2 FROM unnest($1::text[]) AS email
+		 ON CONFLICT (id) DO NOTHING
+		 RETURNING id`, members, newPersonCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create members: %w", err)
+	}
+	if result.PeopleCreated, err = scanStrings(created); err != nil {
+		return nil, fmt.Errorf("failed to create members: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO group_members (group_id, person_email)
+		 SELECT DISTINCT g, m FROM unnest($1::text[], $2::text[]) AS r(g, m)
+		 ON CONFLICT DO NOTHING`, groups, members)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add group memberships: %w", err)
+	}
+	result.MembershipsAdded = int(tag.RowsAffected())
+	result.MembershipsExisting = len(rows) - result.MembershipsAdded
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// scanStrings collects a single text column, sorted, never nil
+func scanStrings(rows pgx.Rows) ([]string, error) {
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+	return values, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrPinNotFound = errors.New("pin not found")
+
+type PinRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPinRepository(pool *pgxpool.Pool) *PinRepository {
+	return &PinRepository{pool: pool}
+}
+
+// Pin pins a load for a user. Pinning a load again keeps the original time.
+// Another group's confidential load is not found.
+func (r *PinRepository) Pin(ctx context.Context, personEmail string, loadID int) (*models.Pin, error) {
+	p := &models.Pin{LoadID: loadID}
+	var date time.Time
+	err := r.pool.QueryRow(ctx,
+		`WITH l AS (
+		   SELECT id, title, date FROM loads WHERE id = $2
+		     AND (confidential_group IS NULL OR confidential_group IN (
+		       SELECT group_id FROM group_members WHERE person_email = $1))
+		 ), p AS (
+		   INSERT INTO pins (person_email, load_id)
+		   SELECT $1, id FROM l
+		   ON CONFLICT (person_email, load_id) DO UPDATE SET pinned_at = pins.pinned_at
+		   RETURNING pinned_at
+		 )
+		 SELECT l.title, l.date, p.pinned_at FROM p, l`,
+		personEmail, loadID).Scan(&p.Title, &date, &p.PinnedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLoadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin load: %w", err)
+	}
+	p.Date = date.Format("2006-01-02")
+
+	return p, nil
+}
+
+// Unpin removes a user's pin
+func (r *PinRepository) Unpin(ctx context.Context, personEmail string, loadID int) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM pins WHERE person_email = $1 AND load_id = $2`, personEmail, loadID)
+	if err != nil {
+		return fmt.Errorf("failed to unpin load: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPinNotFound
+	}
+
+	return nil
+}
+
+// List returns a user's pins on loads from the given date on, in date order.
+// Pins on confidential loads of groups the user has left are skipped, here
+// and in ListForEntity.
+func (r *PinRepository) List(ctx context.Context, personEmail string, from time.Time) ([]models.Pin, error) {
+	return r.query(ctx,
+		`SELECT p.load_id, l.title, l.date, p.pinned_at
+		 FROM pins p
+		 JOIN loads l ON l.id = p.load_id
+		 WHERE p.person_email = $1 AND l.date >= $2
+		   AND (l.confidential_group IS NULL OR l.confidential_group IN (
+		     SELECT group_id FROM group_members WHERE person_email = $1))
+		 ORDER BY l.date, p.pinned_at, p.load_id`,
+		personEmail, from)
+}
+
+// ListForEntity returns a user's pins on loads assigned to an entity, or to
+// a member of a group entity, between start and end inclusive
+func (r *PinRepository) ListForEntity(ctx context.Context, personEmail, entityID string, start, end time.Time) ([]models.Pin, error) {
+	return r.query(ctx,
+		`SELECT p.load_id, l.title, l.date, p.pinned_at
+		 FROM pins p
+		 JOIN loads l ON l.id = p.load_id
+		 WHERE p.person_email = $1 AND l.date BETWEEN $3 AND $4
+		   AND EXISTS (
+		     SELECT 1 FROM load_assignments la
+		     WHERE la.load_id = l.id AND (la.person_email = $2 OR la.person_email IN (
+		       SELECT person_email FROM group_members WHERE group_id = $2)))
+		   AND (l.confidential_group IS NULL OR l.confidential_group IN (
+		     SELECT group_id FROM group_members WHERE person_email = $1))
+		 ORDER BY l.date, p.pinned_at, p.load_id`,
+		personEmail, entityID, start, end)
+}
+
+func (r *PinRepository) query(ctx context.Context, sql string, args ...interface{}) ([]models.Pin, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pins: %w", err)
+	}
+	defer rows.Close()
+
+	pins := []models.Pin{}
+	for rows.Next() {
+		var p models.Pin
+		var date time.Time
+		if err := rows.Scan(&p.LoadID, &p.Title, &date, &p.PinnedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pin: %w", err)
+		}
+		p.Date = date.Format("2006-01-02")
+		pins = append(pins, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pins: %w", err)
+	}
+
+	return pins, nil
+}
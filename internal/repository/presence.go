@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PresenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPresenceRepository(pool *pgxpool.Pool) *PresenceRepository {
+	return &PresenceRepository{pool: pool}
+}
+
+// Touch records that a person is on subject in mode now
+func (r *PresenceRepository) Touch(ctx context.Context, subject, personEmail, mode string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO presence (subject, person_email, mode, seen_at)
+		 VALUES ($1, $2, $3, NOW())
+		 ON CONFLICT (subject, person_email) DO UPDATE SET mode = EXCLUDED.mode, seen_at = NOW()`,
+		subject, personEmail, mode)
+	if err != nil {
+		return fmt.Errorf("failed to record presence: %w", err)
+	}
+	return nil
+}
+
+// ListOthers returns everyone but personEmail seen on subject since, editors
+// first
+func (r *PresenceRepository) ListOthers(ctx context.Context, subject, personEmail string, since time.Time) ([]models.Presence, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT p.person_email, COALESCE(e.title, p.person_email), p.mode, p.seen_at
+		 FROM presence p
+		 LEFT JOIN entities e ON e.id = p.person_email
+		 WHERE p.subject = $1 AND p.person_email <> $2 AND p.seen_at >= $3
+		 ORDER BY p.mode = 'editing' DESC, p.person_email`,
+		subject, personEmail, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presence: %w", err)
+	}
+	defer rows.Close()
+
+	others := []models.Presence{}
+	for rows.Next() {
+		var p models.Presence
+		if err := rows.Scan(&p.Email, &p.Name, &p.Mode, &p.SeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan presence: %w", err)
+		}
+		others = append(others, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read presence: %w", err)
+	}
+
+	return others, nil
+}
+
+// DeleteBefore removes the presence not seen since cutoff
+func (r *PresenceRepository) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM presence WHERE seen_at < $1`, cutoff); err != nil {
+		return fmt.Errorf("failed to delete stale presence: %w", err)
+	}
+	return nil
+}
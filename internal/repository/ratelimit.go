@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RateLimitRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRateLimitRepository(pool *pgxpool.Pool) *RateLimitRepository {
+	return &RateLimitRepository{pool: pool}
+}
+
+// Hit counts a hit on key and returns the hits in key's current window,
+// this one included, and when the window ends. A hit after the previous
+// window ended starts a new one.
+func (r *RateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	var hits int
+	var start time.Time
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO rate_limits (key, window_start, hits)
+		 VALUES ($1, NOW(), 1)
+		 ON CONFLICT (key) DO UPDATE SET
+			window_start = CASE WHEN rate_limits.window_start <= NOW() - $2 * INTERVAL '1 second'
+				THEN NOW() ELSE rate_limits.window_start END,
+			hits = CASE WHEN rate_limits.window_start <= NOW() - $2 * INTERVAL '1 second'
+				THEN 1 ELSE rate_limits.hits + 1 END
+		 RETURNING hits, window_start`,
+		key, window.Seconds()).Scan(&hits, &start)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to count rate limit hit: %w", err)
+	}
+	return hits, start.Add(window), nil
+}
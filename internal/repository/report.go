@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{pool: pool}
+}
+
+// GetEntities returns every person and group that was not archived before
+// from, so people who left during a period are still reported
+func (r *ReportRepository) GetEntities(ctx context.Context, from time.Time) ([]models.Entity, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, type, employee_id, default_capacity, skills, manager_email, private, region, capacity_mode, created_at, archived_at
+		 FROM entities WHERE archived_at IS NULL OR archived_at >= $1 ORDER BY type, title`,
+		from.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []models.Entity
+	for rows.Next() {
+		var e models.Entity
+		if err := rows.Scan(&e.ID, &e.Title, &e.Type, &e.EmployeeID, &e.DefaultCapacity, &e.Skills, &e.ManagerEmail, &e.Private, &e.Region, &e.CapacityMode, &e.CreatedAt, &e.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list report entities: %w", err)
+	}
+
+	return entities, nil
+}
+
+// GetDailyCapacities returns each entity's effective capacity on every day
+// from start to end until it was archived
+func (r *ReportRepository) GetDailyCapacities(ctx context.Context, start, end time.Time) ([]models.EntityDayCapacity, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT e.id, d::date, COALESCE(co.capacity, wc.capacity, e.default_capacity)
+		 FROM entities e
+		 CROSS JOIN generate_series($1::date, $2::date, INTERVAL '1 day') d
+		 LEFT JOIN capacity_overrides co ON co.entity_id = e.id AND co.date = d::date
+		 LEFT JOIN weekly_capacity wc ON wc.entity_id = e.id AND wc.weekday = EXTRACT(ISODOW FROM d)
+		 WHERE e.archived_at IS NULL OR d::date <= e.archived_at::date`,
+		start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily capacities: %w", err)
+	}
+	defer rows.Close()
+
+	var capacities []models.EntityDayCapacity
+	for rows.Next() {
+		var c models.EntityDayCapacity
+		if err := rows.Scan(&c.EntityID, &c.Date, &c.Capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity: %w", err)
+		}
+		capacities = append(capacities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily capacities: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// GetDailySourceLoads returns each person's load per day and source from
+// start to end
+func (r *ReportRepository) GetDailySourceLoads(ctx context.Context, start, end time.Time) ([]models.SourceDayLoad, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT la.person_email, ld.date, COALESCE(l.source, ''), SUM(la.weight * ld.share)
+		 FROM load_days ld
+		 JOIN loads l ON l.id = ld.load_id
+		 JOIN load_assignments la ON la.load_id = l.id
+		 WHERE ld.date BETWEEN $1 AND $2
+		 GROUP BY la.person_email, ld.date, l.source`,
+		start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily loads: %w", err)
+	}
+	defer rows.Close()
+
+	var loads []models.SourceDayLoad
+	for rows.Next() {
+		var l models.SourceDayLoad
+		if err := rows.Scan(&l.PersonEmail, &l.Date, &l.Source, &l.Load); err != nil {
+			return nil, fmt.Errorf("failed to scan load: %w", err)
+		}
+		loads = append(loads, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily loads: %w", err)
+	}
+
+	return loads, nil
+}
+
+// GetMemberships returns the members of every group, keyed by group ID
+func (r *ReportRepository) GetMemberships(ctx context.Context) (map[string][]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT group_id, person_email FROM group_members`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[string][]string)
+	for rows.Next() {
+		var groupID, email string
+		if err := rows.Scan(&groupID, &email); err != nil {
+			return nil, fmt.Errorf("failed to scan membership: %w", err)
+		}
+		members[groupID] = append(members[groupID], email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetUtilizationReport returns the stored report for a quarter, or nil if
+// none has been generated
+func (r *ReportRepository) GetUtilizationReport(ctx context.Context, quarter string) (*models.UtilizationReport, error) {
+	var report models.UtilizationReport
+	err := r.pool.QueryRow(ctx,
+		`SELECT report FROM utilization_reports WHERE quarter = $1`, quarter).Scan(&report)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil // Not generated yet is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get utilization report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// SaveUtilizationReport stores a quarter's report unless one already is, so
+// the first generated report is the one that stays
+func (r *ReportRepository) SaveUtilizationReport(ctx context.Context, report *models.UtilizationReport) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO utilization_reports (quarter, report, generated_at)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (quarter) DO NOTHING`,
+		report.Quarter, report, report.GeneratedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save utilization report: %w", err)
+	}
+
+	return nil
+}
+
+// GetEffortSamples returns the planned and actual effort of every assignment
+// with a recorded actual on a load dated from start to end
+func (r *ReportRepository) GetEffortSamples(ctx context.Context, start, end time.Time) ([]models.EffortSample, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT COALESCE(l.source, ''), a.planned, a.actual
+		 FROM load_actuals a
+		 JOIN loads l ON l.id = a.load_id
+		 WHERE l.date BETWEEN $1 AND $2`,
+		start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get effort samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []models.EffortSample
+	for rows.Next() {
+		var s models.EffortSample
+		if err := rows.Scan(&s.Source, &s.Planned, &s.Actual); err != nil {
+			return nil, fmt.Errorf("failed to scan effort sample: %w", err)
+		}
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read effort samples: %w", err)
+	}
+
+	return samples, nil
+}
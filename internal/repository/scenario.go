@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrScenarioNotFound     = errors.New("scenario not found")
+	ErrScenarioExists       = errors.New("scenario already exists")
+	ErrScenarioLoadNotFound = errors.New("scenario load not found")
+)
+
+// Postgres error codes for constraint violations
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// ScenarioRepository stores what-if scenarios. Their loads and capacities
+// live in their own tables, so nothing here changes real heatmaps.
+type ScenarioRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewScenarioRepository(pool *pgxpool.Pool) *ScenarioRepository {
+	return &ScenarioRepository{pool: pool}
+}
+
+// Create creates a new scenario
+func (r *ScenarioRepository) Create(ctx context.Context, scenario *models.Scenario) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO scenarios (name, description) VALUES ($1, $2)
+		 RETURNING id, created_at`,
+		scenario.Name, scenario.Description).Scan(&scenario.ID, &scenario.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrScenarioExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create scenario: %w", err)
+	}
+
+	return nil
+}
+
+// List returns all scenarios, newest first
+func (r *ScenarioRepository) List(ctx context.Context) ([]models.Scenario, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, name, description, created_at FROM scenarios ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenarios: %w", err)
+	}
+	defer rows.Close()
+
+	scenarios := []models.Scenario{}
+	for rows.Next() {
+		var s models.Scenario
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scenario: %w", err)
+		}
+		scenarios = append(scenarios, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list scenarios: %w", err)
+	}
+
+	return scenarios, nil
+}
+
+// GetByID retrieves a scenario by its ID
+func (r *ScenarioRepository) GetByID(ctx context.Context, id int) (*models.Scenario, error) {
+	scenario := &models.Scenario{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, name, description, created_at FROM scenarios WHERE id = $1`, id).Scan(
+		&scenario.ID, &scenario.Name, &scenario.Description, &scenario.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrScenarioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario: %w", err)
+	}
+
+	return scenario, nil
+}
+
+// GetDetail retrieves a scenario with its loads and capacities
+func (r *ScenarioRepository) GetDetail(ctx context.Context, id int) (*models.ScenarioDetail, error) {
+	scenario, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.ScenarioDetail{
+		Scenario:   *scenario,
+		Loads:      []models.ScenarioLoad{},
+		Capacities: []models.ScenarioCapacity{},
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, scenario_id, title, date, person_email, weight
+		 FROM scenario_loads WHERE scenario_id = $1 ORDER BY date, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario loads: %w", err)
+	}
+	for rows.Next() {
+		var l models.ScenarioLoad
+		if err := rows.Scan(&l.ID, &l.ScenarioID, &l.Title, &l.Date, &l.PersonEmail, &l.Weight); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan scenario load: %w", err)
+		}
+		detail.Loads = append(detail.Loads, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get scenario loads: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx,
+		`SELECT scenario_id, entity_id, date, capacity
+		 FROM scenario_capacity_overrides WHERE scenario_id = $1 ORDER BY date, entity_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario capacities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c models.ScenarioCapacity
+		if err := rows.Scan(&c.ScenarioID, &c.EntityID, &c.Date, &c.Capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan scenario capacity: %w", err)
+		}
+		detail.Capacities = append(detail.Capacities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get scenario capacities: %w", err)
+	}
+
+	return detail, nil
+}
+
+// Delete deletes a scenario with its loads and capacities
+func (r *ScenarioRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM scenarios WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scenario: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrScenarioNotFound
+	}
+
+	return nil
+}
+
+// AddLoads adds hypothetical loads to a scenario in one statement. An unknown
+// person fails with ErrEntityNotFound and adds nothing.
+func (r *ScenarioRepository) AddLoads(ctx context.Context, loads []models.ScenarioLoad) error {
+	if len(loads) == 0 {
+		return nil
+	}
+
+	scenarioIDs := make([]int, len(loads))
+	titles := make([]string, len(loads))
+	dates := make([]time.Time, len(loads))
+	emails := make([]string, len(loads))
+	weights := make([]float64, len(loads))
+	for i, l := range loads {
+		scenarioIDs[i] = l.ScenarioID
+		titles[i] = l.Title
+		dates[i] = l.Date.Truncate(24 * time.Hour)
+		emails[i] = l.PersonEmail
+		weights[i] = l.Weight
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO scenario_loads (scenario_id, title, date, person_email, weight)
+		 SELECT * FROM unnest($1::int[], $2::text[], $3::date[], $4::text[], $5::float8[])`,
+		scenarioIDs, titles, dates, emails, weights)
+	if err != nil {
+		if missing := scenarioReferenceError(err); missing != nil {
+			return missing
+		}
+		return fmt.Errorf("failed to add scenario loads: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLoad removes a hypothetical load from a scenario
+func (r *ScenarioRepository) DeleteLoad(ctx context.Context, scenarioID, loadID int) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM scenario_loads WHERE scenario_id = $1 AND id = $2`, scenarioID, loadID)
+	if err != nil {
+		return fmt.Errorf("failed to delete scenario load: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrScenarioLoadNotFound
+	}
+
+	return nil
+}
+
+// SetCapacity sets a hypothetical capacity for an entity on a date. An
+// unknown entity fails with ErrEntityNotFound.
+func (r *ScenarioRepository) SetCapacity(ctx context.Context, capacity *models.ScenarioCapacity) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO scenario_capacity_overrides (scenario_id, entity_id, date, capacity)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (scenario_id, entity_id, date) DO UPDATE SET capacity = EXCLUDED.capacity`,
+		capacity.ScenarioID, capacity.EntityID, capacity.Date.Truncate(24*time.Hour), capacity.Capacity)
+
+	if err != nil {
+		if missing := scenarioReferenceError(err); missing != nil {
+			return missing
+		}
+		return fmt.Errorf("failed to set scenario capacity: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCapacity removes a hypothetical capacity, so the real one applies again
+func (r *ScenarioRepository) DeleteCapacity(ctx context.Context, scenarioID int, entityID string, date time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM scenario_capacity_overrides WHERE scenario_id = $1 AND entity_id = $2 AND date = $3`,
+		scenarioID, entityID, date.Truncate(24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to delete scenario capacity: %w", err)
+	}
+
+	return nil
+}
+
+// GetLoadOverlay returns the hypothetical load per day that a scenario adds
+// to an entity: a person's own scenario loads, or a group's members'
+func (r *ScenarioRepository) GetLoadOverlay(ctx context.Context, scenarioID int, entityID string, entityType models.EntityType, start, end time.Time) (map[time.Time]float64, error) {
+	query := `SELECT date, SUM(weight) FROM scenario_loads
+		 WHERE scenario_id = $1 AND person_email = $2 AND date BETWEEN $3 AND $4
+		 GROUP BY date`
+	if entityType == models.EntityTypeGroup {
+		query = `SELECT sl.date, SUM(sl.weight) FROM scenario_loads sl
+		 JOIN group_members gm ON gm.person_email = sl.person_email
+		 WHERE sl.scenario_id = $1 AND gm.group_id = $2 AND sl.date BETWEEN $3 AND $4
+		 GROUP BY sl.date`
+	}
+
+	rows, err := r.pool.Query(ctx, query,
+		scenarioID, entityID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario load overlay: %w", err)
+	}
+	defer rows.Close()
+
+	loads := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var load float64
+		if err := rows.Scan(&date, &load); err != nil {
+			return nil, fmt.Errorf("failed to scan scenario load: %w", err)
+		}
+		loads[time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)] = load
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get scenario load overlay: %w", err)
+	}
+
+	return loads, nil
+}
+
+// GetCapacityOverlay returns the hypothetical capacities a scenario sets for
+// an entity
+func (r *ScenarioRepository) GetCapacityOverlay(ctx context.Context, scenarioID int, entityID string, start, end time.Time) (map[time.Time]float64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT date, capacity FROM scenario_capacity_overrides
+		 WHERE scenario_id = $1 AND entity_id = $2 AND date BETWEEN $3 AND $4`,
+		scenarioID, entityID, start.Truncate(24*time.Hour), end.Truncate(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario capacity overlay: %w", err)
+	}
+	defer rows.Close()
+
+	capacities := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var capacity float64
+		if err := rows.Scan(&date, &capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan scenario capacity: %w", err)
+		}
+		capacities[time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)] = capacity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get scenario capacity overlay: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// scenarioReferenceError maps a foreign key violation on a scenario table to
+// the missing row: the scenario itself, or the entity it refers to
+func scenarioReferenceError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgForeignKeyViolation {
+		return nil
+	}
+	if strings.HasSuffix(pgErr.ConstraintName, "_scenario_id_fkey") {
+		return ErrScenarioNotFound
+	}
+	return ErrEntityNotFound
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StoredSettings are the settings keys that have been set, with their JSON
+// values, and the latest change to any of them
+type StoredSettings struct {
+	Values    map[string]json.RawMessage
+	UpdatedAt *time.Time
+	UpdatedBy string
+}
+
+type SettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSettingsRepository(pool *pgxpool.Pool) *SettingsRepository {
+	return &SettingsRepository{pool: pool}
+}
+
+// GetAll returns every settings key that has been set
+func (r *SettingsRepository) GetAll(ctx context.Context) (*StoredSettings, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT key, value, updated_at, updated_by FROM settings ORDER BY updated_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	defer rows.Close()
+
+	stored := &StoredSettings{Values: make(map[string]json.RawMessage)}
+	for rows.Next() {
+		var key string
+		var value json.RawMessage
+		var updatedAt time.Time
+		if err := rows.Scan(&key, &value, &updatedAt, &stored.UpdatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		stored.Values[key] = value
+		stored.UpdatedAt = &updatedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	return stored, nil
+}
+
+// Set stores settings keys with their JSON values, all or none, as changed
+// by actor
+func (r *SettingsRepository) Set(ctx context.Context, values map[string]json.RawMessage, actor string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for key, value := range values {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO settings (key, value, updated_by) VALUES ($1, $2, $3)
+			 ON CONFLICT (key) DO UPDATE
+			 SET value = EXCLUDED.value, updated_at = clock_timestamp(), updated_by = EXCLUDED.updated_by`,
+			key, value, actor)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit settings: %w", err)
+	}
+	return nil
+}
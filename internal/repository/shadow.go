@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrShadowSourceExists   = errors.New("source is already in shadow mode")
+	ErrShadowSourceNotFound = errors.New("source is not in shadow mode")
+)
+
+// shadowScenarioName names the scenario a source's shadow loads are kept in
+func shadowScenarioName(source string) string {
+	return "Shadow: " + source
+}
+
+// CreateShadowSource puts a source in shadow mode, creating the scenario its
+// upserted loads are kept in. A scenario already named after the source
+// fails with ErrScenarioExists.
+func (r *ScenarioRepository) CreateShadowSource(ctx context.Context, source string) (*models.ShadowSource, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM shadow_sources WHERE source = $1)`, source).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check shadow source: %w", err)
+	}
+	if exists {
+		return nil, ErrShadowSourceExists
+	}
+
+	shadow := &models.ShadowSource{Source: source}
+	description := fmt.Sprintf("Loads upserted by %s while it is in shadow mode", source)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO scenarios (name, description) VALUES ($1, $2) RETURNING id`,
+		shadowScenarioName(source), description).Scan(&shadow.ScenarioID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return nil, ErrScenarioExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shadow scenario: %w", err)
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO shadow_sources (source, scenario_id) VALUES ($1, $2) RETURNING created_at`,
+		source, shadow.ScenarioID).Scan(&shadow.CreatedAt)
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return nil, ErrShadowSourceExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shadow source: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return shadow, nil
+}
+
+// ListShadowSources returns the sources in shadow mode with how many loads
+// their scenarios hold, by source
+func (r *ScenarioRepository) ListShadowSources(ctx context.Context) ([]models.ShadowSource, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT s.source, s.scenario_id, s.created_at,
+		   (SELECT COUNT(*) FROM scenario_loads sl WHERE sl.scenario_id = s.scenario_id)
+		 FROM shadow_sources s ORDER BY s.source`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []models.ShadowSource{}
+	for rows.Next() {
+		var s models.ShadowSource
+		if err := rows.Scan(&s.Source, &s.ScenarioID, &s.CreatedAt, &s.Loads); err != nil {
+			return nil, fmt.Errorf("failed to scan shadow source: %w", err)
+		}
+		sources = append(sources, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list shadow sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// GetShadowScenario returns the scenario a source's upserted loads are kept
+// in, or ErrShadowSourceNotFound when the source is live
+func (r *ScenarioRepository) GetShadowScenario(ctx context.Context, source string) (int, error) {
+	var scenarioID int
+	err := r.pool.QueryRow(ctx,
+		`SELECT scenario_id FROM shadow_sources WHERE source = $1`, source).Scan(&scenarioID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrShadowSourceNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get shadow source: %w", err)
+	}
+
+	return scenarioID, nil
+}
+
+// DeleteShadowSource takes a source out of shadow mode, so its upserts are
+// saved as loads again. Its scenario is kept for comparison.
+func (r *ScenarioRepository) DeleteShadowSource(ctx context.Context, source string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM shadow_sources WHERE source = $1`, source)
+	if err != nil {
+		return fmt.Errorf("failed to delete shadow source: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrShadowSourceNotFound
+	}
+
+	return nil
+}
+
+// ReplaceShadowLoads replaces the loads a scenario holds for a source load's
+// external ID, one per assignee and day, in one transaction. An unknown
+// person fails with ErrEntityNotFound and changes nothing.
+func (r *ScenarioRepository) ReplaceShadowLoads(ctx context.Context, scenarioID int, externalID string, loads []models.ScenarioLoad) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM scenario_loads WHERE scenario_id = $1 AND external_id = $2`, scenarioID, externalID)
+	if err != nil {
+		return fmt.Errorf("failed to replace shadow loads: %w", err)
+	}
+
+	titles := make([]string, len(loads))
+	dates := make([]time.Time, len(loads))
+	emails := make([]string, len(loads))
+	weights := make([]float64, len(loads))
+	for i, l := range loads {
+		titles[i] = l.Title
+		dates[i] = l.Date.Truncate(24 * time.Hour)
+		emails[i] = l.PersonEmail
+		weights[i] = l.Weight
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO scenario_loads (scenario_id, external_id, title, date, person_email, weight)
+		 SELECT $1::int, $2::text, * FROM unnest($3::text[], $4::date[], $5::text[], $6::float8[])`,
+		scenarioID, externalID, titles, dates, emails, weights)
+	if err != nil {
+		if missing := scenarioReferenceError(err); missing != nil {
+			return missing
+		}
+		return fmt.Errorf("failed to add shadow loads: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteShadowLoads removes the loads a scenario holds for a source load's
+// external ID, failing with ErrLoadNotFound when it holds none
+func (r *ScenarioRepository) DeleteShadowLoads(ctx context.Context, scenarioID int, externalID string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM scenario_loads WHERE scenario_id = $1 AND external_id = $2`, scenarioID, externalID)
+	if err != nil {
+		return fmt.Errorf("failed to delete shadow loads: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrLoadNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SnapshotRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSnapshotRepository(pool *pgxpool.Pool) *SnapshotRepository {
+	return &SnapshotRepository{pool: pool}
+}
+
+// Get retrieves the heatmap snapshot for an entity
+func (r *SnapshotRepository) Get(ctx context.Context, entityID string) (*models.HeatmapSnapshot, error) {
+	snapshot := &models.HeatmapSnapshot{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT entity_id, window_start, window_end, version_at, version_rows, days, computed_at
+		 FROM heatmap_snapshots WHERE entity_id = $1`, entityID).Scan(
+		&snapshot.EntityID, &snapshot.WindowStart, &snapshot.WindowEnd,
+		&snapshot.Version.LastModified, &snapshot.Version.Rows, &snapshot.Days, &snapshot.ComputedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil // No snapshot yet is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heatmap snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// Save stores a heatmap snapshot, replacing the entity's previous one unless
+// that is newer. Concurrent recomputes can finish out of order, and the
+// slower one may have read older data.
+func (r *SnapshotRepository) Save(ctx context.Context, snapshot *models.HeatmapSnapshot) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO heatmap_snapshots (entity_id, window_start, window_end, version_at, version_rows, days, computed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		 ON CONFLICT (entity_id) DO UPDATE SET
+			window_start = EXCLUDED.window_start,
+			window_end = EXCLUDED.window_end,
+			version_at = EXCLUDED.version_at,
+			version_rows = EXCLUDED.version_rows,
+			days = EXCLUDED.days,
+			computed_at = EXCLUDED.computed_at
+		 WHERE (heatmap_snapshots.window_start, heatmap_snapshots.version_at)
+			<= (EXCLUDED.window_start, EXCLUDED.version_at)`,
+		snapshot.EntityID, snapshot.WindowStart, snapshot.WindowEnd,
+		snapshot.Version.LastModified, snapshot.Version.Rows, snapshot.Days)
+
+	if err != nil {
+		return fmt.Errorf("failed to save heatmap snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the heatmap snapshots of the given entities, so their next
+// read recomputes them
+func (r *SnapshotRepository) Delete(ctx context.Context, entityIDs []string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM heatmap_snapshots WHERE entity_id = ANY($1)`, entityIDs)
+	if err != nil {
+		return fmt.Errorf("failed to delete heatmap snapshots: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+
+type WebhookEndpointRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookEndpointRepository(pool *pgxpool.Pool) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{pool: pool}
+}
+
+const webhookEndpointColumns = `id, url, description, events, enabled, created_at, updated_at`
+
+func scanWebhookEndpoint(row pgx.Row) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	if err := row.Scan(&e.ID, &e.URL, &e.Description, &e.Events, &e.Enabled, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns every webhook endpoint, oldest first
+func (r *WebhookEndpointRepository) List(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+webhookEndpointColumns+` FROM webhook_endpoints ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []models.WebhookEndpoint{}
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// Get returns one webhook endpoint
+func (r *WebhookEndpointRepository) Get(ctx context.Context, id int) (*models.WebhookEndpoint, error) {
+	e, err := scanWebhookEndpoint(r.pool.QueryRow(ctx,
+		`SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookEndpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// Create stores a webhook endpoint
+func (r *WebhookEndpointRepository) Create(ctx context.Context, url, description string, events []string, enabled bool) (*models.WebhookEndpoint, error) {
+	e, err := scanWebhookEndpoint(r.pool.QueryRow(ctx,
+		`INSERT INTO webhook_endpoints (url, description, events, enabled)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+webhookEndpointColumns,
+		url, description, events, enabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// Update stores a webhook endpoint's URL, description, events and whether
+// it is enabled
+func (r *WebhookEndpointRepository) Update(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	e, err := scanWebhookEndpoint(r.pool.QueryRow(ctx,
+		`UPDATE webhook_endpoints
+		 SET url = $2, description = $3, events = $4, enabled = $5, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+webhookEndpointColumns,
+		endpoint.ID, endpoint.URL, endpoint.Description, endpoint.Events, endpoint.Enabled))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookEndpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// Delete removes a webhook endpoint. Its recorded deliveries are kept.
+func (r *WebhookEndpointRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+	return nil
+}
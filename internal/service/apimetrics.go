@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// APIMetricsFlushInterval is how often counted API requests are written to
+// the database
+const APIMetricsFlushInterval = time.Minute
+
+// defaultAPIMetricsDays is how far back the API key metrics report looks when
+// no start date is given
+const defaultAPIMetricsDays = 7
+
+// ErrNotMetricsAdmin is returned when someone not in ADMIN_EMAILS views the
+// API key metrics
+var ErrNotMetricsAdmin = errors.New("only admins may view API metrics")
+
+// apiKeyDay is what API requests are counted under
+type apiKeyDay struct {
+	key  string
+	date string
+}
+
+// APIMetricsService counts requests, errors and payload sizes per API key per
+// UTC day. Requests are counted in memory and added to the database every
+// APIMetricsFlushInterval, so a server that stops without flushing loses at
+// most that much.
+type APIMetricsService struct {
+	metricsRepo *repository.APIMetricsRepository
+
+	// admins may view the metrics, by lowercase email
+	admins map[string]bool
+
+	mu      sync.Mutex
+	pending map[apiKeyDay]*models.APIKeyMetric
+}
+
+func NewAPIMetricsService(metricsRepo *repository.APIMetricsRepository) *APIMetricsService {
+	return &APIMetricsService{
+		metricsRepo: metricsRepo,
+		pending:     make(map[apiKeyDay]*models.APIKeyMetric),
+	}
+}
+
+// SetAdmins sets who may view the metrics
+func (s *APIMetricsService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// IsAdmin reports whether email may view the metrics
+func (s *APIMetricsService) IsAdmin(email string) bool {
+	return email != "" && s.admins[strings.ToLower(email)]
+}
+
+// Record counts a request made with the API key named key, answered with
+// status
+func (s *APIMetricsService) Record(key string, status int, requestBytes, responseBytes int64) {
+	day := apiKeyDay{key: key, date: time.Now().UTC().Format("2006-01-02")}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.pending[day]
+	if !ok {
+		m = &models.APIKeyMetric{Key: day.key, Date: day.date}
+		s.pending[day] = m
+	}
+	m.Requests++
+	switch {
+	case status >= 500:
+		m.ServerErrors++
+	case status >= 400:
+		m.ClientErrors++
+	}
+	m.RequestBytes += max(requestBytes, 0)
+	m.ResponseBytes += max(responseBytes, 0)
+}
+
+// Flush adds the requests counted since the last flush to the database.
+// When that fails they are kept for the next one.
+func (s *APIMetricsService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[apiKeyDay]*models.APIKeyMetric)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	metrics := make([]models.APIKeyMetric, 0, len(pending))
+	for _, m := range pending {
+		metrics = append(metrics, *m)
+	}
+	if err := s.metricsRepo.Add(ctx, metrics); err != nil {
+		s.restore(pending)
+		return err
+	}
+	return nil
+}
+
+// restore puts counts that could not be flushed back with those counted since
+func (s *APIMetricsService) restore(pending map[apiKeyDay]*models.APIKeyMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for day, m := range pending {
+		if current, ok := s.pending[day]; ok {
+			m.Requests += current.Requests
+			m.ClientErrors += current.ClientErrors
+			m.ServerErrors += current.ServerErrors
+			m.RequestBytes += current.RequestBytes
+			m.ResponseBytes += current.ResponseBytes
+		}
+		s.pending[day] = m
+	}
+}
+
+// RunFlush flushes the counted requests every interval until ctx is
+// cancelled
+func (s *APIMetricsService) RunFlush(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Flush(ctx); err != nil {
+			log.Printf("API metrics flush: %v", err)
+		}
+	}
+}
+
+// GetReport returns every API key's daily traffic between from and to, of
+// one key when key is not empty, as an admin. From defaults to 7 days before
+// to, and to defaults to today. This server's unflushed requests are flushed
+// first.
+func (s *APIMetricsService) GetReport(ctx context.Context, actorEmail, fromStr, toStr, key string) (*models.APIKeyMetricsReport, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotMetricsAdmin
+	}
+
+	to, err := parseDateOrToday(toStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	from := to.AddDate(0, 0, -defaultAPIMetricsDays)
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDate)
+		}
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidDate)
+	}
+
+	if err := s.Flush(ctx); err != nil {
+		log.Printf("API metrics flush: %v", err)
+	}
+	metrics, err := s.metricsRepo.List(ctx, from, to, key)
+	if err != nil {
+		return nil, err
+	}
+	for i := range metrics {
+		metrics[i].ErrorRate = errorRate(metrics[i])
+	}
+
+	return &models.APIKeyMetricsReport{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Metrics: metrics,
+	}, nil
+}
+
+// errorRate is the share of a day's requests answered with an error
+func errorRate(m models.APIKeyMetric) float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.ClientErrors+m.ServerErrors) / float64(m.Requests)
+}
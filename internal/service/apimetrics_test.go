@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIMetricsRecord(t *testing.T) {
+	s := NewAPIMetricsService(nil)
+	s.Record("api_key", 200, 120, 40)
+	s.Record("api_key", 422, 80, 20)
+	s.Record("api_key", 503, -1, 10)
+	s.Record("groups:platform", 200, 0, 5)
+
+	assert.Len(t, s.pending, 2)
+	var m *models.APIKeyMetric
+	for day, pending := range s.pending {
+		if day.key == "api_key" {
+			m = pending
+		}
+	}
+	if assert.NotNil(t, m) {
+		assert.Equal(t, int64(3), m.Requests)
+		assert.Equal(t, int64(1), m.ClientErrors)
+		assert.Equal(t, int64(1), m.ServerErrors)
+		assert.Equal(t, int64(200), m.RequestBytes, "unknown lengths count as nothing")
+		assert.Equal(t, int64(70), m.ResponseBytes)
+	}
+}
+
+func TestAPIMetricsRestore(t *testing.T) {
+	s := NewAPIMetricsService(nil)
+	s.Record("api_key", 200, 10, 10)
+	failed := s.pending
+	s.pending = make(map[apiKeyDay]*models.APIKeyMetric)
+
+	s.Record("api_key", 500, 5, 5)
+	s.restore(failed)
+
+	assert.Len(t, s.pending, 1)
+	for _, m := range s.pending {
+		assert.Equal(t, int64(2), m.Requests, "counts that failed to flush are added to newer ones")
+		assert.Equal(t, int64(1), m.ServerErrors)
+		assert.Equal(t, int64(15), m.RequestBytes)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	assert.Equal(t, 0.0, errorRate(models.APIKeyMetric{}))
+	assert.Equal(t, 0.5, errorRate(models.APIKeyMetric{Requests: 4, ClientErrors: 1, ServerErrors: 1}))
+}
@@ -1,18 +1,16 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
-	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gti/heatmap-internal/internal/repository"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -20,29 +18,135 @@ import (
 var (
 	ErrOTPExpired     = errors.New("OTP expired or not found")
 	ErrOTPInvalid     = errors.New("invalid OTP")
+	ErrOTPAttempts    = errors.New("too many wrong codes, request a new OTP")
+	ErrOTPRateLimited = errors.New("too many OTP requests, try again later")
 	ErrSessionInvalid = errors.New("invalid or expired session")
 )
 
+// otpMaxAttempts is how many codes may be tried against one OTP before it is
+// dropped, so guessing a 6-digit code is hopeless
+const otpMaxAttempts = 5
+
+// AuthCleanupInterval is how often expired sessions, OTPs, SSO logins and
+// rate limit windows are deleted
+const AuthCleanupInterval = time.Hour
+
 type AuthService struct {
 	pool          *pgxpool.Pool
-	larkAppID     string
-	larkAppSecret string
+	deliverer     OTPDeliverer
 	otpExpiry     time.Duration
 	sessionExpiry time.Duration
+
+	// rateLimits counts the OTPs sent to each email, at most
+	// otpRequestLimit an hour; nil for no limit
+	rateLimits      *repository.RateLimitRepository
+	otpRequestLimit int
+
+	// oidc signs users in by single sign-on besides OTP; nil when off
+	oidc *OIDCProvider
 }
 
-func NewAuthService(pool *pgxpool.Pool, larkAppID, larkAppSecret string) *AuthService {
+// NewAuthService creates the auth service, sending login codes with
+// deliverer
+func NewAuthService(pool *pgxpool.Pool, deliverer OTPDeliverer) *AuthService {
 	return &AuthService{
 		pool:          pool,
-		larkAppID:     larkAppID,
-		larkAppSecret: larkAppSecret,
-		otpExpiry:     10 * time.Minute,
+		deliverer:     deliverer,
+		otpExpiry:     otpLifetime,
 		sessionExpiry: 24 * time.Hour * 7, // 7 days
 	}
 }
 
-// SendOTP generates and sends a 6-digit OTP to the user's email
+// LimitOTPRequests sends each email at most perHour OTPs an hour, counted in
+// rateLimits; perHour 0 means no limit
+func (s *AuthService) LimitOTPRequests(rateLimits *repository.RateLimitRepository, perHour int) {
+	if perHour > 0 {
+		s.rateLimits = rateLimits
+		s.otpRequestLimit = perHour
+	}
+}
+
+// UseOIDC lets users sign in with an OpenID Connect identity provider as well
+// as by OTP
+func (s *AuthService) UseOIDC(provider *OIDCProvider) {
+	s.oidc = provider
+}
+
+// OIDCName is what the identity provider is shown as on the login page, or
+// empty when single sign-on is off
+func (s *AuthService) OIDCName() string {
+	if s.oidc == nil {
+		return ""
+	}
+	return s.oidc.Name()
+}
+
+// StartOIDCLogin begins a single sign-on login, returning where to send the
+// user and the state the browser must bring back to the callback
+func (s *AuthService) StartOIDCLogin(ctx context.Context) (authURL, state string, err error) {
+	state, err = randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	authURL, err = s.oidc.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = s.pool.Exec(ctx,
+		`INSERT INTO oidc_logins (state, nonce, code_verifier, expires_at) VALUES ($1, $2, $3, $4)`,
+		state, nonce, verifier, time.Now().Add(oidcLoginLifetime))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to store login: %w", err)
+	}
+
+	return authURL, state, nil
+}
+
+// FinishOIDCLogin completes the single sign-on login started with state,
+// redeeming the identity provider's code, and returns the verified email.
+// Each state is used once.
+func (s *AuthService) FinishOIDCLogin(ctx context.Context, state, code string) (string, error) {
+	var nonce, verifier string
+	var expiresAt time.Time
+	err := s.pool.QueryRow(ctx,
+		`DELETE FROM oidc_logins WHERE state = $1 RETURNING nonce, code_verifier, expires_at`, state).
+		Scan(&nonce, &verifier, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrOIDCState
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get login: %w", err)
+	}
+	if time.Now().After(expiresAt) {
+		return "", ErrOIDCState
+	}
+
+	return s.oidc.Exchange(ctx, code, verifier, nonce)
+}
+
+// SendOTP generates a 6-digit OTP and sends it to the user with the
+// deliverer
 func (s *AuthService) SendOTP(ctx context.Context, email string) error {
+	if s.rateLimits != nil {
+		hits, _, err := s.rateLimits.Hit(ctx, "request-otp:email:"+email, time.Hour)
+		if err != nil {
+			return err
+		}
+		if hits > s.otpRequestLimit {
+			return ErrOTPRateLimited
+		}
+	}
+
 	// Generate 6-digit OTP
 	otp, err := generateOTP(6)
 	if err != nil {
@@ -55,37 +159,32 @@ func (s *AuthService) SendOTP(ctx context.Context, email string) error {
 	_, err = s.pool.Exec(ctx,
 		`INSERT INTO otp_records (email, otp, expires_at)
 		 VALUES ($1, $2, $3)
-		 ON CONFLICT (email) DO UPDATE SET otp = $2, expires_at = $3`,
+		 ON CONFLICT (email) DO UPDATE SET otp = $2, expires_at = $3, attempts = 0`,
 		email, otp, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to store OTP: %w", err)
 	}
 
-	// Send OTP via Lark API
-	if s.larkAppID != "" && s.larkAppSecret != "" {
-		if err := s.sendOTPViaLark(ctx, email, otp); err != nil {
-			log.Printf("Failed to send OTP via Lark: %v", err)
-			// Continue anyway - log the OTP for development
-		}
+	if err := s.deliverer.DeliverOTP(ctx, email, otp); err != nil {
+		return fmt.Errorf("failed to deliver OTP: %w", err)
 	}
 
-	// Log OTP for development (remove in production)
-	log.Println("==========================================")
-	log.Printf("🔐 OTP for %s: %s", email, otp)
-	log.Printf("   Expires: %s", expiresAt.Format(time.RFC3339))
-	log.Println("==========================================")
-
 	return nil
 }
 
-// VerifyOTP verifies the OTP and returns true if valid
+// VerifyOTP verifies the OTP and returns true if valid. Each try counts
+// against the OTP, which is dropped after otpMaxAttempts wrong codes.
 func (s *AuthService) VerifyOTP(ctx context.Context, email, otp string) (bool, error) {
 	var storedOTP string
 	var expiresAt time.Time
+	var attempts int
 
+	// Count the try before comparing, so concurrent guesses cannot get more
+	// than otpMaxAttempts codes compared
 	err := s.pool.QueryRow(ctx,
-		`SELECT otp, expires_at FROM otp_records WHERE email = $1`, email).
-		Scan(&storedOTP, &expiresAt)
+		`UPDATE otp_records SET attempts = attempts + 1 WHERE email = $1
+		 RETURNING otp, expires_at, attempts`, email).
+		Scan(&storedOTP, &expiresAt, &attempts)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, ErrOTPExpired
@@ -102,7 +201,14 @@ func (s *AuthService) VerifyOTP(ctx context.Context, email, otp string) (bool, e
 	}
 
 	// Check OTP
+	if attempts > otpMaxAttempts {
+		return false, ErrOTPAttempts
+	}
 	if storedOTP != otp {
+		if attempts == otpMaxAttempts {
+			_, _ = s.pool.Exec(ctx, `DELETE FROM otp_records WHERE email = $1`, email)
+			return false, ErrOTPAttempts
+		}
 		return false, ErrOTPInvalid
 	}
 
@@ -161,7 +267,8 @@ func (s *AuthService) DeleteSession(ctx context.Context, token string) error {
 	return nil
 }
 
-// CleanExpiredSessions removes expired sessions from the database
+// CleanExpiredSessions removes expired sessions, OTPs, SSO logins and
+// ended rate limit windows from the database
 func (s *AuthService) CleanExpiredSessions(ctx context.Context) error {
 	_, err := s.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`)
 	if err != nil {
@@ -171,108 +278,34 @@ func (s *AuthService) CleanExpiredSessions(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to clean OTP records: %w", err)
 	}
-	return nil
-}
-
-// getLarkTenantAccessToken fetches a fresh tenant access token from Lark API
-func (s *AuthService) getLarkTenantAccessToken(ctx context.Context) (string, error) {
-	requestBody := map[string]string{
-		"app_id":     s.larkAppID,
-		"app_secret": s.larkAppSecret,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	_, err = s.pool.Exec(ctx, `DELETE FROM oidc_logins WHERE expires_at < NOW()`)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal token request: %w", err)
+		return fmt.Errorf("failed to clean OIDC logins: %w", err)
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		"https://open.larksuite.com/open-apis/auth/v3/tenant_access_token/internal",
-		bytes.NewBuffer(jsonBody))
+	// Every rate limit window is an hour long
+	_, err = s.pool.Exec(ctx, `DELETE FROM rate_limits WHERE window_start < NOW() - INTERVAL '1 hour'`)
 	if err != nil {
-		return "", fmt.Errorf("failed to create token request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send token request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	var tokenResp struct {
-		Code              int    `json:"code"`
-		Msg               string `json:"msg"`
-		TenantAccessToken string `json:"tenant_access_token"`
-		Expire            int    `json:"expire"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
-	}
-
-	if tokenResp.Code != 0 {
-		return "", fmt.Errorf("lark token API error: code=%d, msg=%s", tokenResp.Code, tokenResp.Msg)
+		return fmt.Errorf("failed to clean rate limits: %w", err)
 	}
-
-	return tokenResp.TenantAccessToken, nil
+	return nil
 }
 
-// sendOTPViaLark sends the OTP via Lark API
-func (s *AuthService) sendOTPViaLark(ctx context.Context, email, otp string) error {
-	// Get fresh tenant access token
-	token, err := s.getLarkTenantAccessToken(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get lark access token: %w", err)
-	}
+// RunCleanup cleans expired sessions every interval until ctx is cancelled
+func (s *AuthService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	// Prepare the request body
-	requestBody := map[string]interface{}{
-		"receive_id": email,
-		"msg_type":   "text",
-		"content":    fmt.Sprintf("{\"text\":\" THE OTP CODE: %s\"}", otp),
-		"uuid":       uuid.New().String(),
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		"https://open.larksuite.com/open-apis/im/v1/messages?receive_id_type=email",
-		bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-
-	// Send request with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		var result map[string]interface{}
-		_ = json.NewDecoder(resp.Body).Decode(&result)
-		return fmt.Errorf("lark API returned status %d: %v", resp.StatusCode, result)
+		if err := s.CleanExpiredSessions(ctx); err != nil {
+			log.Printf("Auth cleanup: %v", err)
+		}
 	}
-
-	return nil
 }
 
 // generateOTP generates a random numeric OTP of the specified length
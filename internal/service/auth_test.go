@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendOTPViaLarkReplay(t *testing.T) {
+	// Live credentials are only needed when re-recording with HTTP_RECORD=1
+	appID := os.Getenv("LARK_APP_ID")
+	appSecret := os.Getenv("LARK_APP_SECRET")
+	if appID == "" {
+		appID = "cli_test"
+		appSecret = "test-secret"
+	}
+
+	rec, err := helpers.NewHTTPRecorder("testdata/cassettes/lark_send_otp.json", helpers.RecorderModeFromEnv())
+	require.NoError(t, err)
+	rec.Redact(appID, appSecret)
+	defer func() { require.NoError(t, rec.Save()) }()
+
+	lark := NewLarkClient(appID, appSecret)
+	lark.SetHTTPClient(rec.Client())
+
+	err = NewLarkOTPDeliverer(lark).DeliverOTP(context.Background(), "alice@example.com", "123456")
+	require.NoError(t, err)
+	require.Len(t, rec.Interactions(), 2, "should fetch a token then send the message")
+}
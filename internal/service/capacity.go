@@ -2,25 +2,118 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/gti/heatmap-internal/internal/cache"
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
 )
 
+// ErrNotApprover is returned when someone other than an owner of one of a
+// person's groups decides on their capacity change
+var ErrNotApprover = errors.New("not an approver for this person")
+
+var (
+	// ErrNotDelegate is returned when someone manages the capacity of a
+	// person who has not delegated it to them
+	ErrNotDelegate = errors.New("not an assistant for this person")
+	// ErrInvalidDelegate is returned when delegating to oneself or to
+	// something other than a person
+	ErrInvalidDelegate = errors.New("assistant must be another person")
+	// ErrInvalidWeekday is returned for a weekly pattern day that is not
+	// monday through sunday
+	ErrInvalidWeekday = errors.New("invalid weekday")
+	// ErrNotCapacityAdmin is returned when someone not in ADMIN_EMAILS
+	// removes another entity's capacity overrides
+	ErrNotCapacityAdmin = errors.New("only admins may remove another entity's capacity overrides")
+)
+
+// auditLogLimit is how many capacity audit entries are listed
+const auditLogLimit = 50
+
+// dryRunCapacityDays is how many days from today a capacity dry run reports
+const dryRunCapacityDays = 14
+
 type CapacityService struct {
 	entityRepo   *repository.EntityRepository
 	capacityRepo *repository.CapacityRepository
+	groupRepo    *repository.GroupRepository
+	delegateRepo *repository.DelegationRepository
+	loadRepo     *repository.LoadRepository
+	renderCache  *cache.RenderCache
+	events       *EventLog
+	webhooks     *WebhookService
+
+	// approvalZeroDays is how many consecutive zero-capacity days need
+	// approval; 0 applies every change directly
+	approvalZeroDays int
+
+	// admins may remove any entity's capacity overrides, by lowercase email
+	admins map[string]bool
 }
 
 func NewCapacityService(
 	entityRepo *repository.EntityRepository,
 	capacityRepo *repository.CapacityRepository,
+	groupRepo *repository.GroupRepository,
+	delegateRepo *repository.DelegationRepository,
+	renderCache *cache.RenderCache,
 ) *CapacityService {
 	return &CapacityService{
 		entityRepo:   entityRepo,
 		capacityRepo: capacityRepo,
+		groupRepo:    groupRepo,
+		delegateRepo: delegateRepo,
+		renderCache:  renderCache,
+	}
+}
+
+// RequireApproval holds capacity reductions, and runs of at least zeroDays
+// consecutive zero-capacity overrides, for approval by an owner of one of the
+// person's groups. People in no owned group are not held.
+func (s *CapacityService) RequireApproval(zeroDays int) {
+	s.approvalZeroDays = zeroDays
+}
+
+// SetAdmins sets who may remove any entity's capacity overrides
+func (s *CapacityService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// IsAdmin reports whether email may remove any entity's capacity overrides
+func (s *CapacityService) IsAdmin(email string) bool {
+	return email != "" && s.admins[strings.ToLower(email)]
+}
+
+// RecordEvents records every capacity and delegation change in the domain
+// event log
+func (s *CapacityService) RecordEvents(events *EventLog) {
+	s.events = events
+}
+
+// ReportLoads has capacity dry runs report each day's load along with its
+// capacity
+func (s *CapacityService) ReportLoads(loadRepo *repository.LoadRepository) {
+	s.loadRepo = loadRepo
+}
+
+// NotifyWebhooks sends a capacity_changed webhook for every capacity change
+// once it applies
+func (s *CapacityService) NotifyWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// notifyChanged sends a capacity_changed webhook, if enabled
+func (s *CapacityService) notifyChanged(ctx context.Context, entityID, actorEmail string, change *models.UpdateCapacityRequest, detail string) {
+	if s.webhooks != nil {
+		s.webhooks.NotifyCapacityChanged(ctx, entityID, actorEmail, change, detail)
 	}
 }
 
@@ -30,7 +123,12 @@ func (s *CapacityService) UpdateDefaultCapacity(ctx context.Context, entityID st
 		return fmt.Errorf("capacity cannot be negative")
 	}
 
-	return s.entityRepo.UpdateDefaultCapacity(ctx, entityID, capacity)
+	if err := s.entityRepo.UpdateDefaultCapacity(ctx, entityID, capacity); err != nil {
+		return err
+	}
+
+	s.renderCache.Invalidate(ctx, entityID)
+	return nil
 }
 
 // SetDateOverride sets a capacity override for a specific date
@@ -51,17 +149,100 @@ func (s *CapacityService) SetDateOverride(ctx context.Context, entityID string,
 		Capacity: capacity,
 	}
 
-	return s.capacityRepo.SetOverride(ctx, override)
+	if err := s.capacityRepo.SetOverride(ctx, override); err != nil {
+		return err
+	}
+
+	s.renderCache.Invalidate(ctx, entityID)
+	return nil
 }
 
-// DeleteDateOverride removes a capacity override for a specific date
-func (s *CapacityService) DeleteDateOverride(ctx context.Context, entityID string, dateStr string) error {
+// DeleteDateOverride removes a capacity override for a specific date on
+// behalf of actorEmail
+func (s *CapacityService) DeleteDateOverride(ctx context.Context, actorEmail, entityID string, dateStr string) error {
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		return fmt.Errorf("invalid date format: %w", err)
 	}
 
-	return s.capacityRepo.DeleteOverride(ctx, entityID, date)
+	if dry := DryRun(ctx); dry != nil {
+		return s.dryRunDeleteOverride(ctx, dry, entityID, date)
+	}
+
+	if err := s.capacityRepo.DeleteOverride(ctx, entityID, date); err != nil {
+		return err
+	}
+
+	s.renderCache.Invalidate(ctx, entityID)
+	detail := "override on " + date.Format("2006-01-02") + " removed"
+	s.events.Record(ctx, models.EventCapacityChanged, actorEmail, []string{entityID},
+		map[string]interface{}{"deleted_override": date.Format("2006-01-02")})
+	s.notifyChanged(ctx, entityID, actorEmail, nil, detail)
+	return s.audit(ctx, entityID, actorEmail, models.CapacityAuditDeleteOverride, detail)
+}
+
+// DeleteOverrides removes every capacity override of entityID from fromStr
+// to toStr inclusive on behalf of actorEmail, to undo mistaken vacation
+// entries in one call, and returns how many were removed
+func (s *CapacityService) DeleteOverrides(ctx context.Context, actorEmail, entityID, fromStr, toStr string) (*models.DeleteOverridesResponse, error) {
+	from, to, err := parseOverrideRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.capacityRepo.DeleteOverridesRange(ctx, entityID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DeleteOverridesResponse{
+		EntityID: entityID,
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Deleted:  deleted,
+	}
+	if deleted == 0 {
+		return result, nil
+	}
+
+	s.renderCache.Invalidate(ctx, entityID)
+	detail := fmt.Sprintf("%d overrides from %s to %s removed", deleted, result.From, result.To)
+	s.events.Record(ctx, models.EventCapacityChanged, actorEmail, []string{entityID},
+		map[string]interface{}{"deleted_overrides": map[string]interface{}{"from": result.From, "to": result.To, "count": deleted}})
+	s.notifyChanged(ctx, entityID, actorEmail, nil, detail)
+	if err := s.audit(ctx, entityID, actorEmail, models.CapacityAuditDeleteOverride, detail); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteEntityOverrides removes every capacity override of any person or
+// group from fromStr to toStr inclusive, as an admin
+func (s *CapacityService) DeleteEntityOverrides(ctx context.Context, actorEmail, entityID, fromStr, toStr string) (*models.DeleteOverridesResponse, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotCapacityAdmin
+	}
+	if _, err := s.entityRepo.GetByID(ctx, entityID); err != nil {
+		return nil, err
+	}
+	return s.DeleteOverrides(ctx, actorEmail, entityID, fromStr, toStr)
+}
+
+// parseOverrideRange parses the dates of an override range deletion. Both are
+// required, so a forgotten one never removes more than meant.
+func parseOverrideRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must not be after to", ErrInvalidDate)
+	}
+	return from, to, nil
 }
 
 // GetCapacityInfo returns capacity information for an entity
@@ -85,8 +266,225 @@ func (s *CapacityService) GetCapacityInfo(ctx context.Context, entityID string)
 	return entity, overrides, nil
 }
 
-// UpdateCapacity handles the full capacity update request
-func (s *CapacityService) UpdateCapacity(ctx context.Context, entityID string, req *models.UpdateCapacityRequest) error {
+// GetWeeklyPattern returns an entity's capacity for each weekday it set one
+// for, from Monday to Sunday
+func (s *CapacityService) GetWeeklyPattern(ctx context.Context, entityID string) ([]models.WeekdayCapacity, error) {
+	pattern, err := s.capacityRepo.GetWeeklyPattern(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []models.WeekdayCapacity{}
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		if capacity, ok := pattern[weekday]; ok {
+			days = append(days, models.WeekdayCapacity{
+				Weekday:  strings.ToLower(weekday.String()),
+				Capacity: &capacity,
+			})
+		}
+	}
+
+	return days, nil
+}
+
+// UpdateCapacity handles the full capacity update request made by actorEmail
+// for entityID. When the change needs approval it is stored instead and
+// returned as a pending request.
+func (s *CapacityService) UpdateCapacity(ctx context.Context, actorEmail, entityID string, req *models.UpdateCapacityRequest) (*models.CapacityChangeRequest, error) {
+	for _, day := range req.WeeklyPattern {
+		if _, err := parseWeekday(day.Weekday); err != nil {
+			return nil, err
+		}
+	}
+
+	if dry := DryRun(ctx); dry != nil {
+		return nil, s.dryRunCapacity(ctx, dry, entityID, req)
+	}
+
+	if s.approvalZeroDays > 0 {
+		pending, err := s.holdForApproval(ctx, entityID, req)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil {
+			s.events.Record(ctx, models.EventCapacityRequested, actorEmail, []string{entityID}, pending)
+			detail := describeCapacityChange(req) + " (" + pending.Reason + ")"
+			return pending, s.audit(ctx, entityID, actorEmail, models.CapacityAuditRequest, detail)
+		}
+	}
+
+	if err := s.applyCapacity(ctx, entityID, req); err != nil {
+		return nil, err
+	}
+	s.events.Record(ctx, models.EventCapacityChanged, actorEmail, []string{entityID}, req)
+	s.notifyChanged(ctx, entityID, actorEmail, req, describeCapacityChange(req))
+	return nil, s.audit(ctx, entityID, actorEmail, models.CapacityAuditUpdate, describeCapacityChange(req))
+}
+
+// holdForApproval stores the change as pending when it needs approval and
+// someone can approve it, and returns nil otherwise
+func (s *CapacityService) holdForApproval(ctx context.Context, entityID string, req *models.UpdateCapacityRequest) (*models.CapacityChangeRequest, error) {
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	reason, err := s.approvalReason(ctx, entity, req)
+	if err != nil || reason == "" {
+		return nil, err
+	}
+
+	pending := &models.CapacityChangeRequest{
+		EntityID: entityID,
+		Change:   *req,
+		Reason:   reason,
+	}
+	if err := s.capacityRepo.CreateChangeRequest(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// approvalReason returns why a change needs approval when someone can
+// approve it, and "" otherwise
+func (s *CapacityService) approvalReason(ctx context.Context, entity *models.Entity, req *models.UpdateCapacityRequest) (string, error) {
+	reason := capacityApprovalReason(entity.DefaultCapacity, req, s.approvalZeroDays)
+	if reason == "" {
+		return "", nil
+	}
+
+	approvers, err := s.groupRepo.GetApprovers(ctx, entity.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(approvers) == 0 {
+		return "", nil
+	}
+	return reason, nil
+}
+
+// ListPendingChanges returns a person's own capacity changes awaiting
+// approval and the ones waiting on them as an approver
+func (s *CapacityService) ListPendingChanges(ctx context.Context, email string) (own, toApprove []models.CapacityChangeRequest, err error) {
+	own, err = s.capacityRepo.ListPendingChangeRequests(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	toApprove, err = s.capacityRepo.ListPendingApprovals(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	return own, toApprove, nil
+}
+
+// ListApprovals returns the capacity changes waiting on an approver
+func (s *CapacityService) ListApprovals(ctx context.Context, approverEmail string) ([]models.CapacityChangeRequest, error) {
+	return s.capacityRepo.ListPendingApprovals(ctx, approverEmail)
+}
+
+// DecideChange approves or rejects a pending capacity change. An approved
+// change is applied; if that fails, the request goes back to pending.
+func (s *CapacityService) DecideChange(ctx context.Context, approverEmail string, id int, approve bool) (*models.CapacityChangeRequest, error) {
+	req, err := s.capacityRepo.GetChangeRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.groupRepo.IsApprover(ctx, approverEmail, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotApprover
+	}
+
+	status := models.CapacityChangeRejected
+	if approve {
+		status = models.CapacityChangeApproved
+	}
+	if err := s.capacityRepo.DecideChangeRequest(ctx, id, status, approverEmail); err != nil {
+		return nil, err
+	}
+
+	if approve {
+		if err := s.applyCapacity(ctx, req.EntityID, &req.Change); err != nil {
+			if reopenErr := s.capacityRepo.ReopenChangeRequest(ctx, id); reopenErr != nil {
+				return nil, errors.Join(err, reopenErr)
+			}
+			return nil, err
+		}
+	}
+
+	action := models.CapacityAuditReject
+	if approve {
+		action = models.CapacityAuditApprove
+	}
+	if err := s.audit(ctx, req.EntityID, approverEmail, action, describeCapacityChange(&req.Change)); err != nil {
+		return nil, err
+	}
+
+	decidedAt := time.Now()
+	req.Status = status
+	req.DecidedBy = &approverEmail
+	req.DecidedAt = &decidedAt
+	s.events.Record(ctx, models.EventCapacityDecided, approverEmail, []string{req.EntityID}, req)
+	if approve {
+		s.notifyChanged(ctx, req.EntityID, approverEmail, &req.Change, describeCapacityChange(&req.Change))
+	}
+	return req, nil
+}
+
+// capacityApprovalReason describes why a change needs approval: it lowers
+// the default capacity, sets a weekday below it, or sets zeroDays or more
+// consecutive days to zero. It returns "" for changes that can be applied
+// directly.
+func capacityApprovalReason(currentDefault float64, req *models.UpdateCapacityRequest, zeroDays int) string {
+	if req.DefaultCapacity != nil && *req.DefaultCapacity < currentDefault {
+		return fmt.Sprintf("default capacity reduced from %.1f to %.1f", currentDefault, *req.DefaultCapacity)
+	}
+
+	for _, day := range req.WeeklyPattern {
+		if day.Capacity != nil && *day.Capacity < currentDefault {
+			return fmt.Sprintf("%s capacity reduced from %.1f to %.1f", strings.ToLower(day.Weekday), currentDefault, *day.Capacity)
+		}
+	}
+
+	var zeroDates []time.Time
+	for _, o := range req.DateOverrides {
+		if o.Capacity != 0 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", o.Date)
+		if err != nil {
+			continue // Rejected when the change is applied
+		}
+		zeroDates = append(zeroDates, date)
+	}
+	sort.Slice(zeroDates, func(i, j int) bool { return zeroDates[i].Before(zeroDates[j]) })
+
+	longest, run := 0, 0
+	for i, date := range zeroDates {
+		switch {
+		case i > 0 && date.Equal(zeroDates[i-1]):
+			continue
+		case i > 0 && date.Equal(zeroDates[i-1].AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	if longest >= zeroDays {
+		return fmt.Sprintf("%d consecutive days at zero capacity", longest)
+	}
+
+	return ""
+}
+
+// applyCapacity writes a capacity change
+func (s *CapacityService) applyCapacity(ctx context.Context, entityID string, req *models.UpdateCapacityRequest) error {
 	// Update default capacity if provided
 	if req.DefaultCapacity != nil {
 		if err := s.UpdateDefaultCapacity(ctx, entityID, *req.DefaultCapacity); err != nil {
@@ -106,5 +504,144 @@ func (s *CapacityService) UpdateCapacity(ctx context.Context, entityID string, r
 		}
 	}
 
+	// Process the weekly pattern
+	for _, day := range req.WeeklyPattern {
+		weekday, err := parseWeekday(day.Weekday)
+		if err != nil {
+			return err
+		}
+
+		if day.Capacity == nil {
+			err = s.capacityRepo.DeleteWeekdayCapacity(ctx, entityID, weekday)
+		} else if *day.Capacity < 0 {
+			err = fmt.Errorf("capacity cannot be negative")
+		} else {
+			err = s.capacityRepo.SetWeekdayCapacity(ctx, entityID, weekday, *day.Capacity)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set %s capacity: %w", day.Weekday, err)
+		}
+	}
+	if len(req.WeeklyPattern) > 0 {
+		s.renderCache.Invalidate(ctx, entityID)
+	}
+
+	return nil
+}
+
+// parseWeekday parses a weekday name such as "friday"
+func parseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidWeekday, name)
+}
+
+// ResolvePerson returns whose capacity actorEmail is managing: personEmail
+// when they are that person's assistant, or actorEmail itself when
+// personEmail is empty
+func (s *CapacityService) ResolvePerson(ctx context.Context, actorEmail, personEmail string) (string, error) {
+	if personEmail == "" || personEmail == actorEmail {
+		return actorEmail, nil
+	}
+
+	ok, err := s.delegateRepo.IsDelegate(ctx, personEmail, actorEmail)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrNotDelegate
+	}
+	return personEmail, nil
+}
+
+// ListDelegations returns a person's assistants and the people they assist
+func (s *CapacityService) ListDelegations(ctx context.Context, email string) (*models.DelegationsResponse, error) {
+	assistants, err := s.delegateRepo.ListAssistants(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	delegators, err := s.delegateRepo.ListDelegators(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DelegationsResponse{Assistants: assistants, Delegators: delegators}, nil
+}
+
+// AddDelegate lets another person manage personEmail's capacity
+func (s *CapacityService) AddDelegate(ctx context.Context, personEmail, assistantEmail string) (*models.Delegation, error) {
+	if assistantEmail == personEmail {
+		return nil, ErrInvalidDelegate
+	}
+
+	assistant, err := s.entityRepo.GetByID(ctx, assistantEmail)
+	if err != nil {
+		return nil, err
+	}
+	if assistant.Type != models.EntityTypePerson || assistant.ArchivedAt != nil {
+		return nil, ErrInvalidDelegate
+	}
+
+	delegation, err := s.delegateRepo.Add(ctx, personEmail, assistantEmail)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Record(ctx, models.EventDelegationAdded, personEmail, []string{personEmail}, delegation)
+	return delegation, nil
+}
+
+// RemoveDelegate stops an assistant managing personEmail's capacity
+func (s *CapacityService) RemoveDelegate(ctx context.Context, personEmail, assistantEmail string) error {
+	if err := s.delegateRepo.Remove(ctx, personEmail, assistantEmail); err != nil {
+		return err
+	}
+	s.events.Record(ctx, models.EventDelegationRemoved, personEmail, []string{personEmail},
+		map[string]interface{}{"assistant_email": assistantEmail})
 	return nil
 }
+
+// ListAuditLog returns the most recent changes to a person's capacity
+func (s *CapacityService) ListAuditLog(ctx context.Context, personEmail string) ([]models.CapacityAuditEntry, error) {
+	return s.capacityRepo.ListAuditEntries(ctx, personEmail, auditLogLimit)
+}
+
+// audit records a change to personEmail's capacity made by actorEmail
+func (s *CapacityService) audit(ctx context.Context, personEmail, actorEmail string, action models.CapacityAuditAction, detail string) error {
+	return s.capacityRepo.AddAuditEntry(ctx, &models.CapacityAuditEntry{
+		PersonEmail: personEmail,
+		ActorEmail:  actorEmail,
+		Action:      action,
+		Detail:      detail,
+	})
+}
+
+// describeCapacityChange summarizes a capacity change for the audit log, with
+// overrides in date order followed by the weekly pattern
+func describeCapacityChange(req *models.UpdateCapacityRequest) string {
+	var parts []string
+	if req.DefaultCapacity != nil {
+		parts = append(parts, fmt.Sprintf("default capacity %.1f", *req.DefaultCapacity))
+	}
+
+	overrides := make([]string, 0, len(req.DateOverrides))
+	for _, o := range req.DateOverrides {
+		overrides = append(overrides, fmt.Sprintf("override %s = %.1f", o.Date, o.Capacity))
+	}
+	sort.Strings(overrides)
+	parts = append(parts, overrides...)
+
+	for _, day := range req.WeeklyPattern {
+		if day.Capacity == nil {
+			parts = append(parts, fmt.Sprintf("%s capacity default", strings.ToLower(day.Weekday)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s capacity %.1f", strings.ToLower(day.Weekday), *day.Capacity))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacityApprovalReason(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"raised default", `{"default_capacity": 6}`, ""},
+		{"unchanged default", `{"default_capacity": 5}`, ""},
+		{"reduced default", `{"default_capacity": 3}`, "default capacity reduced from 5.0 to 3.0"},
+		{"weekday above default", `{"weekly_pattern": [{"weekday": "monday", "capacity": 6}]}`, ""},
+		{"weekday below default", `{"weekly_pattern": [{"weekday": "Friday", "capacity": 3}]}`, "friday capacity reduced from 5.0 to 3.0"},
+		{"weekday cleared", `{"weekly_pattern": [{"weekday": "friday", "capacity": null}]}`, ""},
+		{"short zero run", `{"date_overrides": [
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-11", "capacity": 0}]}`, ""},
+		{"zero run out of order", `{"date_overrides": [
+			{"date": "2025-03-12", "capacity": 0},
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-11", "capacity": 0}]}`, "3 consecutive days at zero capacity"},
+		{"gap breaks the run", `{"date_overrides": [
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-11", "capacity": 0},
+			{"date": "2025-03-13", "capacity": 0}]}`, ""},
+		{"partial capacity breaks the run", `{"date_overrides": [
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-11", "capacity": 2},
+			{"date": "2025-03-12", "capacity": 0}]}`, ""},
+		{"duplicate dates count once", `{"date_overrides": [
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-10", "capacity": 0},
+			{"date": "2025-03-11", "capacity": 0}]}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req models.UpdateCapacityRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			assert.Equal(t, tt.want, capacityApprovalReason(5, &req, 3))
+		})
+	}
+}
+
+func TestDescribeCapacityChange(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", `{}`, "no changes"},
+		{"default only", `{"default_capacity": 3}`, "default capacity 3.0"},
+		{"default and overrides in date order", `{"default_capacity": 4.5, "date_overrides": [
+			{"date": "2025-03-11", "capacity": 2},
+			{"date": "2025-03-10", "capacity": 0}]}`,
+			"default capacity 4.5; override 2025-03-10 = 0.0; override 2025-03-11 = 2.0"},
+		{"weekly pattern", `{"weekly_pattern": [
+			{"weekday": "Monday", "capacity": 5},
+			{"weekday": "friday", "capacity": null}]}`,
+			"monday capacity 5.0; friday capacity default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req models.UpdateCapacityRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			assert.Equal(t, tt.want, describeCapacityChange(&req))
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	got, err := parseWeekday("Friday")
+	require.NoError(t, err)
+	assert.Equal(t, time.Friday, got)
+
+	got, err = parseWeekday("sunday")
+	require.NoError(t, err)
+	assert.Equal(t, time.Sunday, got)
+
+	_, err = parseWeekday("fri")
+	assert.ErrorIs(t, err, ErrInvalidWeekday)
+}
+
+func TestParseOverrideRange(t *testing.T) {
+	from, to, err := parseOverrideRange("2025-03-10", "2025-03-14")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = parseOverrideRange("2025-03-10", "2025-03-10")
+	assert.NoError(t, err, "a single day is a range")
+
+	for name, r := range map[string][2]string{
+		"missing from":  {"", "2025-03-14"},
+		"missing to":    {"2025-03-10", ""},
+		"bad date":      {"10/03/2025", "2025-03-14"},
+		"from after to": {"2025-03-14", "2025-03-10"},
+	} {
+		_, _, err := parseOverrideRange(r[0], r[1])
+		assert.ErrorIs(t, err, ErrInvalidDate, name)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// Custom fields a connector sends to give a load a time window on each of
+// its days, such as a meeting's: RFC 3339 timestamps, or HH:MM times taken
+// as UTC
+const (
+	StartTimeField = "start_time"
+	EndTimeField   = "end_time"
+)
+
+const minutesPerDay = 24 * 60
+
+// timeWindow is a load's time window in minutes after midnight UTC. end is
+// past midnight, over minutesPerDay, for windows running into the next day.
+type timeWindow struct {
+	start, end int
+}
+
+// loadWindow returns a load's time window from its start_time and end_time
+// custom fields, and false when it has none or they cannot be read
+func loadWindow(load *models.Load) (timeWindow, bool) {
+	start, ok := minuteOfDay(load.CustomFields[StartTimeField])
+	if !ok {
+		return timeWindow{}, false
+	}
+	end, ok := minuteOfDay(load.CustomFields[EndTimeField])
+	if !ok || end == start {
+		return timeWindow{}, false
+	}
+	if end < start {
+		end += minutesPerDay
+	}
+	return timeWindow{start: start, end: end}, true
+}
+
+// minuteOfDay reads a custom field value as a time of day, in minutes after
+// midnight UTC
+func minuteOfDay(value interface{}) (int, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if t, err = time.Parse("15:04", s); err != nil {
+			return 0, false
+		}
+	}
+	t = t.UTC()
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// overlaps reports whether two windows on the same day overlap, counting
+// the part of either that runs past midnight
+func (w timeWindow) overlaps(o timeWindow) bool {
+	overlap := func(a, b timeWindow) bool { return a.start < b.end && b.start < a.end }
+	next := func(w timeWindow) timeWindow { return timeWindow{w.start + minutesPerDay, w.end + minutesPerDay} }
+	return overlap(w, o) || overlap(next(w), o) || overlap(w, next(o))
+}
+
+// clockTime formats minutes after midnight as HH:MM, wrapping past midnight
+func clockTime(minute int) string {
+	minute %= minutesPerDay
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// MarkConflicts sets Conflicts on each of a day's loads to the other loads
+// whose time windows overlap its own for an assignee they share. Loads
+// without a time window have no conflicts.
+func MarkConflicts(loads []models.LoadWithAssignments) {
+	windows := make([]timeWindow, len(loads))
+	timed := make([]bool, len(loads))
+	for i := range loads {
+		windows[i], timed[i] = loadWindow(&loads[i].Load)
+	}
+
+	for i := range loads {
+		loads[i].Conflicts = nil
+		if !timed[i] {
+			continue
+		}
+		for j := range loads {
+			if j == i || !timed[j] || loads[j].Load.ID == loads[i].Load.ID {
+				continue
+			}
+			if windows[i].overlaps(windows[j]) && shareAssignee(loads[i].Assignments, loads[j].Assignments) {
+				loads[i].Conflicts = append(loads[i].Conflicts, loads[j].Load.ID)
+			}
+		}
+	}
+}
+
+// shareAssignee reports whether two loads have an assignee in common
+func shareAssignee(a, b []models.LoadAssignment) bool {
+	for _, x := range a {
+		if slices.ContainsFunc(b, func(y models.LoadAssignment) bool { return y.PersonEmail == x.PersonEmail }) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleConflicts returns load, with its time window, and the loads of a
+// person's day whose windows overlap it, by start time, or false when load
+// has no window or nothing overlaps it
+func scheduleConflicts(load *models.Load, day []models.LoadWithAssignments) (models.ScheduleConflict, []models.ScheduleConflict, bool) {
+	window, ok := loadWindow(load)
+	if !ok {
+		return models.ScheduleConflict{}, nil, false
+	}
+
+	type windowed struct {
+		conflict models.ScheduleConflict
+		start    int
+	}
+	var overlapping []windowed
+	for _, l := range day {
+		other, ok := loadWindow(&l.Load)
+		if !ok || l.Load.ID == load.ID || !window.overlaps(other) {
+			continue
+		}
+		overlapping = append(overlapping, windowed{scheduleConflict(&l.Load, other), other.start})
+	}
+	if len(overlapping) == 0 {
+		return models.ScheduleConflict{}, nil, false
+	}
+	sort.SliceStable(overlapping, func(i, j int) bool { return overlapping[i].start < overlapping[j].start })
+
+	conflicts := make([]models.ScheduleConflict, len(overlapping))
+	for i, o := range overlapping {
+		conflicts[i] = o.conflict
+	}
+	return scheduleConflict(load, window), conflicts, true
+}
+
+// scheduleConflict describes a load and its time window for a payload
+func scheduleConflict(load *models.Load, window timeWindow) models.ScheduleConflict {
+	conflict := models.ScheduleConflict{
+		LoadID: load.ID,
+		Title:  load.Title,
+		Start:  clockTime(window.start),
+		End:    clockTime(window.end),
+	}
+	if load.ExternalID != nil {
+		conflict.ExternalID = *load.ExternalID
+	}
+	return conflict
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func timedLoad(id int, start, end interface{}, emails ...string) models.LoadWithAssignments {
+	l := models.LoadWithAssignments{Load: models.Load{ID: id, Title: "Load", CustomFields: map[string]interface{}{}}}
+	if start != nil {
+		l.Load.CustomFields[StartTimeField] = start
+	}
+	if end != nil {
+		l.Load.CustomFields[EndTimeField] = end
+	}
+	for _, email := range emails {
+		l.Assignments = append(l.Assignments, models.LoadAssignment{LoadID: id, PersonEmail: email, Weight: 1})
+	}
+	return l
+}
+
+func TestLoadWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end interface{}
+		want       timeWindow
+		ok         bool
+	}{
+		{"clock times", "09:00", "10:30", timeWindow{540, 630}, true},
+		{"timestamps in UTC", "2025-03-10T09:00:00+07:00", "2025-03-10T10:00:00+07:00", timeWindow{120, 180}, true},
+		{"over midnight", "23:00", "01:00", timeWindow{1380, 1500}, true},
+		{"no end", "09:00", nil, timeWindow{}, false},
+		{"not a time", "morning", "10:00", timeWindow{}, false},
+		{"not a string", 9.0, 10.0, timeWindow{}, false},
+		{"empty", "09:00", "09:00", timeWindow{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := timedLoad(1, tt.start, tt.end)
+			got, ok := loadWindow(&l.Load)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTimeWindowOverlaps(t *testing.T) {
+	assert.True(t, timeWindow{540, 600}.overlaps(timeWindow{570, 630}))
+	assert.False(t, timeWindow{540, 600}.overlaps(timeWindow{600, 660}), "back to back is no overlap")
+	assert.True(t, timeWindow{1380, 1500}.overlaps(timeWindow{30, 60}), "the part past midnight counts")
+	assert.True(t, timeWindow{30, 60}.overlaps(timeWindow{1380, 1500}))
+}
+
+func TestMarkConflicts(t *testing.T) {
+	loads := []models.LoadWithAssignments{
+		timedLoad(1, "09:00", "10:00", "alice@example.com"),
+		timedLoad(2, "09:30", "11:00", "alice@example.com", "bob@example.com"),
+		timedLoad(3, "10:30", "11:30", "bob@example.com"),
+		timedLoad(4, "09:00", "12:00", "carol@example.com"),
+		timedLoad(5, nil, nil, "alice@example.com"),
+	}
+	MarkConflicts(loads)
+
+	assert.Equal(t, []int{2}, loads[0].Conflicts)
+	assert.Equal(t, []int{1, 3}, loads[1].Conflicts)
+	assert.Equal(t, []int{2}, loads[2].Conflicts)
+	assert.Nil(t, loads[3].Conflicts, "overlapping loads of others are no conflict")
+	assert.Nil(t, loads[4].Conflicts, "loads without a window never conflict")
+}
+
+func TestScheduleConflicts(t *testing.T) {
+	standup := timedLoad(1, "09:00", "09:15", "alice@example.com")
+	day := []models.LoadWithAssignments{
+		standup,
+		timedLoad(2, "09:10", "10:00", "alice@example.com"),
+		timedLoad(3, "08:30", "09:05", "alice@example.com"),
+		timedLoad(4, "13:00", "14:00", "alice@example.com"),
+	}
+
+	load, conflicts, ok := scheduleConflicts(&standup.Load, day)
+	assert.True(t, ok)
+	assert.Equal(t, "09:00", load.Start)
+	assert.Equal(t, "09:15", load.End)
+	if assert.Len(t, conflicts, 2) {
+		assert.Equal(t, 3, conflicts[0].LoadID, "by start time")
+		assert.Equal(t, "08:30", conflicts[0].Start)
+		assert.Equal(t, 2, conflicts[1].LoadID)
+	}
+
+	lunch := timedLoad(4, "13:00", "14:00", "alice@example.com")
+	_, _, ok = scheduleConflicts(&lunch.Load, day)
+	assert.False(t, ok)
+}
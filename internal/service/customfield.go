@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// ErrInvalidCustomField is returned when an upsert sends a custom field
+// defined for its source with a value of the wrong type
+var ErrInvalidCustomField = errors.New("invalid custom field")
+
+// CustomFieldService keeps the registry of custom fields each source sends
+// with its loads. Loads keep whatever custom fields they are sent; the
+// registry types the ones it defines and picks those day details show.
+type CustomFieldService struct {
+	customFieldRepo *repository.CustomFieldRepository
+}
+
+func NewCustomFieldService(customFieldRepo *repository.CustomFieldRepository) *CustomFieldService {
+	return &CustomFieldService{customFieldRepo: customFieldRepo}
+}
+
+// List returns the custom fields defined for source, or for every source
+// when it is empty
+func (s *CustomFieldService) List(ctx context.Context, source string) ([]models.CustomField, error) {
+	return s.customFieldRepo.List(ctx, source)
+}
+
+// Put defines a custom field for source, replacing any earlier definition
+func (s *CustomFieldService) Put(ctx context.Context, source, key string, req *models.PutCustomFieldRequest) (*models.CustomField, error) {
+	return s.customFieldRepo.Put(ctx, source, key, req)
+}
+
+// Delete removes a custom field's definition
+func (s *CustomFieldService) Delete(ctx context.Context, source, key string) error {
+	return s.customFieldRepo.Delete(ctx, source, key)
+}
+
+// Check fails with ErrInvalidCustomField when fields has a value of the
+// wrong type for a custom field defined for source
+func (s *CustomFieldService) Check(ctx context.Context, source string, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	defs, err := s.customFieldRepo.List(ctx, source)
+	if err != nil {
+		return err
+	}
+	return checkCustomFields(defs, fields)
+}
+
+// Display sets the fields day details show on each of loads
+func (s *CustomFieldService) Display(ctx context.Context, loads []models.LoadWithAssignments) error {
+	sources := []string{}
+	seen := map[string]bool{}
+	for _, l := range loads {
+		if l.Load.Source != nil && len(l.Load.CustomFields) > 0 && !seen[*l.Load.Source] {
+			seen[*l.Load.Source] = true
+			sources = append(sources, *l.Load.Source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	defs, err := s.customFieldRepo.ListBySources(ctx, sources)
+	if err != nil {
+		return err
+	}
+	for i := range loads {
+		loads[i].Fields = displayedFields(defs, &loads[i].Load)
+	}
+	return nil
+}
+
+// checkCustomFields checks fields against the definitions of their source.
+// Fields without a definition, and null values, are not checked.
+func checkCustomFields(defs []models.CustomField, fields map[string]interface{}) error {
+	for _, def := range defs {
+		value, ok := fields[def.Key]
+		if !ok || value == nil {
+			continue
+		}
+		if !customFieldHasType(def.Type, value) {
+			return fmt.Errorf("%w: %s must be a %s", ErrInvalidCustomField, def.Key, def.Type)
+		}
+	}
+	return nil
+}
+
+// customFieldHasType reports whether a value decoded from JSON has type t
+func customFieldHasType(t models.CustomFieldType, value interface{}) bool {
+	switch t {
+	case models.CustomFieldNumber:
+		_, ok := value.(float64)
+		return ok
+	case models.CustomFieldBoolean:
+		_, ok := value.(bool)
+		return ok
+	case models.CustomFieldURL:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		u, err := url.Parse(s)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	default:
+		_, ok := value.(string)
+		return ok
+	}
+}
+
+// displayedFields returns the load's values of the fields defined for its
+// source to be displayed, in position order
+func displayedFields(defs []models.CustomField, load *models.Load) []models.CustomFieldValue {
+	if load.Source == nil || len(load.CustomFields) == 0 {
+		return nil
+	}
+
+	var shown []models.CustomField
+	for _, def := range defs {
+		if def.Source == *load.Source && def.Display {
+			shown = append(shown, def)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		if shown[i].Position != shown[j].Position {
+			return shown[i].Position < shown[j].Position
+		}
+		return shown[i].Key < shown[j].Key
+	})
+
+	var values []models.CustomFieldValue
+	for _, def := range shown {
+		value, ok := load.CustomFields[def.Key]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		// Links that would not pass Check, such as ones stored before the
+		// field was defined, are shown as text
+		t := def.Type
+		if t == models.CustomFieldURL && !customFieldHasType(t, value) {
+			t = models.CustomFieldString
+			value = fmt.Sprint(value)
+		}
+		values = append(values, models.CustomFieldValue{
+			Key:   def.Key,
+			Label: def.Label,
+			Type:  t,
+			Value: value,
+		})
+	}
+	return values
+}
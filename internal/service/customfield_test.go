@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCustomFields(t *testing.T) {
+	defs := []models.CustomField{
+		{Source: "gcal", Key: "attendees", Type: models.CustomFieldNumber},
+		{Source: "gcal", Key: "recorded", Type: models.CustomFieldBoolean},
+		{Source: "gcal", Key: "meeting_link", Type: models.CustomFieldURL},
+		{Source: "gcal", Key: "room", Type: models.CustomFieldString},
+	}
+
+	assert.NoError(t, checkCustomFields(defs, map[string]interface{}{
+		"attendees":    12.0,
+		"recorded":     true,
+		"meeting_link": "https://meet.example.com/abc",
+		"room":         "Orchid",
+		"organizer":    42.0,
+	}), "values of the defined types pass, and undefined fields are not checked")
+	assert.NoError(t, checkCustomFields(defs, map[string]interface{}{"attendees": nil}), "nulls are not checked")
+
+	for name, fields := range map[string]map[string]interface{}{
+		"number":  {"attendees": "12"},
+		"boolean": {"recorded": "yes"},
+		"url":     {"meeting_link": "meet.example.com/abc"},
+		"scheme":  {"meeting_link": "javascript:alert(1)"},
+		"string":  {"room": 4.0},
+	} {
+		err := checkCustomFields(defs, fields)
+		assert.ErrorIs(t, err, ErrInvalidCustomField, name)
+	}
+}
+
+func TestDisplayedFields(t *testing.T) {
+	defs := []models.CustomField{
+		{Source: "gcal", Key: "room", Label: "Room", Type: models.CustomFieldString, Display: true, Position: 2},
+		{Source: "gcal", Key: "meeting_link", Label: "Meeting", Type: models.CustomFieldURL, Display: true, Position: 1},
+		{Source: "gcal", Key: "attendees", Label: "Attendees", Type: models.CustomFieldNumber},
+		{Source: "jira", Key: "room", Label: "Jira room", Type: models.CustomFieldString, Display: true},
+	}
+	source := "gcal"
+	load := &models.Load{
+		Source: &source,
+		CustomFields: map[string]interface{}{
+			"room":         "Orchid",
+			"meeting_link": "not a link",
+			"attendees":    12.0,
+		},
+	}
+
+	got := displayedFields(defs, load)
+	assert.Equal(t, []models.CustomFieldValue{
+		{Key: "meeting_link", Label: "Meeting", Type: models.CustomFieldString, Value: "not a link"},
+		{Key: "room", Label: "Room", Type: models.CustomFieldString, Value: "Orchid"},
+	}, got, "only displayed fields of the load's source, in position order, with bad links as text")
+
+	assert.Nil(t, displayedFields(defs, &models.Load{Source: &source}), "loads without custom fields show none")
+}
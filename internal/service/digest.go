@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// digestDays is how many days, from Monday, a weekly digest shows
+const digestDays = 14
+
+// DigestService posts each group's upcoming two weeks as a heatmap image to
+// the group's Lark chat every Monday
+type DigestService struct {
+	digestRepo     *repository.DigestRepository
+	entityRepo     *repository.EntityRepository
+	groupRepo      *repository.GroupRepository
+	heatmapService *HeatmapService
+	lark           *LarkClient
+
+	// publicURL is the base of the links back to the app, empty for
+	// relative links
+	publicURL string
+}
+
+func NewDigestService(
+	digestRepo *repository.DigestRepository,
+	entityRepo *repository.EntityRepository,
+	groupRepo *repository.GroupRepository,
+	heatmapService *HeatmapService,
+	lark *LarkClient,
+	publicURL string,
+) *DigestService {
+	return &DigestService{
+		digestRepo:     digestRepo,
+		entityRepo:     entityRepo,
+		groupRepo:      groupRepo,
+		heatmapService: heatmapService,
+		lark:           lark,
+		publicURL:      publicURL,
+	}
+}
+
+// checkGroup fails unless groupID is a group the request's API key may
+// write for
+func (s *DigestService) checkGroup(ctx context.Context, groupID string) error {
+	group, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.Type != models.EntityTypeGroup {
+		return repository.ErrNotAGroup
+	}
+	if groups, ok := KeyScope(ctx); ok && !slices.Contains(groups, groupID) {
+		return fmt.Errorf("%w: %s", ErrOutOfScope, groupID)
+	}
+	return nil
+}
+
+// Get returns a group's weekly Lark digest
+func (s *DigestService) Get(ctx context.Context, groupID string) (*models.LarkDigest, error) {
+	if err := s.checkGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.digestRepo.Get(ctx, groupID)
+}
+
+// Put posts a group's weekly digest to a Lark chat, enabled unless the
+// request says otherwise
+func (s *DigestService) Put(ctx context.Context, groupID string, req *models.PutLarkDigestRequest) (*models.LarkDigest, error) {
+	if err := s.checkGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+	return s.digestRepo.Put(ctx, groupID, strings.TrimSpace(req.ChatID), enabled)
+}
+
+// Delete stops posting a group's weekly digest
+func (s *DigestService) Delete(ctx context.Context, groupID string) error {
+	if err := s.checkGroup(ctx, groupID); err != nil {
+		return err
+	}
+	return s.digestRepo.Delete(ctx, groupID)
+}
+
+// RunWeeklyDigests calls SendDigests every Monday at the given offset from
+// midnight UTC until ctx is cancelled.
+func (s *DigestService) RunWeeklyDigests(ctx context.Context, at time.Duration) {
+	for {
+		timer := time.NewTimer(time.Until(nextWeeklyDigest(time.Now(), at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sent, err := s.SendDigests(ctx, time.Now())
+		if err != nil {
+			log.Printf("Lark digests: %v", err)
+		}
+		log.Printf("Posted %d Lark digests", sent)
+	}
+}
+
+// nextWeeklyDigest returns the first Monday after now at the given offset
+// from midnight UTC
+func nextWeeklyDigest(now time.Time, at time.Duration) time.Time {
+	now = now.UTC()
+	daysToMonday := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+	next := utcDate(now).AddDate(0, 0, daysToMonday).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// digestWeek returns the Monday starting the week of now, in UTC
+func digestWeek(now time.Time) time.Time {
+	today := utcDate(now)
+	return today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+}
+
+// SendDigests posts every enabled group's digest for the week of now that no
+// server has posted yet, and returns how many it posted. A group whose post
+// fails is logged and skipped until the next week.
+func (s *DigestService) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	digests, err := s.digestRepo.ListEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	monday := digestWeek(now)
+	sent := 0
+	for _, d := range digests {
+		claimed, err := s.digestRepo.Claim(ctx, d.GroupID, monday)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.Send(ctx, d.GroupID, d.ChatID, monday); err != nil {
+			log.Printf("Lark digest for %s: %v", d.GroupID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Send posts a group's heatmap for the two weeks from start to a Lark chat:
+// a row for the group, then one for each member whose heatmap is not
+// private, by name
+func (s *DigestService) Send(ctx context.Context, groupID, chatID string, start time.Time) error {
+	group, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	emails, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	members := make([]models.Entity, 0, len(emails))
+	for _, email := range emails {
+		member, err := s.entityRepo.GetByID(ctx, email)
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !member.Private && member.ArchivedAt == nil {
+			members = append(members, *member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Title < members[j].Title })
+
+	end := start.AddDate(0, 0, digestDays-1)
+	entities := append([]models.Entity{*group}, members...)
+	rows := make([][]models.HeatmapDay, 0, len(entities))
+	for i := range entities {
+		days, err := s.heatmapService.computeHeatmapDays(ctx, &entities[i], start, end, nil, "", nil)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, days)
+	}
+
+	image, err := RenderHeatmapPNG(rows)
+	if err != nil {
+		return err
+	}
+	title := fmt.Sprintf("%s: %s – %s", group.Title, start.Format("Jan 2"), end.Format("Jan 2"))
+	return s.lark.PostImage(ctx, chatID, title, image, digestParagraphs(s.publicURL, entities, rows))
+}
+
+// digestParagraphs captions a digest image: which entity each row is, each
+// linked to its heatmap, who is over capacity on which days, and a link to
+// the group's heatmap. entities and rows are in the image's order, the group
+// first.
+func digestParagraphs(publicURL string, entities []models.Entity, rows [][]models.HeatmapDay) [][]map[string]string {
+	link := func(e models.Entity) string {
+		return publicURL + "/?entity=" + url.QueryEscape(e.ID)
+	}
+
+	legend := []map[string]string{{"tag": "text", "text": "Rows, top to bottom: "}}
+	for i, e := range entities {
+		if i > 0 {
+			legend = append(legend, map[string]string{"tag": "text", "text": ", "})
+		}
+		legend = append(legend, map[string]string{"tag": "a", "text": e.Title, "href": link(e)})
+	}
+
+	var overloaded []string
+	for i, e := range entities[1:] {
+		var days []string
+		for _, day := range rows[i+1] {
+			if utilization(day.Load, day.Capacity) > 1 {
+				days = append(days, day.Date.Format("Mon Jan 2"))
+			}
+		}
+		if len(days) > 0 {
+			overloaded = append(overloaded, e.Title+" on "+strings.Join(days, ", "))
+		}
+	}
+	summary := "Nobody is over capacity."
+	if len(overloaded) > 0 {
+		summary = "Over capacity: " + strings.Join(overloaded, "; ") + "."
+	}
+
+	return [][]map[string]string{
+		legend,
+		{{"tag": "text", "text": summary}},
+		{{"tag": "a", "text": "Open the heatmap", "href": link(entities[0])}},
+	}
+}
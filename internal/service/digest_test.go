@@ -0,0 +1,93 @@
+package service
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextWeeklyDigest(t *testing.T) {
+	at := 9 * time.Hour
+	wednesday := time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC), nextWeeklyDigest(wednesday, at))
+
+	mondayEarly := time.Date(2025, 3, 17, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC), nextWeeklyDigest(mondayEarly, at), "later the same Monday")
+
+	mondayLate := time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 24, 9, 0, 0, 0, time.UTC), nextWeeklyDigest(mondayLate, at), "a week later once it is past")
+}
+
+func TestDigestWeek(t *testing.T) {
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, digestWeek(time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, monday, digestWeek(time.Date(2025, 3, 16, 23, 0, 0, 0, time.UTC)), "Sunday ends the week")
+}
+
+func TestRenderHeatmapPNG(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	row := func(color string) []models.HeatmapDay {
+		days := make([]models.HeatmapDay, digestDays)
+		for i := range days {
+			days[i] = models.HeatmapDay{Date: start.AddDate(0, 0, i), Color: color}
+		}
+		return days
+	}
+
+	data, err := RenderHeatmapPNG([][]models.HeatmapDay{row("#dc2626"), row("#22c55e")})
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	bounds := img.Bounds()
+	assert.Equal(t, 2*imagePadding+imageColumnX(digestDays-1)+imageCell, bounds.Dx())
+	assert.Equal(t, 2*imagePadding+2*imageCell+imageGap, bounds.Dy())
+
+	r, g, b, _ := img.At(imagePadding, imagePadding).RGBA()
+	assert.Equal(t, []uint32{0xdc, 0x26, 0x26}, []uint32{r >> 8, g >> 8, b >> 8}, "first row in its color")
+	r, g, b, _ = img.At(imagePadding, imagePadding+imageCell+imageGap).RGBA()
+	assert.Equal(t, []uint32{0x22, 0xc5, 0x5e}, []uint32{r >> 8, g >> 8, b >> 8}, "second row in its color")
+
+	_, err = RenderHeatmapPNG(nil)
+	assert.Error(t, err)
+}
+
+func TestDigestParagraphs(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	day := func(offset int, load, capacity float64) models.HeatmapDay {
+		return models.HeatmapDay{Date: start.AddDate(0, 0, offset), Load: load, Capacity: capacity}
+	}
+	entities := []models.Entity{
+		{ID: "platform", Title: "Platform"},
+		{ID: "alice@example.com", Title: "Alice"},
+		{ID: "bob@example.com", Title: "Bob"},
+	}
+	rows := [][]models.HeatmapDay{
+		{day(0, 9, 10), day(1, 11, 10)},
+		{day(0, 6, 5), day(1, 7, 5)},
+		{day(0, 4, 5), day(1, 0, 0)},
+	}
+
+	got := digestParagraphs("https://heatmap.example.com", entities, rows)
+	assert.Equal(t, [][]map[string]string{
+		{
+			{"tag": "text", "text": "Rows, top to bottom: "},
+			{"tag": "a", "text": "Platform", "href": "https://heatmap.example.com/?entity=platform"},
+			{"tag": "text", "text": ", "},
+			{"tag": "a", "text": "Alice", "href": "https://heatmap.example.com/?entity=alice%40example.com"},
+			{"tag": "text", "text": ", "},
+			{"tag": "a", "text": "Bob", "href": "https://heatmap.example.com/?entity=bob%40example.com"},
+		},
+		{{"tag": "text", "text": "Over capacity: Alice on Mon Mar 10, Tue Mar 11."}},
+		{{"tag": "a", "text": "Open the heatmap", "href": "https://heatmap.example.com/?entity=platform"}},
+	}, got, "members over capacity are named, but not the group")
+
+	rows[1] = []models.HeatmapDay{day(0, 1, 5)}
+	got = digestParagraphs("", entities, rows)
+	assert.Equal(t, "Nobody is over capacity.", got[1][0]["text"])
+}
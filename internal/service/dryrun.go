@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context for a write sent with ?dry_run=true. The
+// write is checked as usual but not made; what it would do is filled in the
+// result DryRun returns instead.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, &models.DryRunResult{DryRun: true})
+}
+
+// DryRun returns what a dry-run write would do, or nil when the request is
+// not a dry run
+func DryRun(ctx context.Context) *models.DryRunResult {
+	dry, _ := ctx.Value(dryRunKey{}).(*models.DryRunResult)
+	return dry
+}
+
+// personDayLoads is a load per person per day
+type personDayLoads map[string]map[time.Time]float64
+
+// loadShares returns the share of its assignment weights a load puts on each
+// day of its occurrences starting on starts, as the load_days view counts it
+func loadShares(load *models.Load, starts []time.Time) map[time.Time]float64 {
+	share := 1.0
+	if load.Spread != models.LoadSpreadPerDay {
+		share = 1 / (lastDay(load).Sub(load.Date).Hours()/24 + 1)
+	}
+
+	shares := make(map[time.Time]float64)
+	for _, day := range coveredDays(load, starts) {
+		shares[day] += share
+	}
+	return shares
+}
+
+// assignmentLoads returns the load each assignment puts on each of days,
+// given the share of the weights falling on each day
+func assignmentLoads(assignments []models.LoadAssignment, shares map[time.Time]float64, days []time.Time) personDayLoads {
+	loads := make(personDayLoads, len(assignments))
+	for _, a := range assignments {
+		if loads[a.PersonEmail] == nil {
+			loads[a.PersonEmail] = make(map[time.Time]float64, len(days))
+		}
+		for _, day := range days {
+			loads[a.PersonEmail][day] += a.Weight * shares[day]
+		}
+	}
+	return loads
+}
+
+// mergeDays returns the days in either list, in order and once each
+func mergeDays(a, b []time.Time) []time.Time {
+	seen := make(map[time.Time]bool, len(a)+len(b))
+	days := make([]time.Time, 0, len(a)+len(b))
+	for _, day := range append(append([]time.Time(nil), a...), b...) {
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// dryRunCreated returns the emails that are not known entities and a write
+// would create as persons, failing with ErrUnknownAssignee as the write
+// would when auto-creation is disabled
+func (s *LoadService) dryRunCreated(ctx context.Context, emails []string) ([]string, error) {
+	missing, err := s.entityRepo.Missing(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 && s.noAutoCreate {
+		return nil, fmt.Errorf("%w: %s (auto-creation is disabled)", ErrUnknownAssignee, strings.Join(missing, ", "))
+	}
+	return missing, nil
+}
+
+// dryRunUpsert fills dry with what upserting load with assignments would do:
+// create or update it, the assignees it would create, and each person's load
+// on the days its first occurrence covers, and covered before if it moves
+func (s *LoadService) dryRunUpsert(ctx context.Context, dry *models.DryRunResult, externalID string, load *models.Load, starts []time.Time, assignments []models.LoadAssignment, blackouts []models.BlackoutConflict) error {
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		emails = append(emails, a.PersonEmail)
+	}
+	created, err := s.dryRunCreated(ctx, emails)
+	if err != nil {
+		return err
+	}
+
+	dry.Action = models.DryRunCreate
+	days := coveredDays(load, starts[:1])
+	var before personDayLoads
+	id, err := s.loadRepo.GetIDByExternalID(ctx, externalID)
+	switch {
+	case err == nil:
+		current, err := s.loadRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		dry.Action = models.DryRunUpdate
+		load.ID = id
+		days = mergeDays(days, coveredDays(&current.Load, []time.Time{current.Load.Date}))
+		shares, err := s.loadRepo.GetLoadShares(ctx, id, days[0], days[len(days)-1])
+		if err != nil {
+			return err
+		}
+		before = assignmentLoads(current.Assignments, shares, days)
+	case !errors.Is(err, repository.ErrLoadNotFound):
+		return err
+	}
+
+	dry.Result = models.LoadWithAssignments{Load: *load, Assignments: assignments}
+	dry.AutoCreated = created
+	dry.Blackouts = blackouts
+	after := assignmentLoads(assignments, loadShares(load, starts), days)
+	return s.dryRunLoadChange(ctx, dry, days, before, after, created)
+}
+
+// dryRunAssignees fills dry with each person's load on the days a load's
+// first occurrence covers, once its assignments are replaced by assignments
+func (s *LoadService) dryRunAssignees(ctx context.Context, dry *models.DryRunResult, load *models.LoadWithAssignments, assignments []models.LoadAssignment, created []string) error {
+	days := coveredDays(&load.Load, []time.Time{load.Load.Date})
+	shares, err := s.loadRepo.GetLoadShares(ctx, load.Load.ID, days[0], days[len(days)-1])
+	if err != nil {
+		return err
+	}
+	before := assignmentLoads(load.Assignments, shares, days)
+	after := assignmentLoads(assignments, shares, days)
+	return s.dryRunLoadChange(ctx, dry, days, before, after, created)
+}
+
+// dryRunLoadChange fills dry with each affected person's total load and
+// capacity on days, now and once their load from a change goes from before
+// to after. Persons in created do not exist yet and would get the default
+// capacity.
+func (s *LoadService) dryRunLoadChange(ctx context.Context, dry *models.DryRunResult, days []time.Time, before, after personDayLoads, created []string) error {
+	first, last := days[0], days[len(days)-1]
+
+	emails := make([]string, 0, len(before)+len(after))
+	for email := range before {
+		emails = append(emails, email)
+	}
+	for email := range after {
+		if _, ok := before[email]; !ok {
+			emails = append(emails, email)
+		}
+	}
+	sort.Strings(emails)
+	isCreated := make(map[string]bool, len(created))
+	for _, email := range created {
+		isCreated[email] = true
+	}
+
+	for _, email := range emails {
+		loads := map[time.Time]float64{}
+		capacitiesBefore := map[time.Time]float64{}
+		capacities := make(map[time.Time]float64, len(days))
+		if isCreated[email] {
+			for _, day := range days {
+				capacities[day] = s.settings.DefaultCapacity()
+			}
+		} else {
+			var err error
+			if loads, err = s.loadRepo.GetPersonLoadForDateRange(ctx, email, first, last, nil, ""); err != nil {
+				return err
+			}
+			if capacities, err = s.capacityRepo.GetCapacitiesForRange(ctx, email, first, last); err != nil {
+				return err
+			}
+			capacitiesBefore = capacities
+		}
+
+		for _, day := range days {
+			load := loads[day] - before[email][day] + after[email][day]
+			dry.Days = append(dry.Days, models.DryRunDay{
+				EntityID:       email,
+				Date:           day.Format("2006-01-02"),
+				LoadBefore:     loads[day],
+				Load:           load,
+				CapacityBefore: capacitiesBefore[day],
+				Capacity:       capacities[day],
+				Overloaded:     load > capacities[day],
+			})
+		}
+	}
+	return nil
+}
+
+// capacityRules are how an entity's capacity on a day is found: its date
+// override, else its weekday in the weekly pattern, else its default
+type capacityRules struct {
+	defaultCapacity float64
+	weekly          map[time.Weekday]float64
+	overrides       map[time.Time]float64
+}
+
+// on returns the capacity on day
+func (r capacityRules) on(day time.Time) float64 {
+	if capacity, ok := r.overrides[day]; ok {
+		return capacity
+	}
+	if capacity, ok := r.weekly[day.Weekday()]; ok {
+		return capacity
+	}
+	return r.defaultCapacity
+}
+
+// with returns the rules once req is applied, failing as applying it would
+func (r capacityRules) with(req *models.UpdateCapacityRequest) (capacityRules, error) {
+	next := capacityRules{
+		defaultCapacity: r.defaultCapacity,
+		weekly:          make(map[time.Weekday]float64, len(r.weekly)),
+		overrides:       make(map[time.Time]float64, len(r.overrides)),
+	}
+	for weekday, capacity := range r.weekly {
+		next.weekly[weekday] = capacity
+	}
+	for day, capacity := range r.overrides {
+		next.overrides[day] = capacity
+	}
+
+	if req.DefaultCapacity != nil {
+		if *req.DefaultCapacity < 0 {
+			return capacityRules{}, fmt.Errorf("failed to update default capacity: capacity cannot be negative")
+		}
+		next.defaultCapacity = *req.DefaultCapacity
+	}
+	for _, override := range req.DateOverrides {
+		date, err := time.Parse("2006-01-02", override.Date)
+		if err != nil {
+			return capacityRules{}, fmt.Errorf("invalid date format for %s: %w", override.Date, err)
+		}
+		if override.Capacity < 0 {
+			return capacityRules{}, fmt.Errorf("failed to set override for %s: capacity cannot be negative", override.Date)
+		}
+		next.overrides[date] = override.Capacity
+	}
+	for _, day := range req.WeeklyPattern {
+		weekday, err := parseWeekday(day.Weekday)
+		if err != nil {
+			return capacityRules{}, err
+		}
+		switch {
+		case day.Capacity == nil:
+			delete(next.weekly, weekday)
+		case *day.Capacity < 0:
+			return capacityRules{}, fmt.Errorf("failed to set %s capacity: capacity cannot be negative", day.Weekday)
+		default:
+			next.weekly[weekday] = *day.Capacity
+		}
+	}
+	return next, nil
+}
+
+// capacityRulesFor reads an entity's capacity rules, with its overrides on
+// days
+func (s *CapacityService) capacityRulesFor(ctx context.Context, entity *models.Entity, days []time.Time) (capacityRules, error) {
+	weekly, err := s.capacityRepo.GetWeeklyPattern(ctx, entity.ID)
+	if err != nil {
+		return capacityRules{}, err
+	}
+	overrides, err := s.capacityRepo.GetOverridesRange(ctx, entity.ID, days[0], days[len(days)-1])
+	if err != nil {
+		return capacityRules{}, err
+	}
+
+	rules := capacityRules{
+		defaultCapacity: entity.DefaultCapacity,
+		weekly:          weekly,
+		overrides:       make(map[time.Time]float64, len(overrides)),
+	}
+	for _, o := range overrides {
+		rules.overrides[utcDate(o.Date)] = o.Capacity
+	}
+	return rules, nil
+}
+
+// dryRunCapacity fills dry with what a capacity change would do: hold it for
+// approval, or change the capacity on the coming two weeks and the days it
+// overrides
+func (s *CapacityService) dryRunCapacity(ctx context.Context, dry *models.DryRunResult, entityID string, req *models.UpdateCapacityRequest) error {
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	if s.approvalZeroDays > 0 {
+		reason, err := s.approvalReason(ctx, entity, req)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			dry.Action = models.DryRunRequestApproval
+			dry.Result = &models.CapacityChangeRequest{
+				EntityID: entityID,
+				Change:   *req,
+				Status:   models.CapacityChangePending,
+				Reason:   reason,
+			}
+			return nil
+		}
+	}
+
+	today := utcDate(time.Now())
+	days := make([]time.Time, 0, dryRunCapacityDays+len(req.DateOverrides))
+	for d := 0; d < dryRunCapacityDays; d++ {
+		days = append(days, today.AddDate(0, 0, d))
+	}
+	var overridden []time.Time
+	for _, override := range req.DateOverrides {
+		if date, err := time.Parse("2006-01-02", override.Date); err == nil {
+			overridden = append(overridden, date)
+		}
+	}
+	days = mergeDays(days, overridden)
+
+	rules, err := s.capacityRulesFor(ctx, entity, days)
+	if err != nil {
+		return err
+	}
+	next, err := rules.with(req)
+	if err != nil {
+		return err
+	}
+
+	dry.Action = models.DryRunUpdate
+	dry.Result = req
+	return s.dryRunCapacityDays(ctx, dry, entityID, days, rules, next)
+}
+
+// dryRunDeleteOverride fills dry with the override a deletion would remove
+// and the capacity on its day without it
+func (s *CapacityService) dryRunDeleteOverride(ctx context.Context, dry *models.DryRunResult, entityID string, date time.Time) error {
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+	override, err := s.capacityRepo.GetOverride(ctx, entityID, date)
+	if err != nil {
+		return err
+	}
+
+	days := []time.Time{date}
+	rules, err := s.capacityRulesFor(ctx, entity, days)
+	if err != nil {
+		return err
+	}
+	next := rules
+	next.overrides = map[time.Time]float64{}
+
+	dry.Action = models.DryRunDelete
+	if override != nil {
+		dry.Result = override
+	}
+	return s.dryRunCapacityDays(ctx, dry, entityID, days, rules, next)
+}
+
+// dryRunCapacityDays fills dry with the entity's load on days against its
+// capacity under the rules before and after a change
+func (s *CapacityService) dryRunCapacityDays(ctx context.Context, dry *models.DryRunResult, entityID string, days []time.Time, before, after capacityRules) error {
+	loads := map[time.Time]float64{}
+	if s.loadRepo != nil {
+		var err error
+		if loads, err = s.loadRepo.GetPersonLoadForDateRange(ctx, entityID, days[0], days[len(days)-1], nil, ""); err != nil {
+			return err
+		}
+	}
+
+	for _, day := range days {
+		capacity := after.on(day)
+		dry.Days = append(dry.Days, models.DryRunDay{
+			EntityID:       entityID,
+			Date:           day.Format("2006-01-02"),
+			LoadBefore:     loads[day],
+			Load:           loads[day],
+			CapacityBefore: before.on(day),
+			Capacity:       capacity,
+			Overloaded:     loads[day] > capacity,
+		})
+	}
+	return nil
+}
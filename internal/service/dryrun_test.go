@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunContext(t *testing.T) {
+	assert.Nil(t, DryRun(context.Background()), "not a dry run")
+
+	ctx := WithDryRun(context.Background())
+	dry := DryRun(ctx)
+	require.NotNil(t, dry)
+	assert.True(t, dry.DryRun)
+	dry.Action = models.DryRunCreate
+	assert.Equal(t, models.DryRunCreate, DryRun(ctx).Action, "services fill in the request's result")
+}
+
+func TestLoadShares(t *testing.T) {
+	end := day(2025, 3, 13)
+	load := &models.Load{Date: day(2025, 3, 10), EndDate: &end, Spread: models.LoadSpreadEven}
+	shares := loadShares(load, []time.Time{load.Date})
+	assert.Len(t, shares, 4)
+	assert.Equal(t, 0.25, shares[day(2025, 3, 12)], "split evenly over four days")
+
+	load.Spread = models.LoadSpreadPerDay
+	shares = loadShares(load, []time.Time{load.Date, day(2025, 3, 12)})
+	assert.Equal(t, 1.0, shares[day(2025, 3, 10)])
+	assert.Equal(t, 2.0, shares[day(2025, 3, 12)], "overlapping occurrences add up")
+	assert.Equal(t, 1.0, shares[day(2025, 3, 15)])
+}
+
+func TestAssignmentLoads(t *testing.T) {
+	days := []time.Time{day(2025, 3, 10), day(2025, 3, 11)}
+	shares := map[time.Time]float64{day(2025, 3, 10): 0.5, day(2025, 3, 11): 0.5}
+	loads := assignmentLoads([]models.LoadAssignment{
+		{PersonEmail: "a@example.com", Weight: 2},
+		{PersonEmail: "b@example.com", Weight: 1},
+	}, shares, days)
+
+	assert.Equal(t, 1.0, loads["a@example.com"][day(2025, 3, 11)])
+	assert.Equal(t, 0.5, loads["b@example.com"][day(2025, 3, 10)])
+	assert.Zero(t, loads["c@example.com"][day(2025, 3, 10)], "not assigned")
+}
+
+func TestMergeDays(t *testing.T) {
+	merged := mergeDays(
+		[]time.Time{day(2025, 3, 12), day(2025, 3, 13)},
+		[]time.Time{day(2025, 3, 10), day(2025, 3, 12)},
+	)
+	assert.Equal(t, []time.Time{day(2025, 3, 10), day(2025, 3, 12), day(2025, 3, 13)}, merged)
+}
+
+func TestCapacityRules(t *testing.T) {
+	friday, monday := day(2025, 3, 14), day(2025, 3, 17)
+	rules := capacityRules{
+		defaultCapacity: 8,
+		weekly:          map[time.Weekday]float64{time.Friday: 4},
+		overrides:       map[time.Time]float64{monday: 0},
+	}
+	assert.Equal(t, 4.0, rules.on(friday), "the weekly pattern")
+	assert.Equal(t, 0.0, rules.on(monday), "the override")
+	assert.Equal(t, 8.0, rules.on(day(2025, 3, 18)), "the default")
+
+	six := 6.0
+	req := &models.UpdateCapacityRequest{
+		DefaultCapacity: &six,
+		WeeklyPattern:   []models.WeekdayCapacity{{Weekday: "friday"}},
+	}
+	req.DateOverrides = append(req.DateOverrides, struct {
+		Date     string  `json:"date" validate:"required"`
+		Capacity float64 `json:"capacity" validate:"required,min=0"`
+	}{Date: "2025-03-18", Capacity: 2})
+	next, err := rules.with(req)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, next.on(friday), "a cleared weekday takes the new default")
+	assert.Equal(t, 0.0, next.on(monday), "overrides left alone stay")
+	assert.Equal(t, 2.0, next.on(day(2025, 3, 18)))
+	assert.Equal(t, 4.0, rules.on(friday), "the rules before are unchanged")
+
+	_, err = rules.with(&models.UpdateCapacityRequest{WeeklyPattern: []models.WeekdayCapacity{{Weekday: "someday"}}})
+	assert.ErrorIs(t, err, ErrInvalidWeekday)
+	negative := -1.0
+	_, err = rules.with(&models.UpdateCapacityRequest{DefaultCapacity: &negative})
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+)
+
+// EventLog records every change to loads, capacity, entities and groups in
+// the append-only domain event log, for auditing and for rebuilding derived
+// tables such as heatmap snapshots after they go wrong
+type EventLog struct {
+	eventRepo *repository.EventRepository
+}
+
+func NewEventLog(eventRepo *repository.EventRepository) *EventLog {
+	return &EventLog{eventRepo: eventRepo}
+}
+
+// Record appends an event for a change that has been made, by actor when
+// known, to the data of the given entities. A nil EventLog records nothing,
+// and failing to append is only logged, so the change stands regardless.
+func (l *EventLog) Record(ctx context.Context, eventType models.DomainEventType, actor string, entityIDs []string, payload interface{}) {
+	if l == nil {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Domain event %s: failed to encode payload: %v", eventType, err)
+		return
+	}
+	if entityIDs == nil {
+		entityIDs = []string{}
+	}
+
+	err = l.eventRepo.Append(ctx, &models.DomainEvent{
+		Type:      eventType,
+		Actor:     actor,
+		EntityIDs: entityIDs,
+		Payload:   body,
+	})
+	if err != nil {
+		log.Printf("Domain event %s: %v", eventType, err)
+	}
+}
+
+// List returns events after the given ID, oldest first, optionally only
+// those that changed entityID. Limit defaults to 100 and is capped at 1000.
+func (l *EventLog) List(ctx context.Context, afterID int64, entityID string, limit int) ([]models.DomainEvent, error) {
+	if limit <= 0 {
+		limit = defaultEventLimit
+	}
+	if limit > maxEventLimit {
+		limit = maxEventLimit
+	}
+	return l.eventRepo.List(ctx, afterID, entityID, limit)
+}
+
+// ChangedEntities returns the entities whose heatmaps the events after the
+// given ID changed, and the last event's ID to replay from next time
+func (l *EventLog) ChangedEntities(ctx context.Context, afterID int64) ([]string, int64, error) {
+	return l.eventRepo.GetChangedEntities(ctx, afterID)
+}
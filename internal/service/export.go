@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// exportColumns are the columns of exported person-day facts, in order
+var exportColumns = []string{"date", "person_email", "load", "capacity", "utilization", "overloaded"}
+
+// ExportDestination stores the person-day facts of one day, replacing any
+// exported for that day before, so a day can be exported again
+type ExportDestination interface {
+	WriteFacts(ctx context.Context, date time.Time, facts []models.PersonDayFact) error
+}
+
+// ExportService exports daily person load and capacity facts for analytics,
+// so reporting queries run against the warehouse instead of this database
+type ExportService struct {
+	reportRepo  *repository.ReportRepository
+	destination ExportDestination
+}
+
+func NewExportService(reportRepo *repository.ReportRepository, destination ExportDestination) *ExportService {
+	return &ExportService{reportRepo: reportRepo, destination: destination}
+}
+
+// RunNightlyExport exports the previous UTC day's facts every day at the
+// given offset from midnight UTC until ctx is cancelled.
+func (s *ExportService) RunNightlyExport(ctx context.Context, at time.Duration) {
+	for {
+		timer := time.NewTimer(time.Until(nextSnapshotRefresh(time.Now(), at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		date := utcDate(time.Now()).AddDate(0, 0, -1)
+		exported, err := s.ExportDay(ctx, date)
+		if err != nil {
+			log.Printf("Warehouse export %s: %v", date.Format("2006-01-02"), err)
+			continue
+		}
+		log.Printf("Exported %d person-day facts for %s", exported, date.Format("2006-01-02"))
+	}
+}
+
+// ExportDay writes every active person's load and capacity on date to the
+// destination and returns how many facts were written
+func (s *ExportService) ExportDay(ctx context.Context, date time.Time) (int, error) {
+	date = utcDate(date)
+
+	entities, err := s.reportRepo.GetEntities(ctx, date)
+	if err != nil {
+		return 0, err
+	}
+	capacities, err := s.reportRepo.GetDailyCapacities(ctx, date, date)
+	if err != nil {
+		return 0, err
+	}
+	loads, err := s.reportRepo.GetDailySourceLoads(ctx, date, date)
+	if err != nil {
+		return 0, err
+	}
+
+	facts := personDayFacts(entities, capacities, loads)
+	if err := s.destination.WriteFacts(ctx, date, facts); err != nil {
+		return 0, err
+	}
+	return len(facts), nil
+}
+
+// personDayFacts totals each person's load over sources per day they were
+// active, ordered by date and email. Groups are left out; the warehouse
+// joins memberships itself.
+func personDayFacts(entities []models.Entity, capacities []models.EntityDayCapacity, loads []models.SourceDayLoad) []models.PersonDayFact {
+	persons := make(map[string]bool)
+	for _, e := range entities {
+		if e.Type == models.EntityTypePerson {
+			persons[e.ID] = true
+		}
+	}
+
+	type personDay struct {
+		email string
+		date  time.Time
+	}
+	dayLoads := make(map[personDay]float64)
+	for _, l := range loads {
+		dayLoads[personDay{l.PersonEmail, utcDate(l.Date)}] += l.Load
+	}
+
+	facts := []models.PersonDayFact{}
+	for _, c := range capacities {
+		if !persons[c.EntityID] {
+			continue
+		}
+		date := utcDate(c.Date)
+		facts = append(facts, models.PersonDayFact{
+			Date:        date,
+			PersonEmail: c.EntityID,
+			Load:        dayLoads[personDay{c.EntityID, date}],
+			Capacity:    c.Capacity,
+		})
+	}
+
+	sort.Slice(facts, func(i, j int) bool {
+		if !facts[i].Date.Equal(facts[j].Date) {
+			return facts[i].Date.Before(facts[j].Date)
+		}
+		return facts[i].PersonEmail < facts[j].PersonEmail
+	})
+	return facts
+}
+
+// RenderFactsCSV renders facts as CSV with a header row. Utilization is
+// left empty on days without capacity.
+func RenderFactsCSV(facts []models.PersonDayFact) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(exportColumns)
+	for _, f := range facts {
+		utilization := ""
+		if f.Capacity > 0 {
+			utilization = formatFact(roundTo(f.Load/f.Capacity, 4))
+		}
+		_ = w.Write([]string{
+			f.Date.Format("2006-01-02"),
+			f.PersonEmail,
+			formatFact(f.Load),
+			formatFact(f.Capacity),
+			utilization,
+			strconv.FormatBool(f.Load > f.Capacity),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func formatFact(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// BucketDestination uploads each day's facts as a CSV object named
+// <prefix>/<date>.csv to an S3 or GCS bucket
+type BucketDestination struct {
+	store  *ObjectStore
+	prefix string
+}
+
+func NewBucketDestination(store *ObjectStore, prefix string) *BucketDestination {
+	return &BucketDestination{store: store, prefix: strings.Trim(prefix, "/")}
+}
+
+// WriteFacts uploads the day's CSV, replacing the object if it exists
+func (d *BucketDestination) WriteFacts(ctx context.Context, date time.Time, facts []models.PersonDayFact) error {
+	key := date.Format("2006-01-02") + ".csv"
+	if d.prefix != "" {
+		key = d.prefix + "/" + key
+	}
+	if err := d.store.Put(ctx, key, "text/csv", RenderFactsCSV(facts)); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// TableDestination writes each day's facts into a table, typically a
+// postgres_fdw foreign table in the warehouse
+type TableDestination struct {
+	exportRepo *repository.ExportRepository
+	table      string
+}
+
+func NewTableDestination(exportRepo *repository.ExportRepository, table string) *TableDestination {
+	return &TableDestination{exportRepo: exportRepo, table: table}
+}
+
+// WriteFacts replaces the day's rows in the table
+func (d *TableDestination) WriteFacts(ctx context.Context, date time.Time, facts []models.PersonDayFact) error {
+	return d.exportRepo.ReplaceDayFacts(ctx, d.table, date, facts)
+}
@@ -2,18 +2,57 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
 )
 
+// ErrDashboardNotEnabled is returned for groups not shown on public dashboards
+var ErrDashboardNotEnabled = errors.New("group dashboard not enabled")
+
+// ErrNoReports is returned for roll-ups of someone nobody reports to
+var ErrNoReports = errors.New("no one reports to this person")
+
+// ErrHeatmapPrivate is returned for a private heatmap the viewer may not see
+var ErrHeatmapPrivate = errors.New("heatmap is private")
+
+// ErrInvalidRange is returned for heatmap date ranges that cannot be read,
+// end before they start, or span more than MaxHeatmapDays
+var ErrInvalidRange = errors.New("invalid date range")
+
+// MaxHeatmapDays is the most days a heatmap with a chosen range covers, a
+// full leap year
+const MaxHeatmapDays = 366
+
 type HeatmapService struct {
 	entityRepo   *repository.EntityRepository
 	capacityRepo *repository.CapacityRepository
 	loadRepo     *repository.LoadRepository
 	groupRepo    *repository.GroupRepository
+	snapshotRepo *repository.SnapshotRepository
+	scenarioRepo *repository.ScenarioRepository
+	holidayRepo  *repository.HolidayRepository
+	events       *EventLog
+
+	// weekStart begins heatmap weeks for viewers who have not chosen a day
+	weekStart time.Weekday
+	// admins may view every private heatmap, by lowercase email
+	admins map[string]bool
+	// noSnapshotSaves recomputes outdated heatmaps without saving them, on a
+	// read-only database
+	noSnapshotSaves bool
 }
 
 func NewHeatmapService(
@@ -21,54 +60,533 @@ func NewHeatmapService(
 	capacityRepo *repository.CapacityRepository,
 	loadRepo *repository.LoadRepository,
 	groupRepo *repository.GroupRepository,
+	snapshotRepo *repository.SnapshotRepository,
+	scenarioRepo *repository.ScenarioRepository,
+	holidayRepo *repository.HolidayRepository,
 ) *HeatmapService {
 	return &HeatmapService{
 		entityRepo:   entityRepo,
 		capacityRepo: capacityRepo,
 		loadRepo:     loadRepo,
 		groupRepo:    groupRepo,
+		snapshotRepo: snapshotRepo,
+		scenarioRepo: scenarioRepo,
+		holidayRepo:  holidayRepo,
+		weekStart:    time.Monday,
+	}
+}
+
+// SetWeekStart sets the weekday heatmap weeks start on for viewers who have
+// not chosen one, Monday by default as in ISO 8601
+func (s *HeatmapService) SetWeekStart(weekStart time.Weekday) {
+	s.weekStart = weekStart
+}
+
+// DisableSnapshotSaves stops saving recomputed heatmaps as snapshots, for a
+// server on a read-only database
+func (s *HeatmapService) DisableSnapshotSaves() {
+	s.noSnapshotSaves = true
+}
+
+// SetAdmins sets who may view every private heatmap
+func (s *HeatmapService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// RecordEvents records heatmap preference changes in the domain event log
+func (s *HeatmapService) RecordEvents(events *EventLog) {
+	s.events = events
+}
+
+// CheckVisible returns ErrHeatmapPrivate when an entity's heatmap is private
+// and the viewer, anonymous when viewerEmail is empty, may not see it
+func (s *HeatmapService) CheckVisible(ctx context.Context, viewerEmail, entityID string) error {
+	private, err := s.entityRepo.IsPrivate(ctx, entityID)
+	if err != nil {
+		return err
+	}
+	if !private {
+		return nil
+	}
+
+	ok, err := s.canViewPrivate(ctx, viewerEmail, entityID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrHeatmapPrivate
+	}
+	return nil
+}
+
+// canViewPrivate reports whether a viewer may see a private person's
+// heatmap: their own, one in a group they own, or any as an admin
+func (s *HeatmapService) canViewPrivate(ctx context.Context, viewerEmail, personEmail string) (bool, error) {
+	if viewerEmail == "" {
+		return false, nil
+	}
+	if strings.EqualFold(viewerEmail, personEmail) || s.admins[strings.ToLower(viewerEmail)] {
+		return true, nil
+	}
+	return s.groupRepo.IsApprover(ctx, viewerEmail, personEmail)
+}
+
+// hiddenFrom returns which of the given private entities a viewer may not see
+func (s *HeatmapService) hiddenFrom(ctx context.Context, viewerEmail string, private []string) (map[string]bool, error) {
+	hidden := make(map[string]bool)
+	for _, id := range private {
+		ok, err := s.canViewPrivate(ctx, viewerEmail, id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			hidden[id] = true
+		}
+	}
+	return hidden, nil
+}
+
+// VisibleEntities drops the private entities a viewer may not see, for
+// selectors and listings
+func (s *HeatmapService) VisibleEntities(ctx context.Context, viewerEmail string, entities []models.Entity) ([]models.Entity, error) {
+	var private []string
+	for _, e := range entities {
+		if e.Private {
+			private = append(private, e.ID)
+		}
+	}
+	if len(private) == 0 {
+		return entities, nil
+	}
+
+	hidden, err := s.hiddenFrom(ctx, viewerEmail, private)
+	if err != nil {
+		return nil, err
+	}
+	return withoutEntities(entities, hidden), nil
+}
+
+// HidePrivateAssignees removes the assignments of private persons a viewer
+// may not see from day details, and loads left with none. Day totals are
+// unchanged, as on the heatmap itself.
+func (s *HeatmapService) HidePrivateAssignees(ctx context.Context, viewerEmail string, loads []models.LoadWithAssignments) ([]models.LoadWithAssignments, error) {
+	var assignees []string
+	for _, l := range loads {
+		for _, a := range l.Assignments {
+			assignees = append(assignees, a.PersonEmail)
+		}
+	}
+	if len(assignees) == 0 {
+		return loads, nil
+	}
+
+	private, err := s.entityRepo.ListPrivate(ctx, assignees)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenFrom(ctx, viewerEmail, private)
+	if err != nil {
+		return nil, err
+	}
+	return withoutAssignees(loads, hidden), nil
+}
+
+// HidePrivateSummaries drops the utilization summaries of private persons a
+// viewer may not see. Group summaries still count them, as group heatmaps do.
+func (s *HeatmapService) HidePrivateSummaries(ctx context.Context, viewerEmail string, summaries []models.UtilizationSummary) ([]models.UtilizationSummary, error) {
+	if len(summaries) == 0 {
+		return summaries, nil
+	}
+	ids := make([]string, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.EntityID
+	}
+
+	private, err := s.entityRepo.ListPrivate(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenFrom(ctx, viewerEmail, private)
+	if err != nil {
+		return nil, err
+	}
+	if len(hidden) == 0 {
+		return summaries, nil
+	}
+	visible := make([]models.UtilizationSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !hidden[summary.EntityID] {
+			visible = append(visible, summary)
+		}
+	}
+	return visible, nil
+}
+
+// HideConfidentialLoads removes the confidential loads of groups a viewer is
+// not a member of from day details and calendar feeds. Admins see them all.
+// Day totals are unchanged, as for private persons.
+func (s *HeatmapService) HideConfidentialLoads(ctx context.Context, viewerEmail string, loads []models.LoadWithAssignments) ([]models.LoadWithAssignments, error) {
+	member := make(map[string]bool)
+	visible := make([]models.LoadWithAssignments, 0, len(loads))
+	for _, l := range loads {
+		group := l.Load.ConfidentialGroup
+		if group == nil {
+			visible = append(visible, l)
+			continue
+		}
+
+		ok, seen := member[*group]
+		if !seen {
+			var err error
+			if ok, err = s.canViewConfidential(ctx, viewerEmail, *group); err != nil {
+				return nil, err
+			}
+			member[*group] = ok
+		}
+		if ok {
+			visible = append(visible, l)
+		}
+	}
+	return visible, nil
+}
+
+// canViewConfidential reports whether a viewer may see a group's
+// confidential loads: as a member of it, or an admin
+func (s *HeatmapService) canViewConfidential(ctx context.Context, viewerEmail, groupID string) (bool, error) {
+	if viewerEmail == "" {
+		return false, nil
+	}
+	if s.admins[strings.ToLower(viewerEmail)] {
+		return true, nil
+	}
+	return s.groupRepo.IsMember(ctx, groupID, strings.ToLower(viewerEmail))
+}
+
+// withoutEntities returns entities without the hidden ones
+func withoutEntities(entities []models.Entity, hidden map[string]bool) []models.Entity {
+	if len(hidden) == 0 {
+		return entities
+	}
+	visible := make([]models.Entity, 0, len(entities))
+	for _, e := range entities {
+		if !hidden[e.ID] {
+			visible = append(visible, e)
+		}
+	}
+	return visible
+}
+
+// withoutAssignees returns loads without hidden persons' assignments,
+// dropping loads that only they were assigned to
+func withoutAssignees(loads []models.LoadWithAssignments, hidden map[string]bool) []models.LoadWithAssignments {
+	if len(hidden) == 0 {
+		return loads
+	}
+	visible := make([]models.LoadWithAssignments, 0, len(loads))
+	for _, l := range loads {
+		var assignments []models.LoadAssignment
+		for _, a := range l.Assignments {
+			if !hidden[a.PersonEmail] {
+				assignments = append(assignments, a)
+			}
+		}
+		if len(assignments) == 0 {
+			continue
+		}
+		l.Assignments = assignments
+		visible = append(visible, l)
+	}
+	return visible
+}
+
+// WeekStart returns the weekday heatmap weeks start on for a viewer: their
+// own choice, or the server's for anonymous viewers and everyone else
+func (s *HeatmapService) WeekStart(ctx context.Context, viewerEmail string) (time.Weekday, error) {
+	if viewerEmail == "" {
+		return s.weekStart, nil
+	}
+
+	weekStart, ok, err := s.entityRepo.GetWeekStart(ctx, viewerEmail)
+	if errors.Is(err, repository.ErrEntityNotFound) || (err == nil && !ok) {
+		return s.weekStart, nil
+	}
+	if err != nil {
+		return 0, err
 	}
+	return weekStart, nil
 }
 
-// GetHeatmapData returns heatmap data for an entity spanning 1 month previous and 6 months ahead from today
-func (s *HeatmapService) GetHeatmapData(ctx context.Context, entityID string, days int) (*models.HeatmapData, error) {
+// GetPreferences returns a person's heatmap preferences
+func (s *HeatmapService) GetPreferences(ctx context.Context, email string) (*models.HeatmapPreferences, error) {
+	weekStart, ok, err := s.entityRepo.GetWeekStart(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		weekStart = s.weekStart
+	}
+	private, err := s.entityRepo.IsPrivate(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	hiddenSources, err := s.entityRepo.GetHiddenSources(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return &models.HeatmapPreferences{
+		WeekStart:     strings.ToLower(weekStart.String()),
+		Custom:        ok,
+		Private:       private,
+		HiddenSources: hiddenSources,
+	}, nil
+}
+
+// UpdatePreferences sets a person's heatmap preferences and returns them
+func (s *HeatmapService) UpdatePreferences(ctx context.Context, email string, req *models.UpdateHeatmapPreferencesRequest) (*models.HeatmapPreferences, error) {
+	var weekStart *time.Weekday
+	if req.WeekStart != nil {
+		day, err := parseWeekday(*req.WeekStart)
+		if err != nil {
+			return nil, err
+		}
+		weekStart = &day
+	}
+
+	if err := s.entityRepo.SetWeekStart(ctx, email, weekStart); err != nil {
+		return nil, err
+	}
+	if req.Private != nil {
+		if err := s.entityRepo.SetPrivate(ctx, email, *req.Private); err != nil {
+			return nil, err
+		}
+	}
+	if req.HiddenSources != nil {
+		if err := s.entityRepo.SetHiddenSources(ctx, email, cleanSources(*req.HiddenSources)); err != nil {
+			return nil, err
+		}
+	}
+	s.events.Record(ctx, models.EventPreferencesUpdated, email, []string{email}, req)
+	return s.GetPreferences(ctx, email)
+}
+
+// cleanSources trims sources and drops blank and repeated ones, sorted
+func cleanSources(sources []string) []string {
+	seen := make(map[string]bool, len(sources))
+	cleaned := make([]string, 0, len(sources))
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" || seen[source] {
+			continue
+		}
+		seen[source] = true
+		cleaned = append(cleaned, source)
+	}
+	sort.Strings(cleaned)
+	return cleaned
+}
+
+// HiddenSources returns the load sources a viewer leaves out of the heatmaps
+// they view, none for anonymous viewers and everyone but persons
+func (s *HeatmapService) HiddenSources(ctx context.Context, viewerEmail string) ([]string, error) {
+	if viewerEmail == "" {
+		return nil, nil
+	}
+
+	sources, err := s.entityRepo.GetHiddenSources(ctx, viewerEmail)
+	if errors.Is(err, repository.ErrEntityNotFound) {
+		return nil, nil
+	}
+	return sources, err
+}
+
+// GetHeatmapData returns heatmap data for an entity from startDate to
+// endDate, usually HeatmapWindow or a range from ParseHeatmapRange, as seen
+// by the viewer, anonymous when viewerEmail is empty.
+// Over HeatmapWindow it serves the entity's snapshot while nothing the
+// heatmap depends on has changed, and otherwise recomputes it and stores a
+// new snapshot. Other ranges, viewers hiding load sources, heatmaps filtered
+// to a tag, and group heatmaps leaving out the excluded members (as returned
+// by ParseExcludedMembers) are computed alone.
+func (s *HeatmapService) GetHeatmapData(ctx context.Context, viewerEmail, entityID, tag string, excluded []string, startDate, endDate time.Time) (*models.HeatmapData, error) {
 	// Get the entity
 	entity, err := s.entityRepo.GetByID(ctx, entityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get entity: %w", err)
 	}
 
-	// Calculate date range: 1 month previous and 6 months ahead
-	// Use UTC for consistent date handling
+	hiddenSources, err := s.HiddenSources(ctx, viewerEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if entity.Type != models.EntityTypeGroup {
+		excluded = nil
+	}
+
+	var heatmapDays []models.HeatmapDay
 	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	startDate := today.AddDate(0, -1, 0) // 1 month before today
-	endDate := today.AddDate(0, 6, 0)    // 6 months after today
+	windowStart, windowEnd := HeatmapWindow(now)
+	if len(hiddenSources) == 0 && tag == "" && len(excluded) == 0 &&
+		startDate.Equal(windowStart) && endDate.Equal(windowEnd) {
+		heatmapDays, _, err = s.heatmapDays(ctx, entity, now)
+	} else {
+		heatmapDays, err = s.computeHeatmapDays(ctx, entity, startDate, endDate, hiddenSources, tag, excluded)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.HeatmapData{
+		Entity: *entity,
+		Days:   heatmapDays,
+	}, nil
+}
+
+// GetScenarioHeatmapData returns an entity's heatmap as it would look with a
+// scenario's loads and capacities applied over the real ones. The real
+// heatmap, its snapshot, and its version are left untouched.
+func (s *HeatmapService) GetScenarioHeatmapData(ctx context.Context, scenarioID int, entityID string) (*models.HeatmapData, error) {
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	now := time.Now()
+	realDays, _, err := s.heatmapDays(ctx, entity, now)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate := HeatmapWindow(now)
+	loads, err := s.scenarioRepo.GetLoadOverlay(ctx, scenarioID, entity.ID, entity.Type, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	capacities, err := s.scenarioRepo.GetCapacityOverlay(ctx, scenarioID, entity.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.HeatmapData{
+		Entity: *entity,
+		Days:   overlayScenario(realDays, loads, capacities),
+	}, nil
+}
+
+// overlayScenario returns a copy of days with a scenario's capacities in
+// place of the real ones and its loads added on top, recolored
+func overlayScenario(days []models.HeatmapDay, loads, capacities map[time.Time]float64) []models.HeatmapDay {
+	overlaid := make([]models.HeatmapDay, len(days))
+	for i, day := range days {
+		key := time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if capacity, ok := capacities[key]; ok {
+			day.Capacity = capacity
+		}
+		day.Load += loads[key]
+		day.Color = getHeatmapColor(day.Load, day.Capacity)
+		overlaid[i] = day
+	}
+	return overlaid
+}
+
+// heatmapDays returns an entity's heatmap days for the window at now, from
+// its snapshot when that is current, and reports whether it recomputed them.
+func (s *HeatmapService) heatmapDays(ctx context.Context, entity *models.Entity, now time.Time) ([]models.HeatmapDay, bool, error) {
+	startDate, endDate := HeatmapWindow(now)
+
+	// Read the version before the data, so a write that lands in between
+	// leaves the snapshot looking outdated rather than current
+	version, err := s.loadRepo.GetHeatmapVersion(ctx, entity.ID, startDate, endDate)
+	if err != nil {
+		return nil, false, err
+	}
 
+	snapshot, err := s.snapshotRepo.Get(ctx, entity.ID)
+	if err != nil {
+		log.Printf("Heatmap snapshot for %s unavailable, recomputing: %v", entity.ID, err)
+	} else if snapshot != nil && snapshot.WindowStart.Equal(startDate) &&
+		snapshot.Version.LastModified.Equal(version.LastModified) && snapshot.Version.Rows == version.Rows {
+		return recolor(snapshot.Days), false, nil
+	}
+
+	heatmapDays, err := s.computeHeatmapDays(ctx, entity, startDate, endDate, nil, "", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.noSnapshotSaves {
+		return heatmapDays, true, nil
+	}
+
+	// A failed save only costs the next reader a recompute
+	err = s.snapshotRepo.Save(ctx, &models.HeatmapSnapshot{
+		EntityID:    entity.ID,
+		WindowStart: startDate,
+		WindowEnd:   endDate,
+		Version:     *version,
+		Days:        heatmapDays,
+	})
+	if err != nil {
+		log.Printf("Failed to save heatmap snapshot for %s: %v", entity.ID, err)
+	}
+
+	return heatmapDays, true, nil
+}
+
+// computeHeatmapDays builds an entity's heatmap days from its capacities,
+// holidays and loads, leaving out loads from the hidden sources, given a tag,
+// loads without it, and for groups, loads of the excluded members, and their
+// capacities when the group sums its members'
+func (s *HeatmapService) computeHeatmapDays(ctx context.Context, entity *models.Entity, startDate, endDate time.Time, hiddenSources []string, tag string, excluded []string) ([]models.HeatmapDay, error) {
 	// Get capacities for the date range
-	capacities, err := s.capacityRepo.GetCapacitiesForRange(ctx, entityID, startDate, endDate)
+	var capacities map[time.Time]float64
+	var err error
+	if entity.Type == models.EntityTypeGroup && entity.CapacityMode == models.CapacityModeMembers {
+		capacities, err = s.capacityRepo.GetMemberCapacitiesForRange(ctx, entity.ID, startDate, endDate, excluded)
+	} else {
+		capacities, err = s.capacityRepo.GetCapacitiesForRange(ctx, entity.ID, startDate, endDate)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get capacities: %w", err)
 	}
 
+	var holidays map[time.Time]string
+	if entity.Region != nil {
+		holidays, err = s.holidayRepo.GetForEntity(ctx, entity.ID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Get loads based on entity type
 	var loads map[time.Time]float64
 	if entity.Type == models.EntityTypePerson {
-		loads, err = s.loadRepo.GetPersonLoadForDateRange(ctx, entityID, startDate, endDate)
+		loads, err = s.loadRepo.GetPersonLoadForDateRange(ctx, entity.ID, startDate, endDate, hiddenSources, tag)
 	} else {
-		loads, err = s.loadRepo.GetGroupLoadForDateRange(ctx, entityID, startDate, endDate)
+		loads, err = s.loadRepo.GetGroupLoadForDateRange(ctx, entity.ID, startDate, endDate, hiddenSources, tag, excluded)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get loads: %w", err)
 	}
 
-	// Build heatmap days
+	return buildHeatmapDays(startDate, endDate, loads, capacities, holidays), nil
+}
+
+// buildHeatmapDays colors each day from startDate to endDate by its load and
+// capacity. Holidays, by date, have no capacity.
+func buildHeatmapDays(startDate, endDate time.Time, loads, capacities map[time.Time]float64, holidays map[time.Time]string) []models.HeatmapDay {
 	heatmapDays := make([]models.HeatmapDay, 0, 300)
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
 		// Use UTC date for lookup
 		lookupDate := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
 		load := loads[lookupDate]
 		capacity := capacities[lookupDate]
+		holiday, ok := holidays[lookupDate]
+		if ok {
+			capacity = 0
+		}
 		color := getHeatmapColor(load, capacity)
 
 		heatmapDays = append(heatmapDays, models.HeatmapDay{
@@ -76,17 +594,319 @@ func (s *HeatmapService) GetHeatmapData(ctx context.Context, entityID string, da
 			Load:     load,
 			Capacity: capacity,
 			Color:    color,
+			Holiday:  holiday,
 		})
 	}
 
+	return heatmapDays
+}
+
+// GetRollupHeatmapData returns the combined heatmap of everyone reporting to
+// a manager, directly or transitively, with their summed loads and
+// capacities
+func (s *HeatmapService) GetRollupHeatmapData(ctx context.Context, managerEmail string) (*models.HeatmapData, error) {
+	manager, err := s.entityRepo.GetByID(ctx, managerEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	reports, err := s.entityRepo.ListReports(ctx, manager.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrNoReports
+	}
+
+	startDate, endDate := HeatmapWindow(time.Now())
+	capacities, err := s.capacityRepo.GetTotalCapacitiesForRange(ctx, reports, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacities: %w", err)
+	}
+	loads, err := s.loadRepo.GetPeopleLoadForDateRange(ctx, reports, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loads: %w", err)
+	}
+
 	return &models.HeatmapData{
-		Entity: *entity,
-		Days:   heatmapDays,
+		Entity: *manager,
+		Days:   buildHeatmapDays(startDate, endDate, loads, capacities, nil),
 	}, nil
 }
 
-// GetDayDetails returns detailed load information for a specific day
-func (s *HeatmapService) GetDayDetails(ctx context.Context, entityID string, date time.Time) ([]models.LoadWithAssignments, float64, float64, error) {
+// RefreshSnapshots brings every entity's heatmap snapshot up to date and
+// returns how many it recomputed. Run after midnight, when the window moves
+// and every snapshot goes out of date, it saves the first viewer of each
+// heatmap the recompute.
+func (s *HeatmapService) RefreshSnapshots(ctx context.Context) (int, error) {
+	entities, err := s.entityRepo.ListAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	now := time.Now()
+	refreshed, failed := 0, 0
+	for i := range entities {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+
+		_, recomputed, err := s.heatmapDays(ctx, &entities[i], now)
+		if err != nil {
+			log.Printf("Failed to refresh heatmap snapshot for %s: %v", entities[i].ID, err)
+			failed++
+			continue
+		}
+		if recomputed {
+			refreshed++
+		}
+	}
+
+	if failed > 0 {
+		return refreshed, fmt.Errorf("failed to refresh %d of %d heatmap snapshots", failed, len(entities))
+	}
+	return refreshed, nil
+}
+
+// RebuildSnapshots discards the heatmap snapshots of the given entities and
+// recomputes them from the source tables, whatever version they were saved
+// at. It returns how many it rebuilt; entities that no longer exist are
+// skipped.
+func (s *HeatmapService) RebuildSnapshots(ctx context.Context, entityIDs []string) (int, error) {
+	if err := s.snapshotRepo.Delete(ctx, entityIDs); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	rebuilt, failed := 0, 0
+	for _, id := range entityIDs {
+		if ctx.Err() != nil {
+			return rebuilt, ctx.Err()
+		}
+
+		entity, err := s.entityRepo.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			continue
+		}
+		if err == nil {
+			_, _, err = s.heatmapDays(ctx, entity, now)
+		}
+		if err != nil {
+			log.Printf("Failed to rebuild heatmap snapshot for %s: %v", id, err)
+			failed++
+			continue
+		}
+		rebuilt++
+	}
+
+	if failed > 0 {
+		return rebuilt, fmt.Errorf("failed to rebuild %d of %d heatmap snapshots", failed, len(entityIDs))
+	}
+	return rebuilt, nil
+}
+
+// BackfillSnapshots returns a job rebuilding the heatmap snapshots of the
+// given entities one at a time, or of every entity when none are given
+func (s *HeatmapService) BackfillSnapshots(ctx context.Context, entityIDs []string) (*BulkJob, error) {
+	if len(entityIDs) == 0 {
+		entities, err := s.entityRepo.ListAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entities {
+			entityIDs = append(entityIDs, e.ID)
+		}
+	}
+
+	return &BulkJob{
+		Kind:  models.JobKindSnapshotsBackfill,
+		Total: len(entityIDs),
+		Run: func(ctx context.Context, p *JobProgress) error {
+			for i, id := range entityIDs {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				rebuilt, err := s.RebuildSnapshots(ctx, []string{id})
+				if err == nil && rebuilt == 0 {
+					err = repository.ErrEntityNotFound
+				}
+				if err != nil {
+					p.Fail(i, id, err)
+					continue
+				}
+				p.Done()
+			}
+			return nil
+		},
+	}, nil
+}
+
+// RunSnapshotRefresh calls RefreshSnapshots every day at the given offset
+// from midnight UTC until ctx is cancelled.
+func (s *HeatmapService) RunSnapshotRefresh(ctx context.Context, at time.Duration) {
+	for {
+		timer := time.NewTimer(time.Until(nextSnapshotRefresh(time.Now(), at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		started := time.Now()
+		refreshed, err := s.RefreshSnapshots(ctx)
+		if err != nil {
+			log.Printf("Heatmap snapshot refresh: %v", err)
+		}
+		log.Printf("Refreshed %d heatmap snapshots in %s", refreshed, time.Since(started).Round(time.Millisecond))
+	}
+}
+
+// nextSnapshotRefresh returns the first time after now that is at the given
+// offset from midnight UTC.
+func nextSnapshotRefresh(now time.Time, at time.Duration) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// CheckDashboard returns ErrDashboardNotEnabled unless groupID is a group
+// whose anonymized heatmap may be shown on public dashboards
+func (s *HeatmapService) CheckDashboard(ctx context.Context, groupID string) error {
+	enabled, err := s.groupRepo.IsDashboardEnabled(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrDashboardNotEnabled
+	}
+	return nil
+}
+
+// HeatmapWindow returns the date range GetHeatmapData covers on the day of
+// now: 1 month previous and 6 months ahead, as UTC dates.
+func HeatmapWindow(now time.Time) (start, end time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, -1, 0), today.AddDate(0, 6, 0)
+}
+
+// ParseHeatmapRange returns the dates a heatmap covers at now given its
+// from, to and days query parameters. Without any, it is HeatmapWindow.
+// from and to are YYYY-MM-DD and default to the window's start and end;
+// days counts days from from, or from today, and cannot be combined with
+// to. Ranges ending before they start or spanning more than MaxHeatmapDays
+// fail with ErrInvalidRange.
+func ParseHeatmapRange(from, to, days string, now time.Time) (time.Time, time.Time, error) {
+	start, end := HeatmapWindow(now)
+	if from == "" && to == "" && days == "" {
+		return start, end, nil
+	}
+
+	if from != "" {
+		d, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidRange)
+		}
+		start = d
+	}
+	if to != "" {
+		d, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidRange)
+		}
+		end = d
+	}
+	if days != "" {
+		if to != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: days cannot be combined with to", ErrInvalidRange)
+		}
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 || n > MaxHeatmapDays {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidRange, MaxHeatmapDays)
+		}
+		if from == "" {
+			start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		end = start.AddDate(0, 0, n-1)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to is before from", ErrInvalidRange)
+	}
+	if end.Sub(start) >= MaxHeatmapDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: at most %d days", ErrInvalidRange, MaxHeatmapDays)
+	}
+	return start, end, nil
+}
+
+// GetHeatmapVersion returns validators for the heatmap GetHeatmapData would
+// build from startDate to endDate at now: a weak ETag and the time it last
+// changed. Both come from row timestamps, so polling clients can be answered
+// 304 without computing it.
+func (s *HeatmapService) GetHeatmapVersion(ctx context.Context, entityID string, startDate, endDate, now time.Time) (string, time.Time, error) {
+	v, err := s.loadRepo.GetHeatmapVersion(ctx, entityID, startDate, endDate)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// The heatmap also changes at midnight, when the window and "today" move
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastModified := v.LastModified
+	if lastModified.Before(today) {
+		lastModified = today
+	}
+
+	// New color thresholds recolor every heatmap
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d|%v", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"),
+		v.LastModified.UnixNano(), v.Rows, *currentColorThresholds())))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`, lastModified, nil
+}
+
+// HiddenSourcesVersion returns the ETag of a heatmap with the given version
+// as seen by a viewer hiding the given load sources, so their heatmaps are
+// tagged apart from everyone else's
+func HiddenSourcesVersion(etag string, hiddenSources []string) string {
+	if len(hiddenSources) == 0 {
+		return etag
+	}
+	sum := sha256.Sum256([]byte(etag + "|" + strings.Join(hiddenSources, "|")))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ParseExcludedMembers returns the members listed in an exclude query
+// parameter, comma separated, as sorted lowercase emails without repeats
+func ParseExcludedMembers(query string) []string {
+	var excluded []string
+	for _, email := range strings.Split(query, ",") {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email != "" {
+			excluded = append(excluded, email)
+		}
+	}
+	sort.Strings(excluded)
+	return slices.Compact(excluded)
+}
+
+// ExcludedMembersVersion returns the ETag of a group heatmap with the given
+// version leaving out the excluded members, so it is tagged apart from the
+// whole group's
+func ExcludedMembersVersion(etag string, excluded []string) string {
+	if len(excluded) == 0 {
+		return etag
+	}
+	sum := sha256.Sum256([]byte(etag + "|exclude:" + strings.Join(excluded, ",")))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// GetDayDetails returns detailed load information for a specific day, only
+// of the loads tagged with tag when one is given. For groups, as on their
+// heatmap, assignments of the members the group excludes and of those in
+// excluded (as returned by ParseExcludedMembers) are left out, and so is
+// their capacity when the group sums its members'.
+func (s *HeatmapService) GetDayDetails(ctx context.Context, entityID string, date time.Time, tag string, excluded []string) ([]models.LoadWithAssignments, float64, float64, error) {
 	// Get entity
 	entity, err := s.entityRepo.GetByID(ctx, entityID)
 	if err != nil {
@@ -98,6 +918,14 @@ func (s *HeatmapService) GetDayDetails(ctx context.Context, entityID string, dat
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to get loads: %w", err)
 	}
+	loads = withTag(loads, tag)
+	if entity.Type == models.EntityTypeGroup {
+		left, err := s.leftOutMembers(ctx, entityID, excluded)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		loads = withoutAssignees(loads, left)
+	}
 
 	// Calculate total load
 	var totalLoad float64
@@ -108,7 +936,14 @@ func (s *HeatmapService) GetDayDetails(ctx context.Context, entityID string, dat
 	}
 
 	// Get capacity
-	capacity, err := s.capacityRepo.GetEffectiveCapacity(ctx, entityID, date)
+	var capacity float64
+	if entity.Type == models.EntityTypeGroup && entity.CapacityMode == models.CapacityModeMembers {
+		var capacities map[time.Time]float64
+		capacities, err = s.capacityRepo.GetMemberCapacitiesForRange(ctx, entityID, date, date, excluded)
+		capacity = capacities[time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)]
+	} else {
+		capacity, err = s.capacityRepo.GetEffectiveCapacity(ctx, entityID, date)
+	}
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to get capacity: %w", err)
 	}
@@ -116,6 +951,241 @@ func (s *HeatmapService) GetDayDetails(ctx context.Context, entityID string, dat
 	return loads, totalLoad, capacity, nil
 }
 
+// GroupDayByMember splits a group's day details, as returned by
+// GetDayDetails and already hidden and ordered for the viewer, into one
+// section per member the viewer may see and the heatmap does not leave out,
+// given the same excluded, with each member's subtotal and capacity on date.
+// Members are listed fullest first, so the overloaded ones lead. Persons have
+// no sections.
+func (s *HeatmapService) GroupDayByMember(ctx context.Context, viewerEmail, entityID string, date time.Time, loads []models.LoadWithAssignments, excluded []string) ([]models.MemberDayLoad, error) {
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	if entity.Type != models.EntityTypeGroup {
+		return nil, nil
+	}
+
+	members, err := s.groupRepo.GetMembers(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	private, err := s.entityRepo.ListPrivate(ctx, members)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenFrom(ctx, viewerEmail, private)
+	if err != nil {
+		return nil, err
+	}
+	left, err := s.leftOutMembers(ctx, entityID, excluded)
+	if err != nil {
+		return nil, err
+	}
+	for email := range left {
+		hidden[email] = true
+	}
+
+	capacities, err := s.memberCapacities(ctx, members, hidden, date)
+	if err != nil {
+		return nil, err
+	}
+	return loadsByMember(capacities, loads), nil
+}
+
+// leftOutMembers returns the members a group's heatmap leaves out: those the
+// group always excludes and those in excluded, which are lowercase emails
+func (s *HeatmapService) leftOutMembers(ctx context.Context, groupID string, excluded []string) (map[string]bool, error) {
+	always, err := s.groupRepo.GetHeatmapExcluded(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	left := make(map[string]bool, len(always)+len(excluded))
+	for _, email := range always {
+		left[email] = true
+	}
+	if len(excluded) == 0 {
+		return left, nil
+	}
+
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range members {
+		if slices.Contains(excluded, strings.ToLower(email)) {
+			left[email] = true
+		}
+	}
+	return left, nil
+}
+
+// memberCapacities returns each member's effective capacity on date, as on
+// their own heatmap, leaving out the hidden members
+func (s *HeatmapService) memberCapacities(ctx context.Context, members []string, hidden map[string]bool, date time.Time) (map[string]float64, error) {
+	capacities := make(map[string]float64, len(members))
+	for _, email := range members {
+		if hidden[email] {
+			continue
+		}
+		capacity, err := s.capacityRepo.GetEffectiveCapacity(ctx, email, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get capacity: %w", err)
+		}
+		capacities[email] = capacity
+	}
+	return capacities, nil
+}
+
+// GetGroupDayBreakdown returns each active member of a group the viewer may
+// see with their own load against their own capacity on date, fullest
+// first, so managers can see who exactly is overloaded. Persons get
+// repository.ErrNotAGroup.
+func (s *HeatmapService) GetGroupDayBreakdown(ctx context.Context, viewerEmail, groupID string, date time.Time) ([]models.MemberDayBreakdown, error) {
+	entity, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	if entity.Type != models.EntityTypeGroup {
+		return nil, repository.ErrNotAGroup
+	}
+
+	members, err := s.loadRepo.GetGroupMemberLoads(ctx, groupID, date)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]string, len(members))
+	for i, m := range members {
+		emails[i] = m.Email
+	}
+	private, err := s.entityRepo.ListPrivate(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenFrom(ctx, viewerEmail, private)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capacities come from the same path as each member's heatmap and day
+	// details, not the load query's
+	capacities, err := s.memberCapacities(ctx, emails, hidden, date)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		members[i].Capacity = capacities[members[i].Email]
+	}
+
+	return memberBreakdown(members, hidden), nil
+}
+
+// memberBreakdown colors each member's day by their load and capacity,
+// fullest first, leaving out the hidden members
+func memberBreakdown(members []models.RebalanceMember, hidden map[string]bool) []models.MemberDayBreakdown {
+	breakdown := make([]models.MemberDayBreakdown, 0, len(members))
+	for _, m := range members {
+		if hidden[m.Email] {
+			continue
+		}
+		breakdown = append(breakdown, models.MemberDayBreakdown{
+			PersonEmail: m.Email,
+			Title:       m.Title,
+			Load:        m.LoadBefore,
+			Capacity:    m.Capacity,
+			Color:       getHeatmapColor(m.LoadBefore, m.Capacity),
+			Overloaded:  m.LoadBefore > m.Capacity,
+		})
+	}
+
+	sort.SliceStable(breakdown, func(i, j int) bool {
+		ri, rj := utilization(breakdown[i].Load, breakdown[i].Capacity), utilization(breakdown[j].Load, breakdown[j].Capacity)
+		if ri != rj {
+			return ri > rj
+		}
+		return breakdown[i].PersonEmail < breakdown[j].PersonEmail
+	})
+	return breakdown
+}
+
+// loadsByMember groups loads under each member in capacities, by member
+// capacity. Assignments of anyone else are left out.
+func loadsByMember(capacities map[string]float64, loads []models.LoadWithAssignments) []models.MemberDayLoad {
+	byEmail := make(map[string]*models.MemberDayLoad, len(capacities))
+	result := make([]models.MemberDayLoad, 0, len(capacities))
+	for email, capacity := range capacities {
+		result = append(result, models.MemberDayLoad{
+			PersonEmail: email,
+			Capacity:    capacity,
+			Loads:       []models.LoadWithAssignments{},
+		})
+	}
+	for i := range result {
+		byEmail[result[i].PersonEmail] = &result[i]
+	}
+
+	for _, l := range loads {
+		for _, a := range l.Assignments {
+			member, ok := byEmail[a.PersonEmail]
+			if !ok {
+				continue
+			}
+			member.Load += a.Weight
+			member.Loads = append(member.Loads, models.LoadWithAssignments{
+				Load:        l.Load,
+				Assignments: []models.LoadAssignment{a},
+				Pinned:      l.Pinned,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ri, rj := utilization(result[i].Load, result[i].Capacity), utilization(result[j].Load, result[j].Capacity)
+		if ri != rj {
+			return ri > rj
+		}
+		return result[i].PersonEmail < result[j].PersonEmail
+	})
+	return result
+}
+
+// utilization is load as a share of capacity, infinite for any load on no
+// capacity
+func utilization(load, capacity float64) float64 {
+	if capacity == 0 {
+		if load > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return load / capacity
+}
+
+// defaultColorThresholds are the load-to-capacity ratios above which a day
+// turns lime, amber, orange, red and blood red, until admins set others
+var defaultColorThresholds = [5]float64{0.2, 0.4, 0.6, 0.8, 1.0}
+
+// colorThresholds are the ratios getHeatmapColor steps at, from the settings
+var colorThresholds atomic.Pointer[[5]float64]
+
+// setColorThresholds makes getHeatmapColor step at thresholds, five
+// ascending ratios; others leave it at the defaults
+func setColorThresholds(thresholds []float64) {
+	t := defaultColorThresholds
+	if len(thresholds) == len(t) {
+		copy(t[:], thresholds)
+	}
+	colorThresholds.Store(&t)
+}
+
+// currentColorThresholds returns the ratios getHeatmapColor steps at
+func currentColorThresholds() *[5]float64 {
+	if t := colorThresholds.Load(); t != nil {
+		return t
+	}
+	return &defaultColorThresholds
+}
+
 // getHeatmapColor returns the appropriate color based on load/capacity ratio
 func getHeatmapColor(load, capacity float64) string {
 	if capacity == 0 {
@@ -125,17 +1195,18 @@ func getHeatmapColor(load, capacity float64) string {
 		return "#e5e7eb" // Gray for zero capacity, zero load
 	}
 
+	t := currentColorThresholds()
 	ratio := load / capacity
 	switch {
-	case ratio > 1.0:
+	case ratio > t[4]:
 		return "#8B0000" // Blood red - overloaded
-	case ratio > 0.8:
+	case ratio > t[3]:
 		return "#dc2626" // Red - near capacity
-	case ratio > 0.6:
+	case ratio > t[2]:
 		return "#f97316" // Orange
-	case ratio > 0.4:
+	case ratio > t[1]:
 		return "#fbbf24" // Yellow/Amber
-	case ratio > 0.2:
+	case ratio > t[0]:
 		return "#a3e635" // Lime green
 	case ratio > 0:
 		return "#22c55e" // Green - low load
@@ -144,6 +1215,17 @@ func getHeatmapColor(load, capacity float64) string {
 	}
 }
 
+// recolor returns a copy of days colored with the current thresholds, for
+// snapshots saved before they changed
+func recolor(days []models.HeatmapDay) []models.HeatmapDay {
+	recolored := make([]models.HeatmapDay, len(days))
+	for i, day := range days {
+		day.Color = getHeatmapColor(day.Load, day.Capacity)
+		recolored[i] = day
+	}
+	return recolored
+}
+
 // GetHeatmapColorForValues is exported for use in templates
 func GetHeatmapColorForValues(load, capacity float64) string {
 	return getHeatmapColor(load, capacity)
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSnapshotRefresh(t *testing.T) {
+	at := 5 * time.Minute
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before today's run", time.Date(2025, 3, 10, 0, 1, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 5, 0, 0, time.UTC)},
+		{"exactly at the run", time.Date(2025, 3, 10, 0, 5, 0, 0, time.UTC), time.Date(2025, 3, 11, 0, 5, 0, 0, time.UTC)},
+		{"after today's run", time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC), time.Date(2025, 3, 11, 0, 5, 0, 0, time.UTC)},
+		{"across a month", time.Date(2025, 3, 31, 23, 59, 0, 0, time.UTC), time.Date(2025, 4, 1, 0, 5, 0, 0, time.UTC)},
+		// 06:00 WIB on the 11th is 23:00 UTC on the 10th
+		{"local time zone", time.Date(2025, 3, 11, 6, 0, 0, 0, jakarta), time.Date(2025, 3, 11, 0, 5, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextSnapshotRefresh(tt.now, at))
+		})
+	}
+}
+
+func TestOverlayScenario(t *testing.T) {
+	day1 := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day1.AddDate(0, 0, 2)
+	days := []models.HeatmapDay{
+		{Date: day1, Load: 1, Capacity: 5, Color: "#22c55e"},
+		{Date: day2, Load: 2, Capacity: 5, Color: "#a3e635"},
+		{Date: day3, Load: 0, Capacity: 5, Color: "#e5e7eb"},
+	}
+
+	got := overlayScenario(days,
+		map[time.Time]float64{day1: 3, day3: 1},
+		map[time.Time]float64{day2: 0, day3: 10},
+	)
+
+	assert.Equal(t, []models.HeatmapDay{
+		{Date: day1, Load: 4, Capacity: 5, Color: "#f97316"},
+		// Zero capacity with any load is overloaded
+		{Date: day2, Load: 2, Capacity: 0, Color: "#8B0000"},
+		{Date: day3, Load: 1, Capacity: 10, Color: "#22c55e"},
+	}, got)
+	assert.Equal(t, 1.0, days[0].Load, "the real days should be left untouched")
+}
+
+func TestBuildHeatmapDays(t *testing.T) {
+	day1 := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	day3 := day2.AddDate(0, 0, 1)
+
+	got := buildHeatmapDays(day1, day3,
+		map[time.Time]float64{day1: 1},
+		map[time.Time]float64{day1: 5, day2: 5, day3: 5},
+		map[time.Time]string{day3: "Nyepi"},
+	)
+
+	assert.Equal(t, []models.HeatmapDay{
+		{Date: day1, Load: 1, Capacity: 5, Color: "#22c55e"},
+		// Days without loads are still listed
+		{Date: day2, Load: 0, Capacity: 5, Color: "#e5e7eb"},
+		// Holidays have no capacity
+		{Date: day3, Load: 0, Capacity: 0, Color: "#e5e7eb", Holiday: "Nyepi"},
+	}, got)
+}
+
+func TestWithoutEntities(t *testing.T) {
+	entities := []models.Entity{
+		{ID: "team", Type: models.EntityTypeGroup},
+		{ID: "alice@example.com", Type: models.EntityTypePerson, Private: true},
+		{ID: "bob@example.com", Type: models.EntityTypePerson},
+	}
+
+	assert.Equal(t, entities, withoutEntities(entities, nil))
+	assert.Equal(t, []models.Entity{entities[0], entities[2]},
+		withoutEntities(entities, map[string]bool{"alice@example.com": true}))
+}
+
+func TestWithoutAssignees(t *testing.T) {
+	shared := models.LoadWithAssignments{
+		Load: models.Load{ID: 1},
+		Assignments: []models.LoadAssignment{
+			{LoadID: 1, PersonEmail: "alice@example.com", Weight: 1},
+			{LoadID: 1, PersonEmail: "bob@example.com", Weight: 2},
+		},
+	}
+	alone := models.LoadWithAssignments{
+		Load:        models.Load{ID: 2},
+		Assignments: []models.LoadAssignment{{LoadID: 2, PersonEmail: "alice@example.com", Weight: 1}},
+	}
+	loads := []models.LoadWithAssignments{shared, alone}
+
+	assert.Equal(t, loads, withoutAssignees(loads, nil))
+
+	got := withoutAssignees(loads, map[string]bool{"alice@example.com": true})
+	assert.Equal(t, []models.LoadWithAssignments{{
+		Load:        models.Load{ID: 1},
+		Assignments: []models.LoadAssignment{{LoadID: 1, PersonEmail: "bob@example.com", Weight: 2}},
+	}}, got, "loads only hidden persons were assigned to should be dropped")
+	assert.Len(t, loads[0].Assignments, 2, "the loads given should be left untouched")
+}
+
+func TestCleanSources(t *testing.T) {
+	assert.Equal(t, []string{"gcal", "jira"}, cleanSources([]string{" jira", "gcal", "", "jira ", "  "}))
+	assert.Equal(t, []string{}, cleanSources(nil), "an empty list shows every source again")
+}
+
+func TestHiddenSourcesVersion(t *testing.T) {
+	etag := `W/"0123456789abcdef"`
+	assert.Equal(t, etag, HiddenSourcesVersion(etag, nil))
+
+	gcal := HiddenSourcesVersion(etag, []string{"gcal"})
+	assert.NotEqual(t, etag, gcal)
+	assert.NotEqual(t, gcal, HiddenSourcesVersion(etag, []string{"gcal", "jira"}))
+	assert.Equal(t, gcal, HiddenSourcesVersion(etag, []string{"gcal"}))
+}
+
+func TestParseExcludedMembers(t *testing.T) {
+	assert.Empty(t, ParseExcludedMembers(""))
+	assert.Empty(t, ParseExcludedMembers(" , "))
+	assert.Equal(t, []string{"lead@example.com", "pm@example.com"},
+		ParseExcludedMembers("PM@example.com, lead@example.com,,pm@example.com"))
+}
+
+func TestExcludedMembersVersion(t *testing.T) {
+	etag := `W/"0123456789abcdef"`
+	assert.Equal(t, etag, ExcludedMembersVersion(etag, nil))
+
+	lead := ExcludedMembersVersion(etag, []string{"lead@example.com"})
+	assert.NotEqual(t, etag, lead)
+	assert.NotEqual(t, lead, ExcludedMembersVersion(etag, []string{"lead@example.com", "pm@example.com"}))
+	assert.NotEqual(t, TagVersion(etag, "lead@example.com"), lead, "exclusions are tagged apart from tags")
+}
+
+func TestLoadsByMember(t *testing.T) {
+	release := models.Load{ID: 1, Title: "Release"}
+	review := models.Load{ID: 2, Title: "Review"}
+	loads := []models.LoadWithAssignments{
+		{Load: release, Pinned: true, Assignments: []models.LoadAssignment{
+			{LoadID: 1, PersonEmail: "alice@example.com", Weight: 4},
+			{LoadID: 1, PersonEmail: "bob@example.com", Weight: 1},
+			{LoadID: 1, PersonEmail: "hidden@example.com", Weight: 3},
+		}},
+		{Load: review, Assignments: []models.LoadAssignment{
+			{LoadID: 2, PersonEmail: "alice@example.com", Weight: 2},
+		}},
+	}
+	capacities := map[string]float64{
+		"alice@example.com": 5,
+		"bob@example.com":   5,
+		"carol@example.com": 4,
+		"dave@example.com":  0,
+	}
+
+	members := loadsByMember(capacities, loads)
+	if !assert.Len(t, members, 4) {
+		return
+	}
+
+	// Fullest first: over capacity, then by share, then the idle by email
+	assert.Equal(t, "alice@example.com", members[0].PersonEmail)
+	assert.Equal(t, 6.0, members[0].Load)
+	assert.Equal(t, 5.0, members[0].Capacity)
+	if assert.Len(t, members[0].Loads, 2) {
+		assert.Equal(t, 1, members[0].Loads[0].Load.ID, "loads keep their order")
+		assert.True(t, members[0].Loads[0].Pinned)
+		assert.Equal(t, []models.LoadAssignment{{LoadID: 1, PersonEmail: "alice@example.com", Weight: 4}},
+			members[0].Loads[0].Assignments, "only the member's own assignment")
+	}
+	assert.Equal(t, "bob@example.com", members[1].PersonEmail)
+	assert.Equal(t, 1.0, members[1].Load)
+	assert.Equal(t, "carol@example.com", members[2].PersonEmail)
+	assert.Equal(t, "dave@example.com", members[3].PersonEmail)
+	assert.NotNil(t, members[3].Loads, "idle members list no loads rather than null")
+}
+
+func TestMemberBreakdown(t *testing.T) {
+	members := []models.RebalanceMember{
+		{Email: "bob@example.com", Title: "Bob", Capacity: 5, LoadBefore: 1},
+		{Email: "alice@example.com", Title: "Alice", Capacity: 5, LoadBefore: 6},
+		{Email: "hidden@example.com", Title: "Hidden", Capacity: 1, LoadBefore: 9},
+		{Email: "dave@example.com", Title: "Dave", Capacity: 0, LoadBefore: 0},
+		{Email: "carol@example.com", Title: "Carol", Capacity: 4, LoadBefore: 0},
+	}
+
+	breakdown := memberBreakdown(members, map[string]bool{"hidden@example.com": true})
+	if !assert.Len(t, breakdown, 4) {
+		return
+	}
+	assert.Equal(t, "alice@example.com", breakdown[0].PersonEmail, "fullest first")
+	assert.True(t, breakdown[0].Overloaded)
+	assert.Equal(t, getHeatmapColor(6, 5), breakdown[0].Color)
+	assert.Equal(t, "bob@example.com", breakdown[1].PersonEmail)
+	assert.False(t, breakdown[1].Overloaded)
+	assert.Equal(t, "carol@example.com", breakdown[2].PersonEmail, "the idle by email")
+	assert.Equal(t, "dave@example.com", breakdown[3].PersonEmail)
+	assert.False(t, breakdown[3].Overloaded, "no load is never overloaded")
+}
+
+func TestParseHeatmapRange(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	windowStart, windowEnd := HeatmapWindow(now)
+
+	tests := []struct {
+		name           string
+		from, to, days string
+		start, end     time.Time
+	}{
+		{"default window", "", "", "", windowStart, windowEnd},
+		{"from and to", "2025-03-17", "2025-03-28", "", day("2025-03-17"), day("2025-03-28")},
+		{"from to the window's end", "2025-01-01", "", "", day("2025-01-01"), windowEnd},
+		{"the window's start to to", "", "2025-03-31", "", windowStart, day("2025-03-31")},
+		{"days from today", "", "", "14", day("2025-03-10"), day("2025-03-23")},
+		{"days from from", "2025-01-01", "", "365", day("2025-01-01"), day("2025-12-31")},
+		{"one day", "2025-03-17", "2025-03-17", "", day("2025-03-17"), day("2025-03-17")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := ParseHeatmapRange(tt.from, tt.to, tt.days, now)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.start, start)
+				assert.Equal(t, tt.end, end)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name           string
+		from, to, days string
+	}{
+		{"bad from", "17/03/2025", "", ""},
+		{"bad to", "", "soon", ""},
+		{"to before from", "2025-03-28", "2025-03-17", ""},
+		{"days with to", "", "2025-03-28", "14"},
+		{"no days", "", "", "0"},
+		{"days not a number", "", "", "two"},
+		{"too many days", "", "", "367"},
+		{"too long", "2025-01-01", "2026-01-02", ""},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseHeatmapRange(tt.from, tt.to, tt.days, now)
+			assert.ErrorIs(t, err, ErrInvalidRange)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// Heatmap image layout, in pixels
+const (
+	imageCell    = 24 // side of a day's square
+	imageGap     = 4  // between squares
+	imageWeekGap = 12 // between weeks, in place of imageGap
+	imagePadding = 12
+)
+
+var imageBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// RenderHeatmapPNG draws rows of heatmap days as a PNG of colored squares,
+// one row per entity and one column per day, with a wider gap every seven
+// days. It has no text, so chat clients that only take raster images can
+// show it; callers caption the rows and columns themselves.
+func RenderHeatmapPNG(rows [][]models.HeatmapDay) ([]byte, error) {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if len(rows) == 0 || columns == 0 {
+		return nil, fmt.Errorf("no heatmap days to draw")
+	}
+
+	width := 2*imagePadding + imageColumnX(columns-1) + imageCell
+	height := 2*imagePadding + len(rows)*imageCell + (len(rows)-1)*imageGap
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(imageBackground), image.Point{}, draw.Src)
+
+	for r, row := range rows {
+		y := imagePadding + r*(imageCell+imageGap)
+		for c, day := range row {
+			x := imagePadding + imageColumnX(c)
+			cell := image.Rect(x, y, x+imageCell, y+imageCell)
+			draw.Draw(img, cell, image.NewUniform(parseHexColor(day.Color)), image.Point{}, draw.Src)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode heatmap image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// imageColumnX is the offset of a day's column from the first one
+func imageColumnX(column int) int {
+	weeks := column / 7
+	return column*(imageCell+imageGap) + weeks*(imageWeekGap-imageGap)
+}
+
+// parseHexColor parses a heatmap color such as "#dc2626", falling back to
+// the no-load gray
+func parseHexColor(hex string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.RGBA{R: 0xe5, G: 0xe7, B: 0xeb, A: 0xff}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
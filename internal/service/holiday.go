@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// DefaultHolidaysAPIURL is Nager.Date's public holidays of a year in a
+// country, by ISO 3166-1 alpha-2 code
+const DefaultHolidaysAPIURL = "https://date.nager.at/api/v3/PublicHolidays/{year}/{region}"
+
+const (
+	// maxHolidayDays is the longest holiday an imported calendar event may
+	// span
+	maxHolidayDays = 31
+	// maxHolidayICSBytes is the largest calendar file imported
+	maxHolidayICSBytes = 1 << 20
+)
+
+var regionPattern = regexp.MustCompile(`^[A-Z0-9-]{2,16}$`)
+
+// ErrInvalidRegion is returned for a region that is not a code such as "ID"
+var ErrInvalidRegion = errors.New("region must be 2 to 16 letters, digits or dashes")
+
+// ErrInvalidHolidays is returned for a holiday calendar or API response
+// without usable holidays
+var ErrInvalidHolidays = errors.New("invalid holidays")
+
+// HolidayService keeps the public holidays of regions, imported from
+// calendar files or a holidays API
+type HolidayService struct {
+	holidayRepo *repository.HolidayRepository
+	apiURL      string
+	client      *http.Client
+}
+
+func NewHolidayService(holidayRepo *repository.HolidayRepository, apiURL string) *HolidayService {
+	if apiURL == "" {
+		apiURL = DefaultHolidaysAPIURL
+	}
+	return &HolidayService{
+		holidayRepo: holidayRepo,
+		apiURL:      apiURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// NormalizeRegion trims and uppercases a region, returning nil for none
+func NormalizeRegion(region *string) *string {
+	if region == nil {
+		return nil
+	}
+	r := strings.ToUpper(strings.TrimSpace(*region))
+	if r == "" {
+		return nil
+	}
+	return &r
+}
+
+// checkRegion normalizes a region and checks it is a code
+func checkRegion(region string) (string, error) {
+	r := strings.ToUpper(strings.TrimSpace(region))
+	if !regionPattern.MatchString(r) {
+		return "", ErrInvalidRegion
+	}
+	return r, nil
+}
+
+// List returns a region's holidays in a year
+func (s *HolidayService) List(ctx context.Context, region string, year int) ([]models.Holiday, error) {
+	region, err := checkRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return s.holidayRepo.List(ctx, region, start, start.AddDate(1, 0, -1))
+}
+
+// Delete removes one of a region's holidays, such as one a calendar listed
+// by mistake
+func (s *HolidayService) Delete(ctx context.Context, region, dateStr string) error {
+	region, err := checkRegion(region)
+	if err != nil {
+		return err
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	return s.holidayRepo.Delete(ctx, region, date)
+}
+
+// ImportICS stores the events of an iCalendar file as a region's holidays,
+// each day of a multi-day event as one
+func (s *HolidayService) ImportICS(ctx context.Context, region string, r io.Reader) (*models.HolidayImport, error) {
+	region, err := checkRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxHolidayICSBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if len(data) > maxHolidayICSBytes {
+		return nil, fmt.Errorf("%w: calendar is larger than %d bytes", ErrInvalidHolidays, maxHolidayICSBytes)
+	}
+	holidays, err := parseHolidayICS(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.store(ctx, region, holidays)
+}
+
+// Fetch imports a region's public holidays of a year from the holidays API
+func (s *HolidayService) Fetch(ctx context.Context, region string, year int) (*models.HolidayImport, error) {
+	region, err := checkRegion(region)
+	if err != nil {
+		return nil, err
+	}
+
+	u := strings.NewReplacer("{year}", strconv.Itoa(year), "{region}", region).Replace(s.apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create holidays request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch holidays: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch holidays: holidays API returned %d", resp.StatusCode)
+	}
+
+	holidays, err := decodeHolidaysAPI(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return s.store(ctx, region, holidays)
+}
+
+// store upserts a region's holidays
+func (s *HolidayService) store(ctx context.Context, region string, holidays map[time.Time]string) (*models.HolidayImport, error) {
+	if len(holidays) == 0 {
+		return nil, fmt.Errorf("%w: no holidays found", ErrInvalidHolidays)
+	}
+	if err := s.holidayRepo.Upsert(ctx, region, holidays); err != nil {
+		return nil, err
+	}
+	return &models.HolidayImport{Region: region, Imported: len(holidays)}, nil
+}
+
+// decodeHolidaysAPI reads the holidays of a holidays API response: a list of
+// holidays with their date and name, as Nager.Date answers
+func decodeHolidaysAPI(r io.Reader) (map[time.Time]string, error) {
+	var entries []struct {
+		Date      string `json:"date"`
+		Name      string `json:"name"`
+		LocalName string `json:"localName"`
+		Global    *bool  `json:"global"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("%w: holidays API response: %v", ErrInvalidHolidays, err)
+	}
+
+	holidays := make(map[time.Time]string, len(entries))
+	for _, e := range entries {
+		// Holidays of only some of the region's provinces are left out
+		if e.Global != nil && !*e.Global {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: holidays API date %q", ErrInvalidHolidays, e.Date)
+		}
+		name := e.Name
+		if name == "" {
+			name = e.LocalName
+		}
+		holidays[date] = name
+	}
+	return holidays, nil
+}
+
+// parseHolidayICS reads the days of the events of an iCalendar file, with
+// their summaries. DTEND is exclusive; events without one last a day.
+func parseHolidayICS(data []byte) (map[time.Time]string, error) {
+	// Unfold continuation lines, which start with a space or tab
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\n "), nil)
+	data = bytes.ReplaceAll(data, []byte("\n\t"), nil)
+
+	holidays := make(map[time.Time]string)
+	var inEvent bool
+	var summary, startStr, endStr string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters such as ;VALUE=DATE
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			summary, startStr, endStr = "", "", ""
+		case !inEvent:
+		case name == "SUMMARY":
+			summary = icsUnescape(value)
+		case name == "DTSTART":
+			startStr = value
+		case name == "DTEND":
+			endStr = value
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			start, err := icsDay(startStr)
+			if err != nil {
+				return nil, fmt.Errorf("%w: DTSTART %q of %q", ErrInvalidHolidays, startStr, summary)
+			}
+			end := start.AddDate(0, 0, 1)
+			if endStr != "" {
+				if end, err = icsDay(endStr); err != nil {
+					return nil, fmt.Errorf("%w: DTEND %q of %q", ErrInvalidHolidays, endStr, summary)
+				}
+			}
+			if end.Sub(start) > maxHolidayDays*24*time.Hour {
+				return nil, fmt.Errorf("%w: %q lasts more than %d days", ErrInvalidHolidays, summary, maxHolidayDays)
+			}
+			for d := start; d.Before(end) || d.Equal(start); d = d.AddDate(0, 0, 1) {
+				holidays[d] = summary
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// icsDay reads the day of a DATE or DATE-TIME value
+func icsDay(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, ErrInvalidHolidays
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// icsUnescape reverses icsText
+func icsUnescape(s string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(s)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHolidayICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20250329\r\n" +
+		"DTEND;VALUE=DATE:20250330\r\n" +
+		"SUMMARY:Nyepi\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20250331\r\n" +
+		"DTEND;VALUE=DATE:20250402\r\n" +
+		"SUMMARY:Idul Fitri\\, 1446 Hijr\r\n" +
+		" iyah\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20251225T000000Z\r\n" +
+		"SUMMARY:Christmas\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	holidays, err := parseHolidayICS([]byte(ics))
+	assert.NoError(t, err)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	assert.Equal(t, map[time.Time]string{
+		day(time.March, 29):    "Nyepi",
+		day(time.March, 31):    "Idul Fitri, 1446 Hijriyah",
+		day(time.April, 1):     "Idul Fitri, 1446 Hijriyah",
+		day(time.December, 25): "Christmas",
+	}, holidays, "DTEND is exclusive, and events without one last a day")
+
+	_, err = parseHolidayICS([]byte("BEGIN:VEVENT\nDTSTART:2025\nEND:VEVENT\n"))
+	assert.ErrorIs(t, err, ErrInvalidHolidays)
+	_, err = parseHolidayICS([]byte("BEGIN:VEVENT\nDTSTART:20250101\nDTEND:20250601\nEND:VEVENT\n"))
+	assert.ErrorIs(t, err, ErrInvalidHolidays, "events longer than a month are not holidays")
+}
+
+func TestDecodeHolidaysAPI(t *testing.T) {
+	body := `[
+		{"date": "2025-01-01", "localName": "Tahun Baru Masehi", "name": "New Year's Day", "global": true},
+		{"date": "2025-03-29", "localName": "Hari Raya Nyepi", "name": "", "global": true},
+		{"date": "2025-06-01", "localName": "Provincial Day", "name": "Provincial Day", "global": false}
+	]`
+
+	holidays, err := decodeHolidaysAPI(strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Time]string{
+		time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC): "New Year's Day",
+		time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC):  "Hari Raya Nyepi",
+	}, holidays, "the local name stands in for a missing name, and regional holidays are left out")
+
+	_, err = decodeHolidaysAPI(strings.NewReader(`{"status": 404}`))
+	assert.ErrorIs(t, err, ErrInvalidHolidays)
+}
+
+func TestRegions(t *testing.T) {
+	region := " id "
+	assert.Equal(t, "ID", *NormalizeRegion(&region))
+	empty := ""
+	assert.Nil(t, NormalizeRegion(&empty))
+	assert.Nil(t, NormalizeRegion(nil))
+
+	r, err := checkRegion("us-ca")
+	assert.NoError(t, err)
+	assert.Equal(t, "US-CA", r)
+	_, err = checkRegion("I")
+	assert.ErrorIs(t, err, ErrInvalidRegion)
+	_, err = checkRegion("../ID")
+	assert.ErrorIs(t, err, ErrInvalidRegion)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// icsLineOctets is the longest content line RFC 5545 allows, CRLF excluded
+const icsLineOctets = 75
+
+// RenderICS renders loads as an iCalendar feed (RFC 5545) named name, for
+// calendar apps to subscribe to. Each load is one all-day event over its
+// days, listing its assignees and weights; a recurring load carries its rule
+// as an RRULE, so the app expands the occurrences itself. Events are free
+// time, since loads are effort rather than meetings, and are stamped now.
+func RenderICS(name string, loads []models.LoadWithAssignments, now time.Time) []byte {
+	var w icsWriter
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//GTI//Heatmap Calendar//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", icsText(name))
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, l := range loads {
+		load := l.Load
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", fmt.Sprintf("load-%d@heatmap-calendar", load.ID))
+		w.line("DTSTAMP", stamp)
+		w.line("DTSTART;VALUE=DATE", icsDate(load.Date))
+		// DTEND is exclusive
+		w.line("DTEND;VALUE=DATE", icsDate(lastDay(&load).AddDate(0, 0, 1)))
+		if load.Recurrence != nil {
+			w.line("RRULE", icsRule(load.Recurrence))
+		}
+		w.line("SUMMARY", icsText(load.Title))
+		w.line("DESCRIPTION", icsText(icsDescription(l)))
+		if load.Source != nil && *load.Source != "" {
+			w.line("CATEGORIES", icsText(*load.Source))
+		}
+		if load.URL != nil {
+			if u, err := url.Parse(*load.URL); err == nil && u.IsAbs() {
+				w.line("URL", u.String())
+			}
+		}
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+// icsDescription lists a load's assignees with their weights, by email
+func icsDescription(l models.LoadWithAssignments) string {
+	assignments := append([]models.LoadAssignment(nil), l.Assignments...)
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].PersonEmail < assignments[j].PersonEmail
+	})
+
+	lines := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		lines = append(lines, fmt.Sprintf("%s: %s", a.PersonEmail, strconv.FormatFloat(a.Weight, 'f', -1, 64)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// icsRule renders a recurrence as an RRULE value. Monthly rules skip months
+// that lack the first day's day of the month, as the server's expansion does.
+func icsRule(rule *models.Recurrence) string {
+	parts := []string{"FREQ=" + strings.ToUpper(string(rule.Frequency))}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if rule.Until != nil {
+		parts = append(parts, "UNTIL="+icsDate(*rule.Until))
+	}
+	if rule.Count != nil {
+		parts = append(parts, "COUNT="+strconv.Itoa(*rule.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// icsDate renders a day as a DATE value
+func icsDate(d time.Time) string {
+	return d.Format("20060102")
+}
+
+// icsText escapes a TEXT value
+func icsText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// icsWriter writes iCalendar content lines, ending them with CRLF and
+// folding those longer than 75 octets without splitting a character
+type icsWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with the folding space
+		limit = icsLineOctets - 1
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderICS(t *testing.T) {
+	source := "jira"
+	link := "https://jira.example.com/browse/OPS-1"
+	endDate := day(2025, 3, 12)
+	count := 4
+	loads := []models.LoadWithAssignments{
+		{
+			Load: models.Load{ID: 7, Title: "Release; QA, sign-off", Source: &source, URL: &link, Date: day(2025, 3, 10), EndDate: &endDate},
+			Assignments: []models.LoadAssignment{
+				{PersonEmail: "bob@example.com", Weight: 1.5},
+				{PersonEmail: "alice@example.com", Weight: 2},
+			},
+		},
+		{
+			Load: models.Load{ID: 8, Title: "Standup", Date: day(2025, 3, 3),
+				Recurrence: &models.Recurrence{Frequency: models.RecurrenceWeekly, Interval: 2, Count: &count}},
+		},
+	}
+
+	body := string(RenderICS("Team, Ops", loads, time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)))
+
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	assert.Contains(t, body, "X-WR-CALNAME:Team\\, Ops\r\n")
+	assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT\r\n"))
+
+	assert.Contains(t, body, "UID:load-7@heatmap-calendar\r\n")
+	assert.Contains(t, body, "DTSTAMP:20250301T093000Z\r\n")
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20250310\r\nDTEND;VALUE=DATE:20250313\r\n", "DTEND is the day after the last")
+	assert.Contains(t, body, "SUMMARY:Release\\; QA\\, sign-off\r\n")
+	assert.Contains(t, body, "DESCRIPTION:alice@example.com: 2\\nbob@example.com: 1.5\r\n", "assignees sorted by email")
+	assert.Contains(t, body, "CATEGORIES:jira\r\n")
+	assert.Contains(t, body, "URL:"+link+"\r\n")
+
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20250303\r\nDTEND;VALUE=DATE:20250304\r\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4\r\n")
+}
+
+func TestICSRule(t *testing.T) {
+	until := day(2025, 6, 30)
+	assert.Equal(t, "FREQ=DAILY", icsRule(&models.Recurrence{Frequency: models.RecurrenceDaily, Interval: 1}))
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=3;UNTIL=20250630",
+		icsRule(&models.Recurrence{Frequency: models.RecurrenceMonthly, Interval: 3, Until: &until}))
+}
+
+func TestICSWriterFolds(t *testing.T) {
+	var w icsWriter
+	w.line("SUMMARY", strings.Repeat("é", 60))
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n")
+
+	assert.Greater(t, len(lines), 1)
+	var unfolded strings.Builder
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), icsLineOctets, "line %d", i)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "), "continuation %d starts with a space", i)
+			line = line[1:]
+		}
+		unfolded.WriteString(line)
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 60), unfolded.String(), "no character is split")
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// recentErrorsPerSource is how many failed syncs the health report lists for
+// each source
+const recentErrorsPerSource = 5
+
+// IntegrationService tracks failed load syncs and reports, per source system
+// and webhook event, whether integrations are working, so ops can spot a
+// broken one before people notice their heatmaps going stale
+type IntegrationService struct {
+	integrationRepo *repository.IntegrationRepository
+}
+
+func NewIntegrationService(integrationRepo *repository.IntegrationRepository) *IntegrationService {
+	return &IntegrationService{integrationRepo: integrationRepo}
+}
+
+// RecordError stores a failed load sync from source. Failing to store it is
+// only logged, so the caller's own error response goes out regardless.
+func (s *IntegrationService) RecordError(ctx context.Context, source, operation string, status int, message string) {
+	err := s.integrationRepo.RecordError(ctx, models.IntegrationError{
+		Source:    source,
+		Operation: operation,
+		Status:    status,
+		Message:   message,
+	})
+	if err != nil {
+		log.Printf("Integration health: %v", err)
+	}
+}
+
+// GetHealthReport summarizes the past 24 hours of load syncs and webhook
+// deliveries
+func (s *IntegrationService) GetHealthReport(ctx context.Context, now time.Time) (*models.IntegrationHealthReport, error) {
+	since := now.Add(-24 * time.Hour)
+
+	syncs, err := s.integrationRepo.GetSourceSyncs(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.integrationRepo.GetErrorCounts(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.integrationRepo.GetRecentErrors(ctx, recentErrorsPerSource)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.integrationRepo.GetWebhookDeliveries(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].SuccessRate = successRate(webhooks[i].Delivered, webhooks[i].Failed)
+	}
+	if webhooks == nil {
+		webhooks = []models.WebhookHealth{}
+	}
+
+	return &models.IntegrationHealthReport{
+		GeneratedAt: now,
+		Sources:     mergeSourceHealth(syncs, counts, recent),
+		Webhooks:    webhooks,
+	}, nil
+}
+
+// mergeSourceHealth adds error counts and recent errors to the sources'
+// syncs. Sources whose every sync failed have errors but no loads, and are
+// listed too. Sources with recent errors come first, then by name.
+func mergeSourceHealth(syncs []models.SourceHealth, counts map[string]int, recent []models.IntegrationError) []models.SourceHealth {
+	bySource := make(map[string]*models.SourceHealth, len(syncs))
+	for i := range syncs {
+		bySource[syncs[i].Source] = &syncs[i]
+	}
+	get := func(source string) *models.SourceHealth {
+		h, ok := bySource[source]
+		if !ok {
+			h = &models.SourceHealth{Source: source}
+			bySource[source] = h
+		}
+		return h
+	}
+	for source, n := range counts {
+		get(source).Errors = n
+	}
+	for _, e := range recent {
+		h := get(e.Source)
+		h.RecentErrors = append(h.RecentErrors, e)
+	}
+
+	sources := make([]models.SourceHealth, 0, len(bySource))
+	for _, h := range bySource {
+		if h.RecentErrors == nil {
+			h.RecentErrors = []models.IntegrationError{}
+		}
+		sources = append(sources, *h)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if (sources[i].Errors > 0) != (sources[j].Errors > 0) {
+			return sources[i].Errors > 0
+		}
+		return sources[i].Source < sources[j].Source
+	})
+	return sources
+}
+
+// successRate is the share of attempts delivered, 1 when there were none
+func successRate(delivered, failed int) float64 {
+	if delivered+failed == 0 {
+		return 1
+	}
+	return float64(delivered) / float64(delivered+failed)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSourceHealth(t *testing.T) {
+	synced := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	failed := synced.Add(time.Hour)
+
+	sources := mergeSourceHealth(
+		[]models.SourceHealth{
+			{Source: "", LastSyncAt: &synced, LoadsSynced: 1},
+			{Source: "gcal", LastSyncAt: &synced, LoadsSynced: 4},
+			{Source: "jira", LastSyncAt: &synced, LoadsSynced: 2},
+		},
+		map[string]int{"jira": 2, "crm": 1},
+		[]models.IntegrationError{
+			{Source: "crm", Status: 400, Message: "bad date", OccurredAt: failed},
+			{Source: "jira", Status: 409, Message: "blackout", OccurredAt: failed},
+			{Source: "jira", Status: 500, Message: "timeout", OccurredAt: synced},
+			// Older than a day, so listed but not counted
+			{Source: "gcal", Status: 403, Message: "scope", OccurredAt: synced.AddDate(0, 0, -3)},
+		},
+	)
+
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Source)
+	}
+	assert.Equal(t, []string{"crm", "jira", "", "gcal"}, names, "sources with errors first, then by name")
+
+	crm := sources[0]
+	assert.Nil(t, crm.LastSyncAt, "a source that never synced a load has no sync time")
+	assert.Equal(t, 1, crm.Errors)
+
+	jira := sources[1]
+	assert.Equal(t, 2, jira.LoadsSynced)
+	assert.Equal(t, 2, jira.Errors)
+	require.Len(t, jira.RecentErrors, 2)
+	assert.Equal(t, "blackout", jira.RecentErrors[0].Message, "recent errors keep their order")
+
+	gcal := sources[3]
+	assert.Equal(t, 0, gcal.Errors)
+	assert.Len(t, gcal.RecentErrors, 1)
+
+	assert.NotNil(t, sources[2].RecentErrors, "sources without errors list none rather than null")
+	assert.Empty(t, sources[2].RecentErrors)
+}
+
+func TestSuccessRate(t *testing.T) {
+	assert.Equal(t, 1.0, successRate(0, 0), "nothing sent, nothing failed")
+	assert.Equal(t, 0.75, successRate(3, 1))
+	assert.Equal(t, 0.0, successRate(0, 2))
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// IssueSource is the source of loads upserted from issue webhooks
+const IssueSource = "jira"
+
+const (
+	// defaultStoryPointsField is Jira Cloud's "Story point estimate" field
+	defaultStoryPointsField = "customfield_10016"
+	defaultPointWeight      = 1.0
+)
+
+// ErrInvalidIssue is returned for an issue webhook without an issue key
+var ErrInvalidIssue = errors.New("invalid issue")
+
+// IssueMapping sets how issues map to loads: which issue field holds story
+// points, and how much load weight each point is
+type IssueMapping struct {
+	StoryPointsField string
+	PointWeight      float64
+}
+
+// MapIssues sets how issue webhooks map to loads, in place of Jira Cloud's
+// story points field at one weight per point
+func (s *LoadService) MapIssues(mapping IssueMapping) {
+	s.issueMapping = mapping
+}
+
+// UpsertFromIssue keeps the load of an issue tracker issue in line with a
+// webhook about it: the issue's due date, assigned to its assignee, weighted
+// by its story points. An issue that is deleted, done, unassigned or without
+// a due date has its load deleted.
+func (s *LoadService) UpsertFromIssue(ctx context.Context, hook *models.IssueWebhook) (*models.IssueLoadResult, error) {
+	key := strings.TrimSpace(hook.Issue.Key)
+	if key == "" {
+		return nil, fmt.Errorf("%w: issue.key is required", ErrInvalidIssue)
+	}
+	result := &models.IssueLoadResult{ExternalID: key}
+
+	req, reason := s.issueLoad(key, hook)
+	if req == nil {
+		result.Reason = reason
+		if _, err := s.DeleteLoadByExternalID(ctx, key); err != nil {
+			if !errors.Is(err, repository.ErrLoadNotFound) {
+				return nil, err
+			}
+			result.Action = "ignored"
+			return result, nil
+		}
+		result.Action = "deleted"
+		return result, nil
+	}
+
+	resp, err := s.UpsertLoad(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result.Action = "upserted"
+	result.Load = resp
+	return result, nil
+}
+
+// issueLoad maps an issue to the upsert of its load, or returns why it has
+// none
+func (s *LoadService) issueLoad(key string, hook *models.IssueWebhook) (*models.UpsertLoadRequest, string) {
+	fields := hook.Issue.Fields
+	if hook.WebhookEvent == "jira:issue_deleted" {
+		return nil, "issue deleted"
+	}
+	if issueString(fields, "status", "statusCategory", "key") == "done" {
+		return nil, "issue done"
+	}
+	assignee := issueString(fields, "assignee", "emailAddress")
+	if assignee == "" {
+		return nil, "issue unassigned"
+	}
+	dueDate := issueString(fields, "duedate")
+	if dueDate == "" {
+		return nil, "issue has no due date"
+	}
+
+	title := key
+	if summary := issueString(fields, "summary"); summary != "" {
+		title += ": " + summary
+	}
+	req := &models.UpsertLoadRequest{
+		ExternalID: key,
+		Title:      title,
+		Source:     IssueSource,
+		URL:        issueBrowseURL(hook.Issue.Self, key),
+		Date:       dueDate,
+	}
+
+	// Story points set the weight; otherwise the original time estimate is
+	// the duration the weight rules go by
+	var weight float64
+	field, pointWeight := s.issueMapping.StoryPointsField, s.issueMapping.PointWeight
+	if field == "" {
+		field = defaultStoryPointsField
+	}
+	if pointWeight <= 0 {
+		pointWeight = defaultPointWeight
+	}
+	if points, ok := issueNumber(fields, field); ok && points > 0 {
+		weight = points * pointWeight
+	} else if seconds, ok := issueNumber(fields, "timeoriginalestimate"); ok && seconds > 0 {
+		minutes := int(seconds / 60)
+		req.DurationMinutes = &minutes
+	}
+
+	req.Assignees = append(req.Assignees, struct {
+		Email  string  `json:"email" validate:"required,email"`
+		Weight float64 `json:"weight,omitempty"`
+	}{Email: strings.ToLower(assignee), Weight: weight})
+	return req, ""
+}
+
+// issueString returns the string at a path of nested issue fields, or ""
+func issueString(fields map[string]interface{}, path ...string) string {
+	value := issueField(fields, path...)
+	s, _ := value.(string)
+	return strings.TrimSpace(s)
+}
+
+// issueNumber returns the number at an issue field, sent as a number or a
+// numeric string
+func issueNumber(fields map[string]interface{}, name string) (float64, bool) {
+	switch v := issueField(fields, name).(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// issueField walks a path of nested issue fields
+func issueField(fields map[string]interface{}, path ...string) interface{} {
+	var value interface{} = fields
+	for _, name := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[name]
+	}
+	return value
+}
+
+// issueBrowseURL links to an issue's page on the tracker its REST URL is on,
+// or is empty without one
+func issueBrowseURL(self, key string) string {
+	u, err := url.Parse(self)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/browse/" + url.PathEscape(key)
+}
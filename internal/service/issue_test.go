@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueLoad(t *testing.T) {
+	hook := func(payload string) *models.IssueWebhook {
+		var h models.IssueWebhook
+		require.NoError(t, json.Unmarshal([]byte(payload), &h))
+		return &h
+	}
+	s := &LoadService{}
+
+	req, reason := s.issueLoad("PROJ-1", hook(`{"webhookEvent": "jira:issue_updated", "issue": {
+		"key": "PROJ-1",
+		"self": "https://acme.atlassian.net/rest/api/2/issue/10001",
+		"fields": {
+			"summary": "Fix login",
+			"assignee": {"emailAddress": "Alice@Example.com"},
+			"duedate": "2025-03-14",
+			"status": {"statusCategory": {"key": "indeterminate"}},
+			"customfield_10016": 3,
+			"timeoriginalestimate": 7200
+		}
+	}}`))
+	require.NotNil(t, req, reason)
+	assert.Equal(t, "PROJ-1", req.ExternalID)
+	assert.Equal(t, "PROJ-1: Fix login", req.Title)
+	assert.Equal(t, "jira", req.Source)
+	assert.Equal(t, "https://acme.atlassian.net/browse/PROJ-1", req.URL)
+	assert.Equal(t, "2025-03-14", req.Date)
+	require.Len(t, req.Assignees, 1)
+	assert.Equal(t, "alice@example.com", req.Assignees[0].Email)
+	assert.Equal(t, 3.0, req.Assignees[0].Weight, "one weight per story point by default")
+	assert.Nil(t, req.DurationMinutes, "story points win over the time estimate")
+
+	s.MapIssues(IssueMapping{StoryPointsField: "customfield_10026", PointWeight: 0.5})
+	req, _ = s.issueLoad("PROJ-2", hook(`{"issue": {"key": "PROJ-2", "fields": {
+		"assignee": {"emailAddress": "bob@example.com"},
+		"duedate": "2025-03-14",
+		"customfield_10026": "5"
+	}}}`))
+	require.NotNil(t, req)
+	assert.Equal(t, 2.5, req.Assignees[0].Weight, "points from the configured field")
+	assert.Empty(t, req.URL, "no link without the issue's REST URL")
+
+	req, _ = s.issueLoad("PROJ-3", hook(`{"issue": {"key": "PROJ-3", "fields": {
+		"assignee": {"emailAddress": "bob@example.com"},
+		"duedate": "2025-03-14",
+		"timeoriginalestimate": 5400
+	}}}`))
+	require.NotNil(t, req)
+	assert.Zero(t, req.Assignees[0].Weight, "weight rules decide without points")
+	require.NotNil(t, req.DurationMinutes)
+	assert.Equal(t, 90, *req.DurationMinutes)
+
+	for payload, want := range map[string]string{
+		`{"webhookEvent": "jira:issue_deleted", "issue": {"key": "X-1", "fields": {"assignee": {"emailAddress": "a@example.com"}, "duedate": "2025-03-14"}}}`:          "issue deleted",
+		`{"issue": {"key": "X-1", "fields": {"status": {"statusCategory": {"key": "done"}}, "assignee": {"emailAddress": "a@example.com"}, "duedate": "2025-03-14"}}}`: "issue done",
+		`{"issue": {"key": "X-1", "fields": {"assignee": null, "duedate": "2025-03-14"}}}`:                                                                             "issue unassigned",
+		`{"issue": {"key": "X-1", "fields": {"assignee": {"emailAddress": "a@example.com"}, "duedate": null}}}`:                                                        "issue has no due date",
+	} {
+		req, reason := s.issueLoad("X-1", hook(payload))
+		assert.Nil(t, req, want)
+		assert.Equal(t, want, reason)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+const (
+	// maxRunningJobs is how many jobs run at once; others wait queued
+	maxRunningJobs = 2
+	// jobFlushInterval is how often unfinished jobs store their progress,
+	// which also shows they are alive
+	jobFlushInterval = time.Second
+	// jobAbandonedAfter is how long an unfinished job may go without storing
+	// progress before it is taken as left behind by a stopped server
+	jobAbandonedAfter = time.Minute
+)
+
+// JobFunc does a job's work, reporting each item to p. An error stops the
+// job and fails it; failed items are reported to p instead.
+type JobFunc func(ctx context.Context, p *JobProgress) error
+
+// BulkJob is work to run as a background job: Total items, done by Run
+type BulkJob struct {
+	Kind  string
+	Total int
+	Run   JobFunc
+}
+
+// JobRunner runs bulk operations in the background, so requests that start
+// them answer 202 with a job to poll instead of holding a connection until
+// they finish. Jobs keep the trace of the request that started them.
+type JobRunner struct {
+	jobRepo *repository.JobRepository
+	ctx     context.Context // cancelled when the server stops
+	slots   chan struct{}
+}
+
+// NewJobRunner returns a runner whose jobs stop when ctx is cancelled
+func NewJobRunner(ctx context.Context, jobRepo *repository.JobRepository) *JobRunner {
+	return &JobRunner{
+		jobRepo: jobRepo,
+		ctx:     ctx,
+		slots:   make(chan struct{}, maxRunningJobs),
+	}
+}
+
+// Submit stores job as queued and runs it once a slot is free
+func (r *JobRunner) Submit(ctx context.Context, job *BulkJob) (*models.Job, error) {
+	stored, err := r.jobRepo.Create(ctx, job.Kind, job.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the request's values, such as its trace, but not its deadline
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(r.ctx, cancel)
+	go func() {
+		defer cancel()
+		defer stop()
+		r.run(jobCtx, stored.ID, job.Run)
+	}()
+
+	return stored, nil
+}
+
+// Get returns a job's progress
+func (r *JobRunner) Get(ctx context.Context, id int64) (*models.Job, error) {
+	return r.jobRepo.Get(ctx, id)
+}
+
+// RunAbandonedJobCheck fails unfinished jobs that stopped reporting, left
+// behind by a server that stopped, every interval until ctx is cancelled.
+func (r *JobRunner) RunAbandonedJobCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		failed, err := r.jobRepo.FailAbandoned(ctx, time.Now().Add(-jobAbandonedAfter))
+		if err != nil {
+			log.Printf("Abandoned job check: %v", err)
+		} else if failed > 0 {
+			log.Printf("Failed %d abandoned jobs", failed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *JobRunner) run(ctx context.Context, id int64, fn JobFunc) {
+	p := &JobProgress{}
+
+	// Store progress while queued and running
+	done := make(chan struct{})
+	var flushing sync.WaitGroup
+	flushing.Add(1)
+	go func() {
+		defer flushing.Done()
+		ticker := time.NewTicker(jobFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				r.flush(ctx, id, p)
+			}
+		}
+	}()
+
+	var err error
+	select {
+	case r.slots <- struct{}{}:
+		if err = r.jobRepo.Start(ctx, id); err == nil {
+			err = fn(ctx, p)
+		}
+		<-r.slots
+	case <-ctx.Done():
+	}
+	if ctx.Err() != nil && (err == nil || errors.Is(err, ctx.Err())) {
+		err = errors.New("interrupted: the server stopped")
+	}
+
+	close(done)
+	flushing.Wait()
+
+	// Record the outcome even when the server is stopping
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	r.flush(ctx, id, p)
+	if finishErr := r.jobRepo.Finish(ctx, id, err); finishErr != nil {
+		log.Printf("Job %d: %v", id, finishErr)
+	}
+	if err != nil {
+		log.Printf("Job %d failed: %v", id, err)
+	}
+}
+
+// flush stores the progress made since the last flush
+func (r *JobRunner) flush(ctx context.Context, id int64, p *JobProgress) {
+	processed, failed, newErrors := p.take()
+	if err := r.jobRepo.Progress(ctx, id, processed, failed, newErrors); err != nil {
+		log.Printf("Job %d: %v", id, err)
+	}
+}
+
+// JobProgress counts a running job's processed and failed items. It is
+// flushed to the job regularly, so pollers see the job advance.
+type JobProgress struct {
+	mu        sync.Mutex
+	processed int
+	failed    int
+	kept      int // failed items kept, up to repository.MaxJobItemErrors
+	errors    []models.JobItemError
+}
+
+// Done records an item that succeeded
+func (p *JobProgress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+}
+
+// Fail records an item that failed, by its index and ID
+func (p *JobProgress) Fail(item int, id string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+	p.failed++
+	if p.kept < repository.MaxJobItemErrors {
+		p.kept++
+		p.errors = append(p.errors, models.JobItemError{Item: item, ID: id, Error: err.Error()})
+	}
+}
+
+// take returns the counts and the failed items not taken before
+func (p *JobProgress) take() (processed, failed int, newErrors []models.JobItemError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	newErrors, p.errors = p.errors, nil
+	return p.processed, p.failed, newErrors
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobProgress(t *testing.T) {
+	p := &JobProgress{}
+	p.Done()
+	p.Fail(1, "load-1", errors.New("date is required"))
+	p.Done()
+
+	processed, failed, newErrors := p.take()
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, 1, failed)
+	if assert.Len(t, newErrors, 1) {
+		assert.Equal(t, 1, newErrors[0].Item)
+		assert.Equal(t, "load-1", newErrors[0].ID)
+		assert.Equal(t, "date is required", newErrors[0].Error)
+	}
+
+	// Taken errors are not returned again; the counts keep growing
+	p.Done()
+	processed, failed, newErrors = p.take()
+	assert.Equal(t, 4, processed)
+	assert.Equal(t, 1, failed)
+	assert.Empty(t, newErrors)
+}
+
+func TestJobProgressKeepsFirstErrors(t *testing.T) {
+	p := &JobProgress{}
+	kept := 0
+	for i := 0; i < repository.MaxJobItemErrors+10; i++ {
+		p.Fail(i, "", errors.New("failed"))
+		// Flushing partway must not reset the cap
+		if i == 10 {
+			_, _, newErrors := p.take()
+			kept += len(newErrors)
+		}
+	}
+
+	processed, failed, newErrors := p.take()
+	kept += len(newErrors)
+	assert.Equal(t, repository.MaxJobItemErrors+10, processed)
+	assert.Equal(t, repository.MaxJobItemErrors+10, failed, "every failure is counted")
+	assert.Equal(t, repository.MaxJobItemErrors, kept, "only the first failures are kept")
+}
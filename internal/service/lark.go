@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const larkBaseURL = "https://open.larksuite.com/open-apis"
+
+// LarkClient posts messages to Lark chats as the Lark app
+type LarkClient struct {
+	appID     string
+	appSecret string
+	baseURL   string
+	client    *http.Client
+}
+
+func NewLarkClient(appID, appSecret string) *LarkClient {
+	return &LarkClient{
+		appID:     appID,
+		appSecret: appSecret,
+		baseURL:   larkBaseURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used for Lark API calls
+func (c *LarkClient) SetHTTPClient(client *http.Client) {
+	c.client = client
+}
+
+// SetBaseURL points the client at another Lark API host, such as a test
+// server
+func (c *LarkClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// larkResponse is the envelope of every Lark API response
+type larkResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// tenantAccessToken fetches a fresh tenant access token for the app
+func (c *LarkClient) tenantAccessToken(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"app_id":     c.appID,
+		"app_secret": c.appSecret,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.baseURL+"/auth/v3/tenant_access_token/internal", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send token request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var tokenResp struct {
+		Code              int    `json:"code"`
+		Msg               string `json:"msg"`
+		TenantAccessToken string `json:"tenant_access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.Code != 0 {
+		return "", fmt.Errorf("lark token API error: code=%d, msg=%s", tokenResp.Code, tokenResp.Msg)
+	}
+
+	return tokenResp.TenantAccessToken, nil
+}
+
+// call sends an authorized request to the Lark API and decodes its data into
+// out, when set
+func (c *LarkClient) call(ctx context.Context, token string, req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result larkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("lark API returned status %d with an unreadable body: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Code != 0 {
+		return fmt.Errorf("lark API returned status %d: code=%d, msg=%s", resp.StatusCode, result.Code, result.Msg)
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("failed to decode lark response: %w", err)
+		}
+	}
+	return nil
+}
+
+// PostImage uploads a PNG image and posts it to a chat in a rich text
+// message, with the title above it and the paragraphs below. Each paragraph
+// is a list of post elements, such as {"tag": "text", "text": ...} or
+// {"tag": "a", "text": ..., "href": ...}.
+func (c *LarkClient) PostImage(ctx context.Context, chatID, title string, png []byte, paragraphs [][]map[string]string) error {
+	token, err := c.tenantAccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get lark access token: %w", err)
+	}
+
+	imageKey, err := c.uploadImage(ctx, token, png)
+	if err != nil {
+		return err
+	}
+
+	content := [][]map[string]string{{{"tag": "img", "image_key": imageKey}}}
+	content = append(content, paragraphs...)
+	post, err := json.Marshal(map[string]interface{}{
+		"en_us": map[string]interface{}{
+			"title":   title,
+			"content": content,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{
+		"receive_id": chatID,
+		"msg_type":   "post",
+		"content":    string(post),
+		"uuid":       uuid.New().String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.baseURL+"/im/v1/messages?receive_id_type=chat_id", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.call(ctx, token, req, nil); err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	return nil
+}
+
+// SendText sends a text message to a Lark user or chat, with receiveIDType
+// saying what receiveID is: "email", "open_id", "chat_id" and so on
+func (c *LarkClient) SendText(ctx context.Context, receiveIDType, receiveID, text string) error {
+	token, err := c.tenantAccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get lark access token: %w", err)
+	}
+
+	content, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{
+		"receive_id": receiveID,
+		"msg_type":   "text",
+		"content":    string(content),
+		"uuid":       uuid.New().String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.baseURL+"/im/v1/messages?receive_id_type="+url.QueryEscape(receiveIDType), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.call(ctx, token, req, nil); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// uploadImage uploads an image for use in messages and returns its key
+func (c *LarkClient) uploadImage(ctx context.Context, token string, png []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("image_type", "message"); err != nil {
+		return "", fmt.Errorf("failed to build image upload: %w", err)
+	}
+	part, err := form.CreateFormFile("image", "heatmap.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to build image upload: %w", err)
+	}
+	if _, err := part.Write(png); err != nil {
+		return "", fmt.Errorf("failed to build image upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build image upload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/im/v1/images", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create image upload: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var uploaded struct {
+		ImageKey string `json:"image_key"`
+	}
+	if err := c.call(ctx, token, req, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+	return uploaded.ImageKey, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLarkClientPostImage(t *testing.T) {
+	var message map[string]string
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/v3/tenant_access_token/internal":
+			_, _ = io.WriteString(w, `{"code": 0, "tenant_access_token": "t-123"}`)
+		case "/im/v1/images":
+			assert.Equal(t, "Bearer t-123", r.Header.Get("Authorization"))
+			assert.Equal(t, "message", r.FormValue("image_type"))
+			file, _, err := r.FormFile("image")
+			require.NoError(t, err)
+			uploaded, _ = io.ReadAll(file)
+			_, _ = io.WriteString(w, `{"code": 0, "data": {"image_key": "img-1"}}`)
+		case "/im/v1/messages":
+			assert.Equal(t, "chat_id", r.URL.Query().Get("receive_id_type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+			_, _ = io.WriteString(w, `{"code": 0, "data": {}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewLarkClient("app", "secret")
+	c.SetBaseURL(server.URL)
+	err := c.PostImage(context.Background(), "oc_team", "Platform", []byte("png"),
+		[][]map[string]string{{{"tag": "text", "text": "Caption"}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("png"), uploaded)
+	assert.Equal(t, "oc_team", message["receive_id"])
+	assert.Equal(t, "post", message["msg_type"])
+	assert.JSONEq(t, `{"en_us": {"title": "Platform", "content": [
+		[{"tag": "img", "image_key": "img-1"}],
+		[{"tag": "text", "text": "Caption"}]
+	]}}`, message["content"])
+}
+
+func TestLarkClientPostImageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/v3/tenant_access_token/internal" {
+			_, _ = io.WriteString(w, `{"code": 0, "tenant_access_token": "t-123"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"code": 230002, "msg": "bot is not in the chat"}`)
+	}))
+	defer server.Close()
+
+	c := NewLarkClient("app", "secret")
+	c.SetBaseURL(server.URL)
+	err := c.PostImage(context.Background(), "oc_team", "Platform", []byte("png"), nil)
+	assert.ErrorContains(t, err, "bot is not in the chat")
+}
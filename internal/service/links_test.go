@@ -0,0 +1,61 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linkQuery splits a signed link into its path and query
+func linkQuery(t *testing.T, link string) (string, url.Values) {
+	t.Helper()
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	return u.Path, u.Query()
+}
+
+func TestLinkSigner(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	date := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	signer := NewLinkSigner("secret", "https://heatmap.example.com", 24*time.Hour)
+
+	links := signer.Links("alice@example.com", date, now)
+	require.NotNil(t, links)
+	assert.True(t, strings.HasPrefix(links.Day, "https://heatmap.example.com/links/day/alice@example.com/2025-03-12?"))
+	assert.True(t, strings.HasPrefix(links.Settings, "https://heatmap.example.com/links/settings/alice@example.com?"))
+
+	path, q := linkQuery(t, links.Day)
+	assert.Equal(t, "/links/day/alice@example.com/2025-03-12", path)
+	assert.NoError(t, signer.VerifyDay("alice@example.com", "2025-03-12", q.Get("expires"), q.Get("sig"), now))
+	assert.NoError(t, signer.VerifyDay("alice@example.com", "2025-03-12", q.Get("expires"), q.Get("sig"), now.Add(24*time.Hour)),
+		"valid until the TTL is up")
+	assert.ErrorIs(t, signer.VerifyDay("alice@example.com", "2025-03-12", q.Get("expires"), q.Get("sig"), now.Add(25*time.Hour)),
+		ErrInvalidLink, "expired")
+
+	assert.ErrorIs(t, signer.VerifyDay("bob@example.com", "2025-03-12", q.Get("expires"), q.Get("sig"), now),
+		ErrInvalidLink, "another person")
+	assert.ErrorIs(t, signer.VerifyDay("alice@example.com", "2025-03-13", q.Get("expires"), q.Get("sig"), now),
+		ErrInvalidLink, "another day")
+	assert.ErrorIs(t, signer.VerifyDay("alice@example.com", "2025-03-12", "9999999999", q.Get("sig"), now),
+		ErrInvalidLink, "extended expiry")
+	assert.ErrorIs(t, signer.VerifySettings("alice@example.com", q.Get("expires"), q.Get("sig"), now),
+		ErrInvalidLink, "a day link does not open settings")
+	assert.ErrorIs(t, NewLinkSigner("other", "", time.Hour).VerifyDay("alice@example.com", "2025-03-12", q.Get("expires"), q.Get("sig"), now),
+		ErrInvalidLink, "another secret")
+
+	_, q = linkQuery(t, links.Settings)
+	assert.NoError(t, signer.VerifySettings("alice@example.com", q.Get("expires"), q.Get("sig"), now))
+	assert.ErrorIs(t, signer.VerifySettings("alice@example.com", "soon", q.Get("sig"), now), ErrInvalidLink)
+}
+
+func TestLinkSignerDisabled(t *testing.T) {
+	var signer *LinkSigner
+	assert.Nil(t, signer.Links("alice@example.com", time.Now(), time.Now()), "no links without a signer")
+
+	links := NewLinkSigner("secret", "", time.Hour).Links("alice@example.com", time.Now(), time.Now())
+	assert.True(t, strings.HasPrefix(links.Day, "/links/day/"), "relative without a public URL")
+}
@@ -2,56 +2,237 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gti/heatmap-internal/internal/cache"
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
 )
 
+// defaultPersonCapacity is the daily capacity of persons and groups created
+// without one, such as persons auto-created as load assignees, until admins
+// set another
+const defaultPersonCapacity = 5.0
+
+const (
+	defaultSuggestionWeight = 1.0
+	defaultSuggestionLimit  = 5
+)
+
+// maxLoadDays is the most days a single load may span
+const maxLoadDays = 366
+
+const (
+	defaultLoadListDays  = 30
+	defaultLoadListLimit = 100
+	maxLoadListLimit     = 1000
+)
+
+const (
+	// calendarPastDays is how far back an entity's calendar feed reaches
+	calendarPastDays = 90
+	// calendarPageSize is how many loads a calendar feed reads per query
+	calendarPageSize = 500
+)
+
+// ErrInvalidCursor is returned for a list cursor the server did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrUnknownAssignee is returned when auto-creation is disabled and a write
+// names an assignee who is not a known person
+var ErrUnknownAssignee = errors.New("assignee not found")
+
+// ErrBlackout is returned when blackouts are enforced and an upsert assigns
+// someone a load on one of their blackout dates
+var ErrBlackout = errors.New("assignee has a blackout on this date")
+
+// ErrInvalidConfidentialGroup is returned for a confidential load whose group
+// is not a group, or assigned to someone outside it
+var ErrInvalidConfidentialGroup = errors.New("invalid confidential group")
+
 type LoadService struct {
 	loadRepo       *repository.LoadRepository
 	entityRepo     *repository.EntityRepository
+	groupRepo      *repository.GroupRepository
+	blackoutRepo   *repository.BlackoutRepository
+	capacityRepo   *repository.CapacityRepository
 	webhookService *WebhookService
+	renderCache    *cache.RenderCache
+	events         *EventLog
+	customFields   *CustomFieldService
+	settings       *SettingsService
+	weightRules    WeightRules
+	issueMapping   IssueMapping
+
+	// rejectBlackouts fails upserts that assign someone on a blackout date
+	// instead of reporting them as warnings
+	rejectBlackouts bool
+
+	// noAutoCreate fails writes naming unknown assignees instead of creating
+	// them; otherwise each source may create autoCreateLimit a day (0 for
+	// no limit)
+	noAutoCreate    bool
+	autoCreateLimit int
+
+	// scenarioRepo keeps the loads of sources in shadow mode, nil when
+	// shadow mode is not available
+	scenarioRepo *repository.ScenarioRepository
 }
 
 func NewLoadService(
 	loadRepo *repository.LoadRepository,
 	entityRepo *repository.EntityRepository,
+	groupRepo *repository.GroupRepository,
+	blackoutRepo *repository.BlackoutRepository,
+	capacityRepo *repository.CapacityRepository,
 	webhookService *WebhookService,
+	renderCache *cache.RenderCache,
 ) *LoadService {
 	return &LoadService{
 		loadRepo:       loadRepo,
 		entityRepo:     entityRepo,
+		groupRepo:      groupRepo,
+		blackoutRepo:   blackoutRepo,
+		capacityRepo:   capacityRepo,
 		webhookService: webhookService,
+		renderCache:    renderCache,
 	}
 }
 
-// UpsertLoad creates or updates a load with its assignments
-func (s *LoadService) UpsertLoad(ctx context.Context, req *models.UpsertLoadRequest) (int, error) {
-	// Parse date
-	date, err := time.Parse("2006-01-02", req.Date)
+// SetWeightRules sets the rules that weigh upserted assignments without a
+// weight
+func (s *LoadService) SetWeightRules(rules WeightRules) {
+	s.weightRules = rules
+}
+
+// RecordEvents records every load and blackout change in the domain event
+// log
+func (s *LoadService) RecordEvents(events *EventLog) {
+	s.events = events
+}
+
+// CheckCustomFields makes upserts fail with ErrInvalidCustomField when they
+// send a custom field defined for their source with a value of the wrong
+// type
+func (s *LoadService) CheckCustomFields(customFields *CustomFieldService) {
+	s.customFields = customFields
+}
+
+// RejectBlackouts makes upserts that newly assign someone on one of their
+// blackout dates fail with ErrBlackout, rather than succeed with a warning
+func (s *LoadService) RejectBlackouts() {
+	s.rejectBlackouts = true
+}
+
+// UseSettings gives auto-created persons the default capacity from the
+// settings
+func (s *LoadService) UseSettings(settings *SettingsService) {
+	s.settings = settings
+}
+
+// DisableAutoCreation makes writes that name an unknown assignee fail with
+// ErrUnknownAssignee, rather than create them as a person
+func (s *LoadService) DisableAutoCreation() {
+	s.noAutoCreate = true
+}
+
+// LimitAutoCreation caps the persons each source's writes may create per UTC
+// day; past it they fail with repository.ErrAutoCreateQuota
+func (s *LoadService) LimitAutoCreation(perSourcePerDay int) {
+	s.autoCreateLimit = perSourcePerDay
+}
+
+// UseShadowSources keeps the loads upserted by sources in shadow mode in
+// their scenarios instead of in loads
+func (s *LoadService) UseShadowSources(scenarioRepo *repository.ScenarioRepository) {
+	s.scenarioRepo = scenarioRepo
+}
+
+// autoCreate is how writes from source create unknown assignees
+func (s *LoadService) autoCreate(source string) repository.AutoCreate {
+	return repository.AutoCreate{
+		Capacity:   s.settings.DefaultCapacity(),
+		Source:     source,
+		DailyLimit: s.autoCreateLimit,
+	}
+}
+
+// checkAssigneesExist fails with ErrUnknownAssignee naming the emails that
+// are not known entities
+func (s *LoadService) checkAssigneesExist(ctx context.Context, emails []string) error {
+	missing, err := s.entityRepo.Missing(ctx, emails)
 	if err != nil {
-		return 0, fmt.Errorf("invalid date format: %w", err)
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s (auto-creation is disabled)", ErrUnknownAssignee, strings.Join(missing, ", "))
 	}
+	return nil
+}
 
-	// Ensure all assignees exist, create missing ones
-	for _, a := range req.Assignees {
-		exists, err := s.entityRepo.Exists(ctx, a.Email)
+// UpsertLoad creates or updates a load with its assignments. It reports the
+// new assignments that fall on a blackout date, unless those are rejected,
+// and each assignee's resulting load against their capacity.
+func (s *LoadService) UpsertLoad(ctx context.Context, req *models.UpsertLoadRequest) (*models.UpsertLoadResponse, error) {
+	return s.upsertLoad(ctx, req, req, true)
+}
+
+// UpsertLoadByEmployeeID creates or updates a load with its assignments
+// using employee_id. Blackouts and loads are reported as in UpsertLoad.
+func (s *LoadService) UpsertLoadByEmployeeID(ctx context.Context, req *models.UpsertLoadByEmployeeIDRequest) (*models.UpsertLoadResponse, error) {
+	byEmail := models.UpsertLoadRequest{
+		ExternalID:        req.ExternalID,
+		Title:             req.Title,
+		Source:            req.Source,
+		URL:               req.URL,
+		Date:              req.Date,
+		EndDate:           req.EndDate,
+		Spread:            req.Spread,
+		DurationMinutes:   req.DurationMinutes,
+		AllDay:            req.AllDay,
+		Recurrence:        req.Recurrence,
+		CustomFields:      req.CustomFields,
+		Tags:              req.Tags,
+		ConfidentialGroup: req.ConfidentialGroup,
+	}
+
+	// Map employee_id to entity email (ID)
+	byEmail.Assignees = slices.Grow(byEmail.Assignees, len(req.Assignees))[:len(req.Assignees)]
+	for i, a := range req.Assignees {
+		entity, err := s.entityRepo.GetByEmployeeID(ctx, a.EmployeeID)
 		if err != nil {
-			return 0, fmt.Errorf("failed to check assignee: %w", err)
+			return nil, fmt.Errorf("assignee with employee_id %s not found: %w", a.EmployeeID, err)
 		}
-		if !exists {
-			// Auto-create missing person entity with default capacity
-			newEntity := &models.Entity{
-				ID:              a.Email,
-				Title:           a.Email, // Use email as default title
-				Type:            models.EntityTypePerson,
-				DefaultCapacity: 5.0, // Default daily capacity
-			}
-			if err := s.entityRepo.Create(ctx, newEntity); err != nil {
-				return 0, fmt.Errorf("failed to create assignee %s: %w", a.Email, err)
-			}
+		byEmail.Assignees[i].Email = entity.ID
+		byEmail.Assignees[i].Weight = a.Weight
+	}
+
+	return s.upsertLoad(ctx, &byEmail, req, false)
+}
+
+// upsertLoad implements UpsertLoad for assignees known by email, recording
+// event as the upserted load. Missing assignees are auto-created as persons
+// when mayCreate is set, unless that is disabled.
+func (s *LoadService) upsertLoad(ctx context.Context, req *models.UpsertLoadRequest, event interface{}, mayCreate bool) (*models.UpsertLoadResponse, error) {
+	date, endDate, err := parseLoadDates(req.Date, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	recurrence, err := parseRecurrence(req.Recurrence, date, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if s.customFields != nil {
+		if err := s.customFields.Check(ctx, req.Source, req.CustomFields); err != nil {
+			return nil, err
 		}
 	}
 
@@ -60,106 +241,316 @@ func (s *LoadService) UpsertLoad(ctx context.Context, req *models.UpsertLoadRequ
 	source := req.Source
 	url := req.URL
 	load := &models.Load{
-		ExternalID: &externalID,
-		Title:      req.Title,
-		Source:     &source,
-		URL:        &url,
-		Date:       date,
+		ExternalID:   &externalID,
+		Title:        req.Title,
+		Source:       &source,
+		URL:          &url,
+		Date:         date,
+		EndDate:      endDate,
+		Spread:       loadSpread(req.Spread),
+		Recurrence:   recurrence,
+		CustomFields: req.CustomFields,
+		Tags:         cleanTags(req.Tags),
 	}
 
+	defaultWeight := s.weightRules.Weight(req.Source, req.DurationMinutes, req.AllDay)
 	assignments := make([]models.LoadAssignment, 0, len(req.Assignees))
 	for _, a := range req.Assignees {
 		weight := a.Weight
 		if weight == 0 {
-			weight = 1.0 // Default weight
+			weight = defaultWeight
 		}
 		assignments = append(assignments, models.LoadAssignment{
 			PersonEmail: a.Email,
 			Weight:      weight,
 		})
 	}
+	if err := s.checkUpsertScope(ctx, req.ExternalID, assignments); err != nil {
+		return nil, err
+	}
+	if load.ConfidentialGroup, err = s.confidentialGroup(ctx, req.ConfidentialGroup, assignments); err != nil {
+		return nil, err
+	}
+	shadow, err := s.shadowScenario(ctx, req.Source)
+	if err != nil {
+		return nil, err
+	}
+	if shadow != 0 {
+		return s.upsertShadow(ctx, shadow, req.ExternalID, load, assignments)
+	}
+
+	horizon := recurrenceHorizon(utcDate(time.Now()))
+	starts := loadStarts(load, horizon)
+	blackouts, err := s.checkBlackouts(ctx, req.ExternalID, load, starts, assignments)
+	if err != nil {
+		return nil, err
+	}
+	if dry := DryRun(ctx); dry != nil {
+		return nil, s.dryRunUpsert(ctx, dry, req.ExternalID, load, starts, assignments, blackouts)
+	}
 
-	// Upsert the load
-	loadID, err := s.loadRepo.UpsertByExternalID(ctx, load, assignments)
+	// Upsert the load with its recurrence, auto-creating missing assignees
+	// as persons unless that is disabled
+	expansion := &repository.Expansion{Occurrences: starts[1:], Through: horizon}
+	var loadID int
+	var previous []string
+	var created bool
+	switch {
+	case !mayCreate:
+		loadID, previous, created, err = s.loadRepo.UpsertByExternalID(ctx, load, assignments, expansion)
+	case s.noAutoCreate:
+		emails := make([]string, 0, len(assignments))
+		for _, a := range assignments {
+			emails = append(emails, a.PersonEmail)
+		}
+		if err := s.checkAssigneesExist(ctx, emails); err != nil {
+			return nil, err
+		}
+		loadID, previous, created, err = s.loadRepo.UpsertByExternalID(ctx, load, assignments, expansion)
+	default:
+		loadID, previous, created, err = s.loadRepo.UpsertCreatingAssignees(ctx, load, assignments, expansion, s.autoCreate(req.Source))
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to upsert load: %w", err)
+		return nil, fmt.Errorf("failed to upsert load: %w", err)
 	}
+	s.assigneesChanged(ctx, models.EventLoadUpserted, previous, assignments,
+		map[string]interface{}{"load_id": loadID, "load": event})
 
 	// Trigger webhook alerts for affected persons (in background)
-	for _, a := range req.Assignees {
-		s.webhookService.CheckAndAlert(ctx, a.Email, date)
+	load.ID = loadID
+	if created {
+		s.webhookService.NotifyLoadCreated(ctx, load, assignments)
 	}
+	days := coveredDays(load, starts)
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		s.webhookService.CheckAndAlertDays(ctx, a.PersonEmail, days)
+		emails = append(emails, a.PersonEmail)
+	}
+	s.webhookService.CheckGroupsAndAlert(ctx, emails, days)
+	s.webhookService.NotifyConflicts(ctx, load, emails, days)
 
-	return loadID, nil
+	return &models.UpsertLoadResponse{
+		Success:   true,
+		LoadID:    loadID,
+		Blackouts: blackouts,
+		Assignees: s.assigneeLoads(ctx, coveredDays(load, starts[:1]), emails),
+	}, nil
 }
 
-// UpsertLoadByEmployeeID creates or updates a load with its assignments using employee_id
-func (s *LoadService) UpsertLoadByEmployeeID(ctx context.Context, req *models.UpsertLoadByEmployeeIDRequest) (int, error) {
-	// Parse date
-	date, err := time.Parse("2006-01-02", req.Date)
+// checkUpsertScope checks a group-scoped API key may write the load a source
+// knows as externalID, as it is now and with the new assignments
+func (s *LoadService) checkUpsertScope(ctx context.Context, externalID string, assignments []models.LoadAssignment) error {
+	if _, ok := KeyScope(ctx); !ok {
+		return nil
+	}
+
+	current, err := s.loadRepo.GetAffectedPersonsByExternalID(ctx, externalID)
 	if err != nil {
-		return 0, fmt.Errorf("invalid date format: %w", err)
+		return err
 	}
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		emails = append(emails, a.PersonEmail)
+	}
+	return s.checkLoadScope(ctx, current, emails)
+}
 
-	// Map employee_id to entity email (ID)
-	type assigneeMapping struct {
-		employeeID string
-		email      string
-		weight     float64
+// confidentialGroup checks a confidential load's group is a group that all
+// its assignees are members of, returning it, or nil for a load anyone may see
+func (s *LoadService) confidentialGroup(ctx context.Context, groupID string, assignments []models.LoadAssignment) (*string, error) {
+	groupID = strings.TrimSpace(groupID)
+	if groupID == "" {
+		return nil, nil
 	}
-	assigneeMappings := make([]assigneeMapping, 0, len(req.Assignees))
 
-	// Look up each assignee by employee_id
-	for _, a := range req.Assignees {
-		entity, err := s.entityRepo.GetByEmployeeID(ctx, a.EmployeeID)
-		if err != nil {
-			return 0, fmt.Errorf("assignee with employee_id %s not found: %w", a.EmployeeID, err)
+	group, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityNotFound) {
+			return nil, fmt.Errorf("%w: group %s not found", ErrInvalidConfidentialGroup, groupID)
 		}
+		return nil, err
+	}
+	if group.Type != models.EntityTypeGroup {
+		return nil, fmt.Errorf("%w: %s is not a group", ErrInvalidConfidentialGroup, groupID)
+	}
 
-		weight := a.Weight
-		if weight == 0 {
-			weight = 1.0 // Default weight
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		emails = append(emails, a.PersonEmail)
+	}
+	outside, err := s.groupRepo.NotMembers(ctx, []string{groupID}, emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(outside) > 0 {
+		return nil, fmt.Errorf("%w: %s not in group %s", ErrInvalidConfidentialGroup, strings.Join(outside, ", "), groupID)
+	}
+	return &groupID, nil
+}
+
+// checkBlackouts finds the upsert's new assignments that fall on a blackout
+// date on any occurrence of the load, starting on starts, failing with
+// ErrBlackout when blackouts are rejected
+func (s *LoadService) checkBlackouts(ctx context.Context, externalID string, load *models.Load, starts []time.Time, assignments []models.LoadAssignment) ([]models.BlackoutConflict, error) {
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		emails = append(emails, a.PersonEmail)
+	}
+
+	span := int(lastDay(load).Sub(load.Date).Hours() / 24)
+	conflicts, err := s.blackoutRepo.FindConflicts(ctx, externalID, starts, span, emails)
+	if err != nil {
+		return nil, err
+	}
+	if s.rejectBlackouts && len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBlackout, describeBlackouts(conflicts))
+	}
+
+	return conflicts, nil
+}
+
+// assigneeLoads reports each assignee's total load against their capacity
+// on the given days, for callers to act on overloads at once rather than
+// wait for the webhook alerts. Reading them is best effort: the upsert has
+// already been applied, so a failure is logged and nothing is reported.
+func (s *LoadService) assigneeLoads(ctx context.Context, days []time.Time, emails []string) []models.AssigneeDayLoad {
+	if len(days) == 0 {
+		return nil
+	}
+	first, last := days[0], days[len(days)-1]
+
+	result := make([]models.AssigneeDayLoad, 0, len(emails)*len(days))
+	for _, email := range emails {
+		loads, err := s.loadRepo.GetPersonLoadForDateRange(ctx, email, first, last, nil, "")
+		if err != nil {
+			log.Printf("Failed to report load of %s after upsert: %v", email, err)
+			return nil
 		}
+		capacities, err := s.capacityRepo.GetCapacitiesForRange(ctx, email, first, last)
+		if err != nil {
+			log.Printf("Failed to report capacity of %s after upsert: %v", email, err)
+			return nil
+		}
+		for _, day := range days {
+			load, capacity := loads[day], capacities[day]
+			result = append(result, models.AssigneeDayLoad{
+				PersonEmail: email,
+				Date:        day.Format("2006-01-02"),
+				Load:        load,
+				Capacity:    capacity,
+				Overloaded:  load > capacity,
+			})
+		}
+	}
+	return result
+}
 
-		assigneeMappings = append(assigneeMappings, assigneeMapping{
-			employeeID: a.EmployeeID,
-			email:      entity.ID,
-			weight:     weight,
-		})
+// parseLoadDates parses an upserted load's first day and, for loads spanning
+// several days, its last; the last day is nil for single-day loads
+func parseLoadDates(date, endDate string) (time.Time, *time.Time, error) {
+	start, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	if endDate == "" {
+		return start, nil, nil
 	}
 
-	// Build load and assignments
-	externalID := req.ExternalID
-	source := req.Source
-	url := req.URL
-	load := &models.Load{
-		ExternalID: &externalID,
-		Title:      req.Title,
-		Source:     &source,
-		URL:        &url,
-		Date:       date,
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidDate)
 	}
+	switch {
+	case end.Before(start):
+		return time.Time{}, nil, fmt.Errorf("%w: end_date is before date", ErrInvalidDate)
+	case end.After(start.AddDate(0, 0, maxLoadDays-1)):
+		return time.Time{}, nil, fmt.Errorf("%w: a load spans at most %d days", ErrInvalidDate, maxLoadDays)
+	case end.Equal(start):
+		return start, nil, nil
+	}
+	return start, &end, nil
+}
 
-	assignments := make([]models.LoadAssignment, 0, len(assigneeMappings))
-	for _, a := range assigneeMappings {
-		assignments = append(assignments, models.LoadAssignment{
-			PersonEmail: a.email,
-			Weight:      a.weight,
-		})
+// loadSpread returns the spread named in an upsert, even by default
+func loadSpread(spread string) models.LoadSpread {
+	if spread == "" {
+		return models.LoadSpreadEven
 	}
+	return models.LoadSpread(spread)
+}
 
-	// Upsert the load
-	loadID, err := s.loadRepo.UpsertByExternalID(ctx, load, assignments)
+// lastDay returns the last day a load covers
+func lastDay(load *models.Load) time.Time {
+	if load.EndDate != nil {
+		return *load.EndDate
+	}
+	return load.Date
+}
+
+// describeBlackouts lists blackout conflicts as "person on date: reason"
+func describeBlackouts(conflicts []models.BlackoutConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		part := fmt.Sprintf("%s on %s: %s", c.PersonEmail, c.Date, c.Reason)
+		if c.EntityID != c.PersonEmail {
+			part += " (" + c.EntityID + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ListBlackouts returns an entity's current and upcoming blackouts
+func (s *LoadService) ListBlackouts(ctx context.Context, entityID string) ([]models.BlackoutDate, error) {
+	if _, err := s.entityRepo.GetByID(ctx, entityID); err != nil {
+		return nil, err
+	}
+	return s.blackoutRepo.List(ctx, entityID, utcDate(time.Now()))
+}
+
+// AddBlackout declares a blackout for an entity; an empty end date makes it
+// a single day
+func (s *LoadService) AddBlackout(ctx context.Context, entityID string, req *models.CreateBlackoutRequest) (*models.BlackoutDate, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
-		return 0, fmt.Errorf("failed to upsert load: %w", err)
+		return nil, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	end := start
+	if req.EndDate != "" {
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidDate)
+		}
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end_date is before start_date", ErrInvalidDate)
 	}
 
-	// Trigger webhook alerts for affected persons (in background)
-	for _, a := range assigneeMappings {
-		s.webhookService.CheckAndAlert(ctx, a.email, date)
+	if _, err := s.entityRepo.GetByID(ctx, entityID); err != nil {
+		return nil, err
 	}
+	if err := s.CheckEntityScope(ctx, entityID); err != nil {
+		return nil, err
+	}
+	blackout, err := s.blackoutRepo.Create(ctx, entityID, start, end, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Record(ctx, models.EventBlackoutAdded, "", []string{entityID}, blackout)
+	return blackout, nil
+}
 
-	return loadID, nil
+// DeleteBlackout removes one of an entity's blackouts
+func (s *LoadService) DeleteBlackout(ctx context.Context, entityID string, id int) error {
+	if err := s.CheckEntityScope(ctx, entityID); err != nil {
+		return err
+	}
+	if err := s.blackoutRepo.Delete(ctx, entityID, id); err != nil {
+		return err
+	}
+	s.events.Record(ctx, models.EventBlackoutDeleted, "", []string{entityID}, map[string]interface{}{"id": id})
+	return nil
 }
 
 // GetLoadsByDateRange returns loads within a date range
@@ -167,9 +558,351 @@ func (s *LoadService) GetLoadsByDateRange(ctx context.Context, start, end time.T
 	return s.loadRepo.GetLoadsByDateRange(ctx, start, end)
 }
 
+// GetLoadsPage returns up to limit loads within a date range that match the
+// filter after the cursor; pass the previous page's Next to continue
+func (s *LoadService) GetLoadsPage(ctx context.Context, start, end time.Time, filter repository.LoadFilter, after repository.LoadCursor, limit int) (*repository.LoadPage, error) {
+	return s.loadRepo.GetLoadsPageByDateRange(ctx, start, end, filter, after, limit)
+}
+
+// CalendarLoads returns the loads of an entity's calendar feed: those
+// assigned to the person, or to any member of the group, from 90 days ago
+// through the recurrence horizon
+func (s *LoadService) CalendarLoads(ctx context.Context, entity *models.Entity, today time.Time) ([]models.LoadWithAssignments, error) {
+	filter := repository.LoadFilter{PersonEmail: entity.ID}
+	if entity.Type == models.EntityTypeGroup {
+		filter = repository.LoadFilter{GroupID: entity.ID}
+	}
+
+	start := today.AddDate(0, 0, -calendarPastDays)
+	end := recurrenceHorizon(today)
+	loads := []models.LoadWithAssignments{}
+	var after repository.LoadCursor
+	for {
+		page, err := s.loadRepo.GetLoadsPageByDateRange(ctx, start, end, filter, after, calendarPageSize)
+		if err != nil {
+			return nil, err
+		}
+		loads = append(loads, page.Loads...)
+		if page.Next == nil {
+			return loads, nil
+		}
+		after = *page.Next
+	}
+}
+
+// ListLoads returns a page of loads on any day from fromStr to toStr that
+// match the filter, continuing after cursor when set. From defaults to today
+// and to to 30 days after from; limit defaults to 100 and is capped at 1000.
+func (s *LoadService) ListLoads(ctx context.Context, fromStr, toStr string, filter repository.LoadFilter, cursor string, limit int) (*models.LoadList, error) {
+	from, to, err := parseListRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var after repository.LoadCursor
+	if cursor != "" {
+		if after, err = decodeLoadCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	if limit <= 0 {
+		limit = defaultLoadListLimit
+	}
+	if limit > maxLoadListLimit {
+		limit = maxLoadListLimit
+	}
+
+	page, err := s.loadRepo.GetLoadsPageByDateRange(ctx, from, to, filter, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.LoadList{
+		From:  from.Format("2006-01-02"),
+		To:    to.Format("2006-01-02"),
+		Loads: page.Loads,
+	}
+	if page.Next != nil {
+		list.NextCursor = encodeLoadCursor(*page.Next)
+	}
+	return list, nil
+}
+
+// parseListRange parses the date range of a list, from today and to 30 days
+// after from by default
+func parseListRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from, err := parseDateOrToday(fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	to := from.AddDate(0, 0, defaultLoadListDays)
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDate)
+		}
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must not be after to", ErrInvalidDate)
+	}
+	return from, to, nil
+}
+
+// encodeLoadCursor turns a page position into an opaque token for clients
+func encodeLoadCursor(c repository.LoadCursor) string {
+	raw := c.Date.Format("2006-01-02") + "/" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeLoadCursor reverses encodeLoadCursor
+func decodeLoadCursor(token string) (repository.LoadCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return repository.LoadCursor{}, ErrInvalidCursor
+	}
+	dateStr, idStr, ok := strings.Cut(string(raw), "/")
+	if !ok {
+		return repository.LoadCursor{}, ErrInvalidCursor
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return repository.LoadCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		return repository.LoadCursor{}, ErrInvalidCursor
+	}
+	return repository.LoadCursor{Date: date, ID: id}, nil
+}
+
+// StreamLoads calls fn for each load within a date range without holding the
+// range in memory, for exports
+func (s *LoadService) StreamLoads(ctx context.Context, start, end time.Time, fn func(models.LoadWithAssignments) error) error {
+	return s.loadRepo.StreamLoadsByDateRange(ctx, start, end, fn)
+}
+
 // DeleteLoad deletes a load by ID
 func (s *LoadService) DeleteLoad(ctx context.Context, id int) error {
-	return s.loadRepo.Delete(ctx, id)
+	persons, err := s.loadRepo.GetAffectedPersons(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get affected persons: %w", err)
+	}
+
+	if err := s.loadRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.renderCache.Invalidate(ctx, persons...)
+	s.events.Record(ctx, models.EventLoadDeleted, "", persons, map[string]interface{}{"load_id": id})
+	return nil
+}
+
+// DeleteLoadByExternalID deletes the load a source system knows as
+// externalID, propagating deletions such as a cancelled calendar event. The
+// assignees' capacity is re-checked without it and reported by webhook.
+func (s *LoadService) DeleteLoadByExternalID(ctx context.Context, externalID string) (*models.DeletedLoad, error) {
+	if _, ok := KeyScope(ctx); ok {
+		current, err := s.loadRepo.GetAffectedPersonsByExternalID(ctx, externalID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkScope(ctx, current); err != nil {
+			return nil, err
+		}
+	}
+
+	if dry := DryRun(ctx); dry != nil {
+		return nil, s.dryRunDelete(ctx, dry, externalID)
+	}
+
+	deleted, err := s.loadRepo.DeleteByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.loadDeleted(ctx, deleted)
+	return &result, nil
+}
+
+// dryRunDelete fills dry with the load a deletion would remove and its
+// assignees' load without it
+func (s *LoadService) dryRunDelete(ctx context.Context, dry *models.DryRunResult, externalID string) error {
+	id, err := s.loadRepo.GetIDByExternalID(ctx, externalID)
+	if err != nil {
+		return err
+	}
+	current, err := s.loadRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	dry.Action = models.DryRunDelete
+	dry.Result = current
+	return s.dryRunAssignees(ctx, dry, current, nil, nil)
+}
+
+// loadDeleted refreshes what depended on a deleted load and reports it
+func (s *LoadService) loadDeleted(ctx context.Context, deleted *models.LoadWithAssignments) models.DeletedLoad {
+	assignees := make([]string, 0, len(deleted.Assignments))
+	for _, a := range deleted.Assignments {
+		assignees = append(assignees, a.PersonEmail)
+	}
+	s.renderCache.Invalidate(ctx, assignees...)
+	s.events.Record(ctx, models.EventLoadDeleted, "", assignees, deleted)
+	s.webhookService.NotifyLoadDeleted(ctx, deleted)
+
+	result := models.DeletedLoad{
+		LoadID:    deleted.Load.ID,
+		Date:      deleted.Load.Date.Format("2006-01-02"),
+		Deleted:   true,
+		Assignees: assignees,
+	}
+	if deleted.Load.ExternalID != nil {
+		result.ExternalID = *deleted.Load.ExternalID
+	}
+	return result
+}
+
+// FreshnessWindows is how long a source may go without upserting one of its
+// loads again before the load counts as stale
+type FreshnessWindows struct {
+	Default time.Duration            // for sources not listed, 0 never goes stale
+	Sources map[string]time.Duration // by load source, 0 never goes stale
+}
+
+// staleCutoffs returns, for each source and for the rest, the last upsert
+// time that keeps a load fresh at now; zero for loads that never go stale
+func staleCutoffs(now time.Time, windows FreshnessWindows) (map[string]time.Time, time.Time) {
+	cutoff := func(window time.Duration) time.Time {
+		if window <= 0 {
+			return time.Time{}
+		}
+		return now.Add(-window)
+	}
+
+	sources := make(map[string]time.Time, len(windows.Sources))
+	for source, window := range windows.Sources {
+		sources[source] = cutoff(window)
+	}
+	return sources, cutoff(windows.Default)
+}
+
+// RunStaleLoadCheck flags upcoming imported loads that their source has not
+// upserted within its freshness window, such as calendar events deleted
+// without the deletion reaching us. Flagged loads stay until reviewed and
+// removed with DeleteStaleLoads, and a later upsert clears the flag. It checks
+// every interval until ctx is cancelled.
+func (s *LoadService) RunStaleLoadCheck(ctx context.Context, interval time.Duration, windows FreshnessWindows) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		sources, rest := staleCutoffs(now, windows)
+		flagged, err := s.loadRepo.MarkStale(ctx, sources, rest, utcDate(now))
+		if err != nil {
+			log.Printf("Stale load check: %v", err)
+			continue
+		}
+		if flagged > 0 {
+			log.Printf("Stale load check: flagged %d loads for review", flagged)
+		}
+	}
+}
+
+// GetStaleLoadReport lists the upcoming loads flagged stale, optionally only
+// those from source, with a count per source
+func (s *LoadService) GetStaleLoadReport(ctx context.Context, source string) (*models.StaleLoadReport, error) {
+	loads, err := s.loadRepo.GetStaleLoads(ctx, source, utcDate(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return &models.StaleLoadReport{
+		Sources: summarizeStaleLoads(loads),
+		Loads:   loads,
+	}, nil
+}
+
+// summarizeStaleLoads counts stale loads per source, in source order
+func summarizeStaleLoads(loads []models.StaleLoad) []models.StaleSourceSummary {
+	bySource := make(map[string]*models.StaleSourceSummary)
+	for _, l := range loads {
+		source := ""
+		if l.Load.Source != nil {
+			source = *l.Load.Source
+		}
+		summary, ok := bySource[source]
+		if !ok {
+			summary = &models.StaleSourceSummary{Source: source, LastSeenAt: l.LastSeenAt}
+			bySource[source] = summary
+		}
+		summary.Loads++
+		if l.LastSeenAt.Before(summary.LastSeenAt) {
+			summary.LastSeenAt = l.LastSeenAt
+		}
+	}
+
+	summaries := make([]models.StaleSourceSummary, 0, len(bySource))
+	for _, summary := range bySource {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Source < summaries[j].Source
+	})
+	return summaries
+}
+
+// DeleteStaleLoads removes reviewed loads that are still flagged stale. Loads
+// their source upserted again since the review, or that are gone or past, are
+// skipped, so a report acted on late cannot remove live work. So are loads
+// outside a group-scoped API key's groups.
+func (s *LoadService) DeleteStaleLoads(ctx context.Context, ids []int) (*models.DeleteStaleLoadsResponse, error) {
+	inScope := ids
+	if _, ok := KeyScope(ctx); ok {
+		inScope = make([]int, 0, len(ids))
+		for _, id := range ids {
+			current, err := s.loadRepo.GetAffectedPersons(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			err = s.checkScope(ctx, current)
+			if errors.Is(err, ErrOutOfScope) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			inScope = append(inScope, id)
+		}
+	}
+
+	deleted, err := s.loadRepo.DeleteStale(ctx, inScope, utcDate(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.DeleteStaleLoadsResponse{
+		Deleted: make([]models.DeletedLoad, 0, len(deleted)),
+		Skipped: []int{},
+	}
+	seen := make(map[int]bool, len(ids))
+	for i := range deleted {
+		resp.Deleted = append(resp.Deleted, s.loadDeleted(ctx, &deleted[i]))
+		seen[deleted[i].Load.ID] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			resp.Skipped = append(resp.Skipped, id)
+			seen[id] = true
+		}
+	}
+
+	return resp, nil
 }
 
 // AddAssignees adds one or more assignees to an existing load
@@ -180,24 +913,16 @@ func (s *LoadService) AddAssignees(ctx context.Context, loadID int, req *models.
 		return fmt.Errorf("load not found: %w", err)
 	}
 
-	// Ensure all assignees exist, create missing ones
+	current := make([]string, 0, len(load.Assignments))
+	for _, a := range load.Assignments {
+		current = append(current, a.PersonEmail)
+	}
+	added := make([]string, 0, len(req.Assignees))
 	for _, a := range req.Assignees {
-		exists, err := s.entityRepo.Exists(ctx, a.Email)
-		if err != nil {
-			return fmt.Errorf("failed to check assignee: %w", err)
-		}
-		if !exists {
-			// Auto-create missing person entity with default capacity
-			newEntity := &models.Entity{
-				ID:              a.Email,
-				Title:           a.Email, // Use email as default title
-				Type:            models.EntityTypePerson,
-				DefaultCapacity: 5.0, // Default daily capacity
-			}
-			if err := s.entityRepo.Create(ctx, newEntity); err != nil {
-				return fmt.Errorf("failed to create assignee %s: %w", a.Email, err)
-			}
-		}
+		added = append(added, a.Email)
+	}
+	if err := s.checkLoadScope(ctx, current, added); err != nil {
+		return err
 	}
 
 	// Build assignments
@@ -205,7 +930,7 @@ func (s *LoadService) AddAssignees(ctx context.Context, loadID int, req *models.
 	for _, a := range req.Assignees {
 		weight := a.Weight
 		if weight == 0 {
-			weight = 1.0 // Default weight
+			weight = defaultLoadWeight
 		}
 		assignments = append(assignments, models.LoadAssignment{
 			LoadID:      loadID,
@@ -214,15 +939,52 @@ func (s *LoadService) AddAssignees(ctx context.Context, loadID int, req *models.
 		})
 	}
 
+	if dry := DryRun(ctx); dry != nil {
+		created, err := s.dryRunCreated(ctx, added)
+		if err != nil {
+			return err
+		}
+		// Assignees already on the load keep one assignment with the new weight
+		merged := append([]models.LoadAssignment(nil), assignments...)
+		for _, a := range load.Assignments {
+			if !slices.Contains(added, a.PersonEmail) {
+				merged = append(merged, a)
+			}
+		}
+		dry.Action = models.DryRunUpdate
+		dry.Result = models.LoadWithAssignments{Load: load.Load, Assignments: merged}
+		dry.AutoCreated = created
+		return s.dryRunAssignees(ctx, dry, load, merged, created)
+	}
+
+	// Ensure all assignees exist, creating missing ones against the quota
+	// of the load's source
+	if s.noAutoCreate {
+		if err := s.checkAssigneesExist(ctx, added); err != nil {
+			return err
+		}
+	} else {
+		source := ""
+		if load.Load.Source != nil {
+			source = *load.Load.Source
+		}
+		if err := s.entityRepo.CreatePersons(ctx, added, s.autoCreate(source)); err != nil {
+			return fmt.Errorf("failed to create assignees: %w", err)
+		}
+	}
+
 	// Add assignments
 	err = s.loadRepo.AddAssignees(ctx, loadID, assignments)
 	if err != nil {
 		return fmt.Errorf("failed to add assignees: %w", err)
 	}
+	s.assigneesChanged(ctx, models.EventLoadAssigneesAdded, nil, assignments,
+		map[string]interface{}{"load_id": loadID, "assignees": req.Assignees})
 
 	// Trigger webhook alerts for affected persons (in background)
+	days := coveredDays(&load.Load, loadStarts(&load.Load, recurrenceHorizon(utcDate(time.Now()))))
 	for _, a := range req.Assignees {
-		s.webhookService.CheckAndAlert(ctx, a.Email, load.Load.Date)
+		s.webhookService.CheckAndAlertDays(ctx, a.Email, days)
 	}
 
 	return nil
@@ -231,16 +993,476 @@ func (s *LoadService) AddAssignees(ctx context.Context, loadID int, req *models.
 // RemoveAssignee removes a specific assignee from a load
 func (s *LoadService) RemoveAssignee(ctx context.Context, loadID int, personEmail string) error {
 	// Verify the load exists
-	_, err := s.loadRepo.GetByID(ctx, loadID)
+	load, err := s.loadRepo.GetByID(ctx, loadID)
 	if err != nil {
 		return fmt.Errorf("load not found: %w", err)
 	}
+	current := make([]string, 0, len(load.Assignments))
+	for _, a := range load.Assignments {
+		current = append(current, a.PersonEmail)
+	}
+	if err := s.checkScope(ctx, current); err != nil {
+		return err
+	}
+
+	if dry := DryRun(ctx); dry != nil {
+		remaining := make([]models.LoadAssignment, 0, len(load.Assignments))
+		for _, a := range load.Assignments {
+			if a.PersonEmail != personEmail {
+				remaining = append(remaining, a)
+			}
+		}
+		if len(remaining) == len(load.Assignments) {
+			return fmt.Errorf("failed to remove assignee: %w", repository.ErrAssignmentNotFound)
+		}
+		dry.Action = models.DryRunUpdate
+		dry.Result = models.LoadWithAssignments{Load: load.Load, Assignments: remaining}
+		return s.dryRunAssignees(ctx, dry, load, remaining, nil)
+	}
 
 	// Remove the assignee
 	err = s.loadRepo.RemoveAssignee(ctx, loadID, personEmail)
 	if err != nil {
 		return fmt.Errorf("failed to remove assignee: %w", err)
 	}
+	s.renderCache.Invalidate(ctx, personEmail)
+	s.events.Record(ctx, models.EventLoadAssigneeRemoved, "", []string{personEmail},
+		map[string]interface{}{"load_id": loadID, "person_email": personEmail})
 
 	return nil
 }
+
+// ReassignLoads returns a job moving a person's loads that last through the
+// request's from date, today by default, to another person. The new assignee
+// must be an active person; loads they already share keep one assignment with
+// both weights.
+func (s *LoadService) ReassignLoads(ctx context.Context, req *models.ReassignLoadsRequest) (*BulkJob, error) {
+	from, err := parseDateOrToday(req.FromDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from_date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	if _, err := s.entityRepo.GetByID(ctx, req.From); err != nil {
+		return nil, err
+	}
+	to, err := s.entityRepo.GetByID(ctx, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if to.Type != models.EntityTypePerson {
+		return nil, repository.ErrNotAPerson
+	}
+	if to.ArchivedAt != nil {
+		return nil, repository.ErrEntityArchived
+	}
+
+	loadIDs, err := s.loadRepo.ListAssignedLoadIDs(ctx, req.From, from, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BulkJob{
+		Kind:  models.JobKindReassign,
+		Total: len(loadIDs),
+		Run: func(ctx context.Context, p *JobProgress) error {
+			horizon := recurrenceHorizon(utcDate(time.Now()))
+			for i, loadID := range loadIDs {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err := s.reassignLoad(ctx, loadID, req.From, req.To, horizon); err != nil {
+					p.Fail(i, strconv.Itoa(loadID), err)
+					continue
+				}
+				p.Done()
+			}
+			return nil
+		},
+	}, nil
+}
+
+func (s *LoadService) reassignLoad(ctx context.Context, loadID int, from, to string, horizon time.Time) error {
+	if err := s.loadRepo.Reassign(ctx, loadID, from, to); err != nil {
+		return err
+	}
+	s.renderCache.Invalidate(ctx, from, to)
+	s.events.Record(ctx, models.EventLoadReassigned, "", []string{from, to},
+		map[string]interface{}{"load_id": loadID, "from": from, "to": to})
+
+	// The load has moved; one that cannot be read back only misses its alerts
+	if load, err := s.loadRepo.GetByID(ctx, loadID); err == nil {
+		s.webhookService.CheckAndAlertDays(ctx, to, coveredDays(&load.Load, loadStarts(&load.Load, horizon)))
+	}
+	return nil
+}
+
+// AcknowledgeLoad records that an assignee has seen a load assigned to them
+func (s *LoadService) AcknowledgeLoad(ctx context.Context, loadID int, personEmail string) (*models.AcknowledgeLoadResponse, error) {
+	acknowledgedAt, err := s.loadRepo.Acknowledge(ctx, loadID, personEmail)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.AcknowledgeLoadResponse{
+		LoadID:         loadID,
+		PersonEmail:    personEmail,
+		AcknowledgedAt: acknowledgedAt,
+	}
+	s.events.Record(ctx, models.EventLoadAcknowledged, personEmail, []string{personEmail}, resp)
+	return resp, nil
+}
+
+// RecordActual records the effort a person actually spent on a past load
+// assigned to them
+func (s *LoadService) RecordActual(ctx context.Context, loadID int, personEmail string, actual float64) (*models.LoadActual, error) {
+	recorded, err := s.loadRepo.RecordActual(ctx, loadID, personEmail, actual, utcDate(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	s.events.Record(ctx, models.EventLoadActualRecorded, personEmail, []string{personEmail}, recorded)
+	return recorded, nil
+}
+
+// ListUnacknowledged returns a person's upcoming loads they have not yet
+// acknowledged
+func (s *LoadService) ListUnacknowledged(ctx context.Context, personEmail string) ([]models.PendingAcknowledgment, error) {
+	return s.loadRepo.GetPendingAcknowledgments(ctx, personEmail, utcDate(time.Now()))
+}
+
+// RunAcknowledgmentReminders reminds assignees, through the webhook, of
+// upcoming loads weighing at least minWeight that they have not acknowledged
+// within after of being assigned. It checks every interval until ctx is
+// cancelled, and reminds about each assignment once.
+func (s *LoadService) RunAcknowledgmentReminders(ctx context.Context, interval, after time.Duration, minWeight float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		due, err := s.loadRepo.GetDueAcknowledgmentReminders(ctx, utcDate(now), now.Add(-after), minWeight)
+		if err != nil {
+			log.Printf("Acknowledgment reminders: %v", err)
+			continue
+		}
+		for _, p := range due {
+			if err := s.webhookService.RemindUnacknowledged(ctx, p); err != nil {
+				log.Printf("Acknowledgment reminders: failed to remind %s of load %d: %v", p.PersonEmail, p.LoadID, err)
+				continue
+			}
+			if err := s.loadRepo.MarkReminded(ctx, p.LoadID, p.PersonEmail); err != nil {
+				log.Printf("Acknowledgment reminders: %v", err)
+			}
+		}
+	}
+}
+
+// RunAnomalyCheck alerts, through the webhook, on person-days from today on
+// whose load is over factor times the person's recent average, even under
+// capacity, such as after an import that multiplied everyone's load. It
+// checks every interval until ctx is cancelled, and alerts on each day once.
+func (s *LoadService) RunAnomalyCheck(ctx context.Context, interval time.Duration, factor float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		anomalies, err := s.loadRepo.GetLoadAnomalies(ctx, utcDate(time.Now()), factor)
+		if err != nil {
+			log.Printf("Anomaly check: %v", err)
+			continue
+		}
+		for _, a := range anomalies {
+			if err := s.webhookService.NotifyAnomaly(ctx, a); err != nil {
+				log.Printf("Anomaly check: failed to alert on %s on %s: %v", a.PersonEmail, a.Date.Format("2006-01-02"), err)
+				continue
+			}
+			if err := s.loadRepo.MarkAnomalyAlerted(ctx, a); err != nil {
+				log.Printf("Anomaly check: %v", err)
+			}
+		}
+	}
+}
+
+// assigneesChanged drops cached heatmaps for everyone a load write touched,
+// the assignees it had before and the ones it has now, and records the write
+// against them
+func (s *LoadService) assigneesChanged(ctx context.Context, eventType models.DomainEventType, previous []string, assignments []models.LoadAssignment, payload interface{}) {
+	persons := previous
+	for _, a := range assignments {
+		persons = append(persons, a.PersonEmail)
+	}
+	s.renderCache.Invalidate(ctx, persons...)
+	s.events.Record(ctx, eventType, "", persons, payload)
+}
+
+// SuggestAssignees ranks the people with a skill by how much capacity they
+// have left on a date, leaving out anyone the load's weight would overload.
+func (s *LoadService) SuggestAssignees(ctx context.Context, req *models.SuggestAssigneeRequest) (*models.SuggestAssigneeResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+
+	weight := req.Weight
+	if weight == 0 {
+		weight = defaultSuggestionWeight
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSuggestionLimit
+	}
+	skill := strings.ToLower(strings.TrimSpace(req.Skill))
+
+	people, err := s.loadRepo.GetSkillAvailability(ctx, skill, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SuggestAssigneeResponse{
+		Date:        req.Date,
+		Skill:       skill,
+		Weight:      weight,
+		Suggestions: rankSuggestions(people, weight, limit),
+	}, nil
+}
+
+// rankSuggestions keeps the people with at least weight remaining, most
+// remaining first, ties broken by email so the order is stable
+func rankSuggestions(people []models.AssigneeSuggestion, weight float64, limit int) []models.AssigneeSuggestion {
+	ranked := make([]models.AssigneeSuggestion, 0, len(people))
+	for _, p := range people {
+		if p.Remaining >= weight {
+			ranked = append(ranked, p)
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Remaining != ranked[j].Remaining {
+			return ranked[i].Remaining > ranked[j].Remaining
+		}
+		return ranked[i].Email < ranked[j].Email
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// LeastLoaded ranks the active members of a group by the capacity they would
+// have left on a date after taking a load of the given weight, default 1.0,
+// so automations can assign whoever is free. Nothing is written.
+func (s *LoadService) LeastLoaded(ctx context.Context, groupID, dateStr string, weight float64) (*models.LeastLoadedResponse, error) {
+	date, err := parseDateOrToday(dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	if weight == 0 {
+		weight = defaultSuggestionWeight
+	}
+
+	group, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Type != models.EntityTypeGroup {
+		return nil, repository.ErrNotAGroup
+	}
+
+	members, err := s.loadRepo.GetGroupMemberLoads(ctx, groupID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LeastLoadedResponse{
+		GroupID: groupID,
+		Date:    date.Format("2006-01-02"),
+		Weight:  weight,
+		Members: rankLeastLoaded(members, weight),
+	}, nil
+}
+
+// rankLeastLoaded orders members by what they would have left after weight,
+// most first, ties broken by email so the order is stable
+func rankLeastLoaded(members []models.RebalanceMember, weight float64) []models.LeastLoadedMember {
+	ranked := make([]models.LeastLoadedMember, 0, len(members))
+	for _, m := range members {
+		remaining := m.Capacity - m.LoadBefore - weight
+		ranked = append(ranked, models.LeastLoadedMember{
+			Email:      m.Email,
+			Title:      m.Title,
+			Capacity:   m.Capacity,
+			Load:       m.LoadBefore,
+			Remaining:  remaining,
+			Overloaded: remaining < -loadEpsilon,
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Remaining != ranked[j].Remaining {
+			return ranked[i].Remaining > ranked[j].Remaining
+		}
+		return ranked[i].Email < ranked[j].Email
+	})
+	return ranked
+}
+
+// RebalanceGroup proposes moves that would resolve the overloads within a
+// group on a date without pushing anyone else over capacity. It is a dry
+// run: nothing is written.
+func (s *LoadService) RebalanceGroup(ctx context.Context, groupID, dateStr string) (*models.RebalancePlan, error) {
+	date, err := parseDateOrToday(dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+
+	group, err := s.entityRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Type != models.EntityTypeGroup {
+		return nil, repository.ErrNotAGroup
+	}
+
+	members, err := s.loadRepo.GetGroupMemberLoads(ctx, groupID, date)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := s.loadRepo.GetLoadsForEntityOnDate(ctx, groupID, models.EntityTypeGroup, date)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := planRebalance(members, loads)
+
+	resolved := true
+	for _, m := range members {
+		if m.LoadAfter > m.Capacity+loadEpsilon {
+			resolved = false
+		}
+	}
+
+	return &models.RebalancePlan{
+		GroupID:  groupID,
+		Date:     date.Format("2006-01-02"),
+		Moves:    moves,
+		Members:  members,
+		Resolved: resolved,
+	}, nil
+}
+
+// loadEpsilon absorbs float rounding when comparing summed weights
+const loadEpsilon = 1e-9
+
+// planRebalance greedily moves assignments off overloaded members, most
+// overloaded first, and fills in each member's LoadAfter. For each overloaded
+// member it prefers the smallest assignment that clears the overload in one
+// move, otherwise the largest that fits anywhere. The target is the member
+// with the most room left after the move who is not already on the load.
+func planRebalance(members []models.RebalanceMember, loads []models.LoadWithAssignments) []models.RebalanceMove {
+	type share struct {
+		loadID int
+		title  string
+		weight float64
+	}
+
+	index := make(map[string]int, len(members))
+	for i := range members {
+		members[i].LoadAfter = members[i].LoadBefore
+		index[members[i].Email] = i
+	}
+
+	assigned := make(map[int]map[string]bool, len(loads))
+	shares := make(map[string][]share, len(members))
+	for _, l := range loads {
+		assigned[l.Load.ID] = make(map[string]bool, len(l.Assignments))
+		for _, a := range l.Assignments {
+			assigned[l.Load.ID][a.PersonEmail] = true
+			if _, ok := index[a.PersonEmail]; ok {
+				shares[a.PersonEmail] = append(shares[a.PersonEmail], share{l.Load.ID, l.Load.Title, a.Weight})
+			}
+		}
+	}
+
+	overloaded := make([]int, 0, len(members))
+	for i, m := range members {
+		if m.LoadBefore > m.Capacity+loadEpsilon {
+			overloaded = append(overloaded, i)
+		}
+	}
+	sort.SliceStable(overloaded, func(a, b int) bool {
+		ea := members[overloaded[a]].LoadBefore - members[overloaded[a]].Capacity
+		eb := members[overloaded[b]].LoadBefore - members[overloaded[b]].Capacity
+		return ea > eb
+	})
+
+	// target returns the member with the most room left after taking s
+	target := func(from int, s share) int {
+		best := -1
+		for i, m := range members {
+			if i == from || assigned[s.loadID][m.Email] {
+				continue
+			}
+			left := m.Capacity - m.LoadAfter - s.weight
+			if left < -loadEpsilon {
+				continue
+			}
+			if best == -1 || left > members[best].Capacity-members[best].LoadAfter-s.weight+loadEpsilon {
+				best = i
+			}
+		}
+		return best
+	}
+
+	moves := []models.RebalanceMove{}
+	for _, from := range overloaded {
+		m := &members[from]
+		for m.LoadAfter > m.Capacity+loadEpsilon {
+			excess := m.LoadAfter - m.Capacity
+			pick, to := -1, -1
+			for i, s := range shares[m.Email] {
+				t := target(from, s)
+				if t == -1 {
+					continue
+				}
+				if pick == -1 {
+					pick, to = i, t
+					continue
+				}
+				current := shares[m.Email][pick].weight
+				clears, currentClears := s.weight >= excess-loadEpsilon, current >= excess-loadEpsilon
+				if (clears && (!currentClears || s.weight < current)) || (!clears && !currentClears && s.weight > current) {
+					pick, to = i, t
+				}
+			}
+			if pick == -1 {
+				break // Nothing left fits anywhere
+			}
+
+			s := shares[m.Email][pick]
+			shares[m.Email] = append(shares[m.Email][:pick], shares[m.Email][pick+1:]...)
+			delete(assigned[s.loadID], m.Email)
+			assigned[s.loadID][members[to].Email] = true
+			m.LoadAfter -= s.weight
+			members[to].LoadAfter += s.weight
+			moves = append(moves, models.RebalanceMove{
+				LoadID: s.loadID,
+				Title:  s.title,
+				Weight: s.weight,
+				From:   m.Email,
+				To:     members[to].Email,
+			})
+		}
+	}
+
+	return moves
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankSuggestions(t *testing.T) {
+	people := []models.AssigneeSuggestion{
+		{Email: "busy@example.com", Capacity: 5, Load: 4.5, Remaining: 0.5},
+		{Email: "free@example.com", Capacity: 5, Load: 0, Remaining: 5},
+		{Email: "b-half@example.com", Capacity: 5, Load: 2.5, Remaining: 2.5},
+		{Email: "a-half@example.com", Capacity: 4, Load: 1.5, Remaining: 2.5},
+		{Email: "off@example.com", Capacity: 0, Load: 0, Remaining: 0},
+		{Email: "over@example.com", Capacity: 5, Load: 7, Remaining: -2},
+	}
+
+	emails := func(ranked []models.AssigneeSuggestion) []string {
+		out := make([]string, 0, len(ranked))
+		for _, p := range ranked {
+			out = append(out, p.Email)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		weight float64
+		limit  int
+		want   []string
+	}{
+		{"most slack first, ties by email", 1, 5,
+			[]string{"free@example.com", "a-half@example.com", "b-half@example.com"}},
+		{"weight that exactly fits", 0.5, 5,
+			[]string{"free@example.com", "a-half@example.com", "b-half@example.com", "busy@example.com"}},
+		{"limit", 1, 2, []string{"free@example.com", "a-half@example.com"}},
+		{"nobody fits", 6, 5, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, emails(rankSuggestions(people, tt.weight, tt.limit)))
+		})
+	}
+}
+
+func TestRankLeastLoaded(t *testing.T) {
+	members := []models.RebalanceMember{
+		{Email: "busy@example.com", Capacity: 5, LoadBefore: 4.5},
+		{Email: "free@example.com", Capacity: 5, LoadBefore: 0},
+		{Email: "b-half@example.com", Capacity: 5, LoadBefore: 2.5},
+		{Email: "a-half@example.com", Capacity: 4, LoadBefore: 1.5},
+	}
+
+	ranked := rankLeastLoaded(members, 1)
+	emails := make([]string, 0, len(ranked))
+	for _, m := range ranked {
+		emails = append(emails, m.Email)
+	}
+	assert.Equal(t, []string{"free@example.com", "a-half@example.com", "b-half@example.com", "busy@example.com"}, emails,
+		"most remaining first, ties by email")
+	assert.Equal(t, 4.0, ranked[0].Remaining)
+	assert.Equal(t, 4.5, ranked[3].Load)
+	assert.Equal(t, -0.5, ranked[3].Remaining)
+	assert.True(t, ranked[3].Overloaded)
+	assert.False(t, ranked[2].Overloaded)
+
+	assert.False(t, rankLeastLoaded(members[:1], 0.5)[0].Overloaded, "a weight that exactly fits")
+	assert.Empty(t, rankLeastLoaded(nil, 1))
+}
+
+func TestPlanRebalance(t *testing.T) {
+	load := func(id int, title string, shares ...models.LoadAssignment) models.LoadWithAssignments {
+		for i := range shares {
+			shares[i].LoadID = id
+		}
+		return models.LoadWithAssignments{Load: models.Load{ID: id, Title: title}, Assignments: shares}
+	}
+	share := func(email string, weight float64) models.LoadAssignment {
+		return models.LoadAssignment{PersonEmail: email, Weight: weight}
+	}
+
+	t.Run("smallest load that clears the overload goes to the most free member", func(t *testing.T) {
+		members := []models.RebalanceMember{
+			{Email: "alice@example.com", Capacity: 5, LoadBefore: 7},
+			{Email: "bob@example.com", Capacity: 5, LoadBefore: 1},
+			{Email: "carol@example.com", Capacity: 5, LoadBefore: 3},
+		}
+		loads := []models.LoadWithAssignments{
+			load(1, "big", share("alice@example.com", 4)),
+			load(2, "fits", share("alice@example.com", 2)),
+			load(3, "small", share("alice@example.com", 1)),
+			load(4, "bob's", share("bob@example.com", 1)),
+		}
+
+		moves := planRebalance(members, loads)
+
+		assert.Equal(t, []models.RebalanceMove{
+			{LoadID: 2, Title: "fits", Weight: 2, From: "alice@example.com", To: "bob@example.com"},
+		}, moves)
+		assert.Equal(t, 5.0, members[0].LoadAfter)
+		assert.Equal(t, 3.0, members[1].LoadAfter)
+		assert.Equal(t, 3.0, members[2].LoadAfter)
+	})
+
+	t.Run("several moves when no single load clears the overload", func(t *testing.T) {
+		members := []models.RebalanceMember{
+			{Email: "alice@example.com", Capacity: 2, LoadBefore: 5},
+			{Email: "bob@example.com", Capacity: 2, LoadBefore: 0},
+			{Email: "carol@example.com", Capacity: 2, LoadBefore: 0},
+		}
+		loads := []models.LoadWithAssignments{
+			load(1, "a", share("alice@example.com", 1.5)),
+			load(2, "b", share("alice@example.com", 1.5)),
+			load(3, "c", share("alice@example.com", 2)),
+		}
+
+		moves := planRebalance(members, loads)
+
+		assert.Len(t, moves, 2)
+		assert.Equal(t, 3, moves[0].LoadID, "largest that fits moves first")
+		assert.Equal(t, "bob@example.com", moves[0].To, "ties go to the first member by email")
+		assert.Equal(t, 1, moves[1].LoadID, "then the smallest that clears the rest")
+		assert.Equal(t, "carol@example.com", moves[1].To)
+		assert.InDelta(t, 1.5, members[0].LoadAfter, loadEpsilon)
+	})
+
+	t.Run("members already on the load are not targets", func(t *testing.T) {
+		members := []models.RebalanceMember{
+			{Email: "alice@example.com", Capacity: 2, LoadBefore: 3},
+			{Email: "bob@example.com", Capacity: 5, LoadBefore: 1},
+			{Email: "carol@example.com", Capacity: 2, LoadBefore: 0},
+		}
+		loads := []models.LoadWithAssignments{
+			load(1, "pair", share("alice@example.com", 3), share("bob@example.com", 1)),
+		}
+
+		moves := planRebalance(members, loads)
+
+		assert.Empty(t, moves, "only bob has room and he is already on the load")
+		assert.Equal(t, 3.0, members[0].LoadAfter)
+	})
+
+	t.Run("no moves without overloads", func(t *testing.T) {
+		members := []models.RebalanceMember{
+			{Email: "alice@example.com", Capacity: 5, LoadBefore: 5},
+			{Email: "bob@example.com", Capacity: 5, LoadBefore: 0},
+		}
+		loads := []models.LoadWithAssignments{load(1, "a", share("alice@example.com", 5))}
+
+		assert.Empty(t, planRebalance(members, loads))
+		assert.Equal(t, 5.0, members[0].LoadAfter)
+	})
+}
+
+func TestDescribeBlackouts(t *testing.T) {
+	got := describeBlackouts([]models.BlackoutConflict{
+		{PersonEmail: "alice@example.com", EntityID: "alice@example.com", Date: "2025-03-10", Reason: "exam week"},
+		{PersonEmail: "bob@example.com", EntityID: "backend", Date: "2025-03-10", Reason: "release freeze"},
+	})
+	assert.Equal(t, "alice@example.com on 2025-03-10: exam week; bob@example.com on 2025-03-10: release freeze (backend)", got)
+}
+
+func TestStaleCutoffs(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	sources, rest := staleCutoffs(now, FreshnessWindows{
+		Default: 7 * 24 * time.Hour,
+		Sources: map[string]time.Duration{"gcal": 48 * time.Hour, "manual": 0},
+	})
+	assert.Equal(t, map[string]time.Time{
+		"gcal":   time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC),
+		"manual": {},
+	}, sources, "a zero window never goes stale")
+	assert.Equal(t, time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC), rest)
+
+	_, rest = staleCutoffs(now, FreshnessWindows{Sources: map[string]time.Duration{"gcal": time.Hour}})
+	assert.True(t, rest.IsZero(), "without a default only listed sources go stale")
+}
+
+func TestSummarizeStaleLoads(t *testing.T) {
+	seen := func(day int) time.Time {
+		return time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC)
+	}
+	stale := func(source string, lastSeen time.Time) models.StaleLoad {
+		l := models.StaleLoad{LastSeenAt: lastSeen}
+		if source != "" {
+			l.Load.Source = &source
+		}
+		return l
+	}
+
+	got := summarizeStaleLoads([]models.StaleLoad{
+		stale("jira", seen(5)),
+		stale("gcal", seen(3)),
+		stale("", seen(4)),
+		stale("gcal", seen(1)),
+	})
+	assert.Equal(t, []models.StaleSourceSummary{
+		{Source: "", Loads: 1, LastSeenAt: seen(4)},
+		{Source: "gcal", Loads: 2, LastSeenAt: seen(1)},
+		{Source: "jira", Loads: 1, LastSeenAt: seen(5)},
+	}, got)
+
+	assert.Empty(t, summarizeStaleLoads(nil))
+}
+
+func TestParseLoadDates(t *testing.T) {
+	start, end, err := parseLoadDates("2025-03-10", "")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), start)
+	assert.Nil(t, end, "single-day load")
+
+	_, end, err = parseLoadDates("2025-03-10", "2025-03-10")
+	assert.NoError(t, err)
+	assert.Nil(t, end, "ending on its first day is a single day")
+
+	_, end, err = parseLoadDates("2025-03-10", "2025-03-14")
+	assert.NoError(t, err)
+	if assert.NotNil(t, end) {
+		assert.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), *end)
+	}
+
+	_, _, err = parseLoadDates("2025-03-10", "2026-03-10")
+	assert.NoError(t, err, "366 days")
+	_, _, err = parseLoadDates("2025-03-10", "2026-03-11")
+	assert.ErrorIs(t, err, ErrInvalidDate, "367 days")
+	_, _, err = parseLoadDates("2025-03-10", "2025-03-09")
+	assert.ErrorIs(t, err, ErrInvalidDate, "ends before it starts")
+	_, _, err = parseLoadDates("10/03/2025", "")
+	assert.ErrorIs(t, err, ErrInvalidDate)
+	_, _, err = parseLoadDates("2025-03-10", "soon")
+	assert.ErrorIs(t, err, ErrInvalidDate)
+}
+
+func TestLoadCursor(t *testing.T) {
+	cursor := repository.LoadCursor{Date: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), ID: 42}
+
+	decoded, err := decodeLoadCursor(encodeLoadCursor(cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	for _, token := range []string{"not base64!", "MjAyNS0wMy0xMA", "eWVzdGVyZGF5LzQy", "MjAyNS0wMy0xMC94"} {
+		_, err := decodeLoadCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
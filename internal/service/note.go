@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+var ErrEmptyNote = errors.New("note body is empty")
+
+// ErrNotInfoAdmin is returned when someone not in ADMIN_EMAILS edits an
+// entity's notes and links
+var ErrNotInfoAdmin = errors.New("only admins may edit entity notes and links")
+
+// ErrInvalidEntityLink is returned for entity links without a title or an
+// http or https URL
+var ErrInvalidEntityLink = errors.New("links need a title and an http or https URL")
+
+// NoteService manages short comments users attach to loads and to their
+// own days, shown in day details, and the notes and links admins keep on
+// entities, shown beside their heatmaps.
+type NoteService struct {
+	noteRepo *repository.NoteRepository
+
+	// admins may edit entity notes and links, by lowercase email
+	admins map[string]bool
+}
+
+func NewNoteService(noteRepo *repository.NoteRepository) *NoteService {
+	return &NoteService{noteRepo: noteRepo}
+}
+
+// SetAdmins sets who may edit entity notes and links
+func (s *NoteService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// IsAdmin reports whether email may edit entity notes and links
+func (s *NoteService) IsAdmin(email string) bool {
+	return email != "" && s.admins[strings.ToLower(email)]
+}
+
+// AddLoadNote comments on a load
+func (s *NoteService) AddLoadNote(ctx context.Context, authorEmail string, loadID int, body string) (*models.Note, error) {
+	body, err := cleanNoteBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.noteRepo.AddLoadNote(ctx, authorEmail, loadID, body)
+}
+
+// AddDayNote notes something about the author's own day
+func (s *NoteService) AddDayNote(ctx context.Context, authorEmail, dateStr, body string) (*models.Note, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	body, err = cleanNoteBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.noteRepo.AddDayNote(ctx, authorEmail, date, body)
+}
+
+// DeleteNote removes one of the author's notes
+func (s *NoteService) DeleteNote(ctx context.Context, authorEmail string, id int) error {
+	return s.noteRepo.Delete(ctx, authorEmail, id)
+}
+
+// ForDay returns the notes for an entity's day details: comments on the
+// day's loads and the day notes of the entity or its members
+func (s *NoteService) ForDay(ctx context.Context, entityID string, date time.Time, loads []models.LoadWithAssignments) ([]models.Note, error) {
+	loadIDs := make([]int, 0, len(loads))
+	for _, l := range loads {
+		loadIDs = append(loadIDs, l.Load.ID)
+	}
+	return s.noteRepo.ListForDay(ctx, entityID, date, loadIDs)
+}
+
+// EntityInfo returns the notes and links kept on an entity
+func (s *NoteService) EntityInfo(ctx context.Context, entityID string) (*models.EntityInfo, error) {
+	return s.noteRepo.GetEntityInfo(ctx, entityID)
+}
+
+// UpdateEntityInfo replaces the notes and links kept on an entity as an
+// admin
+func (s *NoteService) UpdateEntityInfo(ctx context.Context, actorEmail, entityID string, req *models.UpdateEntityInfoRequest) (*models.EntityInfo, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotInfoAdmin
+	}
+	links, err := cleanLinks(req.Links)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &models.EntityInfo{
+		EntityID:  entityID,
+		Notes:     strings.TrimSpace(req.Notes),
+		Links:     links,
+		UpdatedBy: actorEmail,
+	}
+	if err := s.noteRepo.PutEntityInfo(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// cleanLinks trims each link's title and URL, rejecting links without a
+// title or whose URL is not http or https, which the panel could not safely
+// link to
+func cleanLinks(links []models.EntityLink) ([]models.EntityLink, error) {
+	cleaned := make([]models.EntityLink, 0, len(links))
+	for _, l := range links {
+		l.Title, l.URL = strings.TrimSpace(l.Title), strings.TrimSpace(l.URL)
+		u, err := url.Parse(l.URL)
+		if l.Title == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ErrInvalidEntityLink
+		}
+		cleaned = append(cleaned, l)
+	}
+	return cleaned, nil
+}
+
+// cleanNoteBody trims surrounding whitespace, rejecting notes left empty
+func cleanNoteBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrEmptyNote
+	}
+	return body, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanNoteBody(t *testing.T) {
+	body, err := cleanNoteBody("  travel day\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "travel day", body)
+
+	_, err = cleanNoteBody(" \t\n")
+	assert.ErrorIs(t, err, ErrEmptyNote)
+}
+
+func TestCleanLinks(t *testing.T) {
+	links, err := cleanLinks([]models.EntityLink{
+		{Title: " Team charter ", URL: " https://wiki.example.com/charter "},
+		{Title: "On-call", URL: "http://oncall.example.com/platform"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.EntityLink{
+		{Title: "Team charter", URL: "https://wiki.example.com/charter"},
+		{Title: "On-call", URL: "http://oncall.example.com/platform"},
+	}, links)
+
+	links, err = cleanLinks(nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, links, "no links are stored as an empty list")
+
+	for _, l := range []models.EntityLink{
+		{Title: "", URL: "https://example.com"},
+		{Title: "Script", URL: "javascript:alert(1)"},
+		{Title: "Relative", URL: "/wiki/charter"},
+		{Title: "No host", URL: "https://"},
+	} {
+		_, err := cleanLinks([]models.EntityLink{l})
+		assert.ErrorIs(t, err, ErrInvalidEntityLink, l.URL)
+	}
+}
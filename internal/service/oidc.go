@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOIDCState is returned for a single sign-on callback whose login was
+	// not started from this browser, was already used, or has expired
+	ErrOIDCState = errors.New("sign-in expired or was not started here, try again")
+	// ErrOIDCToken is returned when the identity provider refuses the
+	// authorization code, or answers with an ID token that does not check out
+	ErrOIDCToken = errors.New("identity provider did not confirm the sign-in")
+	// ErrOIDCEmail is returned when the ID token carries no verified email
+	ErrOIDCEmail = errors.New("identity provider did not confirm an email")
+)
+
+// oidcLoginLifetime is how long a user has to sign in at the identity
+// provider once they left for it
+const oidcLoginLifetime = 10 * time.Minute
+
+// oidcClockSkew is how far the identity provider's clock may be ahead of ours
+const oidcClockSkew = time.Minute
+
+// OIDCProvider signs users in with an OpenID Connect identity provider, such
+// as Google Workspace or Azure AD, by the authorization code flow with PKCE.
+// Its endpoints and signing keys are discovered from the issuer and cached;
+// the keys are fetched again when a token is signed with an unknown one.
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	emailClaim   string
+	tenantID     string
+	client       *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+// oidcDiscovery is the part of an issuer's openid-configuration used here
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider returns a provider for the issuer, shown to users as name,
+// that redirects back to redirectURL
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string) *OIDCProvider {
+	return &OIDCProvider{
+		name:         name,
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		emailClaim:   "email",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used to call the identity provider
+func (p *OIDCProvider) SetHTTPClient(client *http.Client) {
+	p.client = client
+}
+
+// UseEmailClaim takes the user's email from another ID token claim than
+// email, such as preferred_username for Azure AD, in ID tokens whose tid
+// claim is tenantID. Only the email claim carries email_verified; users of
+// other tenants can set another claim to anyone's email, so it is trusted
+// from one tenant only, and with an empty tenantID no sign-in is accepted.
+// With the email claim, a non-empty tenantID still has to match.
+func (p *OIDCProvider) UseEmailClaim(claim, tenantID string) {
+	p.emailClaim = claim
+	p.tenantID = tenantID
+}
+
+// Name is what users are shown the identity provider as
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns where to send the user to sign in, carrying state back
+// to the callback, nonce in the ID token, and the PKCE challenge of verifier
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code with its PKCE verifier and returns
+// the email of the signed-in user, from an ID token checked against nonce
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send token request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("token endpoint returned status %d with an unreadable body: %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrOIDCToken, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("%w: no ID token", ErrOIDCToken)
+	}
+
+	return p.verifyIDToken(ctx, token.IDToken, nonce, time.Now())
+}
+
+// verifyIDToken checks an ID token's RS256 signature, issuer, tenant,
+// audience, expiry and nonce, and returns its email
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, raw, nonce string, now time.Time) (string, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w: malformed ID token", ErrOIDCToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return "", err
+	}
+	if header.Alg != "RS256" {
+		return "", fmt.Errorf("%w: unsupported signing algorithm %q", ErrOIDCToken, header.Alg)
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return "", err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed signature", ErrOIDCToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return "", fmt.Errorf("%w: bad signature", ErrOIDCToken)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return "", err
+	}
+	if iss, _ := claims["iss"].(string); iss != p.issuer {
+		return "", fmt.Errorf("%w: issued by %q", ErrOIDCToken, iss)
+	}
+	if tid, _ := claims["tid"].(string); p.tenantID != "" && tid != p.tenantID {
+		return "", fmt.Errorf("%w: issued for tenant %q", ErrOIDCToken, tid)
+	}
+	if !oidcAudienceHas(claims["aud"], p.clientID) {
+		return "", fmt.Errorf("%w: issued for another client", ErrOIDCToken)
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.clientID {
+		return "", fmt.Errorf("%w: issued for another client", ErrOIDCToken)
+	}
+	exp, _ := claims["exp"].(float64)
+	if now.Add(-oidcClockSkew).After(time.Unix(int64(exp), 0)) {
+		return "", fmt.Errorf("%w: expired", ErrOIDCToken)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return "", fmt.Errorf("%w: nonce mismatch", ErrOIDCToken)
+	}
+
+	email, _ := claims[p.emailClaim].(string)
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", ErrOIDCEmail
+	}
+	if p.emailClaim == "email" && !oidcTrue(claims["email_verified"]) {
+		return "", ErrOIDCEmail
+	}
+	if p.emailClaim != "email" && p.tenantID == "" {
+		return "", ErrOIDCEmail
+	}
+	return email, nil
+}
+
+// key returns the identity provider's signing key with ID kid, fetching the
+// keys again when it is not known yet
+func (p *OIDCProvider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) == 0 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrOIDCToken, kid)
+}
+
+// discover fetches the issuer's openid-configuration once
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	d := p.discovery
+	p.mu.Unlock()
+	if d != nil {
+		return d, nil
+	}
+
+	d = &oidcDiscovery{}
+	if err := p.getJSON(ctx, strings.TrimRight(p.issuer, "/")+"/.well-known/openid-configuration", d); err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+	if d.Issuer != p.issuer {
+		return nil, fmt.Errorf("identity provider reports issuer %q, configured %q", d.Issuer, p.issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("identity provider configuration lacks an endpoint")
+	}
+
+	p.mu.Lock()
+	p.discovery = d
+	p.mu.Unlock()
+	return d, nil
+}
+
+// getJSON fetches a JSON document into out
+func (p *OIDCProvider) getJSON(ctx context.Context, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", target, err)
+	}
+	return nil
+}
+
+// decodeJWTPart decodes the base64url JSON header or claims of a JWT
+func decodeJWTPart(part string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed ID token", ErrOIDCToken)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%w: malformed ID token", ErrOIDCToken)
+	}
+	return nil
+}
+
+// oidcAudienceHas reports whether an aud claim, a string or a list of them,
+// names clientID
+func oidcAudienceHas(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// oidcTrue reports whether a boolean claim is true; some identity providers
+// send it as a string
+func oidcTrue(claim interface{}) bool {
+	switch v := claim.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// randomToken returns n random bytes, base64url encoded
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdentityProvider serves discovery, signing keys and a token endpoint
+// answering with the ID token claims set on it, signed with key
+type fakeIdentityProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+	form   url.Values
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &fakeIdentityProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		idp.form = r.PostForm
+		if r.PostForm.Get("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error": "invalid_grant", "error_description": "code expired"}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(t, key, idp.claims)})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// sign returns an RS256 JWT of claims signed with key
+func (idp *fakeIdentityProvider) sign(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "key-1", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims are the claims of an ID token that checks out
+func (idp *fakeIdentityProvider) validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":            idp.URL,
+		"aud":            "client-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          "nonce-1",
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}
+}
+
+func TestOIDCProviderAuthCodeURL(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	p := NewOIDCProvider("Google", idp.URL, "client-1", "secret", "https://heatmap.example.com/auth/oidc/callback")
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+	q := u.Query()
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "client-1", q.Get("client_id"))
+	assert.Equal(t, "https://heatmap.example.com/auth/oidc/callback", q.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, "state-1", q.Get("state"))
+	assert.Equal(t, "nonce-1", q.Get("nonce"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	challenge := sha256.Sum256([]byte("verifier-1"))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), q.Get("code_challenge"))
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	p := NewOIDCProvider("Google", idp.URL, "client-1", "secret", "https://heatmap.example.com/auth/oidc/callback")
+	ctx := context.Background()
+
+	idp.claims = idp.validClaims()
+	email, err := p.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", email)
+	assert.Equal(t, "verifier-1", idp.form.Get("code_verifier"), "the PKCE verifier is sent")
+	assert.Equal(t, "secret", idp.form.Get("client_secret"))
+
+	_, err = p.Exchange(ctx, "bad-code", "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrOIDCToken, "a refused code")
+	assert.Contains(t, err.Error(), "invalid_grant")
+
+	tests := []struct {
+		name   string
+		change func(claims map[string]interface{})
+		want   error
+	}{
+		{"other nonce", func(c map[string]interface{}) { c["nonce"] = "nonce-2" }, ErrOIDCToken},
+		{"other issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, ErrOIDCToken},
+		{"other audience", func(c map[string]interface{}) { c["aud"] = "client-2" }, ErrOIDCToken},
+		{"expired", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, ErrOIDCToken},
+		{"unverified email", func(c map[string]interface{}) { c["email_verified"] = false }, ErrOIDCEmail},
+		{"no email", func(c map[string]interface{}) { delete(c, "email") }, ErrOIDCEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.claims = idp.validClaims()
+			tt.change(idp.claims)
+			_, err := p.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	idp.claims = idp.validClaims()
+	idp.claims["aud"] = []string{"client-2", "client-1"}
+	idp.claims["email_verified"] = "true"
+	email, err = p.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+	require.NoError(t, err, "a list of audiences and a string email_verified")
+	assert.Equal(t, "alice@example.com", email)
+}
+
+func TestOIDCProviderRejectsForgedToken(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	p := NewOIDCProvider("Google", idp.URL, "client-1", "secret", "https://heatmap.example.com/auth/oidc/callback")
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	forged := idp.sign(t, other, idp.validClaims())
+	_, err = p.verifyIDToken(context.Background(), forged, "nonce-1", time.Now())
+	assert.ErrorIs(t, err, ErrOIDCToken)
+
+	header, payload, _ := strings.Cut(idp.sign(t, idp.key, idp.validClaims()), ".")
+	_, signature, _ := strings.Cut(payload, ".")
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"email":"mallory@example.com"}`)) + "." + signature
+	_, err = p.verifyIDToken(context.Background(), unsigned, "nonce-1", time.Now())
+	assert.ErrorIs(t, err, ErrOIDCToken, "claims changed after signing")
+}
+
+func TestOIDCProviderEmailClaim(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	p := NewOIDCProvider("Azure AD", idp.URL, "client-1", "secret", "https://heatmap.example.com/auth/oidc/callback")
+	p.UseEmailClaim("preferred_username", "tenant-1")
+
+	idp.claims = idp.validClaims()
+	delete(idp.claims, "email")
+	delete(idp.claims, "email_verified")
+	idp.claims["preferred_username"] = "bob@example.com"
+	idp.claims["tid"] = "tenant-1"
+	email, err := p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", email, "another claim needs no email_verified")
+
+	idp.claims["tid"] = "tenant-2"
+	_, err = p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrOIDCToken, "another tenant's users could set the claim to anyone's email")
+
+	delete(idp.claims, "tid")
+	_, err = p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrOIDCToken, "tokens without a tenant are refused")
+
+	p.UseEmailClaim("preferred_username", "")
+	_, err = p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrOIDCEmail, "another claim is not trusted without a tenant")
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// otpLifetime is how long a login code may be used
+const otpLifetime = 10 * time.Minute
+
+// OTPDeliverer sends a login code to the person with the given email
+type OTPDeliverer interface {
+	DeliverOTP(ctx context.Context, email, otp string) error
+}
+
+// otpMessage is the text sent with a login code
+func otpMessage(otp string) string {
+	return fmt.Sprintf("Your heatmap login code is %s. It expires in %d minutes.", otp, int(otpLifetime.Minutes()))
+}
+
+// ConsoleOTPDeliverer logs login codes instead of sending them, for
+// development and deployments without a way to reach people
+type ConsoleOTPDeliverer struct{}
+
+func (ConsoleOTPDeliverer) DeliverOTP(_ context.Context, email, otp string) error {
+	log.Println("==========================================")
+	log.Printf("🔐 OTP for %s: %s", email, otp)
+	log.Println("==========================================")
+	return nil
+}
+
+// LarkOTPDeliverer sends login codes as Lark messages to the person's
+// account by email
+type LarkOTPDeliverer struct {
+	lark *LarkClient
+}
+
+func NewLarkOTPDeliverer(lark *LarkClient) *LarkOTPDeliverer {
+	return &LarkOTPDeliverer{lark: lark}
+}
+
+func (d *LarkOTPDeliverer) DeliverOTP(ctx context.Context, email, otp string) error {
+	return d.lark.SendText(ctx, "email", email, otpMessage(otp))
+}
+
+// MailgunOTPDeliverer emails login codes through the Mailgun API
+type MailgunOTPDeliverer struct {
+	apiURL string
+	domain string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+// NewMailgunOTPDeliverer sends from the from address of domain, through the
+// Mailgun API at apiURL (https://api.mailgun.net, or https://api.eu.mailgun.net
+// for EU domains)
+func NewMailgunOTPDeliverer(apiURL, domain, apiKey, from string) *MailgunOTPDeliverer {
+	return &MailgunOTPDeliverer{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		domain: domain,
+		apiKey: apiKey,
+		from:   from,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used for Mailgun API calls
+func (d *MailgunOTPDeliverer) SetHTTPClient(client *http.Client) {
+	d.client = client
+}
+
+func (d *MailgunOTPDeliverer) DeliverOTP(ctx context.Context, email, otp string) error {
+	form := url.Values{
+		"from":    {d.from},
+		"to":      {email},
+		"subject": {"Your heatmap login code"},
+		"text":    {otpMessage(otp)},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		d.apiURL+"/v3/"+url.PathEscape(d.domain)+"/messages", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("api", d.apiKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("mailgun API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// SMTPOTPDeliverer emails login codes through an SMTP server, authenticating
+// when given a username
+type SMTPOTPDeliverer struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPOTPDeliverer sends from the from address, such as
+// "Heatmap <heatmap@example.com>", through host:port
+func NewSMTPOTPDeliverer(host, port, username, password, from string) *SMTPOTPDeliverer {
+	d := &SMTPOTPDeliverer{
+		addr:     host + ":" + port,
+		from:     from,
+		sendMail: smtp.SendMail,
+	}
+	if username != "" {
+		d.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return d
+}
+
+func (d *SMTPOTPDeliverer) DeliverOTP(_ context.Context, email, otp string) error {
+	from, err := mail.ParseAddress(d.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + from.String(),
+		"To: " + email,
+		"Subject: Your heatmap login code",
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		otpMessage(otp),
+		"",
+	}, "\r\n")
+
+	if err := d.sendMail(d.addr, d.auth, from.Address, []string{email}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailgunOTPDeliverer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages", r.URL.Path)
+		user, key, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api", user)
+		assert.Equal(t, "key-123", key)
+		assert.Equal(t, "Heatmap <heatmap@mg.example.com>", r.FormValue("from"))
+		assert.Equal(t, "alice@example.com", r.FormValue("to"))
+		assert.Contains(t, r.FormValue("text"), "123456")
+		_, _ = w.Write([]byte(`{"id": "<1@mg.example.com>", "message": "Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	d := NewMailgunOTPDeliverer(server.URL+"/", "mg.example.com", "key-123", "Heatmap <heatmap@mg.example.com>")
+	require.NoError(t, d.DeliverOTP(context.Background(), "alice@example.com", "123456"))
+}
+
+func TestMailgunOTPDelivererError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Forbidden", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	d := NewMailgunOTPDeliverer(server.URL, "mg.example.com", "wrong", "heatmap@mg.example.com")
+	assert.ErrorContains(t, d.DeliverOTP(context.Background(), "alice@example.com", "123456"), "status 401: Forbidden")
+}
+
+func TestSMTPOTPDeliverer(t *testing.T) {
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg string
+	d := NewSMTPOTPDeliverer("smtp.example.com", "587", "mailer", "secret", "Heatmap <heatmap@example.com>")
+	d.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.NotNil(t, a, "authenticates with a username")
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, string(msg)
+		return nil
+	}
+
+	require.NoError(t, d.DeliverOTP(context.Background(), "alice@example.com", "123456"))
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "heatmap@example.com", gotFrom, "the envelope sender is the bare address")
+	assert.Equal(t, []string{"alice@example.com"}, gotTo)
+	assert.Contains(t, gotMsg, "From: \"Heatmap\" <heatmap@example.com>\r\n")
+	assert.Contains(t, gotMsg, "To: alice@example.com\r\n")
+	assert.Contains(t, gotMsg, "\r\n\r\nYour heatmap login code is 123456.")
+
+	d.sendMail = func(string, smtp.Auth, string, []string, []byte) error { return errors.New("connection refused") }
+	assert.ErrorContains(t, d.DeliverOTP(context.Background(), "alice@example.com", "123456"), "connection refused")
+}
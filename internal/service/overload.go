@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// defaultReportDays is how far back the overload resolution report looks
+// when no start date is given
+const defaultReportDays = 30
+
+// OverloadService tracks when person-days go over capacity and when they
+// come back under, so managers can see whether red days get fixed.
+type OverloadService struct {
+	overloadRepo *repository.OverloadRepository
+	entityRepo   *repository.EntityRepository
+}
+
+func NewOverloadService(
+	overloadRepo *repository.OverloadRepository,
+	entityRepo *repository.EntityRepository,
+) *OverloadService {
+	return &OverloadService{
+		overloadRepo: overloadRepo,
+		entityRepo:   entityRepo,
+	}
+}
+
+// RunOverloadSweep records overload transitions every interval until ctx is
+// cancelled. Transition times are accurate to the interval.
+func (s *OverloadService) RunOverloadSweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		opened, resolved, err := s.Sweep(ctx)
+		if err != nil {
+			log.Printf("Overload sweep: %v", err)
+			continue
+		}
+		if opened > 0 || resolved > 0 {
+			log.Printf("Overload sweep: %d person-days overloaded, %d resolved", opened, resolved)
+		}
+	}
+}
+
+// Sweep records overload transitions for person-days from today on, and
+// returns how many days went over capacity and how many came back under
+func (s *OverloadService) Sweep(ctx context.Context) (opened, resolved int64, err error) {
+	now := time.Now()
+	return s.overloadRepo.Sweep(ctx, utcDate(now), now)
+}
+
+// GetResolutionReport summarizes, per group, how its members' overloaded
+// days between from and to were resolved. From defaults to 30 days ago and
+// to defaults to today.
+func (s *OverloadService) GetResolutionReport(ctx context.Context, fromStr, toStr string) (*models.OverloadResolutionReport, error) {
+	to, err := parseDateOrToday(toStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDate)
+		}
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidDate)
+	}
+
+	groups, err := s.entityRepo.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.overloadRepo.ListGroupOverloadDays(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OverloadResolutionReport{
+		From:   from.Format("2006-01-02"),
+		To:     to.Format("2006-01-02"),
+		Groups: summarizeOverloads(groups, days, utcDate(time.Now())),
+	}, nil
+}
+
+// GetMetrics returns service-wide overload counters
+func (s *OverloadService) GetMetrics(ctx context.Context) (*models.OverloadMetrics, error) {
+	return s.overloadRepo.GetMetrics(ctx, utcDate(time.Now()))
+}
+
+// summarizeOverloads counts each group's overload days by state as of today
+// and averages the time its resolved days took. Groups without overload
+// days are reported with zero counts.
+func summarizeOverloads(groups []models.Entity, days []models.GroupOverloadDay, today time.Time) []models.OverloadResolution {
+	byGroup := make(map[string][]models.GroupOverloadDay, len(groups))
+	for _, d := range days {
+		byGroup[d.GroupID] = append(byGroup[d.GroupID], d)
+	}
+
+	summaries := make([]models.OverloadResolution, 0, len(groups))
+	for _, g := range groups {
+		summary := models.OverloadResolution{GroupID: g.ID, Title: g.Title}
+		var resolvedHours float64
+		for _, d := range byGroup[g.ID] {
+			summary.Overloaded++
+			switch {
+			case d.ResolvedAt != nil:
+				summary.Resolved++
+				resolvedHours += d.ResolvedAt.Sub(d.OverloadedAt).Hours()
+			case d.Date.Before(today):
+				summary.Unresolved++
+			default:
+				summary.Open++
+			}
+		}
+		if summary.Resolved > 0 {
+			mean := resolvedHours / float64(summary.Resolved)
+			summary.MeanHoursToResolve = &mean
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// utcDate returns the UTC calendar date of t
+func utcDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
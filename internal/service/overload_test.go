@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeOverloads(t *testing.T) {
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) *time.Time {
+		ts := time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+		return &ts
+	}
+	day := func(group, email string, date int, overloadedAt, resolvedAt *time.Time) models.GroupOverloadDay {
+		return models.GroupOverloadDay{
+			GroupID: group,
+			OverloadDay: models.OverloadDay{
+				PersonEmail:  email,
+				Date:         time.Date(2025, 3, date, 0, 0, 0, 0, time.UTC),
+				OverloadedAt: *overloadedAt,
+				ResolvedAt:   resolvedAt,
+			},
+		}
+	}
+
+	groups := []models.Entity{
+		{ID: "backend", Title: "Backend"},
+		{ID: "design", Title: "Design"},
+		{ID: "quiet", Title: "Quiet"},
+	}
+	days := []models.GroupOverloadDay{
+		day("backend", "alice@example.com", 5, at(1, 9), at(1, 11)), // resolved in 2h
+		day("backend", "bob@example.com", 8, at(2, 9), at(3, 15)),   // resolved in 30h
+		day("backend", "alice@example.com", 9, at(4, 9), nil),       // date passed
+		day("backend", "alice@example.com", 10, at(9, 9), nil),      // today, still open
+		day("design", "alice@example.com", 12, at(9, 12), nil),      // shared member, open
+	}
+
+	got := summarizeOverloads(groups, days, today)
+
+	mean := 16.0
+	assert.Equal(t, []models.OverloadResolution{
+		{GroupID: "backend", Title: "Backend", Overloaded: 4, Resolved: 2, Open: 1, Unresolved: 1, MeanHoursToResolve: &mean},
+		{GroupID: "design", Title: "Design", Overloaded: 1, Open: 1},
+		{GroupID: "quiet", Title: "Quiet"},
+	}, got)
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/cache"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+var (
+	// ErrInvalidDate is returned for dates that are not YYYY-MM-DD
+	ErrInvalidDate = errors.New("invalid date format")
+	// ErrInvalidImport is returned for group imports that cannot be read or
+	// that nest groups
+	ErrInvalidImport = errors.New("invalid group import")
+	// ErrInvalidEntityID is returned for new entity IDs that cannot be used,
+	// such as a person's that is not an email
+	ErrInvalidEntityID = errors.New("invalid entity ID")
+)
+
+// PeopleService onboards and offboards persons, each in a single call that
+// replaces creating the entity, adding memberships, and adjusting capacity
+// and loads one request at a time.
+type PeopleService struct {
+	entityRepo     *repository.EntityRepository
+	webhookService *WebhookService
+	renderCache    *cache.RenderCache
+	events         *EventLog
+	settings       *SettingsService
+}
+
+func NewPeopleService(
+	entityRepo *repository.EntityRepository,
+	webhookService *WebhookService,
+	renderCache *cache.RenderCache,
+) *PeopleService {
+	return &PeopleService{
+		entityRepo:     entityRepo,
+		webhookService: webhookService,
+		renderCache:    renderCache,
+	}
+}
+
+// RecordEvents records every onboarding, offboarding and group import in the
+// domain event log
+func (s *PeopleService) RecordEvents(events *EventLog) {
+	s.events = events
+}
+
+// UseSettings gives onboarded and imported persons without a capacity the
+// default capacity from the settings
+func (s *PeopleService) UseSettings(settings *SettingsService) {
+	s.settings = settings
+}
+
+// Onboard creates a person with their groups and capacity. With a ramp-up,
+// their capacity is overridden for the given number of days from the start
+// date.
+func (s *PeopleService) Onboard(ctx context.Context, req *models.OnboardPersonRequest) (*models.OnboardPersonResponse, error) {
+	startDate, err := parseDateOrToday(req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidDate)
+	}
+
+	capacity := req.DefaultCapacity
+	if capacity == 0 {
+		capacity = s.settings.DefaultCapacity()
+	}
+
+	person := &models.Entity{
+		ID:              req.Email,
+		Title:           req.Title,
+		EmployeeID:      req.EmployeeID,
+		DefaultCapacity: capacity,
+		Skills:          req.Skills,
+		ManagerEmail:    req.ManagerEmail,
+	}
+
+	groups := make([]string, 0, len(req.Groups))
+	seen := make(map[string]bool, len(req.Groups))
+	for _, g := range req.Groups {
+		if !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+
+	var overrides []models.CapacityOverride
+	resp := &models.OnboardPersonResponse{Groups: groups}
+	if req.RampUp != nil {
+		for i := 0; i < req.RampUp.Days; i++ {
+			overrides = append(overrides, models.CapacityOverride{
+				EntityID: person.ID,
+				Date:     startDate.AddDate(0, 0, i),
+				Capacity: req.RampUp.Capacity,
+			})
+		}
+		resp.RampUpUntil = startDate.AddDate(0, 0, req.RampUp.Days-1).Format("2006-01-02")
+	}
+
+	if err := s.entityRepo.Onboard(ctx, person, groups, overrides); err != nil {
+		return nil, err
+	}
+	s.renderCache.Invalidate(ctx, person.ID)
+
+	resp.Entity = *person
+	s.events.Record(ctx, models.EventPersonOnboarded, "", append([]string{person.ID}, groups...), resp)
+	return resp, nil
+}
+
+// Offboard archives a person after their last day, removes them from later
+// loads and capacity overrides, and notifies the webhook destination so the
+// owners of their groups can reassign the work.
+func (s *PeopleService) Offboard(ctx context.Context, email string, req *models.OffboardPersonRequest) (*models.OffboardPersonResponse, error) {
+	lastDay, err := parseDateOrToday(req.LastDay)
+	if err != nil {
+		return nil, fmt.Errorf("%w: last_day must be YYYY-MM-DD", ErrInvalidDate)
+	}
+
+	result, err := s.entityRepo.Offboard(ctx, email, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	s.renderCache.Invalidate(ctx, email)
+	s.events.Record(ctx, models.EventPersonOffboarded, "", []string{email}, result)
+
+	s.webhookService.NotifyOffboarded(ctx, result)
+
+	return result, nil
+}
+
+// ChangeID moves an entity to a new ID, such as a person whose email was
+// renamed, keeping their loads, capacity, memberships, history and logins.
+// A person's new ID must be an email.
+func (s *PeopleService) ChangeID(ctx context.Context, id string, req *models.ChangeEntityIDRequest) (*models.ChangeEntityIDResponse, error) {
+	newID := strings.TrimSpace(req.NewID)
+	if newID == id {
+		return nil, fmt.Errorf("%w: new_id is the current ID", ErrInvalidEntityID)
+	}
+	entity, err := s.entityRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.Type == models.EntityTypePerson && !isEmail(newID) {
+		return nil, fmt.Errorf("%w: a person's new_id must be an email", ErrInvalidEntityID)
+	}
+
+	result, err := s.entityRepo.ChangeID(ctx, id, newID)
+	if err != nil {
+		return nil, err
+	}
+	// Renders of the entity's groups name the old ID too
+	s.renderCache.InvalidateAll()
+	s.events.Record(ctx, models.EventEntityIDChanged, "", []string{id, newID}, result)
+
+	return result, nil
+}
+
+// isEmail reports whether s is a bare email address
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// ListAutoCreated returns the persons upserts created for unknown assignees
+// that have not been reviewed yet, oldest first
+func (s *PeopleService) ListAutoCreated(ctx context.Context) ([]models.AutoCreatedPerson, error) {
+	return s.entityRepo.ListAutoCreated(ctx)
+}
+
+// ConfirmAutoCreated keeps the given auto-created persons and takes them off
+// the review queue
+func (s *PeopleService) ConfirmAutoCreated(ctx context.Context, emails []string) (*models.ReviewAutoCreatedResponse, error) {
+	confirmed, err := s.entityRepo.ConfirmAutoCreated(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(confirmed) > 0 {
+		s.events.Record(ctx, models.EventPersonsConfirmed, "", confirmed, map[string]interface{}{"emails": confirmed})
+	}
+	return reviewResponse(emails, confirmed), nil
+}
+
+// RejectAutoCreated deletes the given auto-created persons, such as mistyped
+// emails, with their load assignments. Persons already confirmed are kept.
+func (s *PeopleService) RejectAutoCreated(ctx context.Context, emails []string) (*models.ReviewAutoCreatedResponse, error) {
+	deleted, err := s.entityRepo.DeleteAutoCreated(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) > 0 {
+		s.renderCache.Invalidate(ctx, deleted...)
+		s.events.Record(ctx, models.EventPersonsRejected, "", deleted, map[string]interface{}{"emails": deleted})
+	}
+	return reviewResponse(emails, deleted), nil
+}
+
+// reviewResponse lists the requested emails that were reviewed and, sorted
+// and without repeats, those that were not
+func reviewResponse(requested, reviewed []string) *models.ReviewAutoCreatedResponse {
+	done := make(map[string]bool, len(reviewed))
+	for _, email := range reviewed {
+		done[email] = true
+	}
+	skipped := []string{}
+	for _, email := range requested {
+		if !done[email] {
+			done[email] = true
+			skipped = append(skipped, email)
+		}
+	}
+	sort.Strings(skipped)
+	return &models.ReviewAutoCreatedResponse{Reviewed: reviewed, Skipped: skipped}
+}
+
+// ImportGroups loads an org structure in one call: it creates the groups and
+// members named by rows and adds the memberships, skipping whatever already
+// exists. Surrounding whitespace is trimmed and repeated rows are counted
+// once. Groups cannot contain groups, so a name used both as a group and as
+// a member fails the whole import.
+func (s *PeopleService) ImportGroups(ctx context.Context, rows []models.GroupImportRow) (*models.GroupImportResponse, error) {
+	unique := make([]models.GroupImportRow, 0, len(rows))
+	seen := make(map[models.GroupImportRow]bool, len(rows))
+	groups := make(map[string]bool)
+	for _, row := range rows {
+		row.Group = strings.TrimSpace(row.Group)
+		row.Member = strings.TrimSpace(row.Member)
+		if row.Group == "" || row.Member == "" {
+			return nil, fmt.Errorf("%w: every row needs a group and a member", ErrInvalidImport)
+		}
+		if !seen[row] {
+			seen[row] = true
+			groups[row.Group] = true
+			unique = append(unique, row)
+		}
+	}
+	for _, row := range unique {
+		if groups[row.Member] {
+			return nil, fmt.Errorf("%w: %s is both a group and a member", ErrInvalidImport, row.Member)
+		}
+	}
+
+	result, err := s.entityRepo.ImportGroups(ctx, unique, s.settings.DefaultCapacity())
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0, len(groups)+len(unique))
+	for group := range groups {
+		s.renderCache.Invalidate(ctx, group)
+		changed = append(changed, group)
+	}
+	for _, row := range unique {
+		changed = append(changed, row.Member)
+	}
+	s.events.Record(ctx, models.EventGroupsImported, "", changed, unique)
+
+	return result, nil
+}
+
+// ParseGroupImportCSV reads (group, member) rows from a CSV export. The first
+// line is a header naming a "group" and a "member" column, in any order and
+// case; other columns are ignored, so an HR export can be sent as it is.
+func ParseGroupImportCSV(r io.Reader) ([]models.GroupImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the CSV is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	groupCol, memberCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "group":
+			groupCol = i
+		case "member":
+			memberCol = i
+		}
+	}
+	if groupCol < 0 || memberCol < 0 {
+		return nil, fmt.Errorf("%w: the header must name a group and a member column", ErrInvalidImport)
+	}
+
+	var rows []models.GroupImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if groupCol >= len(record) || memberCol >= len(record) {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d is missing the group or member column", ErrInvalidImport, line)
+		}
+		rows = append(rows, models.GroupImportRow{
+			Group:  strings.TrimSpace(record[groupCol]),
+			Member: strings.TrimSpace(record[memberCol]),
+		})
+	}
+	return rows, nil
+}
+
+// parseDateOrToday parses a YYYY-MM-DD date, defaulting to today (UTC)
+func parseDateOrToday(value string) (time.Time, error) {
+	if value == "" {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroupImportCSV(t *testing.T) {
+	rows, err := ParseGroupImportCSV(strings.NewReader(
+		"\ufeffEmployee ID,Member,Department,GROUP\n" +
+			"E1, alice@example.com ,Engineering,platform\n" +
+			"E2,bob@example.com,Engineering,\"data, analytics\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []models.GroupImportRow{
+		{Group: "platform", Member: "alice@example.com"},
+		{Group: "data, analytics", Member: "bob@example.com"},
+	}, rows, "columns are found by header name and other columns ignored")
+
+	_, err = ParseGroupImportCSV(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrInvalidImport, "empty CSV")
+
+	_, err = ParseGroupImportCSV(strings.NewReader("team,email\nplatform,alice@example.com\n"))
+	assert.ErrorIs(t, err, ErrInvalidImport, "header without group and member columns")
+
+	_, err = ParseGroupImportCSV(strings.NewReader("member,group\nalice@example.com\n"))
+	assert.ErrorIs(t, err, ErrInvalidImport, "row missing the group column")
+}
+
+func TestReviewResponse(t *testing.T) {
+	resp := reviewResponse(
+		[]string{"typo@exmaple.com", "gone@example.com", "new@example.com", "gone@example.com"},
+		[]string{"new@example.com", "typo@exmaple.com"})
+	assert.Equal(t, []string{"new@example.com", "typo@exmaple.com"}, resp.Reviewed)
+	assert.Equal(t, []string{"gone@example.com"}, resp.Skipped, "listed once")
+
+	resp = reviewResponse([]string{"a@example.com"}, []string{})
+	assert.Equal(t, []string{"a@example.com"}, resp.Skipped)
+}
+
+func TestIsEmail(t *testing.T) {
+	assert.True(t, isEmail("alice.smith@example.com"))
+	assert.False(t, isEmail("alice"))
+	assert.False(t, isEmail("Alice <alice@example.com>"), "only bare addresses")
+	assert.False(t, isEmail(""))
+}
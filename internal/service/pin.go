@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// PinService manages the loads each user pinned. Pins only change how the
+// pinning user sees heatmaps and day details.
+type PinService struct {
+	pinRepo *repository.PinRepository
+}
+
+func NewPinService(pinRepo *repository.PinRepository) *PinService {
+	return &PinService{pinRepo: pinRepo}
+}
+
+// PinLoad pins a load for a user
+func (s *PinService) PinLoad(ctx context.Context, personEmail string, loadID int) (*models.Pin, error) {
+	return s.pinRepo.Pin(ctx, personEmail, loadID)
+}
+
+// UnpinLoad removes a user's pin
+func (s *PinService) UnpinLoad(ctx context.Context, personEmail string, loadID int) error {
+	return s.pinRepo.Unpin(ctx, personEmail, loadID)
+}
+
+// ListPins returns a user's pins on loads from today on
+func (s *PinService) ListPins(ctx context.Context, personEmail string) ([]models.Pin, error) {
+	return s.pinRepo.List(ctx, personEmail, utcDate(time.Now()))
+}
+
+// ForEntity returns the viewing user's pins on an entity's loads between
+// start and end. Anonymous viewers have none.
+func (s *PinService) ForEntity(ctx context.Context, viewerEmail, entityID string, start, end time.Time) ([]models.Pin, error) {
+	if viewerEmail == "" {
+		return nil, nil
+	}
+	return s.pinRepo.ListForEntity(ctx, viewerEmail, entityID, start, end)
+}
+
+// PinFirst marks the pinned loads and moves them to the front, keeping the
+// order of the rest
+func PinFirst(loads []models.LoadWithAssignments, pins []models.Pin) []models.LoadWithAssignments {
+	if len(pins) == 0 {
+		return loads
+	}
+
+	pinned := make(map[int]bool, len(pins))
+	for _, p := range pins {
+		pinned[p.LoadID] = true
+	}
+	for i := range loads {
+		loads[i].Pinned = pinned[loads[i].Load.ID]
+	}
+	sort.SliceStable(loads, func(i, j int) bool {
+		return loads[i].Pinned && !loads[j].Pinned
+	})
+
+	return loads
+}
+
+// PinnedVersion folds a viewer's pins into the heatmap validators from
+// GetHeatmapVersion, so pinning or unpinning a load changes the ETag
+func PinnedVersion(etag string, lastModified time.Time, pins []models.Pin) (string, time.Time) {
+	if len(pins) == 0 {
+		return etag, lastModified
+	}
+
+	var b strings.Builder
+	b.WriteString(etag)
+	for _, p := range pins {
+		fmt.Fprintf(&b, "|%d", p.LoadID)
+		if p.PinnedAt.After(lastModified) {
+			lastModified = p.PinnedAt
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`, lastModified
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinFirst(t *testing.T) {
+	loads := []models.LoadWithAssignments{
+		{Load: models.Load{ID: 1}},
+		{Load: models.Load{ID: 2}},
+		{Load: models.Load{ID: 3}},
+		{Load: models.Load{ID: 4}},
+	}
+
+	got := PinFirst(loads, []models.Pin{{LoadID: 4}, {LoadID: 2}})
+
+	var ids []int
+	for _, l := range got {
+		ids = append(ids, l.Load.ID)
+	}
+	assert.Equal(t, []int{2, 4, 1, 3}, ids, "pinned loads first, otherwise in the original order")
+	assert.True(t, got[0].Pinned)
+	assert.True(t, got[1].Pinned)
+	assert.False(t, got[2].Pinned)
+}
+
+func TestPinnedVersion(t *testing.T) {
+	modified := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	pinnedAt := modified.Add(time.Hour)
+
+	etag, lastModified := PinnedVersion(`W/"base"`, modified, nil)
+	assert.Equal(t, `W/"base"`, etag, "no pins leave the validators alone")
+	assert.Equal(t, modified, lastModified)
+
+	one, lastModified := PinnedVersion(`W/"base"`, modified, []models.Pin{{LoadID: 1, PinnedAt: pinnedAt}})
+	assert.NotEqual(t, `W/"base"`, one)
+	assert.Equal(t, pinnedAt, lastModified, "pinning moves Last-Modified")
+
+	two, _ := PinnedVersion(`W/"base"`, modified, []models.Pin{{LoadID: 1, PinnedAt: pinnedAt}, {LoadID: 2, PinnedAt: modified}})
+	assert.NotEqual(t, one, two, "each pin changes the ETag")
+}
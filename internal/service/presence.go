@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+var (
+	ErrInvalidPresenceSubject = errors.New("presence is tracked on an entity or a load")
+	ErrInvalidPresenceMode    = errors.New("presence mode must be viewing or editing")
+)
+
+// presenceTTL is how long a heartbeat keeps someone present. Pages send one
+// every 10 seconds, so a closed page drops out within half a minute.
+const presenceTTL = 30 * time.Second
+
+// PresenceService tracks who is viewing or editing the same entity or load,
+// so people working on the same data see each other before they overwrite
+// each other's changes
+type PresenceService struct {
+	presenceRepo *repository.PresenceRepository
+	entityRepo   *repository.EntityRepository
+	loadRepo     *repository.LoadRepository
+}
+
+func NewPresenceService(
+	presenceRepo *repository.PresenceRepository,
+	entityRepo *repository.EntityRepository,
+	loadRepo *repository.LoadRepository,
+) *PresenceService {
+	return &PresenceService{
+		presenceRepo: presenceRepo,
+		entityRepo:   entityRepo,
+		loadRepo:     loadRepo,
+	}
+}
+
+// Heartbeat records that a person is viewing, or editing, the entity or load
+// kind and id names, and returns who else has been on it within presenceTTL
+// of now
+func (s *PresenceService) Heartbeat(ctx context.Context, personEmail, kind, id, mode string, now time.Time) (*models.PresenceResponse, error) {
+	if mode != models.PresenceViewing && mode != models.PresenceEditing {
+		return nil, ErrInvalidPresenceMode
+	}
+	if err := s.checkSubject(ctx, kind, id); err != nil {
+		return nil, err
+	}
+
+	subject := kind + ":" + id
+	if err := s.presenceRepo.Touch(ctx, subject, personEmail, mode); err != nil {
+		return nil, err
+	}
+	// Stale rows are only ever skipped, so failing to purge them costs space
+	if err := s.presenceRepo.DeleteBefore(ctx, now.Add(-presenceTTL)); err != nil {
+		log.Printf("Presence: %v", err)
+	}
+
+	others, err := s.presenceRepo.ListOthers(ctx, subject, personEmail, now.Add(-presenceTTL))
+	if err != nil {
+		return nil, err
+	}
+	return &models.PresenceResponse{Subject: subject, Others: others}, nil
+}
+
+// checkSubject checks the entity or load kind and id names exists
+func (s *PresenceService) checkSubject(ctx context.Context, kind, id string) error {
+	switch kind {
+	case models.PresenceEntity:
+		exists, err := s.entityRepo.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrEntityNotFound
+		}
+		return nil
+	case models.PresenceLoad:
+		loadID, err := strconv.Atoi(id)
+		if err != nil {
+			return repository.ErrLoadNotFound
+		}
+		exists, err := s.loadRepo.Exists(ctx, loadID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrLoadNotFound
+		}
+		return nil
+	}
+	return ErrInvalidPresenceSubject
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// ErrInvalidRecurrence is returned for recurrence rules that cannot be
+// expanded
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// recurrenceHorizon returns the day through which the occurrences of
+// recurring loads are expanded. It lies well past the heatmap window, so
+// extending rules that have no end never changes a heatmap on screen.
+func recurrenceHorizon(today time.Time) time.Time {
+	return today.AddDate(1, 0, 0)
+}
+
+// parseRecurrence parses an upserted load's recurrence, nil for a one-off.
+// Occurrences may not overlap, so a load spanning several days must end
+// before it next starts.
+func parseRecurrence(req *models.RecurrenceRequest, date time.Time, endDate *time.Time) (*models.Recurrence, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	rule := &models.Recurrence{
+		Frequency: models.RecurrenceFrequency(req.Frequency),
+		Interval:  req.Interval,
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+
+	// The fewest days between two occurrences; months have at least 28
+	gap := rule.Interval
+	switch rule.Frequency {
+	case models.RecurrenceDaily:
+	case models.RecurrenceWeekly:
+		gap *= 7
+	case models.RecurrenceMonthly:
+		gap *= 28
+	default:
+		return nil, fmt.Errorf("%w: frequency must be daily, weekly or monthly", ErrInvalidRecurrence)
+	}
+	days := 1
+	if endDate != nil {
+		days = int(endDate.Sub(date).Hours()/24) + 1
+	}
+	if days > gap {
+		return nil, fmt.Errorf("%w: a load spanning %d days would overlap its next occurrence", ErrInvalidRecurrence, days)
+	}
+
+	if req.Until != "" && req.Count != 0 {
+		return nil, fmt.Errorf("%w: set until or count, not both", ErrInvalidRecurrence)
+	}
+	if req.Until != "" {
+		until, err := time.Parse("2006-01-02", req.Until)
+		if err != nil {
+			return nil, fmt.Errorf("%w: until must be YYYY-MM-DD", ErrInvalidRecurrence)
+		}
+		if until.Before(date) {
+			return nil, fmt.Errorf("%w: until is before date", ErrInvalidRecurrence)
+		}
+		rule.Until = &until
+	}
+	if req.Count != 0 {
+		count := req.Count
+		rule.Count = &count
+	}
+
+	return rule, nil
+}
+
+// occurrences returns the days the occurrences after the first of a load
+// starting on first begin, through the given day. Monthly rules skip months
+// that lack first's day of the month, as an RRULE does.
+func occurrences(first time.Time, rule models.Recurrence, through time.Time) []time.Time {
+	if rule.Until != nil && rule.Until.Before(through) {
+		through = *rule.Until
+	}
+	remaining := -1
+	if rule.Count != nil {
+		remaining = *rule.Count - 1
+	}
+
+	var dates []time.Time
+	for n := 1; remaining != 0; n++ {
+		var next time.Time
+		switch rule.Frequency {
+		case models.RecurrenceWeekly:
+			next = first.AddDate(0, 0, 7*n*rule.Interval)
+		case models.RecurrenceMonthly:
+			next = first.AddDate(0, n*rule.Interval, 0)
+		default:
+			next = first.AddDate(0, 0, n*rule.Interval)
+		}
+		if next.After(through) {
+			break
+		}
+		// AddDate carries the 31st into the next month when this one is
+		// shorter
+		if rule.Frequency == models.RecurrenceMonthly && next.Day() != first.Day() {
+			continue
+		}
+		dates = append(dates, next)
+		remaining--
+	}
+	return dates
+}
+
+// loadStarts returns the first day of each occurrence of a load through the
+// given day, the load's own date first
+func loadStarts(load *models.Load, through time.Time) []time.Time {
+	starts := []time.Time{load.Date}
+	if load.Recurrence != nil {
+		starts = append(starts, occurrences(load.Date, *load.Recurrence, through)...)
+	}
+	return starts
+}
+
+// coveredDays returns every day covered by the occurrences of a load
+// starting on starts, in order
+func coveredDays(load *models.Load, starts []time.Time) []time.Time {
+	span := int(lastDay(load).Sub(load.Date).Hours() / 24)
+	days := make([]time.Time, 0, len(starts)*(span+1))
+	for _, start := range starts {
+		for d := 0; d <= span; d++ {
+			days = append(days, start.AddDate(0, 0, d))
+		}
+	}
+	return days
+}
+
+// ExpandRecurrences extends the occurrences of recurring loads that have not
+// ended through the horizon from today, and returns how many loads it
+// extended
+func (s *LoadService) ExpandRecurrences(ctx context.Context, today time.Time) (int, error) {
+	horizon := recurrenceHorizon(today)
+	loads, err := s.loadRepo.ListRecurringLoads(ctx, horizon)
+	if err != nil {
+		return 0, err
+	}
+
+	expanded, failed := 0, 0
+	for i := range loads {
+		if ctx.Err() != nil {
+			return expanded, ctx.Err()
+		}
+
+		dates := occurrences(loads[i].Date, *loads[i].Recurrence, horizon)
+		if err := s.loadRepo.AddOccurrences(ctx, loads[i].ID, dates, horizon); err != nil {
+			log.Printf("Failed to expand recurring load %d: %v", loads[i].ID, err)
+			failed++
+			continue
+		}
+		expanded++
+	}
+
+	if failed > 0 {
+		return expanded, fmt.Errorf("failed to expand %d of %d recurring loads", failed, len(loads))
+	}
+	return expanded, nil
+}
+
+// RunRecurrenceExpansion calls ExpandRecurrences at the given interval until
+// ctx is cancelled, so loads that repeat without end keep a year of
+// occurrences ahead.
+func (s *LoadService) RunRecurrenceExpansion(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expanded, err := s.ExpandRecurrences(ctx, utcDate(time.Now()))
+		if err != nil {
+			log.Printf("Recurring load expansion: %v", err)
+		}
+		if expanded > 0 {
+			log.Printf("Recurring load expansion: extended %d loads", expanded)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRecurrence(t *testing.T) {
+	date := day(2025, 3, 10)
+
+	rule, err := parseRecurrence(nil, date, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, rule, "a one-off")
+
+	rule, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "weekly"}, date, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Recurrence{Frequency: models.RecurrenceWeekly, Interval: 1}, rule, "every week without end by default")
+
+	rule, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "daily", Interval: 2, Until: "2025-04-01"}, date, nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, rule) && assert.NotNil(t, rule.Until) {
+		assert.Equal(t, 2, rule.Interval)
+		assert.Equal(t, day(2025, 4, 1), *rule.Until)
+		assert.Nil(t, rule.Count)
+	}
+
+	endDate := day(2025, 3, 16)
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "weekly", Count: 4}, date, &endDate)
+	assert.NoError(t, err, "a week-long load may repeat weekly")
+	endDate = day(2025, 3, 17)
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "weekly", Count: 4}, date, &endDate)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence, "eight days overlap the next week")
+
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "daily", Until: "2025-04-01", Count: 3}, date, nil)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence, "until and count")
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "daily", Until: "2025-03-09"}, date, nil)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence, "until before date")
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "daily", Until: "April"}, date, nil)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = parseRecurrence(&models.RecurrenceRequest{Frequency: "yearly"}, date, nil)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+}
+
+func TestOccurrences(t *testing.T) {
+	count := func(n int) *int { return &n }
+	until := day(2025, 3, 31)
+
+	tests := []struct {
+		name    string
+		first   time.Time
+		rule    models.Recurrence
+		through time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "weekly through the horizon",
+			first:   day(2025, 3, 3),
+			rule:    models.Recurrence{Frequency: models.RecurrenceWeekly, Interval: 1},
+			through: day(2025, 3, 24),
+			want:    []time.Time{day(2025, 3, 10), day(2025, 3, 17), day(2025, 3, 24)},
+		},
+		{
+			name:    "every other day until a day",
+			first:   day(2025, 3, 25),
+			rule:    models.Recurrence{Frequency: models.RecurrenceDaily, Interval: 2, Until: &until},
+			through: day(2026, 1, 1),
+			want:    []time.Time{day(2025, 3, 27), day(2025, 3, 29), day(2025, 3, 31)},
+		},
+		{
+			name:    "count includes the first",
+			first:   day(2025, 3, 3),
+			rule:    models.Recurrence{Frequency: models.RecurrenceDaily, Interval: 1, Count: count(3)},
+			through: day(2026, 1, 1),
+			want:    []time.Time{day(2025, 3, 4), day(2025, 3, 5)},
+		},
+		{
+			name:    "a count of one is a one-off",
+			first:   day(2025, 3, 3),
+			rule:    models.Recurrence{Frequency: models.RecurrenceDaily, Interval: 1, Count: count(1)},
+			through: day(2026, 1, 1),
+		},
+		{
+			name:    "monthly skips months without the day",
+			first:   day(2025, 1, 31),
+			rule:    models.Recurrence{Frequency: models.RecurrenceMonthly, Interval: 1, Count: count(4)},
+			through: day(2026, 1, 1),
+			want:    []time.Time{day(2025, 3, 31), day(2025, 5, 31), day(2025, 7, 31)},
+		},
+		{
+			name:    "every third month",
+			first:   day(2025, 1, 15),
+			rule:    models.Recurrence{Frequency: models.RecurrenceMonthly, Interval: 3},
+			through: day(2025, 12, 31),
+			want:    []time.Time{day(2025, 4, 15), day(2025, 7, 15), day(2025, 10, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, occurrences(tt.first, tt.rule, tt.through))
+		})
+	}
+}
+
+func TestCoveredDays(t *testing.T) {
+	endDate := day(2025, 3, 4)
+	load := &models.Load{Date: day(2025, 3, 3), EndDate: &endDate}
+
+	assert.Equal(t,
+		[]time.Time{day(2025, 3, 3), day(2025, 3, 4), day(2025, 3, 10), day(2025, 3, 11)},
+		coveredDays(load, []time.Time{day(2025, 3, 3), day(2025, 3, 10)}))
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// topSourcesLimit is how many sources each utilization summary lists
+const topSourcesLimit = 3
+
+// calibrationDays is how far back calibration reports look by default
+const calibrationDays = 90
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// ReportService builds quarterly utilization reports for performance cycles.
+// Reports for finished quarters are stored the first time they are built, so
+// they stay the same when loads are edited afterwards.
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+}
+
+func NewReportService(reportRepo *repository.ReportRepository) *ReportService {
+	return &ReportService{reportRepo: reportRepo}
+}
+
+// RunQuarterlyReports stores the report for the last finished quarter,
+// checking every interval until ctx is cancelled, so it is ready soon after
+// each quarter ends.
+func (s *ReportService) RunQuarterlyReports(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		quarter := previousQuarter(time.Now())
+		stored, err := s.reportRepo.GetUtilizationReport(ctx, quarter)
+		switch {
+		case err != nil:
+			log.Printf("Quarterly report: %v", err)
+		case stored == nil:
+			if _, err := s.GetUtilizationReport(ctx, quarter); err != nil {
+				log.Printf("Quarterly report %s: %v", quarter, err)
+			} else {
+				log.Printf("Quarterly report %s generated", quarter)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetUtilizationReport returns the utilization report for a quarter such as
+// "2025-Q1", defaulting to the last finished one. Finished quarters are
+// served as first stored; the current quarter is built up to today.
+func (s *ReportService) GetUtilizationReport(ctx context.Context, quarter string) (*models.UtilizationReport, error) {
+	now := time.Now()
+	if quarter == "" {
+		quarter = previousQuarter(now)
+	}
+
+	start, end, err := parseQuarter(quarter)
+	if err != nil {
+		return nil, err
+	}
+	today := utcDate(now)
+	if start.After(today) {
+		return nil, fmt.Errorf("%w: quarter %s has not started", ErrInvalidDate, quarter)
+	}
+
+	complete := end.Before(today)
+	if complete {
+		stored, err := s.reportRepo.GetUtilizationReport(ctx, quarter)
+		if err != nil || stored != nil {
+			return stored, err
+		}
+	} else {
+		end = today
+	}
+
+	entities, err := s.reportRepo.GetEntities(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	capacities, err := s.reportRepo.GetDailyCapacities(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := s.reportRepo.GetDailySourceLoads(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.reportRepo.GetMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UtilizationReport{
+		Quarter:     quarter,
+		From:        start.Format("2006-01-02"),
+		To:          end.Format("2006-01-02"),
+		Complete:    complete,
+		GeneratedAt: now.UTC(),
+	}
+	report.People, report.Groups = summarizeUtilization(entities, capacities, loads, members)
+
+	if !complete {
+		return report, nil
+	}
+
+	// Another request may have stored the quarter first; serve whichever won
+	if err := s.reportRepo.SaveUtilizationReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.GetUtilizationReport(ctx, quarter)
+}
+
+// GetCalibrationReport compares the weights planned for each source's loads
+// to the effort assignees recorded, for loads dated from "from" to "to"
+// (YYYY-MM-DD, default the last 90 days up to today)
+func (s *ReportService) GetCalibrationReport(ctx context.Context, from, to string) (*models.CalibrationReport, error) {
+	end, err := parseDateOrToday(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDate)
+	}
+	start := end.AddDate(0, 0, -calibrationDays)
+	if from != "" {
+		if start, err = time.Parse("2006-01-02", from); err != nil {
+			return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDate)
+		}
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("%w: from is after to", ErrInvalidDate)
+	}
+
+	samples, err := s.reportRepo.GetEffortSamples(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CalibrationReport{
+		From:    start.Format("2006-01-02"),
+		To:      end.Format("2006-01-02"),
+		Sources: summarizeCalibration(samples),
+	}, nil
+}
+
+// summarizeCalibration totals the planned and actual effort of each source,
+// ordered by source
+func summarizeCalibration(samples []models.EffortSample) []models.SourceCalibration {
+	bySource := make(map[string]*models.SourceCalibration)
+	absErrors := make(map[string]float64)
+	for _, sample := range samples {
+		c, ok := bySource[sample.Source]
+		if !ok {
+			c = &models.SourceCalibration{Source: sample.Source}
+			bySource[sample.Source] = c
+		}
+		c.Samples++
+		c.PlannedTotal += sample.Planned
+		c.ActualTotal += sample.Actual
+		absErrors[sample.Source] += math.Abs(sample.Actual - sample.Planned)
+	}
+
+	sources := make([]models.SourceCalibration, 0, len(bySource))
+	for source, c := range bySource {
+		if c.PlannedTotal > 0 {
+			c.Ratio = roundTo(c.ActualTotal/c.PlannedTotal, 2)
+		}
+		c.MeanAbsoluteError = roundTo(absErrors[source]/float64(c.Samples), 2)
+		c.PlannedTotal = roundTo(c.PlannedTotal, 2)
+		c.ActualTotal = roundTo(c.ActualTotal, 2)
+		sources = append(sources, *c)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Source < sources[j].Source })
+
+	return sources
+}
+
+// summarizeUtilization summarizes each person's and group's days. A group's
+// load is its members' combined load against the group's own capacity, as on
+// its heatmap. Loads on days the entity, or the member, was not active are
+// left out.
+func summarizeUtilization(
+	entities []models.Entity,
+	capacities []models.EntityDayCapacity,
+	loads []models.SourceDayLoad,
+	members map[string][]string,
+) (people, groups []models.UtilizationSummary) {
+	type sourceDay struct {
+		date   time.Time
+		source string
+		load   float64
+	}
+	personLoads := make(map[string][]sourceDay)
+	for _, l := range loads {
+		personLoads[l.PersonEmail] = append(personLoads[l.PersonEmail], sourceDay{utcDate(l.Date), l.Source, l.Load})
+	}
+	entityDays := make(map[string]map[time.Time]float64)
+	for _, c := range capacities {
+		if entityDays[c.EntityID] == nil {
+			entityDays[c.EntityID] = make(map[time.Time]float64)
+		}
+		entityDays[c.EntityID][utcDate(c.Date)] = c.Capacity
+	}
+
+	people = []models.UtilizationSummary{}
+	groups = []models.UtilizationSummary{}
+	for _, e := range entities {
+		days := entityDays[e.ID]
+		dayLoads := make(map[time.Time]float64, len(days))
+		sources := make(map[string]float64)
+
+		personIDs := []string{e.ID}
+		if e.Type == models.EntityTypeGroup {
+			personIDs = members[e.ID]
+		}
+		for _, id := range personIDs {
+			for _, l := range personLoads[id] {
+				if _, active := entityDays[id][l.date]; !active {
+					continue
+				}
+				if _, active := days[l.date]; !active {
+					continue
+				}
+				dayLoads[l.date] += l.load
+				sources[l.source] += l.load
+			}
+		}
+
+		summary := summarizeDays(days, dayLoads, sources)
+		summary.EntityID = e.ID
+		summary.Title = e.Title
+		if e.Type == models.EntityTypeGroup {
+			groups = append(groups, summary)
+		} else {
+			people = append(people, summary)
+		}
+	}
+
+	return people, groups
+}
+
+// summarizeDays computes utilization figures from an entity's capacity and
+// load on each day it was active and its load per source
+func summarizeDays(capacities, loads map[time.Time]float64, sources map[string]float64) models.UtilizationSummary {
+	summary := models.UtilizationSummary{Days: len(capacities), TopSources: []models.SourceLoad{}}
+	if len(capacities) == 0 {
+		return summary
+	}
+
+	var totalLoad, totalCapacity float64
+	dailyLoads := make([]float64, 0, len(capacities))
+	for date, capacity := range capacities {
+		load := loads[date]
+		totalLoad += load
+		totalCapacity += capacity
+		dailyLoads = append(dailyLoads, load)
+		if load > capacity {
+			summary.OverloadedDays++
+		}
+	}
+
+	summary.AverageLoad = roundTo(totalLoad/float64(len(capacities)), 2)
+	if totalCapacity > 0 {
+		summary.AverageUtilization = roundTo(totalLoad/totalCapacity*100, 1)
+	}
+
+	// Nearest-rank percentile
+	sort.Float64s(dailyLoads)
+	rank := int(math.Ceil(0.95 * float64(len(dailyLoads))))
+	summary.P95Load = roundTo(dailyLoads[rank-1], 2)
+
+	for source, load := range sources {
+		summary.TopSources = append(summary.TopSources, models.SourceLoad{Source: source, Load: roundTo(load, 2)})
+	}
+	sort.Slice(summary.TopSources, func(i, j int) bool {
+		a, b := summary.TopSources[i], summary.TopSources[j]
+		if a.Load != b.Load {
+			return a.Load > b.Load
+		}
+		return a.Source < b.Source
+	})
+	if len(summary.TopSources) > topSourcesLimit {
+		summary.TopSources = summary.TopSources[:topSourcesLimit]
+	}
+
+	return summary
+}
+
+// parseQuarter returns the first and last day of a quarter such as "2025-Q1"
+func parseQuarter(quarter string) (start, end time.Time, err error) {
+	m := quarterPattern.FindStringSubmatch(quarter)
+	if m == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: quarter must be YYYY-QN", ErrInvalidDate)
+	}
+	year, _ := strconv.Atoi(m[1])
+	q, _ := strconv.Atoi(m[2])
+
+	start = time.Date(year, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 3, -1), nil
+}
+
+// previousQuarter returns the last quarter that ended before now
+func previousQuarter(now time.Time) string {
+	now = now.UTC()
+	q := (int(now.Month())-1)/3 + 1
+	year := now.Year()
+	if q == 1 {
+		return fmt.Sprintf("%d-Q4", year-1)
+	}
+	return fmt.Sprintf("%d-Q%d", year, q-1)
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
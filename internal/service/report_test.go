@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeUtilization(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	entities := []models.Entity{
+		{ID: "alice@example.com", Title: "Alice", Type: models.EntityTypePerson},
+		{ID: "bob@example.com", Title: "Bob", Type: models.EntityTypePerson},
+		{ID: "backend", Title: "Backend", Type: models.EntityTypeGroup},
+	}
+	var capacities []models.EntityDayCapacity
+	for d := 1; d <= 4; d++ {
+		capacities = append(capacities,
+			models.EntityDayCapacity{EntityID: "alice@example.com", Date: day(d), Capacity: 4},
+			models.EntityDayCapacity{EntityID: "backend", Date: day(d), Capacity: 10})
+	}
+	// Bob left after the 2nd
+	capacities = append(capacities,
+		models.EntityDayCapacity{EntityID: "bob@example.com", Date: day(1), Capacity: 5},
+		models.EntityDayCapacity{EntityID: "bob@example.com", Date: day(2), Capacity: 5})
+
+	loads := []models.SourceDayLoad{
+		{PersonEmail: "alice@example.com", Date: day(1), Source: "jira", Load: 3},
+		{PersonEmail: "alice@example.com", Date: day(1), Source: "gcal", Load: 2}, // over capacity
+		{PersonEmail: "alice@example.com", Date: day(2), Source: "jira", Load: 1},
+		{PersonEmail: "alice@example.com", Date: day(3), Source: "github", Load: 1},
+		{PersonEmail: "alice@example.com", Date: day(3), Source: "manual", Load: 1},
+		{PersonEmail: "bob@example.com", Date: day(2), Source: "jira", Load: 4},
+		{PersonEmail: "bob@example.com", Date: day(3), Source: "jira", Load: 9}, // after leaving
+	}
+	members := map[string][]string{"backend": {"alice@example.com", "bob@example.com"}}
+
+	people, groups := summarizeUtilization(entities, capacities, loads, members)
+
+	assert.Equal(t, []models.UtilizationSummary{
+		{
+			EntityID: "alice@example.com", Title: "Alice", Days: 4,
+			AverageLoad: 2, AverageUtilization: 50, P95Load: 5, OverloadedDays: 1,
+			TopSources: []models.SourceLoad{{Source: "jira", Load: 4}, {Source: "gcal", Load: 2}, {Source: "github", Load: 1}},
+		},
+		{
+			EntityID: "bob@example.com", Title: "Bob", Days: 2,
+			AverageLoad: 2, AverageUtilization: 40, P95Load: 4,
+			TopSources: []models.SourceLoad{{Source: "jira", Load: 4}},
+		},
+	}, people)
+	assert.Equal(t, []models.UtilizationSummary{
+		{
+			EntityID: "backend", Title: "Backend", Days: 4,
+			AverageLoad: 3, AverageUtilization: 30, P95Load: 5,
+			TopSources: []models.SourceLoad{{Source: "jira", Load: 8}, {Source: "gcal", Load: 2}, {Source: "github", Load: 1}},
+		},
+	}, groups)
+}
+
+func TestSummarizeUtilizationWithoutDays(t *testing.T) {
+	people, groups := summarizeUtilization(
+		[]models.Entity{{ID: "new@example.com", Title: "New", Type: models.EntityTypePerson}}, nil, nil, nil)
+
+	assert.Equal(t, []models.UtilizationSummary{
+		{EntityID: "new@example.com", Title: "New", TopSources: []models.SourceLoad{}},
+	}, people)
+	assert.Empty(t, groups)
+}
+
+func TestParseQuarter(t *testing.T) {
+	start, end, err := parseQuarter("2024-Q1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = parseQuarter("2024-Q4")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), end)
+
+	for _, invalid := range []string{"", "2024-Q0", "2024-Q5", "2024Q1", "24-Q1"} {
+		_, _, err := parseQuarter(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidDate), "%q should be rejected", invalid)
+	}
+}
+
+func TestPreviousQuarter(t *testing.T) {
+	assert.Equal(t, "2024-Q4", previousQuarter(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2025-Q1", previousQuarter(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2025-Q3", previousQuarter(time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestSummarizeCalibration(t *testing.T) {
+	got := summarizeCalibration([]models.EffortSample{
+		{Source: "jira", Planned: 2, Actual: 3},
+		{Source: "gcal", Planned: 1, Actual: 1},
+		{Source: "jira", Planned: 2, Actual: 4},
+		{Source: "", Planned: 0, Actual: 1},
+	})
+
+	assert.Equal(t, []models.SourceCalibration{
+		// No planned weight leaves the ratio at zero
+		{Source: "", Samples: 1, PlannedTotal: 0, ActualTotal: 1, Ratio: 0, MeanAbsoluteError: 1},
+		{Source: "gcal", Samples: 1, PlannedTotal: 1, ActualTotal: 1, Ratio: 1, MeanAbsoluteError: 0},
+		{Source: "jira", Samples: 2, PlannedTotal: 4, ActualTotal: 7, Ratio: 1.75, MeanAbsoluteError: 1.5},
+	}, got)
+	assert.Empty(t, summarizeCalibration(nil))
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrOutOfScope is returned when a group-scoped API key writes for people or
+// groups outside its groups
+var ErrOutOfScope = errors.New("outside the API key's groups")
+
+type keyScopeKey struct{}
+
+// WithKeyScope returns a context for a request made with an API key
+// restricted to the given groups
+func WithKeyScope(ctx context.Context, groups []string) context.Context {
+	return context.WithValue(ctx, keyScopeKey{}, groups)
+}
+
+// KeyScope returns the groups the request's API key is restricted to, and
+// false for keys that may write anything
+func KeyScope(ctx context.Context) ([]string, bool) {
+	groups, ok := ctx.Value(keyScopeKey{}).([]string)
+	return groups, ok
+}
+
+// CheckEntityScope returns ErrOutOfScope when the request's API key is
+// restricted to groups and the entity is neither one of them nor a member of
+// one
+func (s *LoadService) CheckEntityScope(ctx context.Context, entityID string) error {
+	groups, ok := KeyScope(ctx)
+	if !ok || slices.Contains(groups, entityID) {
+		return nil
+	}
+	return s.checkScope(ctx, []string{entityID})
+}
+
+// checkScope returns ErrOutOfScope when the request's API key is restricted
+// to groups and any of the people is not a member of one
+func (s *LoadService) checkScope(ctx context.Context, emails []string) error {
+	groups, ok := KeyScope(ctx)
+	if !ok || len(emails) == 0 {
+		return nil
+	}
+
+	outside, err := s.groupRepo.NotMembers(ctx, groups, emails)
+	if err != nil {
+		return err
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("%w: %s", ErrOutOfScope, strings.Join(outside, ", "))
+	}
+	return nil
+}
+
+// checkLoadScope is checkScope for an existing load's assignees as well as
+// the people about to be assigned, so a scoped key can neither take over
+// another team's load nor hand its own to them
+func (s *LoadService) checkLoadScope(ctx context.Context, current []string, assignments []string) error {
+	return s.checkScope(ctx, append(current, assignments...))
+}
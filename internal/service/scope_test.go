@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyScope(t *testing.T) {
+	_, scoped := KeyScope(context.Background())
+	assert.False(t, scoped, "requests are unrestricted by default")
+
+	groups, scoped := KeyScope(WithKeyScope(context.Background(), []string{"platform", "infra"}))
+	assert.True(t, scoped)
+	assert.Equal(t, []string{"platform", "infra"}, groups)
+}
+
+func TestCheckEntityScopeWithoutMembershipLookups(t *testing.T) {
+	// No group repository: these must be decided without asking it
+	s := &LoadService{}
+
+	assert.NoError(t, s.CheckEntityScope(context.Background(), "anyone@example.com"), "unrestricted keys may write anything")
+
+	ctx := WithKeyScope(context.Background(), []string{"platform"})
+	assert.NoError(t, s.CheckEntityScope(ctx, "platform"), "a key may write for its own group")
+	assert.NoError(t, s.checkScope(ctx, nil), "writing for no one is in scope")
+}
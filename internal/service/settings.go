@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// SettingsRefreshInterval is how often settings are read again, so changes
+// made through another instance apply here too
+const SettingsRefreshInterval = 30 * time.Second
+
+const (
+	// maxAlertCooldownMinutes is the longest alert cooldown, a week
+	maxAlertCooldownMinutes = 7 * 24 * 60
+	// maxWebhookSecretLength is the longest webhook signing secret
+	maxWebhookSecretLength = 256
+)
+
+// Settings keys, as stored
+const (
+	settingColorThresholds = "color_thresholds"
+	settingDefaultCapacity = "default_capacity"
+	settingAlertCooldown   = "alert_cooldown_minutes"
+	settingWebhookSecret   = "webhook_secret"
+)
+
+// ErrNotSettingsAdmin is returned when someone not in ADMIN_EMAILS changes
+// the settings
+var ErrNotSettingsAdmin = errors.New("only admins may change settings")
+
+// ErrInvalidSettings is returned for settings out of their range
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// DefaultSettings returns the settings keys take until admins set them
+func DefaultSettings() models.Settings {
+	return models.Settings{
+		ColorThresholds: append([]float64(nil), defaultColorThresholds[:]...),
+		DefaultCapacity: defaultPersonCapacity,
+	}
+}
+
+// SettingsService keeps the settings admins tune at runtime: heatmap color
+// thresholds, the default capacity of new persons and groups, the overload
+// alert cooldown and the webhook signing secret. They are read once at
+// startup and then every SettingsRefreshInterval, and every change is
+// recorded in the domain event log.
+type SettingsService struct {
+	settingsRepo *repository.SettingsRepository
+	events       *EventLog
+
+	// admins may change the settings, by lowercase email
+	admins map[string]bool
+
+	mu      sync.RWMutex
+	current models.Settings
+}
+
+func NewSettingsService(settingsRepo *repository.SettingsRepository) *SettingsService {
+	return &SettingsService{
+		settingsRepo: settingsRepo,
+		current:      DefaultSettings(),
+	}
+}
+
+// SetAdmins sets who may change the settings
+func (s *SettingsService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// IsAdmin reports whether email may change the settings
+func (s *SettingsService) IsAdmin(email string) bool {
+	return email != "" && s.admins[strings.ToLower(email)]
+}
+
+// RecordEvents records settings changes in the domain event log
+func (s *SettingsService) RecordEvents(events *EventLog) {
+	s.events = events
+}
+
+// Current returns the settings in effect. A nil SettingsService has the
+// defaults.
+func (s *SettingsService) Current() models.Settings {
+	if s == nil {
+		return DefaultSettings()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// DefaultCapacity is the daily capacity of persons and groups created
+// without one
+func (s *SettingsService) DefaultCapacity() float64 {
+	return s.Current().DefaultCapacity
+}
+
+// Load reads the settings and puts them in effect
+func (s *SettingsService) Load(ctx context.Context) error {
+	stored, err := s.settingsRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	settings, err := decodeSettings(stored)
+	if err != nil {
+		return err
+	}
+	s.apply(settings)
+	return nil
+}
+
+// RunRefresh reads the settings every interval until ctx is done, keeping
+// the ones in effect when reading fails
+func (s *SettingsService) RunRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Load(ctx); err != nil {
+			log.Printf("Settings refresh: %v", err)
+		}
+	}
+}
+
+// Update changes the settings given in req as an admin, and returns them all
+func (s *SettingsService) Update(ctx context.Context, actorEmail string, req *models.UpdateSettingsRequest) (*models.Settings, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotSettingsAdmin
+	}
+	if err := checkSettings(req); err != nil {
+		return nil, err
+	}
+
+	// Only settings given a new value are stored and recorded
+	current := s.Current()
+	changed := make(map[string]interface{})
+	if req.ColorThresholds != nil && !slices.Equal(req.ColorThresholds, current.ColorThresholds) {
+		changed[settingColorThresholds] = req.ColorThresholds
+	}
+	if req.DefaultCapacity != nil && *req.DefaultCapacity != current.DefaultCapacity {
+		changed[settingDefaultCapacity] = *req.DefaultCapacity
+	}
+	if req.AlertCooldownMinutes != nil && *req.AlertCooldownMinutes != current.AlertCooldownMinutes {
+		changed[settingAlertCooldown] = *req.AlertCooldownMinutes
+	}
+	if req.WebhookSecret != nil && *req.WebhookSecret != current.WebhookSecret {
+		changed[settingWebhookSecret] = *req.WebhookSecret
+	}
+	if len(changed) == 0 {
+		return &current, nil
+	}
+
+	values := make(map[string]json.RawMessage, len(changed))
+	for key, value := range changed {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		values[key] = raw
+	}
+	if err := s.settingsRepo.Set(ctx, values, actorEmail); err != nil {
+		return nil, err
+	}
+	// The event log only notes whether the secret was set or removed
+	if _, ok := changed[settingWebhookSecret]; ok {
+		changed[settingWebhookSecret] = *req.WebhookSecret != ""
+	}
+	s.events.Record(ctx, models.EventSettingsUpdated, actorEmail, nil, changed)
+
+	if err := s.Load(ctx); err != nil {
+		return nil, err
+	}
+	settings := s.Current()
+	return &settings, nil
+}
+
+// apply puts settings in effect
+func (s *SettingsService) apply(settings models.Settings) {
+	s.mu.Lock()
+	s.current = settings
+	s.mu.Unlock()
+	setColorThresholds(settings.ColorThresholds)
+}
+
+// decodeSettings fills the defaults in with the keys that have been set,
+// ignoring keys this version does not know
+func decodeSettings(stored *repository.StoredSettings) (models.Settings, error) {
+	settings := DefaultSettings()
+	settings.UpdatedAt = stored.UpdatedAt
+	settings.UpdatedBy = stored.UpdatedBy
+
+	targets := map[string]interface{}{
+		settingColorThresholds: &settings.ColorThresholds,
+		settingDefaultCapacity: &settings.DefaultCapacity,
+		settingAlertCooldown:   &settings.AlertCooldownMinutes,
+		settingWebhookSecret:   &settings.WebhookSecret,
+	}
+	for key, target := range targets {
+		value, ok := stored.Values[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			return models.Settings{}, fmt.Errorf("failed to decode setting %s: %w", key, err)
+		}
+	}
+	settings.WebhookSecretSet = settings.WebhookSecret != ""
+	return settings, nil
+}
+
+// checkSettings checks the settings of req are in their range
+func checkSettings(req *models.UpdateSettingsRequest) error {
+	if req.ColorThresholds != nil {
+		t := req.ColorThresholds
+		if len(t) != len(defaultColorThresholds) {
+			return fmt.Errorf("%w: color_thresholds must list %d ratios", ErrInvalidSettings, len(defaultColorThresholds))
+		}
+		for i, ratio := range t {
+			if math.IsNaN(ratio) || math.IsInf(ratio, 0) || ratio <= 0 || (i > 0 && ratio <= t[i-1]) {
+				return fmt.Errorf("%w: color_thresholds must be positive and ascending", ErrInvalidSettings)
+			}
+		}
+	}
+	if c := req.DefaultCapacity; c != nil && (math.IsNaN(*c) || math.IsInf(*c, 0) || *c <= 0) {
+		return fmt.Errorf("%w: default_capacity must be positive", ErrInvalidSettings)
+	}
+	if m := req.AlertCooldownMinutes; m != nil && (*m < 0 || *m > maxAlertCooldownMinutes) {
+		return fmt.Errorf("%w: alert_cooldown_minutes must be from 0 to %d", ErrInvalidSettings, maxAlertCooldownMinutes)
+	}
+	if s := req.WebhookSecret; s != nil && len(*s) > maxWebhookSecretLength {
+		return fmt.Errorf("%w: webhook_secret must be at most %d characters", ErrInvalidSettings, maxWebhookSecretLength)
+	}
+	return nil
+}
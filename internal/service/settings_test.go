@@ -0,0 +1,137 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSettings(t *testing.T) {
+	capacity, cooldown, secret := 6.0, 30, "s3cret"
+	require.NoError(t, checkSettings(&models.UpdateSettingsRequest{
+		ColorThresholds:      []float64{0.1, 0.3, 0.5, 0.9, 1.2},
+		DefaultCapacity:      &capacity,
+		AlertCooldownMinutes: &cooldown,
+		WebhookSecret:        &secret,
+	}))
+	require.NoError(t, checkSettings(&models.UpdateSettingsRequest{}), "nothing to change")
+
+	zero, negative, tooLong := 0.0, -1, string(make([]byte, maxWebhookSecretLength+1))
+	weekAndADay := maxAlertCooldownMinutes + 24*60
+	for name, req := range map[string]*models.UpdateSettingsRequest{
+		"too few thresholds":    {ColorThresholds: []float64{0.2, 0.4, 0.6, 0.8}},
+		"unordered thresholds":  {ColorThresholds: []float64{0.2, 0.6, 0.4, 0.8, 1.0}},
+		"repeated thresholds":   {ColorThresholds: []float64{0.2, 0.4, 0.4, 0.8, 1.0}},
+		"zero threshold":        {ColorThresholds: []float64{0, 0.4, 0.6, 0.8, 1.0}},
+		"zero capacity":         {DefaultCapacity: &zero},
+		"negative cooldown":     {AlertCooldownMinutes: &negative},
+		"cooldown over a week":  {AlertCooldownMinutes: &weekAndADay},
+		"secret over 256 chars": {WebhookSecret: &tooLong},
+	} {
+		require.ErrorIs(t, checkSettings(req), ErrInvalidSettings, name)
+	}
+}
+
+func TestDecodeSettings(t *testing.T) {
+	settings, err := decodeSettings(&repository.StoredSettings{Values: map[string]json.RawMessage{}})
+	require.NoError(t, err)
+	require.Equal(t, DefaultSettings(), settings, "keys never set take their defaults")
+
+	updatedAt := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+	settings, err = decodeSettings(&repository.StoredSettings{
+		Values: map[string]json.RawMessage{
+			"default_capacity":       json.RawMessage(`6.5`),
+			"alert_cooldown_minutes": json.RawMessage(`15`),
+			"webhook_secret":         json.RawMessage(`"s3cret"`),
+			"retired_setting":        json.RawMessage(`true`),
+		},
+		UpdatedAt: &updatedAt,
+		UpdatedBy: "admin@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []float64{0.2, 0.4, 0.6, 0.8, 1.0}, settings.ColorThresholds)
+	require.Equal(t, 6.5, settings.DefaultCapacity)
+	require.Equal(t, 15, settings.AlertCooldownMinutes)
+	require.True(t, settings.WebhookSecretSet)
+	require.Equal(t, "admin@example.com", settings.UpdatedBy)
+
+	body, err := json.Marshal(settings)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), "s3cret", "the secret is never sent back")
+
+	_, err = decodeSettings(&repository.StoredSettings{Values: map[string]json.RawMessage{
+		"default_capacity": json.RawMessage(`"five"`),
+	}})
+	require.Error(t, err)
+}
+
+func TestColorThresholds(t *testing.T) {
+	t.Cleanup(func() { setColorThresholds(nil) })
+
+	require.Equal(t, "#fbbf24", getHeatmapColor(2.5, 5), "50% is amber by default")
+	require.Equal(t, "#dc2626", getHeatmapColor(4.5, 5))
+
+	setColorThresholds([]float64{0.1, 0.2, 0.3, 0.4, 0.5})
+	require.Equal(t, "#8B0000", getHeatmapColor(2.6, 5), "past the last threshold is overloaded")
+	require.Equal(t, "#dc2626", getHeatmapColor(2.5, 5))
+	require.Equal(t, "#22c55e", getHeatmapColor(0.5, 5))
+	require.Equal(t, "#e5e7eb", getHeatmapColor(0, 5), "days without load stay gray")
+
+	days := recolor([]models.HeatmapDay{{Load: 2.6, Capacity: 5, Color: "#fbbf24"}})
+	require.Equal(t, "#8B0000", days[0].Color, "snapshots are recolored")
+}
+
+func TestAlertCooldown(t *testing.T) {
+	s := NewWebhookService("", nil, nil)
+	now := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-12", now))
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-12", now), "no settings, no cooldown")
+
+	settings := NewSettingsService(nil)
+	current := DefaultSettings()
+	current.AlertCooldownMinutes = 30
+	settings.apply(current)
+	s.UseSettings(settings)
+
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-12", now))
+	require.False(t, s.startAlertCooldown("person:a@example.com|2025-03-12", now.Add(29*time.Minute)), "within the cooldown")
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-13", now.Add(29*time.Minute)), "another day has its own cooldown")
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-12", now.Add(30*time.Minute)), "after the cooldown")
+
+	s.endAlertCooldown("person:a@example.com|2025-03-13")
+	require.True(t, s.startAlertCooldown("person:a@example.com|2025-03-13", now.Add(31*time.Minute)), "undelivered alerts end their cooldown")
+}
+
+func TestWebhookSignature(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(WebhookSignatureHeader)
+	}))
+	defer server.Close()
+
+	s := NewWebhookService(server.URL, nil, nil)
+	body := []byte(`{"event":"overload_alert"}`)
+	require.NoError(t, s.deliver(t.Context(), server.URL, body))
+	require.Empty(t, signature, "deliveries are not signed without a secret")
+
+	settings := NewSettingsService(nil)
+	current := DefaultSettings()
+	current.WebhookSecret = "s3cret"
+	settings.apply(current)
+	s.UseSettings(settings)
+
+	require.NoError(t, s.deliver(t.Context(), server.URL, body))
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	require.NotEqual(t, SignWebhook("other", body), signature)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// shadowScenario returns the scenario the loads of a source in shadow mode
+// are kept in, or 0 when the source is live
+func (s *LoadService) shadowScenario(ctx context.Context, source string) (int, error) {
+	if s.scenarioRepo == nil || source == "" {
+		return 0, nil
+	}
+	scenarioID, err := s.scenarioRepo.GetShadowScenario(ctx, source)
+	if errors.Is(err, repository.ErrShadowSourceNotFound) {
+		return 0, nil
+	}
+	return scenarioID, err
+}
+
+// upsertShadow keeps an upserted load of a source in shadow mode in the
+// source's scenario, replacing what was kept for its external ID. Nothing
+// real changes: no persons are auto-created, no webhooks are sent and no
+// events are recorded.
+func (s *LoadService) upsertShadow(ctx context.Context, scenarioID int, externalID string, load *models.Load, assignments []models.LoadAssignment) (*models.UpsertLoadResponse, error) {
+	emails := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		emails = append(emails, a.PersonEmail)
+	}
+	missing, err := s.entityRepo.Missing(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s (sources in shadow mode do not create persons)", ErrUnknownAssignee, strings.Join(missing, ", "))
+	}
+
+	loads := shadowLoads(scenarioID, load, loadStarts(load, recurrenceHorizon(utcDate(time.Now()))), assignments)
+	if dry := DryRun(ctx); dry != nil {
+		dry.Action = models.DryRunCreate
+		dry.Result = loads
+		return nil, nil
+	}
+
+	if err := s.scenarioRepo.ReplaceShadowLoads(ctx, scenarioID, externalID, loads); err != nil {
+		return nil, err
+	}
+	return &models.UpsertLoadResponse{Success: true, Shadow: true}, nil
+}
+
+// shadowLoads splits a load into the scenario loads it puts on each assignee
+// on each day of its occurrences starting on starts, weighted as the
+// load_days view would count it
+func shadowLoads(scenarioID int, load *models.Load, starts []time.Time, assignments []models.LoadAssignment) []models.ScenarioLoad {
+	shares := loadShares(load, starts)
+	days := make([]time.Time, 0, len(shares))
+	for day := range shares {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	loads := make([]models.ScenarioLoad, 0, len(days)*len(assignments))
+	for _, day := range days {
+		for _, a := range assignments {
+			loads = append(loads, models.ScenarioLoad{
+				ScenarioID:  scenarioID,
+				Title:       load.Title,
+				Date:        day,
+				PersonEmail: a.PersonEmail,
+				Weight:      a.Weight * shares[day],
+			})
+		}
+	}
+	return loads
+}
+
+// DeleteShadowLoad removes what a source in shadow mode upserted as
+// externalID, for tombstones the source sends. It reports false, changing
+// nothing, when the source is live.
+func (s *LoadService) DeleteShadowLoad(ctx context.Context, source, externalID string) (bool, error) {
+	scenarioID, err := s.shadowScenario(ctx, source)
+	if err != nil || scenarioID == 0 {
+		return false, err
+	}
+	if dry := DryRun(ctx); dry != nil {
+		dry.Action = models.DryRunDelete
+		return true, nil
+	}
+	return true, s.scenarioRepo.DeleteShadowLoads(ctx, scenarioID, externalID)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowLoads(t *testing.T) {
+	end := day(2025, 3, 11)
+	load := &models.Load{Title: "Migration", Date: day(2025, 3, 10), EndDate: &end, Spread: models.LoadSpreadEven}
+	assignments := []models.LoadAssignment{
+		{PersonEmail: "alice@example.com", Weight: 2},
+		{PersonEmail: "bob@example.com", Weight: 1},
+	}
+
+	loads := shadowLoads(7, load, []time.Time{load.Date, day(2025, 3, 17)}, assignments)
+	require.Len(t, loads, 8, "one per assignee and day of each occurrence")
+	assert.Equal(t, models.ScenarioLoad{
+		ScenarioID: 7, Title: "Migration", Date: day(2025, 3, 10), PersonEmail: "alice@example.com", Weight: 1,
+	}, loads[0])
+	assert.Equal(t, "bob@example.com", loads[1].PersonEmail)
+	assert.Equal(t, 0.5, loads[1].Weight, "weights are split over the days")
+	assert.Equal(t, day(2025, 3, 18), loads[7].Date, "days are in order")
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/gti/heatmap-internal/internal/repository"
+)
+
+// ErrNotAdmin is returned when someone not in ADMIN_EMAILS edits the
+// incident notes
+var ErrNotAdmin = errors.New("only admins may edit incident notes")
+
+const (
+	// statusIncidentLimit is how many incident notes the status page lists
+	statusIncidentLimit = 10
+	// statusPingTimeout bounds the database check, so the status page
+	// answers promptly when the database hangs
+	statusPingTimeout = 2 * time.Second
+)
+
+// StatusService reports, for the public status page, whether the service
+// and its database are up, when each source last synced, and the incident
+// notes admins have posted
+type StatusService struct {
+	ping            func(ctx context.Context) error
+	integrationRepo *repository.IntegrationRepository
+	incidentRepo    *repository.IncidentRepository
+	startedAt       time.Time
+
+	// admins may edit incident notes, by lowercase email
+	admins map[string]bool
+}
+
+// NewStatusService reports on a server started at startedAt whose database
+// is checked with ping
+func NewStatusService(
+	ping func(ctx context.Context) error,
+	integrationRepo *repository.IntegrationRepository,
+	incidentRepo *repository.IncidentRepository,
+	startedAt time.Time,
+) *StatusService {
+	return &StatusService{
+		ping:            ping,
+		integrationRepo: integrationRepo,
+		incidentRepo:    incidentRepo,
+		startedAt:       startedAt,
+	}
+}
+
+// SetAdmins sets who may edit incident notes
+func (s *StatusService) SetAdmins(emails []string) {
+	s.admins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		s.admins[strings.ToLower(email)] = true
+	}
+}
+
+// IsAdmin reports whether email may edit incident notes
+func (s *StatusService) IsAdmin(email string) bool {
+	return email != "" && s.admins[strings.ToLower(email)]
+}
+
+// GetReport checks the database and gathers the status page. It does not
+// fail: when the database is down, or its data cannot be read, that is what
+// the report says.
+func (s *StatusService) GetReport(ctx context.Context, now time.Time) *models.StatusReport {
+	report := &models.StatusReport{
+		GeneratedAt: now,
+		StartedAt:   s.startedAt,
+		Uptime:      now.Sub(s.startedAt),
+		Sources:     []models.SourceHealth{},
+		Incidents:   []models.IncidentNote{},
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, statusPingTimeout)
+	defer cancel()
+	if err := s.ping(pingCtx); err != nil {
+		report.DatabaseError = "database unreachable"
+		return report
+	}
+	report.DatabaseOK = true
+
+	sources, err := s.integrationRepo.GetSourceSyncs(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		report.DatabaseError = err.Error()
+		return report
+	}
+	if sources != nil {
+		report.Sources = sources
+	}
+	incidents, err := s.incidentRepo.ListRecent(ctx, statusIncidentLimit)
+	if err != nil {
+		report.DatabaseError = err.Error()
+		return report
+	}
+	report.Incidents = incidents
+
+	return report
+}
+
+// AddIncident posts an open incident note as an admin
+func (s *StatusService) AddIncident(ctx context.Context, actorEmail, body string) (*models.IncidentNote, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotAdmin
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyNote
+	}
+	return s.incidentRepo.Create(ctx, actorEmail, body)
+}
+
+// UpdateIncident edits, resolves or reopens an incident note as an admin
+func (s *StatusService) UpdateIncident(ctx context.Context, actorEmail string, id int, req *models.UpdateIncidentNoteRequest) (*models.IncidentNote, error) {
+	if !s.IsAdmin(actorEmail) {
+		return nil, ErrNotAdmin
+	}
+	body := req.Body
+	if body != nil {
+		trimmed := strings.TrimSpace(*body)
+		if trimmed == "" {
+			return nil, ErrEmptyNote
+		}
+		body = &trimmed
+	}
+	return s.incidentRepo.Update(ctx, id, body, req.Resolved)
+}
+
+// DeleteIncident removes an incident note as an admin
+func (s *StatusService) DeleteIncident(ctx context.Context, actorEmail string, id int) error {
+	if !s.IsAdmin(actorEmail) {
+		return ErrNotAdmin
+	}
+	return s.incidentRepo.Delete(ctx, id)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// tagColors are the colors tags are shown in, picked by a hash of the tag
+// so a tag keeps its color everywhere. They stay clear of the heatmap's
+// green to red scale.
+var tagColors = []string{
+	"#2563eb", // Blue
+	"#7c3aed", // Violet
+	"#db2777", // Pink
+	"#0891b2", // Cyan
+	"#4f46e5", // Indigo
+	"#0d9488", // Teal
+	"#9333ea", // Purple
+	"#475569", // Slate
+}
+
+// untaggedColor is the color untagged loads are shown in
+const untaggedColor = "#9ca3af"
+
+// NormalizeTag trims and lowercases a tag, so tags match case-insensitively
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// cleanTags normalizes tags, dropping empty and repeated ones, in order
+func cleanTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	sort.Strings(cleaned)
+	return cleaned
+}
+
+// TagColor returns the color a tag is shown in, gray for untagged loads
+func TagColor(tag string) string {
+	if tag == "" {
+		return untaggedColor
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tag))
+	return tagColors[h.Sum32()%uint32(len(tagColors))]
+}
+
+// withTag returns the loads tagged with tag, or all of them for no tag
+func withTag(loads []models.LoadWithAssignments, tag string) []models.LoadWithAssignments {
+	if tag == "" {
+		return loads
+	}
+	tagged := make([]models.LoadWithAssignments, 0, len(loads))
+	for _, l := range loads {
+		for _, t := range l.Load.Tags {
+			if t == tag {
+				tagged = append(tagged, l)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// TagBreakdown sums the weights of a day's loads per tag, for the legend in
+// day details: heaviest first, with untagged loads last under an empty tag.
+// A load with several tags counts towards each. It is empty when no load is
+// tagged.
+func TagBreakdown(loads []models.LoadWithAssignments) []models.TagLoad {
+	totals := make(map[string]float64)
+	var untagged float64
+	for _, l := range loads {
+		var weight float64
+		for _, a := range l.Assignments {
+			weight += a.Weight
+		}
+		if len(l.Load.Tags) == 0 {
+			untagged += weight
+			continue
+		}
+		for _, tag := range l.Load.Tags {
+			totals[tag] += weight
+		}
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+
+	breakdown := make([]models.TagLoad, 0, len(totals)+1)
+	for tag, load := range totals {
+		breakdown = append(breakdown, models.TagLoad{Tag: tag, Load: load, Color: TagColor(tag)})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Load != breakdown[j].Load {
+			return breakdown[i].Load > breakdown[j].Load
+		}
+		return breakdown[i].Tag < breakdown[j].Tag
+	})
+	if untagged > 0 {
+		breakdown = append(breakdown, models.TagLoad{Load: untagged, Color: untaggedColor})
+	}
+	return breakdown
+}
+
+// TagVersion returns the ETag of a heatmap with the given version filtered
+// to a tag, so filtered heatmaps are tagged apart from unfiltered ones
+func TagVersion(etag, tag string) string {
+	if tag == "" {
+		return etag
+	}
+	sum := sha256.Sum256([]byte(etag + "|tag:" + tag))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
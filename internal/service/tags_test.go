@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanTags(t *testing.T) {
+	assert.Equal(t, []string{"meeting", "project"}, cleanTags([]string{" Project", "meeting", "", "MEETING"}))
+	assert.Equal(t, []string{}, cleanTags(nil))
+}
+
+func TestTagColor(t *testing.T) {
+	assert.Equal(t, TagColor("meeting"), TagColor("meeting"), "a tag keeps its color")
+	assert.Contains(t, tagColors, TagColor("project"))
+	assert.Equal(t, untaggedColor, TagColor(""))
+}
+
+func TestTagBreakdown(t *testing.T) {
+	load := func(weight float64, tags ...string) models.LoadWithAssignments {
+		return models.LoadWithAssignments{
+			Load:        models.Load{Tags: tags},
+			Assignments: []models.LoadAssignment{{Weight: weight}},
+		}
+	}
+	loads := []models.LoadWithAssignments{
+		load(1, "meeting"),
+		load(2, "project"),
+		load(0.5, "meeting", "project"),
+		load(1.5),
+	}
+
+	assert.Equal(t, []models.TagLoad{
+		{Tag: "project", Load: 2.5, Color: TagColor("project")},
+		{Tag: "meeting", Load: 1.5, Color: TagColor("meeting")},
+		{Tag: "", Load: 1.5, Color: untaggedColor},
+	}, TagBreakdown(loads), "a load counts towards each of its tags, untagged ones last")
+
+	assert.Nil(t, TagBreakdown([]models.LoadWithAssignments{load(1)}), "no breakdown without tags")
+}
+
+func TestWithTag(t *testing.T) {
+	loads := []models.LoadWithAssignments{
+		{Load: models.Load{ID: 1, Tags: []string{"meeting"}}},
+		{Load: models.Load{ID: 2}},
+		{Load: models.Load{ID: 3, Tags: []string{"meeting", "project"}}},
+	}
+
+	tagged := withTag(loads, "meeting")
+	assert.Len(t, tagged, 2)
+	assert.Equal(t, 3, tagged[1].Load.ID)
+	assert.Len(t, withTag(loads, ""), 3, "no tag keeps every load")
+	assert.Empty(t, withTag(loads, "travel"))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// Page sizes of utilization lists, in rows
+const (
+	defaultUtilizationLimit = 1000
+	maxUtilizationLimit     = 10000
+)
+
+// ListUtilization returns the load, capacity and utilization ratio of each
+// entity on every day of a date range, as flat rows for BI tools. Rows are
+// ordered by entity, as listed, then by date. When more rows follow,
+// NextCursor is set; pass it back with the same entities and range for the
+// next page.
+func (s *HeatmapService) ListUtilization(ctx context.Context, entityIDs []string, fromStr, toStr, cursor string, limit int) (*models.UtilizationList, error) {
+	from, to, err := parseListRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*models.Entity, 0, len(entityIDs))
+	ids := make([]string, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		if slices.Contains(ids, id) {
+			continue
+		}
+		entity, err := s.entityRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, id)
+		}
+		entities = append(entities, entity)
+		ids = append(ids, id)
+	}
+
+	start, first := 0, from
+	if cursor != "" {
+		entityID, date, err := decodeUtilizationCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		start = slices.Index(ids, entityID)
+		if start < 0 || date.Before(from) || date.After(to) {
+			return nil, ErrInvalidCursor
+		}
+		first = date.AddDate(0, 0, 1)
+	}
+	if limit <= 0 {
+		limit = defaultUtilizationLimit
+	}
+	if limit > maxUtilizationLimit {
+		limit = maxUtilizationLimit
+	}
+
+	list := &models.UtilizationList{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+		Rows: make([]models.UtilizationRow, 0),
+	}
+	for i := start; i < len(entities); i++ {
+		if i > start {
+			first = from
+		}
+		if first.After(to) {
+			continue
+		}
+
+		// Read one day more than the page has room for, to know whether
+		// another page follows
+		last := first.AddDate(0, 0, limit-len(list.Rows))
+		if last.After(to) {
+			last = to
+		}
+		days, err := s.computeHeatmapDays(ctx, entities[i], first, last, nil, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			if len(list.Rows) == limit {
+				prev := list.Rows[len(list.Rows)-1]
+				list.NextCursor = encodeUtilizationCursor(prev.Entity, prev.Date)
+				return list, nil
+			}
+			list.Rows = append(list.Rows, utilizationRow(entities[i].ID, day))
+		}
+	}
+	return list, nil
+}
+
+// utilizationRow turns a heatmap day into a utilization row
+func utilizationRow(entityID string, day models.HeatmapDay) models.UtilizationRow {
+	row := models.UtilizationRow{
+		Entity:   entityID,
+		Date:     day.Date.Format("2006-01-02"),
+		Load:     day.Load,
+		Capacity: day.Capacity,
+	}
+	if day.Capacity > 0 {
+		ratio := roundTo(day.Load/day.Capacity, 4)
+		row.Ratio = &ratio
+	}
+	return row
+}
+
+// encodeUtilizationCursor turns the last row of a page into an opaque token
+// for clients
+func encodeUtilizationCursor(entityID, date string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(date + "/" + entityID))
+}
+
+// decodeUtilizationCursor reverses encodeUtilizationCursor
+func decodeUtilizationCursor(token string) (string, time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidCursor
+	}
+	dateStr, entityID, ok := strings.Cut(string(raw), "/")
+	if !ok || entityID == "" {
+		return "", time.Time{}, ErrInvalidCursor
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidCursor
+	}
+	return entityID, date, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtilizationCursor(t *testing.T) {
+	entityID, date, err := decodeUtilizationCursor(encodeUtilizationCursor("a@example.com", "2025-03-04"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", entityID)
+	assert.Equal(t, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), date)
+
+	_, _, err = decodeUtilizationCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestUtilizationRow(t *testing.T) {
+	date := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	row := utilizationRow("a@example.com", models.HeatmapDay{Date: date, Load: 1, Capacity: 3})
+	assert.Equal(t, "2025-03-04", row.Date)
+	if assert.NotNil(t, row.Ratio) {
+		assert.Equal(t, 0.3333, *row.Ratio)
+	}
+
+	row = utilizationRow("a@example.com", models.HeatmapDay{Date: date, Load: 1})
+	assert.Nil(t, row.Ratio, "no ratio without capacity")
+}
@@ -3,21 +3,59 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gti/heatmap-internal/internal/models"
 	"github.com/gti/heatmap-internal/internal/repository"
+	"github.com/gti/heatmap-internal/internal/tracing"
 )
 
+// WebhookSignatureHeader carries the signature of a delivery's body when a
+// webhook secret is set, so receivers can check the delivery came from here
+const WebhookSignatureHeader = "X-Heatmap-Signature"
+
+// endpointCacheTTL is how long the webhook endpoints are reused before
+// they are read again. Changes made through this server apply at once.
+const endpointCacheTTL = 30 * time.Second
+
 type WebhookService struct {
 	webhookURL   string
 	loadRepo     *repository.LoadRepository
 	capacityRepo *repository.CapacityRepository
 	client       *http.Client
+	links        *LinkSigner
+	deliveries   *repository.IntegrationRepository
+	endpointRepo *repository.WebhookEndpointRepository
+	groupRepo    *repository.GroupRepository
+	settings     *SettingsService
+
+	mu        sync.Mutex
+	endpoints []models.WebhookEndpoint
+	readAt    time.Time
+
+	// alertedAt is when each overload alert, by person or group and day,
+	// was last sent, for the alert cooldown
+	alertMu   sync.Mutex
+	alertedAt map[string]time.Time
+}
+
+// webhookDestination is a URL an event is delivered to
+type webhookDestination struct {
+	endpointID int // 0 for WEBHOOK_DESTINATION_URL
+	url        string
 }
 
 func NewWebhookService(
@@ -35,73 +73,762 @@ func NewWebhookService(
 	}
 }
 
+// SetHTTPClient replaces the HTTP client used to deliver webhooks
+func (s *WebhookService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetLinkSigner includes signed links to the person's day and settings in
+// the notifications about them
+func (s *WebhookService) SetLinkSigner(links *LinkSigner) {
+	s.links = links
+}
+
+// RecordDeliveries stores the outcome of every delivery for the integration
+// health report
+func (s *WebhookService) RecordDeliveries(integrationRepo *repository.IntegrationRepository) {
+	s.deliveries = integrationRepo
+}
+
+// SetEndpoints delivers each event to the enabled webhook endpoints
+// subscribed to it, besides the webhook URL, if any
+func (s *WebhookService) SetEndpoints(endpointRepo *repository.WebhookEndpointRepository) {
+	s.endpointRepo = endpointRepo
+}
+
+// AlertGroups also checks the groups of a load's assignees after each
+// upsert, alerting when a group's summed load goes over its capacity
+func (s *WebhookService) AlertGroups(groupRepo *repository.GroupRepository) {
+	s.groupRepo = groupRepo
+}
+
+// UseSettings takes the overload alert cooldown and the secret signing
+// deliveries from the settings
+func (s *WebhookService) UseSettings(settings *SettingsService) {
+	s.settings = settings
+}
+
+// ListEndpoints returns every webhook endpoint
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	return s.endpointRepo.List(ctx)
+}
+
+// GetEndpoint returns one webhook endpoint
+func (s *WebhookService) GetEndpoint(ctx context.Context, id int) (*models.WebhookEndpoint, error) {
+	return s.endpointRepo.Get(ctx, id)
+}
+
+// CreateEndpoint adds a webhook endpoint, enabled unless req says otherwise
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req *models.CreateWebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	enabled := req.Enabled == nil || *req.Enabled
+	endpoint, err := s.endpointRepo.Create(ctx, req.URL, req.Description, uniqueEvents(req.Events), enabled)
+	if err != nil {
+		return nil, err
+	}
+	s.forgetEndpoints()
+	return endpoint, nil
+}
+
+// UpdateEndpoint changes the fields of a webhook endpoint set in req
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id int, req *models.UpdateWebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.endpointRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.Events != nil {
+		endpoint.Events = uniqueEvents(req.Events)
+	}
+	if req.Enabled != nil {
+		endpoint.Enabled = *req.Enabled
+	}
+
+	endpoint, err = s.endpointRepo.Update(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	s.forgetEndpoints()
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id int) error {
+	if err := s.endpointRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.forgetEndpoints()
+	return nil
+}
+
+// uniqueEvents returns events sorted, without repeats
+func uniqueEvents(events []string) []string {
+	events = slices.Clone(events)
+	slices.Sort(events)
+	return slices.Compact(events)
+}
+
+// forgetEndpoints makes the next delivery read the endpoints again
+func (s *WebhookService) forgetEndpoints() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = nil
+	s.readAt = time.Time{}
+}
+
+// destinations returns where event is delivered: the webhook URL, if any,
+// then each enabled endpoint subscribed to event. Endpoints that cannot be
+// read are left out.
+func (s *WebhookService) destinations(ctx context.Context, event string) []webhookDestination {
+	var destinations []webhookDestination
+	if s.webhookURL != "" {
+		destinations = append(destinations, webhookDestination{url: s.webhookURL})
+	}
+	if s.endpointRepo == nil {
+		return destinations
+	}
+
+	endpoints, err := s.readEndpoints(ctx)
+	if err != nil {
+		log.Printf("Webhook: %v", err)
+		return destinations
+	}
+	return append(destinations, subscribedEndpoints(endpoints, event)...)
+}
+
+// subscribedEndpoints returns the enabled endpoints subscribed to event
+func subscribedEndpoints(endpoints []models.WebhookEndpoint, event string) []webhookDestination {
+	var destinations []webhookDestination
+	for _, e := range endpoints {
+		if e.Enabled && slices.Contains(e.Events, event) {
+			destinations = append(destinations, webhookDestination{endpointID: e.ID, url: e.URL})
+		}
+	}
+	return destinations
+}
+
+// readEndpoints returns the webhook endpoints, read at most once every
+// endpointCacheTTL
+func (s *WebhookService) readEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.readAt.IsZero() && time.Since(s.readAt) < endpointCacheTTL {
+		return s.endpoints, nil
+	}
+
+	endpoints, err := s.endpointRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.endpoints, s.readAt = endpoints, time.Now()
+	return endpoints, nil
+}
+
+// subscribed reports whether event is delivered anywhere, so callers can
+// skip the work of building it
+func (s *WebhookService) subscribed(ctx context.Context, event string) bool {
+	return len(s.destinations(ctx, event)) > 0
+}
+
 // CheckAndAlert checks if a person is overloaded on a future date and sends webhook alert
 // This runs in a goroutine to avoid blocking the main request
 func (s *WebhookService) CheckAndAlert(ctx context.Context, personEmail string, date time.Time) {
+	s.CheckAndAlertRange(ctx, personEmail, date, date)
+}
+
+// CheckAndAlertRange is CheckAndAlert for every future day from start to
+// end, such as the days of a load spanning several, sending one alert per
+// overloaded day
+func (s *WebhookService) CheckAndAlertRange(ctx context.Context, personEmail string, start, end time.Time) {
+	var days []time.Time
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		days = append(days, date)
+	}
+	s.CheckAndAlertDays(ctx, personEmail, days)
+}
+
+// CheckAndAlertDays is CheckAndAlert for each future day of days, in order,
+// such as the days of every occurrence of a recurring load
+func (s *WebhookService) CheckAndAlertDays(ctx context.Context, personEmail string, days []time.Time) {
+	// Skip if no destination receives overload alerts
+	if !s.subscribed(ctx, models.WebhookEventOverload) {
+		return
+	}
+
+	// Only alert for future dates
+	days = upcomingDays(days, time.Now())
+	if len(days) == 0 {
+		return
+	}
+
 	go func() {
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		// Detach from the request: its context is cancelled as soon as the
+		// handler returns (and by the request deadline middleware)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
 		defer cancel()
 
-		// Only alert for future dates
-		if date.Before(time.Now().Truncate(24 * time.Hour)) {
-			return
+		for _, date := range days {
+			s.alertIfOverloaded(ctx, personEmail, date)
 		}
+	}()
+}
 
-		// Skip if no webhook URL configured
-		if s.webhookURL == "" {
-			return
+// upcomingDays drops the days of days, in order, before now's date
+func upcomingDays(days []time.Time, now time.Time) []time.Time {
+	today := now.Truncate(24 * time.Hour)
+	for len(days) > 0 && days[0].Before(today) {
+		days = days[1:]
+	}
+	return days
+}
+
+// CheckGroupsAndAlert is CheckAndAlertDays for the groups the persons
+// belong to: it sends a group overload alert for each future day of days on
+// which a group's summed load is over the group's capacity. Each group is
+// checked once, however many of the persons are in it.
+func (s *WebhookService) CheckGroupsAndAlert(ctx context.Context, personEmails []string, days []time.Time) {
+	if s.groupRepo == nil || !s.subscribed(ctx, models.WebhookEventGroupOverload) {
+		return
+	}
+
+	days = upcomingDays(days, time.Now())
+	if len(days) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		seen := make(map[string]bool)
+		for _, email := range personEmails {
+			groups, err := s.groupRepo.GetGroupsForPerson(ctx, email)
+			if err != nil {
+				log.Printf("Webhook: failed to get groups of %s: %v", email, err)
+				continue
+			}
+			for _, groupID := range groups {
+				if seen[groupID] {
+					continue
+				}
+				seen[groupID] = true
+				s.alertIfGroupOverloaded(ctx, groupID, days)
+			}
 		}
+	}()
+}
 
-		// Get total load for the person on this date
-		load, err := s.loadRepo.GetPersonLoadForDate(ctx, personEmail, date)
-		if err != nil {
-			log.Printf("Webhook: failed to get load for %s on %s: %v", personEmail, date.Format("2006-01-02"), err)
-			return
+// alertIfGroupOverloaded sends a group overload alert for each day of days,
+// in order, on which the group's summed load is over its capacity
+func (s *WebhookService) alertIfGroupOverloaded(ctx context.Context, groupID string, days []time.Time) {
+	start, end := days[0], days[len(days)-1]
+	loads, err := s.loadRepo.GetGroupLoadForDateRange(ctx, groupID, start, end, nil, "", nil)
+	if err != nil {
+		log.Printf("Webhook: failed to get load for group %s: %v", groupID, err)
+		return
+	}
+	capacities, err := s.capacityRepo.GetGroupCapacitiesForRange(ctx, groupID, start, end, nil)
+	if err != nil {
+		log.Printf("Webhook: failed to get capacity for group %s: %v", groupID, err)
+		return
+	}
+
+	for _, date := range days {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		load, capacity := loads[day], capacities[day]
+		if load <= capacity {
+			continue
+		}
+		subject := "group:" + groupID + "|" + day.Format("2006-01-02")
+		if !s.startAlertCooldown(subject, time.Now()) {
+			continue
 		}
 
-		// Get effective capacity
-		capacity, err := s.capacityRepo.GetEffectiveCapacity(ctx, personEmail, date)
+		members, err := s.loadRepo.GetGroupMemberLoads(ctx, groupID, day)
 		if err != nil {
-			log.Printf("Webhook: failed to get capacity for %s: %v", personEmail, err)
-			return
+			s.endAlertCooldown(subject)
+			log.Printf("Webhook: failed to get member loads for group %s on %s: %v", groupID, day.Format("2006-01-02"), err)
+			continue
 		}
 
-		// Check if overloaded
-		if load <= capacity {
+		payload := groupOverloadPayload(groupID, day, load, capacity, members)
+		payload.Metadata = webhookMetadata(ctx)
+		if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+			s.endAlertCooldown(subject)
+			log.Printf("Webhook: failed to send group alert: %v", err)
+			continue
+		}
+		log.Printf("Webhook: sent group overload alert for %s on %s", groupID, payload.Date)
+	}
+}
+
+// groupOverloadPayload describes a group's overload on date and the members
+// with load that day, most loaded first
+func groupOverloadPayload(groupID string, date time.Time, load, capacity float64, members []models.RebalanceMember) models.WebhookGroupOverloadPayload {
+	contributing := []models.GroupOverloadMember{}
+	for _, m := range members {
+		if m.LoadBefore > 0 {
+			contributing = append(contributing, models.GroupOverloadMember{
+				Email:    m.Email,
+				Title:    m.Title,
+				Load:     m.LoadBefore,
+				Capacity: m.Capacity,
+			})
+		}
+	}
+	sort.SliceStable(contributing, func(i, j int) bool {
+		return contributing[i].Load > contributing[j].Load
+	})
+
+	d := date.Format("2006-01-02")
+	return models.WebhookGroupOverloadPayload{
+		Event:    models.WebhookEventGroupOverload,
+		GroupID:  groupID,
+		Date:     d,
+		Load:     load,
+		Capacity: capacity,
+		Members:  contributing,
+		Message: fmt.Sprintf("Group %s is overloaded on %s (load: %.1f, capacity: %.1f) across %d members",
+			groupID, d, load, capacity, len(contributing)),
+	}
+}
+
+// alertIfOverloaded sends an overload alert if a person's load on date is
+// over their capacity
+func (s *WebhookService) alertIfOverloaded(ctx context.Context, personEmail string, date time.Time) {
+	// Get total load for the person on this date
+	load, err := s.loadRepo.GetPersonLoadForDate(ctx, personEmail, date)
+	if err != nil {
+		log.Printf("Webhook: failed to get load for %s on %s: %v", personEmail, date.Format("2006-01-02"), err)
+		return
+	}
+
+	// Get effective capacity
+	capacity, err := s.capacityRepo.GetEffectiveCapacity(ctx, personEmail, date)
+	if err != nil {
+		log.Printf("Webhook: failed to get capacity for %s: %v", personEmail, err)
+		return
+	}
+
+	// Check if overloaded
+	if load <= capacity {
+		return
+	}
+	subject := "person:" + personEmail + "|" + date.Format("2006-01-02")
+	if !s.startAlertCooldown(subject, time.Now()) {
+		return
+	}
+
+	// Send webhook alert
+	payload := models.WebhookAlertPayload{
+		PersonEmail: personEmail,
+		Date:        date,
+		Load:        load,
+		Capacity:    capacity,
+		Message:     fmt.Sprintf("%s is overloaded on %s (load: %.1f, capacity: %.1f)", personEmail, date.Format("2006-01-02"), load, capacity),
+		Links:       s.links.Links(personEmail, date, time.Now()),
+		Metadata:    webhookMetadata(ctx),
+	}
+
+	if err := s.sendWebhook(ctx, models.WebhookEventOverload, payload); err != nil {
+		s.endAlertCooldown(subject)
+		log.Printf("Webhook: failed to send alert: %v", err)
+		return
+	}
+
+	log.Printf("Webhook: sent overload alert for %s on %s", personEmail, date.Format("2006-01-02"))
+}
+
+// startAlertCooldown reports whether an overload alert about subject may be
+// sent at now, the alert cooldown since the last one having passed, and if
+// so starts a new cooldown
+func (s *WebhookService) startAlertCooldown(subject string, now time.Time) bool {
+	cooldown := time.Duration(s.settings.Current().AlertCooldownMinutes) * time.Minute
+	if cooldown <= 0 {
+		return true
+	}
+
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	if s.alertedAt == nil {
+		s.alertedAt = make(map[string]time.Time)
+	}
+	if at, ok := s.alertedAt[subject]; ok && now.Sub(at) < cooldown {
+		return false
+	}
+	// Forget the alerts whose cooldown is over, so the map stays small
+	for key, at := range s.alertedAt {
+		if now.Sub(at) >= cooldown {
+			delete(s.alertedAt, key)
+		}
+	}
+	s.alertedAt[subject] = now
+	return true
+}
+
+// endAlertCooldown ends the cooldown of an alert that was not delivered
+func (s *WebhookService) endAlertCooldown(subject string) {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	delete(s.alertedAt, subject)
+}
+
+// NotifyOffboarded tells the webhook destinations that a person was
+// offboarded, listing their groups and the loads they were removed from.
+// Like CheckAndAlert it delivers in the background.
+func (s *WebhookService) NotifyOffboarded(ctx context.Context, result *models.OffboardPersonResponse) {
+	if !s.subscribed(ctx, models.WebhookEventPersonOffboarded) {
+		return
+	}
+
+	payload := models.WebhookOffboardingPayload{
+		Event:              models.WebhookEventPersonOffboarded,
+		PersonEmail:        result.Email,
+		LastDay:            result.LastDay,
+		Groups:             result.Groups,
+		RemovedAssignments: result.RemovedAssignments,
+		Message: fmt.Sprintf("%s was offboarded after %s; %d future loads need reassignment",
+			result.Email, result.LastDay, len(result.RemovedAssignments)),
+		Metadata: webhookMetadata(ctx),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+			log.Printf("Webhook: failed to send offboarding notice for %s: %v", result.Email, err)
 			return
 		}
+		log.Printf("Webhook: sent offboarding notice for %s", result.Email)
+	}()
+}
 
-		// Send webhook alert
-		payload := models.WebhookAlertPayload{
-			PersonEmail: personEmail,
-			Date:        date,
-			Load:        load,
-			Capacity:    capacity,
-			Message:     fmt.Sprintf("%s is overloaded on %s (load: %.1f, capacity: %.1f)", personEmail, date.Format("2006-01-02"), load, capacity),
+// NotifyLoadDeleted re-checks the capacity of each assignee of an upcoming
+// load deleted by its source system and tells the webhook destinations where
+// they stand without it, so alerts raised for the load can be cleared. Like
+// CheckAndAlert it runs in the background.
+func (s *WebhookService) NotifyLoadDeleted(ctx context.Context, deleted *models.LoadWithAssignments) {
+	if deleted.Load.Date.Before(time.Now().Truncate(24*time.Hour)) || !s.subscribed(ctx, models.WebhookEventLoadDeleted) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		date := deleted.Load.Date
+		for _, a := range deleted.Assignments {
+			load, err := s.loadRepo.GetPersonLoadForDate(ctx, a.PersonEmail, date)
+			if err != nil {
+				log.Printf("Webhook: failed to get load for %s on %s: %v", a.PersonEmail, date.Format("2006-01-02"), err)
+				continue
+			}
+			capacity, err := s.capacityRepo.GetEffectiveCapacity(ctx, a.PersonEmail, date)
+			if err != nil {
+				log.Printf("Webhook: failed to get capacity for %s: %v", a.PersonEmail, err)
+				continue
+			}
+
+			payload := loadDeletedPayload(deleted, a.PersonEmail, load, capacity)
+			payload.Links = s.links.Links(a.PersonEmail, date, time.Now())
+			payload.Metadata = webhookMetadata(ctx)
+			if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+				log.Printf("Webhook: failed to send load deletion for %s: %v", a.PersonEmail, err)
+				continue
+			}
+			log.Printf("Webhook: sent load deletion for %s on %s", a.PersonEmail, date.Format("2006-01-02"))
 		}
+	}()
+}
+
+// loadDeletedPayload describes where an assignee stands on the date of a
+// deleted load
+func loadDeletedPayload(deleted *models.LoadWithAssignments, personEmail string, load, capacity float64) models.WebhookLoadDeletedPayload {
+	date := deleted.Load.Date.Format("2006-01-02")
+	externalID := ""
+	if deleted.Load.ExternalID != nil {
+		externalID = *deleted.Load.ExternalID
+	}
+
+	standing := "within capacity"
+	if load > capacity {
+		standing = "still overloaded"
+	}
+	return models.WebhookLoadDeletedPayload{
+		Event:       models.WebhookEventLoadDeleted,
+		PersonEmail: personEmail,
+		LoadID:      deleted.Load.ID,
+		ExternalID:  externalID,
+		Title:       deleted.Load.Title,
+		Date:        date,
+		Load:        load,
+		Capacity:    capacity,
+		Overloaded:  load > capacity,
+		Message: fmt.Sprintf("%q on %s was deleted; %s is %s (load: %.1f, capacity: %.1f)",
+			deleted.Load.Title, date, personEmail, standing, load, capacity),
+	}
+}
+
+// NotifyLoadCreated tells the webhook destinations about a load an upsert
+// created. Like CheckAndAlert it delivers in the background.
+func (s *WebhookService) NotifyLoadCreated(ctx context.Context, load *models.Load, assignments []models.LoadAssignment) {
+	if !s.subscribed(ctx, models.WebhookEventLoadCreated) {
+		return
+	}
 
-		if err := s.sendWebhook(payload); err != nil {
-			log.Printf("Webhook: failed to send alert: %v", err)
+	payload := loadCreatedPayload(load, assignments)
+	payload.Metadata = webhookMetadata(ctx)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+			log.Printf("Webhook: failed to send load creation for %d: %v", payload.LoadID, err)
 			return
 		}
+		log.Printf("Webhook: sent load creation for %d", payload.LoadID)
+	}()
+}
 
-		log.Printf("Webhook: sent overload alert for %s on %s", personEmail, date.Format("2006-01-02"))
+// loadCreatedPayload describes a created load and who it is assigned to
+func loadCreatedPayload(load *models.Load, assignments []models.LoadAssignment) models.WebhookLoadCreatedPayload {
+	date := load.Date.Format("2006-01-02")
+	payload := models.WebhookLoadCreatedPayload{
+		Event:       models.WebhookEventLoadCreated,
+		LoadID:      load.ID,
+		Title:       load.Title,
+		Date:        date,
+		Assignments: assignments,
+		Message:     fmt.Sprintf("%q on %s was created", load.Title, date),
+	}
+	if load.ExternalID != nil {
+		payload.ExternalID = *load.ExternalID
+	}
+	if load.Source != nil {
+		payload.Source = *load.Source
+	}
+	if load.EndDate != nil {
+		payload.EndDate = load.EndDate.Format("2006-01-02")
+	}
+	return payload
+}
+
+// NotifyConflicts tells the webhook destinations about each assignee, of
+// emails, for whom a load's time window overlaps others of theirs on an
+// upcoming day of days. Like CheckAndAlert it works in the background, and
+// like an overload alert each conflict is sent once per alert cooldown.
+func (s *WebhookService) NotifyConflicts(ctx context.Context, load *models.Load, emails []string, days []time.Time) {
+	if _, ok := loadWindow(load); !ok || !s.subscribed(ctx, models.WebhookEventScheduleConflict) {
+		return
+	}
+	days = upcomingDays(days, time.Now())
+	if len(days) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		for _, email := range emails {
+			for _, date := range days {
+				s.alertIfConflicting(ctx, load, email, date)
+			}
+		}
+	}()
+}
+
+// alertIfConflicting sends a schedule conflict alert if a load's time window
+// overlaps others of a person's loads on date
+func (s *WebhookService) alertIfConflicting(ctx context.Context, load *models.Load, personEmail string, date time.Time) {
+	day, err := s.loadRepo.GetLoadsForEntityOnDate(ctx, personEmail, models.EntityTypePerson, date)
+	if err != nil {
+		log.Printf("Webhook: failed to get loads for %s on %s: %v", personEmail, date.Format("2006-01-02"), err)
+		return
+	}
+	loadConflict, conflicts, ok := scheduleConflicts(load, day)
+	if !ok {
+		return
+	}
+	subject := "conflict:" + personEmail + "|" + date.Format("2006-01-02") + "|" + strconv.Itoa(load.ID)
+	if !s.startAlertCooldown(subject, time.Now()) {
+		return
+	}
+
+	titles := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		titles[i] = fmt.Sprintf("%q (%s–%s)", c.Title, c.Start, c.End)
+	}
+	payload := models.WebhookScheduleConflictPayload{
+		Event:       models.WebhookEventScheduleConflict,
+		PersonEmail: personEmail,
+		Date:        date.Format("2006-01-02"),
+		Load:        loadConflict,
+		Conflicts:   conflicts,
+		Message: fmt.Sprintf("%q (%s–%s) overlaps %s for %s on %s", load.Title, loadConflict.Start, loadConflict.End,
+			strings.Join(titles, ", "), personEmail, date.Format("2006-01-02")),
+		Links:    s.links.Links(personEmail, date, time.Now()),
+		Metadata: webhookMetadata(ctx),
+	}
+
+	if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+		s.endAlertCooldown(subject)
+		log.Printf("Webhook: failed to send schedule conflict: %v", err)
+		return
+	}
+	log.Printf("Webhook: sent schedule conflict for %s on %s", personEmail, payload.Date)
+}
+
+// NotifyCapacityChanged tells the webhook destinations that a person's
+// capacity changed, by actor, as described. change is nil when an override
+// was removed. Like CheckAndAlert it delivers in the background.
+func (s *WebhookService) NotifyCapacityChanged(ctx context.Context, personEmail, actor string, change *models.UpdateCapacityRequest, description string) {
+	if !s.subscribed(ctx, models.WebhookEventCapacityChanged) {
+		return
+	}
+
+	payload := models.WebhookCapacityChangedPayload{
+		Event:       models.WebhookEventCapacityChanged,
+		PersonEmail: personEmail,
+		ChangedBy:   actor,
+		Change:      change,
+		Message:     fmt.Sprintf("%s's capacity changed: %s", personEmail, description),
+		Metadata:    webhookMetadata(ctx),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		if err := s.sendWebhook(ctx, payload.Event, payload); err != nil {
+			log.Printf("Webhook: failed to send capacity change for %s: %v", personEmail, err)
+			return
+		}
+		log.Printf("Webhook: sent capacity change for %s", personEmail)
 	}()
 }
 
-// sendWebhook sends a JSON payload to the configured webhook URL
-func (s *WebhookService) sendWebhook(payload models.WebhookAlertPayload) error {
+// RemindUnacknowledged asks an assignee to acknowledge a load. Unlike the
+// alerts it delivers synchronously, so callers only record reminders that
+// were sent. Reminders sent outside a request each start their own trace.
+func (s *WebhookService) RemindUnacknowledged(ctx context.Context, p models.PendingAcknowledgment) error {
+	ctx = tracing.Ensure(ctx)
+	date := p.Date.Format("2006-01-02")
+	return s.sendWebhook(ctx, models.WebhookEventLoadUnacknowledged, models.WebhookAcknowledgmentReminderPayload{
+		Event:       models.WebhookEventLoadUnacknowledged,
+		PersonEmail: p.PersonEmail,
+		LoadID:      p.LoadID,
+		Title:       p.Title,
+		Date:        date,
+		Weight:      p.Weight,
+		AssignedAt:  p.AssignedAt,
+		Message: fmt.Sprintf("%s has not acknowledged %q on %s (weight %.1f), assigned %s",
+			p.PersonEmail, p.Title, date, p.Weight, p.AssignedAt.Format("2006-01-02")),
+		Links:    s.links.Links(p.PersonEmail, p.Date, time.Now()),
+		Metadata: webhookMetadata(ctx),
+	})
+}
+
+// NotifyAnomaly tells the webhook destinations about a person-day whose load
+// spiked over their recent average. Like RemindUnacknowledged it delivers
+// synchronously, so callers only record anomalies that were alerted on.
+func (s *WebhookService) NotifyAnomaly(ctx context.Context, a models.LoadAnomaly) error {
+	ctx = tracing.Ensure(ctx)
+	payload := anomalyPayload(a)
+	payload.Links = s.links.Links(a.PersonEmail, a.Date, time.Now())
+	payload.Metadata = webhookMetadata(ctx)
+	return s.sendWebhook(ctx, models.WebhookEventAnomaly, payload)
+}
+
+// anomalyPayload describes how far a person-day's load is over their
+// baseline
+func anomalyPayload(a models.LoadAnomaly) models.WebhookAnomalyPayload {
+	date := a.Date.Format("2006-01-02")
+	ratio := a.Load / a.Baseline
+	return models.WebhookAnomalyPayload{
+		Event:       models.WebhookEventAnomaly,
+		PersonEmail: a.PersonEmail,
+		Date:        date,
+		Load:        a.Load,
+		Baseline:    a.Baseline,
+		Ratio:       ratio,
+		Message: fmt.Sprintf("%s has an unusual load on %s: %.1f, %.1fx their recent average of %.1f",
+			a.PersonEmail, date, a.Load, ratio, a.Baseline),
+	}
+}
+
+// webhookMetadata returns the trace and span IDs of ctx for a payload, nil
+// when it has none
+func webhookMetadata(ctx context.Context) *models.WebhookMetadata {
+	traceID, spanID := tracing.IDs(ctx)
+	if traceID == "" {
+		return nil
+	}
+	return &models.WebhookMetadata{TraceID: traceID, SpanID: spanID}
+}
+
+// sendWebhook sends a JSON payload to every destination of event,
+// recording whether it was delivered to each and the trace it was sent for.
+// It fails when there is no destination or none accepted the payload.
+func (s *WebhookService) sendWebhook(ctx context.Context, event string, payload interface{}) error {
+	destinations := s.destinations(ctx, event)
+	if len(destinations) == 0 {
+		return fmt.Errorf("no webhook destination subscribed to %s", event)
+	}
+
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, s.webhookURL, bytes.NewBuffer(body))
+	var failures []error
+	for _, d := range destinations {
+		err := s.deliver(ctx, d.url, body)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", d.url, err))
+		}
+		s.recordDelivery(ctx, event, d.endpointID, err)
+	}
+	if len(failures) == len(destinations) {
+		return errors.Join(failures...)
+	}
+	for _, err := range failures {
+		log.Printf("Webhook: failed to send %s: %v", event, err)
+	}
+	return nil
+}
+
+// recordDelivery stores whether event was delivered to an endpoint
+func (s *WebhookService) recordDelivery(ctx context.Context, event string, endpointID int, deliveryErr error) {
+	if s.deliveries == nil {
+		return
+	}
+	traceID, spanID := tracing.IDs(ctx)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.deliveries.RecordDelivery(ctx, event, endpointID, traceID, spanID, deliveryErr); err != nil {
+		log.Printf("Webhook: %v", err)
+	}
+}
+
+// deliver posts a JSON body to url, with the traceparent of ctx's span
+func (s *WebhookService) deliver(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if secret := s.settings.Current().WebhookSecret; secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, body))
+	}
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -116,6 +843,14 @@ func (s *WebhookService) sendWebhook(payload models.WebhookAlertPayload) error {
 	return nil
 }
 
+// SignWebhook returns the signature of a delivery's body under secret:
+// "sha256=" and the hex HMAC-SHA256 of the body
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
 // CheckAllAffectedPersons checks and alerts for all persons affected by a load
 func (s *WebhookService) CheckAllAffectedPersons(ctx context.Context, loadID int, date time.Time) {
 	persons, err := s.loadRepo.GetAffectedPersons(ctx, loadID)
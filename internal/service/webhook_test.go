@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gti/heatmap-internal/e2e/helpers"
+	"github.com/gti/heatmap-internal/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWebhookReplay(t *testing.T) {
+	rec, err := helpers.NewHTTPRecorder("testdata/cassettes/webhook_alert.json", helpers.RecorderModeFromEnv())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, rec.Save()) }()
+
+	s := NewWebhookService("https://n8n.example.com/webhook/alerts", nil, nil)
+	s.SetHTTPClient(rec.Client())
+
+	payload := models.WebhookAlertPayload{
+		PersonEmail: "alice@example.com",
+		Date:        time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Load:        6,
+		Capacity:    5,
+		Message:     "alice@example.com is overloaded on 2025-03-10 (load: 6.0, capacity: 5.0)",
+	}
+
+	// First recorded delivery succeeds
+	require.NoError(t, s.sendWebhook(context.Background(), "overload_alert", payload))
+
+	// Second recorded delivery hit an unregistered webhook
+	err = s.sendWebhook(context.Background(), "overload_alert", payload)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "status 404")
+}
+
+func TestLoadDeletedPayload(t *testing.T) {
+	externalID := "gcal-123"
+	deleted := &models.LoadWithAssignments{Load: models.Load{
+		ID:         7,
+		ExternalID: &externalID,
+		Title:      "Sprint Review",
+		Date:       time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+	}}
+
+	p := loadDeletedPayload(deleted, "alice@example.com", 3, 5)
+	require.Equal(t, "load_deleted", p.Event)
+	require.Equal(t, 7, p.LoadID)
+	require.Equal(t, "gcal-123", p.ExternalID)
+	require.Equal(t, "2025-03-10", p.Date)
+	require.False(t, p.Overloaded)
+	require.Equal(t, `"Sprint Review" on 2025-03-10 was deleted; alice@example.com is within capacity (load: 3.0, capacity: 5.0)`, p.Message)
+
+	p = loadDeletedPayload(deleted, "alice@example.com", 6, 5)
+	require.True(t, p.Overloaded)
+	require.Contains(t, p.Message, "still overloaded")
+}
+
+func TestSubscribedEndpoints(t *testing.T) {
+	endpoints := []models.WebhookEndpoint{
+		{ID: 1, URL: "https://a.example.com", Events: []string{"load_created", "overload_alert"}, Enabled: true},
+		{ID: 2, URL: "https://b.example.com", Events: []string{"overload_alert"}, Enabled: false},
+		{ID: 3, URL: "https://c.example.com", Events: []string{"capacity_changed"}, Enabled: true},
+	}
+
+	require.Equal(t, []webhookDestination{{endpointID: 1, url: "https://a.example.com"}},
+		subscribedEndpoints(endpoints, "overload_alert"), "disabled endpoints are skipped")
+	require.Equal(t, []webhookDestination{{endpointID: 3, url: "https://c.example.com"}},
+		subscribedEndpoints(endpoints, "capacity_changed"))
+	require.Empty(t, subscribedEndpoints(endpoints, "person_offboarded"))
+
+	require.Equal(t, []string{"capacity_changed", "load_created"},
+		uniqueEvents([]string{"load_created", "capacity_changed", "load_created"}))
+}
+
+func TestLoadCreatedPayload(t *testing.T) {
+	externalID, source := "gcal-123", "calendar"
+	endDate := time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC)
+	load := &models.Load{
+		ID:         7,
+		ExternalID: &externalID,
+		Title:      "Offsite",
+		Source:     &source,
+		Date:       time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:    &endDate,
+	}
+
+	p := loadCreatedPayload(load, []models.LoadAssignment{{PersonEmail: "alice@example.com", Weight: 2}})
+	require.Equal(t, "load_created", p.Event)
+	require.Equal(t, 7, p.LoadID)
+	require.Equal(t, "gcal-123", p.ExternalID)
+	require.Equal(t, "calendar", p.Source)
+	require.Equal(t, "2025-03-10", p.Date)
+	require.Equal(t, "2025-03-12", p.EndDate)
+	require.Len(t, p.Assignments, 1)
+	require.Equal(t, `"Offsite" on 2025-03-10 was created`, p.Message)
+}
+
+func TestGroupOverloadPayload(t *testing.T) {
+	date := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	members := []models.RebalanceMember{
+		{Email: "alice@example.com", Title: "Alice", Capacity: 5, LoadBefore: 1},
+		{Email: "bob@example.com", Title: "Bob", Capacity: 5, LoadBefore: 3},
+		{Email: "idle@example.com", Title: "Idle", Capacity: 5},
+	}
+
+	p := groupOverloadPayload("team", date, 4, 3, members)
+	require.Equal(t, "group_overload_alert", p.Event)
+	require.Equal(t, "team", p.GroupID)
+	require.Equal(t, "2025-03-10", p.Date)
+	require.Equal(t, []models.GroupOverloadMember{
+		{Email: "bob@example.com", Title: "Bob", Load: 3, Capacity: 5},
+		{Email: "alice@example.com", Title: "Alice", Load: 1, Capacity: 5},
+	}, p.Members, "members without load are left out, the most loaded first")
+	require.Equal(t, "Group team is overloaded on 2025-03-10 (load: 4.0, capacity: 3.0) across 2 members", p.Message)
+}
+
+func TestUpcomingDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2025, time.March, 11, 15, 0, 0, 0, time.UTC)
+
+	require.Equal(t, []time.Time{day(11), day(12)}, upcomingDays([]time.Time{day(9), day(10), day(11), day(12)}, now))
+	require.Empty(t, upcomingDays([]time.Time{day(9), day(10)}, now))
+}
+
+func TestAnomalyPayload(t *testing.T) {
+	p := anomalyPayload(models.LoadAnomaly{
+		PersonEmail: "alice@example.com",
+		Date:        time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Load:        6,
+		Baseline:    2,
+	})
+	require.Equal(t, "anomaly", p.Event)
+	require.Equal(t, "2025-03-10", p.Date)
+	require.Equal(t, 3.0, p.Ratio)
+	require.Equal(t, "alice@example.com has an unusual load on 2025-03-10: 6.0, 3.0x their recent average of 2.0", p.Message)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gti/heatmap-internal/internal/models"
+)
+
+// defaultLoadWeight is the weight of an assignment when neither the request
+// nor a weight rule sets one
+const defaultLoadWeight = 1.0
+
+// WeightRules pick the weight of upserted assignments that omit one, from the
+// load's source, duration, and whether it is all-day. The first matching rule
+// wins, so specific rules go before a source's catch-all.
+type WeightRules []models.WeightRule
+
+// LoadWeightRules reads weight rules from a JSON array in the file at path
+func LoadWeightRules(path string) (WeightRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weight rules: %w", err)
+	}
+
+	var rules WeightRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse weight rules: %w", err)
+	}
+
+	for i, r := range rules {
+		switch {
+		case r.Source == "":
+			return nil, fmt.Errorf("weight rule %d: source is required", i+1)
+		case r.Weight <= 0:
+			return nil, fmt.Errorf("weight rule %d: weight must be positive", i+1)
+		case r.MinMinutes != nil && r.MaxMinutes != nil && *r.MinMinutes > *r.MaxMinutes:
+			return nil, fmt.Errorf("weight rule %d: min_minutes is above max_minutes", i+1)
+		}
+	}
+
+	return rules, nil
+}
+
+// Weight returns the weight of the first rule matching a load, or 1.0. Rules
+// with duration bounds only match loads that report a duration.
+func (rules WeightRules) Weight(source string, durationMinutes *int, allDay bool) float64 {
+	for _, r := range rules {
+		if !strings.EqualFold(r.Source, source) {
+			continue
+		}
+		if r.AllDay != nil && *r.AllDay != allDay {
+			continue
+		}
+		if (r.MinMinutes != nil || r.MaxMinutes != nil) && durationMinutes == nil {
+			continue
+		}
+		if r.MinMinutes != nil && *durationMinutes < *r.MinMinutes {
+			continue
+		}
+		if r.MaxMinutes != nil && *durationMinutes > *r.MaxMinutes {
+			continue
+		}
+		return r.Weight
+	}
+	return defaultLoadWeight
+}
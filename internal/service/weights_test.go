@@ -0,0 +1,60 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightRules(t *testing.T) {
+	rules, err := LoadWeightRules(filepath.Join("..", "..", "weight_rules.example.json"))
+	require.NoError(t, err)
+
+	minutes := func(m int) *int { return &m }
+
+	tests := []struct {
+		name     string
+		source   string
+		duration *int
+		allDay   bool
+		want     float64
+	}{
+		{"all-day event", "gcal", nil, true, 2.0},
+		{"all-day wins over duration", "gcal", minutes(30), true, 2.0},
+		{"short meeting", "gcal", minutes(30), false, 0.25},
+		{"bounds are inclusive", "gcal", minutes(120), false, 0.5},
+		{"long meeting falls through to catch-all", "gcal", minutes(180), false, 1.0},
+		{"no duration skips duration rules", "gcal", nil, false, 1.0},
+		{"source is case-insensitive", "JIRA", nil, false, 1.5},
+		{"unknown source", "github", minutes(30), false, 1.0},
+		{"no source", "", nil, false, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.Weight(tt.source, tt.duration, tt.allDay))
+		})
+	}
+
+	assert.Equal(t, 1.0, WeightRules(nil).Weight("gcal", minutes(30), false), "no rules")
+}
+
+func TestLoadWeightRulesRejectsInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"missing source":  `[{"weight": 1}]`,
+		"zero weight":     `[{"source": "gcal", "weight": 0}]`,
+		"inverted bounds": `[{"source": "gcal", "min_minutes": 60, "max_minutes": 30, "weight": 1}]`,
+		"not an array":    `{"source": "gcal", "weight": 1}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			_, err := LoadWeightRules(path)
+			assert.Error(t, err)
+		})
+	}
+}
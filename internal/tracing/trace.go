@@ -0,0 +1,78 @@
+// Package tracing correlates requests with what they cause, such as webhook
+// deliveries, through W3C Trace Context IDs. Callers instrumented with
+// OpenTelemetry continue their own trace; others get a new one.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Extract returns ctx carrying a new span for the request with the given
+// headers: a child of the caller's span when they sent a valid traceparent,
+// or the root of a new trace otherwise
+func Extract(ctx context.Context, header http.Header) context.Context {
+	parent := trace.SpanContextFromContext(propagator.Extract(ctx, propagation.HeaderCarrier(header)))
+	return trace.ContextWithSpanContext(ctx, child(parent))
+}
+
+// Ensure returns ctx unchanged when it carries a span, and otherwise with the
+// root span of a new trace, for work started outside a request
+func Ensure(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, child(trace.SpanContext{}))
+}
+
+// Inject sets the traceparent header for ctx's span, if it has one
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// IDs returns the hex trace and span IDs of ctx's span, both empty when it
+// has none
+func IDs(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// child returns a new span in parent's trace, or in a new trace when parent
+// is not valid
+func child(parent trace.SpanContext) trace.SpanContext {
+	config := trace.SpanContextConfig{
+		TraceID:    parent.TraceID(),
+		TraceFlags: parent.TraceFlags(),
+	}
+	if !parent.IsValid() {
+		config.TraceID = newTraceID()
+		config.TraceFlags = 0
+	}
+	config.SpanID = newSpanID()
+	return trace.NewSpanContext(config)
+}
+
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
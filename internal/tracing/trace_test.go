@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractContinuesCallerTrace(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := Extract(context.Background(), header)
+	traceID, spanID := IDs(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Len(t, spanID, 16)
+	assert.NotEqual(t, "00f067aa0ba902b7", spanID, "the request gets its own span")
+
+	out := http.Header{}
+	Inject(ctx, out)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spanID+"-01", out.Get("traceparent"), "sampling is kept")
+}
+
+func TestExtractStartsTrace(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "not a traceparent")
+
+	ctx := Extract(context.Background(), header)
+	traceID, spanID := IDs(ctx)
+	assert.Len(t, traceID, 32)
+	assert.Len(t, spanID, 16)
+
+	other, _ := IDs(Extract(context.Background(), http.Header{}))
+	assert.NotEqual(t, traceID, other, "each request without a traceparent starts its own trace")
+}
+
+func TestEnsure(t *testing.T) {
+	traceID, _ := IDs(context.Background())
+	assert.Empty(t, traceID)
+
+	ctx := Ensure(context.Background())
+	traceID, spanID := IDs(ctx)
+	assert.Len(t, traceID, 32)
+
+	again, againSpan := IDs(Ensure(ctx))
+	assert.Equal(t, traceID, again, "a context with a span is kept")
+	assert.Equal(t, spanID, againSpan)
+}
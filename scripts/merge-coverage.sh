@@ -8,7 +8,13 @@
 # Input files (in coverage/):
 #   unit.out        - Unit test coverage
 #   e2e.out         - E2E test coverage (test code itself)
-#   e2e-service/    - E2E service coverage (binary coverage data)
+#   e2e-service*/   - E2E service coverage (binary coverage data, one
+#                     directory per instrumented run)
+#
+# Thresholds:
+#   Per-package minimums are read from scripts/coverage-thresholds.txt.
+#   Override with COVERAGE_THRESHOLDS=<file>, or disable with
+#   COVERAGE_THRESHOLDS= (empty).
 #
 # Output files (in coverage/):
 #   e2e-service.out - Converted service coverage (text format)
@@ -19,6 +25,7 @@
 #   0 - Success
 #   1 - No coverage files found
 #   2 - Merge failed
+#   3 - A package is below its coverage threshold
 
 set -euo pipefail
 
@@ -33,6 +40,7 @@ NC='\033[0m'
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 PROJECT_ROOT="$(cd "${SCRIPT_DIR}/.." && pwd)"
 COVERAGE_DIR="${PROJECT_ROOT}/coverage"
+COVERAGE_THRESHOLDS="${COVERAGE_THRESHOLDS-${SCRIPT_DIR}/coverage-thresholds.txt}"
 
 # Change to project root
 cd "${PROJECT_ROOT}"
@@ -66,31 +74,26 @@ else
     echo -e "${YELLOW}○ No E2E test coverage (e2e.out)${NC}"
 fi
 
-# Check for E2E service coverage (binary format)
-SERVICE_COVERAGE_DIR="${COVERAGE_DIR}/e2e-service"
-SERVICE_COVERAGE_OUT="${COVERAGE_DIR}/e2e-service.out"
-
-if [[ -d "${SERVICE_COVERAGE_DIR}" ]] && [[ -n "$(ls -A "${SERVICE_COVERAGE_DIR}" 2>/dev/null)" ]]; then
-    echo -e "${GREEN}✓ Found E2E service coverage data${NC}"
-
-    # Convert binary coverage data to text format
-    echo -e "${YELLOW}  Converting binary coverage data...${NC}"
-
-    if go tool covdata textfmt -i="${SERVICE_COVERAGE_DIR}" -o="${SERVICE_COVERAGE_OUT}" 2>/dev/null; then
-        echo -e "${GREEN}  Converted to ${SERVICE_COVERAGE_OUT}${NC}"
-        COVERAGE_FILES+=("${SERVICE_COVERAGE_OUT}")
-        HAS_SERVICE=1
-    else
-        echo -e "${RED}  Failed to convert service coverage${NC}"
+# Check for E2E service coverage (binary format, one directory per run)
+SERVICE_COVERAGE_DIRS=()
+for dir in "${COVERAGE_DIR}"/e2e-service*/; do
+    if [[ -d "${dir}" ]] && [[ -n "$(ls -A "${dir}" 2>/dev/null)" ]]; then
+        SERVICE_COVERAGE_DIRS+=("${dir%/}")
     fi
+done
+
+SERVICE_COVERAGE_OUT="${COVERAGE_DIR}/e2e-service.out"
+if [[ ${#SERVICE_COVERAGE_DIRS[@]} -gt 0 ]]; then
+    echo -e "${GREEN}✓ Found E2E service coverage data (${#SERVICE_COVERAGE_DIRS[@]} run(s))${NC}"
+    HAS_SERVICE=1
 else
-    echo -e "${YELLOW}○ No E2E service coverage (e2e-service/)${NC}"
+    echo -e "${YELLOW}○ No E2E service coverage (e2e-service*/)${NC}"
 fi
 
 echo ""
 
 # Check if we have any coverage files
-if [[ ${#COVERAGE_FILES[@]} -eq 0 ]]; then
+if [[ ${#COVERAGE_FILES[@]} -eq 0 ]] && [[ "${HAS_SERVICE}" == "0" ]]; then
     echo -e "${RED}No coverage files found!${NC}"
     echo ""
     echo "Run tests first:"
@@ -103,29 +106,36 @@ fi
 COMBINED_OUT="${COVERAGE_DIR}/combined.out"
 COMBINED_HTML="${COVERAGE_DIR}/coverage.html"
 
-echo -e "${BLUE}Merging ${#COVERAGE_FILES[@]} coverage file(s)...${NC}"
+COVMERGE_ARGS=(-o "${COMBINED_OUT}" -html "${COMBINED_HTML}")
+for file in "${COVERAGE_FILES[@]}"; do
+    COVMERGE_ARGS+=(-profile "${file}")
+done
+for dir in "${SERVICE_COVERAGE_DIRS[@]}"; do
+    COVMERGE_ARGS+=(-coverdir "${dir}")
+done
+if [[ -n "${COVERAGE_THRESHOLDS}" ]]; then
+    COVMERGE_ARGS+=(-thresholds "${COVERAGE_THRESHOLDS}")
+fi
 
-# Method 1: Try gocovmerge if available
-if command -v gocovmerge &> /dev/null; then
-    echo "Using gocovmerge..."
-    gocovmerge "${COVERAGE_FILES[@]}" > "${COMBINED_OUT}"
-else
-    # Method 2: Manual merge (concatenate with header handling)
-    echo "Using manual merge (gocovmerge not installed)..."
-
-    # Start with first file (includes header)
-    FIRST_FILE="${COVERAGE_FILES[0]}"
-    cp "${FIRST_FILE}" "${COMBINED_OUT}"
-
-    # Append remaining files (skip their headers)
-    for ((i=1; i<${#COVERAGE_FILES[@]}; i++)); do
-        FILE="${COVERAGE_FILES[$i]}"
-        # Skip the "mode:" header line and append the rest
-        tail -n +2 "${FILE}" >> "${COMBINED_OUT}"
-    done
+echo -e "${BLUE}Merging ${#COVERAGE_FILES[@]} profile(s) and ${#SERVICE_COVERAGE_DIRS[@]} service run(s)...${NC}"
+echo ""
 
-    echo -e "${YELLOW}Tip: Install gocovmerge for better merging:${NC}"
-    echo "  go install github.com/wadey/gocovmerge@latest"
+THRESHOLDS_FAILED=0
+if ! go run ./cmd/covmerge "${COVMERGE_ARGS[@]}"; then
+    if [[ ! -s "${COMBINED_OUT}" ]]; then
+        echo -e "${RED}Failed to merge coverage${NC}"
+        exit 2
+    fi
+    THRESHOLDS_FAILED=1
+fi
+
+# Keep a standalone service profile for the summary below
+if [[ "${HAS_SERVICE}" == "1" ]]; then
+    SERVICE_ARGS=(-o "${SERVICE_COVERAGE_OUT}")
+    for dir in "${SERVICE_COVERAGE_DIRS[@]}"; do
+        SERVICE_ARGS+=(-coverdir "${dir}")
+    done
+    go run ./cmd/covmerge "${SERVICE_ARGS[@]}" > /dev/null 2>&1 || HAS_SERVICE=0
 fi
 
 echo ""
@@ -136,9 +146,6 @@ if [[ ! -f "${COMBINED_OUT}" ]] || [[ ! -s "${COMBINED_OUT}" ]]; then
     exit 2
 fi
 
-# Generate HTML report
-echo -e "${BLUE}Generating HTML report...${NC}"
-go tool cover -html="${COMBINED_OUT}" -o="${COMBINED_HTML}"
 echo -e "${GREEN}✓ Created ${COMBINED_HTML}${NC}"
 echo ""
 
@@ -171,21 +178,17 @@ TOTAL_COV=$(go tool cover -func="${COMBINED_OUT}" 2>/dev/null | grep "^total:" |
 echo -e "${GREEN}Combined:      ${TOTAL_COV}${NC}"
 echo ""
 
-# Show package breakdown (top 10 lowest coverage)
-echo -e "${BLUE}Lowest Coverage Packages:${NC}"
-go tool cover -func="${COMBINED_OUT}" 2>/dev/null | \
-    grep -v "^total:" | \
-    sort -t'%' -k2 -n | \
-    head -10 | \
-    awk '{printf "  %-50s %s\n", $1, $3}'
-echo ""
-
 # Output file locations
 echo -e "${BLUE}Output files:${NC}"
 echo "  Combined profile: ${COMBINED_OUT}"
 echo "  HTML report:      ${COMBINED_HTML}"
 echo ""
 
+if [[ "${THRESHOLDS_FAILED}" == "1" ]]; then
+    echo -e "${RED}Coverage is below the thresholds in ${COVERAGE_THRESHOLDS}${NC}"
+    exit 3
+fi
+
 # Success message with instructions
 echo -e "${GREEN}Coverage merge complete!${NC}"
 echo ""